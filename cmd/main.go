@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"database/sql"
+	"expvar"
 	"net/http"
 	"os"
 	"os/signal"
@@ -17,6 +18,7 @@ import (
 	"github.com/par1ram/merch-store/internal/service"
 	"github.com/par1ram/merch-store/internal/utils"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	_ "github.com/lib/pq"
 	"github.com/pressly/goose"
@@ -27,6 +29,11 @@ func main() {
 	logger := utils.NewLogger()
 	cfg := config.LoadConfig()
 
+	// Подкоманда сверки балансов запускается без сервера и миграций.
+	if len(os.Args) > 1 && os.Args[1] == "reconcile" {
+		os.Exit(runReconcile(cfg, logger, os.Args[2:]))
+	}
+
 	// Соединение для миграций:
 	sqlDB, err := sql.Open("postgres", cfg.DatabaseURL)
 	if err != nil {
@@ -51,8 +58,14 @@ func main() {
 		logrus.Fatalf("Error seeding merchandise data: %v", err)
 	}
 
+	// Назначаем роль admin пользователям из ADMIN_USERS.
+	if err := utils.SeedAdmins(context.Background(), pool, cfg.AdminUsers, logrus.StandardLogger()); err != nil {
+		logrus.Fatalf("Error seeding admins: %v", err)
+	}
+
 	// Инициализируем sqlc-клиент (сгенерированный код).
 	queries := db.New(pool)
+	txRunner := repository.NewTxRunner(pool, txConfig(cfg), logger)
 
 	// Создание репозиториев и сервисов
 	userRepo := repository.NewPostgresUserRepository(queries, logger)
@@ -64,22 +77,136 @@ func main() {
 	infoHandler := handlers.NewInfoHandler(infoService)
 	secureInfoHandler := middleware.JWTMiddleware([]byte(cfg.JWTSecret))(http.HandlerFunc(infoHandler.HandleInfo))
 
-	sendCoinRepository := repository.NewSendCoinRepository(pool, queries, logger)
-	sendCoinService := service.NewSendCoinService(sendCoinRepository, logger)
+	historyRepo := repository.NewHistoryRepository(queries, logger)
+	historyService := service.NewHistoryService(historyRepo, logger)
+	historyHandler := handlers.NewHistoryHandler(historyService)
+	secureHistoryHandler := middleware.JWTMiddleware([]byte(cfg.JWTSecret))(http.HandlerFunc(historyHandler.HandleHistory))
+
+	// Мутирующие маршруты поддерживают заголовок Idempotency-Key.
+	idempotencyRepo := repository.NewIdempotencyRepository(queries, logger)
+	idempotent := middleware.Idempotency(idempotencyRepo, cfg.IdempotencyWindow, logger)
+	secureMutation := func(h http.HandlerFunc) http.Handler {
+		return middleware.JWTMiddleware([]byte(cfg.JWTSecret))(idempotent(h))
+	}
+
+	transferLimits := service.TransferLimits{
+		Daily:        cfg.TransferDailyLimit,
+		Monthly:      cfg.TransferMonthlyLimit,
+		PerRecipient: cfg.TransferRecipientLimit,
+	}
+	sendCoinRepository := repository.NewSendCoinRepository(txRunner, queries, logger)
+	holdTTL := time.Duration(cfg.PendingTransferDays) * 24 * time.Hour
+	sendCoinService := service.NewSendCoinService(sendCoinRepository, transferLimits, holdTTL, logger)
 	sendCoinHandler := handlers.NewSendCoinHandler(sendCoinService)
-	secureSendCoinHandler := middleware.JWTMiddleware([]byte(cfg.JWTSecret))(http.HandlerFunc(sendCoinHandler.HandleSendCoin))
+	secureSendCoinHandler := secureMutation(sendCoinHandler.HandleSendCoin)
+	secureSendCoinBatchHandler := secureMutation(sendCoinHandler.HandleSendCoinBatch)
+
+	coinRequestRepo := repository.NewCoinRequestRepository(txRunner, queries, logger)
+	coinRequestService := service.NewCoinRequestService(coinRequestRepo, transferLimits, cfg.CoinRequestTTL, logger)
+	coinRequestHandler := handlers.NewCoinRequestHandler(coinRequestService)
+
+	pendingTransferRepo := repository.NewPendingTransferRepository(txRunner, queries, logger)
+	pendingTransferService := service.NewPendingTransferService(pendingTransferRepo, logger)
+	pendingTransferHandler := handlers.NewPendingTransferHandler(pendingTransferService)
 
-	buyRepo := repository.NewBuyRepository(pool, queries, logger)
+	buyRepo := repository.NewBuyRepository(txRunner, queries, logger)
 	buyService := service.NewBuyService(buyRepo, logger)
 	buyHandler := handlers.NewBuyHandler(buyService)
-	secureBuyHandler := middleware.JWTMiddleware([]byte(cfg.JWTSecret))(http.HandlerFunc(buyHandler.HandleBuy))
+	// Покупка выполняется и через GET, поэтому ключ проверяется при любом методе.
+	secureBuyHandler := middleware.JWTMiddleware([]byte(cfg.JWTSecret))(
+		middleware.IdempotencyAnyMethod(idempotencyRepo, cfg.IdempotencyWindow, logger)(http.HandlerFunc(buyHandler.HandleBuy)),
+	)
+	secureCheckoutHandler := secureMutation(buyHandler.HandleCheckout)
+
+	cartRepo := repository.NewCartRepository(queries, logger)
+	cartService := service.NewCartService(cartRepo, logger)
+	cartHandler := handlers.NewCartHandler(cartService)
+	secureCartHandler := secureMutation(cartHandler.HandleCart)
+	secureCartItemHandler := secureMutation(cartHandler.HandleCartItem)
+
+	merchRepo := repository.NewMerchRepository(queries, logger)
+	merchService := service.NewMerchService(merchRepo, logger)
+	merchHandler := handlers.NewMerchHandler(merchService)
+	secureMerchHandler := middleware.JWTMiddleware([]byte(cfg.JWTSecret))(http.HandlerFunc(merchHandler.HandleListMerch))
+
+	refundRepo := repository.NewRefundRepository(txRunner, queries, logger)
+	refundService := service.NewRefundService(refundRepo, logger)
+	refundHandler := handlers.NewRefundHandler(refundService)
+
+	orderRepo := repository.NewOrderRepository(txRunner, queries, logger)
+	orderService := service.NewOrderService(orderRepo, logger)
+	orderHandler := handlers.NewOrderHandler(orderService)
+	secureOrdersHandler := middleware.JWTMiddleware([]byte(cfg.JWTSecret))(http.HandlerFunc(orderHandler.HandleOrders))
+	secureOrderHandler := middleware.JWTMiddleware([]byte(cfg.JWTSecret))(http.HandlerFunc(orderHandler.HandleOrder))
+
+	grantRepo := repository.NewGrantRepository(txRunner, queries, logger)
+	grantService := service.NewGrantService(grantRepo, logger)
+	grantHandler := handlers.NewGrantHandler(grantService)
+
+	allowanceRepo := repository.NewAllowanceRepository(txRunner, queries, logger)
+	allowanceService := service.NewAllowanceService(allowanceRepo, cfg.AllowanceAmount, cfg.AllowanceBatchSize, logger)
+	allowanceHandler := handlers.NewAllowanceHandler(allowanceService)
+
+	expiryRepo := repository.NewExpiryRepository(queries, logger)
+	coinExpiryService := service.NewCoinExpiryService(expiryRepo, logger)
+
+	transferLimitRepo := repository.NewTransferLimitRepository(queries, logger)
+	transferLimitService := service.NewTransferLimitService(transferLimitRepo, transferLimits, logger)
+	transferLimitHandler := handlers.NewTransferLimitHandler(transferLimitService)
+
+	ledgerRepo := repository.NewLedgerRepository(queries, logger)
+	ledgerService := service.NewLedgerService(ledgerRepo, logger)
+	ledgerHandler := handlers.NewLedgerHandler(ledgerService)
+
+	statementRepo := repository.NewStatementRepository(txRunner, queries, logger)
+	statementService := service.NewStatementService(statementRepo, logger)
+	statementHandler := handlers.NewStatementHandler(statementService)
+	secureStatementHandler := middleware.JWTMiddleware([]byte(cfg.JWTSecret))(http.HandlerFunc(statementHandler.HandleStatement))
+
+	reconciliationRepo := repository.NewReconciliationRepository(txRunner, queries, logger)
+	reconciliationService := service.NewReconciliationService(reconciliationRepo, logger)
+
+	// Административные маршруты доступны только пользователям с ролью admin.
+	// Роль проверяется по базе, чтобы понижение действовало сразу.
+	roleRepo := repository.NewRoleRepository(queries, logger)
+	adminOnly := func(h http.HandlerFunc) http.Handler {
+		return middleware.JWTMiddleware([]byte(cfg.JWTSecret))(middleware.RequireRole(roleRepo.GetRole, service.RoleAdmin)(idempotent(h)))
+	}
 
 	// Маршруты
 	mux := http.NewServeMux()
 	mux.HandleFunc("/api/auth", authHandler.HandleAuth)
 	mux.Handle("/api/info", secureInfoHandler)
+	mux.Handle("/api/history", secureHistoryHandler)
+	mux.Handle("/api/statements/", secureStatementHandler)
 	mux.Handle("/api/send-coin", secureSendCoinHandler)
+	mux.Handle("/api/send-coin/batch", secureSendCoinBatchHandler)
+	mux.Handle("/api/coin-requests", secureMutation(coinRequestHandler.HandleCoinRequests))
+	mux.Handle("/api/coin-requests/", secureMutation(coinRequestHandler.HandleCoinRequestAction))
+	mux.Handle("/api/pending-transfers", secureMutation(pendingTransferHandler.HandlePendingTransfers))
+	mux.Handle("/api/pending-transfers/", secureMutation(pendingTransferHandler.HandlePendingTransferAction))
 	mux.Handle("/api/buy/", secureBuyHandler)
+	mux.Handle("/api/cart", secureCartHandler)
+	mux.Handle("/api/cart/", secureCartItemHandler)
+	mux.Handle("/api/checkout", secureCheckoutHandler)
+	mux.Handle("/api/merch", secureMerchHandler)
+	mux.Handle("/api/purchases", middleware.JWTMiddleware([]byte(cfg.JWTSecret))(http.HandlerFunc(refundHandler.HandlePurchases)))
+	mux.Handle("/api/refunds", secureMutation(refundHandler.HandleRefunds))
+	mux.Handle("/api/orders", secureOrdersHandler)
+	mux.Handle("/api/orders/", secureOrderHandler)
+	mux.Handle("/api/admin/merch", adminOnly(merchHandler.HandleAdminMerch))
+	mux.Handle("/api/admin/merch/", adminOnly(merchHandler.HandleAdminMerchItem))
+	mux.Handle("/api/admin/employees/", adminOnly(authHandler.HandleSetRole))
+	mux.Handle("/api/admin/refunds", adminOnly(refundHandler.HandleAdminRefunds))
+	mux.Handle("/api/admin/refunds/", adminOnly(refundHandler.HandleAdminRefundAction))
+	mux.Handle("/api/admin/orders", adminOnly(orderHandler.HandleAdminOrders))
+	mux.Handle("/api/admin/orders/", adminOnly(orderHandler.HandleAdminOrder))
+	mux.Handle("/api/admin/coins", adminOnly(grantHandler.HandleAdminCoins))
+	mux.Handle("/api/admin/allowance/preview", adminOnly(allowanceHandler.HandlePreview))
+	mux.Handle("/api/admin/transfer-limits/", adminOnly(transferLimitHandler.HandleAdminTransferLimits))
+	mux.Handle("/api/admin/ledger", adminOnly(ledgerHandler.HandleVerify))
+	mux.Handle("/api/admin/statements/", adminOnly(statementHandler.HandleAdminStatements))
+	mux.Handle("/debug/vars", adminOnly(expvar.Handler().ServeHTTP))
 
 	// Создаем http.Server
 	server := &http.Server{
@@ -89,6 +216,110 @@ func main() {
 
 	logrus.Infof("Server started on PORT: %s", cfg.ServerPort)
 
+	// Фоновые задачи останавливаются вместе с сервером.
+	bgCtx, stopBackground := context.WithCancel(context.Background())
+	defer stopBackground()
+
+	// Периодически удаляем просроченные ключи идемпотентности.
+	go func() {
+		ticker := time.NewTicker(time.Hour)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-bgCtx.Done():
+				return
+			case <-ticker.C:
+				if _, err := idempotencyRepo.PurgeExpired(bgCtx, time.Now().Add(-cfg.IdempotencyWindow)); err != nil {
+					logrus.Errorf("Failed to purge idempotency keys: %v", err)
+				}
+			}
+		}
+	}()
+
+	// Начисляем ежемесячное пособие: сразу при старте и затем периодически.
+	// Повторные запуски в том же месяце ничего не начисляют.
+	go func() {
+		ticker := time.NewTicker(cfg.AllowanceInterval)
+		defer ticker.Stop()
+		for {
+			if _, err := allowanceService.Accrue(bgCtx, time.Now()); err != nil && bgCtx.Err() == nil {
+				logrus.Errorf("Failed to accrue allowance: %v", err)
+			}
+			select {
+			case <-bgCtx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	// Закрываем запросы монет, оставшиеся без ответа дольше COIN_REQUEST_TTL.
+	go func() {
+		ticker := time.NewTicker(time.Hour)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-bgCtx.Done():
+				return
+			case <-ticker.C:
+				if _, err := coinRequestService.ExpireRequests(bgCtx); err != nil && bgCtx.Err() == nil {
+					logrus.Errorf("Failed to expire coin requests: %v", err)
+				}
+			}
+		}
+	}()
+
+	// Возвращаем отправителям удержанные переводы старше PENDING_TRANSFER_DAYS.
+	go func() {
+		ticker := time.NewTicker(time.Hour)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-bgCtx.Done():
+				return
+			case <-ticker.C:
+				if _, err := pendingTransferService.RefundExpired(bgCtx); err != nil && bgCtx.Err() == nil {
+					logrus.Errorf("Failed to refund expired pending transfers: %v", err)
+				}
+			}
+		}
+	}()
+
+	// Списываем монеты с истёкшим сроком действия.
+	go func() {
+		ticker := time.NewTicker(cfg.CoinExpiryInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-bgCtx.Done():
+				return
+			case <-ticker.C:
+				if _, err := coinExpiryService.ExpireLots(bgCtx); err != nil && bgCtx.Err() == nil {
+					logrus.Errorf("Failed to expire coins: %v", err)
+				}
+			}
+		}
+	}()
+
+	// Сверяем балансы с историей операций, если задан RECONCILE_INTERVAL.
+	// Расхождения попадают в лог и метрики; исправление — только командой reconcile --fix.
+	if cfg.ReconcileInterval > 0 {
+		go func() {
+			ticker := time.NewTicker(cfg.ReconcileInterval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-bgCtx.Done():
+					return
+				case <-ticker.C:
+					if _, err := reconciliationService.Report(bgCtx); err != nil && bgCtx.Err() == nil {
+						logrus.Errorf("Failed to reconcile balances: %v", err)
+					}
+				}
+			}
+		}()
+	}
+
 	// Канал для сигналов
 	stop := make(chan os.Signal, 1)
 	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
@@ -103,6 +334,7 @@ func main() {
 	// Ждём сигнала
 	<-stop
 	logrus.Info("Shutting down gracefully...")
+	stopBackground()
 
 	// Контекст с таймаутом на завершение активных соединений
 	ctxShutDown, cancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -114,3 +346,13 @@ func main() {
 
 	logrus.Info("Server exited properly")
 }
+
+// txConfig собирает параметры транзакций репозиториев из конфигурации.
+func txConfig(cfg *config.Config) repository.TxConfig {
+	return repository.TxConfig{
+		IsoLevel:    pgx.TxIsoLevel(cfg.TxIsolationLevel),
+		MaxAttempts: int(cfg.TxMaxAttempts),
+		BaseDelay:   cfg.TxRetryBaseDelay,
+		MaxDelay:    cfg.TxRetryMaxDelay,
+	}
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/par1ram/merch-store/internal/config"
+	"github.com/par1ram/merch-store/internal/db"
+	"github.com/par1ram/merch-store/internal/repository"
+	"github.com/par1ram/merch-store/internal/service"
+	"github.com/par1ram/merch-store/internal/utils"
+)
+
+// Коды завершения команды reconcile.
+const (
+	reconcileExitOK    = 0
+	reconcileExitError = 1
+	reconcileExitDrift = 2
+)
+
+// runReconcile сверяет балансы сотрудников с историей операций и с партиями
+// монет и печатает отчёт в stdout в формате JSON:
+//
+//	merch-store reconcile [--fix --admin=<username>]
+//
+// С --fix расхождения закрываются компенсирующими транзакциями от имени
+// указанного администратора. Без --fix найденные расхождения дают код завершения 2;
+// расхождения партий с балансом дают код 2 и с --fix, так как он их не исправляет.
+func runReconcile(cfg *config.Config, logger utils.Logger, args []string) int {
+	flags := flag.NewFlagSet("reconcile", flag.ContinueOnError)
+	fix := flags.Bool("fix", false, "write compensating adjustment transactions")
+	admin := flags.String("admin", "", "admin username recorded as the author of compensating transactions")
+	if err := flags.Parse(args); err != nil {
+		return reconcileExitError
+	}
+	if *fix && *admin == "" {
+		fmt.Fprintln(os.Stderr, "reconcile: --fix requires --admin")
+		return reconcileExitError
+	}
+
+	ctx := context.Background()
+	pool, err := pgxpool.New(ctx, cfg.DatabaseURL)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "reconcile: unable to connect to database: %v\n", err)
+		return reconcileExitError
+	}
+	defer pool.Close()
+
+	repo := repository.NewReconciliationRepository(repository.NewTxRunner(pool, txConfig(cfg), logger), db.New(pool), logger)
+	reconciliationService := service.NewReconciliationService(repo, logger)
+
+	var report service.ReconciliationReport
+	if *fix {
+		report, err = reconciliationService.Fix(ctx, *admin)
+	} else {
+		report, err = reconciliationService.Report(ctx)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "reconcile: %v\n", err)
+		return reconcileExitError
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		fmt.Fprintf(os.Stderr, "reconcile: %v\n", err)
+		return reconcileExitError
+	}
+
+	if (!report.Consistent && !report.Fixed) || len(report.LotMismatches) > 0 {
+		return reconcileExitDrift
+	}
+	return reconcileExitOK
+}
@@ -0,0 +1,186 @@
+package e2e_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	concurrentRequests = 40
+	transferAmount     = 30
+	tShirtPrice        = 80
+)
+
+// TestConcurrentTransfersAndPurchases одновременно переводит монеты в обе
+// стороны между двумя сотрудниками и покупает мерч от их имени. Запросов
+// больше, чем позволяют балансы, поэтому часть из них должна быть отклонена;
+// итоговые балансы должны остаться неотрицательными и совпасть с суммой
+// успешных операций.
+func TestConcurrentTransfersAndPurchases(t *testing.T) {
+	client := &http.Client{Timeout: 30 * time.Second}
+
+	suffix := time.Now().UnixNano()
+	alice := fmt.Sprintf("race_alice_%d", suffix)
+	bob := fmt.Sprintf("race_bob_%d", suffix)
+	aliceToken := authenticate(t, client, alice)
+	bobToken := authenticate(t, client, bob)
+
+	aliceStart := getCoins(t, client, aliceToken)
+	bobStart := getCoins(t, client, bobToken)
+
+	var (
+		aliceSent, bobSent     atomic.Int64
+		aliceBought, bobBought atomic.Int64
+		wg                     sync.WaitGroup
+	)
+	for i := 0; i < concurrentRequests; i++ {
+		wg.Add(4)
+		go func() {
+			defer wg.Done()
+			if sendCoins(t, client, aliceToken, bob, transferAmount) {
+				aliceSent.Add(1)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			if sendCoins(t, client, bobToken, alice, transferAmount) {
+				bobSent.Add(1)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			if buyTShirt(t, client, aliceToken) {
+				aliceBought.Add(1)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			if buyTShirt(t, client, bobToken) {
+				bobBought.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	aliceCoins := getCoins(t, client, aliceToken)
+	bobCoins := getCoins(t, client, bobToken)
+
+	assert.GreaterOrEqual(t, aliceCoins, 0, "Баланс не должен уходить в минус")
+	assert.GreaterOrEqual(t, bobCoins, 0, "Баланс не должен уходить в минус")
+
+	aliceExpected := aliceStart - int(aliceSent.Load())*transferAmount + int(bobSent.Load())*transferAmount -
+		int(aliceBought.Load())*tShirtPrice
+	bobExpected := bobStart - int(bobSent.Load())*transferAmount + int(aliceSent.Load())*transferAmount -
+		int(bobBought.Load())*tShirtPrice
+	assert.Equal(t, aliceExpected, aliceCoins, "Баланс должен совпадать с суммой успешных операций")
+	assert.Equal(t, bobExpected, bobCoins, "Баланс должен совпадать с суммой успешных операций")
+	assert.Equal(t, aliceStart+bobStart-int(aliceBought.Load()+bobBought.Load())*tShirtPrice, aliceCoins+bobCoins,
+		"Переводы не должны создавать или терять монеты")
+}
+
+// TestConcurrentPurchasesDoNotOverspend одновременно отправляет больше покупок,
+// чем покрывает баланс: успешных не может быть больше, чем хватает монет.
+func TestConcurrentPurchasesDoNotOverspend(t *testing.T) {
+	client := &http.Client{Timeout: 30 * time.Second}
+
+	token := authenticate(t, client, fmt.Sprintf("race_buyer_%d", time.Now().UnixNano()))
+	start := getCoins(t, client, token)
+
+	var bought atomic.Int64
+	var wg sync.WaitGroup
+	for i := 0; i < start/tShirtPrice+concurrentRequests; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if buyTShirt(t, client, token) {
+				bought.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	coins := getCoins(t, client, token)
+	assert.LessOrEqual(t, bought.Load(), int64(start/tShirtPrice), "Покупок не может пройти больше, чем хватает монет")
+	assert.Equal(t, start-int(bought.Load())*tShirtPrice, coins)
+	assert.GreaterOrEqual(t, coins, 0, "Баланс не должен уходить в минус")
+}
+
+func authenticate(t *testing.T, client *http.Client, username string) string {
+	t.Helper()
+
+	body, err := json.Marshal(map[string]string{"username": username, "password": "testpassword"})
+	require.NoError(t, err)
+	resp, err := client.Post(baseURL+"/api/auth", "application/json", bytes.NewReader(body))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode, "Аутентификация должна вернуть 200")
+
+	var data struct {
+		Token string `json:"token"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&data))
+	require.NotEmpty(t, data.Token)
+	return data.Token
+}
+
+func getCoins(t *testing.T, client *http.Client, token string) int {
+	t.Helper()
+
+	req, err := http.NewRequest(http.MethodGet, baseURL+"/api/info", nil)
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode, "Запрос информации должен вернуть 200")
+
+	var data struct {
+		Coins int `json:"coins"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&data))
+	return data.Coins
+}
+
+// sendCoins сообщает, был ли перевод выполнен. Отказ из-за нехватки монет или
+// лимитов — ожидаемый исход, а не ошибка теста; ошибкой считается только 5xx.
+func sendCoins(t *testing.T, client *http.Client, token, toUser string, amount int) bool {
+	body, err := json.Marshal(map[string]interface{}{"to_user": toUser, "amount": amount})
+	if !assert.NoError(t, err) {
+		return false
+	}
+	req, err := http.NewRequest(http.MethodPost, baseURL+"/api/send-coin", bytes.NewReader(body))
+	if !assert.NoError(t, err) {
+		return false
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", "application/json")
+	return doMutation(t, client, req)
+}
+
+func buyTShirt(t *testing.T, client *http.Client, token string) bool {
+	req, err := http.NewRequest(http.MethodGet, baseURL+"/api/buy/t-shirt", nil)
+	if !assert.NoError(t, err) {
+		return false
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	return doMutation(t, client, req)
+}
+
+func doMutation(t *testing.T, client *http.Client, req *http.Request) bool {
+	resp, err := client.Do(req)
+	if !assert.NoError(t, err) {
+		return false
+	}
+	defer resp.Body.Close()
+	assert.Less(t, resp.StatusCode, http.StatusInternalServerError, "%s %s", req.Method, req.URL.Path)
+	return resp.StatusCode == http.StatusOK
+}
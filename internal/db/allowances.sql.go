@@ -0,0 +1,133 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.27.0
+// source: allowances.sql
+
+package db
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const accrueAllowanceBatch = `-- name: AccrueAllowanceBatch :many
+WITH batch AS (
+  SELECT e.id
+  FROM employees e
+  WHERE NOT EXISTS (
+    SELECT 1 FROM coin_transactions ct
+    WHERE ct.transaction_type = 'allowance'
+      AND ct.to_employee_id = e.id
+      AND ct.period = $2::date
+  )
+  ORDER BY e.id
+  LIMIT $3::integer
+  FOR UPDATE OF e
+),
+inserted AS (
+  INSERT INTO coin_transactions (transaction_type, to_employee_id, amount, period)
+  SELECT 'allowance', batch.id, $1::integer, $2::date
+  FROM batch
+  ON CONFLICT (to_employee_id, period) WHERE transaction_type = 'allowance' DO NOTHING
+  RETURNING id, to_employee_id
+),
+entries AS (
+  INSERT INTO journal_entries (entry_type, coin_transaction_id)
+  SELECT 'allowance', inserted.id FROM inserted
+  RETURNING id, coin_transaction_id
+),
+postings AS (
+  INSERT INTO ledger_postings (entry_id, account_id, amount)
+  SELECT entries.id, leg.account_id, leg.amount
+  FROM entries
+  JOIN inserted ON inserted.id = entries.coin_transaction_id,
+  LATERAL (VALUES
+    ((SELECT id FROM ledger_accounts WHERE code = 'issuance'), -$1::integer),
+    ((SELECT id FROM ledger_accounts WHERE employee_id = inserted.to_employee_id), $1::integer)
+  ) AS leg(account_id, amount)
+),
+lots AS (
+  INSERT INTO coin_lots (employee_id, amount, remaining)
+  SELECT inserted.to_employee_id, $1::integer, $1::integer
+  FROM inserted
+)
+UPDATE employees e
+SET coins = e.coins + $1::integer
+FROM inserted
+WHERE e.id = inserted.to_employee_id
+RETURNING e.id
+`
+
+type AccrueAllowanceBatchParams struct {
+	Amount    int32
+	Period    pgtype.Date
+	BatchSize int32
+}
+
+// AccrueAllowanceBatch начисляет пособие за период очередной порции сотрудников,
+// ещё не получивших его. Запись в coin_transactions, проводка со счёта выпуска,
+// партия монет и изменение баланса выполняются одним запросом; ON CONFLICT по уникальному индексу периода
+// исключает повторное начисление, если другая реплика успела раньше.
+// Строки порции блокируются по возрастанию id, как в LockEmployeeBalances,
+// чтобы начисление не попадало во взаимную блокировку с переводами.
+// Возвращает id сотрудников, которым начислено пособие.
+func (q *Queries) AccrueAllowanceBatch(ctx context.Context, arg AccrueAllowanceBatchParams) ([]int32, error) {
+	rows, err := q.db.Query(ctx, accrueAllowanceBatch, arg.Amount, arg.Period, arg.BatchSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []int32
+	for rows.Next() {
+		var id int32
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAllowanceRecipients = `-- name: ListAllowanceRecipients :many
+SELECT e.id, e.username
+FROM employees e
+WHERE NOT EXISTS (
+  SELECT 1 FROM coin_transactions ct
+  WHERE ct.transaction_type = 'allowance'
+    AND ct.to_employee_id = e.id
+    AND ct.period = $1::date
+)
+ORDER BY e.id
+`
+
+type ListAllowanceRecipientsRow struct {
+	ID       int32
+	Username string
+}
+
+// ----------------------------------------------------------
+// ListAllowanceRecipients возвращает сотрудников, которым ещё не начислено
+// пособие за период. Используется для предварительного просмотра.
+func (q *Queries) ListAllowanceRecipients(ctx context.Context, period pgtype.Date) ([]ListAllowanceRecipientsRow, error) {
+	rows, err := q.db.Query(ctx, listAllowanceRecipients, period)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListAllowanceRecipientsRow
+	for rows.Next() {
+		var i ListAllowanceRecipientsRow
+		if err := rows.Scan(&i.ID, &i.Username); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -0,0 +1,128 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.27.0
+// source: cart.sql
+
+package db
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const addCartItem = `-- name: AddCartItem :execrows
+INSERT INTO cart_items (employee_id, merch_id, quantity)
+VALUES ($1, $2, $3)
+ON CONFLICT (employee_id, merch_id)
+DO UPDATE SET quantity = cart_items.quantity + EXCLUDED.quantity
+WHERE cart_items.quantity + EXCLUDED.quantity <= $4::integer
+`
+
+type AddCartItemParams struct {
+	EmployeeID  int32
+	MerchID     int32
+	Quantity    int32
+	MaxQuantity int32
+}
+
+// AddCartItem добавляет товар в корзину сотрудника или увеличивает количество,
+// если товар уже лежит в корзине. Количество позиции не может превысить
+// max_quantity: такое добавление не меняет строку и возвращает 0.
+func (q *Queries) AddCartItem(ctx context.Context, arg AddCartItemParams) (int64, error) {
+	result, err := q.db.Exec(ctx, addCartItem,
+		arg.EmployeeID,
+		arg.MerchID,
+		arg.Quantity,
+		arg.MaxQuantity,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const clearCart = `-- name: ClearCart :exec
+DELETE FROM cart_items
+WHERE employee_id = $1
+`
+
+// ----------------------------------------------------------
+// ClearCart очищает корзину сотрудника после оформления заказа.
+func (q *Queries) ClearCart(ctx context.Context, employeeID int32) error {
+	_, err := q.db.Exec(ctx, clearCart, employeeID)
+	return err
+}
+
+const listCartItems = `-- name: ListCartItems :many
+SELECT 
+  c.merch_id,
+  m.name AS merch_name,
+  m.price,
+  m.active,
+  m.stock,
+  c.quantity
+FROM cart_items c
+JOIN merch m ON c.merch_id = m.id
+WHERE c.employee_id = $1
+ORDER BY c.merch_id
+`
+
+type ListCartItemsRow struct {
+	MerchID   int32
+	MerchName string
+	Price     int32
+	Active    bool
+	Stock     pgtype.Int4
+	Quantity  int32
+}
+
+// ----------------------------------------------------------
+// ListCartItems возвращает позиции корзины с текущими ценами товаров.
+// Порядок по merch_id задаёт единый порядок блокировки строк merch при оформлении.
+func (q *Queries) ListCartItems(ctx context.Context, employeeID int32) ([]ListCartItemsRow, error) {
+	rows, err := q.db.Query(ctx, listCartItems, employeeID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListCartItemsRow
+	for rows.Next() {
+		var i ListCartItemsRow
+		if err := rows.Scan(
+			&i.MerchID,
+			&i.MerchName,
+			&i.Price,
+			&i.Active,
+			&i.Stock,
+			&i.Quantity,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const removeCartItem = `-- name: RemoveCartItem :execrows
+DELETE FROM cart_items
+WHERE employee_id = $1 AND merch_id = $2
+`
+
+type RemoveCartItemParams struct {
+	EmployeeID int32
+	MerchID    int32
+}
+
+// ----------------------------------------------------------
+// RemoveCartItem удаляет позицию из корзины.
+func (q *Queries) RemoveCartItem(ctx context.Context, arg RemoveCartItemParams) (int64, error) {
+	result, err := q.db.Exec(ctx, removeCartItem, arg.EmployeeID, arg.MerchID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
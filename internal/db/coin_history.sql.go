@@ -11,26 +11,92 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const getCoinAdjustments = `-- name: GetCoinAdjustments :many
+SELECT
+  ct.transaction_type,
+  (CASE WHEN ct.transaction_type IN ('adjustment', 'expiry', 'hold') THEN -ct.amount ELSE ct.amount END)::integer AS amount,
+  COALESCE(ct.reason, '')::text AS reason,
+  ct.created_at
+FROM coin_transactions ct
+WHERE (ct.transaction_type IN ('grant', 'allowance', 'hold_release', 'hold_refund') AND ct.to_employee_id = $1::integer)
+   OR (ct.transaction_type IN ('adjustment', 'expiry', 'hold') AND ct.from_employee_id = $1::integer)
+ORDER BY ct.created_at DESC, ct.id DESC
+LIMIT $2
+`
+
+type GetCoinAdjustmentsParams struct {
+	EmployeeID int32
+	RowLimit   int32
+}
+
+type GetCoinAdjustmentsRow struct {
+	TransactionType TransactionTypeEnum
+	Amount          int32
+	Reason          string
+	CreatedAt       pgtype.Timestamptz
+}
+
+// ----------------------------------------------------------
+// GetCoinAdjustments возвращает начисления и списания администраторами,
+// ежемесячные пособия, сгоревшие монеты и движения удержанных переводов
+// (удержание, зачисление получателю, возврат отправителю). Суммы списаний
+// возвращаются отрицательными; возвращаются только row_limit последних операций.
+func (q *Queries) GetCoinAdjustments(ctx context.Context, arg GetCoinAdjustmentsParams) ([]GetCoinAdjustmentsRow, error) {
+	rows, err := q.db.Query(ctx, getCoinAdjustments, arg.EmployeeID, arg.RowLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetCoinAdjustmentsRow
+	for rows.Next() {
+		var i GetCoinAdjustmentsRow
+		if err := rows.Scan(
+			&i.TransactionType,
+			&i.Amount,
+			&i.Reason,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getReceivedTransfers = `-- name: GetReceivedTransfers :many
 SELECT 
   ct.amount,
-  e.username AS from_user
+  e.username AS from_user,
+  COALESCE(ct.message, '')::text AS message,
+  ct.hashtags
 FROM coin_transactions ct
 JOIN employees e ON ct.from_employee_id = e.id
 WHERE ct.transaction_type = 'transfer'
-  AND ct.to_employee_id = $1
-ORDER BY ct.created_at DESC
+  AND ct.to_employee_id = $1::integer
+ORDER BY ct.created_at DESC, ct.id DESC
+LIMIT $2
 `
 
+type GetReceivedTransfersParams struct {
+	ToEmployeeID int32
+	RowLimit     int32
+}
+
 type GetReceivedTransfersRow struct {
 	Amount   int32
 	FromUser string
+	Message  string
+	Hashtags []string
 }
 
 // GetReceivedTransfers возвращает историю переводов (монеты, полученные сотрудником).
-// Для каждого перевода возвращается сумма и имя отправителя.
-func (q *Queries) GetReceivedTransfers(ctx context.Context, toEmployeeID pgtype.Int4) ([]GetReceivedTransfersRow, error) {
-	rows, err := q.db.Query(ctx, getReceivedTransfers, toEmployeeID)
+// Для каждого перевода возвращается сумма, имя отправителя, сообщение и хэштеги;
+// возвращаются только row_limit последних переводов.
+func (q *Queries) GetReceivedTransfers(ctx context.Context, arg GetReceivedTransfersParams) ([]GetReceivedTransfersRow, error) {
+	rows, err := q.db.Query(ctx, getReceivedTransfers, arg.ToEmployeeID, arg.RowLimit)
 	if err != nil {
 		return nil, err
 	}
@@ -38,7 +104,12 @@ func (q *Queries) GetReceivedTransfers(ctx context.Context, toEmployeeID pgtype.
 	var items []GetReceivedTransfersRow
 	for rows.Next() {
 		var i GetReceivedTransfersRow
-		if err := rows.Scan(&i.Amount, &i.FromUser); err != nil {
+		if err := rows.Scan(
+			&i.Amount,
+			&i.FromUser,
+			&i.Message,
+			&i.Hashtags,
+		); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -52,24 +123,35 @@ func (q *Queries) GetReceivedTransfers(ctx context.Context, toEmployeeID pgtype.
 const getSentTransfers = `-- name: GetSentTransfers :many
 SELECT 
   ct.amount,
-  e.username AS to_user
+  e.username AS to_user,
+  COALESCE(ct.message, '')::text AS message,
+  ct.hashtags
 FROM coin_transactions ct
 JOIN employees e ON ct.to_employee_id = e.id
 WHERE ct.transaction_type = 'transfer'
-  AND ct.from_employee_id = $1
-ORDER BY ct.created_at DESC
+  AND ct.from_employee_id = $1::integer
+ORDER BY ct.created_at DESC, ct.id DESC
+LIMIT $2
 `
 
+type GetSentTransfersParams struct {
+	FromEmployeeID int32
+	RowLimit       int32
+}
+
 type GetSentTransfersRow struct {
-	Amount int32
-	ToUser string
+	Amount   int32
+	ToUser   string
+	Message  string
+	Hashtags []string
 }
 
 // ----------------------------------------------------------
 // GetSentTransfers возвращает историю исходящих переводов (монеты, отправленные сотрудником).
-// Для каждого перевода возвращается сумма и имя получателя.
-func (q *Queries) GetSentTransfers(ctx context.Context, fromEmployeeID int32) ([]GetSentTransfersRow, error) {
-	rows, err := q.db.Query(ctx, getSentTransfers, fromEmployeeID)
+// Для каждого перевода возвращается сумма, имя получателя, сообщение и хэштеги;
+// возвращаются только row_limit последних переводов.
+func (q *Queries) GetSentTransfers(ctx context.Context, arg GetSentTransfersParams) ([]GetSentTransfersRow, error) {
+	rows, err := q.db.Query(ctx, getSentTransfers, arg.FromEmployeeID, arg.RowLimit)
 	if err != nil {
 		return nil, err
 	}
@@ -77,7 +159,128 @@ func (q *Queries) GetSentTransfers(ctx context.Context, fromEmployeeID int32) ([
 	var items []GetSentTransfersRow
 	for rows.Next() {
 		var i GetSentTransfersRow
-		if err := rows.Scan(&i.Amount, &i.ToUser); err != nil {
+		if err := rows.Scan(
+			&i.Amount,
+			&i.ToUser,
+			&i.Message,
+			&i.Hashtags,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listCoinHistory = `-- name: ListCoinHistory :many
+SELECT
+  ct.id,
+  ct.transaction_type,
+  (ct.to_employee_id IS NOT DISTINCT FROM $1::integer)::boolean AS incoming,
+  ct.amount,
+  COALESCE(cp.username, '')::text AS counterparty,
+  COALESCE(m.name, '')::text AS merch_name,
+  ct.quantity,
+  COALESCE(ct.message, '')::text AS message,
+  ct.hashtags,
+  COALESCE(ct.reason, '')::text AS reason,
+  ct.created_at
+FROM coin_transactions ct
+LEFT JOIN merch m ON m.id = ct.merch_id
+LEFT JOIN transfer_holds h ON h.id = ct.hold_id
+LEFT JOIN employees cp ON cp.id = CASE
+  WHEN ct.transaction_type = 'hold_release' THEN h.sender_id
+  WHEN ct.hold_id IS NOT NULL THEN h.recipient_id
+  WHEN ct.to_employee_id = $1::integer THEN ct.from_employee_id
+  ELSE ct.to_employee_id
+END
+WHERE (ct.from_employee_id = $1::integer OR ct.to_employee_id = $1::integer)
+  AND ($2::boolean IS NULL
+       OR (ct.to_employee_id IS NOT DISTINCT FROM $1::integer) = $2::boolean)
+  AND ($3::text IS NULL OR cp.username = $3::text)
+  AND ($4::text[] IS NULL OR ct.transaction_type::text = ANY($4::text[]))
+  AND ($5::timestamptz IS NULL OR ct.created_at >= $5::timestamptz)
+  AND ($6::timestamptz IS NULL OR ct.created_at < $6::timestamptz)
+  AND ($7::integer IS NULL OR ct.amount >= $7::integer)
+  AND ($8::integer IS NULL OR ct.amount <= $8::integer)
+  AND ($9::timestamptz IS NULL
+       OR (ct.created_at, ct.id) < ($9::timestamptz, $10::integer))
+ORDER BY ct.created_at DESC, ct.id DESC
+LIMIT $11
+`
+
+type ListCoinHistoryParams struct {
+	EmployeeID      int32
+	Incoming        pgtype.Bool
+	Counterparty    pgtype.Text
+	Types           []string
+	CreatedFrom     pgtype.Timestamptz
+	CreatedTo       pgtype.Timestamptz
+	MinAmount       pgtype.Int4
+	MaxAmount       pgtype.Int4
+	CursorCreatedAt pgtype.Timestamptz
+	CursorID        pgtype.Int4
+	RowLimit        int32
+}
+
+type ListCoinHistoryRow struct {
+	ID              int32
+	TransactionType TransactionTypeEnum
+	Incoming        bool
+	Amount          int32
+	Counterparty    string
+	MerchName       string
+	Quantity        int32
+	Message         string
+	Hashtags        []string
+	Reason          string
+	CreatedAt       pgtype.Timestamptz
+}
+
+// ----------------------------------------------------------
+// ListCoinHistory возвращает страницу истории операций сотрудника всех типов,
+// новые первыми. incoming — монеты зачислены сотруднику, иначе списаны. Для
+// удержанных переводов контрагентом считается вторая сторона перевода, для
+// покупок, начислений и сгорания контрагента нет. Для покупок и возвратов
+// возвращаются название товара и количество. Страница продолжается после
+// операции (cursor_created_at, cursor_id); фильтры со значением NULL не применяются.
+func (q *Queries) ListCoinHistory(ctx context.Context, arg ListCoinHistoryParams) ([]ListCoinHistoryRow, error) {
+	rows, err := q.db.Query(ctx, listCoinHistory,
+		arg.EmployeeID,
+		arg.Incoming,
+		arg.Counterparty,
+		arg.Types,
+		arg.CreatedFrom,
+		arg.CreatedTo,
+		arg.MinAmount,
+		arg.MaxAmount,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.RowLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListCoinHistoryRow
+	for rows.Next() {
+		var i ListCoinHistoryRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.TransactionType,
+			&i.Incoming,
+			&i.Amount,
+			&i.Counterparty,
+			&i.MerchName,
+			&i.Quantity,
+			&i.Message,
+			&i.Hashtags,
+			&i.Reason,
+			&i.CreatedAt,
+		); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -0,0 +1,350 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.27.0
+// source: coin_lots.sql
+
+package db
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const consumeCoinLots = `-- name: ConsumeCoinLots :many
+WITH locked AS (
+  SELECT id, remaining, granted_at, expires_at
+  FROM coin_lots
+  WHERE employee_id = $1::integer AND remaining > 0
+  ORDER BY expires_at, id
+  FOR UPDATE
+),
+ordered AS (
+  SELECT id, remaining, granted_at, expires_at,
+         SUM(remaining) OVER (ORDER BY expires_at, id) - remaining AS consumed_before
+  FROM locked
+),
+taken AS (
+  SELECT id, granted_at, expires_at,
+         LEAST(remaining, $2::integer - consumed_before)::integer AS taken
+  FROM ordered
+  WHERE consumed_before < $2::integer
+)
+UPDATE coin_lots l
+SET remaining = l.remaining - taken.taken
+FROM taken
+WHERE l.id = taken.id
+RETURNING l.id, taken.taken, taken.granted_at, taken.expires_at
+`
+
+type ConsumeCoinLotsParams struct {
+	EmployeeID int32
+	Amount     int32
+}
+
+type ConsumeCoinLotsRow struct {
+	ID        int32
+	Taken     int32
+	GrantedAt pgtype.Timestamptz
+	ExpiresAt pgtype.Timestamptz
+}
+
+// ----------------------------------------------------------
+// ConsumeCoinLots списывает amount монет из партий сотрудника, начиная
+// с ближайшего срока действия (FIFO). Партии блокируются до расчёта;
+// строка сотрудника к этому моменту уже должна быть заблокирована.
+// Возвращает израсходованные части партий.
+func (q *Queries) ConsumeCoinLots(ctx context.Context, arg ConsumeCoinLotsParams) ([]ConsumeCoinLotsRow, error) {
+	rows, err := q.db.Query(ctx, consumeCoinLots, arg.EmployeeID, arg.Amount)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ConsumeCoinLotsRow
+	for rows.Next() {
+		var i ConsumeCoinLotsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Taken,
+			&i.GrantedAt,
+			&i.ExpiresAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const createCoinLot = `-- name: CreateCoinLot :exec
+INSERT INTO coin_lots (employee_id, amount, remaining)
+VALUES ($1::integer, $2::integer, $2::integer)
+`
+
+type CreateCoinLotParams struct {
+	EmployeeID int32
+	Amount     int32
+}
+
+// CreateCoinLot создаёт партию монет со сроком действия 12 месяцев.
+func (q *Queries) CreateCoinLot(ctx context.Context, arg CreateCoinLotParams) error {
+	_, err := q.db.Exec(ctx, createCoinLot, arg.EmployeeID, arg.Amount)
+	return err
+}
+
+const createCoinLotWithExpiry = `-- name: CreateCoinLotWithExpiry :exec
+INSERT INTO coin_lots (employee_id, amount, remaining, granted_at, expires_at)
+VALUES (
+  $1::integer,
+  $2::integer,
+  $2::integer,
+  $3::timestamptz,
+  $4::timestamptz
+)
+`
+
+type CreateCoinLotWithExpiryParams struct {
+	EmployeeID int32
+	Amount     int32
+	GrantedAt  pgtype.Timestamptz
+	ExpiresAt  pgtype.Timestamptz
+}
+
+// ----------------------------------------------------------
+// CreateCoinLotWithExpiry создаёт партию с заданными датами. Используется при
+// переводе: полученные монеты сохраняют срок действия исходной партии.
+func (q *Queries) CreateCoinLotWithExpiry(ctx context.Context, arg CreateCoinLotWithExpiryParams) error {
+	_, err := q.db.Exec(ctx, createCoinLotWithExpiry,
+		arg.EmployeeID,
+		arg.Amount,
+		arg.GrantedAt,
+		arg.ExpiresAt,
+	)
+	return err
+}
+
+const createPurchaseLot = `-- name: CreatePurchaseLot :exec
+INSERT INTO purchase_lots (purchase_id, amount, remaining, granted_at, expires_at)
+VALUES (
+  $1::integer,
+  $2::integer,
+  $2::integer,
+  $3::timestamptz,
+  $4::timestamptz
+)
+`
+
+type CreatePurchaseLotParams struct {
+	PurchaseID int32
+	Amount     int32
+	GrantedAt  pgtype.Timestamptz
+	ExpiresAt  pgtype.Timestamptz
+}
+
+// ----------------------------------------------------------
+// CreatePurchaseLot запоминает часть партии, израсходованную на покупку.
+func (q *Queries) CreatePurchaseLot(ctx context.Context, arg CreatePurchaseLotParams) error {
+	_, err := q.db.Exec(ctx, createPurchaseLot,
+		arg.PurchaseID,
+		arg.Amount,
+		arg.GrantedAt,
+		arg.ExpiresAt,
+	)
+	return err
+}
+
+const expireCoinLots = `-- name: ExpireCoinLots :many
+WITH stale AS (
+  SELECT l.id, l.employee_id, l.remaining
+  FROM coin_lots l
+  JOIN employees e ON e.id = l.employee_id
+  WHERE l.expires_at <= NOW() AND l.remaining > 0
+  ORDER BY l.id
+  LIMIT $1::integer
+  FOR UPDATE OF l, e SKIP LOCKED
+),
+cleared AS (
+  UPDATE coin_lots l
+  SET remaining = 0
+  FROM stale
+  WHERE l.id = stale.id
+  RETURNING stale.employee_id, stale.remaining
+),
+totals AS (
+  SELECT employee_id, SUM(remaining)::integer AS amount
+  FROM cleared
+  GROUP BY employee_id
+),
+debited AS (
+  UPDATE employees e
+  SET coins = e.coins - totals.amount
+  FROM totals
+  WHERE e.id = totals.employee_id
+),
+expired AS (
+  INSERT INTO coin_transactions (transaction_type, from_employee_id, amount)
+  SELECT 'expiry', totals.employee_id, totals.amount
+  FROM totals
+  RETURNING id, from_employee_id, amount
+),
+entries AS (
+  INSERT INTO journal_entries (entry_type, coin_transaction_id)
+  SELECT 'expiry', expired.id FROM expired
+  RETURNING id, coin_transaction_id
+),
+postings AS (
+  INSERT INTO ledger_postings (entry_id, account_id, amount)
+  SELECT entries.id, leg.account_id, leg.amount
+  FROM entries
+  JOIN expired ON expired.id = entries.coin_transaction_id,
+  LATERAL (VALUES
+    ((SELECT id FROM ledger_accounts WHERE employee_id = expired.from_employee_id), -expired.amount),
+    ((SELECT id FROM ledger_accounts WHERE code = 'expired'), expired.amount)
+  ) AS leg(account_id, amount)
+)
+SELECT from_employee_id::integer AS employee_id, amount FROM expired
+`
+
+type ExpireCoinLotsRow struct {
+	EmployeeID int32
+	Amount     int32
+}
+
+// ----------------------------------------------------------
+// ExpireCoinLots обнуляет очередную порцию партий с истёкшим сроком,
+// уменьшает балансы и записывает по одной транзакции expiry на сотрудника
+// с проводкой на счёт сгоревших монет.
+// Партии блокируются вместе со строками их владельцев. Если заблокирована
+// партия или сотрудник (например, идёт перевод), партия пропускается до
+// следующего запуска: запрос никогда не ждёт блокировок и не может попасть
+// во взаимную блокировку с переводами и покупками.
+func (q *Queries) ExpireCoinLots(ctx context.Context, batchSize int32) ([]ExpireCoinLotsRow, error) {
+	rows, err := q.db.Query(ctx, expireCoinLots, batchSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ExpireCoinLotsRow
+	for rows.Next() {
+		var i ExpireCoinLotsRow
+		if err := rows.Scan(&i.EmployeeID, &i.Amount); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getUpcomingExpirations = `-- name: GetUpcomingExpirations :many
+SELECT
+  date_trunc('day', expires_at)::timestamptz AS expires_on,
+  SUM(remaining)::integer AS amount
+FROM coin_lots
+WHERE employee_id = $1::integer
+  AND remaining > 0
+  AND expires_at <= NOW() + INTERVAL '90 days'
+GROUP BY 1
+ORDER BY 1
+`
+
+type GetUpcomingExpirationsRow struct {
+	ExpiresOn pgtype.Timestamptz
+	Amount    int32
+}
+
+// ----------------------------------------------------------
+// GetUpcomingExpirations возвращает монеты сотрудника, срок действия которых
+// истекает в ближайшие 90 дней, с группировкой по дню истечения.
+func (q *Queries) GetUpcomingExpirations(ctx context.Context, employeeID int32) ([]GetUpcomingExpirationsRow, error) {
+	rows, err := q.db.Query(ctx, getUpcomingExpirations, employeeID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetUpcomingExpirationsRow
+	for rows.Next() {
+		var i GetUpcomingExpirationsRow
+		if err := rows.Scan(&i.ExpiresOn, &i.Amount); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const takePurchaseLots = `-- name: TakePurchaseLots :many
+WITH locked AS (
+  SELECT id, remaining, granted_at, expires_at
+  FROM purchase_lots
+  WHERE purchase_id = $1::integer AND remaining > 0
+  ORDER BY expires_at, id
+  FOR UPDATE
+),
+ordered AS (
+  SELECT id, remaining, granted_at, expires_at,
+         SUM(remaining) OVER (ORDER BY expires_at, id) - remaining AS taken_before
+  FROM locked
+),
+taken AS (
+  SELECT id, granted_at, expires_at,
+         LEAST(remaining, $2::integer - taken_before)::integer AS taken
+  FROM ordered
+  WHERE taken_before < $2::integer
+)
+UPDATE purchase_lots p
+SET remaining = p.remaining - taken.taken
+FROM taken
+WHERE p.id = taken.id
+RETURNING p.id, taken.taken, taken.granted_at, taken.expires_at
+`
+
+type TakePurchaseLotsParams struct {
+	PurchaseID int32
+	Amount     int32
+}
+
+type TakePurchaseLotsRow struct {
+	ID        int32
+	Taken     int32
+	GrantedAt pgtype.Timestamptz
+	ExpiresAt pgtype.Timestamptz
+}
+
+// ----------------------------------------------------------
+// TakePurchaseLots забирает amount монет из ещё не возвращённых частей партий
+// покупки в том порядке, в котором они были израсходованы. Возвращает взятые
+// части с исходными сроками действия; если частей не хватает (покупка сделана
+// до их учёта), сумма взятого меньше amount.
+func (q *Queries) TakePurchaseLots(ctx context.Context, arg TakePurchaseLotsParams) ([]TakePurchaseLotsRow, error) {
+	rows, err := q.db.Query(ctx, takePurchaseLots, arg.PurchaseID, arg.Amount)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []TakePurchaseLotsRow
+	for rows.Next() {
+		var i TakePurchaseLotsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Taken,
+			&i.GrantedAt,
+			&i.ExpiresAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
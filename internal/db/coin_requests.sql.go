@@ -0,0 +1,212 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.27.0
+// source: coin_requests.sql
+
+package db
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const createCoinRequest = `-- name: CreateCoinRequest :one
+INSERT INTO coin_requests (requester_id, payer_id, amount, note, expires_at)
+VALUES (
+  $1::integer,
+  $2::integer,
+  $3::integer,
+  $4::text,
+  $5::timestamptz
+)
+RETURNING id, requester_id, payer_id, amount, note, status, created_at, expires_at, resolved_at
+`
+
+type CreateCoinRequestParams struct {
+	RequesterID int32
+	PayerID     int32
+	Amount      int32
+	Note        string
+	ExpiresAt   pgtype.Timestamptz
+}
+
+// CreateCoinRequest создаёт ожидающий запрос монет.
+func (q *Queries) CreateCoinRequest(ctx context.Context, arg CreateCoinRequestParams) (CoinRequest, error) {
+	row := q.db.QueryRow(ctx, createCoinRequest,
+		arg.RequesterID,
+		arg.PayerID,
+		arg.Amount,
+		arg.Note,
+		arg.ExpiresAt,
+	)
+	var i CoinRequest
+	err := row.Scan(
+		&i.ID,
+		&i.RequesterID,
+		&i.PayerID,
+		&i.Amount,
+		&i.Note,
+		&i.Status,
+		&i.CreatedAt,
+		&i.ExpiresAt,
+		&i.ResolvedAt,
+	)
+	return i, err
+}
+
+const expireCoinRequests = `-- name: ExpireCoinRequests :execrows
+UPDATE coin_requests
+SET
+  status = 'expired',
+  resolved_at = expires_at
+WHERE status = 'pending' AND expires_at <= NOW()
+`
+
+// ----------------------------------------------------------
+// ExpireCoinRequests закрывает ожидающие запросы с истёкшим сроком.
+func (q *Queries) ExpireCoinRequests(ctx context.Context) (int64, error) {
+	result, err := q.db.Exec(ctx, expireCoinRequests)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const getCoinRequestForUpdate = `-- name: GetCoinRequestForUpdate :one
+SELECT
+  id,
+  requester_id,
+  payer_id,
+  amount,
+  note,
+  status,
+  created_at,
+  expires_at,
+  resolved_at
+FROM coin_requests
+WHERE id = $1
+FOR UPDATE
+`
+
+// ----------------------------------------------------------
+// GetCoinRequestForUpdate возвращает запрос и блокирует его до конца транзакции.
+func (q *Queries) GetCoinRequestForUpdate(ctx context.Context, id int32) (CoinRequest, error) {
+	row := q.db.QueryRow(ctx, getCoinRequestForUpdate, id)
+	var i CoinRequest
+	err := row.Scan(
+		&i.ID,
+		&i.RequesterID,
+		&i.PayerID,
+		&i.Amount,
+		&i.Note,
+		&i.Status,
+		&i.CreatedAt,
+		&i.ExpiresAt,
+		&i.ResolvedAt,
+	)
+	return i, err
+}
+
+const listCoinRequests = `-- name: ListCoinRequests :many
+SELECT
+  r.id,
+  requester.username AS requester,
+  payer.username AS payer,
+  r.amount,
+  r.note,
+  (CASE WHEN r.status = 'pending' AND r.expires_at <= NOW() THEN 'expired' ELSE r.status END)::coin_request_status_enum AS status,
+  r.created_at,
+  r.expires_at,
+  r.resolved_at
+FROM coin_requests r
+JOIN employees requester ON requester.id = r.requester_id
+JOIN employees payer ON payer.id = r.payer_id
+WHERE ($1::integer IS NULL OR r.id = $1::integer)
+  AND ($2::integer IS NULL OR r.requester_id = $2::integer)
+  AND ($3::integer IS NULL OR r.payer_id = $3::integer)
+  AND (
+    $4::coin_request_status_enum IS NULL
+    OR (CASE WHEN r.status = 'pending' AND r.expires_at <= NOW() THEN 'expired' ELSE r.status END) = $4::coin_request_status_enum
+  )
+ORDER BY r.id DESC
+LIMIT 100
+`
+
+type ListCoinRequestsParams struct {
+	ID          pgtype.Int4
+	RequesterID pgtype.Int4
+	PayerID     pgtype.Int4
+	Status      NullCoinRequestStatusEnum
+}
+
+type ListCoinRequestsRow struct {
+	ID         int32
+	Requester  string
+	Payer      string
+	Amount     int32
+	Note       string
+	Status     CoinRequestStatusEnum
+	CreatedAt  pgtype.Timestamptz
+	ExpiresAt  pgtype.Timestamptz
+	ResolvedAt pgtype.Timestamptz
+}
+
+// ----------------------------------------------------------
+// ListCoinRequests возвращает последние 100 запросов монет. NULL в фильтре
+// означает отсутствие ограничения по этому полю. Ожидающий запрос с истёкшим
+// сроком показывается как expired, даже если фоновая задача его ещё не закрыла.
+func (q *Queries) ListCoinRequests(ctx context.Context, arg ListCoinRequestsParams) ([]ListCoinRequestsRow, error) {
+	rows, err := q.db.Query(ctx, listCoinRequests,
+		arg.ID,
+		arg.RequesterID,
+		arg.PayerID,
+		arg.Status,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListCoinRequestsRow
+	for rows.Next() {
+		var i ListCoinRequestsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Requester,
+			&i.Payer,
+			&i.Amount,
+			&i.Note,
+			&i.Status,
+			&i.CreatedAt,
+			&i.ExpiresAt,
+			&i.ResolvedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const resolveCoinRequest = `-- name: ResolveCoinRequest :exec
+UPDATE coin_requests
+SET
+  status = $1,
+  resolved_at = NOW()
+WHERE id = $2 AND status = 'pending'
+`
+
+type ResolveCoinRequestParams struct {
+	Status CoinRequestStatusEnum
+	ID     int32
+}
+
+// ----------------------------------------------------------
+// ResolveCoinRequest закрывает ожидающий запрос с указанным решением.
+func (q *Queries) ResolveCoinRequest(ctx context.Context, arg ResolveCoinRequestParams) error {
+	_, err := q.db.Exec(ctx, resolveCoinRequest, arg.Status, arg.ID)
+	return err
+}
@@ -11,39 +11,183 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
-const createCoinTransactionPurchase = `-- name: CreateCoinTransactionPurchase :exec
-INSERT INTO coin_transactions (transaction_type, from_employee_id, merch_id, amount)
-VALUES ('purchase', $1, $2, $3)
+const createCoinTransactionAdjustment = `-- name: CreateCoinTransactionAdjustment :one
+WITH tx AS (
+  INSERT INTO coin_transactions (transaction_type, from_employee_id, amount, reason, created_by)
+  VALUES ('adjustment', $1::integer, $2, $3::text, $4::integer)
+  RETURNING id, from_employee_id, amount
+),
+entry AS (
+  INSERT INTO journal_entries (entry_type, coin_transaction_id)
+  SELECT 'adjustment', tx.id FROM tx
+  RETURNING id
+),
+postings AS (
+  INSERT INTO ledger_postings (entry_id, account_id, amount)
+  SELECT entry.id, leg.account_id, leg.amount
+  FROM entry, tx, LATERAL (VALUES
+    ((SELECT id FROM ledger_accounts WHERE employee_id = tx.from_employee_id), -tx.amount),
+    ((SELECT id FROM ledger_accounts WHERE code = 'issuance'), tx.amount)
+  ) AS leg(account_id, amount)
+)
+SELECT id FROM tx
+`
+
+type CreateCoinTransactionAdjustmentParams struct {
+	FromEmployeeID int32
+	Amount         int32
+	Reason         string
+	CreatedBy      int32
+}
+
+// ----------------------------------------------------------
+// CreateCoinTransactionAdjustment записывает списание монет администратором:
+// монеты возвращаются на счёт выпуска.
+func (q *Queries) CreateCoinTransactionAdjustment(ctx context.Context, arg CreateCoinTransactionAdjustmentParams) (int32, error) {
+	row := q.db.QueryRow(ctx, createCoinTransactionAdjustment,
+		arg.FromEmployeeID,
+		arg.Amount,
+		arg.Reason,
+		arg.CreatedBy,
+	)
+	var id int32
+	err := row.Scan(&id)
+	return id, err
+}
+
+const createCoinTransactionGrant = `-- name: CreateCoinTransactionGrant :one
+WITH tx AS (
+  INSERT INTO coin_transactions (transaction_type, to_employee_id, amount, reason, created_by)
+  VALUES ('grant', $1::integer, $2, $3::text, $4::integer)
+  RETURNING id, to_employee_id, amount
+),
+entry AS (
+  INSERT INTO journal_entries (entry_type, coin_transaction_id)
+  SELECT 'grant', tx.id FROM tx
+  RETURNING id
+),
+postings AS (
+  INSERT INTO ledger_postings (entry_id, account_id, amount)
+  SELECT entry.id, leg.account_id, leg.amount
+  FROM entry, tx, LATERAL (VALUES
+    ((SELECT id FROM ledger_accounts WHERE code = 'issuance'), -tx.amount),
+    ((SELECT id FROM ledger_accounts WHERE employee_id = tx.to_employee_id), tx.amount)
+  ) AS leg(account_id, amount)
+)
+SELECT id FROM tx
+`
+
+type CreateCoinTransactionGrantParams struct {
+	ToEmployeeID int32
+	Amount       int32
+	Reason       string
+	CreatedBy    int32
+}
+
+// ----------------------------------------------------------
+// CreateCoinTransactionGrant записывает начисление монет администратором
+// со счёта выпуска.
+func (q *Queries) CreateCoinTransactionGrant(ctx context.Context, arg CreateCoinTransactionGrantParams) (int32, error) {
+	row := q.db.QueryRow(ctx, createCoinTransactionGrant,
+		arg.ToEmployeeID,
+		arg.Amount,
+		arg.Reason,
+		arg.CreatedBy,
+	)
+	var id int32
+	err := row.Scan(&id)
+	return id, err
+}
+
+const createCoinTransactionPurchase = `-- name: CreateCoinTransactionPurchase :one
+WITH tx AS (
+  INSERT INTO coin_transactions (transaction_type, from_employee_id, merch_id, amount, quantity, unit_price)
+  VALUES ('purchase', $1::integer, $2, $3, $4, $5)
+  RETURNING id, from_employee_id, amount
+),
+entry AS (
+  INSERT INTO journal_entries (entry_type, coin_transaction_id)
+  SELECT 'purchase', tx.id FROM tx
+  RETURNING id
+),
+postings AS (
+  INSERT INTO ledger_postings (entry_id, account_id, amount)
+  SELECT entry.id, leg.account_id, leg.amount
+  FROM entry, tx, LATERAL (VALUES
+    ((SELECT id FROM ledger_accounts WHERE employee_id = tx.from_employee_id), -tx.amount),
+    ((SELECT id FROM ledger_accounts WHERE code = 'store_revenue'), tx.amount)
+  ) AS leg(account_id, amount)
+)
+SELECT id FROM tx
 `
 
 type CreateCoinTransactionPurchaseParams struct {
 	FromEmployeeID int32
 	MerchID        pgtype.Int4
 	Amount         int32
+	Quantity       int32
+	UnitPrice      pgtype.Int4
 }
 
 // ----------------------------------------------------------
 // CreateCoinTransactionPurchase вставляет запись о покупке мерча.
-// $1 - id сотрудника (покупателя), $2 - id мерча, $3 - сумма (обычно равна цене товара).
-func (q *Queries) CreateCoinTransactionPurchase(ctx context.Context, arg CreateCoinTransactionPurchaseParams) error {
-	_, err := q.db.Exec(ctx, createCoinTransactionPurchase, arg.FromEmployeeID, arg.MerchID, arg.Amount)
-	return err
+// $1 - id сотрудника (покупателя), $2 - id мерча, $3 - общая сумма,
+// $4 - количество единиц, $5 - цена за единицу на момент покупки.
+// Монеты переходят на счёт выручки магазина.
+// Возвращает id покупки, по которому создаётся заказ на выдачу.
+func (q *Queries) CreateCoinTransactionPurchase(ctx context.Context, arg CreateCoinTransactionPurchaseParams) (int32, error) {
+	row := q.db.QueryRow(ctx, createCoinTransactionPurchase,
+		arg.FromEmployeeID,
+		arg.MerchID,
+		arg.Amount,
+		arg.Quantity,
+		arg.UnitPrice,
+	)
+	var id int32
+	err := row.Scan(&id)
+	return id, err
 }
 
 const createCoinTransactionTransfer = `-- name: CreateCoinTransactionTransfer :exec
-INSERT INTO coin_transactions (transaction_type, from_employee_id, to_employee_id, amount)
-VALUES ('transfer', $1, $2, $3)
+WITH tx AS (
+  INSERT INTO coin_transactions (transaction_type, from_employee_id, to_employee_id, amount, message, hashtags)
+  VALUES ('transfer', $1::integer, $2, $3, $4::text, $5::text[])
+  RETURNING id, from_employee_id, to_employee_id, amount
+),
+entry AS (
+  INSERT INTO journal_entries (entry_type, coin_transaction_id)
+  SELECT 'transfer', tx.id FROM tx
+  RETURNING id
+)
+INSERT INTO ledger_postings (entry_id, account_id, amount)
+SELECT entry.id, leg.account_id, leg.amount
+FROM entry, tx, LATERAL (VALUES
+  ((SELECT id FROM ledger_accounts WHERE employee_id = tx.from_employee_id), -tx.amount),
+  ((SELECT id FROM ledger_accounts WHERE employee_id = tx.to_employee_id), tx.amount)
+) AS leg(account_id, amount)
 `
 
 type CreateCoinTransactionTransferParams struct {
 	FromEmployeeID int32
 	ToEmployeeID   pgtype.Int4
 	Amount         int32
+	Message        pgtype.Text
+	Hashtags       []string
 }
 
 // CreateCoinTransactionTransfer вставляет запись о переводе монет между сотрудниками.
-// $1 - id отправителя, $2 - id получателя, $3 - сумма перевода.
+// $1 - id отправителя, $2 - id получателя, $3 - сумма перевода,
+// $4 - сообщение (NULL, если не указано), $5 - хэштеги из сообщения.
+// Каждая запись истории сопровождается проводкой в книге в том же запросе,
+// поэтому история и книга не расходятся; отсутствующий счёт даёт NULL
+// в account_id и отклоняет весь запрос.
 func (q *Queries) CreateCoinTransactionTransfer(ctx context.Context, arg CreateCoinTransactionTransferParams) error {
-	_, err := q.db.Exec(ctx, createCoinTransactionTransfer, arg.FromEmployeeID, arg.ToEmployeeID, arg.Amount)
+	_, err := q.db.Exec(ctx, createCoinTransactionTransfer,
+		arg.FromEmployeeID,
+		arg.ToEmployeeID,
+		arg.Amount,
+		arg.Message,
+		arg.Hashtags,
+	)
 	return err
 }
@@ -12,9 +12,34 @@ import (
 )
 
 const createEmployee = `-- name: CreateEmployee :one
-INSERT INTO employees (username, password_hash)
-VALUES ($1, $2)
-RETURNING id, username, coins, password_hash
+WITH created AS (
+  INSERT INTO employees (username, password_hash)
+  VALUES ($1, $2)
+  RETURNING id, username, coins, password_hash, role
+),
+lot AS (
+  INSERT INTO coin_lots (employee_id, amount, remaining)
+  SELECT created.id, created.coins, created.coins FROM created WHERE created.coins > 0
+),
+account AS (
+  INSERT INTO ledger_accounts (employee_id)
+  SELECT created.id FROM created
+  RETURNING id
+),
+entry AS (
+  INSERT INTO journal_entries (entry_type)
+  SELECT 'signup' FROM created WHERE created.coins > 0
+  RETURNING id
+),
+postings AS (
+  INSERT INTO ledger_postings (entry_id, account_id, amount)
+  SELECT entry.id, leg.account_id, leg.amount
+  FROM entry, created, account, LATERAL (VALUES
+    ((SELECT id FROM ledger_accounts WHERE code = 'issuance'), -created.coins),
+    (account.id, created.coins)
+  ) AS leg(account_id, amount)
+)
+SELECT id, username, coins, password_hash, role FROM created
 `
 
 type CreateEmployeeParams struct {
@@ -27,11 +52,14 @@ type CreateEmployeeRow struct {
 	Username     string
 	Coins        int32
 	PasswordHash string
+	Role         EmployeeRoleEnum
 }
 
 // ----------------------------------------------------------
 // CreateEmployee создаёт нового сотрудника с указанным username и password_hash.
-// При создании coins устанавливается значение по умолчанию (1000).
+// При создании coins устанавливается значение по умолчанию (1000);
+// стартовые монеты записываются партией со сроком действия. Вместе с сотрудником
+// открывается его счёт в книге, стартовые монеты проводятся со счёта выпуска.
 func (q *Queries) CreateEmployee(ctx context.Context, arg CreateEmployeeParams) (CreateEmployeeRow, error) {
 	row := q.db.QueryRow(ctx, createEmployee, arg.Username, arg.PasswordHash)
 	var i CreateEmployeeRow
@@ -40,6 +68,7 @@ func (q *Queries) CreateEmployee(ctx context.Context, arg CreateEmployeeParams)
 		&i.Username,
 		&i.Coins,
 		&i.PasswordHash,
+		&i.Role,
 	)
 	return i, err
 }
@@ -94,7 +123,8 @@ SELECT
   username,
   password_hash,
   coins,
-  created_at
+  created_at,
+  role
 FROM employees
 WHERE username = $1
 `
@@ -109,11 +139,113 @@ func (q *Queries) GetEmployeeByUsername(ctx context.Context, username string) (E
 		&i.PasswordHash,
 		&i.Coins,
 		&i.CreatedAt,
+		&i.Role,
+	)
+	return i, err
+}
+
+const getEmployeeRole = `-- name: GetEmployeeRole :one
+SELECT role
+FROM employees
+WHERE id = $1
+`
+
+// ----------------------------------------------------------
+// GetEmployeeRole возвращает текущую роль сотрудника по id.
+func (q *Queries) GetEmployeeRole(ctx context.Context, id int32) (EmployeeRoleEnum, error) {
+	row := q.db.QueryRow(ctx, getEmployeeRole, id)
+	var role EmployeeRoleEnum
+	err := row.Scan(&role)
+	return role, err
+}
+
+const lockEmployeeBalances = `-- name: LockEmployeeBalances :many
+SELECT id, coins
+FROM employees
+WHERE id = ANY($1::integer[])
+ORDER BY id
+FOR UPDATE
+`
+
+type LockEmployeeBalancesRow struct {
+	ID    int32
+	Coins int32
+}
+
+// ----------------------------------------------------------
+// LockEmployeeBalances блокирует строки нескольких сотрудников в порядке
+// возрастания id и возвращает их балансы. Единый порядок блокировок не даёт
+// встречным переводам взаимно заблокировать друг друга.
+func (q *Queries) LockEmployeeBalances(ctx context.Context, ids []int32) ([]LockEmployeeBalancesRow, error) {
+	rows, err := q.db.Query(ctx, lockEmployeeBalances, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []LockEmployeeBalancesRow
+	for rows.Next() {
+		var i LockEmployeeBalancesRow
+		if err := rows.Scan(&i.ID, &i.Coins); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const lockEmployeeCoins = `-- name: LockEmployeeCoins :one
+SELECT coins FROM employees WHERE id = $1 FOR UPDATE
+`
+
+// ----------------------------------------------------------
+// LockEmployeeCoins возвращает баланс сотрудника и блокирует его строку
+// до конца транзакции: параллельные списания ждут её завершения.
+func (q *Queries) LockEmployeeCoins(ctx context.Context, id int32) (int32, error) {
+	row := q.db.QueryRow(ctx, lockEmployeeCoins, id)
+	var coins int32
+	err := row.Scan(&coins)
+	return coins, err
+}
+
+const setEmployeeRole = `-- name: SetEmployeeRole :one
+UPDATE employees
+SET role = $2
+WHERE username = $1
+RETURNING id, username, coins, password_hash, role
+`
+
+type SetEmployeeRoleParams struct {
+	Username string
+	Role     EmployeeRoleEnum
+}
+
+type SetEmployeeRoleRow struct {
+	ID           int32
+	Username     string
+	Coins        int32
+	PasswordHash string
+	Role         EmployeeRoleEnum
+}
+
+// ----------------------------------------------------------
+// SetEmployeeRole назначает роль сотруднику по username.
+func (q *Queries) SetEmployeeRole(ctx context.Context, arg SetEmployeeRoleParams) (SetEmployeeRoleRow, error) {
+	row := q.db.QueryRow(ctx, setEmployeeRole, arg.Username, arg.Role)
+	var i SetEmployeeRoleRow
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.Coins,
+		&i.PasswordHash,
+		&i.Role,
 	)
 	return i, err
 }
 
-const updateEmployeeCoins = `-- name: UpdateEmployeeCoins :exec
+const updateEmployeeCoins = `-- name: UpdateEmployeeCoins :execrows
 UPDATE employees
 SET coins = coins + $2
 WHERE id = $1 AND coins + $2 >= 0
@@ -127,8 +259,12 @@ type UpdateEmployeeCoinsParams struct {
 // ----------------------------------------------------------
 // UpdateEmployeeCoins обновляет баланс сотрудника.
 // Параметр $2 может быть положительным (начисление) или отрицательным (списание).
-// Здесь добавлена проверка, чтобы новый баланс не стал отрицательным.
-func (q *Queries) UpdateEmployeeCoins(ctx context.Context, arg UpdateEmployeeCoinsParams) error {
-	_, err := q.db.Exec(ctx, updateEmployeeCoins, arg.ID, arg.Coins)
-	return err
+// Здесь добавлена проверка, чтобы новый баланс не стал отрицательным:
+// если списание невозможно, строка не обновляется и возвращается 0.
+func (q *Queries) UpdateEmployeeCoins(ctx context.Context, arg UpdateEmployeeCoinsParams) (int64, error) {
+	result, err := q.db.Exec(ctx, updateEmployeeCoins, arg.ID, arg.Coins)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
 }
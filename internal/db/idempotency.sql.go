@@ -0,0 +1,140 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.27.0
+// source: idempotency.sql
+
+package db
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const deleteExpiredIdempotencyKeys = `-- name: DeleteExpiredIdempotencyKeys :execrows
+DELETE FROM idempotency_keys
+WHERE created_at < $1
+`
+
+// ----------------------------------------------------------
+// DeleteExpiredIdempotencyKeys удаляет записи старше окна идемпотентности.
+func (q *Queries) DeleteExpiredIdempotencyKeys(ctx context.Context, createdAt pgtype.Timestamptz) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteExpiredIdempotencyKeys, createdAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteIdempotencyKey = `-- name: DeleteIdempotencyKey :exec
+DELETE FROM idempotency_keys
+WHERE employee_id = $1 AND idempotency_key = $2
+`
+
+type DeleteIdempotencyKeyParams struct {
+	EmployeeID     int32
+	IdempotencyKey string
+}
+
+// ----------------------------------------------------------
+// DeleteIdempotencyKey освобождает ключ, если запрос завершился ошибкой сервера
+// и его можно безопасно повторить.
+func (q *Queries) DeleteIdempotencyKey(ctx context.Context, arg DeleteIdempotencyKeyParams) error {
+	_, err := q.db.Exec(ctx, deleteIdempotencyKey, arg.EmployeeID, arg.IdempotencyKey)
+	return err
+}
+
+const getIdempotencyKey = `-- name: GetIdempotencyKey :one
+SELECT 
+  employee_id,
+  idempotency_key,
+  request_hash,
+  response_status,
+  response_body,
+  created_at
+FROM idempotency_keys
+WHERE employee_id = $1 AND idempotency_key = $2
+`
+
+type GetIdempotencyKeyParams struct {
+	EmployeeID     int32
+	IdempotencyKey string
+}
+
+// ----------------------------------------------------------
+// GetIdempotencyKey возвращает сохранённую запись по ключу.
+func (q *Queries) GetIdempotencyKey(ctx context.Context, arg GetIdempotencyKeyParams) (IdempotencyKey, error) {
+	row := q.db.QueryRow(ctx, getIdempotencyKey, arg.EmployeeID, arg.IdempotencyKey)
+	var i IdempotencyKey
+	err := row.Scan(
+		&i.EmployeeID,
+		&i.IdempotencyKey,
+		&i.RequestHash,
+		&i.ResponseStatus,
+		&i.ResponseBody,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const reserveIdempotencyKey = `-- name: ReserveIdempotencyKey :execrows
+INSERT INTO idempotency_keys (employee_id, idempotency_key, request_hash)
+VALUES ($1, $2, $3)
+ON CONFLICT (employee_id, idempotency_key)
+DO UPDATE SET
+  request_hash = EXCLUDED.request_hash,
+  response_status = NULL,
+  response_body = NULL,
+  created_at = NOW()
+WHERE idempotency_keys.created_at < $4
+`
+
+type ReserveIdempotencyKeyParams struct {
+	EmployeeID     int32
+	IdempotencyKey string
+	RequestHash    string
+	ExpiredBefore  pgtype.Timestamptz
+}
+
+// ReserveIdempotencyKey занимает ключ идемпотентности перед выполнением запроса.
+// Просроченная запись (created_at < expired_before) перезаписывается.
+// 0 затронутых строк означает, что ключ уже занят действующей записью.
+func (q *Queries) ReserveIdempotencyKey(ctx context.Context, arg ReserveIdempotencyKeyParams) (int64, error) {
+	result, err := q.db.Exec(ctx, reserveIdempotencyKey,
+		arg.EmployeeID,
+		arg.IdempotencyKey,
+		arg.RequestHash,
+		arg.ExpiredBefore,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const saveIdempotencyResponse = `-- name: SaveIdempotencyResponse :exec
+UPDATE idempotency_keys
+SET 
+  response_status = $3,
+  response_body = $4
+WHERE employee_id = $1 AND idempotency_key = $2
+`
+
+type SaveIdempotencyResponseParams struct {
+	EmployeeID     int32
+	IdempotencyKey string
+	ResponseStatus pgtype.Int4
+	ResponseBody   []byte
+}
+
+// ----------------------------------------------------------
+// SaveIdempotencyResponse сохраняет ответ на выполненный запрос.
+func (q *Queries) SaveIdempotencyResponse(ctx context.Context, arg SaveIdempotencyResponseParams) error {
+	_, err := q.db.Exec(ctx, saveIdempotencyResponse,
+		arg.EmployeeID,
+		arg.IdempotencyKey,
+		arg.ResponseStatus,
+		arg.ResponseBody,
+	)
+	return err
+}
@@ -55,6 +55,76 @@ func (q *Queries) GetInventoryByEmployeeID(ctx context.Context, employeeID int32
 	return items, nil
 }
 
+const getReceivedInventoryByEmployeeID = `-- name: GetReceivedInventoryByEmployeeID :many
+SELECT
+  m.name AS merch_name,
+  (i.quantity - COALESCE(p.pending, 0))::int AS quantity
+FROM inventory i
+JOIN merch m ON i.merch_id = m.id
+LEFT JOIN (
+  SELECT o.merch_id, SUM(o.quantity)::int AS pending
+  FROM orders o
+  WHERE o.employee_id = $1 AND o.status NOT IN ('delivered', 'cancelled')
+  GROUP BY o.merch_id
+) p ON p.merch_id = i.merch_id
+WHERE i.employee_id = $1
+  AND i.quantity - COALESCE(p.pending, 0) > 0
+ORDER BY m.name
+`
+
+type GetReceivedInventoryByEmployeeIDRow struct {
+	MerchName string
+	Quantity  int32
+}
+
+// ----------------------------------------------------------
+// GetReceivedInventoryByEmployeeID возвращает товары, которые сотрудник уже
+// получил на руки: инвентарь за вычетом заказов, ожидающих выдачи.
+func (q *Queries) GetReceivedInventoryByEmployeeID(ctx context.Context, employeeID int32) ([]GetReceivedInventoryByEmployeeIDRow, error) {
+	rows, err := q.db.Query(ctx, getReceivedInventoryByEmployeeID, employeeID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetReceivedInventoryByEmployeeIDRow
+	for rows.Next() {
+		var i GetReceivedInventoryByEmployeeIDRow
+		if err := rows.Scan(&i.MerchName, &i.Quantity); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const removeInventory = `-- name: RemoveInventory :execrows
+UPDATE inventory
+SET quantity = quantity - $1
+WHERE employee_id = $2
+  AND merch_id = $3
+  AND quantity >= $1
+`
+
+type RemoveInventoryParams struct {
+	Quantity   int32
+	EmployeeID int32
+	MerchID    int32
+}
+
+// ----------------------------------------------------------
+// RemoveInventory уменьшает количество товара в инвентаре сотрудника.
+// 0 затронутых строк означает, что у сотрудника нет столько единиц товара.
+func (q *Queries) RemoveInventory(ctx context.Context, arg RemoveInventoryParams) (int64, error) {
+	result, err := q.db.Exec(ctx, removeInventory, arg.Quantity, arg.EmployeeID, arg.MerchID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const upsertInventory = `-- name: UpsertInventory :exec
 INSERT INTO inventory (employee_id, merch_id, quantity)
 VALUES ($1, $2, $3)
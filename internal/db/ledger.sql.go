@@ -0,0 +1,114 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.27.0
+// source: ledger.sql
+
+package db
+
+import (
+	"context"
+)
+
+const countUnbalancedJournalEntries = `-- name: CountUnbalancedJournalEntries :one
+SELECT COUNT(*) FROM (
+  SELECT entry_id
+  FROM ledger_postings
+  GROUP BY entry_id
+  HAVING SUM(amount) <> 0
+) unbalanced
+`
+
+// ----------------------------------------------------------
+// CountUnbalancedJournalEntries считает проводки, сумма движений которых не равна нулю.
+func (q *Queries) CountUnbalancedJournalEntries(ctx context.Context) (int64, error) {
+	row := q.db.QueryRow(ctx, countUnbalancedJournalEntries)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const listLedgerBalanceMismatches = `-- name: ListLedgerBalanceMismatches :many
+SELECT
+  e.id,
+  e.username,
+  e.coins,
+  COALESCE(SUM(p.amount), 0)::bigint AS ledger_balance
+FROM employees e
+LEFT JOIN ledger_accounts a ON a.employee_id = e.id
+LEFT JOIN ledger_postings p ON p.account_id = a.id
+GROUP BY e.id, e.username, e.coins
+HAVING e.coins <> COALESCE(SUM(p.amount), 0)
+ORDER BY e.id
+`
+
+type ListLedgerBalanceMismatchesRow struct {
+	ID            int32
+	Username      string
+	Coins         int32
+	LedgerBalance int64
+}
+
+// ----------------------------------------------------------
+// ListLedgerBalanceMismatches возвращает сотрудников, у которых employees.coins
+// не совпадает с остатком их счёта в книге.
+func (q *Queries) ListLedgerBalanceMismatches(ctx context.Context) ([]ListLedgerBalanceMismatchesRow, error) {
+	rows, err := q.db.Query(ctx, listLedgerBalanceMismatches)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListLedgerBalanceMismatchesRow
+	for rows.Next() {
+		var i ListLedgerBalanceMismatchesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Username,
+			&i.Coins,
+			&i.LedgerBalance,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listSystemAccountBalances = `-- name: ListSystemAccountBalances :many
+SELECT
+  a.code::text AS code,
+  COALESCE(SUM(p.amount), 0)::bigint AS balance
+FROM ledger_accounts a
+LEFT JOIN ledger_postings p ON p.account_id = a.id
+WHERE a.code IS NOT NULL
+GROUP BY a.id, a.code
+ORDER BY a.id
+`
+
+type ListSystemAccountBalancesRow struct {
+	Code    string
+	Balance int64
+}
+
+// ListSystemAccountBalances возвращает остатки системных счетов книги.
+func (q *Queries) ListSystemAccountBalances(ctx context.Context) ([]ListSystemAccountBalancesRow, error) {
+	rows, err := q.db.Query(ctx, listSystemAccountBalances)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListSystemAccountBalancesRow
+	for rows.Next() {
+		var i ListSystemAccountBalancesRow
+		if err := rows.Scan(&i.Code, &i.Balance); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -7,36 +7,176 @@ package db
 
 import (
 	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const createMerch = `-- name: CreateMerch :one
+INSERT INTO merch (name, price, stock)
+VALUES ($1, $2, $3)
+RETURNING id, name, price, active, retired_at, stock
+`
+
+type CreateMerchParams struct {
+	Name  string
+	Price int32
+	Stock pgtype.Int4
+}
+
+// ----------------------------------------------------------
+// CreateMerch добавляет новый товар в каталог.
+// NULL в stock означает неограниченный остаток.
+func (q *Queries) CreateMerch(ctx context.Context, arg CreateMerchParams) (Merch, error) {
+	row := q.db.QueryRow(ctx, createMerch, arg.Name, arg.Price, arg.Stock)
+	var i Merch
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Price,
+		&i.Active,
+		&i.RetiredAt,
+		&i.Stock,
+	)
+	return i, err
+}
+
+const decrementMerchStock = `-- name: DecrementMerchStock :execrows
+UPDATE merch
+SET stock = stock - $1::integer
+WHERE id = $2
+  AND (stock IS NULL OR stock >= $1::integer)
+`
+
+type DecrementMerchStockParams struct {
+	Quantity int32
+	ID       int32
+}
+
+// ----------------------------------------------------------
+// DecrementMerchStock уменьшает остаток товара на $2 единиц.
+// Условие проверяется под блокировкой строки, поэтому при параллельных
+// покупках остаток не уходит в минус. Товары с stock = NULL не ограничены.
+// 0 затронутых строк означает, что товара не хватает.
+func (q *Queries) DecrementMerchStock(ctx context.Context, arg DecrementMerchStockParams) (int64, error) {
+	result, err := q.db.Exec(ctx, decrementMerchStock, arg.Quantity, arg.ID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const getMerchByID = `-- name: GetMerchByID :one
+SELECT 
+  id,
+  name,
+  price,
+  active,
+  retired_at,
+  stock
+FROM merch
+WHERE id = $1
+`
+
+// ----------------------------------------------------------
+// GetMerchByID возвращает товар по идентификатору независимо от его статуса.
+func (q *Queries) GetMerchByID(ctx context.Context, id int32) (Merch, error) {
+	row := q.db.QueryRow(ctx, getMerchByID, id)
+	var i Merch
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Price,
+		&i.Active,
+		&i.RetiredAt,
+		&i.Stock,
+	)
+	return i, err
+}
+
 const getMerchByName = `-- name: GetMerchByName :one
 SELECT 
   id,
   name,
-  price
+  price,
+  active,
+  retired_at,
+  stock
 FROM merch
-WHERE name = $1
+WHERE name = $1 AND active
 `
 
 // GetMerchByName возвращает информацию о товаре по его названию.
+// Снятые с продажи товары не возвращаются, поэтому купить их нельзя.
 func (q *Queries) GetMerchByName(ctx context.Context, name string) (Merch, error) {
 	row := q.db.QueryRow(ctx, getMerchByName, name)
 	var i Merch
-	err := row.Scan(&i.ID, &i.Name, &i.Price)
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Price,
+		&i.Active,
+		&i.RetiredAt,
+		&i.Stock,
+	)
 	return i, err
 }
 
+const listAllMerch = `-- name: ListAllMerch :many
+SELECT 
+  id,
+  name,
+  price,
+  active,
+  retired_at,
+  stock
+FROM merch
+ORDER BY id
+`
+
+// ----------------------------------------------------------
+// ListAllMerch возвращает все товары, включая снятые с продажи (для администратора).
+func (q *Queries) ListAllMerch(ctx context.Context) ([]Merch, error) {
+	rows, err := q.db.Query(ctx, listAllMerch)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Merch
+	for rows.Next() {
+		var i Merch
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Price,
+			&i.Active,
+			&i.RetiredAt,
+			&i.Stock,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listMerch = `-- name: ListMerch :many
 SELECT 
   id,
   name,
-  price
+  price,
+  active,
+  retired_at,
+  stock
 FROM merch
+WHERE active
 ORDER BY name
 `
 
 // ----------------------------------------------------------
-// ListMerch возвращает список всех товаров из мерча.
+// ListMerch возвращает список всех товаров из мерча, доступных для покупки.
 func (q *Queries) ListMerch(ctx context.Context) ([]Merch, error) {
 	rows, err := q.db.Query(ctx, listMerch)
 	if err != nil {
@@ -46,7 +186,14 @@ func (q *Queries) ListMerch(ctx context.Context) ([]Merch, error) {
 	var items []Merch
 	for rows.Next() {
 		var i Merch
-		if err := rows.Scan(&i.ID, &i.Name, &i.Price); err != nil {
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Price,
+			&i.Active,
+			&i.RetiredAt,
+			&i.Stock,
+		); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -56,3 +203,71 @@ func (q *Queries) ListMerch(ctx context.Context) ([]Merch, error) {
 	}
 	return items, nil
 }
+
+const retireMerch = `-- name: RetireMerch :one
+UPDATE merch
+SET 
+  active = FALSE,
+  retired_at = NOW()
+WHERE id = $1 AND active
+RETURNING id, name, price, active, retired_at, stock
+`
+
+// ----------------------------------------------------------
+// RetireMerch снимает товар с продажи. Строка остаётся в таблице,
+// чтобы не нарушать внешние ключи inventory и coin_transactions.
+func (q *Queries) RetireMerch(ctx context.Context, id int32) (Merch, error) {
+	row := q.db.QueryRow(ctx, retireMerch, id)
+	var i Merch
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Price,
+		&i.Active,
+		&i.RetiredAt,
+		&i.Stock,
+	)
+	return i, err
+}
+
+const updateMerch = `-- name: UpdateMerch :one
+UPDATE merch
+SET 
+  name = COALESCE($1, name),
+  price = COALESCE($2, price),
+  stock = CASE WHEN $3::boolean THEN $4::integer ELSE stock END
+WHERE id = $5
+RETURNING id, name, price, active, retired_at, stock
+`
+
+type UpdateMerchParams struct {
+	Name     pgtype.Text
+	Price    pgtype.Int4
+	SetStock bool
+	Stock    pgtype.Int4
+	ID       int32
+}
+
+// ----------------------------------------------------------
+// UpdateMerch переименовывает товар, меняет его цену и/или остаток.
+// NULL в name/price означает, что поле не меняется. Остаток меняется
+// только при set_stock = TRUE, и тогда NULL делает его неограниченным.
+func (q *Queries) UpdateMerch(ctx context.Context, arg UpdateMerchParams) (Merch, error) {
+	row := q.db.QueryRow(ctx, updateMerch,
+		arg.Name,
+		arg.Price,
+		arg.SetStock,
+		arg.Stock,
+		arg.ID,
+	)
+	var i Merch
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Price,
+		&i.Active,
+		&i.RetiredAt,
+		&i.Stock,
+	)
+	return i, err
+}
@@ -11,11 +11,246 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+type CoinRequestStatusEnum string
+
+const (
+	CoinRequestStatusEnumPending  CoinRequestStatusEnum = "pending"
+	CoinRequestStatusEnumAccepted CoinRequestStatusEnum = "accepted"
+	CoinRequestStatusEnumDeclined CoinRequestStatusEnum = "declined"
+	CoinRequestStatusEnumExpired  CoinRequestStatusEnum = "expired"
+)
+
+func (e *CoinRequestStatusEnum) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = CoinRequestStatusEnum(s)
+	case string:
+		*e = CoinRequestStatusEnum(s)
+	default:
+		return fmt.Errorf("unsupported scan type for CoinRequestStatusEnum: %T", src)
+	}
+	return nil
+}
+
+type NullCoinRequestStatusEnum struct {
+	CoinRequestStatusEnum CoinRequestStatusEnum
+	Valid                 bool // Valid is true if CoinRequestStatusEnum is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullCoinRequestStatusEnum) Scan(value interface{}) error {
+	if value == nil {
+		ns.CoinRequestStatusEnum, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.CoinRequestStatusEnum.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullCoinRequestStatusEnum) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.CoinRequestStatusEnum), nil
+}
+
+type EmployeeRoleEnum string
+
+const (
+	EmployeeRoleEnumEmployee EmployeeRoleEnum = "employee"
+	EmployeeRoleEnumManager  EmployeeRoleEnum = "manager"
+	EmployeeRoleEnumAdmin    EmployeeRoleEnum = "admin"
+)
+
+func (e *EmployeeRoleEnum) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = EmployeeRoleEnum(s)
+	case string:
+		*e = EmployeeRoleEnum(s)
+	default:
+		return fmt.Errorf("unsupported scan type for EmployeeRoleEnum: %T", src)
+	}
+	return nil
+}
+
+type NullEmployeeRoleEnum struct {
+	EmployeeRoleEnum EmployeeRoleEnum
+	Valid            bool // Valid is true if EmployeeRoleEnum is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullEmployeeRoleEnum) Scan(value interface{}) error {
+	if value == nil {
+		ns.EmployeeRoleEnum, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.EmployeeRoleEnum.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullEmployeeRoleEnum) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.EmployeeRoleEnum), nil
+}
+
+type JournalEntryTypeEnum string
+
+const (
+	JournalEntryTypeEnumOpening     JournalEntryTypeEnum = "opening"
+	JournalEntryTypeEnumSignup      JournalEntryTypeEnum = "signup"
+	JournalEntryTypeEnumTransfer    JournalEntryTypeEnum = "transfer"
+	JournalEntryTypeEnumPurchase    JournalEntryTypeEnum = "purchase"
+	JournalEntryTypeEnumRefund      JournalEntryTypeEnum = "refund"
+	JournalEntryTypeEnumGrant       JournalEntryTypeEnum = "grant"
+	JournalEntryTypeEnumAdjustment  JournalEntryTypeEnum = "adjustment"
+	JournalEntryTypeEnumAllowance   JournalEntryTypeEnum = "allowance"
+	JournalEntryTypeEnumExpiry      JournalEntryTypeEnum = "expiry"
+	JournalEntryTypeEnumHold        JournalEntryTypeEnum = "hold"
+	JournalEntryTypeEnumHoldRelease JournalEntryTypeEnum = "hold_release"
+	JournalEntryTypeEnumHoldRefund  JournalEntryTypeEnum = "hold_refund"
+)
+
+func (e *JournalEntryTypeEnum) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = JournalEntryTypeEnum(s)
+	case string:
+		*e = JournalEntryTypeEnum(s)
+	default:
+		return fmt.Errorf("unsupported scan type for JournalEntryTypeEnum: %T", src)
+	}
+	return nil
+}
+
+type NullJournalEntryTypeEnum struct {
+	JournalEntryTypeEnum JournalEntryTypeEnum
+	Valid                bool // Valid is true if JournalEntryTypeEnum is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullJournalEntryTypeEnum) Scan(value interface{}) error {
+	if value == nil {
+		ns.JournalEntryTypeEnum, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.JournalEntryTypeEnum.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullJournalEntryTypeEnum) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.JournalEntryTypeEnum), nil
+}
+
+type OrderStatusEnum string
+
+const (
+	OrderStatusEnumPlaced         OrderStatusEnum = "placed"
+	OrderStatusEnumPacked         OrderStatusEnum = "packed"
+	OrderStatusEnumReadyForPickup OrderStatusEnum = "ready_for_pickup"
+	OrderStatusEnumDelivered      OrderStatusEnum = "delivered"
+	OrderStatusEnumCancelled      OrderStatusEnum = "cancelled"
+)
+
+func (e *OrderStatusEnum) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = OrderStatusEnum(s)
+	case string:
+		*e = OrderStatusEnum(s)
+	default:
+		return fmt.Errorf("unsupported scan type for OrderStatusEnum: %T", src)
+	}
+	return nil
+}
+
+type NullOrderStatusEnum struct {
+	OrderStatusEnum OrderStatusEnum
+	Valid           bool // Valid is true if OrderStatusEnum is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullOrderStatusEnum) Scan(value interface{}) error {
+	if value == nil {
+		ns.OrderStatusEnum, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.OrderStatusEnum.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullOrderStatusEnum) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.OrderStatusEnum), nil
+}
+
+type RefundStatusEnum string
+
+const (
+	RefundStatusEnumPending  RefundStatusEnum = "pending"
+	RefundStatusEnumApproved RefundStatusEnum = "approved"
+	RefundStatusEnumRejected RefundStatusEnum = "rejected"
+)
+
+func (e *RefundStatusEnum) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = RefundStatusEnum(s)
+	case string:
+		*e = RefundStatusEnum(s)
+	default:
+		return fmt.Errorf("unsupported scan type for RefundStatusEnum: %T", src)
+	}
+	return nil
+}
+
+type NullRefundStatusEnum struct {
+	RefundStatusEnum RefundStatusEnum
+	Valid            bool // Valid is true if RefundStatusEnum is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullRefundStatusEnum) Scan(value interface{}) error {
+	if value == nil {
+		ns.RefundStatusEnum, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.RefundStatusEnum.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullRefundStatusEnum) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.RefundStatusEnum), nil
+}
+
 type TransactionTypeEnum string
 
 const (
-	TransactionTypeEnumTransfer TransactionTypeEnum = "transfer"
-	TransactionTypeEnumPurchase TransactionTypeEnum = "purchase"
+	TransactionTypeEnumTransfer    TransactionTypeEnum = "transfer"
+	TransactionTypeEnumPurchase    TransactionTypeEnum = "purchase"
+	TransactionTypeEnumRefund      TransactionTypeEnum = "refund"
+	TransactionTypeEnumGrant       TransactionTypeEnum = "grant"
+	TransactionTypeEnumAdjustment  TransactionTypeEnum = "adjustment"
+	TransactionTypeEnumAllowance   TransactionTypeEnum = "allowance"
+	TransactionTypeEnumExpiry      TransactionTypeEnum = "expiry"
+	TransactionTypeEnumHold        TransactionTypeEnum = "hold"
+	TransactionTypeEnumHoldRelease TransactionTypeEnum = "hold_release"
+	TransactionTypeEnumHoldRefund  TransactionTypeEnum = "hold_refund"
 )
 
 func (e *TransactionTypeEnum) Scan(src interface{}) error {
@@ -53,14 +288,95 @@ func (ns NullTransactionTypeEnum) Value() (driver.Value, error) {
 	return string(ns.TransactionTypeEnum), nil
 }
 
+type TransferHoldStatusEnum string
+
+const (
+	TransferHoldStatusEnumPending  TransferHoldStatusEnum = "pending"
+	TransferHoldStatusEnumAccepted TransferHoldStatusEnum = "accepted"
+	TransferHoldStatusEnumDeclined TransferHoldStatusEnum = "declined"
+	TransferHoldStatusEnumExpired  TransferHoldStatusEnum = "expired"
+)
+
+func (e *TransferHoldStatusEnum) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = TransferHoldStatusEnum(s)
+	case string:
+		*e = TransferHoldStatusEnum(s)
+	default:
+		return fmt.Errorf("unsupported scan type for TransferHoldStatusEnum: %T", src)
+	}
+	return nil
+}
+
+type NullTransferHoldStatusEnum struct {
+	TransferHoldStatusEnum TransferHoldStatusEnum
+	Valid                  bool // Valid is true if TransferHoldStatusEnum is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullTransferHoldStatusEnum) Scan(value interface{}) error {
+	if value == nil {
+		ns.TransferHoldStatusEnum, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.TransferHoldStatusEnum.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullTransferHoldStatusEnum) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.TransferHoldStatusEnum), nil
+}
+
+type CartItem struct {
+	EmployeeID int32
+	MerchID    int32
+	Quantity   int32
+	AddedAt    pgtype.Timestamptz
+}
+
+type CoinLot struct {
+	ID         int32
+	EmployeeID int32
+	Amount     int32
+	Remaining  int32
+	GrantedAt  pgtype.Timestamptz
+	ExpiresAt  pgtype.Timestamptz
+}
+
+type CoinRequest struct {
+	ID          int32
+	RequesterID int32
+	PayerID     int32
+	Amount      int32
+	Note        string
+	Status      CoinRequestStatusEnum
+	CreatedAt   pgtype.Timestamptz
+	ExpiresAt   pgtype.Timestamptz
+	ResolvedAt  pgtype.Timestamptz
+}
+
 type CoinTransaction struct {
 	ID              int32
 	TransactionType TransactionTypeEnum
-	FromEmployeeID  int32
+	FromEmployeeID  pgtype.Int4
 	ToEmployeeID    pgtype.Int4
 	MerchID         pgtype.Int4
 	Amount          int32
 	CreatedAt       pgtype.Timestamptz
+	Quantity        int32
+	UnitPrice       pgtype.Int4
+	RefundOf        pgtype.Int4
+	Reason          pgtype.Text
+	CreatedBy       pgtype.Int4
+	Period          pgtype.Date
+	Message         pgtype.Text
+	Hashtags        []string
+	HoldID          pgtype.Int4
 }
 
 type Employee struct {
@@ -69,6 +385,16 @@ type Employee struct {
 	PasswordHash string
 	Coins        int32
 	CreatedAt    pgtype.Timestamptz
+	Role         EmployeeRoleEnum
+}
+
+type IdempotencyKey struct {
+	EmployeeID     int32
+	IdempotencyKey string
+	RequestHash    string
+	ResponseStatus pgtype.Int4
+	ResponseBody   []byte
+	CreatedAt      pgtype.Timestamptz
 }
 
 type Inventory struct {
@@ -78,8 +404,95 @@ type Inventory struct {
 	Quantity   int32
 }
 
+type JournalEntry struct {
+	ID                int32
+	EntryType         JournalEntryTypeEnum
+	CoinTransactionID pgtype.Int4
+	CreatedAt         pgtype.Timestamptz
+}
+
+type LedgerAccount struct {
+	ID         int32
+	Code       pgtype.Text
+	EmployeeID pgtype.Int4
+	CreatedAt  pgtype.Timestamptz
+}
+
+type LedgerPosting struct {
+	ID        int32
+	EntryID   int32
+	AccountID int32
+	Amount    int32
+}
+
 type Merch struct {
-	ID    int32
-	Name  string
-	Price int32
+	ID        int32
+	Name      string
+	Price     int32
+	Active    bool
+	RetiredAt pgtype.Timestamptz
+	Stock     pgtype.Int4
+}
+
+type Order struct {
+	ID         int32
+	PurchaseID int32
+	EmployeeID int32
+	MerchID    int32
+	Quantity   int32
+	Status     OrderStatusEnum
+	CreatedAt  pgtype.Timestamptz
+	UpdatedAt  pgtype.Timestamptz
+}
+
+type PurchaseLot struct {
+	ID         int32
+	PurchaseID int32
+	Amount     int32
+	Remaining  int32
+	GrantedAt  pgtype.Timestamptz
+	ExpiresAt  pgtype.Timestamptz
+}
+
+type RefundRequest struct {
+	ID         int32
+	PurchaseID int32
+	EmployeeID int32
+	Quantity   int32
+	Reason     string
+	Status     RefundStatusEnum
+	RefundID   pgtype.Int4
+	ResolvedBy pgtype.Int4
+	CreatedAt  pgtype.Timestamptz
+	ResolvedAt pgtype.Timestamptz
+}
+
+type TransferHold struct {
+	ID          int32
+	SenderID    int32
+	RecipientID int32
+	Amount      int32
+	Message     pgtype.Text
+	Hashtags    []string
+	Status      TransferHoldStatusEnum
+	CreatedAt   pgtype.Timestamptz
+	ExpiresAt   pgtype.Timestamptz
+	ResolvedAt  pgtype.Timestamptz
+}
+
+type TransferHoldLot struct {
+	ID        int32
+	HoldID    int32
+	Amount    int32
+	GrantedAt pgtype.Timestamptz
+	ExpiresAt pgtype.Timestamptz
+}
+
+type TransferLimitOverride struct {
+	EmployeeID     int32
+	DailyLimit     pgtype.Int4
+	MonthlyLimit   pgtype.Int4
+	RecipientLimit pgtype.Int4
+	UpdatedBy      int32
+	UpdatedAt      pgtype.Timestamptz
 }
@@ -0,0 +1,356 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.27.0
+// source: orders.sql
+
+package db
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const cancelOrderByPurchase = `-- name: CancelOrderByPurchase :exec
+UPDATE orders
+SET status = 'cancelled', updated_at = NOW()
+WHERE purchase_id = $1 AND status NOT IN ('delivered', 'cancelled')
+`
+
+// ----------------------------------------------------------
+// CancelOrderByPurchase отменяет ещё не выданный заказ по покупке.
+// Используется, когда покупка возвращена полностью.
+func (q *Queries) CancelOrderByPurchase(ctx context.Context, purchaseID int32) error {
+	_, err := q.db.Exec(ctx, cancelOrderByPurchase, purchaseID)
+	return err
+}
+
+const createOrder = `-- name: CreateOrder :exec
+INSERT INTO orders (purchase_id, employee_id, merch_id, quantity)
+VALUES ($1, $2, $3, $4)
+`
+
+type CreateOrderParams struct {
+	PurchaseID int32
+	EmployeeID int32
+	MerchID    int32
+	Quantity   int32
+}
+
+// CreateOrder создаёт заказ на выдачу товара по покупке.
+func (q *Queries) CreateOrder(ctx context.Context, arg CreateOrderParams) error {
+	_, err := q.db.Exec(ctx, createOrder,
+		arg.PurchaseID,
+		arg.EmployeeID,
+		arg.MerchID,
+		arg.Quantity,
+	)
+	return err
+}
+
+const getOrderByEmployee = `-- name: GetOrderByEmployee :one
+SELECT 
+  o.id,
+  o.purchase_id,
+  m.name AS merch_name,
+  o.quantity,
+  o.status,
+  o.created_at,
+  o.updated_at
+FROM orders o
+JOIN merch m ON m.id = o.merch_id
+WHERE o.id = $1 AND o.employee_id = $2
+`
+
+type GetOrderByEmployeeParams struct {
+	ID         int32
+	EmployeeID int32
+}
+
+type GetOrderByEmployeeRow struct {
+	ID         int32
+	PurchaseID int32
+	MerchName  string
+	Quantity   int32
+	Status     OrderStatusEnum
+	CreatedAt  pgtype.Timestamptz
+	UpdatedAt  pgtype.Timestamptz
+}
+
+// ----------------------------------------------------------
+// GetOrderByEmployee возвращает заказ, только если он принадлежит сотруднику.
+func (q *Queries) GetOrderByEmployee(ctx context.Context, arg GetOrderByEmployeeParams) (GetOrderByEmployeeRow, error) {
+	row := q.db.QueryRow(ctx, getOrderByEmployee, arg.ID, arg.EmployeeID)
+	var i GetOrderByEmployeeRow
+	err := row.Scan(
+		&i.ID,
+		&i.PurchaseID,
+		&i.MerchName,
+		&i.Quantity,
+		&i.Status,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getOrderStatusForUpdate = `-- name: GetOrderStatusForUpdate :one
+SELECT status FROM orders WHERE id = $1 FOR UPDATE
+`
+
+// ----------------------------------------------------------
+// GetOrderStatusForUpdate возвращает статус заказа и блокирует его строку
+// до конца транзакции, чтобы переходы статусов не выполнялись параллельно.
+func (q *Queries) GetOrderStatusForUpdate(ctx context.Context, id int32) (OrderStatusEnum, error) {
+	row := q.db.QueryRow(ctx, getOrderStatusForUpdate, id)
+	var status OrderStatusEnum
+	err := row.Scan(&status)
+	return status, err
+}
+
+const getPendingOrdersByEmployeeID = `-- name: GetPendingOrdersByEmployeeID :many
+SELECT 
+  o.id,
+  m.name AS merch_name,
+  o.quantity,
+  o.status,
+  o.created_at
+FROM orders o
+JOIN merch m ON m.id = o.merch_id
+WHERE o.employee_id = $1 AND o.status NOT IN ('delivered', 'cancelled')
+ORDER BY o.id
+`
+
+type GetPendingOrdersByEmployeeIDRow struct {
+	ID        int32
+	MerchName string
+	Quantity  int32
+	Status    OrderStatusEnum
+	CreatedAt pgtype.Timestamptz
+}
+
+// ----------------------------------------------------------
+// GetPendingOrdersByEmployeeID возвращает ещё не выданные и не отменённые заказы сотрудника.
+func (q *Queries) GetPendingOrdersByEmployeeID(ctx context.Context, employeeID int32) ([]GetPendingOrdersByEmployeeIDRow, error) {
+	rows, err := q.db.Query(ctx, getPendingOrdersByEmployeeID, employeeID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetPendingOrdersByEmployeeIDRow
+	for rows.Next() {
+		var i GetPendingOrdersByEmployeeIDRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.MerchName,
+			&i.Quantity,
+			&i.Status,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listOrders = `-- name: ListOrders :many
+SELECT 
+  o.id,
+  o.purchase_id,
+  e.username,
+  m.name AS merch_name,
+  o.quantity,
+  o.status,
+  o.created_at,
+  o.updated_at
+FROM orders o
+JOIN employees e ON e.id = o.employee_id
+JOIN merch m ON m.id = o.merch_id
+WHERE ($1::order_status_enum IS NULL OR o.status = $1::order_status_enum)
+ORDER BY o.id
+`
+
+type ListOrdersRow struct {
+	ID         int32
+	PurchaseID int32
+	Username   string
+	MerchName  string
+	Quantity   int32
+	Status     OrderStatusEnum
+	CreatedAt  pgtype.Timestamptz
+	UpdatedAt  pgtype.Timestamptz
+}
+
+// ----------------------------------------------------------
+// ListOrders возвращает заказы всех сотрудников, старые первыми, чтобы
+// выдавать их в порядке очереди. NULL в фильтре статуса означает все заказы.
+func (q *Queries) ListOrders(ctx context.Context, status NullOrderStatusEnum) ([]ListOrdersRow, error) {
+	rows, err := q.db.Query(ctx, listOrders, status)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListOrdersRow
+	for rows.Next() {
+		var i ListOrdersRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.PurchaseID,
+			&i.Username,
+			&i.MerchName,
+			&i.Quantity,
+			&i.Status,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listOrdersByEmployee = `-- name: ListOrdersByEmployee :many
+SELECT 
+  o.id,
+  o.purchase_id,
+  m.name AS merch_name,
+  o.quantity,
+  o.status,
+  o.created_at,
+  o.updated_at
+FROM orders o
+JOIN merch m ON m.id = o.merch_id
+WHERE o.employee_id = $1
+  AND ($2::order_status_enum IS NULL OR o.status = $2::order_status_enum)
+ORDER BY o.id DESC
+`
+
+type ListOrdersByEmployeeParams struct {
+	EmployeeID int32
+	Status     NullOrderStatusEnum
+}
+
+type ListOrdersByEmployeeRow struct {
+	ID         int32
+	PurchaseID int32
+	MerchName  string
+	Quantity   int32
+	Status     OrderStatusEnum
+	CreatedAt  pgtype.Timestamptz
+	UpdatedAt  pgtype.Timestamptz
+}
+
+// ----------------------------------------------------------
+// ListOrdersByEmployee возвращает заказы сотрудника, новые первыми.
+// NULL в фильтре статуса означает все заказы.
+func (q *Queries) ListOrdersByEmployee(ctx context.Context, arg ListOrdersByEmployeeParams) ([]ListOrdersByEmployeeRow, error) {
+	rows, err := q.db.Query(ctx, listOrdersByEmployee, arg.EmployeeID, arg.Status)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListOrdersByEmployeeRow
+	for rows.Next() {
+		var i ListOrdersByEmployeeRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.PurchaseID,
+			&i.MerchName,
+			&i.Quantity,
+			&i.Status,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const reduceOrderQuantity = `-- name: ReduceOrderQuantity :exec
+UPDATE orders
+SET quantity = quantity - $1::integer, updated_at = NOW()
+WHERE purchase_id = $2::integer
+  AND status NOT IN ('delivered', 'cancelled')
+  AND quantity > $1::integer
+`
+
+type ReduceOrderQuantityParams struct {
+	Quantity   int32
+	PurchaseID int32
+}
+
+// ----------------------------------------------------------
+// ReduceOrderQuantity уменьшает количество в ещё не выданном заказе при
+// частичном возврате покупки.
+func (q *Queries) ReduceOrderQuantity(ctx context.Context, arg ReduceOrderQuantityParams) error {
+	_, err := q.db.Exec(ctx, reduceOrderQuantity, arg.Quantity, arg.PurchaseID)
+	return err
+}
+
+const updateOrderStatus = `-- name: UpdateOrderStatus :one
+WITH updated AS (
+  UPDATE orders
+  SET status = $1, updated_at = NOW()
+  WHERE orders.id = $2
+  RETURNING orders.id, orders.purchase_id, orders.employee_id, orders.merch_id, orders.quantity, orders.status, orders.created_at, orders.updated_at
+)
+SELECT 
+  u.id,
+  u.purchase_id,
+  e.username,
+  m.name AS merch_name,
+  u.quantity,
+  u.status,
+  u.created_at,
+  u.updated_at
+FROM updated u
+JOIN employees e ON e.id = u.employee_id
+JOIN merch m ON m.id = u.merch_id
+`
+
+type UpdateOrderStatusParams struct {
+	Status OrderStatusEnum
+	ID     int32
+}
+
+type UpdateOrderStatusRow struct {
+	ID         int32
+	PurchaseID int32
+	Username   string
+	MerchName  string
+	Quantity   int32
+	Status     OrderStatusEnum
+	CreatedAt  pgtype.Timestamptz
+	UpdatedAt  pgtype.Timestamptz
+}
+
+// ----------------------------------------------------------
+// UpdateOrderStatus переводит заказ в новый статус и возвращает его.
+func (q *Queries) UpdateOrderStatus(ctx context.Context, arg UpdateOrderStatusParams) (UpdateOrderStatusRow, error) {
+	row := q.db.QueryRow(ctx, updateOrderStatus, arg.Status, arg.ID)
+	var i UpdateOrderStatusRow
+	err := row.Scan(
+		&i.ID,
+		&i.PurchaseID,
+		&i.Username,
+		&i.MerchName,
+		&i.Quantity,
+		&i.Status,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
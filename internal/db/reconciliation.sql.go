@@ -0,0 +1,136 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.27.0
+// source: reconciliation.sql
+
+package db
+
+import (
+	"context"
+)
+
+const listBalanceDrift = `-- name: ListBalanceDrift :many
+WITH opening AS (
+  SELECT a.employee_id, SUM(p.amount) AS amount
+  FROM ledger_postings p
+  JOIN journal_entries j ON j.id = p.entry_id
+  JOIN ledger_accounts a ON a.id = p.account_id
+  WHERE j.entry_type IN ('signup', 'opening') AND a.employee_id IS NOT NULL
+  GROUP BY a.employee_id
+),
+movements AS (
+  SELECT t.to_employee_id AS employee_id, t.amount
+  FROM coin_transactions t
+  JOIN journal_entries j ON j.coin_transaction_id = t.id
+  WHERE t.to_employee_id IS NOT NULL
+  UNION ALL
+  SELECT t.from_employee_id, -t.amount
+  FROM coin_transactions t
+  JOIN journal_entries j ON j.coin_transaction_id = t.id
+  WHERE t.from_employee_id IS NOT NULL
+),
+net AS (
+  SELECT employee_id, SUM(amount) AS amount
+  FROM movements
+  GROUP BY employee_id
+),
+expected AS (
+  SELECT
+    e.id,
+    e.username,
+    e.coins,
+    (COALESCE(o.amount, 0) + COALESCE(n.amount, 0))::bigint AS expected
+  FROM employees e
+  LEFT JOIN opening o ON o.employee_id = e.id
+  LEFT JOIN net n ON n.employee_id = e.id
+)
+SELECT id, username, coins, expected
+FROM expected
+WHERE coins <> expected
+ORDER BY id
+`
+
+type ListBalanceDriftRow struct {
+	ID       int32
+	Username string
+	Coins    int32
+	Expected int64
+}
+
+// ListBalanceDrift пересчитывает ожидаемый баланс каждого сотрудника:
+// стартовый остаток из книги (проводки signup при регистрации и opening при
+// переносе балансов в книгу) плюс зачисления (to_employee_id) минус списания
+// (from_employee_id) по coin_transactions, у которых есть проводка. Операции
+// до появления книги уже учтены во входящем остатке opening и не
+// пересчитываются повторно. Возвращает только сотрудников, у которых
+// employees.coins расходится с ожидаемым значением.
+func (q *Queries) ListBalanceDrift(ctx context.Context) ([]ListBalanceDriftRow, error) {
+	rows, err := q.db.Query(ctx, listBalanceDrift)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListBalanceDriftRow
+	for rows.Next() {
+		var i ListBalanceDriftRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Username,
+			&i.Coins,
+			&i.Expected,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listCoinLotDrift = `-- name: ListCoinLotDrift :many
+SELECT e.id, e.username, e.coins, COALESCE(SUM(l.remaining), 0)::bigint AS lots
+FROM employees e
+LEFT JOIN coin_lots l ON l.employee_id = e.id
+GROUP BY e.id
+HAVING e.coins <> COALESCE(SUM(l.remaining), 0)
+ORDER BY e.id
+`
+
+type ListCoinLotDriftRow struct {
+	ID       int32
+	Username string
+	Coins    int32
+	Lots     int64
+}
+
+// ----------------------------------------------------------
+// ListCoinLotDrift сравнивает employees.coins с суммой остатков партий
+// сотрудника. Истёкшие, но ещё не сгоревшие партии учитываются: их монеты
+// остаются на балансе до запуска сгорания. Возвращает только сотрудников,
+// у которых сумма партий расходится с балансом.
+func (q *Queries) ListCoinLotDrift(ctx context.Context) ([]ListCoinLotDriftRow, error) {
+	rows, err := q.db.Query(ctx, listCoinLotDrift)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListCoinLotDriftRow
+	for rows.Next() {
+		var i ListCoinLotDriftRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Username,
+			&i.Coins,
+			&i.Lots,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -0,0 +1,363 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.27.0
+// source: refunds.sql
+
+package db
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const createCoinTransactionRefund = `-- name: CreateCoinTransactionRefund :one
+WITH tx AS (
+  INSERT INTO coin_transactions (transaction_type, to_employee_id, merch_id, amount, quantity, unit_price, refund_of)
+  VALUES (
+    'refund',
+    $1::integer,
+    $2::integer,
+    $3,
+    $4,
+    $5::integer,
+    $6::integer
+  )
+  RETURNING id, to_employee_id, amount
+),
+entry AS (
+  INSERT INTO journal_entries (entry_type, coin_transaction_id)
+  SELECT 'refund', tx.id FROM tx
+  RETURNING id
+),
+postings AS (
+  INSERT INTO ledger_postings (entry_id, account_id, amount)
+  SELECT entry.id, leg.account_id, leg.amount
+  FROM entry, tx, LATERAL (VALUES
+    ((SELECT id FROM ledger_accounts WHERE code = 'store_revenue'), -tx.amount),
+    ((SELECT id FROM ledger_accounts WHERE employee_id = tx.to_employee_id), tx.amount)
+  ) AS leg(account_id, amount)
+)
+SELECT id FROM tx
+`
+
+type CreateCoinTransactionRefundParams struct {
+	ToEmployeeID int32
+	MerchID      int32
+	Amount       int32
+	Quantity     int32
+	UnitPrice    int32
+	RefundOf     int32
+}
+
+// ----------------------------------------------------------
+// CreateCoinTransactionRefund записывает возврат: монеты зачисляются покупателю
+// со счёта выручки магазина, refund_of ссылается на исходную покупку.
+func (q *Queries) CreateCoinTransactionRefund(ctx context.Context, arg CreateCoinTransactionRefundParams) (int32, error) {
+	row := q.db.QueryRow(ctx, createCoinTransactionRefund,
+		arg.ToEmployeeID,
+		arg.MerchID,
+		arg.Amount,
+		arg.Quantity,
+		arg.UnitPrice,
+		arg.RefundOf,
+	)
+	var id int32
+	err := row.Scan(&id)
+	return id, err
+}
+
+const createRefundRequest = `-- name: CreateRefundRequest :one
+INSERT INTO refund_requests (purchase_id, employee_id, quantity, reason)
+VALUES ($1, $2, $3, $4)
+RETURNING id, purchase_id, employee_id, quantity, reason, status, refund_id, resolved_by, created_at, resolved_at
+`
+
+type CreateRefundRequestParams struct {
+	PurchaseID int32
+	EmployeeID int32
+	Quantity   int32
+	Reason     string
+}
+
+// ----------------------------------------------------------
+// CreateRefundRequest создаёт заявку сотрудника на возврат.
+func (q *Queries) CreateRefundRequest(ctx context.Context, arg CreateRefundRequestParams) (RefundRequest, error) {
+	row := q.db.QueryRow(ctx, createRefundRequest,
+		arg.PurchaseID,
+		arg.EmployeeID,
+		arg.Quantity,
+		arg.Reason,
+	)
+	var i RefundRequest
+	err := row.Scan(
+		&i.ID,
+		&i.PurchaseID,
+		&i.EmployeeID,
+		&i.Quantity,
+		&i.Reason,
+		&i.Status,
+		&i.RefundID,
+		&i.ResolvedBy,
+		&i.CreatedAt,
+		&i.ResolvedAt,
+	)
+	return i, err
+}
+
+const getPurchaseForUpdate = `-- name: GetPurchaseForUpdate :one
+SELECT 
+  ct.id,
+  ct.from_employee_id,
+  ct.merch_id,
+  m.name AS merch_name,
+  ct.quantity,
+  ct.unit_price,
+  ct.amount
+FROM coin_transactions ct
+JOIN merch m ON m.id = ct.merch_id
+WHERE ct.id = $1 AND ct.transaction_type = 'purchase'
+FOR UPDATE OF ct
+`
+
+type GetPurchaseForUpdateRow struct {
+	ID             int32
+	FromEmployeeID pgtype.Int4
+	MerchID        pgtype.Int4
+	MerchName      string
+	Quantity       int32
+	UnitPrice      pgtype.Int4
+	Amount         int32
+}
+
+// GetPurchaseForUpdate возвращает покупку и блокирует её строку до конца
+// транзакции, чтобы параллельные возвраты не превысили купленное количество.
+func (q *Queries) GetPurchaseForUpdate(ctx context.Context, id int32) (GetPurchaseForUpdateRow, error) {
+	row := q.db.QueryRow(ctx, getPurchaseForUpdate, id)
+	var i GetPurchaseForUpdateRow
+	err := row.Scan(
+		&i.ID,
+		&i.FromEmployeeID,
+		&i.MerchID,
+		&i.MerchName,
+		&i.Quantity,
+		&i.UnitPrice,
+		&i.Amount,
+	)
+	return i, err
+}
+
+const getRefundRequestForUpdate = `-- name: GetRefundRequestForUpdate :one
+SELECT 
+  id,
+  purchase_id,
+  employee_id,
+  quantity,
+  reason,
+  status,
+  refund_id,
+  resolved_by,
+  created_at,
+  resolved_at
+FROM refund_requests
+WHERE id = $1
+FOR UPDATE
+`
+
+// ----------------------------------------------------------
+// GetRefundRequestForUpdate возвращает заявку и блокирует её до конца транзакции.
+func (q *Queries) GetRefundRequestForUpdate(ctx context.Context, id int32) (RefundRequest, error) {
+	row := q.db.QueryRow(ctx, getRefundRequestForUpdate, id)
+	var i RefundRequest
+	err := row.Scan(
+		&i.ID,
+		&i.PurchaseID,
+		&i.EmployeeID,
+		&i.Quantity,
+		&i.Reason,
+		&i.Status,
+		&i.RefundID,
+		&i.ResolvedBy,
+		&i.CreatedAt,
+		&i.ResolvedAt,
+	)
+	return i, err
+}
+
+const getRefundedQuantity = `-- name: GetRefundedQuantity :one
+SELECT COALESCE(SUM(quantity), 0)::integer AS refunded
+FROM coin_transactions
+WHERE refund_of = $1::integer AND transaction_type = 'refund'
+`
+
+// ----------------------------------------------------------
+// GetRefundedQuantity возвращает количество единиц покупки, уже возвращённых ранее.
+func (q *Queries) GetRefundedQuantity(ctx context.Context, purchaseID int32) (int32, error) {
+	row := q.db.QueryRow(ctx, getRefundedQuantity, purchaseID)
+	var refunded int32
+	err := row.Scan(&refunded)
+	return refunded, err
+}
+
+const listPurchasesByEmployee = `-- name: ListPurchasesByEmployee :many
+SELECT 
+  ct.id,
+  m.name AS merch_name,
+  ct.quantity,
+  ct.unit_price,
+  ct.amount,
+  ct.created_at,
+  COALESCE((
+    SELECT SUM(r.quantity)
+    FROM coin_transactions r
+    WHERE r.refund_of = ct.id AND r.transaction_type = 'refund'
+  ), 0)::integer AS refunded_quantity
+FROM coin_transactions ct
+JOIN merch m ON m.id = ct.merch_id
+WHERE ct.transaction_type = 'purchase'
+  AND ct.from_employee_id = $1::integer
+ORDER BY ct.created_at DESC, ct.id DESC
+`
+
+type ListPurchasesByEmployeeRow struct {
+	ID               int32
+	MerchName        string
+	Quantity         int32
+	UnitPrice        pgtype.Int4
+	Amount           int32
+	CreatedAt        pgtype.Timestamptz
+	RefundedQuantity int32
+}
+
+// ----------------------------------------------------------
+// ListPurchasesByEmployee возвращает покупки сотрудника вместе с количеством
+// уже возвращённых единиц.
+func (q *Queries) ListPurchasesByEmployee(ctx context.Context, employeeID int32) ([]ListPurchasesByEmployeeRow, error) {
+	rows, err := q.db.Query(ctx, listPurchasesByEmployee, employeeID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListPurchasesByEmployeeRow
+	for rows.Next() {
+		var i ListPurchasesByEmployeeRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.MerchName,
+			&i.Quantity,
+			&i.UnitPrice,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.RefundedQuantity,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listRefundRequests = `-- name: ListRefundRequests :many
+SELECT 
+  rr.id,
+  rr.purchase_id,
+  e.username,
+  m.name AS merch_name,
+  rr.quantity,
+  rr.reason,
+  rr.status,
+  rr.refund_id,
+  rr.created_at,
+  rr.resolved_at
+FROM refund_requests rr
+JOIN employees e ON e.id = rr.employee_id
+JOIN coin_transactions ct ON ct.id = rr.purchase_id
+JOIN merch m ON m.id = ct.merch_id
+WHERE ($1::refund_status_enum IS NULL OR rr.status = $1::refund_status_enum)
+  AND ($2::integer IS NULL OR rr.employee_id = $2::integer)
+ORDER BY rr.id DESC
+`
+
+type ListRefundRequestsParams struct {
+	Status     NullRefundStatusEnum
+	EmployeeID pgtype.Int4
+}
+
+type ListRefundRequestsRow struct {
+	ID         int32
+	PurchaseID int32
+	Username   string
+	MerchName  string
+	Quantity   int32
+	Reason     string
+	Status     RefundStatusEnum
+	RefundID   pgtype.Int4
+	CreatedAt  pgtype.Timestamptz
+	ResolvedAt pgtype.Timestamptz
+}
+
+// ----------------------------------------------------------
+// ListRefundRequests возвращает заявки на возврат. NULL в фильтре означает
+// отсутствие ограничения по этому полю.
+func (q *Queries) ListRefundRequests(ctx context.Context, arg ListRefundRequestsParams) ([]ListRefundRequestsRow, error) {
+	rows, err := q.db.Query(ctx, listRefundRequests, arg.Status, arg.EmployeeID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListRefundRequestsRow
+	for rows.Next() {
+		var i ListRefundRequestsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.PurchaseID,
+			&i.Username,
+			&i.MerchName,
+			&i.Quantity,
+			&i.Reason,
+			&i.Status,
+			&i.RefundID,
+			&i.CreatedAt,
+			&i.ResolvedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const resolveRefundRequest = `-- name: ResolveRefundRequest :exec
+UPDATE refund_requests
+SET 
+  status = $1,
+  refund_id = $2,
+  resolved_by = $3::integer,
+  resolved_at = NOW()
+WHERE id = $4 AND status = 'pending'
+`
+
+type ResolveRefundRequestParams struct {
+	Status     RefundStatusEnum
+	RefundID   pgtype.Int4
+	ResolvedBy int32
+	ID         int32
+}
+
+// ----------------------------------------------------------
+// ResolveRefundRequest закрывает ожидающую заявку с указанным решением.
+func (q *Queries) ResolveRefundRequest(ctx context.Context, arg ResolveRefundRequestParams) error {
+	_, err := q.db.Exec(ctx, resolveRefundRequest,
+		arg.Status,
+		arg.RefundID,
+		arg.ResolvedBy,
+		arg.ID,
+	)
+	return err
+}
@@ -0,0 +1,161 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.27.0
+// source: statements.sql
+
+package db
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const listStatementBalances = `-- name: ListStatementBalances :many
+SELECT
+  e.id,
+  e.username,
+  e.created_at,
+  (e.coins - COALESCE(SUM(CASE WHEN ct.to_employee_id = e.id THEN ct.amount ELSE -ct.amount END), 0))::bigint AS opening_balance
+FROM employees e
+LEFT JOIN coin_transactions ct
+  ON (ct.from_employee_id = e.id OR ct.to_employee_id = e.id)
+ AND ct.created_at >= $1::timestamptz
+WHERE e.created_at < $2::timestamptz
+  AND ($3::integer IS NULL OR e.id = $3::integer)
+GROUP BY e.id
+ORDER BY e.id
+`
+
+type ListStatementBalancesParams struct {
+	PeriodStart pgtype.Timestamptz
+	PeriodEnd   pgtype.Timestamptz
+	EmployeeID  pgtype.Int4
+}
+
+type ListStatementBalancesRow struct {
+	ID             int32
+	Username       string
+	CreatedAt      pgtype.Timestamptz
+	OpeningBalance int64
+}
+
+// ListStatementBalances возвращает сотрудников, зарегистрированных до конца
+// периода, и их баланс на начало периода. employees.coins хранит только
+// текущий баланс, поэтому входящий остаток восстанавливается в обратную
+// сторону: из текущего баланса вычитаются зачисления и прибавляются списания с
+// начала периода. Чтобы остаток сходился со строками ListStatementEntries,
+// оба запроса выполняются в одной транзакции REPEATABLE READ и видят один
+// снимок. Для сотрудников, зарегистрированных внутри периода
+// (created_at >= period_start), это стартовые монеты на момент регистрации,
+// а не входящий остаток. employee_id со значением NULL означает всех сотрудников.
+func (q *Queries) ListStatementBalances(ctx context.Context, arg ListStatementBalancesParams) ([]ListStatementBalancesRow, error) {
+	rows, err := q.db.Query(ctx, listStatementBalances, arg.PeriodStart, arg.PeriodEnd, arg.EmployeeID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListStatementBalancesRow
+	for rows.Next() {
+		var i ListStatementBalancesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Username,
+			&i.CreatedAt,
+			&i.OpeningBalance,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listStatementEntries = `-- name: ListStatementEntries :many
+SELECT
+  s.employee_id::integer AS employee_id,
+  ct.id,
+  ct.transaction_type,
+  s.incoming::boolean AS incoming,
+  ct.amount,
+  COALESCE(cp.username, '')::text AS counterparty,
+  COALESCE(m.name, '')::text AS merch_name,
+  ct.quantity,
+  COALESCE(ct.message, '')::text AS message,
+  COALESCE(ct.reason, '')::text AS reason,
+  ct.created_at
+FROM coin_transactions ct
+CROSS JOIN LATERAL (VALUES (ct.to_employee_id, true), (ct.from_employee_id, false)) AS s(employee_id, incoming)
+LEFT JOIN merch m ON m.id = ct.merch_id
+LEFT JOIN transfer_holds h ON h.id = ct.hold_id
+LEFT JOIN employees cp ON cp.id = CASE
+  WHEN ct.transaction_type = 'hold_release' THEN h.sender_id
+  WHEN ct.hold_id IS NOT NULL THEN h.recipient_id
+  WHEN s.incoming THEN ct.from_employee_id
+  ELSE ct.to_employee_id
+END
+WHERE s.employee_id IS NOT NULL
+  AND ct.created_at >= $1::timestamptz
+  AND ct.created_at < $2::timestamptz
+  AND ($3::integer IS NULL OR s.employee_id = $3::integer)
+ORDER BY s.employee_id, ct.created_at, ct.id
+`
+
+type ListStatementEntriesParams struct {
+	PeriodStart pgtype.Timestamptz
+	PeriodEnd   pgtype.Timestamptz
+	EmployeeID  pgtype.Int4
+}
+
+type ListStatementEntriesRow struct {
+	EmployeeID      int32
+	ID              int32
+	TransactionType TransactionTypeEnum
+	Incoming        bool
+	Amount          int32
+	Counterparty    string
+	MerchName       string
+	Quantity        int32
+	Message         string
+	Reason          string
+	CreatedAt       pgtype.Timestamptz
+}
+
+// ListStatementEntries возвращает операции за период [period_start, period_end)
+// с точки зрения каждой из сторон: перевод попадает и в выписку отправителя, и
+// в выписку получателя. Контрагент определяется так же, как в ListCoinHistory.
+// Строки упорядочены по сотруднику и хронологически внутри выписки.
+func (q *Queries) ListStatementEntries(ctx context.Context, arg ListStatementEntriesParams) ([]ListStatementEntriesRow, error) {
+	rows, err := q.db.Query(ctx, listStatementEntries, arg.PeriodStart, arg.PeriodEnd, arg.EmployeeID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListStatementEntriesRow
+	for rows.Next() {
+		var i ListStatementEntriesRow
+		if err := rows.Scan(
+			&i.EmployeeID,
+			&i.ID,
+			&i.TransactionType,
+			&i.Incoming,
+			&i.Amount,
+			&i.Counterparty,
+			&i.MerchName,
+			&i.Quantity,
+			&i.Message,
+			&i.Reason,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -0,0 +1,411 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.27.0
+// source: transfer_holds.sql
+
+package db
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const createCoinTransactionHold = `-- name: CreateCoinTransactionHold :exec
+WITH tx AS (
+  INSERT INTO coin_transactions (transaction_type, from_employee_id, amount, message, hashtags, hold_id)
+  VALUES (
+    'hold',
+    $1::integer,
+    $2::integer,
+    $3::text,
+    $4::text[],
+    $5::integer
+  )
+  RETURNING id, from_employee_id, amount
+),
+entry AS (
+  INSERT INTO journal_entries (entry_type, coin_transaction_id)
+  SELECT 'hold', tx.id FROM tx
+  RETURNING id
+)
+INSERT INTO ledger_postings (entry_id, account_id, amount)
+SELECT entry.id, leg.account_id, leg.amount
+FROM entry, tx, LATERAL (VALUES
+  ((SELECT id FROM ledger_accounts WHERE employee_id = tx.from_employee_id), -tx.amount),
+  ((SELECT id FROM ledger_accounts WHERE code = 'escrow'), tx.amount)
+) AS leg(account_id, amount)
+`
+
+type CreateCoinTransactionHoldParams struct {
+	FromEmployeeID int32
+	Amount         int32
+	Message        pgtype.Text
+	Hashtags       []string
+	HoldID         int32
+}
+
+// ----------------------------------------------------------
+// CreateCoinTransactionHold записывает списание отправителя на счёт удержания.
+func (q *Queries) CreateCoinTransactionHold(ctx context.Context, arg CreateCoinTransactionHoldParams) error {
+	_, err := q.db.Exec(ctx, createCoinTransactionHold,
+		arg.FromEmployeeID,
+		arg.Amount,
+		arg.Message,
+		arg.Hashtags,
+		arg.HoldID,
+	)
+	return err
+}
+
+const createCoinTransactionHoldRefund = `-- name: CreateCoinTransactionHoldRefund :exec
+WITH tx AS (
+  INSERT INTO coin_transactions (transaction_type, to_employee_id, amount, hold_id)
+  VALUES ('hold_refund', $1::integer, $2::integer, $3::integer)
+  RETURNING id, to_employee_id, amount
+),
+entry AS (
+  INSERT INTO journal_entries (entry_type, coin_transaction_id)
+  SELECT 'hold_refund', tx.id FROM tx
+  RETURNING id
+)
+INSERT INTO ledger_postings (entry_id, account_id, amount)
+SELECT entry.id, leg.account_id, leg.amount
+FROM entry, tx, LATERAL (VALUES
+  ((SELECT id FROM ledger_accounts WHERE code = 'escrow'), -tx.amount),
+  ((SELECT id FROM ledger_accounts WHERE employee_id = tx.to_employee_id), tx.amount)
+) AS leg(account_id, amount)
+`
+
+type CreateCoinTransactionHoldRefundParams struct {
+	ToEmployeeID int32
+	Amount       int32
+	HoldID       int32
+}
+
+// ----------------------------------------------------------
+// CreateCoinTransactionHoldRefund записывает возврат удержанных монет отправителю.
+func (q *Queries) CreateCoinTransactionHoldRefund(ctx context.Context, arg CreateCoinTransactionHoldRefundParams) error {
+	_, err := q.db.Exec(ctx, createCoinTransactionHoldRefund, arg.ToEmployeeID, arg.Amount, arg.HoldID)
+	return err
+}
+
+const createCoinTransactionHoldRelease = `-- name: CreateCoinTransactionHoldRelease :exec
+WITH tx AS (
+  INSERT INTO coin_transactions (transaction_type, to_employee_id, amount, hold_id)
+  VALUES ('hold_release', $1::integer, $2::integer, $3::integer)
+  RETURNING id, to_employee_id, amount
+),
+entry AS (
+  INSERT INTO journal_entries (entry_type, coin_transaction_id)
+  SELECT 'hold_release', tx.id FROM tx
+  RETURNING id
+)
+INSERT INTO ledger_postings (entry_id, account_id, amount)
+SELECT entry.id, leg.account_id, leg.amount
+FROM entry, tx, LATERAL (VALUES
+  ((SELECT id FROM ledger_accounts WHERE code = 'escrow'), -tx.amount),
+  ((SELECT id FROM ledger_accounts WHERE employee_id = tx.to_employee_id), tx.amount)
+) AS leg(account_id, amount)
+`
+
+type CreateCoinTransactionHoldReleaseParams struct {
+	ToEmployeeID int32
+	Amount       int32
+	HoldID       int32
+}
+
+// ----------------------------------------------------------
+// CreateCoinTransactionHoldRelease записывает зачисление удержанных монет получателю.
+func (q *Queries) CreateCoinTransactionHoldRelease(ctx context.Context, arg CreateCoinTransactionHoldReleaseParams) error {
+	_, err := q.db.Exec(ctx, createCoinTransactionHoldRelease, arg.ToEmployeeID, arg.Amount, arg.HoldID)
+	return err
+}
+
+const createTransferHold = `-- name: CreateTransferHold :one
+INSERT INTO transfer_holds (sender_id, recipient_id, amount, message, hashtags, expires_at)
+VALUES (
+  $1::integer,
+  $2::integer,
+  $3::integer,
+  $4::text,
+  $5::text[],
+  $6::timestamptz
+)
+RETURNING id, sender_id, recipient_id, amount, message, hashtags, status, created_at, expires_at, resolved_at
+`
+
+type CreateTransferHoldParams struct {
+	SenderID    int32
+	RecipientID int32
+	Amount      int32
+	Message     pgtype.Text
+	Hashtags    []string
+	ExpiresAt   pgtype.Timestamptz
+}
+
+// CreateTransferHold создаёт ожидающий решения получателя перевод.
+func (q *Queries) CreateTransferHold(ctx context.Context, arg CreateTransferHoldParams) (TransferHold, error) {
+	row := q.db.QueryRow(ctx, createTransferHold,
+		arg.SenderID,
+		arg.RecipientID,
+		arg.Amount,
+		arg.Message,
+		arg.Hashtags,
+		arg.ExpiresAt,
+	)
+	var i TransferHold
+	err := row.Scan(
+		&i.ID,
+		&i.SenderID,
+		&i.RecipientID,
+		&i.Amount,
+		&i.Message,
+		&i.Hashtags,
+		&i.Status,
+		&i.CreatedAt,
+		&i.ExpiresAt,
+		&i.ResolvedAt,
+	)
+	return i, err
+}
+
+const createTransferHoldLot = `-- name: CreateTransferHoldLot :exec
+INSERT INTO transfer_hold_lots (hold_id, amount, granted_at, expires_at)
+VALUES (
+  $1::integer,
+  $2::integer,
+  $3::timestamptz,
+  $4::timestamptz
+)
+`
+
+type CreateTransferHoldLotParams struct {
+	HoldID    int32
+	Amount    int32
+	GrantedAt pgtype.Timestamptz
+	ExpiresAt pgtype.Timestamptz
+}
+
+// ----------------------------------------------------------
+// CreateTransferHoldLot сохраняет часть партии, списанную на удержание.
+func (q *Queries) CreateTransferHoldLot(ctx context.Context, arg CreateTransferHoldLotParams) error {
+	_, err := q.db.Exec(ctx, createTransferHoldLot,
+		arg.HoldID,
+		arg.Amount,
+		arg.GrantedAt,
+		arg.ExpiresAt,
+	)
+	return err
+}
+
+const getTransferHoldForUpdate = `-- name: GetTransferHoldForUpdate :one
+SELECT id, sender_id, recipient_id, amount, message, hashtags, status, created_at, expires_at, resolved_at
+FROM transfer_holds
+WHERE id = $1
+FOR UPDATE
+`
+
+// ----------------------------------------------------------
+// GetTransferHoldForUpdate возвращает удержание и блокирует его до конца транзакции.
+func (q *Queries) GetTransferHoldForUpdate(ctx context.Context, id int32) (TransferHold, error) {
+	row := q.db.QueryRow(ctx, getTransferHoldForUpdate, id)
+	var i TransferHold
+	err := row.Scan(
+		&i.ID,
+		&i.SenderID,
+		&i.RecipientID,
+		&i.Amount,
+		&i.Message,
+		&i.Hashtags,
+		&i.Status,
+		&i.CreatedAt,
+		&i.ExpiresAt,
+		&i.ResolvedAt,
+	)
+	return i, err
+}
+
+const listExpiredTransferHolds = `-- name: ListExpiredTransferHolds :many
+SELECT id, sender_id, recipient_id, amount, message, hashtags, status, created_at, expires_at, resolved_at
+FROM transfer_holds
+WHERE status = 'pending' AND expires_at <= NOW()
+ORDER BY id
+LIMIT $1::integer
+FOR UPDATE SKIP LOCKED
+`
+
+// ----------------------------------------------------------
+// ListExpiredTransferHolds блокирует очередную порцию ожидающих удержаний
+// с истёкшим сроком. Заблокированные другими транзакциями пропускаются.
+func (q *Queries) ListExpiredTransferHolds(ctx context.Context, batchSize int32) ([]TransferHold, error) {
+	rows, err := q.db.Query(ctx, listExpiredTransferHolds, batchSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []TransferHold
+	for rows.Next() {
+		var i TransferHold
+		if err := rows.Scan(
+			&i.ID,
+			&i.SenderID,
+			&i.RecipientID,
+			&i.Amount,
+			&i.Message,
+			&i.Hashtags,
+			&i.Status,
+			&i.CreatedAt,
+			&i.ExpiresAt,
+			&i.ResolvedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTransferHoldLots = `-- name: ListTransferHoldLots :many
+SELECT amount, granted_at, expires_at
+FROM transfer_hold_lots
+WHERE hold_id = $1::integer
+ORDER BY expires_at, id
+`
+
+type ListTransferHoldLotsRow struct {
+	Amount    int32
+	GrantedAt pgtype.Timestamptz
+	ExpiresAt pgtype.Timestamptz
+}
+
+// ----------------------------------------------------------
+// ListTransferHoldLots возвращает части партий удержания.
+func (q *Queries) ListTransferHoldLots(ctx context.Context, holdID int32) ([]ListTransferHoldLotsRow, error) {
+	rows, err := q.db.Query(ctx, listTransferHoldLots, holdID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListTransferHoldLotsRow
+	for rows.Next() {
+		var i ListTransferHoldLotsRow
+		if err := rows.Scan(&i.Amount, &i.GrantedAt, &i.ExpiresAt); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTransferHolds = `-- name: ListTransferHolds :many
+SELECT
+  h.id,
+  sender.username AS sender,
+  recipient.username AS recipient,
+  h.amount,
+  COALESCE(h.message, '')::text AS message,
+  h.hashtags,
+  (CASE WHEN h.status = 'pending' AND h.expires_at <= NOW() THEN 'expired' ELSE h.status END)::transfer_hold_status_enum AS status,
+  h.created_at,
+  h.expires_at,
+  h.resolved_at
+FROM transfer_holds h
+JOIN employees sender ON sender.id = h.sender_id
+JOIN employees recipient ON recipient.id = h.recipient_id
+WHERE ($1::integer IS NULL OR h.id = $1::integer)
+  AND ($2::integer IS NULL OR h.sender_id = $2::integer)
+  AND ($3::integer IS NULL OR h.recipient_id = $3::integer)
+  AND (
+    $4::transfer_hold_status_enum IS NULL
+    OR (CASE WHEN h.status = 'pending' AND h.expires_at <= NOW() THEN 'expired' ELSE h.status END) = $4::transfer_hold_status_enum
+  )
+ORDER BY h.id DESC
+LIMIT 100
+`
+
+type ListTransferHoldsParams struct {
+	ID          pgtype.Int4
+	SenderID    pgtype.Int4
+	RecipientID pgtype.Int4
+	Status      NullTransferHoldStatusEnum
+}
+
+type ListTransferHoldsRow struct {
+	ID         int32
+	Sender     string
+	Recipient  string
+	Amount     int32
+	Message    string
+	Hashtags   []string
+	Status     TransferHoldStatusEnum
+	CreatedAt  pgtype.Timestamptz
+	ExpiresAt  pgtype.Timestamptz
+	ResolvedAt pgtype.Timestamptz
+}
+
+// ----------------------------------------------------------
+// ListTransferHolds возвращает последние 100 удержаний. NULL в фильтре означает
+// отсутствие ограничения по этому полю. Ожидающее удержание с истёкшим сроком
+// показывается как expired, даже если фоновая задача ещё не вернула монеты.
+func (q *Queries) ListTransferHolds(ctx context.Context, arg ListTransferHoldsParams) ([]ListTransferHoldsRow, error) {
+	rows, err := q.db.Query(ctx, listTransferHolds,
+		arg.ID,
+		arg.SenderID,
+		arg.RecipientID,
+		arg.Status,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListTransferHoldsRow
+	for rows.Next() {
+		var i ListTransferHoldsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Sender,
+			&i.Recipient,
+			&i.Amount,
+			&i.Message,
+			&i.Hashtags,
+			&i.Status,
+			&i.CreatedAt,
+			&i.ExpiresAt,
+			&i.ResolvedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const resolveTransferHold = `-- name: ResolveTransferHold :exec
+UPDATE transfer_holds
+SET
+  status = $1,
+  resolved_at = NOW()
+WHERE id = $2 AND status = 'pending'
+`
+
+type ResolveTransferHoldParams struct {
+	Status TransferHoldStatusEnum
+	ID     int32
+}
+
+// ----------------------------------------------------------
+// ResolveTransferHold закрывает ожидающее удержание с указанным итогом.
+func (q *Queries) ResolveTransferHold(ctx context.Context, arg ResolveTransferHoldParams) error {
+	_, err := q.db.Exec(ctx, resolveTransferHold, arg.Status, arg.ID)
+	return err
+}
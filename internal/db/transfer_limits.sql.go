@@ -0,0 +1,137 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.27.0
+// source: transfer_limits.sql
+
+package db
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const deleteTransferLimitOverride = `-- name: DeleteTransferLimitOverride :execrows
+DELETE FROM transfer_limit_overrides
+WHERE employee_id = $1::integer
+`
+
+// ----------------------------------------------------------
+// DeleteTransferLimitOverride возвращает сотруднику лимиты по умолчанию.
+func (q *Queries) DeleteTransferLimitOverride(ctx context.Context, employeeID int32) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteTransferLimitOverride, employeeID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const getOutgoingTransferTotals = `-- name: GetOutgoingTransferTotals :one
+SELECT
+  COALESCE(SUM(ct.amount) FILTER (WHERE ct.created_at >= $1::timestamptz), 0)::bigint AS sent_today,
+  COALESCE(SUM(ct.amount), 0)::bigint AS sent_this_month,
+  COALESCE(SUM(ct.amount) FILTER (
+    WHERE COALESCE(ct.to_employee_id, h.recipient_id) = $2::integer
+  ), 0)::bigint AS sent_to_recipient
+FROM coin_transactions ct
+LEFT JOIN transfer_holds h ON h.id = ct.hold_id
+WHERE ct.transaction_type IN ('transfer', 'hold')
+  AND ct.from_employee_id = $3::integer
+  AND ct.created_at >= $4::timestamptz
+  AND (h.status IS NULL OR h.status IN ('pending', 'accepted'))
+`
+
+type GetOutgoingTransferTotalsParams struct {
+	DayStart    pgtype.Timestamptz
+	RecipientID int32
+	SenderID    int32
+	MonthStart  pgtype.Timestamptz
+}
+
+type GetOutgoingTransferTotalsRow struct {
+	SentToday       int64
+	SentThisMonth   int64
+	SentToRecipient int64
+}
+
+// ----------------------------------------------------------
+// GetOutgoingTransferTotals возвращает суммы исходящих переводов сотрудника
+// с начала дня, с начала месяца и получателю recipient_id с начала месяца.
+// Удержанные переводы учитываются с момента создания, пока монеты не
+// вернулись отправителю.
+// Переводы текущей транзакции тоже учитываются.
+func (q *Queries) GetOutgoingTransferTotals(ctx context.Context, arg GetOutgoingTransferTotalsParams) (GetOutgoingTransferTotalsRow, error) {
+	row := q.db.QueryRow(ctx, getOutgoingTransferTotals,
+		arg.DayStart,
+		arg.RecipientID,
+		arg.SenderID,
+		arg.MonthStart,
+	)
+	var i GetOutgoingTransferTotalsRow
+	err := row.Scan(&i.SentToday, &i.SentThisMonth, &i.SentToRecipient)
+	return i, err
+}
+
+const getTransferLimitOverride = `-- name: GetTransferLimitOverride :one
+SELECT
+  o.daily_limit,
+  o.monthly_limit,
+  o.recipient_limit
+FROM employees e
+LEFT JOIN transfer_limit_overrides o ON o.employee_id = e.id
+WHERE e.id = $1::integer
+`
+
+type GetTransferLimitOverrideRow struct {
+	DailyLimit     pgtype.Int4
+	MonthlyLimit   pgtype.Int4
+	RecipientLimit pgtype.Int4
+}
+
+// GetTransferLimitOverride возвращает индивидуальные лимиты сотрудника.
+// Если переопределений нет, все значения NULL.
+func (q *Queries) GetTransferLimitOverride(ctx context.Context, employeeID int32) (GetTransferLimitOverrideRow, error) {
+	row := q.db.QueryRow(ctx, getTransferLimitOverride, employeeID)
+	var i GetTransferLimitOverrideRow
+	err := row.Scan(&i.DailyLimit, &i.MonthlyLimit, &i.RecipientLimit)
+	return i, err
+}
+
+const upsertTransferLimitOverride = `-- name: UpsertTransferLimitOverride :exec
+INSERT INTO transfer_limit_overrides (employee_id, daily_limit, monthly_limit, recipient_limit, updated_by)
+VALUES (
+  $1::integer,
+  $2::integer,
+  $3::integer,
+  $4::integer,
+  $5::integer
+)
+ON CONFLICT (employee_id) DO UPDATE
+SET
+  daily_limit = EXCLUDED.daily_limit,
+  monthly_limit = EXCLUDED.monthly_limit,
+  recipient_limit = EXCLUDED.recipient_limit,
+  updated_by = EXCLUDED.updated_by,
+  updated_at = NOW()
+`
+
+type UpsertTransferLimitOverrideParams struct {
+	EmployeeID     int32
+	DailyLimit     pgtype.Int4
+	MonthlyLimit   pgtype.Int4
+	RecipientLimit pgtype.Int4
+	UpdatedBy      int32
+}
+
+// ----------------------------------------------------------
+// UpsertTransferLimitOverride задаёт индивидуальные лимиты сотрудника.
+func (q *Queries) UpsertTransferLimitOverride(ctx context.Context, arg UpsertTransferLimitOverrideParams) error {
+	_, err := q.db.Exec(ctx, upsertTransferLimitOverride,
+		arg.EmployeeID,
+		arg.DailyLimit,
+		arg.MonthlyLimit,
+		arg.RecipientLimit,
+		arg.UpdatedBy,
+	)
+	return err
+}
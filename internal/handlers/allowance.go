@@ -0,0 +1,32 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/par1ram/merch-store/internal/service"
+	"github.com/par1ram/merch-store/internal/utils"
+)
+
+type AllowanceHandler struct {
+	AllowanceService service.AllowanceService
+}
+
+func NewAllowanceHandler(allowanceService service.AllowanceService) *AllowanceHandler {
+	return &AllowanceHandler{AllowanceService: allowanceService}
+}
+
+// GET /api/admin/allowance/preview?period=YYYY-MM
+// Пробный запуск: показывает, кому и сколько будет начислено, ничего не меняя.
+func (h *AllowanceHandler) HandlePreview(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.JSONErrorResponse(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	preview, err := h.AllowanceService.Preview(r.Context(), r.URL.Query().Get("period"))
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	utils.JSONResponse(w, http.StatusOK, preview)
+}
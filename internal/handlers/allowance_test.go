@@ -0,0 +1,67 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/par1ram/merch-store/internal/handlers"
+	"github.com/par1ram/merch-store/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockAllowanceService struct {
+	mock.Mock
+}
+
+func (m *MockAllowanceService) Accrue(ctx context.Context, now time.Time) (service.AllowanceAccrual, error) {
+	args := m.Called(ctx, now)
+	return args.Get(0).(service.AllowanceAccrual), args.Error(1)
+}
+
+func (m *MockAllowanceService) Preview(ctx context.Context, period string) (service.AllowancePreview, error) {
+	args := m.Called(ctx, period)
+	return args.Get(0).(service.AllowancePreview), args.Error(1)
+}
+
+func TestAllowanceHandler_HandlePreview(t *testing.T) {
+	expected := service.AllowancePreview{
+		Period:      "2026-11",
+		Enabled:     true,
+		Amount:      100,
+		TotalAmount: 100,
+		Recipients:  []service.AllowanceRecipient{{Username: "alice", Amount: 100}},
+	}
+
+	mockService := new(MockAllowanceService)
+	mockService.On("Preview", mock.Anything, "2026-11").Return(expected, nil).Once()
+	mockService.On("Preview", mock.Anything, "bad").
+		Return(service.AllowancePreview{}, fmt.Errorf("%w: %w", service.ErrBusinessValidation, service.ErrInvalidAllowancePeriod)).Once()
+
+	handler := handlers.NewAllowanceHandler(mockService)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/admin/allowance/preview?period=2026-11", nil)
+	rr := httptest.NewRecorder()
+	handler.HandlePreview(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var resp service.AllowancePreview
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+	assert.Equal(t, expected, resp)
+
+	req = httptest.NewRequest(http.MethodGet, "/api/admin/allowance/preview?period=bad", nil)
+	rr = httptest.NewRecorder()
+	handler.HandlePreview(rr, req)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	req = httptest.NewRequest(http.MethodPost, "/api/admin/allowance/preview", nil)
+	rr = httptest.NewRecorder()
+	handler.HandlePreview(rr, req)
+	assert.Equal(t, http.StatusMethodNotAllowed, rr.Code)
+
+	mockService.AssertExpectations(t)
+}
@@ -5,11 +5,14 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
 	"github.com/par1ram/merch-store/internal/handlers"
+	"github.com/par1ram/merch-store/internal/repository"
+	"github.com/par1ram/merch-store/internal/service"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -24,6 +27,11 @@ func (m *MockAuthService) Authenticate(ctx context.Context, username, password s
 	return args.String(0), args.Error(1)
 }
 
+func (m *MockAuthService) SetRole(ctx context.Context, username, role string) (repository.User, error) {
+	args := m.Called(ctx, username, role)
+	return args.Get(0).(repository.User), args.Error(1)
+}
+
 func TestAuthHandler_HandleAuth_Success(t *testing.T) {
 	// Подготавливаем корректный JSON-тело запроса.
 	reqBody := map[string]string{
@@ -135,3 +143,51 @@ func TestAuthHandler_HandleAuth_AuthError(t *testing.T) {
 
 	mockAuthService.AssertExpectations(t)
 }
+
+func TestAuthHandler_HandleSetRole_Success(t *testing.T) {
+	mockAuthService := new(MockAuthService)
+	mockAuthService.
+		On("SetRole", mock.Anything, "alice", "manager").
+		Return(repository.User{ID: 5, Username: "alice", Role: "manager"}, nil).
+		Once()
+
+	req := httptest.NewRequest(http.MethodPut, "/api/admin/employees/alice/role", bytes.NewBufferString(`{"role":"manager"}`))
+	rr := httptest.NewRecorder()
+
+	authHandler := handlers.NewAuthHandler(mockAuthService)
+	authHandler.HandleSetRole(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var resp handlers.SetRoleResponse
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+	assert.Equal(t, handlers.SetRoleResponse{Username: "alice", Role: "manager"}, resp)
+
+	mockAuthService.AssertExpectations(t)
+}
+
+func TestAuthHandler_HandleSetRole_Errors(t *testing.T) {
+	cases := []struct {
+		err    error
+		status int
+	}{
+		{fmt.Errorf("%w: %w", service.ErrBusinessValidation, service.ErrUserNotFound), http.StatusNotFound},
+		{fmt.Errorf("%w: %w", service.ErrBusinessValidation, service.ErrUnknownRole), http.StatusBadRequest},
+		{errors.New("db error"), http.StatusInternalServerError},
+	}
+
+	for _, tc := range cases {
+		mockAuthService := new(MockAuthService)
+		mockAuthService.On("SetRole", mock.Anything, "bob", "admin").
+			Return(repository.User{}, tc.err).
+			Once()
+
+		req := httptest.NewRequest(http.MethodPut, "/api/admin/employees/bob/role", bytes.NewBufferString(`{"role":"admin"}`))
+		rr := httptest.NewRecorder()
+
+		authHandler := handlers.NewAuthHandler(mockAuthService)
+		authHandler.HandleSetRole(rr, req)
+
+		assert.Equal(t, tc.status, rr.Code)
+		mockAuthService.AssertExpectations(t)
+	}
+}
@@ -3,8 +3,10 @@ package handlers
 import (
 	"encoding/json"
 	"net/http"
+	"strings"
 
 	"github.com/par1ram/merch-store/internal/service"
+	"github.com/par1ram/merch-store/internal/utils"
 
 	"github.com/sirupsen/logrus"
 )
@@ -34,19 +36,19 @@ type AuthResponse struct {
 func (h *AuthHandler) HandleAuth(w http.ResponseWriter, r *http.Request) {
 	var req AuthRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "invalid request", http.StatusBadRequest)
+		utils.JSONErrorResponse(w, http.StatusBadRequest, "invalid request")
 		return
 	}
 
 	if req.Username == "" || req.Password == "" {
-		http.Error(w, "username and password are required", http.StatusBadRequest)
+		utils.JSONErrorResponse(w, http.StatusBadRequest, "username and password are required")
 		return
 	}
 
 	// Вызываем сервис для аутентификации.
 	token, err := h.AuthService.Authenticate(r.Context(), req.Username, req.Password)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusUnauthorized)
+		utils.JSONErrorResponse(w, http.StatusUnauthorized, err.Error())
 		return
 	}
 
@@ -56,3 +58,43 @@ func (h *AuthHandler) HandleAuth(w http.ResponseWriter, r *http.Request) {
 		logrus.WithError(err).Error("failed to encode auth response")
 	}
 }
+
+// SetRoleRequest – тело запроса на смену роли.
+type SetRoleRequest struct {
+	Role string `json:"role"`
+}
+
+// SetRoleResponse – ответ с актуальной ролью пользователя.
+type SetRoleResponse struct {
+	Username string `json:"username"`
+	Role     string `json:"role"`
+}
+
+// HandleSetRole обрабатывает PUT /api/admin/employees/{username}/role.
+func (h *AuthHandler) HandleSetRole(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		utils.JSONErrorResponse(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	path := strings.TrimPrefix(r.URL.Path, "/api/admin/employees/")
+	username, ok := strings.CutSuffix(path, "/role")
+	if !ok || username == "" || strings.Contains(username, "/") {
+		utils.JSONErrorResponse(w, http.StatusNotFound, "not found")
+		return
+	}
+
+	var req SetRoleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.JSONErrorResponse(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	user, err := h.AuthService.SetRole(r.Context(), username, req.Role)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	utils.JSONResponse(w, http.StatusOK, SetRoleResponse{Username: user.Username, Role: user.Role})
+}
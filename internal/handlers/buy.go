@@ -1,13 +1,22 @@
 package handlers
 
 import (
+	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/par1ram/merch-store/internal/service"
 	"github.com/par1ram/merch-store/internal/utils"
 )
 
+// BuyRequest — необязательное тело запроса на покупку.
+type BuyRequest struct {
+	Quantity int32 `json:"quantity"`
+}
+
 type BuyHandler struct {
 	BuyService service.BuyService
 }
@@ -18,6 +27,8 @@ func NewBuyHandler(buyService service.BuyService) *BuyHandler {
 	}
 }
 
+// HandleBuy обрабатывает /api/buy/{item}. Количество задаётся параметром
+// ?qty=N или полем quantity в JSON-теле; по умолчанию покупается одна единица.
 func (h *BuyHandler) HandleBuy(w http.ResponseWriter, r *http.Request) {
 	// Удаляем префикс "/api/buy/" из URL, чтобы получить название товара.
 	item := strings.TrimPrefix(r.URL.Path, "/api/buy/")
@@ -26,10 +37,45 @@ func (h *BuyHandler) HandleBuy(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.BuyService.Purchase(r.Context(), item); err != nil {
-		utils.JSONErrorResponse(w, http.StatusInternalServerError, err.Error())
+	quantity := int32(1)
+	if raw := r.URL.Query().Get("qty"); raw != "" {
+		qty, err := strconv.ParseInt(raw, 10, 32)
+		if err != nil || qty <= 0 {
+			utils.JSONErrorResponse(w, http.StatusBadRequest, "qty must be a positive integer")
+			return
+		}
+		quantity = int32(qty)
+	} else if r.Body != nil {
+		var req BuyRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+			utils.JSONErrorResponse(w, http.StatusBadRequest, "invalid request body")
+			return
+		}
+		if req.Quantity != 0 {
+			quantity = req.Quantity
+		}
+	}
+
+	if err := h.BuyService.Purchase(r.Context(), item, quantity); err != nil {
+		writeServiceError(w, err)
 		return
 	}
 
 	utils.JSONResponse(w, http.StatusOK, map[string]string{"status": "purchase successful"})
 }
+
+// POST /api/checkout
+func (h *BuyHandler) HandleCheckout(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.JSONErrorResponse(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	receipt, err := h.BuyService.Checkout(r.Context())
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	utils.JSONResponse(w, http.StatusOK, receipt)
+}
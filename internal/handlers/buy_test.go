@@ -4,12 +4,15 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/par1ram/merch-store/internal/handlers"
 	"github.com/par1ram/merch-store/internal/middleware"
+	"github.com/par1ram/merch-store/internal/service"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -18,15 +21,20 @@ type MockBuyService struct {
 	mock.Mock
 }
 
-func (m *MockBuyService) Purchase(ctx context.Context, item string) error {
-	args := m.Called(ctx, item)
+func (m *MockBuyService) Purchase(ctx context.Context, item string, quantity int32) error {
+	args := m.Called(ctx, item, quantity)
 	return args.Error(0)
 }
 
+func (m *MockBuyService) Checkout(ctx context.Context) (service.CartResponse, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(service.CartResponse), args.Error(1)
+}
+
 func TestBuyHandler_HandleBuy_Success(t *testing.T) {
 	// Создаем MockBuyService
 	mockBuyService := new(MockBuyService)
-	mockBuyService.On("Purchase", mock.Anything, "testItem").Return(nil)
+	mockBuyService.On("Purchase", mock.Anything, "testItem", int32(1)).Return(nil)
 
 	// Создаем BuyHandler
 	buyHandler := handlers.NewBuyHandler(mockBuyService)
@@ -54,7 +62,7 @@ func TestBuyHandler_HandleBuy_Success(t *testing.T) {
 func TestBuyHandler_HandleBuy_Error(t *testing.T) {
 	// Создаем MockBuyService, который возвращает ошибку
 	mockBuyService := new(MockBuyService)
-	mockBuyService.On("Purchase", mock.Anything, "testItem").Return(errors.New("some error"))
+	mockBuyService.On("Purchase", mock.Anything, "testItem", int32(1)).Return(errors.New("some error"))
 
 	// Создаем BuyHandler
 	buyHandler := handlers.NewBuyHandler(mockBuyService)
@@ -74,3 +82,115 @@ func TestBuyHandler_HandleBuy_Error(t *testing.T) {
 	// Проверяем, что MockBuyService был вызван
 	mockBuyService.AssertExpectations(t)
 }
+
+func TestBuyHandler_HandleBuy_OutOfStock(t *testing.T) {
+	mockBuyService := new(MockBuyService)
+	mockBuyService.On("Purchase", mock.Anything, "pink-hoody", int32(1)).
+		Return(fmt.Errorf("%w: %w", service.ErrBusinessValidation, service.ErrOutOfStock))
+
+	buyHandler := handlers.NewBuyHandler(mockBuyService)
+
+	req := httptest.NewRequest("GET", "/api/buy/pink-hoody", nil)
+	w := httptest.NewRecorder()
+	buyHandler.HandleBuy(w, req)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Contains(t, w.Body.String(), "out of stock")
+
+	mockBuyService.AssertExpectations(t)
+}
+
+func TestBuyHandler_HandleBuy_Quantity(t *testing.T) {
+	mockBuyService := new(MockBuyService)
+	mockBuyService.On("Purchase", mock.Anything, "socks", int32(3)).Return(nil).Twice()
+
+	buyHandler := handlers.NewBuyHandler(mockBuyService)
+
+	// Количество в параметре запроса.
+	req := httptest.NewRequest("GET", "/api/buy/socks?qty=3", nil)
+	w := httptest.NewRecorder()
+	buyHandler.HandleBuy(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// Количество в JSON-теле.
+	req = httptest.NewRequest("POST", "/api/buy/socks", strings.NewReader(`{"quantity":3}`))
+	w = httptest.NewRecorder()
+	buyHandler.HandleBuy(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	mockBuyService.AssertExpectations(t)
+}
+
+func TestBuyHandler_HandleBuy_InvalidQuantity(t *testing.T) {
+	mockBuyService := new(MockBuyService)
+	buyHandler := handlers.NewBuyHandler(mockBuyService)
+
+	for _, qty := range []string{"0", "-2", "abc"} {
+		req := httptest.NewRequest("GET", "/api/buy/socks?qty="+qty, nil)
+		w := httptest.NewRecorder()
+		buyHandler.HandleBuy(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code, qty)
+	}
+
+	mockBuyService.AssertNotCalled(t, "Purchase", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestBuyHandler_HandleCheckout(t *testing.T) {
+	receipt := service.CartResponse{
+		Items: []service.CartLine{{Item: "socks", Quantity: 3, UnitPrice: 10, Amount: 30, Available: true}},
+		Total: 30,
+	}
+
+	mockBuyService := new(MockBuyService)
+	mockBuyService.On("Checkout", mock.Anything).Return(receipt, nil).Once()
+	mockBuyService.On("Checkout", mock.Anything).
+		Return(service.CartResponse{}, fmt.Errorf("%w: %w", service.ErrBusinessValidation, service.ErrCartEmpty)).
+		Once()
+
+	buyHandler := handlers.NewBuyHandler(mockBuyService)
+
+	w := httptest.NewRecorder()
+	buyHandler.HandleCheckout(w, httptest.NewRequest("POST", "/api/checkout", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp service.CartResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, receipt, resp)
+
+	w = httptest.NewRecorder()
+	buyHandler.HandleCheckout(w, httptest.NewRequest("POST", "/api/checkout", nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	mockBuyService.AssertExpectations(t)
+}
+
+func TestBuyHandler_HandleBuy_ErrorCodes(t *testing.T) {
+	cases := []struct {
+		err    error
+		status int
+		code   string
+	}{
+		{service.ErrInsufficientFunds, http.StatusPaymentRequired, "INSUFFICIENT_FUNDS"},
+		{fmt.Errorf("%w: testItem", service.ErrMerchNotFound), http.StatusNotFound, "ITEM_NOT_FOUND"},
+		{fmt.Errorf("%w: %w", service.ErrBusinessValidation, service.ErrOutOfStock), http.StatusConflict, "OUT_OF_STOCK"},
+		{fmt.Errorf("%w: %w", service.ErrBusinessValidation, service.ErrInvalidQuantity), http.StatusBadRequest, "INVALID_QUANTITY"},
+		{errors.New("connection reset"), http.StatusInternalServerError, "INTERNAL_ERROR"},
+	}
+
+	for _, tc := range cases {
+		mockBuyService := new(MockBuyService)
+		mockBuyService.On("Purchase", mock.Anything, "testItem", int32(1)).Return(tc.err).Once()
+
+		buyHandler := handlers.NewBuyHandler(mockBuyService)
+		req := httptest.NewRequest("GET", "/api/buy/testItem", nil)
+		w := httptest.NewRecorder()
+		buyHandler.HandleBuy(w, req)
+
+		assert.Equal(t, tc.status, w.Code, tc.err.Error())
+		var resp map[string]string
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, tc.code, resp["code"])
+		assert.NotContains(t, resp["error"], "connection reset")
+
+		mockBuyService.AssertExpectations(t)
+	}
+}
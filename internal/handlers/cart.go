@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/par1ram/merch-store/internal/service"
+	"github.com/par1ram/merch-store/internal/utils"
+)
+
+// AddCartItemRequest — тело запроса на добавление товара в корзину.
+// Quantity по умолчанию равно 1.
+type AddCartItemRequest struct {
+	Item     string `json:"item"`
+	Quantity int32  `json:"quantity"`
+}
+
+type CartHandler struct {
+	CartService service.CartService
+}
+
+func NewCartHandler(cartService service.CartService) *CartHandler {
+	return &CartHandler{CartService: cartService}
+}
+
+// GET, POST /api/cart
+func (h *CartHandler) HandleCart(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		cart, err := h.CartService.GetCart(r.Context())
+		if err != nil {
+			writeServiceError(w, err)
+			return
+		}
+		utils.JSONResponse(w, http.StatusOK, cart)
+
+	case http.MethodPost:
+		var req AddCartItemRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			utils.JSONErrorResponse(w, http.StatusBadRequest, "invalid request body")
+			return
+		}
+		if req.Item == "" {
+			utils.JSONErrorResponse(w, http.StatusBadRequest, "item is required")
+			return
+		}
+		if req.Quantity == 0 {
+			req.Quantity = 1
+		}
+
+		if err := h.CartService.AddItem(r.Context(), req.Item, req.Quantity); err != nil {
+			writeServiceError(w, err)
+			return
+		}
+		utils.JSONResponse(w, http.StatusOK, map[string]string{"status": "item added"})
+
+	default:
+		utils.JSONErrorResponse(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+// DELETE /api/cart/{item}
+func (h *CartHandler) HandleCartItem(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		utils.JSONErrorResponse(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	item := strings.TrimPrefix(r.URL.Path, "/api/cart/")
+	if item == "" {
+		utils.JSONErrorResponse(w, http.StatusBadRequest, "item is required in URL")
+		return
+	}
+
+	if err := h.CartService.RemoveItem(r.Context(), item); err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	utils.JSONResponse(w, http.StatusOK, map[string]string{"status": "item removed"})
+}
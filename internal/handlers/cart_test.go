@@ -0,0 +1,101 @@
+package handlers_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/par1ram/merch-store/internal/handlers"
+	"github.com/par1ram/merch-store/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockCartService struct {
+	mock.Mock
+}
+
+func (m *MockCartService) AddItem(ctx context.Context, item string, quantity int32) error {
+	args := m.Called(ctx, item, quantity)
+	return args.Error(0)
+}
+
+func (m *MockCartService) RemoveItem(ctx context.Context, item string) error {
+	args := m.Called(ctx, item)
+	return args.Error(0)
+}
+
+func (m *MockCartService) GetCart(ctx context.Context) (service.CartResponse, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(service.CartResponse), args.Error(1)
+}
+
+func TestCartHandler_HandleCart_Get(t *testing.T) {
+	expected := service.CartResponse{
+		Items: []service.CartLine{{Item: "cup", Quantity: 2, UnitPrice: 20, Amount: 40, Available: true}},
+		Total: 40,
+	}
+
+	mockService := new(MockCartService)
+	mockService.On("GetCart", mock.Anything).Return(expected, nil).Once()
+
+	handler := handlers.NewCartHandler(mockService)
+	rr := httptest.NewRecorder()
+	handler.HandleCart(rr, httptest.NewRequest(http.MethodGet, "/api/cart", nil))
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var resp service.CartResponse
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+	assert.Equal(t, expected, resp)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestCartHandler_HandleCart_Add(t *testing.T) {
+	mockService := new(MockCartService)
+	mockService.On("AddItem", mock.Anything, "socks", int32(1)).Return(nil).Once()
+	mockService.On("AddItem", mock.Anything, "yacht", int32(2)).
+		Return(fmt.Errorf("%w: %w", service.ErrBusinessValidation, service.ErrMerchNotFound)).
+		Once()
+
+	handler := handlers.NewCartHandler(mockService)
+
+	// Без quantity добавляется одна единица.
+	rr := httptest.NewRecorder()
+	handler.HandleCart(rr, httptest.NewRequest(http.MethodPost, "/api/cart", bytes.NewBufferString(`{"item":"socks"}`)))
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	rr = httptest.NewRecorder()
+	handler.HandleCart(rr, httptest.NewRequest(http.MethodPost, "/api/cart", bytes.NewBufferString(`{"item":"yacht","quantity":2}`)))
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+
+	rr = httptest.NewRecorder()
+	handler.HandleCart(rr, httptest.NewRequest(http.MethodPost, "/api/cart", bytes.NewBufferString(`{}`)))
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestCartHandler_HandleCartItem_Remove(t *testing.T) {
+	mockService := new(MockCartService)
+	mockService.On("RemoveItem", mock.Anything, "socks").Return(nil).Once()
+	mockService.On("RemoveItem", mock.Anything, "cup").
+		Return(fmt.Errorf("%w: %w", service.ErrBusinessValidation, service.ErrCartItemNotFound)).
+		Once()
+
+	handler := handlers.NewCartHandler(mockService)
+
+	rr := httptest.NewRecorder()
+	handler.HandleCartItem(rr, httptest.NewRequest(http.MethodDelete, "/api/cart/socks", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	rr = httptest.NewRecorder()
+	handler.HandleCartItem(rr, httptest.NewRequest(http.MethodDelete, "/api/cart/cup", nil))
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+
+	mockService.AssertExpectations(t)
+}
@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/par1ram/merch-store/internal/service"
+	"github.com/par1ram/merch-store/internal/utils"
+)
+
+// CreateCoinRequestRequest — запрос монет у сотрудника to_user.
+// Note — необязательное сообщение, до 280 символов.
+type CreateCoinRequestRequest struct {
+	ToUser string `json:"to_user"`
+	Amount int32  `json:"amount"`
+	Note   string `json:"note,omitempty"`
+}
+
+type CoinRequestHandler struct {
+	CoinRequestService service.CoinRequestService
+}
+
+func NewCoinRequestHandler(coinRequestService service.CoinRequestService) *CoinRequestHandler {
+	return &CoinRequestHandler{CoinRequestService: coinRequestService}
+}
+
+// GET, POST /api/coin-requests
+// GET возвращает входящие запросы (direction=incoming, по умолчанию) или
+// созданные пользователем (direction=outgoing), с необязательным фильтром status.
+func (h *CoinRequestHandler) HandleCoinRequests(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		status := r.URL.Query().Get("status")
+		var (
+			requests []service.CoinRequest
+			err      error
+		)
+		switch r.URL.Query().Get("direction") {
+		case "", "incoming":
+			requests, err = h.CoinRequestService.ListIncoming(r.Context(), status)
+		case "outgoing":
+			requests, err = h.CoinRequestService.ListOutgoing(r.Context(), status)
+		default:
+			utils.JSONErrorResponse(w, http.StatusBadRequest, "direction must be incoming or outgoing")
+			return
+		}
+		if err != nil {
+			writeServiceError(w, err)
+			return
+		}
+		utils.JSONResponse(w, http.StatusOK, requests)
+
+	case http.MethodPost:
+		var req CreateCoinRequestRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			utils.JSONErrorResponse(w, http.StatusBadRequest, "invalid request body")
+			return
+		}
+		if req.ToUser == "" {
+			utils.JSONErrorResponse(w, http.StatusBadRequest, "to_user is required")
+			return
+		}
+
+		request, err := h.CoinRequestService.CreateRequest(r.Context(), req.ToUser, req.Amount, req.Note)
+		if err != nil {
+			writeServiceError(w, err)
+			return
+		}
+		utils.JSONResponse(w, http.StatusCreated, request)
+
+	default:
+		utils.JSONErrorResponse(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+// POST /api/coin-requests/{id}/accept, POST /api/coin-requests/{id}/decline
+func (h *CoinRequestHandler) HandleCoinRequestAction(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.JSONErrorResponse(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	idPart, action, ok := strings.Cut(strings.TrimPrefix(r.URL.Path, "/api/coin-requests/"), "/")
+	if !ok {
+		utils.JSONErrorResponse(w, http.StatusNotFound, "not found")
+		return
+	}
+	id, err := strconv.ParseInt(idPart, 10, 32)
+	if err != nil || id <= 0 {
+		utils.JSONErrorResponse(w, http.StatusBadRequest, "invalid coin request id")
+		return
+	}
+
+	var request service.CoinRequest
+	switch action {
+	case "accept":
+		request, err = h.CoinRequestService.AcceptRequest(r.Context(), int32(id))
+	case "decline":
+		request, err = h.CoinRequestService.DeclineRequest(r.Context(), int32(id))
+	default:
+		utils.JSONErrorResponse(w, http.StatusNotFound, "not found")
+		return
+	}
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	utils.JSONResponse(w, http.StatusOK, request)
+}
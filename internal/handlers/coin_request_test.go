@@ -0,0 +1,112 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/par1ram/merch-store/internal/handlers"
+	"github.com/par1ram/merch-store/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockCoinRequestService struct {
+	mock.Mock
+}
+
+func (m *MockCoinRequestService) CreateRequest(ctx context.Context, payer string, amount int32, note string) (service.CoinRequest, error) {
+	args := m.Called(ctx, payer, amount, note)
+	return args.Get(0).(service.CoinRequest), args.Error(1)
+}
+
+func (m *MockCoinRequestService) ListIncoming(ctx context.Context, status string) ([]service.CoinRequest, error) {
+	args := m.Called(ctx, status)
+	return args.Get(0).([]service.CoinRequest), args.Error(1)
+}
+
+func (m *MockCoinRequestService) ListOutgoing(ctx context.Context, status string) ([]service.CoinRequest, error) {
+	args := m.Called(ctx, status)
+	return args.Get(0).([]service.CoinRequest), args.Error(1)
+}
+
+func (m *MockCoinRequestService) AcceptRequest(ctx context.Context, requestID int32) (service.CoinRequest, error) {
+	args := m.Called(ctx, requestID)
+	return args.Get(0).(service.CoinRequest), args.Error(1)
+}
+
+func (m *MockCoinRequestService) DeclineRequest(ctx context.Context, requestID int32) (service.CoinRequest, error) {
+	args := m.Called(ctx, requestID)
+	return args.Get(0).(service.CoinRequest), args.Error(1)
+}
+
+func (m *MockCoinRequestService) ExpireRequests(ctx context.Context) (int64, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func TestCoinRequestHandler_CreateAndList(t *testing.T) {
+	created := service.CoinRequest{ID: 5, Requester: "alice", Payer: "bob", Amount: 40, Status: service.CoinRequestStatusPending}
+
+	mockService := new(MockCoinRequestService)
+	mockService.On("CreateRequest", mock.Anything, "bob", int32(40), "lunch").Return(created, nil).Once()
+	mockService.On("ListOutgoing", mock.Anything, "pending").Return([]service.CoinRequest{created}, nil).Once()
+
+	handler := handlers.NewCoinRequestHandler(mockService)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/coin-requests", strings.NewReader(`{"to_user":"bob","amount":40,"note":"lunch"}`))
+	rr := httptest.NewRecorder()
+	handler.HandleCoinRequests(rr, req)
+	assert.Equal(t, http.StatusCreated, rr.Code)
+	var resp service.CoinRequest
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+	assert.Equal(t, int32(5), resp.ID)
+
+	req = httptest.NewRequest(http.MethodGet, "/api/coin-requests?direction=outgoing&status=pending", nil)
+	rr = httptest.NewRecorder()
+	handler.HandleCoinRequests(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	req = httptest.NewRequest(http.MethodGet, "/api/coin-requests?direction=sideways", nil)
+	rr = httptest.NewRecorder()
+	handler.HandleCoinRequests(rr, req)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestCoinRequestHandler_Action(t *testing.T) {
+	mockService := new(MockCoinRequestService)
+	mockService.On("AcceptRequest", mock.Anything, int32(5)).
+		Return(service.CoinRequest{ID: 5, Status: service.CoinRequestStatusAccepted}, nil).Once()
+	mockService.On("DeclineRequest", mock.Anything, int32(6)).
+		Return(service.CoinRequest{}, fmt.Errorf("%w: %w", service.ErrBusinessValidation, service.ErrCoinRequestExpired)).Once()
+
+	handler := handlers.NewCoinRequestHandler(mockService)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/coin-requests/5/accept", nil)
+	rr := httptest.NewRecorder()
+	handler.HandleCoinRequestAction(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	req = httptest.NewRequest(http.MethodPost, "/api/coin-requests/6/decline", nil)
+	rr = httptest.NewRecorder()
+	handler.HandleCoinRequestAction(rr, req)
+	assert.Equal(t, http.StatusConflict, rr.Code)
+
+	req = httptest.NewRequest(http.MethodPost, "/api/coin-requests/abc/accept", nil)
+	rr = httptest.NewRecorder()
+	handler.HandleCoinRequestAction(rr, req)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	req = httptest.NewRequest(http.MethodPost, "/api/coin-requests/5/approve", nil)
+	rr = httptest.NewRecorder()
+	handler.HandleCoinRequestAction(rr, req)
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+
+	mockService.AssertExpectations(t)
+}
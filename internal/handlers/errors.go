@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/par1ram/merch-store/internal/service"
+	"github.com/par1ram/merch-store/internal/utils"
+)
+
+// errorKindStatus сопоставляет категориям бизнес-ошибок HTTP-статусы.
+var errorKindStatus = map[service.ErrorKind]int{
+	service.KindInvalid:           http.StatusBadRequest,
+	service.KindUnauthenticated:   http.StatusUnauthorized,
+	service.KindInsufficientFunds: http.StatusPaymentRequired,
+	service.KindNotFound:          http.StatusNotFound,
+	service.KindConflict:          http.StatusConflict,
+	service.KindForbidden:         http.StatusForbidden,
+}
+
+// writeServiceError отправляет ответ с HTTP-статусом и кодом, соответствующими
+// ошибке сервиса. Ошибки без типа считаются внутренними: их текст клиенту не отдаётся.
+func writeServiceError(w http.ResponseWriter, err error) {
+	var serviceErr *service.Error
+	switch {
+	case errors.As(err, &serviceErr):
+		status, ok := errorKindStatus[serviceErr.Kind]
+		if !ok {
+			status = http.StatusBadRequest
+		}
+		utils.JSONErrorCodeResponse(w, status, string(serviceErr.Code), err.Error())
+	case errors.Is(err, service.ErrBusinessValidation):
+		utils.JSONErrorCodeResponse(w, http.StatusBadRequest, string(service.CodeValidation), err.Error())
+	default:
+		utils.JSONErrorCodeResponse(w, http.StatusInternalServerError, "INTERNAL_ERROR", "internal error")
+	}
+}
@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/par1ram/merch-store/internal/service"
+	"github.com/par1ram/merch-store/internal/utils"
+)
+
+// GrantCoinsRequest — тело запроса на начисление или списание монет.
+// Положительная сумма начисляется, отрицательная списывается. Сотрудник
+// задаётся полем username, список сотрудников — полем usernames.
+type GrantCoinsRequest struct {
+	Username  string   `json:"username"`
+	Usernames []string `json:"usernames"`
+	Amount    int32    `json:"amount"`
+	Reason    string   `json:"reason"`
+}
+
+// GrantCoinsResponse — итог операции по каждому сотруднику.
+type GrantCoinsResponse struct {
+	Grants []service.CoinGrant `json:"grants"`
+}
+
+type GrantHandler struct {
+	GrantService service.GrantService
+}
+
+func NewGrantHandler(grantService service.GrantService) *GrantHandler {
+	return &GrantHandler{GrantService: grantService}
+}
+
+// POST /api/admin/coins
+func (h *GrantHandler) HandleAdminCoins(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.JSONErrorResponse(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	var req GrantCoinsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.JSONErrorResponse(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	usernames := req.Usernames
+	if req.Username != "" {
+		usernames = append([]string{req.Username}, usernames...)
+	}
+
+	grants, err := h.GrantService.AdjustCoins(r.Context(), usernames, req.Amount, req.Reason)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	utils.JSONResponse(w, http.StatusOK, GrantCoinsResponse{Grants: grants})
+}
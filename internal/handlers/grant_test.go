@@ -0,0 +1,91 @@
+package handlers_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/par1ram/merch-store/internal/handlers"
+	"github.com/par1ram/merch-store/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockGrantService struct {
+	mock.Mock
+}
+
+func (m *MockGrantService) AdjustCoins(ctx context.Context, usernames []string, amount int32, reason string) ([]service.CoinGrant, error) {
+	args := m.Called(ctx, usernames, amount, reason)
+	if res, ok := args.Get(0).([]service.CoinGrant); ok {
+		return res, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func TestGrantHandler_HandleAdminCoins(t *testing.T) {
+	expected := []service.CoinGrant{
+		{TransactionID: 40, Username: "alice", Amount: 100, Balance: 1100},
+		{TransactionID: 41, Username: "bob", Amount: 100, Balance: 300},
+	}
+
+	mockService := new(MockGrantService)
+	mockService.On("AdjustCoins", mock.Anything, []string{"alice", "bob"}, int32(100), "hackathon winner").
+		Return(expected, nil).Once()
+
+	handler := handlers.NewGrantHandler(mockService)
+	body := `{"username":"alice","usernames":["bob"],"amount":100,"reason":"hackathon winner"}`
+	req := httptest.NewRequest(http.MethodPost, "/api/admin/coins", bytes.NewBufferString(body))
+	rr := httptest.NewRecorder()
+	handler.HandleAdminCoins(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var resp handlers.GrantCoinsResponse
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+	assert.Equal(t, expected, resp.Grants)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestGrantHandler_HandleAdminCoins_Errors(t *testing.T) {
+	cases := []struct {
+		err    error
+		status int
+		code   string
+	}{
+		{fmt.Errorf("%w: %w", service.ErrBusinessValidation, service.ErrInvalidGrantReason), http.StatusBadRequest, "INVALID_GRANT_REASON"},
+		{fmt.Errorf("%w: ghost", service.ErrUserNotFound), http.StatusNotFound, "USER_NOT_FOUND"},
+		{fmt.Errorf("%w: alice", service.ErrInsufficientFunds), http.StatusPaymentRequired, "INSUFFICIENT_FUNDS"},
+	}
+
+	for _, tc := range cases {
+		mockService := new(MockGrantService)
+		mockService.On("AdjustCoins", mock.Anything, []string{"alice"}, int32(-50), "").Return(nil, tc.err).Once()
+
+		handler := handlers.NewGrantHandler(mockService)
+		req := httptest.NewRequest(http.MethodPost, "/api/admin/coins", bytes.NewBufferString(`{"usernames":["alice"],"amount":-50}`))
+		rr := httptest.NewRecorder()
+		handler.HandleAdminCoins(rr, req)
+
+		assert.Equal(t, tc.status, rr.Code, tc.err.Error())
+		var resp map[string]string
+		assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+		assert.Equal(t, tc.code, resp["code"])
+		mockService.AssertExpectations(t)
+	}
+}
+
+func TestGrantHandler_HandleAdminCoins_MethodNotAllowed(t *testing.T) {
+	mockService := new(MockGrantService)
+	handler := handlers.NewGrantHandler(mockService)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/admin/coins", nil)
+	rr := httptest.NewRecorder()
+	handler.HandleAdminCoins(rr, req)
+
+	assert.Equal(t, http.StatusMethodNotAllowed, rr.Code)
+}
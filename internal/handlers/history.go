@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/par1ram/merch-store/internal/service"
+	"github.com/par1ram/merch-store/internal/utils"
+)
+
+type HistoryHandler struct {
+	HistoryService service.HistoryService
+}
+
+func NewHistoryHandler(historyService service.HistoryService) *HistoryHandler {
+	return &HistoryHandler{HistoryService: historyService}
+}
+
+// GET /api/history
+// Возвращает страницу ленты операций пользователя всех типов — переводов,
+// покупок с названиями товаров, возвратов, начислений. Фильтры: direction
+// (incoming, outgoing), counterparty, type (через запятую или повторением),
+// from и to (YYYY-MM-DD включительно или RFC 3339), min_amount, max_amount.
+// Страница задаётся limit и cursor — значением next_cursor предыдущего ответа.
+func (h *HistoryHandler) HandleHistory(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.JSONErrorResponse(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	query := r.URL.Query()
+	filter := service.HistoryFilter{
+		Direction:    query.Get("direction"),
+		Counterparty: query.Get("counterparty"),
+		Cursor:       query.Get("cursor"),
+	}
+	for _, value := range query["type"] {
+		for _, t := range strings.Split(value, ",") {
+			if t = strings.TrimSpace(t); t != "" {
+				filter.Types = append(filter.Types, t)
+			}
+		}
+	}
+
+	var err error
+	if filter.From, err = parseHistoryTime(query.Get("from"), false); err != nil {
+		utils.JSONErrorResponse(w, http.StatusBadRequest, "from must be a date (YYYY-MM-DD) or RFC 3339 time")
+		return
+	}
+	if filter.To, err = parseHistoryTime(query.Get("to"), true); err != nil {
+		utils.JSONErrorResponse(w, http.StatusBadRequest, "to must be a date (YYYY-MM-DD) or RFC 3339 time")
+		return
+	}
+	if filter.MinAmount, err = parseInt32Param(query.Get("min_amount")); err != nil {
+		utils.JSONErrorResponse(w, http.StatusBadRequest, "min_amount must be a non-negative integer")
+		return
+	}
+	if filter.MaxAmount, err = parseInt32Param(query.Get("max_amount")); err != nil {
+		utils.JSONErrorResponse(w, http.StatusBadRequest, "max_amount must be a non-negative integer")
+		return
+	}
+	if filter.Limit, err = parseInt32Param(query.Get("limit")); err != nil {
+		utils.JSONErrorResponse(w, http.StatusBadRequest, "limit must be a non-negative integer")
+		return
+	}
+
+	page, err := h.HistoryService.ListHistory(r.Context(), filter)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	utils.JSONResponse(w, http.StatusOK, page)
+}
+
+// parseHistoryTime разбирает границу периода. Дата без времени означает начало
+// дня в UTC; для верхней границы — начало следующего дня, чтобы день входил в период.
+func parseHistoryTime(value string, upper bool) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if day, err := time.Parse(time.DateOnly, value); err == nil {
+		if upper {
+			day = day.AddDate(0, 0, 1)
+		}
+		return day, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
+func parseInt32Param(value string) (int32, error) {
+	if value == "" {
+		return 0, nil
+	}
+	n, err := strconv.ParseInt(value, 10, 32)
+	if err != nil || n < 0 {
+		return 0, errors.New("invalid integer")
+	}
+	return int32(n), nil
+}
@@ -0,0 +1,91 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/par1ram/merch-store/internal/handlers"
+	"github.com/par1ram/merch-store/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockHistoryService struct {
+	mock.Mock
+}
+
+func (m *MockHistoryService) ListHistory(ctx context.Context, filter service.HistoryFilter) (service.HistoryPage, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).(service.HistoryPage), args.Error(1)
+}
+
+func TestHistoryHandler_ParsesFilters(t *testing.T) {
+	expected := service.HistoryFilter{
+		Direction:    "outgoing",
+		Counterparty: "bob",
+		Types:        []string{"transfer", "hold", "purchase"},
+		From:         time.Date(2026, time.September, 1, 0, 0, 0, 0, time.UTC),
+		To:           time.Date(2026, time.October, 1, 0, 0, 0, 0, time.UTC),
+		MinAmount:    10,
+		MaxAmount:    500,
+		Cursor:       "abc",
+		Limit:        20,
+	}
+	page := service.HistoryPage{
+		Entries:    []service.HistoryEntry{{ID: 42, Type: "transfer", Direction: "outgoing", Amount: 50, Counterparty: "bob"}},
+		NextCursor: "next",
+	}
+
+	mockService := new(MockHistoryService)
+	mockService.On("ListHistory", mock.Anything, expected).Return(page, nil).Once()
+
+	handler := handlers.NewHistoryHandler(mockService)
+	req := httptest.NewRequest(http.MethodGet, "/api/history?direction=outgoing&counterparty=bob"+
+		"&type=transfer,hold&type=purchase&from=2026-09-01&to=2026-09-30"+
+		"&min_amount=10&max_amount=500&cursor=abc&limit=20", nil)
+	rr := httptest.NewRecorder()
+	handler.HandleHistory(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var resp service.HistoryPage
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+	assert.Equal(t, "next", resp.NextCursor)
+	assert.Len(t, resp.Entries, 1)
+	mockService.AssertExpectations(t)
+}
+
+func TestHistoryHandler_BadParams(t *testing.T) {
+	handler := handlers.NewHistoryHandler(new(MockHistoryService))
+
+	for _, query := range []string{"from=yesterday", "to=2026-13-01", "min_amount=-1", "max_amount=x", "limit=ten"} {
+		req := httptest.NewRequest(http.MethodGet, "/api/history?"+query, nil)
+		rr := httptest.NewRecorder()
+		handler.HandleHistory(rr, req)
+		assert.Equal(t, http.StatusBadRequest, rr.Code, query)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/history", nil)
+	rr := httptest.NewRecorder()
+	handler.HandleHistory(rr, req)
+	assert.Equal(t, http.StatusMethodNotAllowed, rr.Code)
+}
+
+func TestHistoryHandler_InvalidCursor(t *testing.T) {
+	mockService := new(MockHistoryService)
+	mockService.On("ListHistory", mock.Anything, service.HistoryFilter{Cursor: "broken"}).
+		Return(service.HistoryPage{}, fmt.Errorf("%w: %w", service.ErrBusinessValidation, service.ErrInvalidHistoryCursor)).Once()
+
+	handler := handlers.NewHistoryHandler(mockService)
+	req := httptest.NewRequest(http.MethodGet, "/api/history?cursor=broken", nil)
+	rr := httptest.NewRecorder()
+	handler.HandleHistory(rr, req)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Contains(t, rr.Body.String(), "INVALID_HISTORY_CURSOR")
+	mockService.AssertExpectations(t)
+}
@@ -0,0 +1,32 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/par1ram/merch-store/internal/service"
+	"github.com/par1ram/merch-store/internal/utils"
+)
+
+type LedgerHandler struct {
+	LedgerService service.LedgerService
+}
+
+func NewLedgerHandler(ledgerService service.LedgerService) *LedgerHandler {
+	return &LedgerHandler{LedgerService: ledgerService}
+}
+
+// GET /api/admin/ledger
+// Сверяет балансы сотрудников с книгой двойной записи и показывает остатки системных счетов.
+func (h *LedgerHandler) HandleVerify(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.JSONErrorResponse(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	report, err := h.LedgerService.Verify(r.Context())
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	utils.JSONResponse(w, http.StatusOK, report)
+}
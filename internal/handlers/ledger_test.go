@@ -0,0 +1,64 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/par1ram/merch-store/internal/handlers"
+	"github.com/par1ram/merch-store/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockLedgerService struct {
+	mock.Mock
+}
+
+func (m *MockLedgerService) Verify(ctx context.Context) (service.LedgerReport, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(service.LedgerReport), args.Error(1)
+}
+
+func TestLedgerHandler_HandleVerify(t *testing.T) {
+	expected := service.LedgerReport{
+		Consistent:     true,
+		SystemAccounts: []service.LedgerAccountBalance{{Code: "issuance", Balance: -1000}},
+		Mismatches:     []service.LedgerMismatch{},
+	}
+
+	mockService := new(MockLedgerService)
+	mockService.On("Verify", mock.Anything).Return(expected, nil).Once()
+
+	handler := handlers.NewLedgerHandler(mockService)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/admin/ledger", nil)
+	rr := httptest.NewRecorder()
+	handler.HandleVerify(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var resp service.LedgerReport
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+	assert.Equal(t, expected, resp)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestLedgerHandler_HandleVerify_Errors(t *testing.T) {
+	mockService := new(MockLedgerService)
+	mockService.On("Verify", mock.Anything).Return(service.LedgerReport{}, errors.New("db error")).Once()
+
+	handler := handlers.NewLedgerHandler(mockService)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/admin/ledger", nil)
+	rr := httptest.NewRecorder()
+	handler.HandleVerify(rr, req)
+	assert.Equal(t, http.StatusInternalServerError, rr.Code)
+
+	req = httptest.NewRequest(http.MethodPost, "/api/admin/ledger", nil)
+	rr = httptest.NewRecorder()
+	handler.HandleVerify(rr, req)
+	assert.Equal(t, http.StatusMethodNotAllowed, rr.Code)
+}
@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/par1ram/merch-store/internal/service"
+	"github.com/par1ram/merch-store/internal/utils"
+)
+
+// CreateMerchRequest — тело запроса на создание товара.
+// Stock не обязателен: без него остаток товара не ограничен.
+type CreateMerchRequest struct {
+	Name  string `json:"name"`
+	Price int32  `json:"price"`
+	Stock *int32 `json:"stock"`
+}
+
+// UpdateMerchRequest — тело запроса на изменение товара. Отсутствующие поля не меняются.
+// Stock хранится как RawMessage, чтобы отличить отсутствие поля от явного null
+// ("stock": null снимает ограничение остатка).
+type UpdateMerchRequest struct {
+	Name  *string         `json:"name"`
+	Price *int32          `json:"price"`
+	Stock json.RawMessage `json:"stock"`
+}
+
+type MerchHandler struct {
+	MerchService service.MerchService
+}
+
+func NewMerchHandler(merchService service.MerchService) *MerchHandler {
+	return &MerchHandler{MerchService: merchService}
+}
+
+// GET /api/merch?sort=price&order=desc&min_price=10&max_price=300
+func (h *MerchHandler) HandleListMerch(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	filter := service.MerchFilter{
+		SortBy: query.Get("sort"),
+	}
+
+	switch query.Get("order") {
+	case "", "asc":
+	case "desc":
+		filter.Desc = true
+	default:
+		utils.JSONErrorResponse(w, http.StatusBadRequest, "order must be asc or desc")
+		return
+	}
+
+	var err error
+	if filter.MinPrice, err = parsePriceParam(query.Get("min_price")); err != nil {
+		utils.JSONErrorResponse(w, http.StatusBadRequest, "min_price must be a non-negative integer")
+		return
+	}
+	if filter.MaxPrice, err = parsePriceParam(query.Get("max_price")); err != nil {
+		utils.JSONErrorResponse(w, http.StatusBadRequest, "max_price must be a non-negative integer")
+		return
+	}
+
+	resp, err := h.MerchService.ListMerch(r.Context(), filter)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	utils.JSONResponse(w, http.StatusOK, resp)
+}
+
+// GET, POST /api/admin/merch
+func (h *MerchHandler) HandleAdminMerch(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		items, err := h.MerchService.ListAllMerch(r.Context())
+		if err != nil {
+			writeServiceError(w, err)
+			return
+		}
+		utils.JSONResponse(w, http.StatusOK, items)
+
+	case http.MethodPost:
+		var req CreateMerchRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			utils.JSONErrorResponse(w, http.StatusBadRequest, "invalid request body")
+			return
+		}
+
+		item, err := h.MerchService.CreateMerch(r.Context(), req.Name, req.Price, req.Stock)
+		if err != nil {
+			writeServiceError(w, err)
+			return
+		}
+		utils.JSONResponse(w, http.StatusCreated, item)
+
+	default:
+		utils.JSONErrorResponse(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+// PATCH, DELETE /api/admin/merch/{id}
+// DELETE не удаляет товар, а снимает его с продажи.
+func (h *MerchHandler) HandleAdminMerchItem(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(strings.TrimPrefix(r.URL.Path, "/api/admin/merch/"), 10, 32)
+	if err != nil || id <= 0 {
+		utils.JSONErrorResponse(w, http.StatusBadRequest, "invalid merch id")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPatch:
+		var req UpdateMerchRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			utils.JSONErrorResponse(w, http.StatusBadRequest, "invalid request body")
+			return
+		}
+		if req.Name == nil && req.Price == nil && req.Stock == nil {
+			utils.JSONErrorResponse(w, http.StatusBadRequest, "name, price or stock is required")
+			return
+		}
+
+		update := service.MerchUpdate{
+			Name:  req.Name,
+			Price: req.Price,
+		}
+		if req.Stock != nil {
+			update.SetStock = true
+			if err := json.Unmarshal(req.Stock, &update.Stock); err != nil {
+				utils.JSONErrorResponse(w, http.StatusBadRequest, "stock must be an integer or null")
+				return
+			}
+		}
+
+		item, err := h.MerchService.UpdateMerch(r.Context(), int32(id), update)
+		if err != nil {
+			writeServiceError(w, err)
+			return
+		}
+		utils.JSONResponse(w, http.StatusOK, item)
+
+	case http.MethodDelete:
+		item, err := h.MerchService.RetireMerch(r.Context(), int32(id))
+		if err != nil {
+			writeServiceError(w, err)
+			return
+		}
+		utils.JSONResponse(w, http.StatusOK, item)
+
+	default:
+		utils.JSONErrorResponse(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+// parsePriceParam разбирает необязательный ценовой параметр запроса.
+func parsePriceParam(value string) (int32, error) {
+	if value == "" {
+		return 0, nil
+	}
+	price, err := strconv.ParseInt(value, 10, 32)
+	if err != nil || price < 0 {
+		return 0, errors.New("invalid price")
+	}
+	return int32(price), nil
+}
@@ -0,0 +1,245 @@
+package handlers_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/par1ram/merch-store/internal/handlers"
+	"github.com/par1ram/merch-store/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockMerchService struct {
+	mock.Mock
+}
+
+func (m *MockMerchService) ListMerch(ctx context.Context, filter service.MerchFilter) (service.MerchListResponse, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).(service.MerchListResponse), args.Error(1)
+}
+
+func (m *MockMerchService) ListAllMerch(ctx context.Context) ([]service.AdminMerchItem, error) {
+	args := m.Called(ctx)
+	if res, ok := args.Get(0).([]service.AdminMerchItem); ok {
+		return res, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockMerchService) CreateMerch(ctx context.Context, name string, price int32, stock *int32) (service.AdminMerchItem, error) {
+	args := m.Called(ctx, name, price, stock)
+	return args.Get(0).(service.AdminMerchItem), args.Error(1)
+}
+
+func (m *MockMerchService) UpdateMerch(ctx context.Context, id int32, update service.MerchUpdate) (service.AdminMerchItem, error) {
+	args := m.Called(ctx, id, update)
+	return args.Get(0).(service.AdminMerchItem), args.Error(1)
+}
+
+func (m *MockMerchService) RetireMerch(ctx context.Context, id int32) (service.AdminMerchItem, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(service.AdminMerchItem), args.Error(1)
+}
+
+func TestMerchHandler_HandleListMerch_Success(t *testing.T) {
+	expected := service.MerchListResponse{
+		Items: []service.MerchItem{
+			{ID: 6, Name: "hoody", Price: 300, Affordable: true},
+			{ID: 1, Name: "t-shirt", Price: 80, Affordable: true},
+		},
+	}
+
+	mockService := new(MockMerchService)
+	mockService.
+		On("ListMerch", mock.Anything, service.MerchFilter{
+			SortBy:   "price",
+			Desc:     true,
+			MinPrice: 50,
+			MaxPrice: 500,
+		}).
+		Return(expected, nil).
+		Once()
+
+	handler := handlers.NewMerchHandler(mockService)
+
+	req := httptest.NewRequest("GET", "/api/merch?sort=price&order=desc&min_price=50&max_price=500", nil)
+	rr := httptest.NewRecorder()
+	handler.HandleListMerch(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	var resp service.MerchListResponse
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+	assert.Equal(t, expected, resp)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestMerchHandler_HandleListMerch_InvalidParams(t *testing.T) {
+	cases := []struct {
+		query   string
+		message string
+	}{
+		{"order=sideways", "order must be asc or desc"},
+		{"min_price=abc", "min_price must be a non-negative integer"},
+		{"max_price=-5", "max_price must be a non-negative integer"},
+	}
+
+	for _, tc := range cases {
+		mockService := new(MockMerchService)
+		handler := handlers.NewMerchHandler(mockService)
+
+		req := httptest.NewRequest("GET", "/api/merch?"+tc.query, nil)
+		rr := httptest.NewRecorder()
+		handler.HandleListMerch(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code, tc.query)
+		var resp map[string]interface{}
+		assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+		assert.Equal(t, tc.message, resp["error"])
+
+		mockService.AssertNotCalled(t, "ListMerch", mock.Anything, mock.Anything)
+	}
+}
+
+func TestMerchHandler_HandleListMerch_ServiceErrors(t *testing.T) {
+	cases := []struct {
+		err    error
+		status int
+	}{
+		{fmt.Errorf("%w: %v", service.ErrBusinessValidation, service.ErrInvalidMerchFilter), http.StatusBadRequest},
+		{errors.New("db error"), http.StatusInternalServerError},
+	}
+
+	for _, tc := range cases {
+		mockService := new(MockMerchService)
+		mockService.On("ListMerch", mock.Anything, mock.Anything).
+			Return(service.MerchListResponse{}, tc.err).
+			Once()
+
+		handler := handlers.NewMerchHandler(mockService)
+		req := httptest.NewRequest("GET", "/api/merch?sort=color", nil)
+		rr := httptest.NewRecorder()
+		handler.HandleListMerch(rr, req)
+
+		assert.Equal(t, tc.status, rr.Code)
+		mockService.AssertExpectations(t)
+	}
+}
+
+func TestMerchHandler_HandleAdminMerch_Create(t *testing.T) {
+	expected := service.AdminMerchItem{ID: 11, Name: "sticker", Price: 5, Active: true}
+
+	mockService := new(MockMerchService)
+	mockService.On("CreateMerch", mock.Anything, "sticker", int32(5), (*int32)(nil)).Return(expected, nil).Once()
+
+	body, err := json.Marshal(handlers.CreateMerchRequest{Name: "sticker", Price: 5})
+	assert.NoError(t, err)
+
+	handler := handlers.NewMerchHandler(mockService)
+	req := httptest.NewRequest(http.MethodPost, "/api/admin/merch", bytes.NewBuffer(body))
+	rr := httptest.NewRecorder()
+	handler.HandleAdminMerch(rr, req)
+
+	assert.Equal(t, http.StatusCreated, rr.Code)
+	var resp service.AdminMerchItem
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+	assert.Equal(t, expected, resp)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestMerchHandler_HandleAdminMerch_CreateDuplicate(t *testing.T) {
+	mockService := new(MockMerchService)
+	mockService.On("CreateMerch", mock.Anything, "cup", int32(20), (*int32)(nil)).
+		Return(service.AdminMerchItem{}, fmt.Errorf("%w: %w", service.ErrBusinessValidation, service.ErrMerchExists)).
+		Once()
+
+	handler := handlers.NewMerchHandler(mockService)
+	req := httptest.NewRequest(http.MethodPost, "/api/admin/merch", bytes.NewBufferString(`{"name":"cup","price":20}`))
+	rr := httptest.NewRecorder()
+	handler.HandleAdminMerch(rr, req)
+
+	assert.Equal(t, http.StatusConflict, rr.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestMerchHandler_HandleAdminMerchItem_Update(t *testing.T) {
+	price := int32(90)
+	expected := service.AdminMerchItem{ID: 1, Name: "t-shirt", Price: 90, Active: true}
+
+	mockService := new(MockMerchService)
+	mockService.On("UpdateMerch", mock.Anything, int32(1), service.MerchUpdate{Price: &price}).
+		Return(expected, nil).
+		Once()
+
+	handler := handlers.NewMerchHandler(mockService)
+	req := httptest.NewRequest(http.MethodPatch, "/api/admin/merch/1", bytes.NewBufferString(`{"price":90}`))
+	rr := httptest.NewRecorder()
+	handler.HandleAdminMerchItem(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestMerchHandler_HandleAdminMerchItem_UpdateStock(t *testing.T) {
+	stock := int32(40)
+
+	mockService := new(MockMerchService)
+	mockService.On("UpdateMerch", mock.Anything, int32(6), service.MerchUpdate{Stock: &stock, SetStock: true}).
+		Return(service.AdminMerchItem{ID: 6, Name: "pink-hoody", Price: 500, Stock: &stock, Active: true}, nil).
+		Once()
+	// Явный null снимает ограничение остатка.
+	mockService.On("UpdateMerch", mock.Anything, int32(6), service.MerchUpdate{SetStock: true}).
+		Return(service.AdminMerchItem{ID: 6, Name: "pink-hoody", Price: 500, Active: true}, nil).
+		Once()
+
+	handler := handlers.NewMerchHandler(mockService)
+	for _, body := range []string{`{"stock":40}`, `{"stock":null}`} {
+		req := httptest.NewRequest(http.MethodPatch, "/api/admin/merch/6", bytes.NewBufferString(body))
+		rr := httptest.NewRecorder()
+		handler.HandleAdminMerchItem(rr, req)
+		assert.Equal(t, http.StatusOK, rr.Code, body)
+	}
+
+	req := httptest.NewRequest(http.MethodPatch, "/api/admin/merch/6", bytes.NewBufferString(`{"stock":"many"}`))
+	rr := httptest.NewRecorder()
+	handler.HandleAdminMerchItem(rr, req)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestMerchHandler_HandleAdminMerchItem_RetireNotFound(t *testing.T) {
+	mockService := new(MockMerchService)
+	mockService.On("RetireMerch", mock.Anything, int32(99)).
+		Return(service.AdminMerchItem{}, fmt.Errorf("%w: %w", service.ErrBusinessValidation, service.ErrMerchNotFound)).
+		Once()
+
+	handler := handlers.NewMerchHandler(mockService)
+	req := httptest.NewRequest(http.MethodDelete, "/api/admin/merch/99", nil)
+	rr := httptest.NewRecorder()
+	handler.HandleAdminMerchItem(rr, req)
+
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestMerchHandler_HandleAdminMerchItem_InvalidID(t *testing.T) {
+	mockService := new(MockMerchService)
+	handler := handlers.NewMerchHandler(mockService)
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/admin/merch/abc", nil)
+	rr := httptest.NewRecorder()
+	handler.HandleAdminMerchItem(rr, req)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	mockService.AssertNotCalled(t, "RetireMerch", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/par1ram/merch-store/internal/service"
+	"github.com/par1ram/merch-store/internal/utils"
+)
+
+// UpdateOrderStatusRequest — тело запроса администратора на смену статуса заказа.
+type UpdateOrderStatusRequest struct {
+	Status string `json:"status"`
+}
+
+type OrderHandler struct {
+	OrderService service.OrderService
+}
+
+func NewOrderHandler(orderService service.OrderService) *OrderHandler {
+	return &OrderHandler{OrderService: orderService}
+}
+
+// GET /api/orders?status=placed
+func (h *OrderHandler) HandleOrders(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.JSONErrorResponse(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	orders, err := h.OrderService.ListMyOrders(r.Context(), r.URL.Query().Get("status"))
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	utils.JSONResponse(w, http.StatusOK, orders)
+}
+
+// GET /api/orders/{id}
+func (h *OrderHandler) HandleOrder(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.JSONErrorResponse(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	id, ok := parseOrderID(w, strings.TrimPrefix(r.URL.Path, "/api/orders/"))
+	if !ok {
+		return
+	}
+
+	order, err := h.OrderService.GetMyOrder(r.Context(), id)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	utils.JSONResponse(w, http.StatusOK, order)
+}
+
+// GET /api/admin/orders?status=placed
+func (h *OrderHandler) HandleAdminOrders(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.JSONErrorResponse(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	orders, err := h.OrderService.ListOrders(r.Context(), r.URL.Query().Get("status"))
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	utils.JSONResponse(w, http.StatusOK, orders)
+}
+
+// PATCH /api/admin/orders/{id}
+func (h *OrderHandler) HandleAdminOrder(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPatch {
+		utils.JSONErrorResponse(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	id, ok := parseOrderID(w, strings.TrimPrefix(r.URL.Path, "/api/admin/orders/"))
+	if !ok {
+		return
+	}
+
+	var req UpdateOrderStatusRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.JSONErrorResponse(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if req.Status == "" {
+		utils.JSONErrorResponse(w, http.StatusBadRequest, "status is required")
+		return
+	}
+
+	order, err := h.OrderService.UpdateStatus(r.Context(), id, req.Status)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	utils.JSONResponse(w, http.StatusOK, order)
+}
+
+// parseOrderID разбирает id заказа из пути и при ошибке сам отправляет ответ.
+func parseOrderID(w http.ResponseWriter, value string) (int32, bool) {
+	id, err := strconv.ParseInt(value, 10, 32)
+	if err != nil || id <= 0 {
+		utils.JSONErrorResponse(w, http.StatusBadRequest, "invalid order id")
+		return 0, false
+	}
+	return int32(id), true
+}
@@ -0,0 +1,121 @@
+package handlers_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/par1ram/merch-store/internal/handlers"
+	"github.com/par1ram/merch-store/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockOrderService struct {
+	mock.Mock
+}
+
+func (m *MockOrderService) ListMyOrders(ctx context.Context, status string) ([]service.Order, error) {
+	args := m.Called(ctx, status)
+	if res, ok := args.Get(0).([]service.Order); ok {
+		return res, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockOrderService) GetMyOrder(ctx context.Context, orderID int32) (service.Order, error) {
+	args := m.Called(ctx, orderID)
+	return args.Get(0).(service.Order), args.Error(1)
+}
+
+func (m *MockOrderService) ListOrders(ctx context.Context, status string) ([]service.Order, error) {
+	args := m.Called(ctx, status)
+	if res, ok := args.Get(0).([]service.Order); ok {
+		return res, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockOrderService) UpdateStatus(ctx context.Context, orderID int32, status string) (service.Order, error) {
+	args := m.Called(ctx, orderID, status)
+	return args.Get(0).(service.Order), args.Error(1)
+}
+
+func TestOrderHandler_HandleOrders_Success(t *testing.T) {
+	expected := []service.Order{{ID: 2, PurchaseID: 10, Item: "cup", Quantity: 1, Status: "packed"}}
+
+	mockService := new(MockOrderService)
+	mockService.On("ListMyOrders", mock.Anything, "packed").Return(expected, nil).Once()
+
+	handler := handlers.NewOrderHandler(mockService)
+	req := httptest.NewRequest(http.MethodGet, "/api/orders?status=packed", nil)
+	rr := httptest.NewRecorder()
+	handler.HandleOrders(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var resp []service.Order
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+	assert.Equal(t, expected, resp)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestOrderHandler_HandleOrder_NotFound(t *testing.T) {
+	mockService := new(MockOrderService)
+	mockService.On("GetMyOrder", mock.Anything, int32(5)).
+		Return(service.Order{}, fmt.Errorf("%w: %w", service.ErrBusinessValidation, service.ErrOrderNotFound)).Once()
+
+	handler := handlers.NewOrderHandler(mockService)
+	req := httptest.NewRequest(http.MethodGet, "/api/orders/5", nil)
+	rr := httptest.NewRecorder()
+	handler.HandleOrder(rr, req)
+
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestOrderHandler_HandleAdminOrder_UpdateStatus(t *testing.T) {
+	cases := []struct {
+		err    error
+		status int
+	}{
+		{nil, http.StatusOK},
+		{fmt.Errorf("%w: %w", service.ErrBusinessValidation, service.ErrInvalidOrderTransition), http.StatusConflict},
+		{fmt.Errorf("%w: %w", service.ErrBusinessValidation, service.ErrInvalidOrderStatus), http.StatusBadRequest},
+	}
+
+	for _, tc := range cases {
+		mockService := new(MockOrderService)
+		mockService.On("UpdateStatus", mock.Anything, int32(4), "packed").
+			Return(service.Order{ID: 4, Status: "packed"}, tc.err).Once()
+
+		handler := handlers.NewOrderHandler(mockService)
+		req := httptest.NewRequest(http.MethodPatch, "/api/admin/orders/4", bytes.NewBufferString(`{"status":"packed"}`))
+		rr := httptest.NewRecorder()
+		handler.HandleAdminOrder(rr, req)
+
+		assert.Equal(t, tc.status, rr.Code)
+		mockService.AssertExpectations(t)
+	}
+}
+
+func TestOrderHandler_HandleAdminOrder_Validation(t *testing.T) {
+	mockService := new(MockOrderService)
+	handler := handlers.NewOrderHandler(mockService)
+
+	for path, body := range map[string]string{
+		"/api/admin/orders/abc": `{"status":"packed"}`,
+		"/api/admin/orders/4":   `{}`,
+	} {
+		req := httptest.NewRequest(http.MethodPatch, path, bytes.NewBufferString(body))
+		rr := httptest.NewRecorder()
+		handler.HandleAdminOrder(rr, req)
+		assert.Equal(t, http.StatusBadRequest, rr.Code, path)
+	}
+
+	mockService.AssertNotCalled(t, "UpdateStatus", mock.Anything, mock.Anything, mock.Anything)
+}
@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/par1ram/merch-store/internal/service"
+	"github.com/par1ram/merch-store/internal/utils"
+)
+
+type PendingTransferHandler struct {
+	PendingTransferService service.PendingTransferService
+}
+
+func NewPendingTransferHandler(pendingTransferService service.PendingTransferService) *PendingTransferHandler {
+	return &PendingTransferHandler{PendingTransferService: pendingTransferService}
+}
+
+// GET /api/pending-transfers
+// Возвращает удержанные переводы, адресованные пользователю (direction=incoming,
+// по умолчанию) или отправленные им (direction=outgoing), с необязательным фильтром status.
+func (h *PendingTransferHandler) HandlePendingTransfers(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.JSONErrorResponse(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	status := r.URL.Query().Get("status")
+	var (
+		transfers []service.PendingTransfer
+		err       error
+	)
+	switch r.URL.Query().Get("direction") {
+	case "", "incoming":
+		transfers, err = h.PendingTransferService.ListIncoming(r.Context(), status)
+	case "outgoing":
+		transfers, err = h.PendingTransferService.ListOutgoing(r.Context(), status)
+	default:
+		utils.JSONErrorResponse(w, http.StatusBadRequest, "direction must be incoming or outgoing")
+		return
+	}
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	utils.JSONResponse(w, http.StatusOK, transfers)
+}
+
+// POST /api/pending-transfers/{id}/accept, POST /api/pending-transfers/{id}/decline
+func (h *PendingTransferHandler) HandlePendingTransferAction(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.JSONErrorResponse(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	idPart, action, ok := strings.Cut(strings.TrimPrefix(r.URL.Path, "/api/pending-transfers/"), "/")
+	if !ok {
+		utils.JSONErrorResponse(w, http.StatusNotFound, "not found")
+		return
+	}
+	id, err := strconv.ParseInt(idPart, 10, 32)
+	if err != nil || id <= 0 {
+		utils.JSONErrorResponse(w, http.StatusBadRequest, "invalid pending transfer id")
+		return
+	}
+
+	var transfer service.PendingTransfer
+	switch action {
+	case "accept":
+		transfer, err = h.PendingTransferService.AcceptTransfer(r.Context(), int32(id))
+	case "decline":
+		transfer, err = h.PendingTransferService.DeclineTransfer(r.Context(), int32(id))
+	default:
+		utils.JSONErrorResponse(w, http.StatusNotFound, "not found")
+		return
+	}
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	utils.JSONResponse(w, http.StatusOK, transfer)
+}
@@ -0,0 +1,106 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/par1ram/merch-store/internal/handlers"
+	"github.com/par1ram/merch-store/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockPendingTransferService struct {
+	mock.Mock
+}
+
+func (m *MockPendingTransferService) ListIncoming(ctx context.Context, status string) ([]service.PendingTransfer, error) {
+	args := m.Called(ctx, status)
+	return args.Get(0).([]service.PendingTransfer), args.Error(1)
+}
+
+func (m *MockPendingTransferService) ListOutgoing(ctx context.Context, status string) ([]service.PendingTransfer, error) {
+	args := m.Called(ctx, status)
+	return args.Get(0).([]service.PendingTransfer), args.Error(1)
+}
+
+func (m *MockPendingTransferService) AcceptTransfer(ctx context.Context, holdID int32) (service.PendingTransfer, error) {
+	args := m.Called(ctx, holdID)
+	return args.Get(0).(service.PendingTransfer), args.Error(1)
+}
+
+func (m *MockPendingTransferService) DeclineTransfer(ctx context.Context, holdID int32) (service.PendingTransfer, error) {
+	args := m.Called(ctx, holdID)
+	return args.Get(0).(service.PendingTransfer), args.Error(1)
+}
+
+func (m *MockPendingTransferService) RefundExpired(ctx context.Context) (int, error) {
+	args := m.Called(ctx)
+	return args.Int(0), args.Error(1)
+}
+
+func TestPendingTransferHandler_List(t *testing.T) {
+	held := service.PendingTransfer{ID: 7, Sender: "alice", Recipient: "bob", Amount: 40, Status: service.PendingTransferStatusPending}
+
+	mockService := new(MockPendingTransferService)
+	mockService.On("ListIncoming", mock.Anything, "").Return([]service.PendingTransfer{held}, nil).Once()
+	mockService.On("ListOutgoing", mock.Anything, "declined").Return([]service.PendingTransfer{}, nil).Once()
+
+	handler := handlers.NewPendingTransferHandler(mockService)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/pending-transfers", nil)
+	rr := httptest.NewRecorder()
+	handler.HandlePendingTransfers(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var resp []service.PendingTransfer
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+	assert.Len(t, resp, 1)
+
+	req = httptest.NewRequest(http.MethodGet, "/api/pending-transfers?direction=outgoing&status=declined", nil)
+	rr = httptest.NewRecorder()
+	handler.HandlePendingTransfers(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	req = httptest.NewRequest(http.MethodPost, "/api/pending-transfers", nil)
+	rr = httptest.NewRecorder()
+	handler.HandlePendingTransfers(rr, req)
+	assert.Equal(t, http.StatusMethodNotAllowed, rr.Code)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestPendingTransferHandler_Action(t *testing.T) {
+	mockService := new(MockPendingTransferService)
+	mockService.On("AcceptTransfer", mock.Anything, int32(7)).
+		Return(service.PendingTransfer{ID: 7, Status: service.PendingTransferStatusAccepted}, nil).Once()
+	mockService.On("DeclineTransfer", mock.Anything, int32(8)).
+		Return(service.PendingTransfer{}, fmt.Errorf("%w: %w", service.ErrBusinessValidation, service.ErrPendingTransferResolved)).Once()
+
+	handler := handlers.NewPendingTransferHandler(mockService)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/pending-transfers/7/accept", nil)
+	rr := httptest.NewRecorder()
+	handler.HandlePendingTransferAction(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	req = httptest.NewRequest(http.MethodPost, "/api/pending-transfers/8/decline", nil)
+	rr = httptest.NewRecorder()
+	handler.HandlePendingTransferAction(rr, req)
+	assert.Equal(t, http.StatusConflict, rr.Code)
+
+	req = httptest.NewRequest(http.MethodPost, "/api/pending-transfers/0/accept", nil)
+	rr = httptest.NewRecorder()
+	handler.HandlePendingTransferAction(rr, req)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	req = httptest.NewRequest(http.MethodPost, "/api/pending-transfers/7/refund", nil)
+	rr = httptest.NewRecorder()
+	handler.HandlePendingTransferAction(rr, req)
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+
+	mockService.AssertExpectations(t)
+}
@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/par1ram/merch-store/internal/service"
+	"github.com/par1ram/merch-store/internal/utils"
+)
+
+// CreateRefundRequest — тело заявки сотрудника на возврат покупки.
+// Quantity не обязателен: без него возвращаются все ещё не возвращённые единицы.
+type CreateRefundRequest struct {
+	PurchaseID int32  `json:"purchase_id"`
+	Quantity   int32  `json:"quantity"`
+	Reason     string `json:"reason"`
+}
+
+// AdminRefundRequest — тело запроса администратора на немедленный возврат.
+type AdminRefundRequest struct {
+	PurchaseID int32 `json:"purchase_id"`
+	Quantity   int32 `json:"quantity"`
+}
+
+type RefundHandler struct {
+	RefundService service.RefundService
+}
+
+func NewRefundHandler(refundService service.RefundService) *RefundHandler {
+	return &RefundHandler{RefundService: refundService}
+}
+
+// GET /api/purchases
+func (h *RefundHandler) HandlePurchases(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.JSONErrorResponse(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	purchases, err := h.RefundService.ListPurchases(r.Context())
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	utils.JSONResponse(w, http.StatusOK, purchases)
+}
+
+// GET, POST /api/refunds
+func (h *RefundHandler) HandleRefunds(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		requests, err := h.RefundService.ListMyRefundRequests(r.Context())
+		if err != nil {
+			writeServiceError(w, err)
+			return
+		}
+		utils.JSONResponse(w, http.StatusOK, requests)
+
+	case http.MethodPost:
+		var req CreateRefundRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			utils.JSONErrorResponse(w, http.StatusBadRequest, "invalid request body")
+			return
+		}
+		if req.PurchaseID <= 0 {
+			utils.JSONErrorResponse(w, http.StatusBadRequest, "purchase_id is required")
+			return
+		}
+
+		request, err := h.RefundService.RequestRefund(r.Context(), req.PurchaseID, req.Quantity, req.Reason)
+		if err != nil {
+			writeServiceError(w, err)
+			return
+		}
+		utils.JSONResponse(w, http.StatusCreated, request)
+
+	default:
+		utils.JSONErrorResponse(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+// GET, POST /api/admin/refunds?status=pending
+// POST выполняет возврат сразу, без заявки сотрудника.
+func (h *RefundHandler) HandleAdminRefunds(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		requests, err := h.RefundService.ListRefundRequests(r.Context(), r.URL.Query().Get("status"))
+		if err != nil {
+			writeServiceError(w, err)
+			return
+		}
+		utils.JSONResponse(w, http.StatusOK, requests)
+
+	case http.MethodPost:
+		var req AdminRefundRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			utils.JSONErrorResponse(w, http.StatusBadRequest, "invalid request body")
+			return
+		}
+		if req.PurchaseID <= 0 {
+			utils.JSONErrorResponse(w, http.StatusBadRequest, "purchase_id is required")
+			return
+		}
+
+		result, err := h.RefundService.RefundPurchase(r.Context(), req.PurchaseID, req.Quantity)
+		if err != nil {
+			writeServiceError(w, err)
+			return
+		}
+		utils.JSONResponse(w, http.StatusOK, result)
+
+	default:
+		utils.JSONErrorResponse(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+// POST /api/admin/refunds/{id}/approve, POST /api/admin/refunds/{id}/reject
+func (h *RefundHandler) HandleAdminRefundAction(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.JSONErrorResponse(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	idPart, action, ok := strings.Cut(strings.TrimPrefix(r.URL.Path, "/api/admin/refunds/"), "/")
+	if !ok {
+		utils.JSONErrorResponse(w, http.StatusNotFound, "not found")
+		return
+	}
+	id, err := strconv.ParseInt(idPart, 10, 32)
+	if err != nil || id <= 0 {
+		utils.JSONErrorResponse(w, http.StatusBadRequest, "invalid refund request id")
+		return
+	}
+
+	switch action {
+	case "approve":
+		result, err := h.RefundService.ApproveRefundRequest(r.Context(), int32(id))
+		if err != nil {
+			writeServiceError(w, err)
+			return
+		}
+		utils.JSONResponse(w, http.StatusOK, result)
+
+	case "reject":
+		if err := h.RefundService.RejectRefundRequest(r.Context(), int32(id)); err != nil {
+			writeServiceError(w, err)
+			return
+		}
+		utils.JSONResponse(w, http.StatusOK, map[string]string{"status": service.RefundStatusRejected})
+
+	default:
+		utils.JSONErrorResponse(w, http.StatusNotFound, "not found")
+	}
+}
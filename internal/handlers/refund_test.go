@@ -0,0 +1,148 @@
+package handlers_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/par1ram/merch-store/internal/handlers"
+	"github.com/par1ram/merch-store/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockRefundService struct {
+	mock.Mock
+}
+
+func (m *MockRefundService) ListPurchases(ctx context.Context) ([]service.PurchaseRecord, error) {
+	args := m.Called(ctx)
+	if res, ok := args.Get(0).([]service.PurchaseRecord); ok {
+		return res, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockRefundService) RequestRefund(ctx context.Context, purchaseID, quantity int32, reason string) (service.RefundRequest, error) {
+	args := m.Called(ctx, purchaseID, quantity, reason)
+	return args.Get(0).(service.RefundRequest), args.Error(1)
+}
+
+func (m *MockRefundService) ListMyRefundRequests(ctx context.Context) ([]service.RefundRequest, error) {
+	args := m.Called(ctx)
+	if res, ok := args.Get(0).([]service.RefundRequest); ok {
+		return res, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockRefundService) ListRefundRequests(ctx context.Context, status string) ([]service.RefundRequest, error) {
+	args := m.Called(ctx, status)
+	if res, ok := args.Get(0).([]service.RefundRequest); ok {
+		return res, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockRefundService) RefundPurchase(ctx context.Context, purchaseID, quantity int32) (service.RefundResult, error) {
+	args := m.Called(ctx, purchaseID, quantity)
+	return args.Get(0).(service.RefundResult), args.Error(1)
+}
+
+func (m *MockRefundService) ApproveRefundRequest(ctx context.Context, requestID int32) (service.RefundResult, error) {
+	args := m.Called(ctx, requestID)
+	return args.Get(0).(service.RefundResult), args.Error(1)
+}
+
+func (m *MockRefundService) RejectRefundRequest(ctx context.Context, requestID int32) error {
+	args := m.Called(ctx, requestID)
+	return args.Error(0)
+}
+
+func TestRefundHandler_HandleRefunds_Create(t *testing.T) {
+	expected := service.RefundRequest{ID: 8, PurchaseID: 5, Item: "cup", Quantity: 1, Reason: "wrong size", Status: "pending"}
+
+	mockService := new(MockRefundService)
+	mockService.On("RequestRefund", mock.Anything, int32(5), int32(1), "wrong size").Return(expected, nil).Once()
+
+	handler := handlers.NewRefundHandler(mockService)
+	body := `{"purchase_id":5,"quantity":1,"reason":"wrong size"}`
+	req := httptest.NewRequest(http.MethodPost, "/api/refunds", bytes.NewBufferString(body))
+	rr := httptest.NewRecorder()
+	handler.HandleRefunds(rr, req)
+
+	assert.Equal(t, http.StatusCreated, rr.Code)
+	var resp service.RefundRequest
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+	assert.Equal(t, expected.ID, resp.ID)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestRefundHandler_HandleRefunds_MissingPurchase(t *testing.T) {
+	mockService := new(MockRefundService)
+	handler := handlers.NewRefundHandler(mockService)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/refunds", bytes.NewBufferString(`{"quantity":1}`))
+	rr := httptest.NewRecorder()
+	handler.HandleRefunds(rr, req)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	mockService.AssertNotCalled(t, "RequestRefund", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestRefundHandler_HandleAdminRefunds_Errors(t *testing.T) {
+	cases := []struct {
+		err    error
+		status int
+	}{
+		{fmt.Errorf("%w: %w", service.ErrBusinessValidation, service.ErrPurchaseNotFound), http.StatusNotFound},
+		{fmt.Errorf("%w: %w", service.ErrBusinessValidation, service.ErrRefundExceedsPurchase), http.StatusConflict},
+		{fmt.Errorf("%w: %w", service.ErrBusinessValidation, service.ErrInvalidQuantity), http.StatusBadRequest},
+		{assert.AnError, http.StatusInternalServerError},
+	}
+
+	for _, tc := range cases {
+		mockService := new(MockRefundService)
+		mockService.On("RefundPurchase", mock.Anything, int32(5), int32(2)).Return(service.RefundResult{}, tc.err).Once()
+
+		handler := handlers.NewRefundHandler(mockService)
+		req := httptest.NewRequest(http.MethodPost, "/api/admin/refunds", bytes.NewBufferString(`{"purchase_id":5,"quantity":2}`))
+		rr := httptest.NewRecorder()
+		handler.HandleAdminRefunds(rr, req)
+
+		assert.Equal(t, tc.status, rr.Code, tc.err.Error())
+		mockService.AssertExpectations(t)
+	}
+}
+
+func TestRefundHandler_HandleAdminRefundAction(t *testing.T) {
+	mockService := new(MockRefundService)
+	mockService.On("ApproveRefundRequest", mock.Anything, int32(8)).
+		Return(service.RefundResult{RefundID: 13, PurchaseID: 5, Item: "cup", Quantity: 1, Amount: 20}, nil).Once()
+	mockService.On("RejectRefundRequest", mock.Anything, int32(9)).
+		Return(fmt.Errorf("%w: %w", service.ErrBusinessValidation, service.ErrRefundRequestResolved)).Once()
+
+	handler := handlers.NewRefundHandler(mockService)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/admin/refunds/8/approve", nil)
+	rr := httptest.NewRecorder()
+	handler.HandleAdminRefundAction(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	req = httptest.NewRequest(http.MethodPost, "/api/admin/refunds/9/reject", nil)
+	rr = httptest.NewRecorder()
+	handler.HandleAdminRefundAction(rr, req)
+	assert.Equal(t, http.StatusConflict, rr.Code)
+
+	req = httptest.NewRequest(http.MethodPost, "/api/admin/refunds/abc/approve", nil)
+	rr = httptest.NewRecorder()
+	handler.HandleAdminRefundAction(rr, req)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	mockService.AssertExpectations(t)
+}
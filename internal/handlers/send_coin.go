@@ -2,16 +2,35 @@ package handlers
 
 import (
 	"encoding/json"
-	"errors"
 	"net/http"
 
 	"github.com/par1ram/merch-store/internal/service"
 	"github.com/par1ram/merch-store/internal/utils"
 )
 
+// SendCoinRequest.Message — необязательное сообщение получателю, до 280 символов.
+// Pending удерживает монеты до решения получателя вместо немедленного зачисления.
 type SendCoinRequest struct {
-	ToUser string `json:"to_user"`
-	Amount int32  `json:"amount"`
+	ToUser  string `json:"to_user"`
+	Amount  int32  `json:"amount"`
+	Message string `json:"message,omitempty"`
+	Pending bool   `json:"pending,omitempty"`
+}
+
+// SendCoinBatchRequest — пакетный перевод. Получатели с суммами задаются
+// полем recipients; либо список to_users и общая сумма total, которая
+// делится поровну.
+type SendCoinBatchRequest struct {
+	Recipients []service.TransferLeg `json:"recipients"`
+	ToUsers    []string              `json:"to_users"`
+	Total      int32                 `json:"total"`
+	Message    string                `json:"message,omitempty"`
+}
+
+// SendCoinBatchResponse — выполненные переводы и их общая сумма.
+type SendCoinBatchResponse struct {
+	Transfers []service.TransferLeg `json:"transfers"`
+	Total     int64                 `json:"total"`
 }
 
 type SendCoinHandler struct {
@@ -43,16 +62,59 @@ func (h *SendCoinHandler) HandleSendCoin(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	err := h.SendCoinService.SendCoin(r.Context(), req.ToUser, req.Amount)
-	if err != nil {
-		switch {
-		case errors.Is(err, service.ErrBusinessValidation):
-			utils.JSONErrorResponse(w, http.StatusBadRequest, err.Error())
-		default:
-			utils.JSONErrorResponse(w, http.StatusInternalServerError, "internal error")
+	if req.Pending {
+		transfer, err := h.SendCoinService.SendCoinPending(r.Context(), req.ToUser, req.Amount, req.Message)
+		if err != nil {
+			writeServiceError(w, err)
+			return
 		}
+		utils.JSONResponse(w, http.StatusAccepted, transfer)
+		return
+	}
+
+	err := h.SendCoinService.SendCoin(r.Context(), req.ToUser, req.Amount, req.Message)
+	if err != nil {
+		writeServiceError(w, err)
 		return
 	}
 
 	utils.JSONResponse(w, http.StatusOK, map[string]string{"status": "success"})
 }
+
+// POST /api/send-coin/batch
+func (h *SendCoinHandler) HandleSendCoinBatch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.JSONErrorResponse(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	var req SendCoinBatchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.JSONErrorResponse(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if len(req.Recipients) > 0 && len(req.ToUsers) > 0 {
+		utils.JSONErrorResponse(w, http.StatusBadRequest, "use either recipients or to_users with total")
+		return
+	}
+
+	var (
+		transfers []service.TransferLeg
+		err       error
+	)
+	if len(req.ToUsers) > 0 {
+		transfers, err = h.SendCoinService.SendCoinSplit(r.Context(), req.ToUsers, req.Total, req.Message)
+	} else {
+		transfers, err = h.SendCoinService.SendCoinBatch(r.Context(), req.Recipients, req.Message)
+	}
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	resp := SendCoinBatchResponse{Transfers: transfers}
+	for _, t := range transfers {
+		resp.Total += int64(t.Amount)
+	}
+	utils.JSONResponse(w, http.StatusOK, resp)
+}
@@ -5,6 +5,7 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -19,11 +20,32 @@ type MockSendCoinService struct {
 	mock.Mock
 }
 
-func (m *MockSendCoinService) SendCoin(ctx context.Context, toUser string, amount int32) error {
-	args := m.Called(ctx, toUser, amount)
+func (m *MockSendCoinService) SendCoin(ctx context.Context, toUser string, amount int32, message string) error {
+	args := m.Called(ctx, toUser, amount, message)
 	return args.Error(0)
 }
 
+func (m *MockSendCoinService) SendCoinPending(ctx context.Context, toUser string, amount int32, message string) (service.PendingTransfer, error) {
+	args := m.Called(ctx, toUser, amount, message)
+	return args.Get(0).(service.PendingTransfer), args.Error(1)
+}
+
+func (m *MockSendCoinService) SendCoinBatch(ctx context.Context, legs []service.TransferLeg, message string) ([]service.TransferLeg, error) {
+	args := m.Called(ctx, legs, message)
+	if res, ok := args.Get(0).([]service.TransferLeg); ok {
+		return res, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockSendCoinService) SendCoinSplit(ctx context.Context, toUsers []string, total int32, message string) ([]service.TransferLeg, error) {
+	args := m.Called(ctx, toUsers, total, message)
+	if res, ok := args.Get(0).([]service.TransferLeg); ok {
+		return res, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
 func TestSendCoinHandler_HandleSendCoin_Success(t *testing.T) {
 	// Формируем корректный запрос.
 	reqBody := handlers.SendCoinRequest{
@@ -40,7 +62,7 @@ func TestSendCoinHandler_HandleSendCoin_Success(t *testing.T) {
 	// Настраиваем моковый сервис: при вызове SendCoin с аргументами ("Bob", 50) возвращаем nil.
 	mockService := new(MockSendCoinService)
 	mockService.
-		On("SendCoin", mock.Anything, "Bob", int32(50)).
+		On("SendCoin", mock.Anything, "Bob", int32(50), "").
 		Return(nil).
 		Once()
 
@@ -143,7 +165,7 @@ func TestSendCoinHandler_HandleSendCoin_BusinessValidationError(t *testing.T) {
 	// Настраиваем моковый сервис так, чтобы он возвращал бизнес-валидационную ошибку.
 	mockService := new(MockSendCoinService)
 	mockService.
-		On("SendCoin", mock.Anything, "Bob", int32(50)).
+		On("SendCoin", mock.Anything, "Bob", int32(50), "").
 		Return(service.ErrBusinessValidation).
 		Once()
 
@@ -176,7 +198,7 @@ func TestSendCoinHandler_HandleSendCoin_InternalError(t *testing.T) {
 	// Настраиваем моковый сервис так, чтобы он возвращал общую ошибку.
 	mockService := new(MockSendCoinService)
 	mockService.
-		On("SendCoin", mock.Anything, "Bob", int32(50)).
+		On("SendCoin", mock.Anything, "Bob", int32(50), "").
 		Return(errors.New("some internal error")).
 		Once()
 
@@ -191,3 +213,109 @@ func TestSendCoinHandler_HandleSendCoin_InternalError(t *testing.T) {
 
 	mockService.AssertExpectations(t)
 }
+
+func TestSendCoinHandler_HandleSendCoin_WithMessage(t *testing.T) {
+	bodyBytes, err := json.Marshal(handlers.SendCoinRequest{ToUser: "Bob", Amount: 50, Message: "thanks! #teamwork"})
+	assert.NoError(t, err)
+
+	req := httptest.NewRequest("POST", "/api/send-coin", bytes.NewBuffer(bodyBytes))
+	rr := httptest.NewRecorder()
+
+	mockService := new(MockSendCoinService)
+	mockService.
+		On("SendCoin", mock.Anything, "Bob", int32(50), "thanks! #teamwork").
+		Return(fmt.Errorf("%w: %w", service.ErrBusinessValidation, service.ErrInvalidMessage)).
+		Once()
+
+	handler := handlers.NewSendCoinHandler(mockService)
+	handler.HandleSendCoin(rr, req)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	var resp map[string]interface{}
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+	assert.Equal(t, string(service.CodeInvalidMessage), resp["code"])
+
+	mockService.AssertExpectations(t)
+}
+
+func TestSendCoinHandler_HandleSendCoin_Pending(t *testing.T) {
+	bodyBytes, err := json.Marshal(handlers.SendCoinRequest{ToUser: "Bob", Amount: 50, Pending: true})
+	assert.NoError(t, err)
+
+	req := httptest.NewRequest("POST", "/api/send-coin", bytes.NewBuffer(bodyBytes))
+	rr := httptest.NewRecorder()
+
+	mockService := new(MockSendCoinService)
+	mockService.
+		On("SendCoinPending", mock.Anything, "Bob", int32(50), "").
+		Return(service.PendingTransfer{ID: 7, Recipient: "Bob", Amount: 50, Status: service.PendingTransferStatusPending}, nil).
+		Once()
+
+	handler := handlers.NewSendCoinHandler(mockService)
+	handler.HandleSendCoin(rr, req)
+
+	assert.Equal(t, http.StatusAccepted, rr.Code)
+	var resp service.PendingTransfer
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+	assert.Equal(t, int32(7), resp.ID)
+	assert.Equal(t, service.PendingTransferStatusPending, resp.Status)
+
+	mockService.AssertNotCalled(t, "SendCoin", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	mockService.AssertExpectations(t)
+}
+
+func TestSendCoinHandler_HandleSendCoinBatch(t *testing.T) {
+	legs := []service.TransferLeg{{ToUser: "alice", Amount: 30}, {ToUser: "bob", Amount: 20}}
+
+	mockService := new(MockSendCoinService)
+	mockService.On("SendCoinBatch", mock.Anything, legs, "sprint done").Return(legs, nil).Once()
+
+	handler := handlers.NewSendCoinHandler(mockService)
+
+	body, err := json.Marshal(handlers.SendCoinBatchRequest{Recipients: legs, Message: "sprint done"})
+	assert.NoError(t, err)
+	req := httptest.NewRequest(http.MethodPost, "/api/send-coin/batch", bytes.NewBuffer(body))
+	rr := httptest.NewRecorder()
+	handler.HandleSendCoinBatch(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var resp handlers.SendCoinBatchResponse
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+	assert.Equal(t, handlers.SendCoinBatchResponse{Transfers: legs, Total: 50}, resp)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestSendCoinHandler_HandleSendCoinBatch_Split(t *testing.T) {
+	legs := []service.TransferLeg{{ToUser: "alice", Amount: 34}, {ToUser: "bob", Amount: 33}, {ToUser: "carol", Amount: 33}}
+
+	mockService := new(MockSendCoinService)
+	mockService.On("SendCoinSplit", mock.Anything, []string{"alice", "bob", "carol"}, int32(100), "").Return(legs, nil).Once()
+	mockService.On("SendCoinSplit", mock.Anything, []string{"alice"}, int32(5000), "").
+		Return(nil, fmt.Errorf("%w: %w", service.ErrBusinessValidation, service.ErrInsufficientFunds)).Once()
+
+	handler := handlers.NewSendCoinHandler(mockService)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/send-coin/batch",
+		bytes.NewBufferString(`{"to_users":["alice","bob","carol"],"total":100}`))
+	rr := httptest.NewRecorder()
+	handler.HandleSendCoinBatch(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var resp handlers.SendCoinBatchResponse
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+	assert.Equal(t, int64(100), resp.Total)
+
+	req = httptest.NewRequest(http.MethodPost, "/api/send-coin/batch",
+		bytes.NewBufferString(`{"to_users":["alice"],"total":5000}`))
+	rr = httptest.NewRecorder()
+	handler.HandleSendCoinBatch(rr, req)
+	assert.Equal(t, http.StatusPaymentRequired, rr.Code)
+
+	req = httptest.NewRequest(http.MethodPost, "/api/send-coin/batch",
+		bytes.NewBufferString(`{"to_users":["alice"],"total":10,"recipients":[{"to_user":"bob","amount":5}]}`))
+	rr = httptest.NewRecorder()
+	handler.HandleSendCoinBatch(rr, req)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	mockService.AssertExpectations(t)
+}
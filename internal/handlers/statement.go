@@ -0,0 +1,252 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"html/template"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/par1ram/merch-store/internal/service"
+	"github.com/par1ram/merch-store/internal/utils"
+)
+
+// Форматы выписки, выбираемые параметром format.
+const (
+	statementFormatCSV  = "csv"
+	statementFormatHTML = "html"
+)
+
+type StatementHandler struct {
+	StatementService service.StatementService
+}
+
+func NewStatementHandler(statementService service.StatementService) *StatementHandler {
+	return &StatementHandler{StatementService: statementService}
+}
+
+// GET /api/statements/{yyyy-mm}?format=csv|html
+// Выписка текущего пользователя за месяц: входящий остаток, все операции и
+// исходящий остаток. По умолчанию CSV; html — страница для печати.
+func (h *StatementHandler) HandleStatement(w http.ResponseWriter, r *http.Request) {
+	period, format, ok := statementRequest(w, r, "/api/statements/")
+	if !ok {
+		return
+	}
+
+	statement, err := h.StatementService.GetStatement(r.Context(), period)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeStatements(w, format, "statement-"+period, []service.Statement{statement})
+}
+
+// GET /api/admin/statements/{yyyy-mm}?format=csv|html
+// Выписки всех сотрудников за месяц одним файлом: в CSV строки различаются
+// колонкой employee, в HTML каждая выписка печатается с новой страницы.
+func (h *StatementHandler) HandleAdminStatements(w http.ResponseWriter, r *http.Request) {
+	period, format, ok := statementRequest(w, r, "/api/admin/statements/")
+	if !ok {
+		return
+	}
+
+	statements, err := h.StatementService.ListStatements(r.Context(), period)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeStatements(w, format, "statements-"+period, statements)
+}
+
+// statementRequest проверяет метод и формат и извлекает период из пути.
+// При ошибке ответ уже отправлен.
+func statementRequest(w http.ResponseWriter, r *http.Request, prefix string) (period, format string, ok bool) {
+	if r.Method != http.MethodGet {
+		utils.JSONErrorResponse(w, http.StatusMethodNotAllowed, "method not allowed")
+		return "", "", false
+	}
+
+	period = strings.TrimPrefix(r.URL.Path, prefix)
+	if period == "" || strings.Contains(period, "/") {
+		utils.JSONErrorResponse(w, http.StatusNotFound, "not found")
+		return "", "", false
+	}
+
+	format = r.URL.Query().Get("format")
+	switch format {
+	case "":
+		format = statementFormatCSV
+	case statementFormatCSV, statementFormatHTML:
+	default:
+		utils.JSONErrorResponse(w, http.StatusBadRequest, "format must be csv or html")
+		return "", "", false
+	}
+	return period, format, true
+}
+
+// writeStatements рендерит выписки целиком в память, чтобы ошибка рендеринга
+// превратилась в 500, а не в обрезанный файл.
+func writeStatements(w http.ResponseWriter, format, name string, statements []service.Statement) {
+	var (
+		buf         bytes.Buffer
+		err         error
+		contentType string
+	)
+	switch format {
+	case statementFormatHTML:
+		contentType = "text/html; charset=utf-8"
+		err = statementTemplate.Execute(&buf, statements)
+	default:
+		contentType = "text/csv; charset=utf-8"
+		err = writeStatementsCSV(&buf, statements)
+	}
+	if err != nil {
+		utils.JSONErrorResponse(w, http.StatusInternalServerError, "failed to render statement")
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("%s; filename=%q", statementDisposition(format), name+"."+format))
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(buf.Bytes())
+}
+
+// HTML открывается в браузере для печати, CSV скачивается файлом.
+func statementDisposition(format string) string {
+	if format == statementFormatHTML {
+		return "inline"
+	}
+	return "attachment"
+}
+
+var statementCSVHeader = []string{
+	"employee", "time", "transaction_id", "type", "counterparty", "item", "quantity", "amount", "balance", "description",
+}
+
+// writeStatementsCSV пишет выписки одной таблицей. Каждая выписка начинается
+// строкой opening_balance и заканчивается строкой closing_balance; amount
+// операции со знаком, balance — остаток после неё. У строки signup нет id
+// операции.
+func writeStatementsCSV(buf *bytes.Buffer, statements []service.Statement) error {
+	cw := csv.NewWriter(buf)
+	if err := cw.Write(statementCSVHeader); err != nil {
+		return err
+	}
+	for _, s := range statements {
+		employee := csvText(s.Username)
+		if err := cw.Write([]string{
+			employee, s.From.Format(time.RFC3339), "", "opening_balance", "", "", "", "", strconv.FormatInt(s.Opening, 10), "",
+		}); err != nil {
+			return err
+		}
+		for _, l := range s.Lines {
+			quantity := ""
+			if l.Quantity > 0 {
+				quantity = strconv.Itoa(int(l.Quantity))
+			}
+			id := ""
+			if l.ID != 0 {
+				id = strconv.Itoa(int(l.ID))
+			}
+			if err := cw.Write([]string{
+				employee,
+				l.CreatedAt.UTC().Format(time.RFC3339),
+				id,
+				l.Type,
+				csvText(l.Counterparty),
+				csvText(l.Item),
+				quantity,
+				strconv.FormatInt(l.Change(), 10),
+				strconv.FormatInt(l.Balance, 10),
+				csvText(statementDescription(l)),
+			}); err != nil {
+				return err
+			}
+		}
+		if err := cw.Write([]string{
+			employee, s.To.Format(time.RFC3339), "", "closing_balance", "", "", "", "", strconv.FormatInt(s.Closing, 10), "",
+		}); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// csvText защищает от формул в табличных редакторах: текст пользователя,
+// начинающийся с =, +, - или @, выводится с ведущим апострофом.
+func csvText(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+// statementDescription — причина операции, а если её нет — сообщение перевода.
+func statementDescription(l service.StatementLine) string {
+	if l.Reason != "" {
+		return l.Reason
+	}
+	return l.Message
+}
+
+var statementTemplate = template.Must(template.New("statement").Funcs(template.FuncMap{
+	"datetime":    func(t time.Time) string { return t.UTC().Format("2006-01-02 15:04") },
+	"date":        func(t time.Time) string { return t.UTC().Format(time.DateOnly) },
+	"lastDay":     func(t time.Time) string { return t.UTC().AddDate(0, 0, -1).Format(time.DateOnly) },
+	"description": statementDescription,
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Coin statement</title>
+<style>
+  body { font-family: sans-serif; font-size: 12px; margin: 2em; }
+  section { margin-bottom: 3em; }
+  table { width: 100%; border-collapse: collapse; }
+  th, td { border-bottom: 1px solid #ccc; padding: 4px 6px; text-align: left; }
+  td.num, th.num { text-align: right; }
+  tr.total td { font-weight: bold; }
+  @media print {
+    body { margin: 0; }
+    section { page-break-after: always; }
+    section:last-child { page-break-after: auto; }
+  }
+</style>
+</head>
+<body>
+{{- range .}}
+<section>
+  <h1>Coin statement: {{.Username}}</h1>
+  <p>Period {{.Period}} ({{date .From}} – {{lastDay .To}}, UTC)</p>
+  <table>
+    <thead>
+      <tr><th>Time</th><th>Type</th><th>Counterparty</th><th>Item</th><th>Description</th><th class="num">Amount</th><th class="num">Balance</th></tr>
+    </thead>
+    <tbody>
+      <tr class="total"><td>{{date .From}}</td><td colspan="5">Opening balance</td><td class="num">{{.Opening}}</td></tr>
+      {{- range .Lines}}
+      <tr>
+        <td>{{datetime .CreatedAt}}</td>
+        <td>{{.Type}}</td>
+        <td>{{.Counterparty}}</td>
+        <td>{{.Item}}{{if .Quantity}} × {{.Quantity}}{{end}}</td>
+        <td>{{description .}}</td>
+        <td class="num">{{.Change}}</td>
+        <td class="num">{{.Balance}}</td>
+      </tr>
+      {{- end}}
+      <tr class="total"><td>{{lastDay .To}}</td><td colspan="5">Closing balance</td><td class="num">{{.Closing}}</td></tr>
+    </tbody>
+  </table>
+</section>
+{{- else}}
+<p>No statements for the period.</p>
+{{- end}}
+</body>
+</html>
+`))
@@ -0,0 +1,203 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/par1ram/merch-store/internal/handlers"
+	"github.com/par1ram/merch-store/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockStatementService struct {
+	mock.Mock
+}
+
+func (m *MockStatementService) GetStatement(ctx context.Context, period string) (service.Statement, error) {
+	args := m.Called(ctx, period)
+	return args.Get(0).(service.Statement), args.Error(1)
+}
+
+func (m *MockStatementService) ListStatements(ctx context.Context, period string) ([]service.Statement, error) {
+	args := m.Called(ctx, period)
+	if statements, ok := args.Get(0).([]service.Statement); ok {
+		return statements, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func testStatement(username string) service.Statement {
+	from := time.Date(2026, time.September, 1, 0, 0, 0, 0, time.UTC)
+	return service.Statement{
+		EmployeeID: 1,
+		Username:   username,
+		Period:     "2026-09",
+		From:       from,
+		To:         from.AddDate(0, 1, 0),
+		Opening:    1000,
+		Closing:    920,
+		Lines: []service.StatementLine{
+			{
+				HistoryEntry: service.HistoryEntry{
+					ID: 7, Type: "purchase", Direction: service.HistoryDirectionOutgoing, Amount: 80,
+					Item: "cup", Quantity: 1, CreatedAt: from.Add(time.Hour),
+				},
+				Balance: 920,
+			},
+		},
+	}
+}
+
+func TestStatementHandler_CSV(t *testing.T) {
+	mockService := new(MockStatementService)
+	mockService.On("GetStatement", mock.Anything, "2026-09").Return(testStatement("alice"), nil).Once()
+
+	handler := handlers.NewStatementHandler(mockService)
+	req := httptest.NewRequest(http.MethodGet, "/api/statements/2026-09", nil)
+	rr := httptest.NewRecorder()
+	handler.HandleStatement(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "text/csv; charset=utf-8", rr.Header().Get("Content-Type"))
+	assert.Equal(t, `attachment; filename="statement-2026-09.csv"`, rr.Header().Get("Content-Disposition"))
+
+	records, err := csv.NewReader(rr.Body).ReadAll()
+	assert.NoError(t, err)
+	assert.Equal(t, [][]string{
+		{"employee", "time", "transaction_id", "type", "counterparty", "item", "quantity", "amount", "balance", "description"},
+		{"alice", "2026-09-01T00:00:00Z", "", "opening_balance", "", "", "", "", "1000", ""},
+		{"alice", "2026-09-01T01:00:00Z", "7", "purchase", "", "cup", "1", "-80", "920", ""},
+		{"alice", "2026-10-01T00:00:00Z", "", "closing_balance", "", "", "", "", "920", ""},
+	}, records)
+	mockService.AssertExpectations(t)
+}
+
+func TestStatementHandler_CSVSignupLine(t *testing.T) {
+	statement := testStatement("alice")
+	statement.Opening = 0
+	statement.Lines = append([]service.StatementLine{{
+		HistoryEntry: service.HistoryEntry{
+			Type: service.StatementTypeSignup, Direction: service.HistoryDirectionIncoming, Amount: 1000,
+			CreatedAt: statement.From.Add(30 * time.Minute),
+		},
+		Balance: 1000,
+	}}, statement.Lines...)
+
+	mockService := new(MockStatementService)
+	mockService.On("GetStatement", mock.Anything, "2026-09").Return(statement, nil).Once()
+
+	handler := handlers.NewStatementHandler(mockService)
+	req := httptest.NewRequest(http.MethodGet, "/api/statements/2026-09", nil)
+	rr := httptest.NewRecorder()
+	handler.HandleStatement(rr, req)
+
+	records, err := csv.NewReader(rr.Body).ReadAll()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"alice", "2026-09-01T00:00:00Z", "", "opening_balance", "", "", "", "", "0", ""}, records[1])
+	assert.Equal(t, []string{"alice", "2026-09-01T00:30:00Z", "", "signup", "", "", "", "1000", "1000", ""}, records[2])
+	mockService.AssertExpectations(t)
+}
+
+func TestStatementHandler_CSVEscapesFormulas(t *testing.T) {
+	mockService := new(MockStatementService)
+	mockService.On("GetStatement", mock.Anything, "2026-09").Return(testStatement("=cmd()"), nil).Once()
+
+	handler := handlers.NewStatementHandler(mockService)
+	req := httptest.NewRequest(http.MethodGet, "/api/statements/2026-09?format=csv", nil)
+	rr := httptest.NewRecorder()
+	handler.HandleStatement(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), "'=cmd()")
+	mockService.AssertExpectations(t)
+}
+
+func TestStatementHandler_HTML(t *testing.T) {
+	mockService := new(MockStatementService)
+	mockService.On("GetStatement", mock.Anything, "2026-09").Return(testStatement("<alice>"), nil).Once()
+
+	handler := handlers.NewStatementHandler(mockService)
+	req := httptest.NewRequest(http.MethodGet, "/api/statements/2026-09?format=html", nil)
+	rr := httptest.NewRecorder()
+	handler.HandleStatement(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "text/html; charset=utf-8", rr.Header().Get("Content-Type"))
+	assert.Equal(t, `inline; filename="statement-2026-09.html"`, rr.Header().Get("Content-Disposition"))
+	body := rr.Body.String()
+	assert.Contains(t, body, "&lt;alice&gt;")
+	assert.Contains(t, body, "Opening balance")
+	assert.Contains(t, body, "-80")
+	assert.Contains(t, body, "2026-09-30")
+	mockService.AssertExpectations(t)
+}
+
+func TestStatementHandler_BadRequests(t *testing.T) {
+	handler := handlers.NewStatementHandler(new(MockStatementService))
+
+	for target, status := range map[string]int{
+		"/api/statements/":                    http.StatusNotFound,
+		"/api/statements/2026-09/extra":       http.StatusNotFound,
+		"/api/statements/2026-09?format=pdf":  http.StatusBadRequest,
+		"/api/statements/2026-09?format=json": http.StatusBadRequest,
+	} {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		rr := httptest.NewRecorder()
+		handler.HandleStatement(rr, req)
+		assert.Equal(t, status, rr.Code, target)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/statements/2026-09", nil)
+	rr := httptest.NewRecorder()
+	handler.HandleStatement(rr, req)
+	assert.Equal(t, http.StatusMethodNotAllowed, rr.Code)
+}
+
+func TestStatementHandler_InvalidPeriod(t *testing.T) {
+	mockService := new(MockStatementService)
+	mockService.On("GetStatement", mock.Anything, "2026-13").
+		Return(service.Statement{}, fmt.Errorf("%w: %w", service.ErrBusinessValidation, service.ErrInvalidStatementPeriod)).Once()
+
+	handler := handlers.NewStatementHandler(mockService)
+	req := httptest.NewRequest(http.MethodGet, "/api/statements/2026-13", nil)
+	rr := httptest.NewRecorder()
+	handler.HandleStatement(rr, req)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Contains(t, rr.Body.String(), "INVALID_STATEMENT_PERIOD")
+	mockService.AssertExpectations(t)
+}
+
+func TestStatementHandler_AdminBulk(t *testing.T) {
+	mockService := new(MockStatementService)
+	mockService.On("ListStatements", mock.Anything, "2026-09").
+		Return([]service.Statement{testStatement("alice"), testStatement("bob")}, nil).Twice()
+
+	handler := handlers.NewStatementHandler(mockService)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/admin/statements/2026-09", nil)
+	rr := httptest.NewRecorder()
+	handler.HandleAdminStatements(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, `attachment; filename="statements-2026-09.csv"`, rr.Header().Get("Content-Disposition"))
+	records, err := csv.NewReader(rr.Body).ReadAll()
+	assert.NoError(t, err)
+	assert.Len(t, records, 7)
+	assert.Equal(t, "bob", records[6][0])
+
+	req = httptest.NewRequest(http.MethodGet, "/api/admin/statements/2026-09?format=html", nil)
+	rr = httptest.NewRecorder()
+	handler.HandleAdminStatements(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, 2, strings.Count(rr.Body.String(), "<section>"))
+	mockService.AssertExpectations(t)
+}
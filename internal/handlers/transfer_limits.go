@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/par1ram/merch-store/internal/service"
+	"github.com/par1ram/merch-store/internal/utils"
+)
+
+type TransferLimitHandler struct {
+	TransferLimitService service.TransferLimitService
+}
+
+func NewTransferLimitHandler(transferLimitService service.TransferLimitService) *TransferLimitHandler {
+	return &TransferLimitHandler{TransferLimitService: transferLimitService}
+}
+
+// GET, PUT, DELETE /api/admin/transfer-limits/{username}
+// PUT задаёт индивидуальные лимиты (null — лимит по умолчанию, 0 — переводы запрещены),
+// DELETE возвращает сотруднику лимиты по умолчанию.
+func (h *TransferLimitHandler) HandleAdminTransferLimits(w http.ResponseWriter, r *http.Request) {
+	username := strings.TrimPrefix(r.URL.Path, "/api/admin/transfer-limits/")
+	if username == "" || strings.Contains(username, "/") {
+		utils.JSONErrorResponse(w, http.StatusNotFound, "not found")
+		return
+	}
+
+	var (
+		limits service.EmployeeTransferLimits
+		err    error
+	)
+	switch r.Method {
+	case http.MethodGet:
+		limits, err = h.TransferLimitService.GetLimits(r.Context(), username)
+	case http.MethodPut:
+		var req service.TransferLimitOverride
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			utils.JSONErrorResponse(w, http.StatusBadRequest, "invalid request body")
+			return
+		}
+		limits, err = h.TransferLimitService.SetOverride(r.Context(), username, req)
+	case http.MethodDelete:
+		limits, err = h.TransferLimitService.ResetOverride(r.Context(), username)
+	default:
+		utils.JSONErrorResponse(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	utils.JSONResponse(w, http.StatusOK, limits)
+}
@@ -0,0 +1,89 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/par1ram/merch-store/internal/handlers"
+	"github.com/par1ram/merch-store/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockTransferLimitService struct {
+	mock.Mock
+}
+
+func (m *MockTransferLimitService) GetLimits(ctx context.Context, username string) (service.EmployeeTransferLimits, error) {
+	args := m.Called(ctx, username)
+	return args.Get(0).(service.EmployeeTransferLimits), args.Error(1)
+}
+
+func (m *MockTransferLimitService) SetOverride(ctx context.Context, username string, override service.TransferLimitOverride) (service.EmployeeTransferLimits, error) {
+	args := m.Called(ctx, username, override)
+	return args.Get(0).(service.EmployeeTransferLimits), args.Error(1)
+}
+
+func (m *MockTransferLimitService) ResetOverride(ctx context.Context, username string) (service.EmployeeTransferLimits, error) {
+	args := m.Called(ctx, username)
+	return args.Get(0).(service.EmployeeTransferLimits), args.Error(1)
+}
+
+func TestTransferLimitHandler_Put(t *testing.T) {
+	daily := int32(50)
+	expected := service.EmployeeTransferLimits{
+		Username:  "bob",
+		Effective: service.TransferLimits{Daily: 50, Monthly: 1000},
+		Override:  service.TransferLimitOverride{Daily: &daily},
+	}
+
+	mockService := new(MockTransferLimitService)
+	mockService.On("SetOverride", mock.Anything, "bob", service.TransferLimitOverride{Daily: &daily}).Return(expected, nil).Once()
+
+	handler := handlers.NewTransferLimitHandler(mockService)
+
+	req := httptest.NewRequest(http.MethodPut, "/api/admin/transfer-limits/bob", strings.NewReader(`{"daily":50,"monthly":null}`))
+	rr := httptest.NewRecorder()
+	handler.HandleAdminTransferLimits(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var resp service.EmployeeTransferLimits
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+	assert.Equal(t, expected, resp)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestTransferLimitHandler_Errors(t *testing.T) {
+	mockService := new(MockTransferLimitService)
+	mockService.On("ResetOverride", mock.Anything, "ghost").
+		Return(service.EmployeeTransferLimits{}, fmt.Errorf("%w: ghost", service.ErrUserNotFound)).Once()
+
+	handler := handlers.NewTransferLimitHandler(mockService)
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/admin/transfer-limits/ghost", nil)
+	rr := httptest.NewRecorder()
+	handler.HandleAdminTransferLimits(rr, req)
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+
+	req = httptest.NewRequest(http.MethodPut, "/api/admin/transfer-limits/bob", strings.NewReader(`{`))
+	rr = httptest.NewRecorder()
+	handler.HandleAdminTransferLimits(rr, req)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	req = httptest.NewRequest(http.MethodPost, "/api/admin/transfer-limits/bob", nil)
+	rr = httptest.NewRecorder()
+	handler.HandleAdminTransferLimits(rr, req)
+	assert.Equal(t, http.StatusMethodNotAllowed, rr.Code)
+
+	req = httptest.NewRequest(http.MethodGet, "/api/admin/transfer-limits/", nil)
+	rr = httptest.NewRecorder()
+	handler.HandleAdminTransferLimits(rr, req)
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+
+	mockService.AssertExpectations(t)
+}
@@ -0,0 +1,27 @@
+// Package metrics публикует счётчики приложения через expvar (GET /debug/vars).
+package metrics
+
+import "expvar"
+
+var (
+	// BalanceDriftEmployees — число сотрудников, баланс которых расходится
+	// с историей операций, по результату последней сверки.
+	BalanceDriftEmployees = expvar.NewInt("balance_drift_employees")
+	// BalanceDriftCoins — сумма расхождений по модулю по результату последней сверки.
+	BalanceDriftCoins = expvar.NewInt("balance_drift_coins")
+	// CoinLotDriftEmployees — число сотрудников, у которых сумма остатков
+	// партий расходится с балансом, по результату последней сверки.
+	CoinLotDriftEmployees = expvar.NewInt("coin_lot_drift_employees")
+	// ReconciliationLastRun — время последней сверки балансов (Unix, секунды).
+	ReconciliationLastRun = expvar.NewInt("reconciliation_last_run_unix")
+)
+
+var (
+	// TxConflicts — число транзакций, прерванных сбоем сериализации или
+	// взаимной блокировкой, по SQLSTATE (40001, 40P01).
+	TxConflicts = expvar.NewMap("tx_conflicts")
+	// TxRetries — число повторов транзакций после таких сбоев.
+	TxRetries = expvar.NewInt("tx_retries")
+	// TxRetriesExhausted — число транзакций, не выполненных за отведённые попытки.
+	TxRetriesExhausted = expvar.NewInt("tx_retries_exhausted")
+)
@@ -2,16 +2,27 @@ package middleware
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"net/http"
 	"strings"
 
 	"github.com/golang-jwt/jwt/v4"
+	"github.com/par1ram/merch-store/internal/utils"
 )
 
 type contextKey string
 
 const UserCtxKey = contextKey("user")
 
+// Коды ошибок аутентификации и авторизации; совпадают с кодами сервисного
+// слоя, чтобы отказы middleware не отличались по формату от ответов обработчиков.
+const (
+	codeUnauthenticated = "UNAUTHENTICATED"
+	codeForbidden       = "FORBIDDEN"
+	codeInternalError   = "INTERNAL_ERROR"
+)
+
 // JWTMiddleware проверяет валидность JWT-токена и добавляет данные из него в контекст.
 func JWTMiddleware(jwtSecret []byte) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
@@ -19,13 +30,13 @@ func JWTMiddleware(jwtSecret []byte) func(http.Handler) http.Handler {
 
 			authHeader := r.Header.Get("Authorization")
 			if authHeader == "" {
-				http.Error(w, "missing authorization header", http.StatusUnauthorized)
+				utils.JSONErrorCodeResponse(w, http.StatusUnauthorized, codeUnauthenticated, "missing authorization header")
 				return
 			}
 
 			parts := strings.Split(authHeader, " ")
 			if len(parts) != 2 || parts[0] != "Bearer" {
-				http.Error(w, "invalid authorization header", http.StatusUnauthorized)
+				utils.JSONErrorCodeResponse(w, http.StatusUnauthorized, codeUnauthenticated, "invalid authorization header")
 				return
 			}
 			tokenStr := parts[1]
@@ -37,7 +48,7 @@ func JWTMiddleware(jwtSecret []byte) func(http.Handler) http.Handler {
 				return jwtSecret, nil
 			})
 			if err != nil || !token.Valid {
-				http.Error(w, "invalid token", http.StatusUnauthorized)
+				utils.JSONErrorCodeResponse(w, http.StatusUnauthorized, codeUnauthenticated, "invalid token")
 				return
 			}
 
@@ -45,7 +56,7 @@ func JWTMiddleware(jwtSecret []byte) func(http.Handler) http.Handler {
 				ctx := context.WithValue(r.Context(), UserCtxKey, claims)
 				r = r.WithContext(ctx)
 			} else {
-				http.Error(w, "invalid token claims", http.StatusUnauthorized)
+				utils.JSONErrorCodeResponse(w, http.StatusUnauthorized, codeUnauthenticated, "invalid token claims")
 				return
 			}
 
@@ -54,6 +65,47 @@ func JWTMiddleware(jwtSecret []byte) func(http.Handler) http.Handler {
 	}
 }
 
+// RoleLookup возвращает текущую роль сотрудника. Если сотрудника нет,
+// возвращает ошибку, оборачивающую sql.ErrNoRows.
+type RoleLookup func(ctx context.Context, employeeID int32) (string, error)
+
+// RequireRole пропускает запрос, только если текущая роль пользователя входит
+// в список разрешённых. Роль читается через lookup из базы, а не из токена:
+// после понижения роли старый токен не должен сохранять доступ до истечения
+// срока. Должен применяться после JWTMiddleware.
+func RequireRole(lookup RoleLookup, roles ...string) func(http.Handler) http.Handler {
+	allowed := make(map[string]struct{}, len(roles))
+	for _, role := range roles {
+		allowed[role] = struct{}{}
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			userID := GetUserIDFromContext(r.Context())
+			if userID == 0 {
+				utils.JSONErrorCodeResponse(w, http.StatusForbidden, codeForbidden, "forbidden")
+				return
+			}
+
+			role, err := lookup(r.Context(), int32(userID))
+			switch {
+			case errors.Is(err, sql.ErrNoRows):
+				utils.JSONErrorCodeResponse(w, http.StatusForbidden, codeForbidden, "forbidden")
+				return
+			case err != nil:
+				utils.JSONErrorCodeResponse(w, http.StatusInternalServerError, codeInternalError, "internal error")
+				return
+			}
+
+			if _, ok := allowed[role]; !ok {
+				utils.JSONErrorCodeResponse(w, http.StatusForbidden, codeForbidden, "forbidden")
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
 // GetUserIDFromContext — вспомогательная функция для извлечения userID из контекста.
 func GetUserIDFromContext(ctx context.Context) int64 {
 	claims, ok := ctx.Value(UserCtxKey).(jwt.MapClaims)
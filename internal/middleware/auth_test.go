@@ -2,6 +2,8 @@ package middleware_test
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -11,6 +13,7 @@ import (
 	"github.com/golang-jwt/jwt/v4"
 	"github.com/par1ram/merch-store/internal/middleware"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
 // dummyHandler — простой обработчик, который запоминает, что был вызван.
@@ -38,7 +41,8 @@ func TestJWTMiddleware_MissingAuthorizationHeader(t *testing.T) {
 	handler.ServeHTTP(rr, req)
 
 	assert.Equal(t, http.StatusUnauthorized, rr.Code)
-	assert.Contains(t, rr.Body.String(), "missing authorization header")
+	assert.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{"error":"missing authorization header","code":"UNAUTHENTICATED"}`, rr.Body.String())
 	assert.False(t, next.called, "next handler should not be called")
 }
 
@@ -150,3 +154,55 @@ func TestGetUserIDFromContext(t *testing.T) {
 	userID = middleware.GetUserIDFromContext(ctxWithClaims)
 	assert.Equal(t, int64(99), userID)
 }
+
+type MockRoleRepository struct {
+	mock.Mock
+}
+
+func (m *MockRoleRepository) GetRole(ctx context.Context, employeeID int32) (string, error) {
+	args := m.Called(ctx, employeeID)
+	return args.String(0), args.Error(1)
+}
+
+func TestRequireRole(t *testing.T) {
+	repo := new(MockRoleRepository)
+	repo.On("GetRole", mock.Anything, int32(1)).Return("admin", nil)
+	repo.On("GetRole", mock.Anything, int32(2)).Return("manager", nil)
+	repo.On("GetRole", mock.Anything, int32(3)).Return("employee", nil)
+	repo.On("GetRole", mock.Anything, int32(4)).Return("", sql.ErrNoRows)
+	repo.On("GetRole", mock.Anything, int32(5)).Return("", errors.New("db error"))
+
+	mw := middleware.RequireRole(repo.GetRole, "admin", "manager")
+
+	cases := []struct {
+		claims jwt.MapClaims
+		status int
+	}{
+		{jwt.MapClaims{"user_id": float64(1), "role": "employee"}, http.StatusOK},
+		{jwt.MapClaims{"user_id": float64(2)}, http.StatusOK},
+		// Роль из токена не учитывается: сотрудника понизили после выдачи токена.
+		{jwt.MapClaims{"user_id": float64(3), "role": "admin"}, http.StatusForbidden},
+		{jwt.MapClaims{"user_id": float64(4), "role": "admin"}, http.StatusForbidden},
+		{jwt.MapClaims{"user_id": float64(5), "role": "admin"}, http.StatusInternalServerError},
+		{jwt.MapClaims{"role": "admin"}, http.StatusForbidden},
+	}
+
+	for _, tc := range cases {
+		next := &dummyHandler{}
+		req := httptest.NewRequest(http.MethodGet, "/api/admin/merch", nil)
+		req = req.WithContext(context.WithValue(req.Context(), middleware.UserCtxKey, tc.claims))
+		rr := httptest.NewRecorder()
+
+		mw(next).ServeHTTP(rr, req)
+
+		assert.Equal(t, tc.status, rr.Code, tc.claims)
+		assert.Equal(t, tc.status == http.StatusOK, next.called)
+		switch tc.status {
+		case http.StatusForbidden:
+			assert.JSONEq(t, `{"error":"forbidden","code":"FORBIDDEN"}`, rr.Body.String())
+		case http.StatusInternalServerError:
+			assert.JSONEq(t, `{"error":"internal error","code":"INTERNAL_ERROR"}`, rr.Body.String())
+		}
+	}
+	repo.AssertExpectations(t)
+}
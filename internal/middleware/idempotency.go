@@ -0,0 +1,166 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/par1ram/merch-store/internal/repository"
+	"github.com/par1ram/merch-store/internal/utils"
+)
+
+// IdempotencyKeyHeader — заголовок, которым клиент помечает повторяемый запрос.
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// IdempotentReplayHeader выставляется в ответах, восстановленных из хранилища.
+const IdempotentReplayHeader = "Idempotent-Replayed"
+
+// maxIdempotencyKeyLength совпадает с размером колонки idempotency_keys.idempotency_key.
+const maxIdempotencyKeyLength = 255
+
+// Idempotency повторно отдаёт сохранённый ответ, если запрос с тем же
+// Idempotency-Key уже выполнялся в пределах окна window. Повтор не вызывает
+// обработчик, поэтому побочные эффекты не повторяются. Ответы 5xx не
+// сохраняются: такой запрос можно безопасно повторить. Так же освобождается
+// ключ, если обработчик паникует; сама паника передаётся дальше.
+// GET и HEAD пропускаются без проверки ключа.
+// Должен применяться после JWTMiddleware — ключи уникальны в пределах пользователя.
+func Idempotency(repo repository.IdempotencyRepository, window time.Duration, logger utils.Logger) func(http.Handler) http.Handler {
+	return idempotency(repo, window, logger, true)
+}
+
+// IdempotencyAnyMethod — Idempotency для обработчиков, которые меняют данные
+// при любом методе запроса, включая GET: например, покупка GET /api/buy/{item}.
+func IdempotencyAnyMethod(repo repository.IdempotencyRepository, window time.Duration, logger utils.Logger) func(http.Handler) http.Handler {
+	return idempotency(repo, window, logger, false)
+}
+
+func idempotency(repo repository.IdempotencyRepository, window time.Duration, logger utils.Logger, skipSafe bool) func(http.Handler) http.Handler {
+	logger = logger.WithFields(utils.LogFields{"component": "idempotency_middleware"})
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get(IdempotencyKeyHeader)
+			safe := r.Method == http.MethodGet || r.Method == http.MethodHead
+			if key == "" || (skipSafe && safe) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if len(key) > maxIdempotencyKeyLength {
+				utils.JSONErrorResponse(w, http.StatusBadRequest, "idempotency key is too long")
+				return
+			}
+
+			userID := int32(GetUserIDFromContext(r.Context()))
+			if userID == 0 {
+				utils.JSONErrorResponse(w, http.StatusUnauthorized, "user not authenticated")
+				return
+			}
+
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				utils.JSONErrorResponse(w, http.StatusBadRequest, "invalid request body")
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+			hash := requestHash(r, body)
+
+			log := logger.WithFields(utils.LogFields{"user_id": userID, "key": key})
+
+			// Запись ответа не должна прерываться, если клиент уже отключился.
+			ctx := context.WithoutCancel(r.Context())
+
+			reserved, err := repo.Reserve(ctx, userID, key, hash, time.Now().Add(-window))
+			if err != nil {
+				utils.JSONErrorResponse(w, http.StatusInternalServerError, "internal error")
+				return
+			}
+			if !reserved {
+				replay(ctx, w, repo, userID, key, hash, log)
+				return
+			}
+
+			// Без освобождения ключ после паники оставался бы «в процессе»
+			// до конца окна, и повтор запроса получал бы 409.
+			defer func() {
+				if p := recover(); p != nil {
+					log.WithFields(utils.LogFields{"panic": p}).Error("Handler panicked, releasing idempotency key")
+					_ = repo.Release(ctx, userID, key)
+					panic(p)
+				}
+			}()
+
+			rec := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(rec, r)
+
+			if rec.status >= http.StatusInternalServerError {
+				_ = repo.Release(ctx, userID, key)
+				return
+			}
+			if err := repo.SaveResponse(ctx, userID, key, rec.status, rec.body.Bytes()); err != nil {
+				log.WithFields(utils.LogFields{"error": err}).Error("Failed to store idempotent response")
+			}
+		})
+	}
+}
+
+// replay отдаёт сохранённый ответ на повторный запрос.
+func replay(ctx context.Context, w http.ResponseWriter, repo repository.IdempotencyRepository, userID int32, key, hash string, log utils.Logger) {
+	record, err := repo.Get(ctx, userID, key)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		// Исходный запрос завершился ошибкой и освободил ключ между проверками.
+		utils.JSONErrorResponse(w, http.StatusConflict, "request with this idempotency key is in progress, retry later")
+		return
+	case err != nil:
+		utils.JSONErrorResponse(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+
+	if record.RequestHash != hash {
+		log.Warn("Idempotency key reused with a different request")
+		utils.JSONErrorResponse(w, http.StatusUnprocessableEntity, "idempotency key was already used for a different request")
+		return
+	}
+	if !record.Completed {
+		utils.JSONErrorResponse(w, http.StatusConflict, "request with this idempotency key is in progress, retry later")
+		return
+	}
+
+	log.Info("Replaying stored response")
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set(IdempotentReplayHeader, "true")
+	w.WriteHeader(record.Status)
+	_, _ = w.Write(record.Body)
+}
+
+// requestHash связывает ключ с конкретным запросом: метод, путь с параметрами и тело.
+func requestHash(r *http.Request, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(r.Method + " " + r.URL.RequestURI() + "\n"))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// responseRecorder пропускает ответ клиенту и одновременно запоминает его.
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
@@ -0,0 +1,259 @@
+package middleware_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/par1ram/merch-store/internal/middleware"
+	"github.com/par1ram/merch-store/internal/repository"
+	"github.com/par1ram/merch-store/internal/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockIdempotencyRepository struct {
+	mock.Mock
+}
+
+func (m *MockIdempotencyRepository) Reserve(ctx context.Context, employeeID int32, key, requestHash string, expiredBefore time.Time) (bool, error) {
+	args := m.Called(ctx, employeeID, key, requestHash, expiredBefore)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockIdempotencyRepository) Get(ctx context.Context, employeeID int32, key string) (repository.IdempotencyRecord, error) {
+	args := m.Called(ctx, employeeID, key)
+	return args.Get(0).(repository.IdempotencyRecord), args.Error(1)
+}
+
+func (m *MockIdempotencyRepository) SaveResponse(ctx context.Context, employeeID int32, key string, status int, body []byte) error {
+	args := m.Called(ctx, employeeID, key, status, body)
+	return args.Error(0)
+}
+
+func (m *MockIdempotencyRepository) Release(ctx context.Context, employeeID int32, key string) error {
+	args := m.Called(ctx, employeeID, key)
+	return args.Error(0)
+}
+
+func (m *MockIdempotencyRepository) PurgeExpired(ctx context.Context, expiredBefore time.Time) (int64, error) {
+	args := m.Called(ctx, expiredBefore)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+// countingHandler считает вызовы и отвечает заданным статусом.
+type countingHandler struct {
+	calls  int
+	status int
+}
+
+func (h *countingHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.calls++
+	w.WriteHeader(h.status)
+	_, _ = w.Write([]byte(`{"status":"success"}`))
+}
+
+func idempotentRequest(key, body string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/api/send-coin", strings.NewReader(body))
+	if key != "" {
+		req.Header.Set(middleware.IdempotencyKeyHeader, key)
+	}
+	claims := jwt.MapClaims{"user_id": float64(7)}
+	return req.WithContext(context.WithValue(req.Context(), middleware.UserCtxKey, claims))
+}
+
+func TestIdempotency_NoKeyPassesThrough(t *testing.T) {
+	repo := new(MockIdempotencyRepository)
+	next := &countingHandler{status: http.StatusOK}
+	handler := middleware.Idempotency(repo, time.Hour, utils.NewLogger())(next)
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, idempotentRequest("", `{"to_user":"bob","amount":10}`))
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, 1, next.calls)
+	repo.AssertNotCalled(t, "Reserve", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestIdempotency_StoresFirstResponse(t *testing.T) {
+	repo := new(MockIdempotencyRepository)
+	repo.On("Reserve", mock.Anything, int32(7), "key-1", mock.AnythingOfType("string"), mock.AnythingOfType("time.Time")).
+		Return(true, nil).Once()
+	repo.On("SaveResponse", mock.Anything, int32(7), "key-1", http.StatusOK, []byte(`{"status":"success"}`)).
+		Return(nil).Once()
+
+	next := &countingHandler{status: http.StatusOK}
+	handler := middleware.Idempotency(repo, time.Hour, utils.NewLogger())(next)
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, idempotentRequest("key-1", `{"to_user":"bob","amount":10}`))
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, 1, next.calls)
+	repo.AssertExpectations(t)
+}
+
+func TestIdempotency_ReplaysStoredResponse(t *testing.T) {
+	body := `{"to_user":"bob","amount":10}`
+
+	// Сохраняем хеш первого запроса, чтобы вернуть его при повторе.
+	var storedHash string
+	repo := new(MockIdempotencyRepository)
+	repo.On("Reserve", mock.Anything, int32(7), "key-1", mock.AnythingOfType("string"), mock.Anything).
+		Run(func(args mock.Arguments) { storedHash = args.String(3) }).
+		Return(true, nil).Once()
+	repo.On("SaveResponse", mock.Anything, int32(7), "key-1", http.StatusOK, mock.Anything).Return(nil).Once()
+
+	next := &countingHandler{status: http.StatusOK}
+	handler := middleware.Idempotency(repo, time.Hour, utils.NewLogger())(next)
+	handler.ServeHTTP(httptest.NewRecorder(), idempotentRequest("key-1", body))
+
+	repo.On("Reserve", mock.Anything, int32(7), "key-1", mock.AnythingOfType("string"), mock.Anything).
+		Return(false, nil).Once()
+	repo.On("Get", mock.Anything, int32(7), "key-1").
+		Return(repository.IdempotencyRecord{
+			RequestHash: storedHash,
+			Completed:   true,
+			Status:      http.StatusOK,
+			Body:        []byte(`{"status":"success"}`),
+		}, nil).Once()
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, idempotentRequest("key-1", body))
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, `{"status":"success"}`, rr.Body.String())
+	assert.Equal(t, "true", rr.Header().Get(middleware.IdempotentReplayHeader))
+	// Обработчик вызван только один раз: повтор не создаёт второй перевод.
+	assert.Equal(t, 1, next.calls)
+	repo.AssertExpectations(t)
+}
+
+func TestIdempotency_KeyReuseWithDifferentRequest(t *testing.T) {
+	repo := new(MockIdempotencyRepository)
+	repo.On("Reserve", mock.Anything, int32(7), "key-1", mock.Anything, mock.Anything).Return(false, nil).Once()
+	repo.On("Get", mock.Anything, int32(7), "key-1").
+		Return(repository.IdempotencyRecord{RequestHash: "other", Completed: true, Status: http.StatusOK}, nil).Once()
+
+	next := &countingHandler{status: http.StatusOK}
+	handler := middleware.Idempotency(repo, time.Hour, utils.NewLogger())(next)
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, idempotentRequest("key-1", `{"to_user":"bob","amount":999}`))
+
+	assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+	assert.Equal(t, 0, next.calls)
+	repo.AssertExpectations(t)
+}
+
+func TestIdempotency_RequestInProgress(t *testing.T) {
+	repo := new(MockIdempotencyRepository)
+	var hash string
+	repo.On("Reserve", mock.Anything, int32(7), "key-1", mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) { hash = args.String(3) }).
+		Return(false, nil).Once()
+	// Запись с тем же хешем, но без сохранённого ответа.
+	getCall := repo.On("Get", mock.Anything, int32(7), "key-1").Once()
+	getCall.Run(func(mock.Arguments) {
+		getCall.ReturnArguments = mock.Arguments{repository.IdempotencyRecord{RequestHash: hash}, nil}
+	})
+
+	next := &countingHandler{status: http.StatusOK}
+	handler := middleware.Idempotency(repo, time.Hour, utils.NewLogger())(next)
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, idempotentRequest("key-1", `{}`))
+
+	assert.Equal(t, http.StatusConflict, rr.Code)
+	assert.Equal(t, 0, next.calls)
+}
+
+func TestIdempotency_ServerErrorReleasesKey(t *testing.T) {
+	repo := new(MockIdempotencyRepository)
+	repo.On("Reserve", mock.Anything, int32(7), "key-1", mock.Anything, mock.Anything).Return(true, nil).Once()
+	repo.On("Release", mock.Anything, int32(7), "key-1").Return(nil).Once()
+
+	next := &countingHandler{status: http.StatusInternalServerError}
+	handler := middleware.Idempotency(repo, time.Hour, utils.NewLogger())(next)
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, idempotentRequest("key-1", `{}`))
+
+	assert.Equal(t, http.StatusInternalServerError, rr.Code)
+	repo.AssertExpectations(t)
+	repo.AssertNotCalled(t, "SaveResponse", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestIdempotency_PanicReleasesKey(t *testing.T) {
+	repo := new(MockIdempotencyRepository)
+	repo.On("Reserve", mock.Anything, int32(7), "key-1", mock.Anything, mock.Anything).Return(true, nil).Once()
+	repo.On("Release", mock.Anything, int32(7), "key-1").Return(nil).Once()
+
+	next := http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		panic("boom")
+	})
+	handler := middleware.Idempotency(repo, time.Hour, utils.NewLogger())(next)
+
+	// Паника передаётся дальше, а ключ освобождается для повтора.
+	assert.PanicsWithValue(t, "boom", func() {
+		handler.ServeHTTP(httptest.NewRecorder(), idempotentRequest("key-1", `{}`))
+	})
+	repo.AssertExpectations(t)
+	repo.AssertNotCalled(t, "SaveResponse", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func buyRequest(key string) *http.Request {
+	req := httptest.NewRequest(http.MethodGet, "/api/buy/t-shirt", nil)
+	req.Header.Set(middleware.IdempotencyKeyHeader, key)
+	claims := jwt.MapClaims{"user_id": float64(7)}
+	return req.WithContext(context.WithValue(req.Context(), middleware.UserCtxKey, claims))
+}
+
+func TestIdempotency_GetPassesThrough(t *testing.T) {
+	repo := new(MockIdempotencyRepository)
+	next := &countingHandler{status: http.StatusOK}
+	handler := middleware.Idempotency(repo, time.Hour, utils.NewLogger())(next)
+
+	handler.ServeHTTP(httptest.NewRecorder(), buyRequest("key-1"))
+	handler.ServeHTTP(httptest.NewRecorder(), buyRequest("key-1"))
+
+	assert.Equal(t, 2, next.calls)
+	repo.AssertNotCalled(t, "Reserve", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestIdempotencyAnyMethod_ReplaysGetBuy(t *testing.T) {
+	var storedHash string
+	repo := new(MockIdempotencyRepository)
+	repo.On("Reserve", mock.Anything, int32(7), "buy-1", mock.AnythingOfType("string"), mock.Anything).
+		Run(func(args mock.Arguments) { storedHash = args.String(3) }).
+		Return(true, nil).Once()
+	repo.On("SaveResponse", mock.Anything, int32(7), "buy-1", http.StatusOK, []byte(`{"status":"success"}`)).
+		Return(nil).Once()
+
+	next := &countingHandler{status: http.StatusOK}
+	handler := middleware.IdempotencyAnyMethod(repo, time.Hour, utils.NewLogger())(next)
+	handler.ServeHTTP(httptest.NewRecorder(), buyRequest("buy-1"))
+
+	repo.On("Reserve", mock.Anything, int32(7), "buy-1", mock.AnythingOfType("string"), mock.Anything).
+		Return(false, nil).Once()
+	repo.On("Get", mock.Anything, int32(7), "buy-1").
+		Return(repository.IdempotencyRecord{
+			RequestHash: storedHash,
+			Completed:   true,
+			Status:      http.StatusOK,
+			Body:        []byte(`{"status":"success"}`),
+		}, nil).Once()
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, buyRequest("buy-1"))
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "true", rr.Header().Get(middleware.IdempotentReplayHeader))
+	// Повтор покупки не списывает монеты второй раз.
+	assert.Equal(t, 1, next.calls)
+	repo.AssertExpectations(t)
+}
@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/par1ram/merch-store/internal/db"
+	"github.com/par1ram/merch-store/internal/utils"
+)
+
+// AllowanceRepository начисляет ежемесячное пособие. Каждая порция
+// начисляется одним запросом в транзакции TxRunner: при взаимной блокировке
+// или сбое сериализации порция повторяется.
+type AllowanceRepository interface {
+	AccrueBatch(ctx context.Context, period time.Time, amount, batchSize int32) ([]int32, error)
+	ListRecipients(ctx context.Context, period time.Time) ([]db.ListAllowanceRecipientsRow, error)
+}
+
+type allowanceRepository struct {
+	tx      TxRunner
+	queries *db.Queries
+	logger  utils.Logger
+}
+
+// NewAllowanceRepository создаёт репозиторий начисления пособий.
+func NewAllowanceRepository(tx TxRunner, queries *db.Queries, logger utils.Logger) AllowanceRepository {
+	logger.WithFields(utils.LogFields{"component": "allowance_repository"}).Info("AllowanceRepository initialized")
+	return &allowanceRepository{
+		tx:      tx,
+		queries: queries,
+		logger:  logger.WithFields(utils.LogFields{"component": "allowance_repository"}),
+	}
+}
+
+// AccrueBatch начисляет пособие за период не более чем batchSize сотрудникам
+// и возвращает их id. Пустой результат означает, что период закрыт.
+func (r *allowanceRepository) AccrueBatch(ctx context.Context, period time.Time, amount, batchSize int32) ([]int32, error) {
+	log := r.logger.WithFields(utils.LogFields{
+		"operation":  "accrue_allowance_batch",
+		"period":     period.Format("2006-01"),
+		"amount":     amount,
+		"batch_size": batchSize,
+	})
+
+	var ids []int32
+	err := r.tx.Run(ctx, func(tx pgx.Tx) error {
+		var err error
+		ids, err = r.queries.WithTx(tx).AccrueAllowanceBatch(ctx, db.AccrueAllowanceBatchParams{
+			Amount:    amount,
+			Period:    pgtype.Date{Time: period, Valid: true},
+			BatchSize: batchSize,
+		})
+		return err
+	})
+	if err != nil {
+		log.WithFields(utils.LogFields{"error": err}).Error("Failed to accrue allowance batch")
+		return nil, fmt.Errorf("accrue allowance batch failed: %w", err)
+	}
+
+	log.WithFields(utils.LogFields{"credited": len(ids)}).Debug("Allowance batch accrued")
+	return ids, nil
+}
+
+func (r *allowanceRepository) ListRecipients(ctx context.Context, period time.Time) ([]db.ListAllowanceRecipientsRow, error) {
+	rows, err := r.queries.ListAllowanceRecipients(ctx, pgtype.Date{Time: period, Valid: true})
+	if err != nil {
+		r.logger.WithFields(utils.LogFields{"error": err, "period": period.Format("2006-01")}).Error("Failed to list allowance recipients")
+		return nil, err
+	}
+	return rows, nil
+}
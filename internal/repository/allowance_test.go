@@ -0,0 +1,81 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/par1ram/merch-store/internal/db"
+	"github.com/par1ram/merch-store/internal/repository"
+	"github.com/par1ram/merch-store/internal/utils"
+)
+
+func TestAllowanceRepository_AccrueBatch(t *testing.T) {
+	mockPool, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mockPool.Close()
+
+	repo := repository.NewAllowanceRepository(newTxRunner(mockPool), db.New(mockPool), utils.NewLogger())
+	period := time.Date(2026, time.October, 1, 0, 0, 0, 0, time.UTC)
+
+	mockPool.ExpectBegin()
+	mockPool.ExpectQuery(`(?s)WITH batch AS.*ORDER BY e.id.*LIMIT \$3::integer.*FOR UPDATE OF e.*ON CONFLICT \(to_employee_id, period\) WHERE transaction_type = 'allowance' DO NOTHING.*UPDATE employees e.*RETURNING e.id`).
+		WithArgs(int32(100), pgtype.Date{Time: period, Valid: true}, int32(2)).
+		WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(int32(1)).AddRow(int32(2)))
+	mockPool.ExpectCommit()
+
+	ids, err := repo.AccrueBatch(context.Background(), period, 100, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []int32{1, 2}, ids)
+
+	assert.NoError(t, mockPool.ExpectationsWereMet())
+}
+
+func TestAllowanceRepository_AccrueBatch_RetriesDeadlock(t *testing.T) {
+	mockPool, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mockPool.Close()
+
+	repo := repository.NewAllowanceRepository(newTxRunner(mockPool), db.New(mockPool), utils.NewLogger())
+	period := time.Date(2026, time.October, 1, 0, 0, 0, 0, time.UTC)
+	query := `(?s)WITH batch AS.*FOR UPDATE OF e`
+	args := []interface{}{int32(100), pgtype.Date{Time: period, Valid: true}, int32(2)}
+
+	mockPool.ExpectBegin()
+	mockPool.ExpectQuery(query).WithArgs(args...).WillReturnError(&pgconn.PgError{Code: "40P01"})
+	mockPool.ExpectRollback()
+	mockPool.ExpectBegin()
+	mockPool.ExpectQuery(query).WithArgs(args...).
+		WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(int32(1)))
+	mockPool.ExpectCommit()
+
+	ids, err := repo.AccrueBatch(context.Background(), period, 100, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []int32{1}, ids)
+
+	assert.NoError(t, mockPool.ExpectationsWereMet())
+}
+
+func TestAllowanceRepository_ListRecipients(t *testing.T) {
+	mockPool, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mockPool.Close()
+
+	repo := repository.NewAllowanceRepository(newTxRunner(mockPool), db.New(mockPool), utils.NewLogger())
+	period := time.Date(2026, time.October, 1, 0, 0, 0, 0, time.UTC)
+
+	mockPool.ExpectQuery(`(?s)SELECT e.id, e.username.*FROM employees e.*ct.period = \$1::date`).
+		WithArgs(pgtype.Date{Time: period, Valid: true}).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "username"}).AddRow(int32(3), "carol"))
+
+	rows, err := repo.ListRecipients(context.Background(), period)
+	assert.NoError(t, err)
+	assert.Equal(t, []db.ListAllowanceRecipientsRow{{ID: 3, Username: "carol"}}, rows)
+
+	assert.NoError(t, mockPool.ExpectationsWereMet())
+}
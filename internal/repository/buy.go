@@ -4,64 +4,53 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/par1ram/merch-store/internal/db"
 	"github.com/par1ram/merch-store/internal/utils"
 )
 
+// BuyRepository выполняет покупки. LockBalance блокирует строку покупателя
+// до конца транзакции, поэтому проверка баланса и списание не разделены гонкой.
+// Части партий, израсходованные DeductCoins, запоминаются за покупками, которые
+// CreatePurchaseTransaction записывает в той же транзакции, в порядке записи.
 type BuyRepository interface {
 	ExecTx(ctx context.Context, fn func(BuyRepository) error) error
 	GetMerch(ctx context.Context, merchName string) (db.Merch, error)
-	GetBalance(ctx context.Context, userID int32) (int32, error)
+	LockBalance(ctx context.Context, userID int32) (int32, error)
 	DeductCoins(ctx context.Context, userID, amount int32) (int64, error)
+	DecrementStock(ctx context.Context, merchID, quantity int32) (int64, error)
 	UpsertInventory(ctx context.Context, params db.UpsertInventoryParams) error
-	CreatePurchaseTransaction(ctx context.Context, params db.CreateCoinTransactionPurchaseParams) error
+	CreatePurchaseTransaction(ctx context.Context, params db.CreateCoinTransactionPurchaseParams) (int32, error)
+	CreateOrder(ctx context.Context, params db.CreateOrderParams) error
+	ListCartItems(ctx context.Context, employeeID int32) ([]db.ListCartItemsRow, error)
+	ClearCart(ctx context.Context, employeeID int32) error
 }
 
 type buyRepository struct {
-	// Изменяем тип поля pool на наш интерфейс:
-	pool    PoolIface
+	tx      TxRunner
 	queries *db.Queries
 	logger  utils.Logger
+	// consumed — части партий, списанные в транзакции и ещё не отнесённые к покупке.
+	consumed []db.ConsumeCoinLotsRow
 }
 
-func NewBuyRepository(pool PoolIface, queries *db.Queries, logger utils.Logger) BuyRepository {
+func NewBuyRepository(tx TxRunner, queries *db.Queries, logger utils.Logger) BuyRepository {
 	logger.WithFields(utils.LogFields{"component": "buy_repository"}).Info("BuyRepository initialized")
 	return &buyRepository{
-		pool:    pool,
+		tx:      tx,
 		queries: queries,
 		logger:  logger,
 	}
 }
 
 func (r *buyRepository) ExecTx(ctx context.Context, fn func(BuyRepository) error) error {
-	r.logger.WithFields(utils.LogFields{"operation": "exec_tx"}).Info("Starting transaction")
-	tx, err := r.pool.Begin(ctx)
-	if err != nil {
-		r.logger.WithFields(utils.LogFields{"error": err}).Error("Transaction begin failed")
-		return fmt.Errorf("transaction start failed: %w", err)
-	}
-	defer tx.Rollback(ctx)
-
-	// Получаем новый экземпляр queries, ассоциированный с транзакцией.
-	qtx := r.queries.WithTx(tx)
-	txRepo := &buyRepository{
-		pool:    r.pool, // пул остаётся тем же
-		queries: qtx,
-		logger:  r.logger,
-	}
-
-	if err := fn(txRepo); err != nil {
-		r.logger.WithFields(utils.LogFields{"error": err}).Error("Transaction operation failed")
-		return err
-	}
-
-	if err := tx.Commit(ctx); err != nil {
-		r.logger.WithFields(utils.LogFields{"error": err}).Error("Transaction commit failed")
-		return fmt.Errorf("transaction commit failed: %w", err)
-	}
-
-	r.logger.Info("Transaction committed")
-	return nil
+	return r.tx.Run(ctx, func(tx pgx.Tx) error {
+		return fn(&buyRepository{
+			tx:      r.tx,
+			queries: r.queries.WithTx(tx),
+			logger:  r.logger,
+		})
+	})
 }
 
 func (r *buyRepository) GetMerch(ctx context.Context, merchName string) (db.Merch, error) {
@@ -78,26 +67,49 @@ func (r *buyRepository) GetMerch(ctx context.Context, merchName string) (db.Merc
 	return merch, nil
 }
 
-func (r *buyRepository) GetBalance(ctx context.Context, userID int32) (int32, error) {
-	balance, err := r.queries.GetCoinsByID(ctx, userID)
+// LockBalance возвращает баланс сотрудника и блокирует его строку до конца транзакции.
+func (r *buyRepository) LockBalance(ctx context.Context, userID int32) (int32, error) {
+	balance, err := r.queries.LockEmployeeCoins(ctx, userID)
 	if err != nil {
-		r.logger.WithFields(utils.LogFields{"error": err, "userID": userID}).Error("Failed to get balance")
-		return 0, fmt.Errorf("get balance failed: %w", err)
+		r.logger.WithFields(utils.LogFields{"error": err, "userID": userID}).Error("Failed to lock balance")
+		return 0, fmt.Errorf("lock balance failed: %w", err)
 	}
-	r.logger.WithFields(utils.LogFields{"userID": userID, "balance": balance}).Debug("Balance retrieved")
+	r.logger.WithFields(utils.LogFields{"userID": userID, "balance": balance}).Debug("Balance locked")
 	return balance, nil
 }
 
+// DeductCoins списывает монеты и возвращает число изменённых строк employees:
+// 0 означает, что списание сделало бы баланс отрицательным.
 func (r *buyRepository) DeductCoins(ctx context.Context, userID, amount int32) (int64, error) {
-	err := r.queries.UpdateEmployeeCoins(ctx, db.UpdateEmployeeCoinsParams{
-		ID:    userID,
-		Coins: -amount,
-	})
+	affected, err := r.queries.UpdateEmployeeCoins(ctx, db.UpdateEmployeeCoinsParams{ID: userID, Coins: -amount})
+	if err != nil {
+		r.logger.WithFields(utils.LogFields{"error": err, "userID": userID, "amount": amount}).Error("Failed to deduct coins")
+		return 0, err
+	}
+	if affected == 0 {
+		return 0, nil
+	}
+	lots, err := consumeCoinLots(ctx, r.queries, r.logger, userID, amount)
 	if err != nil {
 		r.logger.WithFields(utils.LogFields{"error": err, "userID": userID, "amount": amount}).Error("Failed to deduct coins")
 		return 0, err
 	}
-	return 1, nil
+	r.consumed = append(r.consumed, lots...)
+	return affected, nil
+}
+
+// DecrementStock уменьшает остаток товара. Возвращает число изменённых строк:
+// 0 означает, что на складе недостаточно единиц товара.
+func (r *buyRepository) DecrementStock(ctx context.Context, merchID, quantity int32) (int64, error) {
+	affected, err := r.queries.DecrementMerchStock(ctx, db.DecrementMerchStockParams{
+		ID:       merchID,
+		Quantity: quantity,
+	})
+	if err != nil {
+		r.logger.WithFields(utils.LogFields{"error": err, "merchID": merchID, "quantity": quantity}).Error("Failed to decrement stock")
+		return 0, err
+	}
+	return affected, nil
 }
 
 func (r *buyRepository) UpsertInventory(ctx context.Context, params db.UpsertInventoryParams) error {
@@ -108,9 +120,51 @@ func (r *buyRepository) UpsertInventory(ctx context.Context, params db.UpsertInv
 	return nil
 }
 
-func (r *buyRepository) CreatePurchaseTransaction(ctx context.Context, params db.CreateCoinTransactionPurchaseParams) error {
-	if err := r.queries.CreateCoinTransactionPurchase(ctx, params); err != nil {
+// CreatePurchaseTransaction записывает покупку и возвращает её id. За покупкой
+// запоминаются части партий на её сумму, чтобы возврат восстановил их сроки.
+func (r *buyRepository) CreatePurchaseTransaction(ctx context.Context, params db.CreateCoinTransactionPurchaseParams) (int32, error) {
+	id, err := r.queries.CreateCoinTransactionPurchase(ctx, params)
+	if err != nil {
 		r.logger.WithFields(utils.LogFields{"error": err, "fromUserID": params.FromEmployeeID, "merchID": params.MerchID}).Error("Failed to create purchase transaction")
+		return 0, err
+	}
+
+	var lots []db.ConsumeCoinLotsRow
+	lots, r.consumed = takeLots(r.consumed, params.Amount)
+	for _, lot := range lots {
+		if err := r.queries.CreatePurchaseLot(ctx, db.CreatePurchaseLotParams{
+			PurchaseID: id,
+			Amount:     lot.Taken,
+			GrantedAt:  lot.GrantedAt,
+			ExpiresAt:  lot.ExpiresAt,
+		}); err != nil {
+			r.logger.WithFields(utils.LogFields{"error": err, "purchase_id": id}).Error("Failed to record purchase lot")
+			return 0, fmt.Errorf("record purchase lot failed: %w", err)
+		}
+	}
+	return id, nil
+}
+
+func (r *buyRepository) CreateOrder(ctx context.Context, params db.CreateOrderParams) error {
+	if err := r.queries.CreateOrder(ctx, params); err != nil {
+		r.logger.WithFields(utils.LogFields{"error": err, "purchase_id": params.PurchaseID}).Error("Failed to create order")
+		return err
+	}
+	return nil
+}
+
+func (r *buyRepository) ListCartItems(ctx context.Context, employeeID int32) ([]db.ListCartItemsRow, error) {
+	items, err := r.queries.ListCartItems(ctx, employeeID)
+	if err != nil {
+		r.logger.WithFields(utils.LogFields{"error": err, "employee_id": employeeID}).Error("Failed to list cart items")
+		return nil, err
+	}
+	return items, nil
+}
+
+func (r *buyRepository) ClearCart(ctx context.Context, employeeID int32) error {
+	if err := r.queries.ClearCart(ctx, employeeID); err != nil {
+		r.logger.WithFields(utils.LogFields{"error": err, "employee_id": employeeID}).Error("Failed to clear cart")
 		return err
 	}
 	return nil
@@ -4,6 +4,7 @@ import (
 	"context"
 	"regexp"
 	"testing"
+	"time"
 
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/pashagolub/pgxmock/v4"
@@ -26,16 +27,16 @@ func TestBuyRepository_GetMerch_Success(t *testing.T) {
 	queries := db.New(mockPool)
 
 	// Настраиваем ожидаемые строки, которые вернет запрос GetMerchByName.
-	rows := pgxmock.NewRows([]string{"id", "name", "price"}).
-		AddRow(int32(1), "T-Shirt", int32(100))
+	rows := pgxmock.NewRows([]string{"id", "name", "price", "active", "retired_at", "stock"}).
+		AddRow(int32(1), "T-Shirt", int32(100), true, pgtype.Timestamptz{}, pgtype.Int4{Int32: 40, Valid: true})
 
 	// Настраиваем ожидание запроса.
-	mockPool.ExpectQuery(regexp.QuoteMeta(`SELECT id, name, price FROM merch WHERE name = $1`)).
+	mockPool.ExpectQuery(regexp.QuoteMeta(`SELECT id, name, price, active, retired_at, stock FROM merch WHERE name = $1 AND active`)).
 		WithArgs("T-Shirt").
 		WillReturnRows(rows)
 
 	// Создаем репозиторий через публичный конструктор.
-	repo := repository.NewBuyRepository(mockPool, queries, utils.NewLogger())
+	repo := repository.NewBuyRepository(newTxRunner(mockPool), queries, utils.NewLogger())
 
 	// Вызываем тестируемый метод.
 	merch, err := repo.GetMerch(context.Background(), "T-Shirt")
@@ -43,6 +44,7 @@ func TestBuyRepository_GetMerch_Success(t *testing.T) {
 	assert.Equal(t, int32(1), merch.ID)
 	assert.Equal(t, "T-Shirt", merch.Name)
 	assert.Equal(t, int32(100), merch.Price)
+	assert.Equal(t, pgtype.Int4{Int32: 40, Valid: true}, merch.Stock)
 
 	// Проверяем, что все ожидания мокового пула выполнены.
 	err = mockPool.ExpectationsWereMet()
@@ -56,16 +58,16 @@ func TestBuyRepository_GetMerch_NotFound(t *testing.T) {
 
 	queries := db.New(mockPool)
 	logger := utils.NewLogger()
-	repo := repository.NewBuyRepository(mockPool, queries, logger)
+	repo := repository.NewBuyRepository(newTxRunner(mockPool), queries, logger)
 
 	ctx := context.Background()
 	item := "NonExistent"
 
 	// Настраиваем пустую выборку => sql.ErrNoRows.
 	mockPool.
-		ExpectQuery(regexp.QuoteMeta(`SELECT id, name, price FROM merch WHERE name = $1`)).
+		ExpectQuery(regexp.QuoteMeta(`SELECT id, name, price, active, retired_at, stock FROM merch WHERE name = $1 AND active`)).
 		WithArgs(item).
-		WillReturnRows(mockPool.NewRows([]string{"id", "name", "price"})) // без строк
+		WillReturnRows(mockPool.NewRows([]string{"id", "name", "price", "active", "retired_at"})) // без строк
 
 	merch, err := repo.GetMerch(ctx, item)
 	assert.Error(t, err)
@@ -82,13 +84,13 @@ func TestBuyRepository_GetMerch_Error(t *testing.T) {
 
 	queries := db.New(mockPool)
 	logger := utils.NewLogger()
-	repo := repository.NewBuyRepository(mockPool, queries, logger)
+	repo := repository.NewBuyRepository(newTxRunner(mockPool), queries, logger)
 
 	ctx := context.Background()
 	item := "AnyItem"
 
 	mockPool.
-		ExpectQuery(regexp.QuoteMeta(`SELECT id, name, price FROM merch WHERE name = $1`)).
+		ExpectQuery(regexp.QuoteMeta(`SELECT id, name, price, active, retired_at, stock FROM merch WHERE name = $1 AND active`)).
 		WithArgs(item).
 		WillReturnError(assert.AnError) // имитация ошибки
 
@@ -98,8 +100,8 @@ func TestBuyRepository_GetMerch_Error(t *testing.T) {
 	assert.NoError(t, mockPool.ExpectationsWereMet())
 }
 
-// 4. GetBalance: успешное получение баланса.
-func TestBuyRepository_GetBalance_Success(t *testing.T) {
+// 4. LockBalance: баланс читается с блокировкой строки.
+func TestBuyRepository_LockBalance_Success(t *testing.T) {
 	// Создаём моковый пул.
 	mockPool, err := pgxmock.NewPool()
 	assert.NoError(t, err)
@@ -107,7 +109,7 @@ func TestBuyRepository_GetBalance_Success(t *testing.T) {
 
 	queries := db.New(mockPool)
 	logger := utils.NewLogger()
-	repo := repository.NewBuyRepository(mockPool, queries, logger)
+	repo := repository.NewBuyRepository(newTxRunner(mockPool), queries, logger)
 
 	ctx := context.Background()
 	userID := int32(123)
@@ -115,21 +117,20 @@ func TestBuyRepository_GetBalance_Success(t *testing.T) {
 	// Возвращаем одну строку с coins=500
 	rows := mockPool.NewRows([]string{"coins"}).AddRow(int32(500))
 
-	// Используем "гибкую" регулярку с (?s).* и без пробелов, чтобы
-	// совпадало с sqlc-шным запросом: "-- name: GetCoinsByID :one\nSELECT coins FROM employees WHERE id=$1"
-	mockPool.ExpectQuery(`(?s).*SELECT coins FROM employees WHERE id=\$1.*`).
+	// Строка сотрудника блокируется до конца транзакции.
+	mockPool.ExpectQuery(`(?s)SELECT coins FROM employees WHERE id = \$1 FOR UPDATE`).
 		WithArgs(userID).
 		WillReturnRows(rows)
 
-	balance, err := repo.GetBalance(ctx, userID)
+	balance, err := repo.LockBalance(ctx, userID)
 	assert.NoError(t, err)
 	assert.Equal(t, int32(500), balance)
 
 	assert.NoError(t, mockPool.ExpectationsWereMet())
 }
 
-// 5. GetBalance: ошибка при запросе.
-func TestBuyRepository_GetBalance_Error(t *testing.T) {
+// 5. LockBalance: ошибка при запросе.
+func TestBuyRepository_LockBalance_Error(t *testing.T) {
 	// Создаём моковый пул.
 	mockPool, err := pgxmock.NewPool()
 	assert.NoError(t, err)
@@ -137,17 +138,17 @@ func TestBuyRepository_GetBalance_Error(t *testing.T) {
 
 	queries := db.New(mockPool)
 	logger := utils.NewLogger()
-	repo := repository.NewBuyRepository(mockPool, queries, logger)
+	repo := repository.NewBuyRepository(newTxRunner(mockPool), queries, logger)
 
 	ctx := context.Background()
 	userID := int32(999)
 
 	// Имитация ошибки, например, "no rows found" или любая другая:
-	mockPool.ExpectQuery(`(?s).*SELECT coins FROM employees WHERE id=\$1.*`).
+	mockPool.ExpectQuery(`(?s)SELECT coins FROM employees WHERE id = \$1 FOR UPDATE`).
 		WithArgs(userID).
 		WillReturnError(assert.AnError)
 
-	balance, err := repo.GetBalance(ctx, userID)
+	balance, err := repo.LockBalance(ctx, userID)
 	assert.Error(t, err)
 	assert.Equal(t, int32(0), balance)
 
@@ -155,8 +156,8 @@ func TestBuyRepository_GetBalance_Error(t *testing.T) {
 }
 
 // 6. DeductCoins: успешное списание.
-// По коду repo.DeductCoins просто вызывает UpdateEmployeeCoins(...),
-// и при успехе возвращает (1, nil). При ошибке — (0, err).
+// repo.DeductCoins возвращает число строк, изменённых UpdateEmployeeCoins(...).
+// При ошибке — (0, err).
 func TestBuyRepository_DeductCoins_Success(t *testing.T) {
 	mockPool, err := pgxmock.NewPool()
 	assert.NoError(t, err)
@@ -164,17 +165,21 @@ func TestBuyRepository_DeductCoins_Success(t *testing.T) {
 
 	queries := db.New(mockPool)
 	logger := utils.NewLogger()
-	repo := repository.NewBuyRepository(mockPool, queries, logger)
+	repo := repository.NewBuyRepository(newTxRunner(mockPool), queries, logger)
 
 	ctx := context.Background()
 	userID := int32(100)
 	amount := int32(50)
 
-	// Ожидаем exec UpdateEmployeeCoins, где coins = -amount
+	// Сначала UpdateEmployeeCoins, где coins = -amount, затем расходуются партии монет
 	mockPool.
 		ExpectExec(regexp.QuoteMeta(`UPDATE employees SET coins = coins + $2 WHERE id = $1 AND coins + $2 >= 0`)).
 		WithArgs(userID, -amount).
 		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mockPool.ExpectQuery(`(?s)WITH locked AS.*FROM coin_lots.*FOR UPDATE.*UPDATE coin_lots l`).
+		WithArgs(userID, amount).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "taken", "granted_at", "expires_at"}).
+			AddRow(int32(1), amount, pgtype.Timestamptz{}, pgtype.Timestamptz{}))
 
 	affected, err := repo.DeductCoins(ctx, userID, amount)
 	assert.NoError(t, err)
@@ -183,6 +188,55 @@ func TestBuyRepository_DeductCoins_Success(t *testing.T) {
 	assert.NoError(t, mockPool.ExpectationsWereMet())
 }
 
+// DecrementStock: атомарное уменьшение остатка.
+// 0 затронутых строк означает, что товар закончился.
+func TestBuyRepository_DeductCoins_InsufficientBalance(t *testing.T) {
+	mockPool, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mockPool.Close()
+
+	repo := repository.NewBuyRepository(newTxRunner(mockPool), db.New(mockPool), utils.NewLogger())
+
+	// Баланса не хватает: строка не изменилась, партии не трогаются.
+	mockPool.
+		ExpectExec(regexp.QuoteMeta(`UPDATE employees SET coins = coins + $2 WHERE id = $1 AND coins + $2 >= 0`)).
+		WithArgs(int32(100), int32(-500)).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+
+	affected, err := repo.DeductCoins(context.Background(), 100, 500)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), affected)
+
+	assert.NoError(t, mockPool.ExpectationsWereMet())
+}
+
+func TestBuyRepository_DecrementStock(t *testing.T) {
+	mockPool, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mockPool.Close()
+
+	queries := db.New(mockPool)
+	repo := repository.NewBuyRepository(newTxRunner(mockPool), queries, utils.NewLogger())
+
+	stockQuery := `UPDATE merch SET stock = stock - \$1::integer WHERE id = \$2 AND \(stock IS NULL OR stock >= \$1::integer\)`
+	mockPool.ExpectExec(stockQuery).
+		WithArgs(int32(1), int32(6)).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mockPool.ExpectExec(stockQuery).
+		WithArgs(int32(1), int32(6)).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+
+	affected, err := repo.DecrementStock(context.Background(), 6, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), affected)
+
+	affected, err = repo.DecrementStock(context.Background(), 6, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), affected)
+
+	assert.NoError(t, mockPool.ExpectationsWereMet())
+}
+
 // 7. DeductCoins: ошибка при списании.
 func TestBuyRepository_DeductCoins_Error(t *testing.T) {
 	mockPool, err := pgxmock.NewPool()
@@ -191,7 +245,7 @@ func TestBuyRepository_DeductCoins_Error(t *testing.T) {
 
 	queries := db.New(mockPool)
 	logger := utils.NewLogger()
-	repo := repository.NewBuyRepository(mockPool, queries, logger)
+	repo := repository.NewBuyRepository(newTxRunner(mockPool), queries, logger)
 
 	ctx := context.Background()
 	userID := int32(100)
@@ -217,7 +271,7 @@ func TestBuyRepository_UpsertInventory_Success(t *testing.T) {
 
 	queries := db.New(mockPool)
 	logger := utils.NewLogger()
-	repo := repository.NewBuyRepository(mockPool, queries, logger)
+	repo := repository.NewBuyRepository(newTxRunner(mockPool), queries, logger)
 
 	ctx := context.Background()
 	params := db.UpsertInventoryParams{
@@ -249,7 +303,7 @@ func TestBuyRepository_UpsertInventory_Error(t *testing.T) {
 
 	queries := db.New(mockPool)
 	logger := utils.NewLogger()
-	repo := repository.NewBuyRepository(mockPool, queries, logger)
+	repo := repository.NewBuyRepository(newTxRunner(mockPool), queries, logger)
 
 	ctx := context.Background()
 	params := db.UpsertInventoryParams{
@@ -278,25 +332,29 @@ func TestBuyRepository_CreatePurchaseTransaction_Success(t *testing.T) {
 
 	queries := db.New(mockPool)
 	logger := utils.NewLogger()
-	repo := repository.NewBuyRepository(mockPool, queries, logger)
+	repo := repository.NewBuyRepository(newTxRunner(mockPool), queries, logger)
 
 	ctx := context.Background()
 	params := db.CreateCoinTransactionPurchaseParams{
 		FromEmployeeID: 100,
 		MerchID:        pgtype.Int4{Int32: 1, Valid: true},
-		Amount:         50,
+		Amount:         150,
+		Quantity:       3,
+		UnitPrice:      pgtype.Int4{Int32: 50, Valid: true},
 	}
 
 	// sqlc генерирует что-то вроде:
-	// INSERT INTO coin_transactions (transaction_type, from_employee_id, merch_id, amount)
-	// VALUES ('purchase', $1, $2, $3)
+	// INSERT INTO coin_transactions (transaction_type, from_employee_id, merch_id, amount, quantity, unit_price)
+	// VALUES ('purchase', $1::integer, $2, $3, $4, $5)
+	// RETURNING id
 	mockPool.
-		ExpectExec(regexp.QuoteMeta(`INSERT INTO coin_transactions (transaction_type, from_employee_id, merch_id, amount) VALUES ('purchase', $1, $2, $3)`)).
-		WithArgs(params.FromEmployeeID, params.MerchID, params.Amount).
-		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+		ExpectQuery(regexp.QuoteMeta(`INSERT INTO coin_transactions (transaction_type, from_employee_id, merch_id, amount, quantity, unit_price) VALUES ('purchase', $1::integer, $2, $3, $4, $5) RETURNING id`)).
+		WithArgs(params.FromEmployeeID, params.MerchID, params.Amount, params.Quantity, params.UnitPrice).
+		WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(int32(42)))
 
-	err = repo.CreatePurchaseTransaction(ctx, params)
+	id, err := repo.CreatePurchaseTransaction(ctx, params)
 	assert.NoError(t, err)
+	assert.Equal(t, int32(42), id)
 
 	assert.NoError(t, mockPool.ExpectationsWereMet())
 }
@@ -309,7 +367,7 @@ func TestBuyRepository_CreatePurchaseTransaction_Error(t *testing.T) {
 
 	queries := db.New(mockPool)
 	logger := utils.NewLogger()
-	repo := repository.NewBuyRepository(mockPool, queries, logger)
+	repo := repository.NewBuyRepository(newTxRunner(mockPool), queries, logger)
 
 	ctx := context.Background()
 	params := db.CreateCoinTransactionPurchaseParams{
@@ -319,12 +377,95 @@ func TestBuyRepository_CreatePurchaseTransaction_Error(t *testing.T) {
 	}
 
 	mockPool.
-		ExpectExec(regexp.QuoteMeta(`INSERT INTO coin_transactions (transaction_type, from_employee_id, merch_id, amount) VALUES ('purchase', $1, $2, $3)`)).
-		WithArgs(params.FromEmployeeID, params.MerchID, params.Amount).
+		ExpectQuery(regexp.QuoteMeta(`INSERT INTO coin_transactions (transaction_type, from_employee_id, merch_id, amount, quantity, unit_price) VALUES ('purchase', $1::integer, $2, $3, $4, $5) RETURNING id`)).
+		WithArgs(params.FromEmployeeID, params.MerchID, params.Amount, params.Quantity, params.UnitPrice).
 		WillReturnError(assert.AnError)
 
-	err = repo.CreatePurchaseTransaction(ctx, params)
+	_, err = repo.CreatePurchaseTransaction(ctx, params)
 	assert.Error(t, err)
 
 	assert.NoError(t, mockPool.ExpectationsWereMet())
 }
+
+// Части партий, израсходованные при оформлении корзины, делятся между
+// покупками в порядке их записи.
+func TestBuyRepository_CreatePurchaseTransaction_RecordsLots(t *testing.T) {
+	mockPool, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mockPool.Close()
+
+	repo := repository.NewBuyRepository(newTxRunner(mockPool), db.New(mockPool), utils.NewLogger())
+
+	early := pgtype.Timestamptz{Time: time.Date(2026, time.March, 1, 0, 0, 0, 0, time.UTC), Valid: true}
+	late := pgtype.Timestamptz{Time: time.Date(2026, time.June, 1, 0, 0, 0, 0, time.UTC), Valid: true}
+	purchaseQuery := regexp.QuoteMeta(`INSERT INTO coin_transactions (transaction_type, from_employee_id, merch_id, amount, quantity, unit_price) VALUES ('purchase', $1::integer, $2, $3, $4, $5) RETURNING id`)
+	lotQuery := `(?s)INSERT INTO purchase_lots \(purchase_id, amount, remaining, granted_at, expires_at\)`
+
+	mockPool.ExpectBegin()
+	mockPool.
+		ExpectExec(regexp.QuoteMeta(`UPDATE employees SET coins = coins + $2 WHERE id = $1 AND coins + $2 >= 0`)).
+		WithArgs(int32(100), int32(-80)).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mockPool.ExpectQuery(`(?s)WITH locked AS.*FROM coin_lots.*FOR UPDATE.*UPDATE coin_lots l`).
+		WithArgs(int32(100), int32(80)).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "taken", "granted_at", "expires_at"}).
+			AddRow(int32(1), int32(30), early, early).
+			AddRow(int32(2), int32(50), late, late))
+	mockPool.ExpectQuery(purchaseQuery).
+		WithArgs(int32(100), pgtype.Int4{Int32: 1, Valid: true}, int32(50), int32(1), pgtype.Int4{Int32: 50, Valid: true}).
+		WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(int32(41)))
+	mockPool.ExpectExec(lotQuery).WithArgs(int32(41), int32(30), early, early).WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	mockPool.ExpectExec(lotQuery).WithArgs(int32(41), int32(20), late, late).WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	mockPool.ExpectQuery(purchaseQuery).
+		WithArgs(int32(100), pgtype.Int4{Int32: 2, Valid: true}, int32(30), int32(1), pgtype.Int4{Int32: 30, Valid: true}).
+		WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(int32(42)))
+	mockPool.ExpectExec(lotQuery).WithArgs(int32(42), int32(30), late, late).WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	mockPool.ExpectCommit()
+
+	err = repo.ExecTx(context.Background(), func(r repository.BuyRepository) error {
+		if _, err := r.DeductCoins(context.Background(), 100, 80); err != nil {
+			return err
+		}
+		for _, item := range []struct{ merchID, price int32 }{{1, 50}, {2, 30}} {
+			if _, err := r.CreatePurchaseTransaction(context.Background(), db.CreateCoinTransactionPurchaseParams{
+				FromEmployeeID: 100,
+				MerchID:        pgtype.Int4{Int32: item.merchID, Valid: true},
+				Amount:         item.price,
+				Quantity:       1,
+				UnitPrice:      pgtype.Int4{Int32: item.price, Valid: true},
+			}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+
+	assert.NoError(t, mockPool.ExpectationsWereMet())
+}
+
+// 12. CreateOrder: успешное создание заказа на выдачу
+func TestBuyRepository_CreateOrder_Success(t *testing.T) {
+	mockPool, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mockPool.Close()
+
+	queries := db.New(mockPool)
+	repo := repository.NewBuyRepository(newTxRunner(mockPool), queries, utils.NewLogger())
+
+	params := db.CreateOrderParams{
+		PurchaseID: 42,
+		EmployeeID: 100,
+		MerchID:    1,
+		Quantity:   3,
+	}
+	mockPool.
+		ExpectExec(regexp.QuoteMeta(`INSERT INTO orders (purchase_id, employee_id, merch_id, quantity) VALUES ($1, $2, $3, $4)`)).
+		WithArgs(params.PurchaseID, params.EmployeeID, params.MerchID, params.Quantity).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+
+	err = repo.CreateOrder(context.Background(), params)
+	assert.NoError(t, err)
+
+	assert.NoError(t, mockPool.ExpectationsWereMet())
+}
@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/par1ram/merch-store/internal/db"
+	"github.com/par1ram/merch-store/internal/utils"
+)
+
+// CartRepository управляет содержимым корзины. Оформление заказа выполняется
+// через BuyRepository, чтобы все изменения попали в одну транзакцию.
+type CartRepository interface {
+	GetMerch(ctx context.Context, merchName string) (db.Merch, error)
+	AddItem(ctx context.Context, params db.AddCartItemParams) (int64, error)
+	RemoveItem(ctx context.Context, params db.RemoveCartItemParams) (int64, error)
+	ListItems(ctx context.Context, employeeID int32) ([]db.ListCartItemsRow, error)
+}
+
+type cartRepository struct {
+	queries *db.Queries
+	logger  utils.Logger
+}
+
+// NewCartRepository создаёт репозиторий корзины.
+func NewCartRepository(queries *db.Queries, logger utils.Logger) CartRepository {
+	logger.WithFields(utils.LogFields{"component": "cart_repository"}).Info("CartRepository initialized")
+	return &cartRepository{
+		queries: queries,
+		logger:  logger.WithFields(utils.LogFields{"component": "cart_repository"}),
+	}
+}
+
+func (r *cartRepository) GetMerch(ctx context.Context, merchName string) (db.Merch, error) {
+	merch, err := r.queries.GetMerchByName(ctx, merchName)
+	if err != nil {
+		r.logger.WithFields(utils.LogFields{"error": err, "merchName": merchName}).Error("Failed to get merch by name")
+		return merch, err
+	}
+	return merch, nil
+}
+
+// AddItem возвращает 0, если количество позиции превысило бы params.MaxQuantity.
+func (r *cartRepository) AddItem(ctx context.Context, params db.AddCartItemParams) (int64, error) {
+	log := r.logger.WithFields(utils.LogFields{
+		"operation":   "add_cart_item",
+		"employee_id": params.EmployeeID,
+		"merch_id":    params.MerchID,
+		"quantity":    params.Quantity,
+	})
+
+	affected, err := r.queries.AddCartItem(ctx, params)
+	if err != nil {
+		log.WithFields(utils.LogFields{"error": err}).Error("Failed to add cart item")
+		return 0, fmt.Errorf("add cart item failed: %w", err)
+	}
+
+	log.WithFields(utils.LogFields{"affected": affected}).Debug("Cart item added")
+	return affected, nil
+}
+
+func (r *cartRepository) RemoveItem(ctx context.Context, params db.RemoveCartItemParams) (int64, error) {
+	log := r.logger.WithFields(utils.LogFields{
+		"operation":   "remove_cart_item",
+		"employee_id": params.EmployeeID,
+		"merch_id":    params.MerchID,
+	})
+
+	affected, err := r.queries.RemoveCartItem(ctx, params)
+	if err != nil {
+		log.WithFields(utils.LogFields{"error": err}).Error("Failed to remove cart item")
+		return 0, fmt.Errorf("remove cart item failed: %w", err)
+	}
+
+	log.WithFields(utils.LogFields{"affected": affected}).Debug("Cart item removed")
+	return affected, nil
+}
+
+func (r *cartRepository) ListItems(ctx context.Context, employeeID int32) ([]db.ListCartItemsRow, error) {
+	log := r.logger.WithFields(utils.LogFields{
+		"operation":   "list_cart_items",
+		"employee_id": employeeID,
+	})
+
+	items, err := r.queries.ListCartItems(ctx, employeeID)
+	if err != nil {
+		log.WithFields(utils.LogFields{"error": err}).Error("Failed to list cart items")
+		return nil, fmt.Errorf("list cart items failed: %w", err)
+	}
+
+	log.WithFields(utils.LogFields{"item_count": len(items)}).Debug("Cart items retrieved")
+	return items, nil
+}
@@ -0,0 +1,97 @@
+package repository_test
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/par1ram/merch-store/internal/db"
+	"github.com/par1ram/merch-store/internal/repository"
+	"github.com/par1ram/merch-store/internal/utils"
+)
+
+func TestCartRepository_AddItem_Success(t *testing.T) {
+	mockPool, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mockPool.Close()
+
+	queries := db.New(mockPool)
+	repo := repository.NewCartRepository(queries, utils.NewLogger())
+
+	mockPool.ExpectExec(`INSERT INTO cart_items \(employee_id, merch_id, quantity\)`).
+		WithArgs(int32(7), int32(3), int32(2), int32(1000)).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+
+	affected, err := repo.AddItem(context.Background(), db.AddCartItemParams{EmployeeID: 7, MerchID: 3, Quantity: 2, MaxQuantity: 1000})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), affected)
+
+	assert.NoError(t, mockPool.ExpectationsWereMet())
+}
+
+func TestCartRepository_AddItem_LimitReached(t *testing.T) {
+	mockPool, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mockPool.Close()
+
+	queries := db.New(mockPool)
+	repo := repository.NewCartRepository(queries, utils.NewLogger())
+
+	// Суммарное количество превысило бы предел: строка не обновляется.
+	mockPool.ExpectExec(`(?s)INSERT INTO cart_items.*WHERE cart_items.quantity \+ EXCLUDED.quantity <= \$4::integer`).
+		WithArgs(int32(7), int32(3), int32(5), int32(1000)).
+		WillReturnResult(pgxmock.NewResult("INSERT", 0))
+
+	affected, err := repo.AddItem(context.Background(), db.AddCartItemParams{EmployeeID: 7, MerchID: 3, Quantity: 5, MaxQuantity: 1000})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), affected)
+
+	assert.NoError(t, mockPool.ExpectationsWereMet())
+}
+
+func TestCartRepository_ListItems_Success(t *testing.T) {
+	mockPool, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mockPool.Close()
+
+	queries := db.New(mockPool)
+	repo := repository.NewCartRepository(queries, utils.NewLogger())
+
+	rows := pgxmock.NewRows([]string{"merch_id", "merch_name", "price", "active", "stock", "quantity"}).
+		AddRow(int32(2), "cup", int32(20), true, pgtype.Int4{}, int32(1)).
+		AddRow(int32(3), "socks", int32(10), true, pgtype.Int4{Int32: 5, Valid: true}, int32(2))
+	mockPool.ExpectQuery(regexp.QuoteMeta(`FROM cart_items c JOIN merch m ON c.merch_id = m.id WHERE c.employee_id = $1 ORDER BY c.merch_id`)).
+		WithArgs(int32(7)).
+		WillReturnRows(rows)
+
+	items, err := repo.ListItems(context.Background(), 7)
+	assert.NoError(t, err)
+	assert.Len(t, items, 2)
+	assert.Equal(t, "socks", items[1].MerchName)
+	assert.Equal(t, int32(2), items[1].Quantity)
+
+	assert.NoError(t, mockPool.ExpectationsWereMet())
+}
+
+func TestCartRepository_RemoveItem_NotInCart(t *testing.T) {
+	mockPool, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mockPool.Close()
+
+	queries := db.New(mockPool)
+	repo := repository.NewCartRepository(queries, utils.NewLogger())
+
+	mockPool.ExpectExec(regexp.QuoteMeta(`DELETE FROM cart_items WHERE employee_id = $1 AND merch_id = $2`)).
+		WithArgs(int32(7), int32(9)).
+		WillReturnResult(pgxmock.NewResult("DELETE", 0))
+
+	affected, err := repo.RemoveItem(context.Background(), db.RemoveCartItemParams{EmployeeID: 7, MerchID: 9})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), affected)
+
+	assert.NoError(t, mockPool.ExpectationsWereMet())
+}
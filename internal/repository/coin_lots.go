@@ -0,0 +1,144 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/par1ram/merch-store/internal/db"
+	"github.com/par1ram/merch-store/internal/utils"
+)
+
+// ErrInsufficientBalance возвращается, если списание сделало бы баланс отрицательным.
+// Частично выполненные изменения должны быть отменены откатом транзакции.
+var ErrInsufficientBalance = errors.New("insufficient balance")
+
+// ErrEmployeeNotFound возвращается, если зачисление не изменило ни одной строки:
+// сотрудника с таким id нет.
+var ErrEmployeeNotFound = errors.New("employee not found")
+
+// ErrCoinLotsMismatch возвращается, если открытых партий сотрудника меньше,
+// чем списанных с баланса монет. Операция откатывается, чтобы сумма партий
+// не разошлась с employees.coins.
+var ErrCoinLotsMismatch = errors.New("coin lots do not cover debited amount")
+
+// Порядок блокировок при операциях с монетами: сначала строки employees по
+// возрастанию id (LockEmployeeBalances или UPDATE баланса), затем партии
+// coin_lots. Сгорание монет берёт блокировки с SKIP LOCKED и этот порядок не нарушает.
+
+// debitCoins списывает монеты: уменьшает employees.coins с проверкой на
+// отрицательный баланс, затем расходует партии с ближайшим сроком действия.
+func debitCoins(ctx context.Context, q *db.Queries, logger utils.Logger, userID, amount int32) ([]db.ConsumeCoinLotsRow, error) {
+	affected, err := q.UpdateEmployeeCoins(ctx, db.UpdateEmployeeCoinsParams{ID: userID, Coins: -amount})
+	if err != nil {
+		return nil, err
+	}
+	if affected == 0 {
+		return nil, ErrInsufficientBalance
+	}
+	return consumeCoinLots(ctx, q, logger, userID, amount)
+}
+
+// consumeCoinLots расходует партии сотрудника на amount монет после того,
+// как баланс уже уменьшен. Если партий не хватает, возвращает
+// ErrCoinLotsMismatch: списание без партий оставило бы их сумму больше
+// баланса, и это расхождение ничем не исправляется.
+func consumeCoinLots(ctx context.Context, q *db.Queries, logger utils.Logger, userID, amount int32) ([]db.ConsumeCoinLotsRow, error) {
+	lots, err := q.ConsumeCoinLots(ctx, db.ConsumeCoinLotsParams{EmployeeID: userID, Amount: amount})
+	if err != nil {
+		return nil, fmt.Errorf("consume coin lots failed: %w", err)
+	}
+
+	var consumed int32
+	for _, lot := range lots {
+		consumed += lot.Taken
+	}
+	if consumed != amount {
+		logger.WithFields(utils.LogFields{
+			"user_id":  userID,
+			"amount":   amount,
+			"consumed": consumed,
+		}).Error("Coin lots do not cover debited amount")
+		return nil, fmt.Errorf("%w: user %d, debited %d, lots %d", ErrCoinLotsMismatch, userID, amount, consumed)
+	}
+	return lots, nil
+}
+
+// creditCoins зачисляет монеты новой партией со сроком действия 12 месяцев.
+func creditCoins(ctx context.Context, q *db.Queries, userID, amount int32) error {
+	if err := depositCoins(ctx, q, userID, amount); err != nil {
+		return err
+	}
+	if err := q.CreateCoinLot(ctx, db.CreateCoinLotParams{EmployeeID: userID, Amount: amount}); err != nil {
+		return fmt.Errorf("create coin lot failed: %w", err)
+	}
+	return nil
+}
+
+// depositCoins увеличивает employees.coins без создания партий.
+func depositCoins(ctx context.Context, q *db.Queries, userID, amount int32) error {
+	affected, err := q.UpdateEmployeeCoins(ctx, db.UpdateEmployeeCoinsParams{ID: userID, Coins: amount})
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return fmt.Errorf("%w: %d", ErrEmployeeNotFound, userID)
+	}
+	return nil
+}
+
+// lockBalances блокирует строки сотрудников в порядке возрастания id и
+// возвращает их балансы. Отсутствующие сотрудники в результат не попадают.
+func lockBalances(ctx context.Context, q *db.Queries, userIDs []int32) (map[int32]int32, error) {
+	rows, err := q.LockEmployeeBalances(ctx, userIDs)
+	if err != nil {
+		return nil, err
+	}
+	balances := make(map[int32]int32, len(rows))
+	for _, row := range rows {
+		balances[row.ID] = row.Coins
+	}
+	return balances, nil
+}
+
+// takeLots отделяет от начала lots части партий на amount монет и возвращает
+// их вместе с остатком. Часть партии на границе делится между ними.
+func takeLots(lots []db.ConsumeCoinLotsRow, amount int32) (taken, rest []db.ConsumeCoinLotsRow) {
+	for i, lot := range lots {
+		if amount == 0 {
+			return taken, lots[i:]
+		}
+		if lot.Taken > amount {
+			head, tail := lot, lot
+			head.Taken, tail.Taken = amount, lot.Taken-amount
+			return append(taken, head), append([]db.ConsumeCoinLotsRow{tail}, lots[i+1:]...)
+		}
+		taken = append(taken, lot)
+		amount -= lot.Taken
+	}
+	return taken, nil
+}
+
+// moveCoinLots зачисляет получателю израсходованные у отправителя части партий
+// с их исходными сроками: перевод не продлевает срок действия монет. Если
+// переданных частей партий меньше суммы (покупки, сделанные до учёта партий
+// покупки), остаток зачисляется новой партией.
+func moveCoinLots(ctx context.Context, q *db.Queries, toUserID, amount int32, lots []db.ConsumeCoinLotsRow) error {
+	for _, lot := range lots {
+		amount -= lot.Taken
+		if err := q.CreateCoinLotWithExpiry(ctx, db.CreateCoinLotWithExpiryParams{
+			EmployeeID: toUserID,
+			Amount:     lot.Taken,
+			GrantedAt:  lot.GrantedAt,
+			ExpiresAt:  lot.ExpiresAt,
+		}); err != nil {
+			return fmt.Errorf("move coin lot failed: %w", err)
+		}
+	}
+	if amount > 0 {
+		if err := q.CreateCoinLot(ctx, db.CreateCoinLotParams{EmployeeID: toUserID, Amount: amount}); err != nil {
+			return fmt.Errorf("create coin lot failed: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/par1ram/merch-store/internal/db"
+	"github.com/par1ram/merch-store/internal/utils"
+)
+
+// CoinRequestRepository хранит запросы монет. Принятие запроса выполняет перевод
+// через Transfers() в той же транзакции, в которой закрывается запрос.
+type CoinRequestRepository interface {
+	ExecTx(ctx context.Context, fn func(CoinRequestRepository) error) error
+	// Transfers возвращает репозиторий переводов, работающий в транзакции этого репозитория.
+	Transfers() SendCoinRepository
+	GetEmployee(ctx context.Context, username string) (db.Employee, error)
+	CreateCoinRequest(ctx context.Context, params db.CreateCoinRequestParams) (db.CoinRequest, error)
+	GetCoinRequestForUpdate(ctx context.Context, id int32) (db.CoinRequest, error)
+	ResolveCoinRequest(ctx context.Context, params db.ResolveCoinRequestParams) error
+	ListCoinRequests(ctx context.Context, params db.ListCoinRequestsParams) ([]db.ListCoinRequestsRow, error)
+	ExpireCoinRequests(ctx context.Context) (int64, error)
+}
+
+type coinRequestRepository struct {
+	tx      TxRunner
+	queries *db.Queries
+	logger  utils.Logger
+}
+
+func NewCoinRequestRepository(tx TxRunner, queries *db.Queries, logger utils.Logger) CoinRequestRepository {
+	logger.WithFields(utils.LogFields{"component": "coin_request_repository"}).Info("CoinRequestRepository initialized")
+	return &coinRequestRepository{
+		tx:      tx,
+		queries: queries,
+		logger:  logger.WithFields(utils.LogFields{"component": "coin_request_repository"}),
+	}
+}
+
+func (r *coinRequestRepository) ExecTx(ctx context.Context, fn func(CoinRequestRepository) error) error {
+	return r.tx.Run(ctx, func(tx pgx.Tx) error {
+		return fn(&coinRequestRepository{
+			tx:      r.tx,
+			queries: r.queries.WithTx(tx),
+			logger:  r.logger,
+		})
+	})
+}
+
+func (r *coinRequestRepository) Transfers() SendCoinRepository {
+	return &sendCoinRepository{
+		tx:      r.tx,
+		queries: r.queries,
+		logger:  r.logger.WithFields(utils.LogFields{"component": "send_coin_repository"}),
+	}
+}
+
+func (r *coinRequestRepository) GetEmployee(ctx context.Context, username string) (db.Employee, error) {
+	employee, err := r.queries.GetEmployeeByUsername(ctx, username)
+	if err != nil {
+		r.logger.WithFields(utils.LogFields{"error": err, "username": username}).Error("Failed to get employee")
+		return employee, err
+	}
+	return employee, nil
+}
+
+func (r *coinRequestRepository) CreateCoinRequest(ctx context.Context, params db.CreateCoinRequestParams) (db.CoinRequest, error) {
+	request, err := r.queries.CreateCoinRequest(ctx, params)
+	if err != nil {
+		r.logger.WithFields(utils.LogFields{"error": err, "requester_id": params.RequesterID}).Error("Failed to create coin request")
+		return request, err
+	}
+	return request, nil
+}
+
+func (r *coinRequestRepository) GetCoinRequestForUpdate(ctx context.Context, id int32) (db.CoinRequest, error) {
+	request, err := r.queries.GetCoinRequestForUpdate(ctx, id)
+	if err != nil {
+		r.logger.WithFields(utils.LogFields{"error": err, "request_id": id}).Error("Failed to lock coin request")
+		return request, err
+	}
+	return request, nil
+}
+
+func (r *coinRequestRepository) ResolveCoinRequest(ctx context.Context, params db.ResolveCoinRequestParams) error {
+	if err := r.queries.ResolveCoinRequest(ctx, params); err != nil {
+		r.logger.WithFields(utils.LogFields{"error": err, "request_id": params.ID}).Error("Failed to resolve coin request")
+		return err
+	}
+	return nil
+}
+
+func (r *coinRequestRepository) ListCoinRequests(ctx context.Context, params db.ListCoinRequestsParams) ([]db.ListCoinRequestsRow, error) {
+	rows, err := r.queries.ListCoinRequests(ctx, params)
+	if err != nil {
+		r.logger.WithFields(utils.LogFields{"error": err}).Error("Failed to list coin requests")
+		return nil, err
+	}
+	return rows, nil
+}
+
+func (r *coinRequestRepository) ExpireCoinRequests(ctx context.Context) (int64, error) {
+	affected, err := r.queries.ExpireCoinRequests(ctx)
+	if err != nil {
+		r.logger.WithFields(utils.LogFields{"error": err}).Error("Failed to expire coin requests")
+		return 0, err
+	}
+	return affected, nil
+}
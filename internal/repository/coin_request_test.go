@@ -0,0 +1,54 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/par1ram/merch-store/internal/db"
+	"github.com/par1ram/merch-store/internal/repository"
+	"github.com/par1ram/merch-store/internal/utils"
+)
+
+func TestCoinRequestRepository_ListCoinRequests(t *testing.T) {
+	mockPool, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mockPool.Close()
+
+	repo := repository.NewCoinRequestRepository(newTxRunner(mockPool), db.New(mockPool), utils.NewLogger())
+
+	params := db.ListCoinRequestsParams{PayerID: pgtype.Int4{Int32: 2, Valid: true}}
+	mockPool.ExpectQuery(`(?s)FROM coin_requests r.*JOIN employees requester.*JOIN employees payer.*LIMIT 100`).
+		WithArgs(params.ID, params.RequesterID, params.PayerID, params.Status).
+		WillReturnRows(pgxmock.NewRows([]string{
+			"id", "requester", "payer", "amount", "note", "status", "created_at", "expires_at", "resolved_at",
+		}).AddRow(int32(5), "alice", "bob", int32(40), "lunch", db.CoinRequestStatusEnumExpired,
+			pgtype.Timestamptz{}, pgtype.Timestamptz{}, pgtype.Timestamptz{}))
+
+	rows, err := repo.ListCoinRequests(context.Background(), params)
+	assert.NoError(t, err)
+	assert.Len(t, rows, 1)
+	assert.Equal(t, db.CoinRequestStatusEnumExpired, rows[0].Status)
+
+	assert.NoError(t, mockPool.ExpectationsWereMet())
+}
+
+func TestCoinRequestRepository_ExpireCoinRequests(t *testing.T) {
+	mockPool, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mockPool.Close()
+
+	repo := repository.NewCoinRequestRepository(newTxRunner(mockPool), db.New(mockPool), utils.NewLogger())
+
+	mockPool.ExpectExec(`(?s)UPDATE coin_requests.*status = 'expired'.*WHERE status = 'pending' AND expires_at <= NOW\(\)`).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 3))
+
+	expired, err := repo.ExpireCoinRequests(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), expired)
+
+	assert.NoError(t, mockPool.ExpectationsWereMet())
+}
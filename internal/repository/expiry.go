@@ -0,0 +1,40 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/par1ram/merch-store/internal/db"
+	"github.com/par1ram/merch-store/internal/utils"
+)
+
+// ExpiryRepository списывает монеты с истёкшим сроком действия. Каждая порция
+// обрабатывается одним запросом, поэтому отдельная транзакция не нужна.
+type ExpiryRepository interface {
+	ExpireBatch(ctx context.Context, batchSize int32) ([]db.ExpireCoinLotsRow, error)
+}
+
+type expiryRepository struct {
+	queries *db.Queries
+	logger  utils.Logger
+}
+
+// NewExpiryRepository создаёт репозиторий сгорания монет.
+func NewExpiryRepository(queries *db.Queries, logger utils.Logger) ExpiryRepository {
+	logger.WithFields(utils.LogFields{"component": "expiry_repository"}).Info("ExpiryRepository initialized")
+	return &expiryRepository{
+		queries: queries,
+		logger:  logger.WithFields(utils.LogFields{"component": "expiry_repository"}),
+	}
+}
+
+// ExpireBatch обнуляет не более batchSize просроченных партий и возвращает
+// списанные суммы по сотрудникам. Пустой результат означает, что обрабатывать нечего.
+func (r *expiryRepository) ExpireBatch(ctx context.Context, batchSize int32) ([]db.ExpireCoinLotsRow, error) {
+	rows, err := r.queries.ExpireCoinLots(ctx, batchSize)
+	if err != nil {
+		r.logger.WithFields(utils.LogFields{"error": err, "batch_size": batchSize}).Error("Failed to expire coin lots")
+		return nil, fmt.Errorf("expire coin lots failed: %w", err)
+	}
+	return rows, nil
+}
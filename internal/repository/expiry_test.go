@@ -0,0 +1,53 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/par1ram/merch-store/internal/db"
+	"github.com/par1ram/merch-store/internal/repository"
+	"github.com/par1ram/merch-store/internal/utils"
+)
+
+func TestExpiryRepository_ExpireBatch(t *testing.T) {
+	mockPool, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mockPool.Close()
+
+	repo := repository.NewExpiryRepository(db.New(mockPool), utils.NewLogger())
+
+	mockPool.ExpectQuery(`(?s)WITH stale AS.*JOIN employees e ON e.id = l.employee_id.*expires_at <= NOW\(\).*FOR UPDATE OF l, e SKIP LOCKED.*'expiry'`).
+		WithArgs(int32(500)).
+		WillReturnRows(pgxmock.NewRows([]string{"employee_id", "amount"}).
+			AddRow(int32(1), int32(40)).
+			AddRow(int32(2), int32(15)))
+
+	rows, err := repo.ExpireBatch(context.Background(), 500)
+	assert.NoError(t, err)
+	assert.Equal(t, []db.ExpireCoinLotsRow{{EmployeeID: 1, Amount: 40}, {EmployeeID: 2, Amount: 15}}, rows)
+
+	assert.NoError(t, mockPool.ExpectationsWereMet())
+}
+
+func TestExpiryRepository_ExpireBatch_Error(t *testing.T) {
+	mockPool, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mockPool.Close()
+
+	repo := repository.NewExpiryRepository(db.New(mockPool), utils.NewLogger())
+
+	dbErr := errors.New("connection reset")
+	mockPool.ExpectQuery(`(?s)WITH stale AS`).
+		WithArgs(int32(500)).
+		WillReturnError(dbErr)
+
+	rows, err := repo.ExpireBatch(context.Background(), 500)
+	assert.ErrorIs(t, err, dbErr)
+	assert.Nil(t, rows)
+
+	assert.NoError(t, mockPool.ExpectationsWereMet())
+}
@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/par1ram/merch-store/internal/db"
+	"github.com/par1ram/merch-store/internal/utils"
+)
+
+// GrantRepository обслуживает начисления и списания монет администратором.
+// Пакетная операция выполняется целиком в одной транзакции через ExecTx.
+type GrantRepository interface {
+	ExecTx(ctx context.Context, fn func(GrantRepository) error) error
+	GetEmployee(ctx context.Context, username string) (db.Employee, error)
+	LockBalances(ctx context.Context, userIDs ...int32) (map[int32]int32, error)
+	UpdateCoins(ctx context.Context, userID, delta int32) (int64, error)
+	GetBalance(ctx context.Context, userID int32) (int32, error)
+	CreateGrantTransaction(ctx context.Context, params db.CreateCoinTransactionGrantParams) (int32, error)
+	CreateAdjustmentTransaction(ctx context.Context, params db.CreateCoinTransactionAdjustmentParams) (int32, error)
+}
+
+type grantRepository struct {
+	tx      TxRunner
+	queries *db.Queries
+	logger  utils.Logger
+}
+
+func NewGrantRepository(tx TxRunner, queries *db.Queries, logger utils.Logger) GrantRepository {
+	logger.WithFields(utils.LogFields{"component": "grant_repository"}).Info("GrantRepository initialized")
+	return &grantRepository{
+		tx:      tx,
+		queries: queries,
+		logger:  logger.WithFields(utils.LogFields{"component": "grant_repository"}),
+	}
+}
+
+func (r *grantRepository) ExecTx(ctx context.Context, fn func(GrantRepository) error) error {
+	return r.tx.Run(ctx, func(tx pgx.Tx) error {
+		return fn(&grantRepository{
+			tx:      r.tx,
+			queries: r.queries.WithTx(tx),
+			logger:  r.logger,
+		})
+	})
+}
+
+func (r *grantRepository) GetEmployee(ctx context.Context, username string) (db.Employee, error) {
+	employee, err := r.queries.GetEmployeeByUsername(ctx, username)
+	if err != nil {
+		r.logger.WithFields(utils.LogFields{"error": err, "username": username}).Error("Failed to get employee")
+		return employee, err
+	}
+	return employee, nil
+}
+
+// LockBalances блокирует строки сотрудников по возрастанию id и возвращает
+// их балансы.
+func (r *grantRepository) LockBalances(ctx context.Context, userIDs ...int32) (map[int32]int32, error) {
+	balances, err := lockBalances(ctx, r.queries, userIDs)
+	if err != nil {
+		r.logger.WithFields(utils.LogFields{"error": err, "user_ids": userIDs}).Error("Failed to lock balances")
+		return nil, err
+	}
+	return balances, nil
+}
+
+// UpdateCoins меняет баланс на delta через UpdateEmployeeCoins и возвращает
+// число изменённых строк: 0 означает, что списание сделало бы баланс
+// отрицательным. Начисление создаёт новую партию монет, списание расходует старейшие партии.
+func (r *grantRepository) UpdateCoins(ctx context.Context, userID, delta int32) (int64, error) {
+	log := r.logger.WithFields(utils.LogFields{"userID": userID, "delta": delta})
+
+	affected, err := r.queries.UpdateEmployeeCoins(ctx, db.UpdateEmployeeCoinsParams{ID: userID, Coins: delta})
+	if err != nil {
+		log.WithFields(utils.LogFields{"error": err}).Error("Failed to update coins")
+		return 0, err
+	}
+	if affected == 0 {
+		return 0, nil
+	}
+
+	if delta > 0 {
+		err = r.queries.CreateCoinLot(ctx, db.CreateCoinLotParams{EmployeeID: userID, Amount: delta})
+	} else {
+		_, err = consumeCoinLots(ctx, r.queries, r.logger, userID, -delta)
+	}
+	if err != nil {
+		log.WithFields(utils.LogFields{"error": err}).Error("Failed to update coin lots")
+		return 0, err
+	}
+	return affected, nil
+}
+
+func (r *grantRepository) GetBalance(ctx context.Context, userID int32) (int32, error) {
+	balance, err := r.queries.GetCoinsByID(ctx, userID)
+	if err != nil {
+		r.logger.WithFields(utils.LogFields{"error": err, "userID": userID}).Error("Failed to get balance")
+		return 0, err
+	}
+	return balance, nil
+}
+
+func (r *grantRepository) CreateGrantTransaction(ctx context.Context, params db.CreateCoinTransactionGrantParams) (int32, error) {
+	id, err := r.queries.CreateCoinTransactionGrant(ctx, params)
+	if err != nil {
+		r.logger.WithFields(utils.LogFields{"error": err, "to_employee_id": params.ToEmployeeID}).Error("Failed to record grant")
+		return 0, err
+	}
+	return id, nil
+}
+
+func (r *grantRepository) CreateAdjustmentTransaction(ctx context.Context, params db.CreateCoinTransactionAdjustmentParams) (int32, error) {
+	id, err := r.queries.CreateCoinTransactionAdjustment(ctx, params)
+	if err != nil {
+		r.logger.WithFields(utils.LogFields{"error": err, "from_employee_id": params.FromEmployeeID}).Error("Failed to record adjustment")
+		return 0, err
+	}
+	return id, nil
+}
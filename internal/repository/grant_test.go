@@ -0,0 +1,126 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/par1ram/merch-store/internal/db"
+	"github.com/par1ram/merch-store/internal/repository"
+	"github.com/par1ram/merch-store/internal/utils"
+)
+
+func TestGrantRepository_GrantInTx_Success(t *testing.T) {
+	mockPool, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mockPool.Close()
+
+	queries := db.New(mockPool)
+	repo := repository.NewGrantRepository(newTxRunner(mockPool), queries, utils.NewLogger())
+
+	mockPool.ExpectBegin()
+	mockPool.ExpectQuery(`(?s)FROM employees.*WHERE username = \$1`).
+		WithArgs("alice").
+		WillReturnRows(pgxmock.NewRows([]string{"id", "username", "password_hash", "coins", "created_at", "role"}).
+			AddRow(int32(7), "alice", "hash", int32(1000), pgtype.Timestamptz{}, db.EmployeeRoleEnumEmployee))
+	mockPool.ExpectExec(`(?s)UPDATE employees.*SET coins = coins \+ \$2.*coins \+ \$2 >= 0`).
+		WithArgs(int32(7), int32(150)).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mockPool.ExpectExec(`(?s)INSERT INTO coin_lots \(employee_id, amount, remaining\)`).
+		WithArgs(int32(7), int32(150)).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	mockPool.ExpectQuery(`(?s)INSERT INTO coin_transactions .*reason, created_by.*VALUES \('grant'.*RETURNING id`).
+		WithArgs(int32(7), int32(150), "hackathon winner", int32(1)).
+		WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(int32(40)))
+	mockPool.ExpectCommit()
+
+	var grantID int32
+	err = repo.ExecTx(context.Background(), func(r repository.GrantRepository) error {
+		employee, err := r.GetEmployee(context.Background(), "alice")
+		if err != nil {
+			return err
+		}
+		affected, err := r.UpdateCoins(context.Background(), employee.ID, 150)
+		if err != nil {
+			return err
+		}
+		assert.Equal(t, int64(1), affected)
+
+		grantID, err = r.CreateGrantTransaction(context.Background(), db.CreateCoinTransactionGrantParams{
+			ToEmployeeID: employee.ID,
+			Amount:       150,
+			Reason:       "hackathon winner",
+			CreatedBy:    1,
+		})
+		return err
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, int32(40), grantID)
+
+	assert.NoError(t, mockPool.ExpectationsWereMet())
+}
+
+func TestGrantRepository_LockBalances(t *testing.T) {
+	mockPool, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mockPool.Close()
+
+	queries := db.New(mockPool)
+	repo := repository.NewGrantRepository(newTxRunner(mockPool), queries, utils.NewLogger())
+
+	mockPool.ExpectQuery(`(?s)FROM employees\s+WHERE id = ANY\(\$1::integer\[\]\)\s+ORDER BY id\s+FOR UPDATE`).
+		WithArgs([]int32{8, 7}).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "coins"}).AddRow(int32(7), int32(1000)).AddRow(int32(8), int32(200)))
+
+	balances, err := repo.LockBalances(context.Background(), 8, 7)
+	assert.NoError(t, err)
+	assert.Equal(t, map[int32]int32{7: 1000, 8: 200}, balances)
+
+	assert.NoError(t, mockPool.ExpectationsWereMet())
+}
+
+func TestGrantRepository_UpdateCoins_InsufficientBalance(t *testing.T) {
+	mockPool, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mockPool.Close()
+
+	queries := db.New(mockPool)
+	repo := repository.NewGrantRepository(newTxRunner(mockPool), queries, utils.NewLogger())
+
+	mockPool.ExpectExec(`(?s)UPDATE employees.*coins \+ \$2 >= 0`).
+		WithArgs(int32(7), int32(-5000)).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+
+	affected, err := repo.UpdateCoins(context.Background(), 7, -5000)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), affected)
+
+	assert.NoError(t, mockPool.ExpectationsWereMet())
+}
+
+func TestGrantRepository_CreateAdjustmentTransaction(t *testing.T) {
+	mockPool, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mockPool.Close()
+
+	queries := db.New(mockPool)
+	repo := repository.NewGrantRepository(newTxRunner(mockPool), queries, utils.NewLogger())
+
+	mockPool.ExpectQuery(`(?s)INSERT INTO coin_transactions .*VALUES \('adjustment'.*RETURNING id`).
+		WithArgs(int32(7), int32(20), "duplicate grant", int32(1)).
+		WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(int32(41)))
+
+	id, err := repo.CreateAdjustmentTransaction(context.Background(), db.CreateCoinTransactionAdjustmentParams{
+		FromEmployeeID: 7,
+		Amount:         20,
+		Reason:         "duplicate grant",
+		CreatedBy:      1,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, int32(41), id)
+
+	assert.NoError(t, mockPool.ExpectationsWereMet())
+}
@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/par1ram/merch-store/internal/db"
+	"github.com/par1ram/merch-store/internal/utils"
+)
+
+// HistoryRepository постранично читает историю операций сотрудника.
+type HistoryRepository interface {
+	ListCoinHistory(ctx context.Context, params db.ListCoinHistoryParams) ([]db.ListCoinHistoryRow, error)
+}
+
+type historyRepository struct {
+	queries *db.Queries
+	logger  utils.Logger
+}
+
+func NewHistoryRepository(queries *db.Queries, logger utils.Logger) HistoryRepository {
+	logger.WithFields(utils.LogFields{"component": "history_repository"}).Info("HistoryRepository initialized")
+	return &historyRepository{
+		queries: queries,
+		logger:  logger.WithFields(utils.LogFields{"component": "history_repository"}),
+	}
+}
+
+func (r *historyRepository) ListCoinHistory(ctx context.Context, params db.ListCoinHistoryParams) ([]db.ListCoinHistoryRow, error) {
+	rows, err := r.queries.ListCoinHistory(ctx, params)
+	if err != nil {
+		r.logger.WithFields(utils.LogFields{"error": err, "employee_id": params.EmployeeID}).Error("Failed to list coin history")
+		return nil, err
+	}
+	return rows, nil
+}
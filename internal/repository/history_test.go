@@ -0,0 +1,53 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/par1ram/merch-store/internal/db"
+	"github.com/par1ram/merch-store/internal/repository"
+	"github.com/par1ram/merch-store/internal/utils"
+)
+
+func TestHistoryRepository_ListCoinHistory(t *testing.T) {
+	mockPool, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mockPool.Close()
+
+	repo := repository.NewHistoryRepository(db.New(mockPool), utils.NewLogger())
+
+	createdAt := pgtype.Timestamptz{Time: time.Date(2026, time.October, 10, 12, 0, 0, 0, time.UTC), Valid: true}
+	params := db.ListCoinHistoryParams{
+		EmployeeID:      1,
+		Incoming:        pgtype.Bool{Bool: true, Valid: true},
+		Types:           []string{"transfer", "hold_release"},
+		CursorCreatedAt: createdAt,
+		CursorID:        pgtype.Int4{Int32: 50, Valid: true},
+		RowLimit:        21,
+	}
+
+	mockPool.ExpectQuery(`(?s)FROM coin_transactions ct.*LEFT JOIN merch m ON m.id = ct.merch_id.*LEFT JOIN transfer_holds h.*`+
+		`WHERE \(ct.from_employee_id = \$1::integer OR ct.to_employee_id = \$1::integer\).*`+
+		`\(ct.created_at, ct.id\) < .*ORDER BY ct.created_at DESC, ct.id DESC\s+LIMIT \$11`).
+		WithArgs(params.EmployeeID, params.Incoming, params.Counterparty, params.Types, params.CreatedFrom,
+			params.CreatedTo, params.MinAmount, params.MaxAmount, params.CursorCreatedAt, params.CursorID, params.RowLimit).
+		WillReturnRows(pgxmock.NewRows([]string{
+			"id", "transaction_type", "incoming", "amount", "counterparty", "merch_name", "quantity",
+			"message", "hashtags", "reason", "created_at",
+		}).
+			AddRow(int32(49), db.TransactionTypeEnumTransfer, true, int32(30), "alice", "", int32(1), "thanks", []string{}, "", createdAt).
+			AddRow(int32(48), db.TransactionTypeEnumHoldRelease, true, int32(40), "carol", "", int32(1), "", []string{}, "", createdAt))
+
+	rows, err := repo.ListCoinHistory(context.Background(), params)
+	assert.NoError(t, err)
+	assert.Len(t, rows, 2)
+	assert.Equal(t, "carol", rows[1].Counterparty)
+	assert.True(t, rows[1].Incoming)
+
+	assert.NoError(t, mockPool.ExpectationsWereMet())
+}
@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/par1ram/merch-store/internal/db"
+	"github.com/par1ram/merch-store/internal/utils"
+)
+
+// IdempotencyRecord — сохранённый результат запроса с ключом идемпотентности.
+// Completed = false, пока исходный запрос ещё выполняется.
+type IdempotencyRecord struct {
+	RequestHash string
+	Completed   bool
+	Status      int
+	Body        []byte
+}
+
+type IdempotencyRepository interface {
+	Reserve(ctx context.Context, employeeID int32, key, requestHash string, expiredBefore time.Time) (bool, error)
+	Get(ctx context.Context, employeeID int32, key string) (IdempotencyRecord, error)
+	SaveResponse(ctx context.Context, employeeID int32, key string, status int, body []byte) error
+	Release(ctx context.Context, employeeID int32, key string) error
+	PurgeExpired(ctx context.Context, expiredBefore time.Time) (int64, error)
+}
+
+type idempotencyRepository struct {
+	queries *db.Queries
+	logger  utils.Logger
+}
+
+// NewIdempotencyRepository создаёт хранилище ключей идемпотентности.
+func NewIdempotencyRepository(queries *db.Queries, logger utils.Logger) IdempotencyRepository {
+	logger.WithFields(utils.LogFields{"component": "idempotency_repository"}).Info("IdempotencyRepository initialized")
+	return &idempotencyRepository{
+		queries: queries,
+		logger:  logger.WithFields(utils.LogFields{"component": "idempotency_repository"}),
+	}
+}
+
+// Reserve занимает ключ. Возвращает false, если ключ уже занят действующей записью.
+func (r *idempotencyRepository) Reserve(ctx context.Context, employeeID int32, key, requestHash string, expiredBefore time.Time) (bool, error) {
+	log := r.logger.WithFields(utils.LogFields{
+		"operation":   "reserve",
+		"employee_id": employeeID,
+		"key":         key,
+	})
+
+	affected, err := r.queries.ReserveIdempotencyKey(ctx, db.ReserveIdempotencyKeyParams{
+		EmployeeID:     employeeID,
+		IdempotencyKey: key,
+		RequestHash:    requestHash,
+		ExpiredBefore:  pgtype.Timestamptz{Time: expiredBefore, Valid: true},
+	})
+	if err != nil {
+		log.WithFields(utils.LogFields{"error": err}).Error("Failed to reserve idempotency key")
+		return false, fmt.Errorf("reserve idempotency key failed: %w", err)
+	}
+
+	log.WithFields(utils.LogFields{"reserved": affected > 0}).Debug("Idempotency key reservation attempted")
+	return affected > 0, nil
+}
+
+func (r *idempotencyRepository) Get(ctx context.Context, employeeID int32, key string) (IdempotencyRecord, error) {
+	row, err := r.queries.GetIdempotencyKey(ctx, db.GetIdempotencyKeyParams{
+		EmployeeID:     employeeID,
+		IdempotencyKey: key,
+	})
+	if err != nil {
+		r.logger.WithFields(utils.LogFields{"error": err, "employee_id": employeeID, "key": key}).Error("Failed to get idempotency key")
+		return IdempotencyRecord{}, err
+	}
+
+	return IdempotencyRecord{
+		RequestHash: row.RequestHash,
+		Completed:   row.ResponseStatus.Valid,
+		Status:      int(row.ResponseStatus.Int32),
+		Body:        row.ResponseBody,
+	}, nil
+}
+
+func (r *idempotencyRepository) SaveResponse(ctx context.Context, employeeID int32, key string, status int, body []byte) error {
+	err := r.queries.SaveIdempotencyResponse(ctx, db.SaveIdempotencyResponseParams{
+		EmployeeID:     employeeID,
+		IdempotencyKey: key,
+		ResponseStatus: pgtype.Int4{Int32: int32(status), Valid: true},
+		ResponseBody:   body,
+	})
+	if err != nil {
+		r.logger.WithFields(utils.LogFields{"error": err, "employee_id": employeeID, "key": key}).Error("Failed to save idempotent response")
+		return fmt.Errorf("save idempotent response failed: %w", err)
+	}
+	return nil
+}
+
+func (r *idempotencyRepository) Release(ctx context.Context, employeeID int32, key string) error {
+	err := r.queries.DeleteIdempotencyKey(ctx, db.DeleteIdempotencyKeyParams{
+		EmployeeID:     employeeID,
+		IdempotencyKey: key,
+	})
+	if err != nil {
+		r.logger.WithFields(utils.LogFields{"error": err, "employee_id": employeeID, "key": key}).Error("Failed to release idempotency key")
+		return fmt.Errorf("release idempotency key failed: %w", err)
+	}
+	return nil
+}
+
+// PurgeExpired удаляет записи, вышедшие за окно идемпотентности.
+func (r *idempotencyRepository) PurgeExpired(ctx context.Context, expiredBefore time.Time) (int64, error) {
+	deleted, err := r.queries.DeleteExpiredIdempotencyKeys(ctx, pgtype.Timestamptz{Time: expiredBefore, Valid: true})
+	if err != nil {
+		r.logger.WithFields(utils.LogFields{"error": err}).Error("Failed to purge idempotency keys")
+		return 0, fmt.Errorf("purge idempotency keys failed: %w", err)
+	}
+
+	r.logger.WithFields(utils.LogFields{"deleted": deleted}).Debug("Expired idempotency keys purged")
+	return deleted, nil
+}
@@ -0,0 +1,72 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/par1ram/merch-store/internal/db"
+	"github.com/par1ram/merch-store/internal/repository"
+	"github.com/par1ram/merch-store/internal/utils"
+)
+
+func TestIdempotencyRepository_Reserve(t *testing.T) {
+	mockPool, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mockPool.Close()
+
+	queries := db.New(mockPool)
+	repo := repository.NewIdempotencyRepository(queries, utils.NewLogger())
+
+	expiredBefore := time.Now().Add(-time.Hour)
+	reserveQuery := `(?s)INSERT INTO idempotency_keys.*ON CONFLICT \(employee_id, idempotency_key\).*WHERE idempotency_keys.created_at < \$4`
+
+	// Новый ключ занимается.
+	mockPool.ExpectExec(reserveQuery).
+		WithArgs(int32(7), "key-1", "hash", pgtype.Timestamptz{Time: expiredBefore, Valid: true}).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	// Действующий ключ занять нельзя.
+	mockPool.ExpectExec(reserveQuery).
+		WithArgs(int32(7), "key-1", "hash", pgtype.Timestamptz{Time: expiredBefore, Valid: true}).
+		WillReturnResult(pgxmock.NewResult("INSERT", 0))
+
+	reserved, err := repo.Reserve(context.Background(), 7, "key-1", "hash", expiredBefore)
+	assert.NoError(t, err)
+	assert.True(t, reserved)
+
+	reserved, err = repo.Reserve(context.Background(), 7, "key-1", "hash", expiredBefore)
+	assert.NoError(t, err)
+	assert.False(t, reserved)
+
+	assert.NoError(t, mockPool.ExpectationsWereMet())
+}
+
+func TestIdempotencyRepository_Get(t *testing.T) {
+	mockPool, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mockPool.Close()
+
+	queries := db.New(mockPool)
+	repo := repository.NewIdempotencyRepository(queries, utils.NewLogger())
+
+	rows := pgxmock.NewRows([]string{"employee_id", "idempotency_key", "request_hash", "response_status", "response_body", "created_at"}).
+		AddRow(int32(7), "key-1", "hash", pgtype.Int4{Int32: 200, Valid: true}, []byte(`{"status":"success"}`), pgtype.Timestamptz{Time: time.Now(), Valid: true})
+	mockPool.ExpectQuery(`(?s)SELECT.*FROM idempotency_keys WHERE employee_id = \$1 AND idempotency_key = \$2`).
+		WithArgs(int32(7), "key-1").
+		WillReturnRows(rows)
+
+	record, err := repo.Get(context.Background(), 7, "key-1")
+	assert.NoError(t, err)
+	assert.Equal(t, repository.IdempotencyRecord{
+		RequestHash: "hash",
+		Completed:   true,
+		Status:      200,
+		Body:        []byte(`{"status":"success"}`),
+	}, record)
+
+	assert.NoError(t, mockPool.ExpectationsWereMet())
+}
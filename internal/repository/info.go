@@ -2,8 +2,8 @@ package repository
 
 import (
 	"context"
+	"time"
 
-	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/par1ram/merch-store/internal/db"
 	"github.com/par1ram/merch-store/internal/utils"
 )
@@ -16,18 +16,72 @@ type InventoryItem struct {
 type ReceivedTransaction struct {
 	FromUser string
 	Amount   int
+	Message  string
+	Hashtags []string
 }
 
 type SentTransaction struct {
-	ToUser string
-	Amount int
+	ToUser   string
+	Amount   int
+	Message  string
+	Hashtags []string
+}
+
+// CoinAdjustment — начисление или списание монет администратором, пособие
+// или сгорание монет.
+// Amount отрицателен для списаний.
+type CoinAdjustment struct {
+	Type      string
+	Amount    int
+	Reason    string
+	CreatedAt time.Time
+}
+
+// CoinActivity — операция из общей ленты сотрудника: перевод, покупка, возврат,
+// начисление и любая другая. Incoming — монеты зачислены сотруднику; Item и
+// Quantity заполнены для покупок и возвратов.
+type CoinActivity struct {
+	ID           int32
+	Type         string
+	Incoming     bool
+	Amount       int32
+	Counterparty string
+	Item         string
+	Quantity     int32
+	Message      string
+	Hashtags     []string
+	Reason       string
+	CreatedAt    time.Time
 }
 
+// CoinExpiration — монеты, срок действия которых истекает в указанный день.
+type CoinExpiration struct {
+	Amount    int
+	ExpiresAt time.Time
+}
+
+// PendingOrder — заказ, который ещё не выдан сотруднику.
+type PendingOrder struct {
+	ID       int32
+	Item     string
+	Quantity int
+	Status   string
+	PlacedAt time.Time
+}
+
+// InfoRepository собирает сводку по сотруднику. Истории переводов и
+// корректировок ограничены limit последними записями; полная история
+// доступна через HistoryRepository.
 type InfoRepository interface {
 	GetCoins(ctx context.Context, userID int64) (int, error)
 	GetInventory(ctx context.Context, userID int64) ([]InventoryItem, error)
-	GetReceivedTransfers(ctx context.Context, userID int64) ([]ReceivedTransaction, error)
-	GetSentTransfers(ctx context.Context, userID int64) ([]SentTransaction, error)
+	GetReceivedItems(ctx context.Context, userID int64) ([]InventoryItem, error)
+	GetReceivedTransfers(ctx context.Context, userID int64, limit int32) ([]ReceivedTransaction, error)
+	GetSentTransfers(ctx context.Context, userID int64, limit int32) ([]SentTransaction, error)
+	GetCoinAdjustments(ctx context.Context, userID int64, limit int32) ([]CoinAdjustment, error)
+	GetRecentActivity(ctx context.Context, userID int64, limit int32) ([]CoinActivity, error)
+	GetUpcomingExpirations(ctx context.Context, userID int64) ([]CoinExpiration, error)
+	GetPendingOrders(ctx context.Context, userID int64) ([]PendingOrder, error)
 }
 
 type infoRepository struct {
@@ -90,13 +144,37 @@ func (r *infoRepository) GetInventory(ctx context.Context, userID int64) ([]Inve
 	return inventory, nil
 }
 
-func (r *infoRepository) GetReceivedTransfers(ctx context.Context, userID int64) ([]ReceivedTransaction, error) {
+// GetReceivedItems возвращает товары, уже выданные сотруднику: инвентарь
+// без единиц из заказов, ожидающих выдачи.
+func (r *infoRepository) GetReceivedItems(ctx context.Context, userID int64) ([]InventoryItem, error) {
+	log := r.logOperation(ctx, "get_received_items")
+	log.Debugf("Starting received items retrieval")
+
+	items, err := r.Queries.GetReceivedInventoryByEmployeeID(ctx, int32(userID))
+	if err != nil {
+		log.WithFields(utils.LogFields{"error": err}).Errorf("Failed to get received items")
+		return nil, err
+	}
+
+	received := make([]InventoryItem, 0, len(items))
+	for _, item := range items {
+		received = append(received, InventoryItem{
+			Type:     item.MerchName,
+			Quantity: int(item.Quantity),
+		})
+	}
+
+	log.WithFields(utils.LogFields{"item_count": len(received)}).Debugf("Received items retrieved")
+	return received, nil
+}
+
+func (r *infoRepository) GetReceivedTransfers(ctx context.Context, userID int64, limit int32) ([]ReceivedTransaction, error) {
 	log := r.logOperation(ctx, "get_received_transfers")
 	log.Debugf("Starting received transfers retrieval")
 
-	transfers, err := r.Queries.GetReceivedTransfers(ctx, pgtype.Int4{
-		Int32: int32(userID),
-		Valid: true,
+	transfers, err := r.Queries.GetReceivedTransfers(ctx, db.GetReceivedTransfersParams{
+		ToEmployeeID: int32(userID),
+		RowLimit:     limit,
 	})
 	if err != nil {
 		log.WithFields(utils.LogFields{"error": err}).Errorf("Failed to get received transfers")
@@ -108,6 +186,8 @@ func (r *infoRepository) GetReceivedTransfers(ctx context.Context, userID int64)
 		recTrans = append(recTrans, ReceivedTransaction{
 			FromUser: t.FromUser,
 			Amount:   int(t.Amount),
+			Message:  t.Message,
+			Hashtags: t.Hashtags,
 		})
 	}
 
@@ -115,11 +195,14 @@ func (r *infoRepository) GetReceivedTransfers(ctx context.Context, userID int64)
 	return recTrans, nil
 }
 
-func (r *infoRepository) GetSentTransfers(ctx context.Context, userID int64) ([]SentTransaction, error) {
+func (r *infoRepository) GetSentTransfers(ctx context.Context, userID int64, limit int32) ([]SentTransaction, error) {
 	log := r.logOperation(ctx, "get_sent_transfers")
 	log.Debugf("Starting sent transfers retrieval")
 
-	transfers, err := r.Queries.GetSentTransfers(ctx, int32(userID))
+	transfers, err := r.Queries.GetSentTransfers(ctx, db.GetSentTransfersParams{
+		FromEmployeeID: int32(userID),
+		RowLimit:       limit,
+	})
 	if err != nil {
 		log.WithFields(utils.LogFields{"error": err}).Errorf("Failed to get sent transfers")
 		return nil, err
@@ -128,11 +211,121 @@ func (r *infoRepository) GetSentTransfers(ctx context.Context, userID int64) ([]
 	sentTrans := make([]SentTransaction, 0, len(transfers))
 	for _, t := range transfers {
 		sentTrans = append(sentTrans, SentTransaction{
-			ToUser: t.ToUser,
-			Amount: int(t.Amount),
+			ToUser:   t.ToUser,
+			Amount:   int(t.Amount),
+			Message:  t.Message,
+			Hashtags: t.Hashtags,
 		})
 	}
 
 	log.WithFields(utils.LogFields{"transfer_count": len(sentTrans)}).Debugf("Sent transfers retrieved")
 	return sentTrans, nil
 }
+
+func (r *infoRepository) GetCoinAdjustments(ctx context.Context, userID int64, limit int32) ([]CoinAdjustment, error) {
+	log := r.logOperation(ctx, "get_coin_adjustments")
+	log.Debugf("Starting coin adjustments retrieval")
+
+	rows, err := r.Queries.GetCoinAdjustments(ctx, db.GetCoinAdjustmentsParams{
+		EmployeeID: int32(userID),
+		RowLimit:   limit,
+	})
+	if err != nil {
+		log.WithFields(utils.LogFields{"error": err}).Errorf("Failed to get coin adjustments")
+		return nil, err
+	}
+
+	adjustments := make([]CoinAdjustment, 0, len(rows))
+	for _, row := range rows {
+		adjustments = append(adjustments, CoinAdjustment{
+			Type:      string(row.TransactionType),
+			Amount:    int(row.Amount),
+			Reason:    row.Reason,
+			CreatedAt: row.CreatedAt.Time,
+		})
+	}
+
+	log.WithFields(utils.LogFields{"adjustment_count": len(adjustments)}).Debugf("Coin adjustments retrieved")
+	return adjustments, nil
+}
+
+func (r *infoRepository) GetRecentActivity(ctx context.Context, userID int64, limit int32) ([]CoinActivity, error) {
+	log := r.logOperation(ctx, "get_recent_activity")
+	log.Debugf("Starting recent activity retrieval")
+
+	rows, err := r.Queries.ListCoinHistory(ctx, db.ListCoinHistoryParams{
+		EmployeeID: int32(userID),
+		RowLimit:   limit,
+	})
+	if err != nil {
+		log.WithFields(utils.LogFields{"error": err}).Errorf("Failed to get recent activity")
+		return nil, err
+	}
+
+	activity := make([]CoinActivity, 0, len(rows))
+	for _, row := range rows {
+		activity = append(activity, CoinActivity{
+			ID:           row.ID,
+			Type:         string(row.TransactionType),
+			Incoming:     row.Incoming,
+			Amount:       row.Amount,
+			Counterparty: row.Counterparty,
+			Item:         row.MerchName,
+			Quantity:     row.Quantity,
+			Message:      row.Message,
+			Hashtags:     row.Hashtags,
+			Reason:       row.Reason,
+			CreatedAt:    row.CreatedAt.Time,
+		})
+	}
+
+	log.WithFields(utils.LogFields{"activity_count": len(activity)}).Debugf("Recent activity retrieved")
+	return activity, nil
+}
+
+func (r *infoRepository) GetUpcomingExpirations(ctx context.Context, userID int64) ([]CoinExpiration, error) {
+	log := r.logOperation(ctx, "get_upcoming_expirations")
+	log.Debugf("Starting upcoming expirations retrieval")
+
+	rows, err := r.Queries.GetUpcomingExpirations(ctx, int32(userID))
+	if err != nil {
+		log.WithFields(utils.LogFields{"error": err}).Errorf("Failed to get upcoming expirations")
+		return nil, err
+	}
+
+	expirations := make([]CoinExpiration, 0, len(rows))
+	for _, row := range rows {
+		expirations = append(expirations, CoinExpiration{
+			Amount:    int(row.Amount),
+			ExpiresAt: row.ExpiresOn.Time,
+		})
+	}
+
+	log.WithFields(utils.LogFields{"expiration_count": len(expirations)}).Debugf("Upcoming expirations retrieved")
+	return expirations, nil
+}
+
+func (r *infoRepository) GetPendingOrders(ctx context.Context, userID int64) ([]PendingOrder, error) {
+	log := r.logOperation(ctx, "get_pending_orders")
+	log.Debugf("Starting pending orders retrieval")
+
+	rows, err := r.Queries.GetPendingOrdersByEmployeeID(ctx, int32(userID))
+	if err != nil {
+		log.WithFields(utils.LogFields{"error": err}).Errorf("Failed to get pending orders")
+		return nil, err
+	}
+
+	orders := make([]PendingOrder, 0, len(rows))
+	for _, row := range rows {
+		orders = append(orders, PendingOrder{
+			ID:       row.ID,
+			Item:     row.MerchName,
+			Quantity: int(row.Quantity),
+			Status:   string(row.Status),
+			PlacedAt: row.CreatedAt.Time,
+		})
+	}
+
+	log.WithFields(utils.LogFields{"order_count": len(orders)}).Debugf("Pending orders retrieved")
+	return orders, nil
+}
@@ -98,6 +98,28 @@ ORDER BY m.name
 	assert.NoError(t, err)
 }
 
+func TestInfoRepository_GetReceivedItems_Success(t *testing.T) {
+	mockPool, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mockPool.Close()
+
+	queries := db.New(mockPool)
+
+	rows := pgxmock.NewRows([]string{"merch_name", "quantity"}).
+		AddRow("T-Shirt", int32(1))
+
+	mockPool.ExpectQuery(`(?s)FROM inventory i.*LEFT JOIN \(.*FROM orders o.*o.status NOT IN \('delivered', 'cancelled'\).*quantity - COALESCE\(p.pending, 0\) > 0`).
+		WithArgs(int32(123)).
+		WillReturnRows(rows)
+
+	repoInstance := repository.NewInfoRepository(queries, utils.NewLogger())
+	received, err := repoInstance.GetReceivedItems(context.Background(), 123)
+	assert.NoError(t, err)
+	assert.Equal(t, []repository.InventoryItem{{Type: "T-Shirt", Quantity: 1}}, received)
+
+	assert.NoError(t, mockPool.ExpectationsWereMet())
+}
+
 func TestInfoRepository_GetReceivedTransfers_Success(t *testing.T) {
 	mockPool, err := pgxmock.NewPool()
 	assert.NoError(t, err)
@@ -106,33 +128,34 @@ func TestInfoRepository_GetReceivedTransfers_Success(t *testing.T) {
 	queries := db.New(mockPool)
 
 	// Для запроса GetReceivedTransfers ожидаем столбцы:
-	// amount, from_user
-	rows := pgxmock.NewRows([]string{"amount", "from_user"}).
-		AddRow(int32(50), "Alice").
-		AddRow(int32(30), "Charlie")
+	// amount, from_user, message, hashtags
+	rows := pgxmock.NewRows([]string{"amount", "from_user", "message", "hashtags"}).
+		AddRow(int32(50), "Alice", "thanks for the demo #teamwork", []string{"teamwork"}).
+		AddRow(int32(30), "Charlie", "", []string{})
 
-	// В запросе используется аргумент типа pgtype.Int4.
-	arg := pgtype.Int4{Int32: int32(123), Valid: true}
 	mockPool.ExpectQuery(regexp.QuoteMeta(`
 SELECT
   ct.amount,
-  e.username AS from_user
+  e.username AS from_user,
+  COALESCE(ct.message, '')::text AS message,
+  ct.hashtags
 FROM coin_transactions ct
 JOIN employees e ON ct.from_employee_id = e.id
 WHERE ct.transaction_type = 'transfer'
-  AND ct.to_employee_id = $1
-ORDER BY ct.created_at DESC
+  AND ct.to_employee_id = $1::integer
+ORDER BY ct.created_at DESC, ct.id DESC
+LIMIT $2
 `)).
-		WithArgs(arg).
+		WithArgs(int32(123), int32(20)).
 		WillReturnRows(rows)
 
 	repoInstance := repository.NewInfoRepository(queries, utils.NewLogger())
-	received, err := repoInstance.GetReceivedTransfers(context.Background(), 123)
+	received, err := repoInstance.GetReceivedTransfers(context.Background(), 123, 20)
 	assert.NoError(t, err)
 
 	expectedReceived := []repository.ReceivedTransaction{
-		{FromUser: "Alice", Amount: 50},
-		{FromUser: "Charlie", Amount: 30},
+		{FromUser: "Alice", Amount: 50, Message: "thanks for the demo #teamwork", Hashtags: []string{"teamwork"}},
+		{FromUser: "Charlie", Amount: 30, Hashtags: []string{}},
 	}
 	assert.Equal(t, expectedReceived, received)
 
@@ -148,34 +171,144 @@ func TestInfoRepository_GetSentTransfers_Success(t *testing.T) {
 	queries := db.New(mockPool)
 
 	// Для запроса GetSentTransfers ожидаем столбцы:
-	// amount, to_user
-	rows := pgxmock.NewRows([]string{"amount", "to_user"}).
-		AddRow(int32(40), "Bob").
-		AddRow(int32(20), "David")
+	// amount, to_user, message, hashtags
+	rows := pgxmock.NewRows([]string{"amount", "to_user", "message", "hashtags"}).
+		AddRow(int32(40), "Bob", "great pairing #help #teamwork", []string{"help", "teamwork"}).
+		AddRow(int32(20), "David", "", []string{})
 
 	mockPool.ExpectQuery(regexp.QuoteMeta(`
 SELECT
   ct.amount,
-  e.username AS to_user
+  e.username AS to_user,
+  COALESCE(ct.message, '')::text AS message,
+  ct.hashtags
 FROM coin_transactions ct
 JOIN employees e ON ct.to_employee_id = e.id
 WHERE ct.transaction_type = 'transfer'
-  AND ct.from_employee_id = $1
-ORDER BY ct.created_at DESC
+  AND ct.from_employee_id = $1::integer
+ORDER BY ct.created_at DESC, ct.id DESC
+LIMIT $2
 `)).
-		WithArgs(int32(123)).
+		WithArgs(int32(123), int32(20)).
 		WillReturnRows(rows)
 
 	repoInstance := repository.NewInfoRepository(queries, utils.NewLogger())
-	sent, err := repoInstance.GetSentTransfers(context.Background(), 123)
+	sent, err := repoInstance.GetSentTransfers(context.Background(), 123, 20)
 	assert.NoError(t, err)
 
 	expectedSent := []repository.SentTransaction{
-		{ToUser: "Bob", Amount: 40},
-		{ToUser: "David", Amount: 20},
+		{ToUser: "Bob", Amount: 40, Message: "great pairing #help #teamwork", Hashtags: []string{"help", "teamwork"}},
+		{ToUser: "David", Amount: 20, Hashtags: []string{}},
 	}
 	assert.Equal(t, expectedSent, sent)
 
 	err = mockPool.ExpectationsWereMet()
 	assert.NoError(t, err)
 }
+
+func TestInfoRepository_GetPendingOrders_Success(t *testing.T) {
+	mockPool, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mockPool.Close()
+
+	queries := db.New(mockPool)
+
+	placedAt := time.Now()
+	rows := pgxmock.NewRows([]string{"id", "merch_name", "quantity", "status", "created_at"}).
+		AddRow(int32(5), "cup", int32(2), db.OrderStatusEnumPacked, pgtype.Timestamptz{Time: placedAt, Valid: true})
+
+	mockPool.ExpectQuery(`(?s)FROM orders o.*WHERE o.employee_id = \$1 AND o.status NOT IN \('delivered', 'cancelled'\)`).
+		WithArgs(int32(123)).
+		WillReturnRows(rows)
+
+	repoInstance := repository.NewInfoRepository(queries, utils.NewLogger())
+	orders, err := repoInstance.GetPendingOrders(context.Background(), 123)
+	assert.NoError(t, err)
+	assert.Equal(t, []repository.PendingOrder{
+		{ID: 5, Item: "cup", Quantity: 2, Status: "packed", PlacedAt: placedAt},
+	}, orders)
+
+	assert.NoError(t, mockPool.ExpectationsWereMet())
+}
+
+func TestInfoRepository_GetCoinAdjustments_Success(t *testing.T) {
+	mockPool, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mockPool.Close()
+
+	queries := db.New(mockPool)
+
+	createdAt := time.Now()
+	rows := pgxmock.NewRows([]string{"transaction_type", "amount", "reason", "created_at"}).
+		AddRow(db.TransactionTypeEnumGrant, int32(200), "hackathon winner", pgtype.Timestamptz{Time: createdAt, Valid: true}).
+		AddRow(db.TransactionTypeEnumAdjustment, int32(-20), "duplicate grant", pgtype.Timestamptz{Time: createdAt, Valid: true})
+
+	mockPool.ExpectQuery(`(?s)FROM coin_transactions ct.*ct.transaction_type IN \('grant', 'allowance', 'hold_release', 'hold_refund'\) AND ct.to_employee_id = \$1::integer.*ct.transaction_type IN \('adjustment', 'expiry', 'hold'\) AND ct.from_employee_id = \$1::integer.*LIMIT \$2`).
+		WithArgs(int32(123), int32(20)).
+		WillReturnRows(rows)
+
+	repoInstance := repository.NewInfoRepository(queries, utils.NewLogger())
+	adjustments, err := repoInstance.GetCoinAdjustments(context.Background(), 123, 20)
+	assert.NoError(t, err)
+	assert.Equal(t, []repository.CoinAdjustment{
+		{Type: "grant", Amount: 200, Reason: "hackathon winner", CreatedAt: createdAt},
+		{Type: "adjustment", Amount: -20, Reason: "duplicate grant", CreatedAt: createdAt},
+	}, adjustments)
+
+	assert.NoError(t, mockPool.ExpectationsWereMet())
+}
+
+func TestInfoRepository_GetRecentActivity_Success(t *testing.T) {
+	mockPool, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mockPool.Close()
+
+	queries := db.New(mockPool)
+
+	createdAt := time.Now()
+	rows := pgxmock.NewRows([]string{
+		"id", "transaction_type", "incoming", "amount", "counterparty", "merch_name", "quantity",
+		"message", "hashtags", "reason", "created_at",
+	}).
+		AddRow(int32(12), db.TransactionTypeEnumPurchase, false, int32(160), "", "t-shirt", int32(2),
+			"", []string{}, "", pgtype.Timestamptz{Time: createdAt, Valid: true}).
+		AddRow(int32(11), db.TransactionTypeEnumTransfer, true, int32(50), "alice", "", int32(1),
+			"thanks", []string{}, "", pgtype.Timestamptz{Time: createdAt, Valid: true})
+
+	mockPool.ExpectQuery(`(?s)FROM coin_transactions ct.*LEFT JOIN merch m ON m.id = ct.merch_id.*LIMIT \$11`).
+		WithArgs(int32(123), pgtype.Bool{}, pgtype.Text{}, []string(nil), pgtype.Timestamptz{}, pgtype.Timestamptz{},
+			pgtype.Int4{}, pgtype.Int4{}, pgtype.Timestamptz{}, pgtype.Int4{}, int32(20)).
+		WillReturnRows(rows)
+
+	repoInstance := repository.NewInfoRepository(queries, utils.NewLogger())
+	activity, err := repoInstance.GetRecentActivity(context.Background(), 123, 20)
+	assert.NoError(t, err)
+	assert.Equal(t, []repository.CoinActivity{
+		{ID: 12, Type: "purchase", Amount: 160, Item: "t-shirt", Quantity: 2, Hashtags: []string{}, CreatedAt: createdAt},
+		{ID: 11, Type: "transfer", Incoming: true, Amount: 50, Counterparty: "alice", Quantity: 1,
+			Message: "thanks", Hashtags: []string{}, CreatedAt: createdAt},
+	}, activity)
+
+	assert.NoError(t, mockPool.ExpectationsWereMet())
+}
+
+func TestInfoRepository_GetUpcomingExpirations_Success(t *testing.T) {
+	mockPool, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mockPool.Close()
+
+	expiresOn := time.Date(2026, time.December, 1, 0, 0, 0, 0, time.UTC)
+	rows := pgxmock.NewRows([]string{"expires_on", "amount"}).
+		AddRow(pgtype.Timestamptz{Time: expiresOn, Valid: true}, int32(300))
+
+	mockPool.ExpectQuery(`(?s)FROM coin_lots.*employee_id = \$1::integer.*remaining > 0.*INTERVAL '90 days'`).
+		WithArgs(int32(123)).
+		WillReturnRows(rows)
+
+	repoInstance := repository.NewInfoRepository(db.New(mockPool), utils.NewLogger())
+	expirations, err := repoInstance.GetUpcomingExpirations(context.Background(), 123)
+	assert.NoError(t, err)
+	assert.Equal(t, []repository.CoinExpiration{{Amount: 300, ExpiresAt: expiresOn}}, expirations)
+
+	assert.NoError(t, mockPool.ExpectationsWereMet())
+}
@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/par1ram/merch-store/internal/db"
+	"github.com/par1ram/merch-store/internal/utils"
+)
+
+// LedgerRepository читает книгу двойной записи для проверки балансов.
+// Проводки создаются теми же запросами, что и записи coin_transactions.
+type LedgerRepository interface {
+	ListSystemAccountBalances(ctx context.Context) ([]db.ListSystemAccountBalancesRow, error)
+	ListBalanceMismatches(ctx context.Context) ([]db.ListLedgerBalanceMismatchesRow, error)
+	CountUnbalancedEntries(ctx context.Context) (int64, error)
+}
+
+type ledgerRepository struct {
+	queries *db.Queries
+	logger  utils.Logger
+}
+
+// NewLedgerRepository создаёт репозиторий книги двойной записи.
+func NewLedgerRepository(queries *db.Queries, logger utils.Logger) LedgerRepository {
+	logger.WithFields(utils.LogFields{"component": "ledger_repository"}).Info("LedgerRepository initialized")
+	return &ledgerRepository{
+		queries: queries,
+		logger:  logger.WithFields(utils.LogFields{"component": "ledger_repository"}),
+	}
+}
+
+func (r *ledgerRepository) ListSystemAccountBalances(ctx context.Context) ([]db.ListSystemAccountBalancesRow, error) {
+	rows, err := r.queries.ListSystemAccountBalances(ctx)
+	if err != nil {
+		r.logger.WithFields(utils.LogFields{"error": err}).Error("Failed to list system account balances")
+		return nil, err
+	}
+	return rows, nil
+}
+
+func (r *ledgerRepository) ListBalanceMismatches(ctx context.Context) ([]db.ListLedgerBalanceMismatchesRow, error) {
+	rows, err := r.queries.ListLedgerBalanceMismatches(ctx)
+	if err != nil {
+		r.logger.WithFields(utils.LogFields{"error": err}).Error("Failed to list ledger balance mismatches")
+		return nil, err
+	}
+	return rows, nil
+}
+
+func (r *ledgerRepository) CountUnbalancedEntries(ctx context.Context) (int64, error) {
+	count, err := r.queries.CountUnbalancedJournalEntries(ctx)
+	if err != nil {
+		r.logger.WithFields(utils.LogFields{"error": err}).Error("Failed to count unbalanced journal entries")
+		return 0, err
+	}
+	return count, nil
+}
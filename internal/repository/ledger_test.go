@@ -0,0 +1,70 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/par1ram/merch-store/internal/db"
+	"github.com/par1ram/merch-store/internal/repository"
+	"github.com/par1ram/merch-store/internal/utils"
+)
+
+func TestLedgerRepository_ListSystemAccountBalances(t *testing.T) {
+	mockPool, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mockPool.Close()
+
+	repo := repository.NewLedgerRepository(db.New(mockPool), utils.NewLogger())
+
+	mockPool.ExpectQuery(`(?s)FROM ledger_accounts a.*LEFT JOIN ledger_postings p.*WHERE a.code IS NOT NULL`).
+		WillReturnRows(pgxmock.NewRows([]string{"code", "balance"}).
+			AddRow("issuance", int64(-2000)).
+			AddRow("store_revenue", int64(300)))
+
+	rows, err := repo.ListSystemAccountBalances(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []db.ListSystemAccountBalancesRow{
+		{Code: "issuance", Balance: -2000},
+		{Code: "store_revenue", Balance: 300},
+	}, rows)
+
+	assert.NoError(t, mockPool.ExpectationsWereMet())
+}
+
+func TestLedgerRepository_ListBalanceMismatches(t *testing.T) {
+	mockPool, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mockPool.Close()
+
+	repo := repository.NewLedgerRepository(db.New(mockPool), utils.NewLogger())
+
+	mockPool.ExpectQuery(`(?s)FROM employees e.*LEFT JOIN ledger_accounts a.*HAVING e.coins <> COALESCE\(SUM\(p.amount\), 0\)`).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "username", "coins", "ledger_balance"}).
+			AddRow(int32(4), "dave", int32(950), int64(1000)))
+
+	rows, err := repo.ListBalanceMismatches(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []db.ListLedgerBalanceMismatchesRow{{ID: 4, Username: "dave", Coins: 950, LedgerBalance: 1000}}, rows)
+
+	assert.NoError(t, mockPool.ExpectationsWereMet())
+}
+
+func TestLedgerRepository_CountUnbalancedEntries(t *testing.T) {
+	mockPool, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mockPool.Close()
+
+	repo := repository.NewLedgerRepository(db.New(mockPool), utils.NewLogger())
+
+	mockPool.ExpectQuery(`(?s)FROM ledger_postings.*GROUP BY entry_id.*HAVING SUM\(amount\) <> 0`).
+		WillReturnRows(pgxmock.NewRows([]string{"count"}).AddRow(int64(0)))
+
+	count, err := repo.CountUnbalancedEntries(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), count)
+
+	assert.NoError(t, mockPool.ExpectationsWereMet())
+}
@@ -0,0 +1,163 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/par1ram/merch-store/internal/db"
+	"github.com/par1ram/merch-store/internal/utils"
+)
+
+// ErrMerchNameTaken возвращается, когда товар с таким названием уже существует.
+var ErrMerchNameTaken = errors.New("merch name already taken")
+
+// uniqueViolationCode — код ошибки Postgres при нарушении уникальности.
+const uniqueViolationCode = "23505"
+
+type MerchRepository interface {
+	ListMerch(ctx context.Context) ([]db.Merch, error)
+	ListAllMerch(ctx context.Context) ([]db.Merch, error)
+	GetMerchByID(ctx context.Context, id int32) (db.Merch, error)
+	CreateMerch(ctx context.Context, params db.CreateMerchParams) (db.Merch, error)
+	UpdateMerch(ctx context.Context, params db.UpdateMerchParams) (db.Merch, error)
+	RetireMerch(ctx context.Context, id int32) (db.Merch, error)
+	GetBalance(ctx context.Context, userID int32) (int32, error)
+}
+
+type merchRepository struct {
+	queries *db.Queries
+	logger  utils.Logger
+}
+
+// NewMerchRepository создаёт репозиторий каталога мерча.
+func NewMerchRepository(queries *db.Queries, logger utils.Logger) MerchRepository {
+	logger.WithFields(utils.LogFields{"component": "merch_repository"}).Info("MerchRepository initialized")
+	return &merchRepository{
+		queries: queries,
+		logger:  logger.WithFields(utils.LogFields{"component": "merch_repository"}),
+	}
+}
+
+func (r *merchRepository) ListMerch(ctx context.Context) ([]db.Merch, error) {
+	log := r.logger.WithFields(utils.LogFields{"operation": "list_merch"})
+
+	items, err := r.queries.ListMerch(ctx)
+	if err != nil {
+		log.WithFields(utils.LogFields{"error": err}).Error("merch list retrieval failed")
+		return nil, fmt.Errorf("list merch failed: %w", err)
+	}
+
+	log.WithFields(utils.LogFields{"item_count": len(items)}).Debug("merch list retrieved")
+	return items, nil
+}
+
+func (r *merchRepository) ListAllMerch(ctx context.Context) ([]db.Merch, error) {
+	log := r.logger.WithFields(utils.LogFields{"operation": "list_all_merch"})
+
+	items, err := r.queries.ListAllMerch(ctx)
+	if err != nil {
+		log.WithFields(utils.LogFields{"error": err}).Error("full merch list retrieval failed")
+		return nil, fmt.Errorf("list all merch failed: %w", err)
+	}
+
+	log.WithFields(utils.LogFields{"item_count": len(items)}).Debug("full merch list retrieved")
+	return items, nil
+}
+
+func (r *merchRepository) GetMerchByID(ctx context.Context, id int32) (db.Merch, error) {
+	log := r.logger.WithFields(utils.LogFields{
+		"operation": "get_merch_by_id",
+		"merch_id":  id,
+	})
+
+	merch, err := r.queries.GetMerchByID(ctx, id)
+	if err != nil {
+		log.WithFields(utils.LogFields{"error": err}).Error("merch lookup failed")
+		return db.Merch{}, fmt.Errorf("get merch failed: %w", err)
+	}
+
+	log.Debug("merch found")
+	return merch, nil
+}
+
+func (r *merchRepository) CreateMerch(ctx context.Context, params db.CreateMerchParams) (db.Merch, error) {
+	log := r.logger.WithFields(utils.LogFields{
+		"operation": "create_merch",
+		"name":      params.Name,
+		"price":     params.Price,
+	})
+
+	merch, err := r.queries.CreateMerch(ctx, params)
+	if err != nil {
+		log.WithFields(utils.LogFields{"error": err}).Error("merch creation failed")
+		if isUniqueViolation(err) {
+			return db.Merch{}, ErrMerchNameTaken
+		}
+		return db.Merch{}, fmt.Errorf("create merch failed: %w", err)
+	}
+
+	log.WithFields(utils.LogFields{"merch_id": merch.ID}).Info("merch created")
+	return merch, nil
+}
+
+func (r *merchRepository) UpdateMerch(ctx context.Context, params db.UpdateMerchParams) (db.Merch, error) {
+	log := r.logger.WithFields(utils.LogFields{
+		"operation": "update_merch",
+		"merch_id":  params.ID,
+	})
+
+	merch, err := r.queries.UpdateMerch(ctx, params)
+	if err != nil {
+		log.WithFields(utils.LogFields{"error": err}).Error("merch update failed")
+		if isUniqueViolation(err) {
+			return db.Merch{}, ErrMerchNameTaken
+		}
+		return db.Merch{}, fmt.Errorf("update merch failed: %w", err)
+	}
+
+	log.WithFields(utils.LogFields{
+		"name":  merch.Name,
+		"price": merch.Price,
+	}).Info("merch updated")
+	return merch, nil
+}
+
+func (r *merchRepository) RetireMerch(ctx context.Context, id int32) (db.Merch, error) {
+	log := r.logger.WithFields(utils.LogFields{
+		"operation": "retire_merch",
+		"merch_id":  id,
+	})
+
+	merch, err := r.queries.RetireMerch(ctx, id)
+	if err != nil {
+		log.WithFields(utils.LogFields{"error": err}).Error("merch retirement failed")
+		return db.Merch{}, fmt.Errorf("retire merch failed: %w", err)
+	}
+
+	log.Info("merch retired")
+	return merch, nil
+}
+
+func (r *merchRepository) GetBalance(ctx context.Context, userID int32) (int32, error) {
+	log := r.logger.WithFields(utils.LogFields{
+		"operation": "get_balance",
+		"user_id":   userID,
+	})
+
+	balance, err := r.queries.GetCoinsByID(ctx, userID)
+	if err != nil {
+		log.WithFields(utils.LogFields{"error": err}).Error("balance check failed")
+		return 0, fmt.Errorf("get balance failed: %w", err)
+	}
+
+	log.WithFields(utils.LogFields{"balance": balance}).Debug("balance retrieved")
+	return balance, nil
+}
+
+// isUniqueViolation проверяет, что ошибка вызвана нарушением ограничения уникальности.
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == uniqueViolationCode
+}
@@ -0,0 +1,169 @@
+package repository_test
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/par1ram/merch-store/internal/db"
+	"github.com/par1ram/merch-store/internal/repository"
+	"github.com/par1ram/merch-store/internal/utils"
+)
+
+var merchColumns = []string{"id", "name", "price", "active", "retired_at", "stock"}
+
+func TestMerchRepository_ListMerch_Success(t *testing.T) {
+	mockPool, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mockPool.Close()
+
+	queries := db.New(mockPool)
+
+	rows := pgxmock.NewRows(merchColumns).
+		AddRow(int32(2), "cup", int32(20), true, pgtype.Timestamptz{}, pgtype.Int4{}).
+		AddRow(int32(1), "t-shirt", int32(80), true, pgtype.Timestamptz{}, pgtype.Int4{})
+
+	mockPool.ExpectQuery(regexp.QuoteMeta(`SELECT id, name, price, active, retired_at, stock FROM merch WHERE active ORDER BY name`)).
+		WillReturnRows(rows)
+
+	repo := repository.NewMerchRepository(queries, utils.NewLogger())
+	items, err := repo.ListMerch(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []db.Merch{
+		{ID: 2, Name: "cup", Price: 20, Active: true},
+		{ID: 1, Name: "t-shirt", Price: 80, Active: true},
+	}, items)
+
+	assert.NoError(t, mockPool.ExpectationsWereMet())
+}
+
+func TestMerchRepository_ListMerch_Error(t *testing.T) {
+	mockPool, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mockPool.Close()
+
+	queries := db.New(mockPool)
+
+	mockPool.ExpectQuery(regexp.QuoteMeta(`SELECT id, name, price, active, retired_at, stock FROM merch WHERE active ORDER BY name`)).
+		WillReturnError(assert.AnError)
+
+	repo := repository.NewMerchRepository(queries, utils.NewLogger())
+	items, err := repo.ListMerch(context.Background())
+	assert.Error(t, err)
+	assert.Nil(t, items)
+
+	assert.NoError(t, mockPool.ExpectationsWereMet())
+}
+
+func TestMerchRepository_GetBalance_Success(t *testing.T) {
+	mockPool, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mockPool.Close()
+
+	queries := db.New(mockPool)
+
+	rows := pgxmock.NewRows([]string{"coins"}).AddRow(int32(300))
+	mockPool.ExpectQuery(`(?s).*SELECT coins FROM employees WHERE id=\$1.*`).
+		WithArgs(int32(7)).
+		WillReturnRows(rows)
+
+	repo := repository.NewMerchRepository(queries, utils.NewLogger())
+	balance, err := repo.GetBalance(context.Background(), 7)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(300), balance)
+
+	assert.NoError(t, mockPool.ExpectationsWereMet())
+}
+
+func TestMerchRepository_CreateMerch_Success(t *testing.T) {
+	mockPool, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mockPool.Close()
+
+	queries := db.New(mockPool)
+
+	rows := pgxmock.NewRows(merchColumns).
+		AddRow(int32(11), "sticker", int32(5), true, pgtype.Timestamptz{}, pgtype.Int4{})
+	mockPool.ExpectQuery(regexp.QuoteMeta(`INSERT INTO merch (name, price, stock) VALUES ($1, $2, $3)`)).
+		WithArgs("sticker", int32(5), pgtype.Int4{}).
+		WillReturnRows(rows)
+
+	repo := repository.NewMerchRepository(queries, utils.NewLogger())
+	merch, err := repo.CreateMerch(context.Background(), db.CreateMerchParams{Name: "sticker", Price: 5})
+	assert.NoError(t, err)
+	assert.Equal(t, db.Merch{ID: 11, Name: "sticker", Price: 5, Active: true}, merch)
+
+	assert.NoError(t, mockPool.ExpectationsWereMet())
+}
+
+func TestMerchRepository_CreateMerch_DuplicateName(t *testing.T) {
+	mockPool, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mockPool.Close()
+
+	queries := db.New(mockPool)
+
+	mockPool.ExpectQuery(regexp.QuoteMeta(`INSERT INTO merch (name, price, stock) VALUES ($1, $2, $3)`)).
+		WithArgs("cup", int32(20), pgtype.Int4{}).
+		WillReturnError(&pgconn.PgError{Code: "23505"})
+
+	repo := repository.NewMerchRepository(queries, utils.NewLogger())
+	_, err = repo.CreateMerch(context.Background(), db.CreateMerchParams{Name: "cup", Price: 20})
+	assert.ErrorIs(t, err, repository.ErrMerchNameTaken)
+
+	assert.NoError(t, mockPool.ExpectationsWereMet())
+}
+
+func TestMerchRepository_UpdateMerch_Success(t *testing.T) {
+	mockPool, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mockPool.Close()
+
+	queries := db.New(mockPool)
+
+	params := db.UpdateMerchParams{
+		Price: pgtype.Int4{Int32: 25, Valid: true},
+		ID:    2,
+	}
+	rows := pgxmock.NewRows(merchColumns).
+		AddRow(int32(2), "cup", int32(25), true, pgtype.Timestamptz{}, pgtype.Int4{})
+	mockPool.ExpectQuery(`(?s)UPDATE merch.*COALESCE`).
+		WithArgs(params.Name, params.Price, params.SetStock, params.Stock, params.ID).
+		WillReturnRows(rows)
+
+	repo := repository.NewMerchRepository(queries, utils.NewLogger())
+	merch, err := repo.UpdateMerch(context.Background(), params)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(25), merch.Price)
+
+	assert.NoError(t, mockPool.ExpectationsWereMet())
+}
+
+func TestMerchRepository_RetireMerch_Success(t *testing.T) {
+	mockPool, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mockPool.Close()
+
+	queries := db.New(mockPool)
+
+	retiredAt := pgtype.Timestamptz{Time: time.Now(), Valid: true}
+	rows := pgxmock.NewRows(merchColumns).
+		AddRow(int32(10), "pink-hoody", int32(500), false, retiredAt, pgtype.Int4{})
+	mockPool.ExpectQuery(`(?s)UPDATE merch.*SET.*active = FALSE.*WHERE id = \$1 AND active`).
+		WithArgs(int32(10)).
+		WillReturnRows(rows)
+
+	repo := repository.NewMerchRepository(queries, utils.NewLogger())
+	merch, err := repo.RetireMerch(context.Background(), 10)
+	assert.NoError(t, err)
+	assert.False(t, merch.Active)
+	assert.True(t, merch.RetiredAt.Valid)
+
+	assert.NoError(t, mockPool.ExpectationsWereMet())
+}
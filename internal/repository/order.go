@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/par1ram/merch-store/internal/db"
+	"github.com/par1ram/merch-store/internal/utils"
+)
+
+// OrderRepository обслуживает заказы на выдачу купленного товара.
+// Смена статуса выполняется через ExecTx, чтобы проверка перехода и
+// обновление видели одно и то же состояние заказа.
+type OrderRepository interface {
+	ExecTx(ctx context.Context, fn func(OrderRepository) error) error
+	ListEmployeeOrders(ctx context.Context, params db.ListOrdersByEmployeeParams) ([]db.ListOrdersByEmployeeRow, error)
+	GetEmployeeOrder(ctx context.Context, params db.GetOrderByEmployeeParams) (db.GetOrderByEmployeeRow, error)
+	ListOrders(ctx context.Context, status db.NullOrderStatusEnum) ([]db.ListOrdersRow, error)
+	GetOrderStatusForUpdate(ctx context.Context, orderID int32) (db.OrderStatusEnum, error)
+	UpdateOrderStatus(ctx context.Context, params db.UpdateOrderStatusParams) (db.UpdateOrderStatusRow, error)
+}
+
+type orderRepository struct {
+	tx      TxRunner
+	queries *db.Queries
+	logger  utils.Logger
+}
+
+func NewOrderRepository(tx TxRunner, queries *db.Queries, logger utils.Logger) OrderRepository {
+	logger.WithFields(utils.LogFields{"component": "order_repository"}).Info("OrderRepository initialized")
+	return &orderRepository{
+		tx:      tx,
+		queries: queries,
+		logger:  logger.WithFields(utils.LogFields{"component": "order_repository"}),
+	}
+}
+
+func (r *orderRepository) ExecTx(ctx context.Context, fn func(OrderRepository) error) error {
+	return r.tx.Run(ctx, func(tx pgx.Tx) error {
+		return fn(&orderRepository{
+			tx:      r.tx,
+			queries: r.queries.WithTx(tx),
+			logger:  r.logger,
+		})
+	})
+}
+
+func (r *orderRepository) ListEmployeeOrders(ctx context.Context, params db.ListOrdersByEmployeeParams) ([]db.ListOrdersByEmployeeRow, error) {
+	orders, err := r.queries.ListOrdersByEmployee(ctx, params)
+	if err != nil {
+		r.logger.WithFields(utils.LogFields{"error": err, "employee_id": params.EmployeeID}).Error("Failed to list employee orders")
+		return nil, fmt.Errorf("list employee orders failed: %w", err)
+	}
+	return orders, nil
+}
+
+func (r *orderRepository) GetEmployeeOrder(ctx context.Context, params db.GetOrderByEmployeeParams) (db.GetOrderByEmployeeRow, error) {
+	order, err := r.queries.GetOrderByEmployee(ctx, params)
+	if err != nil {
+		r.logger.WithFields(utils.LogFields{"error": err, "order_id": params.ID, "employee_id": params.EmployeeID}).Error("Failed to get order")
+		return order, err
+	}
+	return order, nil
+}
+
+func (r *orderRepository) ListOrders(ctx context.Context, status db.NullOrderStatusEnum) ([]db.ListOrdersRow, error) {
+	orders, err := r.queries.ListOrders(ctx, status)
+	if err != nil {
+		r.logger.WithFields(utils.LogFields{"error": err}).Error("Failed to list orders")
+		return nil, fmt.Errorf("list orders failed: %w", err)
+	}
+	return orders, nil
+}
+
+func (r *orderRepository) GetOrderStatusForUpdate(ctx context.Context, orderID int32) (db.OrderStatusEnum, error) {
+	status, err := r.queries.GetOrderStatusForUpdate(ctx, orderID)
+	if err != nil {
+		r.logger.WithFields(utils.LogFields{"error": err, "order_id": orderID}).Error("Failed to get order status")
+		return status, err
+	}
+	return status, nil
+}
+
+func (r *orderRepository) UpdateOrderStatus(ctx context.Context, params db.UpdateOrderStatusParams) (db.UpdateOrderStatusRow, error) {
+	order, err := r.queries.UpdateOrderStatus(ctx, params)
+	if err != nil {
+		r.logger.WithFields(utils.LogFields{"error": err, "order_id": params.ID, "status": params.Status}).Error("Failed to update order status")
+		return order, err
+	}
+	return order, nil
+}
@@ -0,0 +1,100 @@
+package repository_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/par1ram/merch-store/internal/db"
+	"github.com/par1ram/merch-store/internal/repository"
+	"github.com/par1ram/merch-store/internal/utils"
+)
+
+func TestOrderRepository_UpdateStatusInTx_Success(t *testing.T) {
+	mockPool, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mockPool.Close()
+
+	queries := db.New(mockPool)
+	repo := repository.NewOrderRepository(newTxRunner(mockPool), queries, utils.NewLogger())
+
+	now := pgtype.Timestamptz{Time: time.Now(), Valid: true}
+	mockPool.ExpectBegin()
+	mockPool.ExpectQuery(`SELECT status FROM orders WHERE id = \$1 FOR UPDATE`).
+		WithArgs(int32(4)).
+		WillReturnRows(pgxmock.NewRows([]string{"status"}).AddRow(db.OrderStatusEnumPlaced))
+	mockPool.ExpectQuery(`(?s)WITH updated AS \(.*UPDATE orders.*SET status = \$1, updated_at = NOW\(\).*WHERE orders.id = \$2`).
+		WithArgs(db.OrderStatusEnumPacked, int32(4)).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "purchase_id", "username", "merch_name", "quantity", "status", "created_at", "updated_at"}).
+			AddRow(int32(4), int32(10), "alice", "cup", int32(2), db.OrderStatusEnumPacked, now, now))
+	mockPool.ExpectCommit()
+
+	var order db.UpdateOrderStatusRow
+	err = repo.ExecTx(context.Background(), func(r repository.OrderRepository) error {
+		status, err := r.GetOrderStatusForUpdate(context.Background(), 4)
+		if err != nil {
+			return err
+		}
+		assert.Equal(t, db.OrderStatusEnumPlaced, status)
+
+		order, err = r.UpdateOrderStatus(context.Background(), db.UpdateOrderStatusParams{
+			Status: db.OrderStatusEnumPacked,
+			ID:     4,
+		})
+		return err
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "alice", order.Username)
+	assert.Equal(t, db.OrderStatusEnumPacked, order.Status)
+
+	assert.NoError(t, mockPool.ExpectationsWereMet())
+}
+
+func TestOrderRepository_ListEmployeeOrders_Success(t *testing.T) {
+	mockPool, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mockPool.Close()
+
+	queries := db.New(mockPool)
+	repo := repository.NewOrderRepository(newTxRunner(mockPool), queries, utils.NewLogger())
+
+	now := pgtype.Timestamptz{Time: time.Now(), Valid: true}
+	params := db.ListOrdersByEmployeeParams{
+		EmployeeID: 7,
+		Status:     db.NullOrderStatusEnum{OrderStatusEnum: db.OrderStatusEnumReadyForPickup, Valid: true},
+	}
+	mockPool.ExpectQuery(`(?s)FROM orders o.*WHERE o.employee_id = \$1.*ORDER BY o.id DESC`).
+		WithArgs(params.EmployeeID, params.Status).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "purchase_id", "merch_name", "quantity", "status", "created_at", "updated_at"}).
+			AddRow(int32(3), int32(9), "hoody", int32(1), db.OrderStatusEnumReadyForPickup, now, now))
+
+	orders, err := repo.ListEmployeeOrders(context.Background(), params)
+	assert.NoError(t, err)
+	assert.Len(t, orders, 1)
+	assert.Equal(t, "hoody", orders[0].MerchName)
+
+	assert.NoError(t, mockPool.ExpectationsWereMet())
+}
+
+func TestOrderRepository_GetEmployeeOrder_NotFound(t *testing.T) {
+	mockPool, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mockPool.Close()
+
+	queries := db.New(mockPool)
+	repo := repository.NewOrderRepository(newTxRunner(mockPool), queries, utils.NewLogger())
+
+	mockPool.ExpectQuery(`(?s)FROM orders o.*WHERE o.id = \$1 AND o.employee_id = \$2`).
+		WithArgs(int32(3), int32(8)).
+		WillReturnError(sql.ErrNoRows)
+
+	_, err = repo.GetEmployeeOrder(context.Background(), db.GetOrderByEmployeeParams{ID: 3, EmployeeID: 8})
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+
+	assert.NoError(t, mockPool.ExpectationsWereMet())
+}
@@ -0,0 +1,133 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/par1ram/merch-store/internal/db"
+	"github.com/par1ram/merch-store/internal/utils"
+)
+
+// PendingTransferRepository обслуживает удержанные переводы после их создания:
+// принятие, отказ и возврат по истечении срока. Удержания создаёт SendCoinRepository.HoldCoins.
+type PendingTransferRepository interface {
+	ExecTx(ctx context.Context, fn func(PendingTransferRepository) error) error
+	GetTransferHoldForUpdate(ctx context.Context, id int32) (db.TransferHold, error)
+	ListExpiredTransferHolds(ctx context.Context, batchSize int32) ([]db.TransferHold, error)
+	ListTransferHolds(ctx context.Context, params db.ListTransferHoldsParams) ([]db.ListTransferHoldsRow, error)
+	SettleHold(ctx context.Context, hold db.TransferHold, status db.TransferHoldStatusEnum) error
+}
+
+type pendingTransferRepository struct {
+	tx      TxRunner
+	queries *db.Queries
+	logger  utils.Logger
+}
+
+func NewPendingTransferRepository(tx TxRunner, queries *db.Queries, logger utils.Logger) PendingTransferRepository {
+	logger.WithFields(utils.LogFields{"component": "pending_transfer_repository"}).Info("PendingTransferRepository initialized")
+	return &pendingTransferRepository{
+		tx:      tx,
+		queries: queries,
+		logger:  logger.WithFields(utils.LogFields{"component": "pending_transfer_repository"}),
+	}
+}
+
+func (r *pendingTransferRepository) ExecTx(ctx context.Context, fn func(PendingTransferRepository) error) error {
+	return r.tx.Run(ctx, func(tx pgx.Tx) error {
+		return fn(&pendingTransferRepository{
+			tx:      r.tx,
+			queries: r.queries.WithTx(tx),
+			logger:  r.logger,
+		})
+	})
+}
+
+func (r *pendingTransferRepository) GetTransferHoldForUpdate(ctx context.Context, id int32) (db.TransferHold, error) {
+	hold, err := r.queries.GetTransferHoldForUpdate(ctx, id)
+	if err != nil {
+		r.logger.WithFields(utils.LogFields{"error": err, "hold_id": id}).Error("Failed to lock transfer hold")
+		return hold, err
+	}
+	return hold, nil
+}
+
+func (r *pendingTransferRepository) ListExpiredTransferHolds(ctx context.Context, batchSize int32) ([]db.TransferHold, error) {
+	holds, err := r.queries.ListExpiredTransferHolds(ctx, batchSize)
+	if err != nil {
+		r.logger.WithFields(utils.LogFields{"error": err}).Error("Failed to list expired transfer holds")
+		return nil, err
+	}
+	return holds, nil
+}
+
+func (r *pendingTransferRepository) ListTransferHolds(ctx context.Context, params db.ListTransferHoldsParams) ([]db.ListTransferHoldsRow, error) {
+	rows, err := r.queries.ListTransferHolds(ctx, params)
+	if err != nil {
+		r.logger.WithFields(utils.LogFields{"error": err}).Error("Failed to list transfer holds")
+		return nil, err
+	}
+	return rows, nil
+}
+
+// SettleHold закрывает удержание со статусом status: принятое зачисляется
+// получателю, отклонённое или просроченное возвращается отправителю. Монеты
+// зачисляются частями исходных партий с их сроками действия.
+func (r *pendingTransferRepository) SettleHold(ctx context.Context, hold db.TransferHold, status db.TransferHoldStatusEnum) error {
+	toUserID := hold.SenderID
+	if status == db.TransferHoldStatusEnumAccepted {
+		toUserID = hold.RecipientID
+	}
+	log := r.logger.WithFields(utils.LogFields{
+		"operation":  "settle_hold",
+		"hold_id":    hold.ID,
+		"status":     status,
+		"to_user_id": toUserID,
+	})
+
+	heldLots, err := r.queries.ListTransferHoldLots(ctx, hold.ID)
+	if err != nil {
+		log.WithFields(utils.LogFields{"error": err}).Error("Failed to get held lots")
+		return fmt.Errorf("get held lots failed: %w", err)
+	}
+	lots := make([]db.ConsumeCoinLotsRow, 0, len(heldLots))
+	for _, lot := range heldLots {
+		lots = append(lots, db.ConsumeCoinLotsRow{Taken: lot.Amount, GrantedAt: lot.GrantedAt, ExpiresAt: lot.ExpiresAt})
+	}
+
+	if err := depositCoins(ctx, r.queries, toUserID, hold.Amount); err != nil {
+		log.WithFields(utils.LogFields{"error": err}).Error("Deposit failed")
+		return fmt.Errorf("deposit failed: %w", err)
+	}
+	if err := moveCoinLots(ctx, r.queries, toUserID, hold.Amount, lots); err != nil {
+		log.WithFields(utils.LogFields{"error": err}).Error("Deposit failed")
+		return fmt.Errorf("deposit failed: %w", err)
+	}
+
+	if status == db.TransferHoldStatusEnumAccepted {
+		err = r.queries.CreateCoinTransactionHoldRelease(ctx, db.CreateCoinTransactionHoldReleaseParams{
+			ToEmployeeID: toUserID,
+			Amount:       hold.Amount,
+			HoldID:       hold.ID,
+		})
+	} else {
+		err = r.queries.CreateCoinTransactionHoldRefund(ctx, db.CreateCoinTransactionHoldRefundParams{
+			ToEmployeeID: toUserID,
+			Amount:       hold.Amount,
+			HoldID:       hold.ID,
+		})
+	}
+	if err != nil {
+		log.WithFields(utils.LogFields{"error": err}).Error("Transaction record creation failed")
+		return fmt.Errorf("transaction record failed: %w", err)
+	}
+
+	if err := r.queries.ResolveTransferHold(ctx, db.ResolveTransferHoldParams{ID: hold.ID, Status: status}); err != nil {
+		log.WithFields(utils.LogFields{"error": err}).Error("Failed to resolve transfer hold")
+		return fmt.Errorf("resolve hold failed: %w", err)
+	}
+
+	log.Info("Transfer hold settled")
+	return nil
+}
@@ -0,0 +1,145 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/par1ram/merch-store/internal/db"
+	"github.com/par1ram/merch-store/internal/repository"
+	"github.com/par1ram/merch-store/internal/utils"
+)
+
+var holdColumns = []string{
+	"id", "sender_id", "recipient_id", "amount", "message", "hashtags", "status", "created_at", "expires_at", "resolved_at",
+}
+
+func TestSendCoinRepository_HoldCoins_Success(t *testing.T) {
+	mockPool, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mockPool.Close()
+
+	repo := repository.NewSendCoinRepository(newTxRunner(mockPool), db.New(mockPool), utils.NewLogger())
+
+	granted := pgtype.Timestamptz{Time: time.Date(2026, time.January, 10, 0, 0, 0, 0, time.UTC), Valid: true}
+	lotExpires := pgtype.Timestamptz{Time: time.Date(2027, time.January, 10, 0, 0, 0, 0, time.UTC), Valid: true}
+	holdExpires := time.Date(2026, time.October, 24, 0, 0, 0, 0, time.UTC)
+	message := pgtype.Text{String: "for the demo #teamwork", Valid: true}
+
+	mockPool.ExpectExec(`(?s)UPDATE employees\s+SET coins = coins \+ \$2`).
+		WithArgs(int32(1), int32(-40)).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mockPool.ExpectQuery(`(?s)WITH locked AS.*FROM coin_lots.*FOR UPDATE`).
+		WithArgs(int32(1), int32(40)).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "taken", "granted_at", "expires_at"}).
+			AddRow(int32(10), int32(40), granted, lotExpires))
+	mockPool.ExpectQuery(`(?s)INSERT INTO transfer_holds \(sender_id, recipient_id, amount, message, hashtags, expires_at\)`).
+		WithArgs(int32(1), int32(2), int32(40), message, []string{"teamwork"},
+			pgtype.Timestamptz{Time: holdExpires, Valid: true}).
+		WillReturnRows(pgxmock.NewRows(holdColumns).
+			AddRow(int32(7), int32(1), int32(2), int32(40), message, []string{"teamwork"}, db.TransferHoldStatusEnumPending,
+				pgtype.Timestamptz{}, pgtype.Timestamptz{Time: holdExpires, Valid: true}, pgtype.Timestamptz{}))
+	mockPool.ExpectExec(`(?s)INSERT INTO transfer_hold_lots`).
+		WithArgs(int32(7), int32(40), granted, lotExpires).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	mockPool.ExpectExec(`(?s)INSERT INTO coin_transactions .*'hold'.*INSERT INTO journal_entries.*code = 'escrow'`).
+		WithArgs(int32(1), int32(40), message, []string{"teamwork"}, int32(7)).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+
+	hold, err := repo.HoldCoins(context.Background(), 1, 2, 40, repository.TransferMemo{
+		Message:  message.String,
+		Hashtags: []string{"teamwork"},
+	}, holdExpires)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(7), hold.ID)
+	assert.Equal(t, db.TransferHoldStatusEnumPending, hold.Status)
+
+	assert.NoError(t, mockPool.ExpectationsWereMet())
+}
+
+func TestSendCoinRepository_HoldCoins_InsufficientBalance(t *testing.T) {
+	mockPool, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mockPool.Close()
+
+	repo := repository.NewSendCoinRepository(newTxRunner(mockPool), db.New(mockPool), utils.NewLogger())
+
+	mockPool.ExpectExec(`(?s)UPDATE employees\s+SET coins = coins \+ \$2`).
+		WithArgs(int32(1), int32(-40)).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+
+	_, err = repo.HoldCoins(context.Background(), 1, 2, 40, repository.TransferMemo{}, time.Now())
+	assert.ErrorIs(t, err, repository.ErrInsufficientBalance)
+
+	assert.NoError(t, mockPool.ExpectationsWereMet())
+}
+
+func TestPendingTransferRepository_SettleHold_Accepted(t *testing.T) {
+	mockPool, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mockPool.Close()
+
+	repo := repository.NewPendingTransferRepository(newTxRunner(mockPool), db.New(mockPool), utils.NewLogger())
+
+	granted := pgtype.Timestamptz{Time: time.Date(2026, time.January, 10, 0, 0, 0, 0, time.UTC), Valid: true}
+	lotExpires := pgtype.Timestamptz{Time: time.Date(2027, time.January, 10, 0, 0, 0, 0, time.UTC), Valid: true}
+	hold := db.TransferHold{ID: 7, SenderID: 1, RecipientID: 2, Amount: 40, Status: db.TransferHoldStatusEnumPending}
+
+	mockPool.ExpectQuery(`(?s)FROM transfer_hold_lots\s+WHERE hold_id = \$1::integer`).
+		WithArgs(int32(7)).
+		WillReturnRows(pgxmock.NewRows([]string{"amount", "granted_at", "expires_at"}).
+			AddRow(int32(40), granted, lotExpires))
+	mockPool.ExpectExec(`(?s)UPDATE employees\s+SET coins = coins \+ \$2`).
+		WithArgs(int32(2), int32(40)).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mockPool.ExpectExec(`(?s)INSERT INTO coin_lots \(employee_id, amount, remaining, granted_at, expires_at\)`).
+		WithArgs(int32(2), int32(40), granted, lotExpires).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	mockPool.ExpectExec(`(?s)INSERT INTO coin_transactions .*'hold_release'`).
+		WithArgs(int32(2), int32(40), int32(7)).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	mockPool.ExpectExec(`(?s)UPDATE transfer_holds.*WHERE id = \$2 AND status = 'pending'`).
+		WithArgs(db.TransferHoldStatusEnumAccepted, int32(7)).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+
+	err = repo.SettleHold(context.Background(), hold, db.TransferHoldStatusEnumAccepted)
+	assert.NoError(t, err)
+
+	assert.NoError(t, mockPool.ExpectationsWereMet())
+}
+
+func TestPendingTransferRepository_SettleHold_RefundsSender(t *testing.T) {
+	mockPool, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mockPool.Close()
+
+	repo := repository.NewPendingTransferRepository(newTxRunner(mockPool), db.New(mockPool), utils.NewLogger())
+
+	hold := db.TransferHold{ID: 7, SenderID: 1, RecipientID: 2, Amount: 40, Status: db.TransferHoldStatusEnumPending}
+
+	// Партий в удержании нет — возврат зачисляется новой партией.
+	mockPool.ExpectQuery(`(?s)FROM transfer_hold_lots`).
+		WithArgs(int32(7)).
+		WillReturnRows(pgxmock.NewRows([]string{"amount", "granted_at", "expires_at"}))
+	mockPool.ExpectExec(`(?s)UPDATE employees\s+SET coins = coins \+ \$2`).
+		WithArgs(int32(1), int32(40)).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mockPool.ExpectExec(`(?s)INSERT INTO coin_lots`).
+		WithArgs(int32(1), int32(40)).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	mockPool.ExpectExec(`(?s)INSERT INTO coin_transactions .*'hold_refund'`).
+		WithArgs(int32(1), int32(40), int32(7)).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	mockPool.ExpectExec(`(?s)UPDATE transfer_holds`).
+		WithArgs(db.TransferHoldStatusEnumExpired, int32(7)).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+
+	err = repo.SettleHold(context.Background(), hold, db.TransferHoldStatusEnumExpired)
+	assert.NoError(t, err)
+
+	assert.NoError(t, mockPool.ExpectationsWereMet())
+}
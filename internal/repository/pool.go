@@ -7,5 +7,5 @@ import (
 )
 
 type PoolIface interface {
-	Begin(ctx context.Context) (pgx.Tx, error)
+	BeginTx(ctx context.Context, txOptions pgx.TxOptions) (pgx.Tx, error)
 }
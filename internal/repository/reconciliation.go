@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/par1ram/merch-store/internal/db"
+	"github.com/par1ram/merch-store/internal/utils"
+)
+
+// ReconciliationRepository сверяет балансы сотрудников с историей операций
+// и с партиями монет и записывает компенсирующие транзакции. Исправление
+// выполняется целиком в одной транзакции через ExecTx. Перед записью
+// компенсаций строки сотрудников блокируются через LockBalances: партии
+// меняются в том же порядке блокировок, что и при остальных операциях.
+type ReconciliationRepository interface {
+	ExecTx(ctx context.Context, fn func(ReconciliationRepository) error) error
+	ListBalanceDrift(ctx context.Context) ([]db.ListBalanceDriftRow, error)
+	ListCoinLotDrift(ctx context.Context) ([]db.ListCoinLotDriftRow, error)
+	GetEmployee(ctx context.Context, username string) (db.Employee, error)
+	LockBalances(ctx context.Context, userIDs ...int32) (map[int32]int32, error)
+	CreateGrantTransaction(ctx context.Context, params db.CreateCoinTransactionGrantParams) (int32, error)
+	CreateAdjustmentTransaction(ctx context.Context, params db.CreateCoinTransactionAdjustmentParams) (int32, error)
+}
+
+type reconciliationRepository struct {
+	tx      TxRunner
+	queries *db.Queries
+	logger  utils.Logger
+}
+
+func NewReconciliationRepository(tx TxRunner, queries *db.Queries, logger utils.Logger) ReconciliationRepository {
+	logger.WithFields(utils.LogFields{"component": "reconciliation_repository"}).Info("ReconciliationRepository initialized")
+	return &reconciliationRepository{
+		tx:      tx,
+		queries: queries,
+		logger:  logger.WithFields(utils.LogFields{"component": "reconciliation_repository"}),
+	}
+}
+
+func (r *reconciliationRepository) ExecTx(ctx context.Context, fn func(ReconciliationRepository) error) error {
+	return r.tx.Run(ctx, func(tx pgx.Tx) error {
+		return fn(&reconciliationRepository{
+			tx:      r.tx,
+			queries: r.queries.WithTx(tx),
+			logger:  r.logger,
+		})
+	})
+}
+
+func (r *reconciliationRepository) ListBalanceDrift(ctx context.Context) ([]db.ListBalanceDriftRow, error) {
+	rows, err := r.queries.ListBalanceDrift(ctx)
+	if err != nil {
+		r.logger.WithFields(utils.LogFields{"error": err}).Error("Failed to list balance drift")
+		return nil, err
+	}
+	return rows, nil
+}
+
+func (r *reconciliationRepository) ListCoinLotDrift(ctx context.Context) ([]db.ListCoinLotDriftRow, error) {
+	rows, err := r.queries.ListCoinLotDrift(ctx)
+	if err != nil {
+		r.logger.WithFields(utils.LogFields{"error": err}).Error("Failed to list coin lot drift")
+		return nil, err
+	}
+	return rows, nil
+}
+
+func (r *reconciliationRepository) GetEmployee(ctx context.Context, username string) (db.Employee, error) {
+	employee, err := r.queries.GetEmployeeByUsername(ctx, username)
+	if err != nil {
+		r.logger.WithFields(utils.LogFields{"error": err, "username": username}).Error("Failed to get employee")
+		return employee, err
+	}
+	return employee, nil
+}
+
+// LockBalances блокирует строки сотрудников по возрастанию id и возвращает
+// их балансы.
+func (r *reconciliationRepository) LockBalances(ctx context.Context, userIDs ...int32) (map[int32]int32, error) {
+	balances, err := lockBalances(ctx, r.queries, userIDs)
+	if err != nil {
+		r.logger.WithFields(utils.LogFields{"error": err, "user_ids": userIDs}).Error("Failed to lock balances")
+		return nil, err
+	}
+	return balances, nil
+}
+
+// CreateGrantTransaction записывает компенсирующее начисление и создаёт
+// партию на его сумму, как и grantRepository.UpdateCoins. Баланс не меняется.
+func (r *reconciliationRepository) CreateGrantTransaction(ctx context.Context, params db.CreateCoinTransactionGrantParams) (int32, error) {
+	log := r.logger.WithFields(utils.LogFields{"to_employee_id": params.ToEmployeeID})
+
+	id, err := r.queries.CreateCoinTransactionGrant(ctx, params)
+	if err != nil {
+		log.WithFields(utils.LogFields{"error": err}).Error("Failed to record compensating grant")
+		return 0, err
+	}
+	if err := r.queries.CreateCoinLot(ctx, db.CreateCoinLotParams{EmployeeID: params.ToEmployeeID, Amount: params.Amount}); err != nil {
+		log.WithFields(utils.LogFields{"error": err}).Error("Failed to create coin lot")
+		return 0, err
+	}
+	return id, nil
+}
+
+// CreateAdjustmentTransaction записывает компенсирующее списание и расходует
+// партии на его сумму, как и grantRepository.UpdateCoins. Баланс не меняется.
+// Строка сотрудника должна быть заблокирована через LockBalances.
+func (r *reconciliationRepository) CreateAdjustmentTransaction(ctx context.Context, params db.CreateCoinTransactionAdjustmentParams) (int32, error) {
+	log := r.logger.WithFields(utils.LogFields{"from_employee_id": params.FromEmployeeID})
+
+	id, err := r.queries.CreateCoinTransactionAdjustment(ctx, params)
+	if err != nil {
+		log.WithFields(utils.LogFields{"error": err}).Error("Failed to record compensating adjustment")
+		return 0, err
+	}
+	if _, err := consumeCoinLots(ctx, r.queries, r.logger, params.FromEmployeeID, params.Amount); err != nil {
+		log.WithFields(utils.LogFields{"error": err}).Error("Failed to consume coin lots")
+		return 0, err
+	}
+	return id, nil
+}
@@ -0,0 +1,175 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/par1ram/merch-store/internal/db"
+	"github.com/par1ram/merch-store/internal/repository"
+	"github.com/par1ram/merch-store/internal/utils"
+)
+
+func TestReconciliationRepository_ListBalanceDrift(t *testing.T) {
+	mockPool, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mockPool.Close()
+
+	repo := repository.NewReconciliationRepository(newTxRunner(mockPool), db.New(mockPool), utils.NewLogger())
+
+	// Стартовый остаток берётся из проводок signup и opening, а не из константы.
+	mockPool.ExpectQuery(`(?s)FROM ledger_postings p.*entry_type IN \('signup', 'opening'\).*JOIN journal_entries j ON j.coin_transaction_id = t.id.*WHERE coins <> expected`).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "username", "coins", "expected"}).
+			AddRow(int32(4), "dave", int32(950), int64(1000)))
+
+	rows, err := repo.ListBalanceDrift(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []db.ListBalanceDriftRow{{ID: 4, Username: "dave", Coins: 950, Expected: 1000}}, rows)
+
+	assert.NoError(t, mockPool.ExpectationsWereMet())
+}
+
+func TestReconciliationRepository_FixInTx_Success(t *testing.T) {
+	mockPool, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mockPool.Close()
+
+	repo := repository.NewReconciliationRepository(newTxRunner(mockPool), db.New(mockPool), utils.NewLogger())
+
+	// Компенсирующее списание расходует партии так же, как ручное списание.
+	mockPool.ExpectBegin()
+	mockPool.ExpectQuery(`(?s)FROM employees\s+WHERE id = ANY\(\$1::integer\[\]\)\s+ORDER BY id\s+FOR UPDATE`).
+		WithArgs([]int32{4}).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "coins"}).AddRow(int32(4), int32(950)))
+	mockPool.ExpectQuery(`(?s)INSERT INTO coin_transactions .*VALUES \('adjustment'.*INSERT INTO ledger_postings.*SELECT id FROM tx`).
+		WithArgs(int32(4), int32(50), "balance reconciliation", int32(1)).
+		WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(int32(90)))
+	mockPool.ExpectQuery(`(?s)WITH locked AS.*FROM coin_lots.*FOR UPDATE.*UPDATE coin_lots l`).
+		WithArgs(int32(4), int32(50)).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "taken", "granted_at", "expires_at"}).
+			AddRow(int32(1), int32(50), pgtype.Timestamptz{}, pgtype.Timestamptz{}))
+	mockPool.ExpectCommit()
+
+	var id int32
+	err = repo.ExecTx(context.Background(), func(r repository.ReconciliationRepository) error {
+		if _, err := r.LockBalances(context.Background(), 4); err != nil {
+			return err
+		}
+		var err error
+		id, err = r.CreateAdjustmentTransaction(context.Background(), db.CreateCoinTransactionAdjustmentParams{
+			FromEmployeeID: 4,
+			Amount:         50,
+			Reason:         "balance reconciliation",
+			CreatedBy:      1,
+		})
+		return err
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, int32(90), id)
+
+	assert.NoError(t, mockPool.ExpectationsWereMet())
+}
+
+func TestReconciliationRepository_CompensatingGrantCreatesLot(t *testing.T) {
+	mockPool, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mockPool.Close()
+
+	repo := repository.NewReconciliationRepository(newTxRunner(mockPool), db.New(mockPool), utils.NewLogger())
+
+	mockPool.ExpectQuery(`(?s)INSERT INTO coin_transactions .*VALUES \('grant'`).
+		WithArgs(int32(5), int32(30), "balance reconciliation", int32(1)).
+		WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(int32(91)))
+	mockPool.ExpectExec(`(?s)INSERT INTO coin_lots \(employee_id, amount, remaining\)`).
+		WithArgs(int32(5), int32(30)).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+
+	id, err := repo.CreateGrantTransaction(context.Background(), db.CreateCoinTransactionGrantParams{
+		ToEmployeeID: 5,
+		Amount:       30,
+		Reason:       "balance reconciliation",
+		CreatedBy:    1,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, int32(91), id)
+
+	assert.NoError(t, mockPool.ExpectationsWereMet())
+}
+
+func TestReconciliationRepository_CompensatingAdjustmentWithoutLots(t *testing.T) {
+	mockPool, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mockPool.Close()
+
+	repo := repository.NewReconciliationRepository(newTxRunner(mockPool), db.New(mockPool), utils.NewLogger())
+
+	// Партий меньше, чем списывается: сверка не должна оставлять их сумму
+	// больше баланса, операция прерывается.
+	mockPool.ExpectQuery(`(?s)INSERT INTO coin_transactions .*VALUES \('adjustment'`).
+		WithArgs(int32(4), int32(50), "balance reconciliation", int32(1)).
+		WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(int32(90)))
+	mockPool.ExpectQuery(`(?s)WITH locked AS.*FROM coin_lots.*FOR UPDATE.*UPDATE coin_lots l`).
+		WithArgs(int32(4), int32(50)).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "taken", "granted_at", "expires_at"}).
+			AddRow(int32(1), int32(20), pgtype.Timestamptz{}, pgtype.Timestamptz{}))
+
+	_, err = repo.CreateAdjustmentTransaction(context.Background(), db.CreateCoinTransactionAdjustmentParams{
+		FromEmployeeID: 4,
+		Amount:         50,
+		Reason:         "balance reconciliation",
+		CreatedBy:      1,
+	})
+	assert.ErrorIs(t, err, repository.ErrCoinLotsMismatch)
+
+	assert.NoError(t, mockPool.ExpectationsWereMet())
+}
+
+func TestReconciliationRepository_ListCoinLotDrift(t *testing.T) {
+	mockPool, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mockPool.Close()
+
+	repo := repository.NewReconciliationRepository(newTxRunner(mockPool), db.New(mockPool), utils.NewLogger())
+
+	mockPool.ExpectQuery(`(?s)SUM\(l.remaining\).*LEFT JOIN coin_lots l ON l.employee_id = e.id.*HAVING e.coins <>`).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "username", "coins", "lots"}).
+			AddRow(int32(4), "dave", int32(950), int64(1000)))
+
+	rows, err := repo.ListCoinLotDrift(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []db.ListCoinLotDriftRow{{ID: 4, Username: "dave", Coins: 950, Lots: 1000}}, rows)
+
+	assert.NoError(t, mockPool.ExpectationsWereMet())
+}
+
+func TestReconciliationRepository_FixInTx_Rollback(t *testing.T) {
+	mockPool, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mockPool.Close()
+
+	repo := repository.NewReconciliationRepository(newTxRunner(mockPool), db.New(mockPool), utils.NewLogger())
+
+	dbErr := errors.New("insert failed")
+	mockPool.ExpectBegin()
+	mockPool.ExpectQuery(`(?s)INSERT INTO coin_transactions .*VALUES \('grant'`).
+		WithArgs(int32(4), int32(50), "balance reconciliation", int32(1)).
+		WillReturnError(dbErr)
+	mockPool.ExpectRollback()
+
+	err = repo.ExecTx(context.Background(), func(r repository.ReconciliationRepository) error {
+		_, err := r.CreateGrantTransaction(context.Background(), db.CreateCoinTransactionGrantParams{
+			ToEmployeeID: 4,
+			Amount:       50,
+			Reason:       "balance reconciliation",
+			CreatedBy:    1,
+		})
+		return err
+	})
+	assert.ErrorIs(t, err, dbErr)
+
+	assert.NoError(t, mockPool.ExpectationsWereMet())
+}
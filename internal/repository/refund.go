@@ -0,0 +1,194 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/par1ram/merch-store/internal/db"
+	"github.com/par1ram/merch-store/internal/utils"
+)
+
+// RefundRepository обслуживает возвраты покупок и заявки на них.
+// Возврат меняет инвентарь, баланс и историю, поэтому выполняется через ExecTx.
+// Строка покупателя блокируется через LockBalance до изменения инвентаря:
+// покупка берёт блокировки в том же порядке (сотрудник, затем инвентарь).
+type RefundRepository interface {
+	ExecTx(ctx context.Context, fn func(RefundRepository) error) error
+	GetPurchaseForUpdate(ctx context.Context, purchaseID int32) (db.GetPurchaseForUpdateRow, error)
+	GetRefundedQuantity(ctx context.Context, purchaseID int32) (int32, error)
+	LockBalance(ctx context.Context, userID int32) (int32, error)
+	RemoveInventory(ctx context.Context, params db.RemoveInventoryParams) (int64, error)
+	RestoreCoins(ctx context.Context, purchaseID, userID, amount int32) error
+	CreateRefundTransaction(ctx context.Context, params db.CreateCoinTransactionRefundParams) (int32, error)
+	CancelOrder(ctx context.Context, purchaseID int32) error
+	ReduceOrder(ctx context.Context, purchaseID, quantity int32) error
+	ListPurchases(ctx context.Context, employeeID int32) ([]db.ListPurchasesByEmployeeRow, error)
+	CreateRefundRequest(ctx context.Context, params db.CreateRefundRequestParams) (db.RefundRequest, error)
+	GetRefundRequestForUpdate(ctx context.Context, requestID int32) (db.RefundRequest, error)
+	ListRefundRequests(ctx context.Context, params db.ListRefundRequestsParams) ([]db.ListRefundRequestsRow, error)
+	ResolveRefundRequest(ctx context.Context, params db.ResolveRefundRequestParams) error
+}
+
+type refundRepository struct {
+	tx      TxRunner
+	queries *db.Queries
+	logger  utils.Logger
+}
+
+func NewRefundRepository(tx TxRunner, queries *db.Queries, logger utils.Logger) RefundRepository {
+	logger.WithFields(utils.LogFields{"component": "refund_repository"}).Info("RefundRepository initialized")
+	return &refundRepository{
+		tx:      tx,
+		queries: queries,
+		logger:  logger.WithFields(utils.LogFields{"component": "refund_repository"}),
+	}
+}
+
+func (r *refundRepository) ExecTx(ctx context.Context, fn func(RefundRepository) error) error {
+	return r.tx.Run(ctx, func(tx pgx.Tx) error {
+		return fn(&refundRepository{
+			tx:      r.tx,
+			queries: r.queries.WithTx(tx),
+			logger:  r.logger,
+		})
+	})
+}
+
+func (r *refundRepository) GetPurchaseForUpdate(ctx context.Context, purchaseID int32) (db.GetPurchaseForUpdateRow, error) {
+	purchase, err := r.queries.GetPurchaseForUpdate(ctx, purchaseID)
+	if err != nil {
+		r.logger.WithFields(utils.LogFields{"error": err, "purchase_id": purchaseID}).Error("Failed to get purchase")
+		return purchase, err
+	}
+	return purchase, nil
+}
+
+func (r *refundRepository) GetRefundedQuantity(ctx context.Context, purchaseID int32) (int32, error) {
+	refunded, err := r.queries.GetRefundedQuantity(ctx, purchaseID)
+	if err != nil {
+		r.logger.WithFields(utils.LogFields{"error": err, "purchase_id": purchaseID}).Error("Failed to get refunded quantity")
+		return 0, err
+	}
+	return refunded, nil
+}
+
+// LockBalance возвращает баланс сотрудника и блокирует его строку до конца транзакции.
+func (r *refundRepository) LockBalance(ctx context.Context, userID int32) (int32, error) {
+	balance, err := r.queries.LockEmployeeCoins(ctx, userID)
+	if err != nil {
+		r.logger.WithFields(utils.LogFields{"error": err, "userID": userID}).Error("Failed to lock balance")
+		return 0, fmt.Errorf("lock balance failed: %w", err)
+	}
+	return balance, nil
+}
+
+// RemoveInventory возвращает 0, если у сотрудника нет нужного количества товара.
+func (r *refundRepository) RemoveInventory(ctx context.Context, params db.RemoveInventoryParams) (int64, error) {
+	affected, err := r.queries.RemoveInventory(ctx, params)
+	if err != nil {
+		r.logger.WithFields(utils.LogFields{"error": err, "employee_id": params.EmployeeID, "merch_id": params.MerchID}).Error("Failed to remove inventory")
+		return 0, err
+	}
+	return affected, nil
+}
+
+// RestoreCoins зачисляет покупателю amount монет по возврату покупки частями
+// израсходованных на неё партий с их исходными сроками действия: возврат не
+// продлевает срок монет, а монеты с уже истёкшим сроком сгорят при следующем
+// запуске сгорания. Часть суммы, для которой частей нет (покупка сделана
+// до их учёта), зачисляется новой партией.
+func (r *refundRepository) RestoreCoins(ctx context.Context, purchaseID, userID, amount int32) error {
+	log := r.logger.WithFields(utils.LogFields{"purchase_id": purchaseID, "userID": userID, "amount": amount})
+
+	taken, err := r.queries.TakePurchaseLots(ctx, db.TakePurchaseLotsParams{PurchaseID: purchaseID, Amount: amount})
+	if err != nil {
+		log.WithFields(utils.LogFields{"error": err}).Error("Failed to take purchase lots")
+		return fmt.Errorf("take purchase lots failed: %w", err)
+	}
+	lots := make([]db.ConsumeCoinLotsRow, 0, len(taken))
+	for _, lot := range taken {
+		lots = append(lots, db.ConsumeCoinLotsRow{Taken: lot.Taken, GrantedAt: lot.GrantedAt, ExpiresAt: lot.ExpiresAt})
+	}
+
+	if err := depositCoins(ctx, r.queries, userID, amount); err != nil {
+		log.WithFields(utils.LogFields{"error": err}).Error("Failed to credit coins")
+		return err
+	}
+	if err := moveCoinLots(ctx, r.queries, userID, amount, lots); err != nil {
+		log.WithFields(utils.LogFields{"error": err}).Error("Failed to restore coin lots")
+		return err
+	}
+	return nil
+}
+
+func (r *refundRepository) CreateRefundTransaction(ctx context.Context, params db.CreateCoinTransactionRefundParams) (int32, error) {
+	id, err := r.queries.CreateCoinTransactionRefund(ctx, params)
+	if err != nil {
+		r.logger.WithFields(utils.LogFields{"error": err, "purchase_id": params.RefundOf}).Error("Failed to create refund transaction")
+		return 0, err
+	}
+	return id, nil
+}
+
+// CancelOrder отменяет ещё не выданный заказ по полностью возвращённой покупке.
+func (r *refundRepository) CancelOrder(ctx context.Context, purchaseID int32) error {
+	if err := r.queries.CancelOrderByPurchase(ctx, purchaseID); err != nil {
+		r.logger.WithFields(utils.LogFields{"error": err, "purchase_id": purchaseID}).Error("Failed to cancel order")
+		return err
+	}
+	return nil
+}
+
+// ReduceOrder уменьшает ещё не выданный заказ на частично возвращённое количество.
+func (r *refundRepository) ReduceOrder(ctx context.Context, purchaseID, quantity int32) error {
+	if err := r.queries.ReduceOrderQuantity(ctx, db.ReduceOrderQuantityParams{PurchaseID: purchaseID, Quantity: quantity}); err != nil {
+		r.logger.WithFields(utils.LogFields{"error": err, "purchase_id": purchaseID}).Error("Failed to reduce order")
+		return err
+	}
+	return nil
+}
+
+func (r *refundRepository) ListPurchases(ctx context.Context, employeeID int32) ([]db.ListPurchasesByEmployeeRow, error) {
+	purchases, err := r.queries.ListPurchasesByEmployee(ctx, employeeID)
+	if err != nil {
+		r.logger.WithFields(utils.LogFields{"error": err, "employee_id": employeeID}).Error("Failed to list purchases")
+		return nil, fmt.Errorf("list purchases failed: %w", err)
+	}
+	return purchases, nil
+}
+
+func (r *refundRepository) CreateRefundRequest(ctx context.Context, params db.CreateRefundRequestParams) (db.RefundRequest, error) {
+	request, err := r.queries.CreateRefundRequest(ctx, params)
+	if err != nil {
+		r.logger.WithFields(utils.LogFields{"error": err, "purchase_id": params.PurchaseID}).Error("Failed to create refund request")
+		return request, err
+	}
+	return request, nil
+}
+
+func (r *refundRepository) GetRefundRequestForUpdate(ctx context.Context, requestID int32) (db.RefundRequest, error) {
+	request, err := r.queries.GetRefundRequestForUpdate(ctx, requestID)
+	if err != nil {
+		r.logger.WithFields(utils.LogFields{"error": err, "request_id": requestID}).Error("Failed to get refund request")
+		return request, err
+	}
+	return request, nil
+}
+
+func (r *refundRepository) ListRefundRequests(ctx context.Context, params db.ListRefundRequestsParams) ([]db.ListRefundRequestsRow, error) {
+	requests, err := r.queries.ListRefundRequests(ctx, params)
+	if err != nil {
+		r.logger.WithFields(utils.LogFields{"error": err}).Error("Failed to list refund requests")
+		return nil, fmt.Errorf("list refund requests failed: %w", err)
+	}
+	return requests, nil
+}
+
+func (r *refundRepository) ResolveRefundRequest(ctx context.Context, params db.ResolveRefundRequestParams) error {
+	if err := r.queries.ResolveRefundRequest(ctx, params); err != nil {
+		r.logger.WithFields(utils.LogFields{"error": err, "request_id": params.ID}).Error("Failed to resolve refund request")
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,162 @@
+package repository_test
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/par1ram/merch-store/internal/db"
+	"github.com/par1ram/merch-store/internal/repository"
+	"github.com/par1ram/merch-store/internal/utils"
+)
+
+func TestRefundRepository_RefundInTx_Success(t *testing.T) {
+	mockPool, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mockPool.Close()
+
+	queries := db.New(mockPool)
+	repo := repository.NewRefundRepository(newTxRunner(mockPool), queries, utils.NewLogger())
+
+	mockPool.ExpectBegin()
+	mockPool.ExpectQuery(`(?s)FROM coin_transactions ct.*WHERE ct.id = \$1 AND ct.transaction_type = 'purchase'.*FOR UPDATE OF ct`).
+		WithArgs(int32(5)).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "from_employee_id", "merch_id", "merch_name", "quantity", "unit_price", "amount"}).
+			AddRow(int32(5), pgtype.Int4{Int32: 7, Valid: true}, pgtype.Int4{Int32: 2, Valid: true}, "cup", int32(3), pgtype.Int4{Int32: 20, Valid: true}, int32(60)))
+	mockPool.ExpectQuery(`(?s)SELECT COALESCE\(SUM\(quantity\), 0\)::integer AS refunded.*refund_of = \$1::integer`).
+		WithArgs(int32(5)).
+		WillReturnRows(pgxmock.NewRows([]string{"refunded"}).AddRow(int32(1)))
+	// Строка покупателя блокируется до изменения инвентаря, как при покупке.
+	mockPool.ExpectQuery(`(?s)SELECT coins FROM employees WHERE id = \$1 FOR UPDATE`).
+		WithArgs(int32(7)).
+		WillReturnRows(pgxmock.NewRows([]string{"coins"}).AddRow(int32(500)))
+	mockPool.ExpectExec(`(?s)UPDATE inventory.*SET quantity = quantity - \$1.*AND quantity >= \$1`).
+		WithArgs(int32(2), int32(7), int32(2)).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	// Части партий покупки покрывают 30 из 40 монет: они возвращаются с исходным
+	// сроком, остаток — новой партией.
+	grantedAt := pgtype.Timestamptz{Time: time.Date(2026, time.January, 10, 0, 0, 0, 0, time.UTC), Valid: true}
+	expiresAt := pgtype.Timestamptz{Time: time.Date(2027, time.January, 10, 0, 0, 0, 0, time.UTC), Valid: true}
+	mockPool.ExpectQuery(`(?s)FROM purchase_lots.*FOR UPDATE.*UPDATE purchase_lots p`).
+		WithArgs(int32(5), int32(40)).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "taken", "granted_at", "expires_at"}).
+			AddRow(int32(3), int32(30), grantedAt, expiresAt))
+	mockPool.ExpectExec(`(?s)UPDATE employees.*SET coins = coins \+ \$2`).
+		WithArgs(int32(7), int32(40)).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mockPool.ExpectExec(`(?s)INSERT INTO coin_lots \(employee_id, amount, remaining, granted_at, expires_at\)`).
+		WithArgs(int32(7), int32(30), grantedAt, expiresAt).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	mockPool.ExpectExec(`(?s)INSERT INTO coin_lots \(employee_id, amount, remaining\)`).
+		WithArgs(int32(7), int32(10)).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	mockPool.ExpectQuery(`(?s)INSERT INTO coin_transactions .*refund_of.*VALUES \(.*'refund'.*RETURNING id`).
+		WithArgs(int32(7), int32(2), int32(40), int32(2), int32(20), int32(5)).
+		WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(int32(12)))
+	mockPool.ExpectCommit()
+
+	var refundID int32
+	err = repo.ExecTx(context.Background(), func(r repository.RefundRepository) error {
+		purchase, err := r.GetPurchaseForUpdate(context.Background(), 5)
+		if err != nil {
+			return err
+		}
+		refunded, err := r.GetRefundedQuantity(context.Background(), 5)
+		if err != nil {
+			return err
+		}
+		assert.Equal(t, int32(1), refunded)
+
+		if _, err := r.LockBalance(context.Background(), purchase.FromEmployeeID.Int32); err != nil {
+			return err
+		}
+		affected, err := r.RemoveInventory(context.Background(), db.RemoveInventoryParams{
+			Quantity:   2,
+			EmployeeID: purchase.FromEmployeeID.Int32,
+			MerchID:    purchase.MerchID.Int32,
+		})
+		if err != nil {
+			return err
+		}
+		assert.Equal(t, int64(1), affected)
+
+		if err := r.RestoreCoins(context.Background(), 5, 7, 40); err != nil {
+			return err
+		}
+		refundID, err = r.CreateRefundTransaction(context.Background(), db.CreateCoinTransactionRefundParams{
+			ToEmployeeID: 7,
+			MerchID:      2,
+			Amount:       40,
+			Quantity:     2,
+			UnitPrice:    20,
+			RefundOf:     5,
+		})
+		return err
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, int32(12), refundID)
+
+	assert.NoError(t, mockPool.ExpectationsWereMet())
+}
+
+func TestRefundRepository_RemoveInventory_NotEnough(t *testing.T) {
+	mockPool, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mockPool.Close()
+
+	queries := db.New(mockPool)
+	repo := repository.NewRefundRepository(newTxRunner(mockPool), queries, utils.NewLogger())
+
+	mockPool.ExpectExec(`(?s)UPDATE inventory.*AND quantity >= \$1`).
+		WithArgs(int32(3), int32(7), int32(2)).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+
+	affected, err := repo.RemoveInventory(context.Background(), db.RemoveInventoryParams{Quantity: 3, EmployeeID: 7, MerchID: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), affected)
+
+	assert.NoError(t, mockPool.ExpectationsWereMet())
+}
+
+func TestRefundRepository_ResolveRefundRequest_Error(t *testing.T) {
+	mockPool, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mockPool.Close()
+
+	queries := db.New(mockPool)
+	repo := repository.NewRefundRepository(newTxRunner(mockPool), queries, utils.NewLogger())
+
+	params := db.ResolveRefundRequestParams{
+		Status:     db.RefundStatusEnumRejected,
+		ResolvedBy: 1,
+		ID:         3,
+	}
+	mockPool.ExpectExec(regexp.QuoteMeta(`UPDATE refund_requests`)).
+		WithArgs(params.Status, params.RefundID, params.ResolvedBy, params.ID).
+		WillReturnError(assert.AnError)
+
+	err = repo.ResolveRefundRequest(context.Background(), params)
+	assert.ErrorIs(t, err, assert.AnError)
+
+	assert.NoError(t, mockPool.ExpectationsWereMet())
+}
+
+func TestRefundRepository_ReduceOrder(t *testing.T) {
+	mockPool, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mockPool.Close()
+
+	repo := repository.NewRefundRepository(newTxRunner(mockPool), db.New(mockPool), utils.NewLogger())
+
+	mockPool.ExpectExec(`(?s)UPDATE orders\s+SET quantity = quantity - \$1::integer.*status NOT IN \('delivered', 'cancelled'\)`).
+		WithArgs(int32(2), int32(5)).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+
+	assert.NoError(t, repo.ReduceOrder(context.Background(), 5, 2))
+	assert.NoError(t, mockPool.ExpectationsWereMet())
+}
@@ -0,0 +1,37 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/par1ram/merch-store/internal/db"
+	"github.com/par1ram/merch-store/internal/utils"
+)
+
+// RoleRepository читает текущую роль сотрудника. Роль в токене может устареть
+// после смены через /api/admin/employees/{username}/role, поэтому права на
+// административные маршруты проверяются по базе.
+type RoleRepository interface {
+	GetRole(ctx context.Context, employeeID int32) (string, error)
+}
+
+type roleRepository struct {
+	queries *db.Queries
+	logger  utils.Logger
+}
+
+func NewRoleRepository(queries *db.Queries, logger utils.Logger) RoleRepository {
+	logger.WithFields(utils.LogFields{"component": "role_repository"}).Info("RoleRepository initialized")
+	return &roleRepository{
+		queries: queries,
+		logger:  logger.WithFields(utils.LogFields{"component": "role_repository"}),
+	}
+}
+
+func (r *roleRepository) GetRole(ctx context.Context, employeeID int32) (string, error) {
+	role, err := r.queries.GetEmployeeRole(ctx, employeeID)
+	if err != nil {
+		r.logger.WithFields(utils.LogFields{"error": err, "employee_id": employeeID}).Error("Failed to get employee role")
+		return "", err
+	}
+	return string(role), nil
+}
@@ -0,0 +1,50 @@
+package repository_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/par1ram/merch-store/internal/db"
+	"github.com/par1ram/merch-store/internal/repository"
+	"github.com/par1ram/merch-store/internal/utils"
+)
+
+func TestRoleRepository_GetRole(t *testing.T) {
+	mockPool, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mockPool.Close()
+
+	repo := repository.NewRoleRepository(db.New(mockPool), utils.NewLogger())
+
+	mockPool.ExpectQuery(`SELECT role FROM employees WHERE id = \$1`).
+		WithArgs(int32(7)).
+		WillReturnRows(pgxmock.NewRows([]string{"role"}).AddRow(db.EmployeeRoleEnumAdmin))
+
+	role, err := repo.GetRole(context.Background(), 7)
+	assert.NoError(t, err)
+	assert.Equal(t, "admin", role)
+
+	assert.NoError(t, mockPool.ExpectationsWereMet())
+}
+
+func TestRoleRepository_GetRole_NotFound(t *testing.T) {
+	mockPool, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mockPool.Close()
+
+	repo := repository.NewRoleRepository(db.New(mockPool), utils.NewLogger())
+
+	mockPool.ExpectQuery(`SELECT role FROM employees WHERE id = \$1`).
+		WithArgs(int32(7)).
+		WillReturnError(pgx.ErrNoRows)
+
+	_, err = repo.GetRole(context.Background(), 7)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+
+	assert.NoError(t, mockPool.ExpectationsWereMet())
+}
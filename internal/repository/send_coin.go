@@ -3,62 +3,56 @@ package repository
 import (
 	"context"
 	"fmt"
+	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/par1ram/merch-store/internal/db"
 	"github.com/par1ram/merch-store/internal/utils"
 )
 
+// TransferMemo — сообщение к переводу и хэштеги из него. Пустое сообщение не сохраняется.
+type TransferMemo struct {
+	Message  string
+	Hashtags []string
+}
+
+// SendCoinRepository выполняет переводы. LockBalances блокирует строки
+// участников перевода до конца транзакции: проверка баланса и списание
+// выполняются без гонок с параллельными переводами и покупками.
 type SendCoinRepository interface {
 	ExecTx(ctx context.Context, fn func(SendCoinRepository) error) error
 	GetRecipient(ctx context.Context, username string) (*db.Employee, error)
-	GetBalance(ctx context.Context, userID int32) (int32, error)
-	TransferCoins(ctx context.Context, fromUserID, toUserID, amount int32) error
+	LockBalances(ctx context.Context, userIDs ...int32) (map[int32]int32, error)
+	TransferCoins(ctx context.Context, fromUserID, toUserID, amount int32, memo TransferMemo) error
+	HoldCoins(ctx context.Context, fromUserID, toUserID, amount int32, memo TransferMemo, expiresAt time.Time) (db.TransferHold, error)
+	GetTransferLimitOverride(ctx context.Context, userID int32) (db.GetTransferLimitOverrideRow, error)
+	GetOutgoingTransferTotals(ctx context.Context, params db.GetOutgoingTransferTotalsParams) (db.GetOutgoingTransferTotalsRow, error)
 }
 
 type sendCoinRepository struct {
-	pool    PoolIface
+	tx      TxRunner
 	queries *db.Queries
 	logger  utils.Logger
 }
 
-func NewSendCoinRepository(pool PoolIface, queries *db.Queries, logger utils.Logger) SendCoinRepository {
+func NewSendCoinRepository(tx TxRunner, queries *db.Queries, logger utils.Logger) SendCoinRepository {
 	logger.WithFields(utils.LogFields{"component": "send_coin_repository"}).Info("SendCoinRepository initialized")
 	return &sendCoinRepository{
-		pool:    pool,
+		tx:      tx,
 		queries: queries,
 		logger:  logger.WithFields(utils.LogFields{"component": "send_coin_repository"}),
 	}
 }
 
 func (r *sendCoinRepository) ExecTx(ctx context.Context, fn func(SendCoinRepository) error) error {
-	log := r.logger.WithFields(utils.LogFields{"operation": "exec_tx"})
-
-	tx, err := r.pool.Begin(ctx)
-	if err != nil {
-		log.WithFields(utils.LogFields{"error": err}).Error("transaction begin failed")
-		return fmt.Errorf("transaction start failed: %w", err)
-	}
-	defer tx.Rollback(ctx)
-
-	qtx := r.queries.WithTx(tx)
-	txRepo := &sendCoinRepository{
-		queries: qtx,
-		logger:  r.logger,
-	}
-
-	if err := fn(txRepo); err != nil {
-		log.WithFields(utils.LogFields{"error": err}).Error("transaction operation failed")
-		return err
-	}
-
-	if err := tx.Commit(ctx); err != nil {
-		log.WithFields(utils.LogFields{"error": err}).Error("transaction commit failed")
-		return fmt.Errorf("transaction commit failed: %w", err)
-	}
-
-	log.Info("transaction committed")
-	return nil
+	return r.tx.Run(ctx, func(tx pgx.Tx) error {
+		return fn(&sendCoinRepository{
+			tx:      r.tx,
+			queries: r.queries.WithTx(tx),
+			logger:  r.logger,
+		})
+	})
 }
 
 func (r *sendCoinRepository) GetRecipient(ctx context.Context, username string) (*db.Employee, error) {
@@ -77,23 +71,25 @@ func (r *sendCoinRepository) GetRecipient(ctx context.Context, username string)
 	return &employee, nil
 }
 
-func (r *sendCoinRepository) GetBalance(ctx context.Context, userID int32) (int32, error) {
+// LockBalances блокирует строки сотрудников в порядке возрастания id,
+// независимо от порядка аргументов, и возвращает их балансы.
+func (r *sendCoinRepository) LockBalances(ctx context.Context, userIDs ...int32) (map[int32]int32, error) {
 	log := r.logger.WithFields(utils.LogFields{
-		"operation": "get_balance",
-		"user_id":   userID,
+		"operation": "lock_balances",
+		"user_ids":  userIDs,
 	})
 
-	balance, err := r.queries.GetCoinsByID(ctx, userID)
+	balances, err := lockBalances(ctx, r.queries, userIDs)
 	if err != nil {
-		log.WithFields(utils.LogFields{"error": err}).Error("balance check failed")
-		return 0, fmt.Errorf("get balance failed: %w", err)
+		log.WithFields(utils.LogFields{"error": err}).Error("balance lock failed")
+		return nil, fmt.Errorf("lock balances failed: %w", err)
 	}
 
-	log.WithFields(utils.LogFields{"balance": balance}).Debug("balance retrieved")
-	return balance, nil
+	log.WithFields(utils.LogFields{"balances": balances}).Debug("balances locked")
+	return balances, nil
 }
 
-func (r *sendCoinRepository) TransferCoins(ctx context.Context, fromUserID, toUserID, amount int32) error {
+func (r *sendCoinRepository) TransferCoins(ctx context.Context, fromUserID, toUserID, amount int32, memo TransferMemo) error {
 	log := r.logger.WithFields(utils.LogFields{
 		"operation":    "transfer_coins",
 		"from_user_id": fromUserID,
@@ -101,26 +97,32 @@ func (r *sendCoinRepository) TransferCoins(ctx context.Context, fromUserID, toUs
 		"amount":       amount,
 	})
 
-	if err := r.queries.UpdateEmployeeCoins(ctx, db.UpdateEmployeeCoinsParams{
-		ID:    fromUserID,
-		Coins: -amount,
-	}); err != nil {
+	lots, err := debitCoins(ctx, r.queries, log, fromUserID, amount)
+	if err != nil {
 		log.WithFields(utils.LogFields{"error": err}).Error("withdrawal failed")
 		return fmt.Errorf("withdrawal failed: %w", err)
 	}
 
-	if err := r.queries.UpdateEmployeeCoins(ctx, db.UpdateEmployeeCoinsParams{
-		ID:    toUserID,
-		Coins: amount,
-	}); err != nil {
+	if err := depositCoins(ctx, r.queries, toUserID, amount); err != nil {
+		log.WithFields(utils.LogFields{"error": err}).Error("deposit failed")
+		return fmt.Errorf("deposit failed: %w", err)
+	}
+
+	if err := moveCoinLots(ctx, r.queries, toUserID, amount, lots); err != nil {
 		log.WithFields(utils.LogFields{"error": err}).Error("deposit failed")
 		return fmt.Errorf("deposit failed: %w", err)
 	}
 
+	hashtags := memo.Hashtags
+	if hashtags == nil {
+		hashtags = []string{}
+	}
 	if err := r.queries.CreateCoinTransactionTransfer(ctx, db.CreateCoinTransactionTransferParams{
 		FromEmployeeID: fromUserID,
 		ToEmployeeID:   pgtype.Int4{Int32: toUserID, Valid: true},
 		Amount:         amount,
+		Message:        pgtype.Text{String: memo.Message, Valid: memo.Message != ""},
+		Hashtags:       hashtags,
 	}); err != nil {
 		log.WithFields(utils.LogFields{"error": err}).Error("transaction record creation failed")
 		return fmt.Errorf("transaction record failed: %w", err)
@@ -129,3 +131,85 @@ func (r *sendCoinRepository) TransferCoins(ctx context.Context, fromUserID, toUs
 	log.Info("transfer completed")
 	return nil
 }
+
+// HoldCoins списывает монеты отправителя на счёт удержания до решения получателя.
+// Израсходованные части партий сохраняются вместе с удержанием.
+func (r *sendCoinRepository) HoldCoins(ctx context.Context, fromUserID, toUserID, amount int32, memo TransferMemo, expiresAt time.Time) (db.TransferHold, error) {
+	log := r.logger.WithFields(utils.LogFields{
+		"operation":    "hold_coins",
+		"from_user_id": fromUserID,
+		"to_user_id":   toUserID,
+		"amount":       amount,
+	})
+
+	lots, err := debitCoins(ctx, r.queries, log, fromUserID, amount)
+	if err != nil {
+		log.WithFields(utils.LogFields{"error": err}).Error("withdrawal failed")
+		return db.TransferHold{}, fmt.Errorf("withdrawal failed: %w", err)
+	}
+
+	hashtags := memo.Hashtags
+	if hashtags == nil {
+		hashtags = []string{}
+	}
+	message := pgtype.Text{String: memo.Message, Valid: memo.Message != ""}
+
+	hold, err := r.queries.CreateTransferHold(ctx, db.CreateTransferHoldParams{
+		SenderID:    fromUserID,
+		RecipientID: toUserID,
+		Amount:      amount,
+		Message:     message,
+		Hashtags:    hashtags,
+		ExpiresAt:   pgtype.Timestamptz{Time: expiresAt, Valid: true},
+	})
+	if err != nil {
+		log.WithFields(utils.LogFields{"error": err}).Error("hold creation failed")
+		return db.TransferHold{}, fmt.Errorf("hold creation failed: %w", err)
+	}
+
+	for _, lot := range lots {
+		if err := r.queries.CreateTransferHoldLot(ctx, db.CreateTransferHoldLotParams{
+			HoldID:    hold.ID,
+			Amount:    lot.Taken,
+			GrantedAt: lot.GrantedAt,
+			ExpiresAt: lot.ExpiresAt,
+		}); err != nil {
+			log.WithFields(utils.LogFields{"error": err}).Error("hold lot creation failed")
+			return db.TransferHold{}, fmt.Errorf("hold lot creation failed: %w", err)
+		}
+	}
+
+	if err := r.queries.CreateCoinTransactionHold(ctx, db.CreateCoinTransactionHoldParams{
+		FromEmployeeID: fromUserID,
+		Amount:         amount,
+		Message:        message,
+		Hashtags:       hashtags,
+		HoldID:         hold.ID,
+	}); err != nil {
+		log.WithFields(utils.LogFields{"error": err}).Error("transaction record creation failed")
+		return db.TransferHold{}, fmt.Errorf("transaction record failed: %w", err)
+	}
+
+	log.WithFields(utils.LogFields{"hold_id": hold.ID}).Info("coins held")
+	return hold, nil
+}
+
+// GetTransferLimitOverride возвращает индивидуальные лимиты переводов сотрудника;
+// незаданные лимиты возвращаются как NULL.
+func (r *sendCoinRepository) GetTransferLimitOverride(ctx context.Context, userID int32) (db.GetTransferLimitOverrideRow, error) {
+	row, err := r.queries.GetTransferLimitOverride(ctx, userID)
+	if err != nil {
+		r.logger.WithFields(utils.LogFields{"error": err, "user_id": userID}).Error("transfer limit lookup failed")
+		return row, fmt.Errorf("get transfer limits failed: %w", err)
+	}
+	return row, nil
+}
+
+func (r *sendCoinRepository) GetOutgoingTransferTotals(ctx context.Context, params db.GetOutgoingTransferTotalsParams) (db.GetOutgoingTransferTotalsRow, error) {
+	row, err := r.queries.GetOutgoingTransferTotals(ctx, params)
+	if err != nil {
+		r.logger.WithFields(utils.LogFields{"error": err, "user_id": params.SenderID}).Error("outgoing transfer totals failed")
+		return row, fmt.Errorf("get outgoing transfer totals failed: %w", err)
+	}
+	return row, nil
+}
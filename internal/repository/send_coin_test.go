@@ -26,8 +26,8 @@ func TestSendCoinRepository_GetRecipient_Success(t *testing.T) {
 
 	now := time.Now() // или time.Now(), с учётом того, как вы его используете
 	createdAt := pgtype.Timestamptz{Time: now, Valid: true}
-	rows := pgxmock.NewRows([]string{"id", "username", "password_hash", "coins", "created_at"}).
-		AddRow(int32(2), "recipient_user", "somehash", int32(200), createdAt)
+	rows := pgxmock.NewRows([]string{"id", "username", "password_hash", "coins", "created_at", "role"}).
+		AddRow(int32(2), "recipient_user", "somehash", int32(200), createdAt, db.EmployeeRoleEnumEmployee)
 
 	queryRegex := regexp.MustCompile("(?s)SELECT.*FROM employees.*WHERE username = \\$1")
 	mockPool.ExpectQuery(queryRegex.String()).
@@ -35,7 +35,7 @@ func TestSendCoinRepository_GetRecipient_Success(t *testing.T) {
 		WillReturnRows(rows)
 
 	// Создаем репозиторий через конструктор, который теперь принимает PoolIface.
-	repoInstance := repository.NewSendCoinRepository(mockPool, queries, utils.NewLogger())
+	repoInstance := repository.NewSendCoinRepository(newTxRunner(mockPool), queries, utils.NewLogger())
 	recipient, err := repoInstance.GetRecipient(context.Background(), "recipient_user")
 	assert.NoError(t, err)
 	assert.NotNil(t, recipient)
@@ -45,24 +45,25 @@ func TestSendCoinRepository_GetRecipient_Success(t *testing.T) {
 	assert.NoError(t, mockPool.ExpectationsWereMet())
 }
 
-func TestSendCoinRepository_GetBalance_Success(t *testing.T) {
+func TestSendCoinRepository_LockBalances_Success(t *testing.T) {
 	mockPool, err := pgxmock.NewPool()
 	assert.NoError(t, err)
 	defer mockPool.Close()
 
 	queries := db.New(mockPool)
 
-	// Ожидаем запрос: SELECT coins FROM employees WHERE id=$1
-	rows := pgxmock.NewRows([]string{"coins"}).AddRow(int32(150))
-	queryRegex := regexp.MustCompile("(?s)SELECT coins FROM employees WHERE id=\\$1")
+	// Строки блокируются одним запросом в порядке возрастания id,
+	// независимо от порядка аргументов.
+	rows := pgxmock.NewRows([]string{"id", "coins"}).AddRow(int32(1), int32(150)).AddRow(int32(2), int32(30))
+	queryRegex := regexp.MustCompile("(?s)FROM employees\\s+WHERE id = ANY\\(\\$1::integer\\[\\]\\)\\s+ORDER BY id\\s+FOR UPDATE")
 	mockPool.ExpectQuery(queryRegex.String()).
-		WithArgs(int32(1)).
+		WithArgs([]int32{2, 1}).
 		WillReturnRows(rows)
 
-	repoInstance := repository.NewSendCoinRepository(mockPool, queries, utils.NewLogger())
-	balance, err := repoInstance.GetBalance(context.Background(), 1)
+	repoInstance := repository.NewSendCoinRepository(newTxRunner(mockPool), queries, utils.NewLogger())
+	balances, err := repoInstance.LockBalances(context.Background(), 2, 1)
 	assert.NoError(t, err)
-	assert.Equal(t, int32(150), balance)
+	assert.Equal(t, map[int32]int32{1: 150, 2: 30}, balances)
 
 	assert.NoError(t, mockPool.ExpectationsWereMet())
 }
@@ -73,31 +74,82 @@ func TestSendCoinRepository_TransferCoins_Success(t *testing.T) {
 	defer mockPool.Close()
 
 	queries := db.New(mockPool)
-	repoInstance := repository.NewSendCoinRepository(mockPool, queries, utils.NewLogger())
+	repoInstance := repository.NewSendCoinRepository(newTxRunner(mockPool), queries, utils.NewLogger())
 
 	fromUserID := int32(1)
 	toUserID := int32(2)
 	amount := int32(50)
 
-	// 1. Ожидаем вызов UpdateEmployeeCoins для списания средств у отправителя.
+	// 1. Ожидаем вызов UpdateEmployeeCoins для списания средств у отправителя:
+	// строка сотрудника блокируется раньше его партий.
 	updateQueryRegex := regexp.MustCompile("(?s)UPDATE employees\\s+SET coins = coins \\+ \\$2\\s+WHERE id = \\$1 AND coins \\+ \\$2 >= 0")
 	mockPool.ExpectExec(updateQueryRegex.String()).
 		WithArgs(fromUserID, -amount).
 		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
 
-	// 2. Ожидаем вызов UpdateEmployeeCoins для начисления средств получателю.
+	// 2. Ожидаем расход партий отправителя: 30 монет из старшей партии и 20 из следующей.
+	oldGranted := pgtype.Timestamptz{Time: time.Date(2026, time.January, 10, 0, 0, 0, 0, time.UTC), Valid: true}
+	oldExpires := pgtype.Timestamptz{Time: time.Date(2027, time.January, 10, 0, 0, 0, 0, time.UTC), Valid: true}
+	newGranted := pgtype.Timestamptz{Time: time.Date(2026, time.March, 1, 0, 0, 0, 0, time.UTC), Valid: true}
+	newExpires := pgtype.Timestamptz{Time: time.Date(2027, time.March, 1, 0, 0, 0, 0, time.UTC), Valid: true}
+	mockPool.ExpectQuery(`(?s)WITH locked AS.*FROM coin_lots.*ORDER BY expires_at, id.*FOR UPDATE`).
+		WithArgs(fromUserID, amount).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "taken", "granted_at", "expires_at"}).
+			AddRow(int32(10), int32(30), oldGranted, oldExpires).
+			AddRow(int32(11), int32(20), newGranted, newExpires))
+
+	// 3. Ожидаем вызов UpdateEmployeeCoins для начисления средств получателю.
 	mockPool.ExpectExec(updateQueryRegex.String()).
 		WithArgs(toUserID, amount).
 		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
 
-	// 3. Ожидаем вызов CreateCoinTransactionTransfer для записи транзакции.
-	ctQueryRegex := regexp.MustCompile("(?s)INSERT INTO coin_transactions .*VALUES \\('transfer', \\$1, \\$2, \\$3\\)")
+	// 4. Получатель получает партии с исходными сроками действия.
+	lotQuery := `(?s)INSERT INTO coin_lots \(employee_id, amount, remaining, granted_at, expires_at\)`
+	mockPool.ExpectExec(lotQuery).
+		WithArgs(toUserID, int32(30), oldGranted, oldExpires).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	mockPool.ExpectExec(lotQuery).
+		WithArgs(toUserID, int32(20), newGranted, newExpires).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+
+	// 5. Ожидаем вызов CreateCoinTransactionTransfer для записи транзакции и проводки в книге.
+	ctQueryRegex := regexp.MustCompile("(?s)INSERT INTO coin_transactions .*VALUES \\('transfer', \\$1::integer, \\$2, \\$3, \\$4::text, \\$5::text\\[\\]\\).*INSERT INTO journal_entries.*INSERT INTO ledger_postings")
 	mockPool.ExpectExec(ctQueryRegex.String()).
-		WithArgs(fromUserID, pgtype.Int4{Int32: toUserID, Valid: true}, amount).
+		WithArgs(fromUserID, pgtype.Int4{Int32: toUserID, Valid: true}, amount,
+			pgtype.Text{String: "thanks for the review #teamwork", Valid: true}, []string{"teamwork"}).
 		WillReturnResult(pgxmock.NewResult("INSERT", 1))
 
-	err = repoInstance.TransferCoins(context.Background(), fromUserID, toUserID, amount)
+	err = repoInstance.TransferCoins(context.Background(), fromUserID, toUserID, amount, repository.TransferMemo{
+		Message:  "thanks for the review #teamwork",
+		Hashtags: []string{"teamwork"},
+	})
+	assert.NoError(t, err)
+
+	assert.NoError(t, mockPool.ExpectationsWereMet())
+}
+
+func TestSendCoinRepository_TransferCoins_LotsMismatch(t *testing.T) {
+	mockPool, err := pgxmock.NewPool()
 	assert.NoError(t, err)
+	defer mockPool.Close()
+
+	queries := db.New(mockPool)
+	repoInstance := repository.NewSendCoinRepository(newTxRunner(mockPool), queries, utils.NewLogger())
+
+	mockPool.ExpectExec(`(?s)UPDATE employees\s+SET coins = coins \+ \$2`).
+		WithArgs(int32(1), int32(-50)).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+
+	// Партий хватает только на 30 монет из 50: перевод прерывается, а не
+	// оставляет сумму партий больше баланса.
+	expires := pgtype.Timestamptz{Time: time.Date(2027, time.January, 10, 0, 0, 0, 0, time.UTC), Valid: true}
+	mockPool.ExpectQuery(`(?s)WITH locked AS.*FROM coin_lots.*FOR UPDATE`).
+		WithArgs(int32(1), int32(50)).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "taken", "granted_at", "expires_at"}).
+			AddRow(int32(10), int32(30), expires, expires))
+
+	err = repoInstance.TransferCoins(context.Background(), 1, 2, 50, repository.TransferMemo{})
+	assert.ErrorIs(t, err, repository.ErrCoinLotsMismatch)
 
 	assert.NoError(t, mockPool.ExpectationsWereMet())
 }
@@ -113,7 +165,7 @@ func TestSendCoinRepository_ExecTx_Success(t *testing.T) {
 	mockPool.ExpectCommit()
 
 	queries := db.New(mockPool)
-	repoInstance := repository.NewSendCoinRepository(mockPool, queries, utils.NewLogger())
+	repoInstance := repository.NewSendCoinRepository(newTxRunner(mockPool), queries, utils.NewLogger())
 
 	err = repoInstance.ExecTx(context.Background(), func(repo repository.SendCoinRepository) error {
 		// В callback можно вызывать методы репозитория, здесь достаточно вернуть nil.
@@ -123,3 +175,21 @@ func TestSendCoinRepository_ExecTx_Success(t *testing.T) {
 
 	assert.NoError(t, mockPool.ExpectationsWereMet())
 }
+
+func TestSendCoinRepository_TransferCoins_InsufficientBalance(t *testing.T) {
+	mockPool, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mockPool.Close()
+
+	queries := db.New(mockPool)
+	repoInstance := repository.NewSendCoinRepository(newTxRunner(mockPool), queries, utils.NewLogger())
+
+	mockPool.ExpectExec(`(?s)UPDATE employees.*coins \+ \$2 >= 0`).
+		WithArgs(int32(1), int32(-500)).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+
+	err = repoInstance.TransferCoins(context.Background(), 1, 2, 500, repository.TransferMemo{})
+	assert.ErrorIs(t, err, repository.ErrInsufficientBalance)
+
+	assert.NoError(t, mockPool.ExpectationsWereMet())
+}
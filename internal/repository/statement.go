@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/par1ram/merch-store/internal/db"
+	"github.com/par1ram/merch-store/internal/utils"
+)
+
+// StatementRepository читает данные для месячных выписок: входящие остатки и
+// операции за период одного или всех сотрудников. Остатки и операции должны
+// читаться в одном ExecTx: он открывает транзакцию только для чтения с уровнем
+// REPEATABLE READ, и оба запроса видят один снимок.
+type StatementRepository interface {
+	ExecTx(ctx context.Context, fn func(StatementRepository) error) error
+	ListStatementBalances(ctx context.Context, params db.ListStatementBalancesParams) ([]db.ListStatementBalancesRow, error)
+	ListStatementEntries(ctx context.Context, params db.ListStatementEntriesParams) ([]db.ListStatementEntriesRow, error)
+}
+
+type statementRepository struct {
+	tx      TxRunner
+	queries *db.Queries
+	logger  utils.Logger
+}
+
+func NewStatementRepository(tx TxRunner, queries *db.Queries, logger utils.Logger) StatementRepository {
+	logger.WithFields(utils.LogFields{"component": "statement_repository"}).Info("StatementRepository initialized")
+	return &statementRepository{
+		tx:      tx,
+		queries: queries,
+		logger:  logger.WithFields(utils.LogFields{"component": "statement_repository"}),
+	}
+}
+
+func (r *statementRepository) ExecTx(ctx context.Context, fn func(StatementRepository) error) error {
+	return r.tx.RunReadOnly(ctx, func(tx pgx.Tx) error {
+		return fn(&statementRepository{
+			tx:      r.tx,
+			queries: r.queries.WithTx(tx),
+			logger:  r.logger,
+		})
+	})
+}
+
+func (r *statementRepository) ListStatementBalances(ctx context.Context, params db.ListStatementBalancesParams) ([]db.ListStatementBalancesRow, error) {
+	rows, err := r.queries.ListStatementBalances(ctx, params)
+	if err != nil {
+		r.logger.WithFields(utils.LogFields{"error": err, "employee_id": params.EmployeeID.Int32}).Error("Failed to list statement balances")
+		return nil, err
+	}
+	return rows, nil
+}
+
+func (r *statementRepository) ListStatementEntries(ctx context.Context, params db.ListStatementEntriesParams) ([]db.ListStatementEntriesRow, error) {
+	rows, err := r.queries.ListStatementEntries(ctx, params)
+	if err != nil {
+		r.logger.WithFields(utils.LogFields{"error": err, "employee_id": params.EmployeeID.Int32}).Error("Failed to list statement entries")
+		return nil, err
+	}
+	return rows, nil
+}
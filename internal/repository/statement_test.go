@@ -0,0 +1,98 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/par1ram/merch-store/internal/db"
+	"github.com/par1ram/merch-store/internal/repository"
+	"github.com/par1ram/merch-store/internal/utils"
+)
+
+func statementPeriod() (pgtype.Timestamptz, pgtype.Timestamptz) {
+	start := time.Date(2026, time.September, 1, 0, 0, 0, 0, time.UTC)
+	return pgtype.Timestamptz{Time: start, Valid: true}, pgtype.Timestamptz{Time: start.AddDate(0, 1, 0), Valid: true}
+}
+
+func TestStatementRepository_ListStatementBalances(t *testing.T) {
+	mockPool, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mockPool.Close()
+
+	repo := repository.NewStatementRepository(newTxRunner(mockPool), db.New(mockPool), utils.NewLogger())
+
+	start, end := statementPeriod()
+	params := db.ListStatementBalancesParams{PeriodStart: start, PeriodEnd: end}
+	registered := pgtype.Timestamptz{Time: time.Date(2026, time.March, 2, 9, 0, 0, 0, time.UTC), Valid: true}
+
+	mockPool.ExpectQuery(`(?s)e.coins - COALESCE\(SUM\(.*ct.created_at >= \$1::timestamptz.*`+
+		`WHERE e.created_at < \$2::timestamptz.*GROUP BY e.id`).
+		WithArgs(params.PeriodStart, params.PeriodEnd, params.EmployeeID).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "username", "created_at", "opening_balance"}).
+			AddRow(int32(1), "alice", registered, int64(1000)).
+			AddRow(int32(2), "bob", registered, int64(850)))
+
+	rows, err := repo.ListStatementBalances(context.Background(), params)
+	assert.NoError(t, err)
+	assert.Equal(t, []db.ListStatementBalancesRow{
+		{ID: 1, Username: "alice", CreatedAt: registered, OpeningBalance: 1000},
+		{ID: 2, Username: "bob", CreatedAt: registered, OpeningBalance: 850},
+	}, rows)
+
+	assert.NoError(t, mockPool.ExpectationsWereMet())
+}
+
+func TestStatementRepository_ListStatementEntries(t *testing.T) {
+	mockPool, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mockPool.Close()
+
+	repo := repository.NewStatementRepository(newTxRunner(mockPool), db.New(mockPool), utils.NewLogger())
+
+	start, end := statementPeriod()
+	params := db.ListStatementEntriesParams{PeriodStart: start, PeriodEnd: end, EmployeeID: pgtype.Int4{Int32: 1, Valid: true}}
+	createdAt := pgtype.Timestamptz{Time: start.Time.Add(time.Hour), Valid: true}
+
+	mockPool.ExpectQuery(`(?s)CROSS JOIN LATERAL \(VALUES \(ct.to_employee_id, true\), \(ct.from_employee_id, false\)\).*`+
+		`ORDER BY s.employee_id, ct.created_at, ct.id`).
+		WithArgs(params.PeriodStart, params.PeriodEnd, params.EmployeeID).
+		WillReturnRows(pgxmock.NewRows([]string{
+			"employee_id", "id", "transaction_type", "incoming", "amount", "counterparty", "merch_name",
+			"quantity", "message", "reason", "created_at",
+		}).
+			AddRow(int32(1), int32(7), db.TransactionTypeEnumTransfer, false, int32(30), "bob", "", int32(1), "thanks", "", createdAt))
+
+	rows, err := repo.ListStatementEntries(context.Background(), params)
+	assert.NoError(t, err)
+	assert.Len(t, rows, 1)
+	assert.Equal(t, "bob", rows[0].Counterparty)
+	assert.False(t, rows[0].Incoming)
+
+	assert.NoError(t, mockPool.ExpectationsWereMet())
+}
+
+func TestStatementRepository_ListStatementEntries_Error(t *testing.T) {
+	mockPool, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mockPool.Close()
+
+	repo := repository.NewStatementRepository(newTxRunner(mockPool), db.New(mockPool), utils.NewLogger())
+
+	start, end := statementPeriod()
+	params := db.ListStatementEntriesParams{PeriodStart: start, PeriodEnd: end}
+	mockPool.ExpectQuery(`FROM coin_transactions ct`).
+		WithArgs(params.PeriodStart, params.PeriodEnd, params.EmployeeID).
+		WillReturnError(errors.New("db error"))
+
+	rows, err := repo.ListStatementEntries(context.Background(), params)
+	assert.Error(t, err)
+	assert.Nil(t, rows)
+
+	assert.NoError(t, mockPool.ExpectationsWereMet())
+}
@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/par1ram/merch-store/internal/db"
+	"github.com/par1ram/merch-store/internal/utils"
+)
+
+// TransferLimitRepository хранит индивидуальные лимиты переводов сотрудников.
+type TransferLimitRepository interface {
+	GetEmployee(ctx context.Context, username string) (db.Employee, error)
+	GetOverride(ctx context.Context, employeeID int32) (db.GetTransferLimitOverrideRow, error)
+	UpsertOverride(ctx context.Context, params db.UpsertTransferLimitOverrideParams) error
+	DeleteOverride(ctx context.Context, employeeID int32) (int64, error)
+}
+
+type transferLimitRepository struct {
+	queries *db.Queries
+	logger  utils.Logger
+}
+
+func NewTransferLimitRepository(queries *db.Queries, logger utils.Logger) TransferLimitRepository {
+	logger.WithFields(utils.LogFields{"component": "transfer_limit_repository"}).Info("TransferLimitRepository initialized")
+	return &transferLimitRepository{
+		queries: queries,
+		logger:  logger.WithFields(utils.LogFields{"component": "transfer_limit_repository"}),
+	}
+}
+
+func (r *transferLimitRepository) GetEmployee(ctx context.Context, username string) (db.Employee, error) {
+	employee, err := r.queries.GetEmployeeByUsername(ctx, username)
+	if err != nil {
+		r.logger.WithFields(utils.LogFields{"error": err, "username": username}).Error("Failed to get employee")
+		return employee, err
+	}
+	return employee, nil
+}
+
+func (r *transferLimitRepository) GetOverride(ctx context.Context, employeeID int32) (db.GetTransferLimitOverrideRow, error) {
+	row, err := r.queries.GetTransferLimitOverride(ctx, employeeID)
+	if err != nil {
+		r.logger.WithFields(utils.LogFields{"error": err, "employee_id": employeeID}).Error("Failed to get transfer limits")
+		return row, err
+	}
+	return row, nil
+}
+
+func (r *transferLimitRepository) UpsertOverride(ctx context.Context, params db.UpsertTransferLimitOverrideParams) error {
+	if err := r.queries.UpsertTransferLimitOverride(ctx, params); err != nil {
+		r.logger.WithFields(utils.LogFields{"error": err, "employee_id": params.EmployeeID}).Error("Failed to save transfer limits")
+		return err
+	}
+	return nil
+}
+
+func (r *transferLimitRepository) DeleteOverride(ctx context.Context, employeeID int32) (int64, error) {
+	affected, err := r.queries.DeleteTransferLimitOverride(ctx, employeeID)
+	if err != nil {
+		r.logger.WithFields(utils.LogFields{"error": err, "employee_id": employeeID}).Error("Failed to reset transfer limits")
+		return 0, err
+	}
+	return affected, nil
+}
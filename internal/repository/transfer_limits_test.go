@@ -0,0 +1,69 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/par1ram/merch-store/internal/db"
+	"github.com/par1ram/merch-store/internal/repository"
+	"github.com/par1ram/merch-store/internal/utils"
+)
+
+func TestSendCoinRepository_GetOutgoingTransferTotals(t *testing.T) {
+	mockPool, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mockPool.Close()
+
+	repo := repository.NewSendCoinRepository(newTxRunner(mockPool), db.New(mockPool), utils.NewLogger())
+
+	monthStart := pgtype.Timestamptz{Time: time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC), Valid: true}
+	dayStart := pgtype.Timestamptz{Time: time.Date(2025, 3, 14, 0, 0, 0, 0, time.UTC), Valid: true}
+
+	mockPool.ExpectQuery(`(?s)FROM coin_transactions.*transaction_type IN \('transfer', 'hold'\).*from_employee_id = \$3`).
+		WithArgs(dayStart, int32(2), int32(1), monthStart).
+		WillReturnRows(pgxmock.NewRows([]string{"sent_today", "sent_this_month", "sent_to_recipient"}).
+			AddRow(int64(30), int64(120), int64(50)))
+
+	totals, err := repo.GetOutgoingTransferTotals(context.Background(), db.GetOutgoingTransferTotalsParams{
+		DayStart:    dayStart,
+		RecipientID: 2,
+		SenderID:    1,
+		MonthStart:  monthStart,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, db.GetOutgoingTransferTotalsRow{SentToday: 30, SentThisMonth: 120, SentToRecipient: 50}, totals)
+
+	assert.NoError(t, mockPool.ExpectationsWereMet())
+}
+
+func TestTransferLimitRepository_UpsertAndDelete(t *testing.T) {
+	mockPool, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mockPool.Close()
+
+	repo := repository.NewTransferLimitRepository(db.New(mockPool), utils.NewLogger())
+
+	params := db.UpsertTransferLimitOverrideParams{
+		EmployeeID:   7,
+		MonthlyLimit: pgtype.Int4{Int32: 500, Valid: true},
+		UpdatedBy:    1,
+	}
+	mockPool.ExpectExec(`(?s)INSERT INTO transfer_limit_overrides.*ON CONFLICT \(employee_id\) DO UPDATE`).
+		WithArgs(int32(7), pgtype.Int4{}, params.MonthlyLimit, pgtype.Int4{}, int32(1)).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	mockPool.ExpectExec(`DELETE FROM transfer_limit_overrides`).
+		WithArgs(int32(7)).
+		WillReturnResult(pgxmock.NewResult("DELETE", 1))
+
+	assert.NoError(t, repo.UpsertOverride(context.Background(), params))
+	affected, err := repo.DeleteOverride(context.Background(), 7)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), affected)
+
+	assert.NoError(t, mockPool.ExpectationsWereMet())
+}
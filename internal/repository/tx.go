@@ -0,0 +1,159 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/par1ram/merch-store/internal/metrics"
+	"github.com/par1ram/merch-store/internal/utils"
+)
+
+// SQLSTATE ошибок, после которых транзакцию можно безопасно повторить целиком.
+const (
+	sqlStateSerializationFailure = "40001"
+	sqlStateDeadlockDetected     = "40P01"
+)
+
+// TxConfig — параметры транзакций репозиториев.
+type TxConfig struct {
+	// IsoLevel — уровень изоляции; пустой — уровень по умолчанию сервера БД.
+	IsoLevel pgx.TxIsoLevel
+	// MaxAttempts — сколько раз транзакция выполняется, включая первую попытку.
+	MaxAttempts int
+	// BaseDelay и MaxDelay ограничивают паузу перед повтором: она растёт
+	// экспоненциально от BaseDelay, не превышает MaxDelay и случайно сдвигается,
+	// чтобы конфликтующие транзакции не повторялись одновременно.
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+}
+
+// DefaultTxConfig — уровень изоляции сервера и до трёх попыток.
+var DefaultTxConfig = TxConfig{
+	MaxAttempts: 3,
+	BaseDelay:   20 * time.Millisecond,
+	MaxDelay:    500 * time.Millisecond,
+}
+
+// TxRunner выполняет функцию в транзакции и повторяет её при сбое сериализации
+// или взаимной блокировке. Функция может быть вызвана несколько раз, поэтому
+// она не должна накапливать состояние между попытками.
+type TxRunner interface {
+	Run(ctx context.Context, fn func(pgx.Tx) error) error
+	// RunReadOnly выполняет fn в транзакции только для чтения с уровнем
+	// REPEATABLE READ: все запросы fn видят один снимок данных.
+	RunReadOnly(ctx context.Context, fn func(pgx.Tx) error) error
+}
+
+type txRunner struct {
+	pool   PoolIface
+	cfg    TxConfig
+	logger utils.Logger
+}
+
+// NewTxRunner создаёт исполнитель транзакций поверх пула соединений.
+func NewTxRunner(pool PoolIface, cfg TxConfig, logger utils.Logger) TxRunner {
+	if cfg.MaxAttempts < 1 {
+		cfg.MaxAttempts = 1
+	}
+	return &txRunner{
+		pool:   pool,
+		cfg:    cfg,
+		logger: logger.WithFields(utils.LogFields{"component": "tx_runner"}),
+	}
+}
+
+func (r *txRunner) Run(ctx context.Context, fn func(pgx.Tx) error) error {
+	return r.run(ctx, pgx.TxOptions{IsoLevel: r.cfg.IsoLevel}, fn)
+}
+
+func (r *txRunner) RunReadOnly(ctx context.Context, fn func(pgx.Tx) error) error {
+	return r.run(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly}, fn)
+}
+
+func (r *txRunner) run(ctx context.Context, opts pgx.TxOptions, fn func(pgx.Tx) error) error {
+	for attempt := 1; ; attempt++ {
+		err := r.runOnce(ctx, opts, fn)
+		sqlState, retryable := retryableSQLState(err)
+		if !retryable {
+			return err
+		}
+		metrics.TxConflicts.Add(sqlState, 1)
+
+		log := r.logger.WithFields(utils.LogFields{
+			"error":        err,
+			"sqlstate":     sqlState,
+			"attempt":      attempt,
+			"max_attempts": r.cfg.MaxAttempts,
+		})
+		if attempt >= r.cfg.MaxAttempts {
+			metrics.TxRetriesExhausted.Add(1)
+			log.Error("Transaction retries exhausted")
+			return err
+		}
+
+		delay := r.backoff(attempt)
+		metrics.TxRetries.Add(1)
+		log.WithFields(utils.LogFields{"delay": delay.String()}).Warn("Retrying transaction")
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
+
+// runOnce выполняет одну попытку транзакции. Ошибки fn здесь не логируются:
+// конфликты логирует run при повторе, остальные — вызывающий код.
+func (r *txRunner) runOnce(ctx context.Context, opts pgx.TxOptions, fn func(pgx.Tx) error) error {
+	tx, err := r.pool.BeginTx(ctx, opts)
+	if err != nil {
+		r.logger.WithFields(utils.LogFields{"error": err}).Error("Transaction begin failed")
+		return fmt.Errorf("transaction start failed: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		r.logger.WithFields(utils.LogFields{"error": err}).Error("Transaction commit failed")
+		return fmt.Errorf("transaction commit failed: %w", err)
+	}
+	return nil
+}
+
+// backoff возвращает паузу перед попыткой attempt+1: половина экспоненциальной
+// задержки фиксирована, вторая половина выбирается случайно.
+func (r *txRunner) backoff(attempt int) time.Duration {
+	delay := r.cfg.BaseDelay << (attempt - 1)
+	if delay <= 0 || (r.cfg.MaxDelay > 0 && delay > r.cfg.MaxDelay) {
+		delay = r.cfg.MaxDelay
+	}
+	if delay <= 0 {
+		return 0
+	}
+	half := delay / 2
+	return half + rand.N(delay-half+1)
+}
+
+// retryableSQLState сообщает, вызвана ли ошибка сбоем сериализации или взаимной блокировкой.
+func retryableSQLState(err error) (string, bool) {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return "", false
+	}
+	switch pgErr.Code {
+	case sqlStateSerializationFailure, sqlStateDeadlockDetected:
+		return pgErr.Code, true
+	}
+	return "", false
+}
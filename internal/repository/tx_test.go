@@ -0,0 +1,169 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/par1ram/merch-store/internal/metrics"
+	"github.com/par1ram/merch-store/internal/repository"
+	"github.com/par1ram/merch-store/internal/utils"
+)
+
+// newTxRunner создаёт исполнитель транзакций с короткими паузами между повторами.
+func newTxRunner(pool repository.PoolIface) repository.TxRunner {
+	return repository.NewTxRunner(pool, repository.TxConfig{
+		MaxAttempts: 3,
+		BaseDelay:   time.Millisecond,
+		MaxDelay:    time.Millisecond,
+	}, utils.NewLogger())
+}
+
+func TestTxRunner_Run_Commit(t *testing.T) {
+	mockPool, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mockPool.Close()
+
+	mockPool.ExpectBegin()
+	mockPool.ExpectCommit()
+
+	calls := 0
+	err = newTxRunner(mockPool).Run(context.Background(), func(pgx.Tx) error {
+		calls++
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, calls)
+
+	assert.NoError(t, mockPool.ExpectationsWereMet())
+}
+
+func TestTxRunner_Run_RetriesDeadlock(t *testing.T) {
+	mockPool, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mockPool.Close()
+
+	retries := metrics.TxRetries.Value()
+
+	mockPool.ExpectBegin()
+	mockPool.ExpectRollback()
+	mockPool.ExpectBegin()
+	mockPool.ExpectCommit()
+
+	calls := 0
+	err = newTxRunner(mockPool).Run(context.Background(), func(pgx.Tx) error {
+		calls++
+		if calls == 1 {
+			return fmt.Errorf("failed to lock balances: %w", &pgconn.PgError{Code: "40P01"})
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, calls)
+	assert.Equal(t, retries+1, metrics.TxRetries.Value())
+
+	assert.NoError(t, mockPool.ExpectationsWereMet())
+}
+
+func TestTxRunner_Run_RetriesSerializationFailureOnCommit(t *testing.T) {
+	mockPool, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mockPool.Close()
+
+	mockPool.ExpectBegin()
+	mockPool.ExpectCommit().WillReturnError(&pgconn.PgError{Code: "40001"})
+	mockPool.ExpectBegin()
+	mockPool.ExpectCommit()
+
+	calls := 0
+	err = newTxRunner(mockPool).Run(context.Background(), func(pgx.Tx) error {
+		calls++
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, calls)
+
+	assert.NoError(t, mockPool.ExpectationsWereMet())
+}
+
+func TestTxRunner_Run_RetriesExhausted(t *testing.T) {
+	mockPool, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mockPool.Close()
+
+	exhausted := metrics.TxRetriesExhausted.Value()
+	for i := 0; i < 3; i++ {
+		mockPool.ExpectBegin()
+		mockPool.ExpectRollback()
+	}
+
+	calls := 0
+	deadlock := &pgconn.PgError{Code: "40P01"}
+	err = newTxRunner(mockPool).Run(context.Background(), func(pgx.Tx) error {
+		calls++
+		return deadlock
+	})
+	assert.ErrorIs(t, err, deadlock)
+	assert.Equal(t, 3, calls)
+	assert.Equal(t, exhausted+1, metrics.TxRetriesExhausted.Value())
+
+	assert.NoError(t, mockPool.ExpectationsWereMet())
+}
+
+func TestTxRunner_Run_DoesNotRetryOtherErrors(t *testing.T) {
+	mockPool, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mockPool.Close()
+
+	mockPool.ExpectBegin()
+	mockPool.ExpectRollback()
+
+	calls := 0
+	businessErr := errors.New("insufficient funds")
+	err = newTxRunner(mockPool).Run(context.Background(), func(pgx.Tx) error {
+		calls++
+		return businessErr
+	})
+	assert.ErrorIs(t, err, businessErr)
+	assert.Equal(t, 1, calls)
+
+	assert.NoError(t, mockPool.ExpectationsWereMet())
+}
+
+func TestTxRunner_Run_IsolationLevel(t *testing.T) {
+	mockPool, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mockPool.Close()
+
+	mockPool.ExpectBeginTx(pgx.TxOptions{IsoLevel: pgx.Serializable})
+	mockPool.ExpectCommit()
+
+	runner := repository.NewTxRunner(mockPool, repository.TxConfig{IsoLevel: pgx.Serializable}, utils.NewLogger())
+	err = runner.Run(context.Background(), func(pgx.Tx) error { return nil })
+	assert.NoError(t, err)
+
+	assert.NoError(t, mockPool.ExpectationsWereMet())
+}
+
+func TestTxRunner_RunReadOnly(t *testing.T) {
+	mockPool, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mockPool.Close()
+
+	// Уровень из конфигурации не влияет на чтение снимка.
+	mockPool.ExpectBeginTx(pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+	mockPool.ExpectCommit()
+
+	runner := repository.NewTxRunner(mockPool, repository.TxConfig{IsoLevel: pgx.ReadCommitted}, utils.NewLogger())
+	err = runner.RunReadOnly(context.Background(), func(pgx.Tx) error { return nil })
+	assert.NoError(t, err)
+
+	assert.NoError(t, mockPool.ExpectationsWereMet())
+}
@@ -13,11 +13,13 @@ type User struct {
 	Username     string `json:"username"`
 	PasswordHash string `json:"password_hash"`
 	Coins        int32  `json:"coins"`
+	Role         string `json:"role"`
 }
 
 type UserRepository interface {
 	GetByUsername(ctx context.Context, username string) (User, error)
 	Create(ctx context.Context, username, passwordHash string) (User, error)
+	SetRole(ctx context.Context, username, role string) (User, error)
 }
 
 type PostgresUserRepository struct {
@@ -54,6 +56,7 @@ func (r *PostgresUserRepository) GetByUsername(ctx context.Context, username str
 		Username:     emp.Username,
 		PasswordHash: emp.PasswordHash,
 		Coins:        emp.Coins,
+		Role:         string(emp.Role),
 	}, nil
 }
 
@@ -80,5 +83,35 @@ func (r *PostgresUserRepository) Create(ctx context.Context, username, passwordH
 		Username:     emp.Username,
 		PasswordHash: emp.PasswordHash,
 		Coins:        emp.Coins,
+		Role:         string(emp.Role),
+	}, nil
+}
+
+// SetRole назначает роль пользователю.
+func (r *PostgresUserRepository) SetRole(ctx context.Context, username, role string) (User, error) {
+	r.logger.Infof("Setting role %s for user: %s", role, username)
+	emp, err := r.Queries.SetEmployeeRole(ctx, db.SetEmployeeRoleParams{
+		Username: username,
+		Role:     db.EmployeeRoleEnum(role),
+	})
+	if err != nil {
+		r.logger.WithFields(utils.LogFields{
+			"username": username,
+			"role":     role,
+			"error":    err,
+		}).Errorf("Failed to set employee role")
+		return User{}, err
+	}
+	r.logger.WithFields(utils.LogFields{
+		"userID":   emp.ID,
+		"username": emp.Username,
+		"role":     emp.Role,
+	}).Debugf("User role updated successfully")
+	return User{
+		ID:           int64(emp.ID),
+		Username:     emp.Username,
+		PasswordHash: emp.PasswordHash,
+		Coins:        emp.Coins,
+		Role:         string(emp.Role),
 	}, nil
 }
@@ -37,11 +37,11 @@ func TestUserRepository_GetByUsername_Success(t *testing.T) {
 	// Для простоты предположим, что sqlc генерирует именно такой запрос:
 	// "SELECT id, username, password_hash, coins, created_at FROM employees WHERE username = $1"
 	mockPool.
-		ExpectQuery(`SELECT id, username, password_hash, coins, created_at FROM employees WHERE username = \$1`).
+		ExpectQuery(`SELECT id, username, password_hash, coins, created_at, role FROM employees WHERE username = \$1`).
 		WithArgs(username).
 		WillReturnRows(
-			mockPool.NewRows([]string{"id", "username", "password_hash", "coins", "created_at"}).
-				AddRow(int32(10), "alice", "hash123", int32(100), createdAt),
+			mockPool.NewRows([]string{"id", "username", "password_hash", "coins", "created_at", "role"}).
+				AddRow(int32(10), "alice", "hash123", int32(100), createdAt, db.EmployeeRoleEnumManager),
 		)
 
 	// Вызываем метод
@@ -51,6 +51,7 @@ func TestUserRepository_GetByUsername_Success(t *testing.T) {
 	assert.Equal(t, "alice", user.Username)
 	assert.Equal(t, "hash123", user.PasswordHash)
 	assert.Equal(t, int32(100), user.Coins)
+	assert.Equal(t, "manager", user.Role)
 
 	assert.NoError(t, mockPool.ExpectationsWereMet())
 }
@@ -69,9 +70,9 @@ func TestUserRepository_GetByUsername_NoRows(t *testing.T) {
 
 	// Настраиваем пустой результат, чтобы вернулось sql.ErrNoRows.
 	mockPool.
-		ExpectQuery(`SELECT id, username, password_hash, coins, created_at FROM employees WHERE username = \$1`).
+		ExpectQuery(`SELECT id, username, password_hash, coins, created_at, role FROM employees WHERE username = \$1`).
 		WithArgs(username).
-		WillReturnRows(mockPool.NewRows([]string{"id", "username", "password_hash", "coins", "created_at"})) // без строк
+		WillReturnRows(mockPool.NewRows([]string{"id", "username", "password_hash", "coins", "created_at", "role"})) // без строк
 
 	// Вызываем метод
 	user, err := userRepo.GetByUsername(ctx, username)
@@ -95,11 +96,11 @@ func TestUserRepository_Create_Success(t *testing.T) {
 	passwordHash := "hashedpass"
 
 	mockPool.
-		ExpectQuery(`INSERT INTO employees.*RETURNING id, username, coins, password_hash`).
+		ExpectQuery(`INSERT INTO employees.*RETURNING id, username, coins, password_hash, role`).
 		WithArgs(username, passwordHash).
 		WillReturnRows(
-			mockPool.NewRows([]string{"id", "username", "coins", "password_hash"}).
-				AddRow(int32(11), username, int32(0), passwordHash),
+			mockPool.NewRows([]string{"id", "username", "coins", "password_hash", "role"}).
+				AddRow(int32(11), username, int32(0), passwordHash, db.EmployeeRoleEnumEmployee),
 		)
 
 	user, err := userRepo.Create(ctx, username, passwordHash)
@@ -108,6 +109,7 @@ func TestUserRepository_Create_Success(t *testing.T) {
 	assert.Equal(t, "newuser", user.Username)
 	assert.Equal(t, "hashedpass", user.PasswordHash)
 	assert.Equal(t, int32(0), user.Coins)
+	assert.Equal(t, "employee", user.Role)
 
 	assert.NoError(t, mockPool.ExpectationsWereMet())
 }
@@ -137,3 +139,27 @@ func TestUserRepository_Create_Error(t *testing.T) {
 	assert.Empty(t, user) // Пустой пользователь
 	assert.NoError(t, mockPool.ExpectationsWereMet())
 }
+
+func TestUserRepository_SetRole_Success(t *testing.T) {
+	mockPool, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mockPool.Close()
+
+	queries := db.New(mockPool)
+	userRepo := repository.NewPostgresUserRepository(queries, utils.NewLogger())
+
+	mockPool.
+		ExpectQuery(`UPDATE employees\s+SET role = \$2\s+WHERE username = \$1`).
+		WithArgs("bob", db.EmployeeRoleEnumAdmin).
+		WillReturnRows(
+			mockPool.NewRows([]string{"id", "username", "coins", "password_hash", "role"}).
+				AddRow(int32(12), "bob", int32(1000), "hash", db.EmployeeRoleEnumAdmin),
+		)
+
+	user, err := userRepo.SetRole(context.Background(), "bob", "admin")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(12), user.ID)
+	assert.Equal(t, "admin", user.Role)
+
+	assert.NoError(t, mockPool.ExpectationsWereMet())
+}
@@ -0,0 +1,149 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/par1ram/merch-store/internal/repository"
+	"github.com/par1ram/merch-store/internal/utils"
+)
+
+const (
+	// allowancePeriodLayout — формат периода пособия в запросах и ответах.
+	allowancePeriodLayout = "2006-01"
+	// defaultAllowanceBatchSize используется, если размер порции не задан.
+	defaultAllowanceBatchSize = 500
+)
+
+var ErrInvalidAllowancePeriod = newError(CodeInvalidAllowancePeriod, KindInvalid, "period must be in YYYY-MM format")
+
+// AllowanceRecipient — сотрудник, которому будет начислено пособие.
+type AllowanceRecipient struct {
+	Username string `json:"username"`
+	Amount   int32  `json:"amount"`
+}
+
+// AllowancePreview — результат пробного запуска начисления за период.
+// Сотрудники, уже получившие пособие за период, в список не входят.
+type AllowancePreview struct {
+	Period      string               `json:"period"`
+	Enabled     bool                 `json:"enabled"`
+	Amount      int32                `json:"amount"`
+	TotalAmount int64                `json:"total_amount"`
+	Recipients  []AllowanceRecipient `json:"recipients"`
+}
+
+// AllowanceAccrual — итог начисления пособия за период.
+type AllowanceAccrual struct {
+	Period   string `json:"period"`
+	Credited int    `json:"credited"`
+	Amount   int32  `json:"amount"`
+}
+
+// AllowanceService начисляет ежемесячное пособие всем сотрудникам.
+// Начисление идемпотентно в пределах периода: повторный запуск, в том числе
+// с другой реплики, не начисляет монеты повторно. Нулевая сумма отключает начисление.
+type AllowanceService interface {
+	Accrue(ctx context.Context, now time.Time) (AllowanceAccrual, error)
+	Preview(ctx context.Context, period string) (AllowancePreview, error)
+}
+
+type allowanceService struct {
+	repo      repository.AllowanceRepository
+	amount    int32
+	batchSize int32
+	logger    utils.Logger
+}
+
+func NewAllowanceService(repo repository.AllowanceRepository, amount, batchSize int32, logger utils.Logger) AllowanceService {
+	if batchSize <= 0 {
+		batchSize = defaultAllowanceBatchSize
+	}
+	logger.WithFields(utils.LogFields{
+		"component":  "allowance_service",
+		"amount":     amount,
+		"batch_size": batchSize,
+	}).Info("AllowanceService initialized")
+	return &allowanceService{
+		repo:      repo,
+		amount:    amount,
+		batchSize: batchSize,
+		logger:    logger.WithFields(utils.LogFields{"component": "allowance_service"}),
+	}
+}
+
+// Accrue начисляет пособие за месяц, в который попадает now, порциями по batchSize.
+func (s *allowanceService) Accrue(ctx context.Context, now time.Time) (AllowanceAccrual, error) {
+	period := allowancePeriod(now)
+	result := AllowanceAccrual{Period: period.Format(allowancePeriodLayout), Amount: s.amount}
+	if s.amount <= 0 {
+		return result, nil
+	}
+
+	log := s.logger.WithFields(utils.LogFields{
+		"operation": "accrue_allowance",
+		"period":    result.Period,
+	})
+
+	for {
+		ids, err := s.repo.AccrueBatch(ctx, period, s.amount, s.batchSize)
+		if err != nil {
+			log.WithFields(utils.LogFields{"error": err, "credited": result.Credited}).Error("Allowance accrual failed")
+			return result, err
+		}
+		result.Credited += len(ids)
+		if len(ids) < int(s.batchSize) {
+			break
+		}
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+	}
+
+	if result.Credited > 0 {
+		log.WithFields(utils.LogFields{"credited": result.Credited, "amount": s.amount}).Info("Allowance accrued")
+	}
+	return result, nil
+}
+
+// Preview показывает, кому и сколько будет начислено за период без изменения данных.
+// Пустой period означает текущий месяц.
+func (s *allowanceService) Preview(ctx context.Context, period string) (AllowancePreview, error) {
+	start := allowancePeriod(time.Now())
+	if period != "" {
+		parsed, err := time.Parse(allowancePeriodLayout, period)
+		if err != nil {
+			return AllowancePreview{}, fmt.Errorf("%w: %w", ErrBusinessValidation, ErrInvalidAllowancePeriod)
+		}
+		start = parsed
+	}
+
+	preview := AllowancePreview{
+		Period:     start.Format(allowancePeriodLayout),
+		Enabled:    s.amount > 0,
+		Amount:     s.amount,
+		Recipients: []AllowanceRecipient{},
+	}
+	if !preview.Enabled {
+		return preview, nil
+	}
+
+	rows, err := s.repo.ListRecipients(ctx, start)
+	if err != nil {
+		s.logger.WithFields(utils.LogFields{"error": err, "period": preview.Period}).Error("Failed to preview allowance")
+		return AllowancePreview{}, err
+	}
+
+	for _, row := range rows {
+		preview.Recipients = append(preview.Recipients, AllowanceRecipient{Username: row.Username, Amount: s.amount})
+	}
+	preview.TotalAmount = int64(s.amount) * int64(len(rows))
+	return preview, nil
+}
+
+// allowancePeriod возвращает первый день месяца в UTC.
+func allowancePeriod(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
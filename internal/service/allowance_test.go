@@ -0,0 +1,104 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/par1ram/merch-store/internal/db"
+	"github.com/par1ram/merch-store/internal/service"
+	"github.com/par1ram/merch-store/internal/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockAllowanceRepository struct {
+	mock.Mock
+}
+
+func (m *MockAllowanceRepository) AccrueBatch(ctx context.Context, period time.Time, amount, batchSize int32) ([]int32, error) {
+	args := m.Called(ctx, period, amount, batchSize)
+	if res, ok := args.Get(0).([]int32); ok {
+		return res, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockAllowanceRepository) ListRecipients(ctx context.Context, period time.Time) ([]db.ListAllowanceRecipientsRow, error) {
+	args := m.Called(ctx, period)
+	if res, ok := args.Get(0).([]db.ListAllowanceRecipientsRow); ok {
+		return res, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func TestAllowanceAccrue_Batches(t *testing.T) {
+	ctx := context.Background()
+	period := time.Date(2026, time.October, 1, 0, 0, 0, 0, time.UTC)
+
+	repo := new(MockAllowanceRepository)
+	repo.On("AccrueBatch", ctx, period, int32(100), int32(2)).Return([]int32{1, 2}, nil).Once()
+	repo.On("AccrueBatch", ctx, period, int32(100), int32(2)).Return([]int32{3}, nil).Once()
+
+	svc := service.NewAllowanceService(repo, 100, 2, utils.NewLogger())
+	result, err := svc.Accrue(ctx, time.Date(2026, time.October, 17, 9, 30, 0, 0, time.UTC))
+	assert.NoError(t, err)
+	assert.Equal(t, service.AllowanceAccrual{Period: "2026-10", Credited: 3, Amount: 100}, result)
+
+	repo.AssertExpectations(t)
+}
+
+func TestAllowanceAccrue_AlreadyAccrued(t *testing.T) {
+	ctx := context.Background()
+	period := time.Date(2026, time.October, 1, 0, 0, 0, 0, time.UTC)
+
+	repo := new(MockAllowanceRepository)
+	repo.On("AccrueBatch", ctx, period, int32(100), int32(500)).Return([]int32{}, nil).Once()
+
+	svc := service.NewAllowanceService(repo, 100, 0, utils.NewLogger())
+	result, err := svc.Accrue(ctx, period)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, result.Credited)
+
+	repo.AssertExpectations(t)
+}
+
+func TestAllowanceAccrue_Disabled(t *testing.T) {
+	repo := new(MockAllowanceRepository)
+	svc := service.NewAllowanceService(repo, 0, 500, utils.NewLogger())
+
+	result, err := svc.Accrue(context.Background(), time.Now())
+	assert.NoError(t, err)
+	assert.Equal(t, 0, result.Credited)
+	repo.AssertNotCalled(t, "AccrueBatch", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestAllowancePreview(t *testing.T) {
+	ctx := context.Background()
+	period := time.Date(2026, time.November, 1, 0, 0, 0, 0, time.UTC)
+
+	repo := new(MockAllowanceRepository)
+	repo.On("ListRecipients", ctx, period).Return([]db.ListAllowanceRecipientsRow{
+		{ID: 1, Username: "alice"},
+		{ID: 2, Username: "bob"},
+	}, nil).Once()
+
+	svc := service.NewAllowanceService(repo, 100, 500, utils.NewLogger())
+	preview, err := svc.Preview(ctx, "2026-11")
+	assert.NoError(t, err)
+	assert.Equal(t, service.AllowancePreview{
+		Period:      "2026-11",
+		Enabled:     true,
+		Amount:      100,
+		TotalAmount: 200,
+		Recipients: []service.AllowanceRecipient{
+			{Username: "alice", Amount: 100},
+			{Username: "bob", Amount: 100},
+		},
+	}, preview)
+
+	_, err = svc.Preview(ctx, "November")
+	assert.ErrorIs(t, err, service.ErrInvalidAllowancePeriod)
+
+	repo.AssertExpectations(t)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/jackc/pgx"
@@ -14,9 +15,22 @@ import (
 	"golang.org/x/crypto/bcrypt"
 )
 
-// AuthService определяет интерфейс для аутентификации.
+// Роли сотрудников (employees.role). Они же передаются в claims токена.
+const (
+	RoleEmployee = "employee"
+	RoleManager  = "manager"
+	RoleAdmin    = "admin"
+)
+
+var (
+	ErrUnknownRole  = newError(CodeUnknownRole, KindInvalid, "unknown role")
+	ErrUserNotFound = newError(CodeUserNotFound, KindNotFound, "user not found")
+)
+
+// AuthService определяет интерфейс для аутентификации и управления ролями.
 type AuthService interface {
 	Authenticate(ctx context.Context, username, password string) (string, error)
+	SetRole(ctx context.Context, username, role string) (repository.User, error)
 }
 
 // authService — конкретная реализация AuthService.
@@ -83,12 +97,42 @@ func (s *authService) Authenticate(ctx context.Context, username, password strin
 	return token, nil
 }
 
+// SetRole назначает пользователю роль. Административные маршруты проверяют
+// роль по базе, поэтому смена действует сразу; в токен новая роль попадает
+// при следующей аутентификации пользователя.
+func (s *authService) SetRole(ctx context.Context, username, role string) (repository.User, error) {
+	switch role {
+	case RoleEmployee, RoleManager, RoleAdmin:
+	default:
+		s.logger.Warnf("Unknown role %q requested for user %s", role, username)
+		return repository.User{}, fmt.Errorf("%w: %w", ErrBusinessValidation, ErrUnknownRole)
+	}
+
+	user, err := s.userRepo.SetRole(ctx, username, role)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			s.logger.Warnf("User %s not found, role not changed", username)
+			return repository.User{}, fmt.Errorf("%w: %w", ErrBusinessValidation, ErrUserNotFound)
+		}
+		s.logger.Errorf("Error setting role for user %s: %v", username, err)
+		return repository.User{}, err
+	}
+
+	s.logger.Infof("User %s now has role %s", username, role)
+	return user, nil
+}
+
 // generateJWT создаёт JWT-токен с информацией о пользователе.
 func generateJWT(user repository.User, jwtSecret []byte) (string, error) {
 	expirationTime := time.Now().Add(24 * time.Hour)
+	role := user.Role
+	if role == "" {
+		role = RoleEmployee
+	}
 	claims := jwt.MapClaims{
 		"user_id":  user.ID,
 		"username": user.Username,
+		"role":     role,
 		"exp":      expirationTime.Unix(),
 		"iat":      time.Now().Unix(),
 	}
@@ -25,6 +25,11 @@ func (m *MockUserRepository) GetByUsername(ctx context.Context, username string)
 	return args.Get(0).(repository.User), args.Error(1)
 }
 
+func (m *MockUserRepository) SetRole(ctx context.Context, username, role string) (repository.User, error) {
+	args := m.Called(ctx, username, role)
+	return args.Get(0).(repository.User), args.Error(1)
+}
+
 func (m *MockUserRepository) Create(ctx context.Context, username, passwordHash string) (repository.User, error) {
 	args := m.Called(ctx, username, passwordHash)
 	return args.Get(0).(repository.User), args.Error(1)
@@ -74,6 +79,7 @@ func TestAuthService_UserNotFound_CreateUser(t *testing.T) {
 	claims := extractClaims(t, token, jwtSecret)
 	assert.Equal(t, float64(10), claims["user_id"])
 	assert.Equal(t, username, claims["username"])
+	assert.Equal(t, "employee", claims["role"])
 }
 
 func TestAuthService_UserFound_CorrectPassword(t *testing.T) {
@@ -107,6 +113,52 @@ func TestAuthService_UserFound_CorrectPassword(t *testing.T) {
 	assert.Equal(t, username, claims["username"])
 }
 
+func TestAuthService_RoleInClaims(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	jwtSecret := []byte("secret")
+	authSvc := service.NewAuthService(mockRepo, jwtSecret, utils.NewLogger())
+
+	ctx := context.Background()
+	hashBytes, _ := bcrypt.GenerateFromPassword([]byte("adminpass"), bcrypt.DefaultCost)
+	admin := userStub(1, "root", string(hashBytes))
+	admin.Role = "admin"
+
+	mockRepo.On("GetByUsername", ctx, "root").Return(admin, nil).Once()
+
+	token, err := authSvc.Authenticate(ctx, "root", "adminpass")
+	assert.NoError(t, err)
+
+	claims := extractClaims(t, token, jwtSecret)
+	assert.Equal(t, "admin", claims["role"])
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestAuthService_SetRole(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	authSvc := service.NewAuthService(mockRepo, []byte("secret"), utils.NewLogger())
+	ctx := context.Background()
+
+	manager := userStub(2, "bob", "hash")
+	manager.Role = "manager"
+	mockRepo.On("SetRole", ctx, "bob", "manager").Return(manager, nil).Once()
+
+	user, err := authSvc.SetRole(ctx, "bob", "manager")
+	assert.NoError(t, err)
+	assert.Equal(t, "manager", user.Role)
+
+	// Неизвестная роль не доходит до репозитория.
+	_, err = authSvc.SetRole(ctx, "bob", "superuser")
+	assert.ErrorIs(t, err, service.ErrUnknownRole)
+
+	// Несуществующий пользователь.
+	mockRepo.On("SetRole", ctx, "ghost", "admin").Return(repository.User{}, sql.ErrNoRows).Once()
+	_, err = authSvc.SetRole(ctx, "ghost", "admin")
+	assert.ErrorIs(t, err, service.ErrUserNotFound)
+
+	mockRepo.AssertExpectations(t)
+}
+
 func TestAuthService_UserFound_InvalidPassword(t *testing.T) {
 	mockRepo := new(MockUserRepository)
 	jwtSecret := []byte("secret")
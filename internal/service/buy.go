@@ -2,8 +2,10 @@ package service
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"fmt"
+	"math"
 
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/par1ram/merch-store/internal/db"
@@ -12,9 +14,16 @@ import (
 	"github.com/par1ram/merch-store/internal/utils"
 )
 
+var (
+	// ErrOutOfStock возвращается, когда остаток товара на складе исчерпан.
+	ErrOutOfStock      = newError(CodeOutOfStock, KindConflict, "out of stock")
+	ErrInvalidQuantity = newError(CodeInvalidQuantity, KindInvalid, "quantity must be positive")
+)
+
 // BuyService определяет метод покупки товара.
 type BuyService interface {
-	Purchase(ctx context.Context, item string) error
+	Purchase(ctx context.Context, item string, quantity int32) error
+	Checkout(ctx context.Context) (CartResponse, error)
 }
 
 type buyService struct {
@@ -30,57 +39,88 @@ func NewBuyService(repo repository.BuyRepository, logger utils.Logger) BuyServic
 	}
 }
 
-func (s *buyService) Purchase(ctx context.Context, item string) error {
+// Purchase покупает quantity единиц товара одной транзакцией.
+func (s *buyService) Purchase(ctx context.Context, item string, quantity int32) error {
 	// Извлекаем идентификатор пользователя из контекста.
 	userID := middleware.GetUserIDFromContext(ctx)
 	if userID == 0 {
 		s.logger.Error("User not authenticated")
-		return errors.New("user not authenticated")
+		return ErrUnauthenticated
 	}
-	s.logger.Infof("Processing purchase; userID=%d, item=%s", userID, item)
+	if quantity <= 0 {
+		s.logger.Warnf("Invalid quantity; userID=%d, item=%s, quantity=%d", userID, item, quantity)
+		return fmt.Errorf("%w: %w", ErrBusinessValidation, ErrInvalidQuantity)
+	}
+	s.logger.Infof("Processing purchase; userID=%d, item=%s, quantity=%d", userID, item, quantity)
 
 	// Получаем информацию о товаре.
 	merch, err := s.repo.GetMerch(ctx, item)
 	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			s.logger.Warnf("Merch not found; userID=%d, item=%s", userID, item)
+			return fmt.Errorf("%w: %w: %s", ErrBusinessValidation, ErrMerchNotFound, item)
+		}
 		s.logger.WithFields(map[string]interface{}{
 			"error":   err,
 			"item":    item,
 			"user_id": userID,
 		}).Error("Failed to get merch")
-		return fmt.Errorf("merch not found: %w", err)
+		return fmt.Errorf("failed to get merch: %w", err)
 	}
 	s.logger.Infof("Merch found; item=%s, price=%d", merch.Name, merch.Price)
 
-	// Проверяем, достаточно ли средств у пользователя.
-	balance, err := s.repo.GetBalance(ctx, int32(userID))
-	if err != nil {
-		s.logger.WithFields(map[string]interface{}{
-			"error":   err,
-			"user_id": userID,
-		}).Error("Failed to get user balance")
-		return fmt.Errorf("failed to get balance: %w", err)
+	// Быстрая проверка остатка; окончательная выполняется в транзакции.
+	if merch.Stock.Valid && merch.Stock.Int32 < quantity {
+		s.logger.Warnf("Out of stock; userID=%d, item=%s", userID, merch.Name)
+		return fmt.Errorf("%w: %w", ErrBusinessValidation, ErrOutOfStock)
 	}
-	if balance < merch.Price {
-		s.logger.Warnf("Insufficient funds; userID=%d, balance=%d, price=%d", userID, balance, merch.Price)
-		return errors.New("insufficient funds")
+
+	// Сумма считается в int64, чтобы большое количество не переполнило int32;
+	// сумма больше int32 заведомо превышает любой баланс.
+	total := int64(merch.Price) * int64(quantity)
+	if total > math.MaxInt32 {
+		s.logger.Warnf("Insufficient funds; userID=%d, total=%d", userID, total)
+		return fmt.Errorf("%w: %w", ErrBusinessValidation, ErrInsufficientFunds)
 	}
+	amount := int32(total)
 
 	// Запускаем транзакцию для покупки товара.
 	err = s.repo.ExecTx(ctx, func(r repository.BuyRepository) error {
+		// Баланс проверяется под блокировкой строки покупателя: параллельная
+		// покупка или перевод дождутся конца транзакции.
+		balance, err := r.LockBalance(ctx, int32(userID))
+		if err != nil {
+			return fmt.Errorf("failed to get balance: %w", err)
+		}
+		if balance < amount {
+			s.logger.Warnf("Insufficient funds; userID=%d, balance=%d, total=%d", userID, balance, total)
+			return fmt.Errorf("%w: %w", ErrBusinessValidation, ErrInsufficientFunds)
+		}
+
 		// Списываем монеты с баланса пользователя.
-		affected, err := r.DeductCoins(ctx, int32(userID), merch.Price)
+		affected, err := r.DeductCoins(ctx, int32(userID), amount)
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return fmt.Errorf("%w: %w", ErrBusinessValidation, ErrInsufficientFunds)
+		}
+
+		// Уменьшаем остаток на складе. Если товар закончился у параллельного
+		// покупателя, транзакция откатывается вместе со списанием монет.
+		affected, err = r.DecrementStock(ctx, merch.ID, quantity)
 		if err != nil {
 			return err
 		}
 		if affected == 0 {
-			return errors.New("failed to deduct coins")
+			return fmt.Errorf("%w: %w", ErrBusinessValidation, ErrOutOfStock)
 		}
 
-		// Обновляем инвентарь: добавляем единицу купленного товара.
+		// Обновляем инвентарь: добавляем купленные единицы товара.
 		upsertParams := db.UpsertInventoryParams{
 			EmployeeID: int32(userID),
 			MerchID:    merch.ID,
-			Quantity:   1,
+			Quantity:   quantity,
 		}
 		if err := r.UpsertInventory(ctx, upsertParams); err != nil {
 			return err
@@ -90,12 +130,22 @@ func (s *buyService) Purchase(ctx context.Context, item string) error {
 		purchaseParams := db.CreateCoinTransactionPurchaseParams{
 			FromEmployeeID: int32(userID),
 			MerchID:        pgtype.Int4{Int32: merch.ID, Valid: true},
-			Amount:         merch.Price,
+			Amount:         amount,
+			Quantity:       quantity,
+			UnitPrice:      pgtype.Int4{Int32: merch.Price, Valid: true},
 		}
-		if err := r.CreatePurchaseTransaction(ctx, purchaseParams); err != nil {
+		purchaseID, err := r.CreatePurchaseTransaction(ctx, purchaseParams)
+		if err != nil {
 			return err
 		}
-		return nil
+
+		// Создаём заказ на выдачу купленного товара.
+		return r.CreateOrder(ctx, db.CreateOrderParams{
+			PurchaseID: purchaseID,
+			EmployeeID: int32(userID),
+			MerchID:    merch.ID,
+			Quantity:   quantity,
+		})
 	})
 	if err != nil {
 		s.logger.WithFields(map[string]interface{}{
@@ -106,6 +156,107 @@ func (s *buyService) Purchase(ctx context.Context, item string) error {
 		return err
 	}
 
-	s.logger.Infof("Purchase successful; userID=%d, item=%s, price=%d", userID, merch.Name, merch.Price)
+	s.logger.Infof("Purchase successful; userID=%d, item=%s, quantity=%d, amount=%d", userID, merch.Name, quantity, amount)
 	return nil
 }
+
+// Checkout оформляет всю корзину сотрудника в одной транзакции: либо
+// покупаются все позиции, либо изменения откатываются целиком.
+func (s *buyService) Checkout(ctx context.Context) (CartResponse, error) {
+	userID := middleware.GetUserIDFromContext(ctx)
+	if userID == 0 {
+		s.logger.Error("User not authenticated")
+		return CartResponse{}, ErrUnauthenticated
+	}
+	s.logger.Infof("Processing checkout; userID=%d", userID)
+
+	var receipt CartResponse
+	err := s.repo.ExecTx(ctx, func(r repository.BuyRepository) error {
+		// Корзина читается внутри транзакции, чтобы цены и состав
+		// совпадали с тем, что будет списано.
+		items, err := r.ListCartItems(ctx, int32(userID))
+		if err != nil {
+			return err
+		}
+		if len(items) == 0 {
+			return fmt.Errorf("%w: %w", ErrBusinessValidation, ErrCartEmpty)
+		}
+
+		receipt = priceCart(items)
+		for _, line := range receipt.Items {
+			if !line.Available {
+				return fmt.Errorf("%w: %w: %s", ErrBusinessValidation, ErrCartItemUnavailable, line.Item)
+			}
+		}
+
+		balance, err := r.LockBalance(ctx, int32(userID))
+		if err != nil {
+			return err
+		}
+		if int64(balance) < receipt.Total {
+			s.logger.Warnf("Insufficient funds; userID=%d, balance=%d, total=%d", userID, balance, receipt.Total)
+			return fmt.Errorf("%w: %w", ErrBusinessValidation, ErrInsufficientFunds)
+		}
+
+		affected, err := r.DeductCoins(ctx, int32(userID), int32(receipt.Total))
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return fmt.Errorf("%w: %w", ErrBusinessValidation, ErrInsufficientFunds)
+		}
+
+		for i, item := range items {
+			affected, err := r.DecrementStock(ctx, item.MerchID, item.Quantity)
+			if err != nil {
+				return err
+			}
+			if affected == 0 {
+				return fmt.Errorf("%w: %w: %s", ErrBusinessValidation, ErrOutOfStock, item.MerchName)
+			}
+
+			upsertParams := db.UpsertInventoryParams{
+				EmployeeID: int32(userID),
+				MerchID:    item.MerchID,
+				Quantity:   item.Quantity,
+			}
+			if err := r.UpsertInventory(ctx, upsertParams); err != nil {
+				return err
+			}
+
+			purchaseParams := db.CreateCoinTransactionPurchaseParams{
+				FromEmployeeID: int32(userID),
+				MerchID:        pgtype.Int4{Int32: item.MerchID, Valid: true},
+				Amount:         int32(receipt.Items[i].Amount),
+				Quantity:       item.Quantity,
+				UnitPrice:      pgtype.Int4{Int32: item.Price, Valid: true},
+			}
+			purchaseID, err := r.CreatePurchaseTransaction(ctx, purchaseParams)
+			if err != nil {
+				return err
+			}
+
+			orderParams := db.CreateOrderParams{
+				PurchaseID: purchaseID,
+				EmployeeID: int32(userID),
+				MerchID:    item.MerchID,
+				Quantity:   item.Quantity,
+			}
+			if err := r.CreateOrder(ctx, orderParams); err != nil {
+				return err
+			}
+		}
+
+		return r.ClearCart(ctx, int32(userID))
+	})
+	if err != nil {
+		s.logger.WithFields(map[string]interface{}{
+			"error":   err,
+			"user_id": userID,
+		}).Error("Checkout transaction failed")
+		return CartResponse{}, err
+	}
+
+	s.logger.Infof("Checkout successful; userID=%d, items=%d, total=%d", userID, len(receipt.Items), receipt.Total)
+	return receipt, nil
+}
@@ -2,10 +2,12 @@ package service_test
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"testing"
 
 	"github.com/golang-jwt/jwt/v4"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/par1ram/merch-store/internal/db"
 	"github.com/par1ram/merch-store/internal/middleware"
 	"github.com/par1ram/merch-store/internal/repository"
@@ -32,7 +34,7 @@ func (m *MockBuyRepository) GetMerch(ctx context.Context, merchName string) (db.
 	return args.Get(0).(db.Merch), args.Error(1)
 }
 
-func (m *MockBuyRepository) GetBalance(ctx context.Context, userID int32) (int32, error) {
+func (m *MockBuyRepository) LockBalance(ctx context.Context, userID int32) (int32, error) {
 	args := m.Called(ctx, userID)
 	return args.Get(0).(int32), args.Error(1)
 }
@@ -42,16 +44,39 @@ func (m *MockBuyRepository) DeductCoins(ctx context.Context, userID, amount int3
 	return args.Get(0).(int64), args.Error(1)
 }
 
+func (m *MockBuyRepository) DecrementStock(ctx context.Context, merchID, quantity int32) (int64, error) {
+	args := m.Called(ctx, merchID, quantity)
+	return args.Get(0).(int64), args.Error(1)
+}
+
 func (m *MockBuyRepository) UpsertInventory(ctx context.Context, params db.UpsertInventoryParams) error {
 	args := m.Called(ctx, params)
 	return args.Error(0)
 }
 
-func (m *MockBuyRepository) CreatePurchaseTransaction(ctx context.Context, params db.CreateCoinTransactionPurchaseParams) error {
+func (m *MockBuyRepository) CreatePurchaseTransaction(ctx context.Context, params db.CreateCoinTransactionPurchaseParams) (int32, error) {
+	args := m.Called(ctx, params)
+	return args.Get(0).(int32), args.Error(1)
+}
+
+func (m *MockBuyRepository) CreateOrder(ctx context.Context, params db.CreateOrderParams) error {
 	args := m.Called(ctx, params)
 	return args.Error(0)
 }
 
+func (m *MockBuyRepository) ListCartItems(ctx context.Context, employeeID int32) ([]db.ListCartItemsRow, error) {
+	args := m.Called(ctx, employeeID)
+	if res, ok := args.Get(0).([]db.ListCartItemsRow); ok {
+		return res, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockBuyRepository) ClearCart(ctx context.Context, employeeID int32) error {
+	args := m.Called(ctx, employeeID)
+	return args.Error(0)
+}
+
 func TestPurchase_Success(t *testing.T) {
 	// 1) Создаём контекст с user_id
 	claims := jwt.MapClaims{"user_id": 123.0}
@@ -67,7 +92,7 @@ func TestPurchase_Success(t *testing.T) {
 		Price: int32(100),
 	}
 	repoMock.On("GetMerch", ctx, "T-Shirt").Return(merchData, nil).Once()
-	repoMock.On("GetBalance", ctx, int32(123)).Return(int32(200), nil).Once()
+	repoMock.On("LockBalance", ctx, int32(123)).Return(int32(200), nil).Once()
 
 	// 4) Настраиваем ExecTx (не делаем Run(func(...){...}) — достаточно Return(nil)),
 	//    потому что в MockBuyRepository.ExecTx уже есть “return fn(m)”.
@@ -78,17 +103,28 @@ func TestPurchase_Success(t *testing.T) {
 	repoMock.On("DeductCoins", mock.Anything, int32(123), int32(100)).
 		Return(int64(1), nil).
 		Once()
+	repoMock.On("DecrementStock", mock.Anything, int32(1), int32(1)).
+		Return(int64(1), nil).
+		Once()
 	repoMock.On("UpsertInventory", mock.Anything, mock.Anything).
 		Return(nil).
 		Once()
 	repoMock.On("CreatePurchaseTransaction", mock.Anything, mock.Anything).
+		Return(int32(42), nil).
+		Once()
+	repoMock.On("CreateOrder", mock.Anything, db.CreateOrderParams{
+		PurchaseID: 42,
+		EmployeeID: 123,
+		MerchID:    1,
+		Quantity:   1,
+	}).
 		Return(nil).
 		Once()
 
 	// 6) Вызываем сервис
 	logger := utils.NewLogger()
 	buySvc := service.NewBuyService(repoMock, logger)
-	err := buySvc.Purchase(ctx, "T-Shirt")
+	err := buySvc.Purchase(ctx, "T-Shirt", 1)
 	assert.NoError(t, err)
 
 	// 7) Проверяем ожидания
@@ -104,7 +140,7 @@ func TestPurchase_UserNotAuthenticated(t *testing.T) {
 	logger := utils.NewLogger()
 	buySvc := service.NewBuyService(repoMock, logger)
 
-	err := buySvc.Purchase(ctx, "T-Shirt")
+	err := buySvc.Purchase(ctx, "T-Shirt", 1)
 	assert.Error(t, err)
 	assert.Equal(t, "user not authenticated", err.Error())
 }
@@ -122,15 +158,16 @@ func TestPurchase_InsufficientFunds(t *testing.T) {
 		Price: 150,
 	}
 	repoMock.On("GetMerch", ctx, merchItem).Return(merchData, nil).Once()
+	repoMock.On("ExecTx", ctx, mock.AnythingOfType("func(repository.BuyRepository) error")).Return(nil).Once()
 	// Возвращаем баланс меньше цены товара.
-	repoMock.On("GetBalance", ctx, int32(123)).Return(int32(100), nil).Once()
+	repoMock.On("LockBalance", ctx, int32(123)).Return(int32(100), nil).Once()
 
 	logger := utils.NewLogger()
 	buySvc := service.NewBuyService(repoMock, logger)
 
-	err := buySvc.Purchase(ctx, merchItem)
-	assert.Error(t, err)
-	assert.Equal(t, "insufficient funds", err.Error())
+	err := buySvc.Purchase(ctx, merchItem, 1)
+	assert.ErrorIs(t, err, service.ErrInsufficientFunds)
+	assert.ErrorIs(t, err, service.ErrBusinessValidation)
 
 	repoMock.AssertExpectations(t)
 }
@@ -148,13 +185,34 @@ func TestPurchase_GetMerchError(t *testing.T) {
 	logger := utils.NewLogger()
 	buySvc := service.NewBuyService(repoMock, logger)
 
-	err := buySvc.Purchase(ctx, merchItem)
+	err := buySvc.Purchase(ctx, merchItem, 1)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "merch not found")
 
 	repoMock.AssertExpectations(t)
 }
 
+// TestPurchase_MerchNotFound проверяет, что отсутствующий товар возвращается как бизнес-ошибка с кодом.
+func TestPurchase_MerchNotFound(t *testing.T) {
+	claims := jwt.MapClaims{"user_id": 123.0}
+	ctx := context.WithValue(context.Background(), middleware.UserCtxKey, claims)
+
+	repoMock := new(MockBuyRepository)
+	repoMock.On("GetMerch", ctx, "ghost").Return(db.Merch{}, sql.ErrNoRows).Once()
+
+	buySvc := service.NewBuyService(repoMock, utils.NewLogger())
+
+	err := buySvc.Purchase(ctx, "ghost", 1)
+	assert.ErrorIs(t, err, service.ErrMerchNotFound)
+	assert.ErrorIs(t, err, service.ErrBusinessValidation)
+
+	var serviceErr *service.Error
+	assert.True(t, errors.As(err, &serviceErr))
+	assert.Equal(t, service.CodeItemNotFound, serviceErr.Code)
+
+	repoMock.AssertExpectations(t)
+}
+
 func TestPurchase_DeductCoinsFailure(t *testing.T) {
 	claims := jwt.MapClaims{"user_id": 123.0}
 	ctx := context.WithValue(context.Background(), middleware.UserCtxKey, claims)
@@ -169,13 +227,13 @@ func TestPurchase_DeductCoinsFailure(t *testing.T) {
 
 	// "До транзакции"
 	repoMock.On("GetMerch", ctx, merchItem).Return(merchData, nil).Once()
-	repoMock.On("GetBalance", ctx, int32(123)).Return(int32(200), nil).Once()
 
 	// ExecTx
 	repoMock.On("ExecTx", ctx, mock.AnythingOfType("func(repository.BuyRepository) error")).
 		Return(nil).Once()
 
 	// "Внутри транзакции"
+	repoMock.On("LockBalance", ctx, int32(123)).Return(int32(200), nil).Once()
 	// Возвращаем (int64(0), deductErr)
 	deductErr := errors.New("failed to deduct coins")
 	repoMock.On("DeductCoins", mock.Anything, int32(123), int32(100)).
@@ -183,9 +241,232 @@ func TestPurchase_DeductCoinsFailure(t *testing.T) {
 		Once()
 
 	buySvc := service.NewBuyService(repoMock, utils.NewLogger())
-	err := buySvc.Purchase(ctx, merchItem)
+	err := buySvc.Purchase(ctx, merchItem, 1)
 	assert.Error(t, err)
 	assert.Equal(t, deductErr, err)
 
 	repoMock.AssertExpectations(t)
 }
+
+// TestPurchase_DeductCoinsNoRows проверяет, что списание, не изменившее
+// ни одной строки, считается нехваткой средств, а не внутренней ошибкой.
+func TestPurchase_DeductCoinsNoRows(t *testing.T) {
+	claims := jwt.MapClaims{"user_id": 123.0}
+	ctx := context.WithValue(context.Background(), middleware.UserCtxKey, claims)
+
+	repoMock := new(MockBuyRepository)
+	repoMock.On("GetMerch", ctx, "cup").Return(db.Merch{ID: 2, Name: "cup", Price: 20}, nil).Once()
+	repoMock.On("ExecTx", ctx, mock.Anything).Return(nil).Once()
+	repoMock.On("LockBalance", ctx, int32(123)).Return(int32(20), nil).Once()
+	repoMock.On("DeductCoins", ctx, int32(123), int32(20)).Return(int64(0), nil).Once()
+
+	buySvc := service.NewBuyService(repoMock, utils.NewLogger())
+	err := buySvc.Purchase(ctx, "cup", 1)
+	assert.ErrorIs(t, err, service.ErrInsufficientFunds)
+
+	repoMock.AssertNotCalled(t, "DecrementStock", mock.Anything, mock.Anything, mock.Anything)
+	repoMock.AssertExpectations(t)
+}
+
+// TestPurchase_OutOfStock проверяет, что закончившийся товар не продаётся.
+func TestPurchase_OutOfStock(t *testing.T) {
+	claims := jwt.MapClaims{"user_id": 123.0}
+	ctx := context.WithValue(context.Background(), middleware.UserCtxKey, claims)
+
+	repoMock := new(MockBuyRepository)
+	merchData := db.Merch{
+		ID:    6,
+		Name:  "pink-hoody",
+		Price: 500,
+		Stock: pgtype.Int4{Int32: 0, Valid: true},
+	}
+	repoMock.On("GetMerch", ctx, "pink-hoody").Return(merchData, nil).Once()
+
+	buySvc := service.NewBuyService(repoMock, utils.NewLogger())
+	err := buySvc.Purchase(ctx, "pink-hoody", 1)
+	assert.ErrorIs(t, err, service.ErrOutOfStock)
+
+	repoMock.AssertExpectations(t)
+	repoMock.AssertNotCalled(t, "ExecTx", mock.Anything, mock.Anything)
+}
+
+// TestPurchase_SoldOutConcurrently проверяет случай, когда последнюю единицу
+// купили параллельно: остаток не уменьшился, транзакция откатывается.
+func TestPurchase_SoldOutConcurrently(t *testing.T) {
+	claims := jwt.MapClaims{"user_id": 123.0}
+	ctx := context.WithValue(context.Background(), middleware.UserCtxKey, claims)
+
+	repoMock := new(MockBuyRepository)
+	merchData := db.Merch{
+		ID:    6,
+		Name:  "pink-hoody",
+		Price: 500,
+		Stock: pgtype.Int4{Int32: 1, Valid: true},
+	}
+	repoMock.On("GetMerch", ctx, "pink-hoody").Return(merchData, nil).Once()
+	repoMock.On("LockBalance", ctx, int32(123)).Return(int32(1000), nil).Once()
+	repoMock.On("ExecTx", ctx, mock.AnythingOfType("func(repository.BuyRepository) error")).Return(nil).Once()
+	repoMock.On("DeductCoins", mock.Anything, int32(123), int32(500)).Return(int64(1), nil).Once()
+	repoMock.On("DecrementStock", mock.Anything, int32(6), int32(1)).Return(int64(0), nil).Once()
+
+	buySvc := service.NewBuyService(repoMock, utils.NewLogger())
+	err := buySvc.Purchase(ctx, "pink-hoody", 1)
+	assert.ErrorIs(t, err, service.ErrOutOfStock)
+
+	repoMock.AssertExpectations(t)
+	repoMock.AssertNotCalled(t, "UpsertInventory", mock.Anything, mock.Anything)
+}
+
+// TestPurchase_MultipleUnits проверяет, что при покупке нескольких единиц
+// списывается полная сумма, а в истории сохраняются количество и цена за единицу.
+func TestPurchase_MultipleUnits(t *testing.T) {
+	claims := jwt.MapClaims{"user_id": 123.0}
+	ctx := context.WithValue(context.Background(), middleware.UserCtxKey, claims)
+
+	repoMock := new(MockBuyRepository)
+	merchData := db.Merch{ID: 3, Name: "socks", Price: 10}
+	repoMock.On("GetMerch", ctx, "socks").Return(merchData, nil).Once()
+	repoMock.On("LockBalance", ctx, int32(123)).Return(int32(100), nil).Once()
+	repoMock.On("ExecTx", ctx, mock.AnythingOfType("func(repository.BuyRepository) error")).Return(nil).Once()
+	repoMock.On("DeductCoins", mock.Anything, int32(123), int32(40)).Return(int64(1), nil).Once()
+	repoMock.On("DecrementStock", mock.Anything, int32(3), int32(4)).Return(int64(1), nil).Once()
+	repoMock.On("UpsertInventory", mock.Anything, db.UpsertInventoryParams{
+		EmployeeID: 123,
+		MerchID:    3,
+		Quantity:   4,
+	}).Return(nil).Once()
+	repoMock.On("CreatePurchaseTransaction", mock.Anything, db.CreateCoinTransactionPurchaseParams{
+		FromEmployeeID: 123,
+		MerchID:        pgtype.Int4{Int32: 3, Valid: true},
+		Amount:         40,
+		Quantity:       4,
+		UnitPrice:      pgtype.Int4{Int32: 10, Valid: true},
+	}).Return(int32(42), nil).Once()
+	repoMock.On("CreateOrder", mock.Anything, db.CreateOrderParams{
+		PurchaseID: 42,
+		EmployeeID: 123,
+		MerchID:    3,
+		Quantity:   4,
+	}).Return(nil).Once()
+
+	buySvc := service.NewBuyService(repoMock, utils.NewLogger())
+	err := buySvc.Purchase(ctx, "socks", 4)
+	assert.NoError(t, err)
+
+	repoMock.AssertExpectations(t)
+}
+
+// TestPurchase_InvalidQuantity проверяет, что неположительное количество отклоняется.
+func TestPurchase_InvalidQuantity(t *testing.T) {
+	claims := jwt.MapClaims{"user_id": 123.0}
+	ctx := context.WithValue(context.Background(), middleware.UserCtxKey, claims)
+
+	repoMock := new(MockBuyRepository)
+	buySvc := service.NewBuyService(repoMock, utils.NewLogger())
+
+	err := buySvc.Purchase(ctx, "socks", 0)
+	assert.ErrorIs(t, err, service.ErrInvalidQuantity)
+
+	repoMock.AssertNotCalled(t, "GetMerch", mock.Anything, mock.Anything)
+}
+
+func checkoutCart() []db.ListCartItemsRow {
+	return []db.ListCartItemsRow{
+		{MerchID: 2, MerchName: "cup", Price: 20, Active: true, Quantity: 1},
+		{MerchID: 3, MerchName: "socks", Price: 10, Active: true, Quantity: 3},
+	}
+}
+
+// TestCheckout_Success проверяет, что все позиции корзины покупаются в одной транзакции.
+func TestCheckout_Success(t *testing.T) {
+	claims := jwt.MapClaims{"user_id": 123.0}
+	ctx := context.WithValue(context.Background(), middleware.UserCtxKey, claims)
+
+	repoMock := new(MockBuyRepository)
+	repoMock.On("ExecTx", ctx, mock.AnythingOfType("func(repository.BuyRepository) error")).Return(nil).Once()
+	repoMock.On("ListCartItems", ctx, int32(123)).Return(checkoutCart(), nil).Once()
+	repoMock.On("LockBalance", ctx, int32(123)).Return(int32(100), nil).Once()
+	repoMock.On("DeductCoins", ctx, int32(123), int32(50)).Return(int64(1), nil).Once()
+	repoMock.On("DecrementStock", ctx, int32(2), int32(1)).Return(int64(1), nil).Once()
+	repoMock.On("DecrementStock", ctx, int32(3), int32(3)).Return(int64(1), nil).Once()
+	repoMock.On("UpsertInventory", ctx, mock.Anything).Return(nil).Twice()
+	repoMock.On("CreatePurchaseTransaction", ctx, db.CreateCoinTransactionPurchaseParams{
+		FromEmployeeID: 123,
+		MerchID:        pgtype.Int4{Int32: 3, Valid: true},
+		Amount:         30,
+		Quantity:       3,
+		UnitPrice:      pgtype.Int4{Int32: 10, Valid: true},
+	}).Return(int32(43), nil).Once()
+	repoMock.On("CreatePurchaseTransaction", ctx, mock.Anything).Return(int32(42), nil).Once()
+	repoMock.On("CreateOrder", ctx, db.CreateOrderParams{PurchaseID: 42, EmployeeID: 123, MerchID: 2, Quantity: 1}).Return(nil).Once()
+	repoMock.On("CreateOrder", ctx, db.CreateOrderParams{PurchaseID: 43, EmployeeID: 123, MerchID: 3, Quantity: 3}).Return(nil).Once()
+	repoMock.On("ClearCart", ctx, int32(123)).Return(nil).Once()
+
+	buySvc := service.NewBuyService(repoMock, utils.NewLogger())
+	receipt, err := buySvc.Checkout(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(50), receipt.Total)
+	assert.Len(t, receipt.Items, 2)
+
+	repoMock.AssertExpectations(t)
+}
+
+// TestCheckout_InsufficientFunds проверяет, что при нехватке монет ничего не списывается.
+func TestCheckout_InsufficientFunds(t *testing.T) {
+	claims := jwt.MapClaims{"user_id": 123.0}
+	ctx := context.WithValue(context.Background(), middleware.UserCtxKey, claims)
+
+	repoMock := new(MockBuyRepository)
+	repoMock.On("ExecTx", ctx, mock.AnythingOfType("func(repository.BuyRepository) error")).Return(nil).Once()
+	repoMock.On("ListCartItems", ctx, int32(123)).Return(checkoutCart(), nil).Once()
+	repoMock.On("LockBalance", ctx, int32(123)).Return(int32(40), nil).Once()
+
+	buySvc := service.NewBuyService(repoMock, utils.NewLogger())
+	_, err := buySvc.Checkout(ctx)
+	assert.ErrorIs(t, err, service.ErrInsufficientFunds)
+
+	repoMock.AssertExpectations(t)
+	repoMock.AssertNotCalled(t, "DeductCoins", mock.Anything, mock.Anything, mock.Anything)
+	repoMock.AssertNotCalled(t, "ClearCart", mock.Anything, mock.Anything)
+}
+
+// TestCheckout_OutOfStock проверяет, что нехватка одной позиции откатывает весь заказ.
+func TestCheckout_OutOfStock(t *testing.T) {
+	claims := jwt.MapClaims{"user_id": 123.0}
+	ctx := context.WithValue(context.Background(), middleware.UserCtxKey, claims)
+
+	repoMock := new(MockBuyRepository)
+	repoMock.On("ExecTx", ctx, mock.AnythingOfType("func(repository.BuyRepository) error")).Return(nil).Once()
+	repoMock.On("ListCartItems", ctx, int32(123)).Return(checkoutCart(), nil).Once()
+	repoMock.On("LockBalance", ctx, int32(123)).Return(int32(100), nil).Once()
+	repoMock.On("DeductCoins", ctx, int32(123), int32(50)).Return(int64(1), nil).Once()
+	repoMock.On("DecrementStock", ctx, int32(2), int32(1)).Return(int64(1), nil).Once()
+	repoMock.On("UpsertInventory", ctx, mock.Anything).Return(nil).Once()
+	repoMock.On("CreatePurchaseTransaction", ctx, mock.Anything).Return(int32(42), nil).Once()
+	repoMock.On("CreateOrder", ctx, mock.Anything).Return(nil).Once()
+	repoMock.On("DecrementStock", ctx, int32(3), int32(3)).Return(int64(0), nil).Once()
+
+	buySvc := service.NewBuyService(repoMock, utils.NewLogger())
+	_, err := buySvc.Checkout(ctx)
+	assert.ErrorIs(t, err, service.ErrOutOfStock)
+	assert.Contains(t, err.Error(), "socks")
+
+	repoMock.AssertExpectations(t)
+	repoMock.AssertNotCalled(t, "ClearCart", mock.Anything, mock.Anything)
+}
+
+// TestCheckout_EmptyCart проверяет оформление пустой корзины.
+func TestCheckout_EmptyCart(t *testing.T) {
+	claims := jwt.MapClaims{"user_id": 123.0}
+	ctx := context.WithValue(context.Background(), middleware.UserCtxKey, claims)
+
+	repoMock := new(MockBuyRepository)
+	repoMock.On("ExecTx", ctx, mock.AnythingOfType("func(repository.BuyRepository) error")).Return(nil).Once()
+	repoMock.On("ListCartItems", ctx, int32(123)).Return([]db.ListCartItemsRow{}, nil).Once()
+
+	buySvc := service.NewBuyService(repoMock, utils.NewLogger())
+	_, err := buySvc.Checkout(ctx)
+	assert.ErrorIs(t, err, service.ErrCartEmpty)
+
+	repoMock.AssertExpectations(t)
+}
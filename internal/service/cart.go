@@ -0,0 +1,193 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/par1ram/merch-store/internal/db"
+	"github.com/par1ram/merch-store/internal/middleware"
+	"github.com/par1ram/merch-store/internal/repository"
+	"github.com/par1ram/merch-store/internal/utils"
+)
+
+// maxCartLineQuantity ограничивает количество одного товара в корзине с учётом
+// уже добавленного. То же значение закреплено ограничением cart_items_quantity_limit.
+const maxCartLineQuantity = 1000
+
+var (
+	ErrCartEmpty           = newError(CodeCartEmpty, KindInvalid, "cart is empty")
+	ErrCartItemNotFound    = newError(CodeCartItemNotFound, KindNotFound, "item is not in cart")
+	ErrCartItemUnavailable = newError(CodeCartItemUnavailable, KindConflict, "item in cart is no longer available")
+)
+
+// CartLine — позиция корзины, оценённая по текущей цене товара.
+type CartLine struct {
+	Item      string `json:"item"`
+	Quantity  int32  `json:"quantity"`
+	UnitPrice int32  `json:"unit_price"`
+	Amount    int64  `json:"amount"`
+	Available bool   `json:"available"`
+}
+
+type CartResponse struct {
+	Items []CartLine `json:"items"`
+	Total int64      `json:"total"`
+}
+
+type CartService interface {
+	AddItem(ctx context.Context, item string, quantity int32) error
+	RemoveItem(ctx context.Context, item string) error
+	GetCart(ctx context.Context) (CartResponse, error)
+}
+
+type cartService struct {
+	repo   repository.CartRepository
+	logger utils.Logger
+}
+
+func NewCartService(repo repository.CartRepository, logger utils.Logger) CartService {
+	logger.WithFields(utils.LogFields{"component": "cart_service"}).Info("CartService initialized")
+	return &cartService{
+		repo:   repo,
+		logger: logger.WithFields(utils.LogFields{"component": "cart_service"}),
+	}
+}
+
+func (s *cartService) AddItem(ctx context.Context, item string, quantity int32) error {
+	log := s.logger.WithFields(utils.LogFields{
+		"operation": "add_cart_item",
+		"item":      item,
+		"quantity":  quantity,
+	})
+
+	userID := middleware.GetUserIDFromContext(ctx)
+	if userID == 0 {
+		log.Error("User not authenticated")
+		return ErrUnauthenticated
+	}
+	if quantity <= 0 {
+		log.Warn("Invalid quantity")
+		return fmt.Errorf("%w: %w", ErrBusinessValidation, ErrInvalidQuantity)
+	}
+	if quantity > maxCartLineQuantity {
+		log.Warn("Quantity exceeds cart line limit")
+		return fmt.Errorf("%w: %w: at most %d per item", ErrBusinessValidation, ErrInvalidQuantity, maxCartLineQuantity)
+	}
+
+	merch, err := s.repo.GetMerch(ctx, item)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			log.Warn("Merch not found")
+			return fmt.Errorf("%w: %w", ErrBusinessValidation, ErrMerchNotFound)
+		}
+		log.WithFields(utils.LogFields{"error": err}).Error("Failed to get merch")
+		return err
+	}
+
+	affected, err := s.repo.AddItem(ctx, db.AddCartItemParams{
+		EmployeeID:  int32(userID),
+		MerchID:     merch.ID,
+		Quantity:    quantity,
+		MaxQuantity: maxCartLineQuantity,
+	})
+	if err != nil {
+		log.WithFields(utils.LogFields{"error": err}).Error("Failed to add cart item")
+		return err
+	}
+	if affected == 0 {
+		log.Warn("Cart line quantity limit reached")
+		return fmt.Errorf("%w: %w: at most %d per item", ErrBusinessValidation, ErrInvalidQuantity, maxCartLineQuantity)
+	}
+
+	log.WithFields(utils.LogFields{"user_id": userID}).Info("Item added to cart")
+	return nil
+}
+
+func (s *cartService) RemoveItem(ctx context.Context, item string) error {
+	log := s.logger.WithFields(utils.LogFields{
+		"operation": "remove_cart_item",
+		"item":      item,
+	})
+
+	userID := middleware.GetUserIDFromContext(ctx)
+	if userID == 0 {
+		log.Error("User not authenticated")
+		return ErrUnauthenticated
+	}
+
+	// Товар ищется среди позиций корзины, а не в каталоге: снятый с продажи
+	// товар тоже должно быть можно убрать.
+	items, err := s.repo.ListItems(ctx, int32(userID))
+	if err != nil {
+		log.WithFields(utils.LogFields{"error": err}).Error("Failed to list cart items")
+		return err
+	}
+
+	for _, line := range items {
+		if line.MerchName != item {
+			continue
+		}
+		affected, err := s.repo.RemoveItem(ctx, db.RemoveCartItemParams{
+			EmployeeID: int32(userID),
+			MerchID:    line.MerchID,
+		})
+		if err != nil {
+			log.WithFields(utils.LogFields{"error": err}).Error("Failed to remove cart item")
+			return err
+		}
+		if affected == 0 {
+			break
+		}
+		log.WithFields(utils.LogFields{"user_id": userID}).Info("Item removed from cart")
+		return nil
+	}
+
+	log.Warn("Item is not in cart")
+	return fmt.Errorf("%w: %w", ErrBusinessValidation, ErrCartItemNotFound)
+}
+
+func (s *cartService) GetCart(ctx context.Context) (CartResponse, error) {
+	log := s.logger.WithFields(utils.LogFields{"operation": "get_cart"})
+
+	userID := middleware.GetUserIDFromContext(ctx)
+	if userID == 0 {
+		log.Error("User not authenticated")
+		return CartResponse{}, ErrUnauthenticated
+	}
+
+	items, err := s.repo.ListItems(ctx, int32(userID))
+	if err != nil {
+		log.WithFields(utils.LogFields{"error": err}).Error("Failed to list cart items")
+		return CartResponse{}, err
+	}
+
+	resp := priceCart(items)
+	log.WithFields(utils.LogFields{
+		"user_id":    userID,
+		"item_count": len(resp.Items),
+		"total":      resp.Total,
+	}).Debug("Cart retrieved")
+	return resp, nil
+}
+
+// priceCart оценивает позиции корзины по текущим ценам. Снятые с продажи
+// товары помечаются недоступными и не входят в итоговую сумму.
+func priceCart(items []db.ListCartItemsRow) CartResponse {
+	resp := CartResponse{Items: make([]CartLine, 0, len(items))}
+	for _, item := range items {
+		line := CartLine{
+			Item:      item.MerchName,
+			Quantity:  item.Quantity,
+			UnitPrice: item.Price,
+			Amount:    int64(item.Price) * int64(item.Quantity),
+			Available: item.Active,
+		}
+		if line.Available {
+			resp.Total += line.Amount
+		}
+		resp.Items = append(resp.Items, line)
+	}
+	return resp
+}
@@ -0,0 +1,141 @@
+package service_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/par1ram/merch-store/internal/db"
+	"github.com/par1ram/merch-store/internal/middleware"
+	"github.com/par1ram/merch-store/internal/service"
+	"github.com/par1ram/merch-store/internal/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockCartRepository struct {
+	mock.Mock
+}
+
+func (m *MockCartRepository) GetMerch(ctx context.Context, merchName string) (db.Merch, error) {
+	args := m.Called(ctx, merchName)
+	return args.Get(0).(db.Merch), args.Error(1)
+}
+
+func (m *MockCartRepository) AddItem(ctx context.Context, params db.AddCartItemParams) (int64, error) {
+	args := m.Called(ctx, params)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockCartRepository) RemoveItem(ctx context.Context, params db.RemoveCartItemParams) (int64, error) {
+	args := m.Called(ctx, params)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockCartRepository) ListItems(ctx context.Context, employeeID int32) ([]db.ListCartItemsRow, error) {
+	args := m.Called(ctx, employeeID)
+	if res, ok := args.Get(0).([]db.ListCartItemsRow); ok {
+		return res, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func cartCtx() context.Context {
+	claims := jwt.MapClaims{"user_id": 123.0}
+	return context.WithValue(context.Background(), middleware.UserCtxKey, claims)
+}
+
+func TestCartService_AddItem_Success(t *testing.T) {
+	ctx := cartCtx()
+
+	repoMock := new(MockCartRepository)
+	repoMock.On("GetMerch", ctx, "socks").Return(db.Merch{ID: 3, Name: "socks", Price: 10, Active: true}, nil).Once()
+	repoMock.On("AddItem", ctx, db.AddCartItemParams{EmployeeID: 123, MerchID: 3, Quantity: 2, MaxQuantity: 1000}).Return(int64(1), nil).Once()
+
+	svc := service.NewCartService(repoMock, utils.NewLogger())
+	assert.NoError(t, svc.AddItem(ctx, "socks", 2))
+
+	repoMock.AssertExpectations(t)
+}
+
+func TestCartService_AddItem_UnknownMerch(t *testing.T) {
+	ctx := cartCtx()
+
+	repoMock := new(MockCartRepository)
+	repoMock.On("GetMerch", ctx, "yacht").Return(db.Merch{}, sql.ErrNoRows).Once()
+
+	svc := service.NewCartService(repoMock, utils.NewLogger())
+	err := svc.AddItem(ctx, "yacht", 1)
+	assert.ErrorIs(t, err, service.ErrMerchNotFound)
+
+	err = svc.AddItem(ctx, "socks", -1)
+	assert.ErrorIs(t, err, service.ErrInvalidQuantity)
+
+	err = svc.AddItem(ctx, "socks", 1001)
+	assert.ErrorIs(t, err, service.ErrInvalidQuantity)
+	assert.ErrorIs(t, err, service.ErrBusinessValidation)
+
+	repoMock.AssertExpectations(t)
+	repoMock.AssertNotCalled(t, "AddItem", mock.Anything, mock.Anything)
+}
+
+func TestCartService_AddItem_LineLimitReached(t *testing.T) {
+	ctx := cartCtx()
+
+	// В корзине уже лежит почти предельное количество: запрос не меняет строку.
+	repoMock := new(MockCartRepository)
+	repoMock.On("GetMerch", ctx, "socks").Return(db.Merch{ID: 3, Name: "socks", Price: 10, Active: true}, nil).Once()
+	repoMock.On("AddItem", ctx, db.AddCartItemParams{EmployeeID: 123, MerchID: 3, Quantity: 5, MaxQuantity: 1000}).Return(int64(0), nil).Once()
+
+	svc := service.NewCartService(repoMock, utils.NewLogger())
+	err := svc.AddItem(ctx, "socks", 5)
+	assert.ErrorIs(t, err, service.ErrInvalidQuantity)
+	assert.ErrorIs(t, err, service.ErrBusinessValidation)
+
+	repoMock.AssertExpectations(t)
+}
+
+func TestCartService_RemoveItem(t *testing.T) {
+	ctx := cartCtx()
+
+	repoMock := new(MockCartRepository)
+	repoMock.On("ListItems", ctx, int32(123)).Return([]db.ListCartItemsRow{
+		{MerchID: 3, MerchName: "socks", Price: 10, Active: true, Quantity: 2},
+	}, nil).Twice()
+	repoMock.On("RemoveItem", ctx, db.RemoveCartItemParams{EmployeeID: 123, MerchID: 3}).Return(int64(1), nil).Once()
+
+	svc := service.NewCartService(repoMock, utils.NewLogger())
+	assert.NoError(t, svc.RemoveItem(ctx, "socks"))
+
+	err := svc.RemoveItem(ctx, "cup")
+	assert.ErrorIs(t, err, service.ErrCartItemNotFound)
+
+	repoMock.AssertExpectations(t)
+}
+
+func TestCartService_GetCart_PricesLines(t *testing.T) {
+	ctx := cartCtx()
+
+	repoMock := new(MockCartRepository)
+	repoMock.On("ListItems", ctx, int32(123)).Return([]db.ListCartItemsRow{
+		{MerchID: 2, MerchName: "cup", Price: 20, Active: true, Quantity: 1},
+		{MerchID: 3, MerchName: "socks", Price: 10, Active: true, Quantity: 3},
+		{MerchID: 10, MerchName: "pink-hoody", Price: 500, Active: false, Quantity: 1},
+	}, nil).Once()
+
+	svc := service.NewCartService(repoMock, utils.NewLogger())
+	cart, err := svc.GetCart(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, service.CartResponse{
+		Items: []service.CartLine{
+			{Item: "cup", Quantity: 1, UnitPrice: 20, Amount: 20, Available: true},
+			{Item: "socks", Quantity: 3, UnitPrice: 10, Amount: 30, Available: true},
+			{Item: "pink-hoody", Quantity: 1, UnitPrice: 500, Amount: 500, Available: false},
+		},
+		// Снятый с продажи товар не входит в сумму.
+		Total: 50,
+	}, cart)
+
+	repoMock.AssertExpectations(t)
+}
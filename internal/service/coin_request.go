@@ -0,0 +1,355 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/par1ram/merch-store/internal/db"
+	"github.com/par1ram/merch-store/internal/middleware"
+	"github.com/par1ram/merch-store/internal/repository"
+	"github.com/par1ram/merch-store/internal/utils"
+)
+
+// Статусы запросов монет.
+const (
+	CoinRequestStatusPending  = "pending"
+	CoinRequestStatusAccepted = "accepted"
+	CoinRequestStatusDeclined = "declined"
+	CoinRequestStatusExpired  = "expired"
+)
+
+// defaultCoinRequestTTL используется, если срок действия запроса не задан.
+const defaultCoinRequestTTL = 7 * 24 * time.Hour
+
+var (
+	ErrCoinRequestNotFound      = newError(CodeCoinRequestNotFound, KindNotFound, "coin request not found")
+	ErrCoinRequestResolved      = newError(CodeCoinRequestResolved, KindConflict, "coin request already resolved")
+	ErrCoinRequestExpired       = newError(CodeCoinRequestExpired, KindConflict, "coin request expired")
+	ErrInvalidCoinRequestStatus = newError(CodeInvalidCoinRequestStatus, KindInvalid, "invalid coin request status")
+)
+
+// CoinRequest — запрос монет: Requester просит Payer перевести ему Amount монет.
+type CoinRequest struct {
+	ID         int32      `json:"id"`
+	Requester  string     `json:"requester"`
+	Payer      string     `json:"payer"`
+	Amount     int32      `json:"amount"`
+	Note       string     `json:"note,omitempty"`
+	Status     string     `json:"status"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	ResolvedAt *time.Time `json:"resolved_at,omitempty"`
+}
+
+// CoinRequestService позволяет попросить монеты у коллеги. Получатель запроса
+// принимает его — тогда выполняется обычный перевод с проверкой баланса и
+// лимитов — или отклоняет. Запрос без ответа истекает через ttl.
+type CoinRequestService interface {
+	CreateRequest(ctx context.Context, payer string, amount int32, note string) (CoinRequest, error)
+	ListIncoming(ctx context.Context, status string) ([]CoinRequest, error)
+	ListOutgoing(ctx context.Context, status string) ([]CoinRequest, error)
+	AcceptRequest(ctx context.Context, requestID int32) (CoinRequest, error)
+	DeclineRequest(ctx context.Context, requestID int32) (CoinRequest, error)
+	ExpireRequests(ctx context.Context) (int64, error)
+}
+
+type coinRequestService struct {
+	repo   repository.CoinRequestRepository
+	limits TransferLimits
+	ttl    time.Duration
+	logger utils.Logger
+}
+
+// NewCoinRequestService создаёт сервис запросов монет; limits — лимиты переводов
+// по умолчанию, те же, что у SendCoinService.
+func NewCoinRequestService(repo repository.CoinRequestRepository, limits TransferLimits, ttl time.Duration, logger utils.Logger) CoinRequestService {
+	if ttl <= 0 {
+		ttl = defaultCoinRequestTTL
+	}
+	logger.WithFields(utils.LogFields{
+		"component": "coin_request_service",
+		"ttl":       ttl.String(),
+	}).Info("CoinRequestService initialized")
+	return &coinRequestService{
+		repo:   repo,
+		limits: limits,
+		ttl:    ttl,
+		logger: logger.WithFields(utils.LogFields{"component": "coin_request_service"}),
+	}
+}
+
+func (s *coinRequestService) CreateRequest(ctx context.Context, payer string, amount int32, note string) (CoinRequest, error) {
+	userID := middleware.GetUserIDFromContext(ctx)
+	log := s.logger.WithFields(utils.LogFields{
+		"operation": "create_coin_request",
+		"user_id":   userID,
+		"payer":     payer,
+		"amount":    amount,
+	})
+
+	if userID == 0 {
+		log.Error("User not authenticated")
+		return CoinRequest{}, ErrUnauthenticated
+	}
+	if amount <= 0 {
+		return CoinRequest{}, fmt.Errorf("%w: %w", ErrBusinessValidation, ErrInvalidTransferAmount)
+	}
+	memo, err := parseTransferMessage(note)
+	if err != nil {
+		return CoinRequest{}, err
+	}
+
+	employee, err := s.repo.GetEmployee(ctx, payer)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return CoinRequest{}, fmt.Errorf("%w: %w: %s", ErrBusinessValidation, ErrUserNotFound, payer)
+		}
+		return CoinRequest{}, fmt.Errorf("failed to get employee: %w", err)
+	}
+	if employee.ID == int32(userID) {
+		return CoinRequest{}, fmt.Errorf("%w: %w", ErrBusinessValidation, ErrSelfTransfer)
+	}
+
+	request, err := s.repo.CreateCoinRequest(ctx, db.CreateCoinRequestParams{
+		RequesterID: int32(userID),
+		PayerID:     employee.ID,
+		Amount:      amount,
+		Note:        memo.Message,
+		ExpiresAt:   pgtype.Timestamptz{Time: time.Now().Add(s.ttl), Valid: true},
+	})
+	if err != nil {
+		log.WithFields(utils.LogFields{"error": err}).Error("Failed to create coin request")
+		return CoinRequest{}, err
+	}
+
+	log.WithFields(utils.LogFields{"request_id": request.ID}).Info("Coin request created")
+	return s.getRequest(ctx, s.repo, request.ID)
+}
+
+// ListIncoming возвращает запросы, адресованные текущему пользователю.
+func (s *coinRequestService) ListIncoming(ctx context.Context, status string) ([]CoinRequest, error) {
+	return s.list(ctx, status, true)
+}
+
+// ListOutgoing возвращает запросы, созданные текущим пользователем.
+func (s *coinRequestService) ListOutgoing(ctx context.Context, status string) ([]CoinRequest, error) {
+	return s.list(ctx, status, false)
+}
+
+func (s *coinRequestService) list(ctx context.Context, status string, incoming bool) ([]CoinRequest, error) {
+	userID := middleware.GetUserIDFromContext(ctx)
+	if userID == 0 {
+		s.logger.Error("User not authenticated")
+		return nil, ErrUnauthenticated
+	}
+
+	params := db.ListCoinRequestsParams{}
+	if incoming {
+		params.PayerID = pgtype.Int4{Int32: int32(userID), Valid: true}
+	} else {
+		params.RequesterID = pgtype.Int4{Int32: int32(userID), Valid: true}
+	}
+	if status != "" {
+		switch status {
+		case CoinRequestStatusPending, CoinRequestStatusAccepted, CoinRequestStatusDeclined, CoinRequestStatusExpired:
+		default:
+			return nil, fmt.Errorf("%w: %w", ErrBusinessValidation, ErrInvalidCoinRequestStatus)
+		}
+		params.Status = db.NullCoinRequestStatusEnum{CoinRequestStatusEnum: db.CoinRequestStatusEnum(status), Valid: true}
+	}
+
+	rows, err := s.repo.ListCoinRequests(ctx, params)
+	if err != nil {
+		s.logger.WithFields(utils.LogFields{"error": err}).Error("Failed to list coin requests")
+		return nil, err
+	}
+
+	requests := make([]CoinRequest, 0, len(rows))
+	for _, row := range rows {
+		requests = append(requests, toCoinRequest(row))
+	}
+	return requests, nil
+}
+
+// AcceptRequest переводит запрошенные монеты от текущего пользователя автору
+// запроса через SendCoinRepository.TransferCoins и закрывает запрос в той же транзакции.
+func (s *coinRequestService) AcceptRequest(ctx context.Context, requestID int32) (CoinRequest, error) {
+	userID := middleware.GetUserIDFromContext(ctx)
+	log := s.logger.WithFields(utils.LogFields{
+		"operation":  "accept_coin_request",
+		"request_id": requestID,
+		"user_id":    userID,
+	})
+	if userID == 0 {
+		log.Error("User not authenticated")
+		return CoinRequest{}, ErrUnauthenticated
+	}
+
+	var result CoinRequest
+	err := s.repo.ExecTx(ctx, func(r repository.CoinRequestRepository) error {
+		request, err := s.lockPendingRequest(ctx, r, requestID, int32(userID))
+		if err != nil {
+			return err
+		}
+
+		if err := s.transfer(ctx, r.Transfers(), request); err != nil {
+			return err
+		}
+
+		if err := r.ResolveCoinRequest(ctx, db.ResolveCoinRequestParams{
+			ID:     requestID,
+			Status: db.CoinRequestStatusEnumAccepted,
+		}); err != nil {
+			return err
+		}
+
+		result, err = s.getRequest(ctx, r, requestID)
+		return err
+	})
+	if err != nil {
+		log.WithFields(utils.LogFields{"error": err}).Error("Failed to accept coin request")
+		return CoinRequest{}, err
+	}
+
+	log.WithFields(utils.LogFields{"amount": result.Amount, "requester": result.Requester}).Info("Coin request accepted")
+	return result, nil
+}
+
+func (s *coinRequestService) DeclineRequest(ctx context.Context, requestID int32) (CoinRequest, error) {
+	userID := middleware.GetUserIDFromContext(ctx)
+	log := s.logger.WithFields(utils.LogFields{
+		"operation":  "decline_coin_request",
+		"request_id": requestID,
+		"user_id":    userID,
+	})
+	if userID == 0 {
+		log.Error("User not authenticated")
+		return CoinRequest{}, ErrUnauthenticated
+	}
+
+	var result CoinRequest
+	err := s.repo.ExecTx(ctx, func(r repository.CoinRequestRepository) error {
+		if _, err := s.lockPendingRequest(ctx, r, requestID, int32(userID)); err != nil {
+			return err
+		}
+		if err := r.ResolveCoinRequest(ctx, db.ResolveCoinRequestParams{
+			ID:     requestID,
+			Status: db.CoinRequestStatusEnumDeclined,
+		}); err != nil {
+			return err
+		}
+
+		var err error
+		result, err = s.getRequest(ctx, r, requestID)
+		return err
+	})
+	if err != nil {
+		log.WithFields(utils.LogFields{"error": err}).Error("Failed to decline coin request")
+		return CoinRequest{}, err
+	}
+
+	log.Info("Coin request declined")
+	return result, nil
+}
+
+// ExpireRequests закрывает ожидающие запросы с истёкшим сроком.
+func (s *coinRequestService) ExpireRequests(ctx context.Context) (int64, error) {
+	expired, err := s.repo.ExpireCoinRequests(ctx)
+	if err != nil {
+		s.logger.WithFields(utils.LogFields{"error": err}).Error("Failed to expire coin requests")
+		return 0, err
+	}
+	if expired > 0 {
+		s.logger.WithFields(utils.LogFields{"expired": expired}).Info("Coin requests expired")
+	}
+	return expired, nil
+}
+
+// lockPendingRequest блокирует запрос и проверяет, что он адресован payerID
+// и ещё ожидает решения. Чужой запрос неотличим от несуществующего.
+func (s *coinRequestService) lockPendingRequest(ctx context.Context, r repository.CoinRequestRepository, requestID, payerID int32) (db.CoinRequest, error) {
+	request, err := r.GetCoinRequestForUpdate(ctx, requestID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return request, fmt.Errorf("%w: %w", ErrBusinessValidation, ErrCoinRequestNotFound)
+		}
+		return request, err
+	}
+	if request.PayerID != payerID {
+		return request, fmt.Errorf("%w: %w", ErrBusinessValidation, ErrCoinRequestNotFound)
+	}
+	if request.Status == db.CoinRequestStatusEnumExpired ||
+		(request.Status == db.CoinRequestStatusEnumPending && !request.ExpiresAt.Time.After(time.Now())) {
+		return request, fmt.Errorf("%w: %w", ErrBusinessValidation, ErrCoinRequestExpired)
+	}
+	if request.Status != db.CoinRequestStatusEnumPending {
+		return request, fmt.Errorf("%w: %w", ErrBusinessValidation, ErrCoinRequestResolved)
+	}
+	return request, nil
+}
+
+// transfer выполняет перевод по запросу с теми же проверками баланса и лимитов,
+// что и SendCoin. Сообщение запроса становится сообщением перевода.
+func (s *coinRequestService) transfer(ctx context.Context, r repository.SendCoinRepository, request db.CoinRequest) error {
+	memo, err := parseTransferMessage(request.Note)
+	if err != nil {
+		return err
+	}
+
+	balances, err := r.LockBalances(ctx, request.PayerID, request.RequesterID)
+	if err != nil {
+		return fmt.Errorf("failed to lock balances: %w", err)
+	}
+	if balances[request.PayerID] < request.Amount {
+		return fmt.Errorf("%w: %w", ErrBusinessValidation, ErrInsufficientFunds)
+	}
+
+	limits, err := resolveTransferLimits(ctx, r, s.limits, request.PayerID)
+	if err != nil {
+		return err
+	}
+	if err := checkTransferLimits(ctx, r, limits, request.PayerID, request.RequesterID, request.Amount); err != nil {
+		return err
+	}
+
+	if err := r.TransferCoins(ctx, request.PayerID, request.RequesterID, request.Amount, memo); err != nil {
+		if errors.Is(err, repository.ErrInsufficientBalance) {
+			return fmt.Errorf("%w: %w", ErrBusinessValidation, ErrInsufficientFunds)
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *coinRequestService) getRequest(ctx context.Context, r repository.CoinRequestRepository, requestID int32) (CoinRequest, error) {
+	rows, err := r.ListCoinRequests(ctx, db.ListCoinRequestsParams{ID: pgtype.Int4{Int32: requestID, Valid: true}})
+	if err != nil {
+		return CoinRequest{}, fmt.Errorf("failed to get coin request: %w", err)
+	}
+	if len(rows) == 0 {
+		return CoinRequest{}, fmt.Errorf("%w: %w", ErrBusinessValidation, ErrCoinRequestNotFound)
+	}
+	return toCoinRequest(rows[0]), nil
+}
+
+func toCoinRequest(row db.ListCoinRequestsRow) CoinRequest {
+	request := CoinRequest{
+		ID:        row.ID,
+		Requester: row.Requester,
+		Payer:     row.Payer,
+		Amount:    row.Amount,
+		Note:      row.Note,
+		Status:    string(row.Status),
+		CreatedAt: row.CreatedAt.Time,
+		ExpiresAt: row.ExpiresAt.Time,
+	}
+	if row.ResolvedAt.Valid {
+		resolvedAt := row.ResolvedAt.Time
+		request.ResolvedAt = &resolvedAt
+	}
+	return request
+}
@@ -0,0 +1,239 @@
+package service_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/par1ram/merch-store/internal/db"
+	"github.com/par1ram/merch-store/internal/repository"
+	"github.com/par1ram/merch-store/internal/service"
+	"github.com/par1ram/merch-store/internal/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockCoinRequestRepository struct {
+	mock.Mock
+	transfers *MockSendCoinRepository
+}
+
+func (m *MockCoinRequestRepository) ExecTx(ctx context.Context, fn func(repository.CoinRequestRepository) error) error {
+	args := m.Called(ctx, fn)
+	if err := args.Error(0); err != nil {
+		return err
+	}
+	return fn(m)
+}
+
+func (m *MockCoinRequestRepository) Transfers() repository.SendCoinRepository {
+	return m.transfers
+}
+
+func (m *MockCoinRequestRepository) GetEmployee(ctx context.Context, username string) (db.Employee, error) {
+	args := m.Called(ctx, username)
+	return args.Get(0).(db.Employee), args.Error(1)
+}
+
+func (m *MockCoinRequestRepository) CreateCoinRequest(ctx context.Context, params db.CreateCoinRequestParams) (db.CoinRequest, error) {
+	args := m.Called(ctx, params)
+	return args.Get(0).(db.CoinRequest), args.Error(1)
+}
+
+func (m *MockCoinRequestRepository) GetCoinRequestForUpdate(ctx context.Context, id int32) (db.CoinRequest, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(db.CoinRequest), args.Error(1)
+}
+
+func (m *MockCoinRequestRepository) ResolveCoinRequest(ctx context.Context, params db.ResolveCoinRequestParams) error {
+	args := m.Called(ctx, params)
+	return args.Error(0)
+}
+
+func (m *MockCoinRequestRepository) ListCoinRequests(ctx context.Context, params db.ListCoinRequestsParams) ([]db.ListCoinRequestsRow, error) {
+	args := m.Called(ctx, params)
+	return args.Get(0).([]db.ListCoinRequestsRow), args.Error(1)
+}
+
+func (m *MockCoinRequestRepository) ExpireCoinRequests(ctx context.Context) (int64, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func pendingCoinRequest(id, requesterID, payerID, amount int32, note string) db.CoinRequest {
+	return db.CoinRequest{
+		ID:          id,
+		RequesterID: requesterID,
+		PayerID:     payerID,
+		Amount:      amount,
+		Note:        note,
+		Status:      db.CoinRequestStatusEnumPending,
+		ExpiresAt:   pgtype.Timestamptz{Time: time.Now().Add(time.Hour), Valid: true},
+	}
+}
+
+func byRequestID(id int32) db.ListCoinRequestsParams {
+	return db.ListCoinRequestsParams{ID: pgtype.Int4{Int32: id, Valid: true}}
+}
+
+func TestCoinRequestService_Create(t *testing.T) {
+	ctx := userCtx(1)
+
+	mockRepo := new(MockCoinRequestRepository)
+	mockRepo.On("GetEmployee", ctx, "bob").Return(db.Employee{ID: 2, Username: "bob"}, nil).Once()
+	mockRepo.On("CreateCoinRequest", ctx, mock.MatchedBy(func(p db.CreateCoinRequestParams) bool {
+		return p.RequesterID == 1 && p.PayerID == 2 && p.Amount == 40 && p.Note == "lunch #team" &&
+			p.ExpiresAt.Time.After(time.Now().Add(47*time.Hour))
+	})).Return(db.CoinRequest{ID: 5}, nil).Once()
+	mockRepo.On("ListCoinRequests", ctx, byRequestID(5)).
+		Return([]db.ListCoinRequestsRow{{ID: 5, Requester: "alice", Payer: "bob", Amount: 40, Note: "lunch #team", Status: db.CoinRequestStatusEnumPending}}, nil).Once()
+
+	svc := service.NewCoinRequestService(mockRepo, service.TransferLimits{}, 48*time.Hour, utils.NewLogger())
+	request, err := svc.CreateRequest(ctx, "bob", 40, "  lunch\n#team ")
+	assert.NoError(t, err)
+	assert.Equal(t, "bob", request.Payer)
+	assert.Equal(t, service.CoinRequestStatusPending, request.Status)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCoinRequestService_CreateValidation(t *testing.T) {
+	ctx := userCtx(1)
+
+	mockRepo := new(MockCoinRequestRepository)
+	mockRepo.On("GetEmployee", ctx, "alice").Return(db.Employee{ID: 1, Username: "alice"}, nil).Once()
+	mockRepo.On("GetEmployee", ctx, "ghost").Return(db.Employee{}, sql.ErrNoRows).Once()
+
+	svc := service.NewCoinRequestService(mockRepo, service.TransferLimits{}, 0, utils.NewLogger())
+
+	_, err := svc.CreateRequest(ctx, "bob", 0, "")
+	assert.ErrorIs(t, err, service.ErrInvalidTransferAmount)
+
+	_, err = svc.CreateRequest(ctx, "alice", 10, "")
+	assert.ErrorIs(t, err, service.ErrSelfTransfer)
+
+	_, err = svc.CreateRequest(ctx, "ghost", 10, "")
+	assert.ErrorIs(t, err, service.ErrUserNotFound)
+
+	_, err = svc.CreateRequest(context.Background(), "bob", 10, "")
+	assert.ErrorIs(t, err, service.ErrUnauthenticated)
+
+	mockRepo.AssertNotCalled(t, "CreateCoinRequest", mock.Anything, mock.Anything)
+}
+
+func TestCoinRequestService_Accept(t *testing.T) {
+	ctx := userCtx(2)
+
+	transfers := new(MockSendCoinRepository)
+	transfers.On("LockBalances", ctx, mock.Anything).Return(map[int32]int32{2: 100}, nil).Once()
+	transfers.On("GetTransferLimitOverride", ctx, int32(2)).Return(db.GetTransferLimitOverrideRow{}, nil).Once()
+	transfers.On("TransferCoins", ctx, int32(2), int32(1), int32(40),
+		repository.TransferMemo{Message: "lunch #team", Hashtags: []string{"team"}}).Return(nil).Once()
+
+	mockRepo := &MockCoinRequestRepository{transfers: transfers}
+	mockRepo.On("ExecTx", ctx, mock.Anything).Return(nil).Once()
+	mockRepo.On("GetCoinRequestForUpdate", ctx, int32(5)).Return(pendingCoinRequest(5, 1, 2, 40, "lunch #team"), nil).Once()
+	mockRepo.On("ResolveCoinRequest", ctx, db.ResolveCoinRequestParams{ID: 5, Status: db.CoinRequestStatusEnumAccepted}).Return(nil).Once()
+	mockRepo.On("ListCoinRequests", ctx, byRequestID(5)).
+		Return([]db.ListCoinRequestsRow{{ID: 5, Requester: "alice", Payer: "bob", Amount: 40, Status: db.CoinRequestStatusEnumAccepted}}, nil).Once()
+
+	svc := service.NewCoinRequestService(mockRepo, service.TransferLimits{}, 0, utils.NewLogger())
+	request, err := svc.AcceptRequest(ctx, 5)
+	assert.NoError(t, err)
+	assert.Equal(t, service.CoinRequestStatusAccepted, request.Status)
+
+	mockRepo.AssertExpectations(t)
+	transfers.AssertExpectations(t)
+}
+
+func TestCoinRequestService_AcceptInsufficientFunds(t *testing.T) {
+	ctx := userCtx(2)
+
+	transfers := new(MockSendCoinRepository)
+	transfers.On("LockBalances", ctx, mock.Anything).Return(map[int32]int32{2: 10}, nil).Once()
+
+	mockRepo := &MockCoinRequestRepository{transfers: transfers}
+	mockRepo.On("ExecTx", ctx, mock.Anything).Return(nil).Once()
+	mockRepo.On("GetCoinRequestForUpdate", ctx, int32(5)).Return(pendingCoinRequest(5, 1, 2, 40, ""), nil).Once()
+
+	svc := service.NewCoinRequestService(mockRepo, service.TransferLimits{}, 0, utils.NewLogger())
+	_, err := svc.AcceptRequest(ctx, 5)
+	assert.ErrorIs(t, err, service.ErrInsufficientFunds)
+
+	transfers.AssertNotCalled(t, "TransferCoins", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	mockRepo.AssertNotCalled(t, "ResolveCoinRequest", mock.Anything, mock.Anything)
+}
+
+func TestCoinRequestService_ResolveRejected(t *testing.T) {
+	ctx := userCtx(2)
+
+	expired := pendingCoinRequest(6, 1, 2, 40, "")
+	expired.ExpiresAt = pgtype.Timestamptz{Time: time.Now().Add(-time.Minute), Valid: true}
+	declined := pendingCoinRequest(7, 1, 2, 40, "")
+	declined.Status = db.CoinRequestStatusEnumDeclined
+
+	mockRepo := &MockCoinRequestRepository{transfers: new(MockSendCoinRepository)}
+	mockRepo.On("ExecTx", ctx, mock.Anything).Return(nil)
+	mockRepo.On("GetCoinRequestForUpdate", ctx, int32(5)).Return(pendingCoinRequest(5, 1, 3, 40, ""), nil).Once()
+	mockRepo.On("GetCoinRequestForUpdate", ctx, int32(6)).Return(expired, nil).Once()
+	mockRepo.On("GetCoinRequestForUpdate", ctx, int32(7)).Return(declined, nil).Once()
+	mockRepo.On("GetCoinRequestForUpdate", ctx, int32(8)).Return(db.CoinRequest{}, sql.ErrNoRows).Once()
+
+	svc := service.NewCoinRequestService(mockRepo, service.TransferLimits{}, 0, utils.NewLogger())
+
+	// Запрос адресован другому сотруднику.
+	_, err := svc.AcceptRequest(ctx, 5)
+	assert.ErrorIs(t, err, service.ErrCoinRequestNotFound)
+
+	_, err = svc.AcceptRequest(ctx, 6)
+	assert.ErrorIs(t, err, service.ErrCoinRequestExpired)
+
+	_, err = svc.DeclineRequest(ctx, 7)
+	assert.ErrorIs(t, err, service.ErrCoinRequestResolved)
+
+	_, err = svc.DeclineRequest(ctx, 8)
+	assert.ErrorIs(t, err, service.ErrCoinRequestNotFound)
+
+	mockRepo.AssertNotCalled(t, "ResolveCoinRequest", mock.Anything, mock.Anything)
+}
+
+func TestCoinRequestService_Decline(t *testing.T) {
+	ctx := userCtx(2)
+
+	mockRepo := &MockCoinRequestRepository{transfers: new(MockSendCoinRepository)}
+	mockRepo.On("ExecTx", ctx, mock.Anything).Return(nil).Once()
+	mockRepo.On("GetCoinRequestForUpdate", ctx, int32(5)).Return(pendingCoinRequest(5, 1, 2, 40, ""), nil).Once()
+	mockRepo.On("ResolveCoinRequest", ctx, db.ResolveCoinRequestParams{ID: 5, Status: db.CoinRequestStatusEnumDeclined}).Return(nil).Once()
+	mockRepo.On("ListCoinRequests", ctx, byRequestID(5)).
+		Return([]db.ListCoinRequestsRow{{ID: 5, Status: db.CoinRequestStatusEnumDeclined}}, nil).Once()
+
+	svc := service.NewCoinRequestService(mockRepo, service.TransferLimits{}, 0, utils.NewLogger())
+	request, err := svc.DeclineRequest(ctx, 5)
+	assert.NoError(t, err)
+	assert.Equal(t, service.CoinRequestStatusDeclined, request.Status)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCoinRequestService_ListIncoming(t *testing.T) {
+	ctx := userCtx(2)
+
+	mockRepo := new(MockCoinRequestRepository)
+	mockRepo.On("ListCoinRequests", ctx, db.ListCoinRequestsParams{
+		PayerID: pgtype.Int4{Int32: 2, Valid: true},
+		Status:  db.NullCoinRequestStatusEnum{CoinRequestStatusEnum: db.CoinRequestStatusEnumPending, Valid: true},
+	}).Return([]db.ListCoinRequestsRow{{ID: 5, Requester: "alice", Payer: "bob", Amount: 40, Status: db.CoinRequestStatusEnumPending}}, nil).Once()
+
+	svc := service.NewCoinRequestService(mockRepo, service.TransferLimits{}, 0, utils.NewLogger())
+	requests, err := svc.ListIncoming(ctx, "pending")
+	assert.NoError(t, err)
+	assert.Len(t, requests, 1)
+	assert.Equal(t, "alice", requests[0].Requester)
+
+	_, err = svc.ListOutgoing(ctx, "unknown")
+	assert.ErrorIs(t, err, service.ErrInvalidCoinRequestStatus)
+
+	mockRepo.AssertExpectations(t)
+}
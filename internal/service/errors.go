@@ -0,0 +1,128 @@
+package service
+
+import "errors"
+
+// ErrBusinessValidation — общий признак бизнес-ошибки: все ошибки *Error
+// совпадают с ним через errors.Is.
+var ErrBusinessValidation = errors.New("business validation error")
+
+// ErrUnauthenticated возвращается, если в контексте нет идентификатора пользователя.
+var ErrUnauthenticated = newError(CodeUnauthenticated, KindUnauthenticated, "user not authenticated")
+
+// ErrorCode — стабильный машиночитаемый код бизнес-ошибки. Клиенты
+// опираются на коды, поэтому существующие значения не переименовываются.
+type ErrorCode string
+
+const (
+	CodeValidation      ErrorCode = "VALIDATION_ERROR"
+	CodeUnauthenticated ErrorCode = "UNAUTHENTICATED"
+
+	CodeInsufficientFunds ErrorCode = "INSUFFICIENT_FUNDS"
+	CodeSelfTransfer      ErrorCode = "SELF_TRANSFER"
+	CodeRecipientNotFound ErrorCode = "RECIPIENT_NOT_FOUND"
+	CodeInvalidMessage    ErrorCode = "INVALID_MESSAGE"
+
+	CodeInvalidBatchRecipients ErrorCode = "INVALID_BATCH_RECIPIENTS"
+	CodeInvalidTransferAmount  ErrorCode = "INVALID_TRANSFER_AMOUNT"
+
+	CodeDailyTransferLimit     ErrorCode = "DAILY_TRANSFER_LIMIT_EXCEEDED"
+	CodeMonthlyTransferLimit   ErrorCode = "MONTHLY_TRANSFER_LIMIT_EXCEEDED"
+	CodeRecipientTransferLimit ErrorCode = "RECIPIENT_TRANSFER_LIMIT_EXCEEDED"
+	CodeInvalidTransferLimit   ErrorCode = "INVALID_TRANSFER_LIMIT"
+	CodeTransfersBlocked       ErrorCode = "TRANSFERS_BLOCKED"
+
+	CodeCoinRequestNotFound      ErrorCode = "COIN_REQUEST_NOT_FOUND"
+	CodeCoinRequestResolved      ErrorCode = "COIN_REQUEST_RESOLVED"
+	CodeCoinRequestExpired       ErrorCode = "COIN_REQUEST_EXPIRED"
+	CodeInvalidCoinRequestStatus ErrorCode = "INVALID_COIN_REQUEST_STATUS"
+
+	CodePendingTransferNotFound      ErrorCode = "PENDING_TRANSFER_NOT_FOUND"
+	CodePendingTransferResolved      ErrorCode = "PENDING_TRANSFER_RESOLVED"
+	CodePendingTransferExpired       ErrorCode = "PENDING_TRANSFER_EXPIRED"
+	CodeInvalidPendingTransferStatus ErrorCode = "INVALID_PENDING_TRANSFER_STATUS"
+
+	CodeInvalidHistoryFilter ErrorCode = "INVALID_HISTORY_FILTER"
+	CodeInvalidHistoryCursor ErrorCode = "INVALID_HISTORY_CURSOR"
+
+	CodeItemNotFound    ErrorCode = "ITEM_NOT_FOUND"
+	CodeOutOfStock      ErrorCode = "OUT_OF_STOCK"
+	CodeInvalidQuantity ErrorCode = "INVALID_QUANTITY"
+
+	CodeCartEmpty           ErrorCode = "CART_EMPTY"
+	CodeCartItemNotFound    ErrorCode = "CART_ITEM_NOT_FOUND"
+	CodeCartItemUnavailable ErrorCode = "CART_ITEM_UNAVAILABLE"
+
+	CodeInvalidMerchFilter ErrorCode = "INVALID_MERCH_FILTER"
+	CodeInvalidMerchName   ErrorCode = "INVALID_MERCH_NAME"
+	CodeInvalidMerchPrice  ErrorCode = "INVALID_MERCH_PRICE"
+	CodeInvalidMerchStock  ErrorCode = "INVALID_MERCH_STOCK"
+	CodeMerchExists        ErrorCode = "MERCH_EXISTS"
+	CodeMerchRetired       ErrorCode = "MERCH_RETIRED"
+
+	CodeUserNotFound ErrorCode = "USER_NOT_FOUND"
+	CodeUnknownRole  ErrorCode = "UNKNOWN_ROLE"
+
+	CodePurchaseNotFound      ErrorCode = "PURCHASE_NOT_FOUND"
+	CodeRefundExceedsPurchase ErrorCode = "REFUND_EXCEEDS_PURCHASE"
+	CodeRefundItemNotHeld     ErrorCode = "REFUND_ITEM_NOT_HELD"
+	CodeRefundRequestNotFound ErrorCode = "REFUND_REQUEST_NOT_FOUND"
+	CodeRefundRequestResolved ErrorCode = "REFUND_REQUEST_RESOLVED"
+	CodeInvalidRefundStatus   ErrorCode = "INVALID_REFUND_STATUS"
+	CodeInvalidRefundReason   ErrorCode = "INVALID_REFUND_REASON"
+
+	CodeOrderNotFound          ErrorCode = "ORDER_NOT_FOUND"
+	CodeInvalidOrderStatus     ErrorCode = "INVALID_ORDER_STATUS"
+	CodeInvalidOrderTransition ErrorCode = "INVALID_ORDER_TRANSITION"
+	CodeOrderCancelViaRefund   ErrorCode = "ORDER_CANCEL_VIA_REFUND"
+
+	CodeInvalidGrantAmount     ErrorCode = "INVALID_GRANT_AMOUNT"
+	CodeInvalidGrantReason     ErrorCode = "INVALID_GRANT_REASON"
+	CodeInvalidGrantRecipients ErrorCode = "INVALID_GRANT_RECIPIENTS"
+
+	CodeInvalidAllowancePeriod ErrorCode = "INVALID_ALLOWANCE_PERIOD"
+
+	CodeInvalidStatementPeriod ErrorCode = "INVALID_STATEMENT_PERIOD"
+	CodeStatementNotFound      ErrorCode = "STATEMENT_NOT_FOUND"
+
+	CodeAdminRequired ErrorCode = "ADMIN_REQUIRED"
+)
+
+// ErrorKind — категория бизнес-ошибки. Сервисы не знают об HTTP;
+// обработчики переводят категорию в код ответа.
+type ErrorKind int
+
+const (
+	// KindInvalid — некорректный запрос.
+	KindInvalid ErrorKind = iota
+	// KindUnauthenticated — пользователь не определён.
+	KindUnauthenticated
+	// KindInsufficientFunds — на балансе недостаточно монет.
+	KindInsufficientFunds
+	// KindNotFound — объект запроса не существует.
+	KindNotFound
+	// KindConflict — запрос противоречит текущему состоянию данных.
+	KindConflict
+	// KindForbidden — у пользователя нет прав на операцию.
+	KindForbidden
+)
+
+// Error — типизированная бизнес-ошибка со стабильным кодом.
+// Значения создаются один раз как переменные-эталоны и сравниваются через errors.Is.
+type Error struct {
+	Code    ErrorCode
+	Kind    ErrorKind
+	Message string
+}
+
+func newError(code ErrorCode, kind ErrorKind, message string) *Error {
+	return &Error{Code: code, Kind: kind, Message: message}
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Is позволяет проверять любую бизнес-ошибку через errors.Is(err, ErrBusinessValidation).
+func (e *Error) Is(target error) bool {
+	return target == ErrBusinessValidation
+}
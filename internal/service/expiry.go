@@ -0,0 +1,65 @@
+package service
+
+import (
+	"context"
+
+	"github.com/par1ram/merch-store/internal/repository"
+	"github.com/par1ram/merch-store/internal/utils"
+)
+
+// expiryBatchSize — сколько партий обрабатывается одним запросом.
+const expiryBatchSize = 500
+
+// CoinExpiryResult — итог одного запуска сгорания монет. Transactions — число
+// записей expiry: по одной на сотрудника в каждой обработанной порции.
+type CoinExpiryResult struct {
+	Transactions int   `json:"transactions"`
+	Amount       int64 `json:"amount"`
+}
+
+// CoinExpiryService списывает монеты, срок действия которых истёк, записывая
+// транзакции expiry. Запуск безопасен на нескольких репликах одновременно.
+type CoinExpiryService interface {
+	ExpireLots(ctx context.Context) (CoinExpiryResult, error)
+}
+
+type coinExpiryService struct {
+	repo   repository.ExpiryRepository
+	logger utils.Logger
+}
+
+func NewCoinExpiryService(repo repository.ExpiryRepository, logger utils.Logger) CoinExpiryService {
+	logger.WithFields(utils.LogFields{"component": "coin_expiry_service"}).Info("CoinExpiryService initialized")
+	return &coinExpiryService{
+		repo:   repo,
+		logger: logger.WithFields(utils.LogFields{"component": "coin_expiry_service"}),
+	}
+}
+
+func (s *coinExpiryService) ExpireLots(ctx context.Context) (CoinExpiryResult, error) {
+	log := s.logger.WithFields(utils.LogFields{"operation": "expire_lots"})
+
+	var result CoinExpiryResult
+	for {
+		rows, err := s.repo.ExpireBatch(ctx, expiryBatchSize)
+		if err != nil {
+			log.WithFields(utils.LogFields{"error": err}).Error("Coin expiry failed")
+			return result, err
+		}
+		if len(rows) == 0 {
+			break
+		}
+		result.Transactions += len(rows)
+		for _, row := range rows {
+			result.Amount += int64(row.Amount)
+		}
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+	}
+
+	if result.Transactions > 0 {
+		log.WithFields(utils.LogFields{"transactions": result.Transactions, "amount": result.Amount}).Info("Expired coins written off")
+	}
+	return result, nil
+}
@@ -0,0 +1,58 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/par1ram/merch-store/internal/db"
+	"github.com/par1ram/merch-store/internal/service"
+	"github.com/par1ram/merch-store/internal/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockExpiryRepository struct {
+	mock.Mock
+}
+
+func (m *MockExpiryRepository) ExpireBatch(ctx context.Context, batchSize int32) ([]db.ExpireCoinLotsRow, error) {
+	args := m.Called(ctx, batchSize)
+	if res, ok := args.Get(0).([]db.ExpireCoinLotsRow); ok {
+		return res, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func TestExpireLots_Batches(t *testing.T) {
+	ctx := context.Background()
+
+	repo := new(MockExpiryRepository)
+	repo.On("ExpireBatch", ctx, int32(500)).Return([]db.ExpireCoinLotsRow{
+		{EmployeeID: 1, Amount: 40},
+		{EmployeeID: 2, Amount: 15},
+	}, nil).Once()
+	repo.On("ExpireBatch", ctx, int32(500)).Return([]db.ExpireCoinLotsRow{{EmployeeID: 1, Amount: 5}}, nil).Once()
+	repo.On("ExpireBatch", ctx, int32(500)).Return([]db.ExpireCoinLotsRow{}, nil).Once()
+
+	svc := service.NewCoinExpiryService(repo, utils.NewLogger())
+	result, err := svc.ExpireLots(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, service.CoinExpiryResult{Transactions: 3, Amount: 60}, result)
+
+	repo.AssertExpectations(t)
+}
+
+func TestExpireLots_Error(t *testing.T) {
+	ctx := context.Background()
+	dbErr := errors.New("db error")
+
+	repo := new(MockExpiryRepository)
+	repo.On("ExpireBatch", ctx, int32(500)).Return(nil, dbErr).Once()
+
+	svc := service.NewCoinExpiryService(repo, utils.NewLogger())
+	_, err := svc.ExpireLots(ctx)
+	assert.ErrorIs(t, err, dbErr)
+
+	repo.AssertExpectations(t)
+}
@@ -0,0 +1,198 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/par1ram/merch-store/internal/db"
+	"github.com/par1ram/merch-store/internal/middleware"
+	"github.com/par1ram/merch-store/internal/repository"
+	"github.com/par1ram/merch-store/internal/utils"
+)
+
+const (
+	// maxGrantReasonLength ограничивает длину причины начисления или списания.
+	maxGrantReasonLength = 500
+	// maxGrantAmount ограничивает сумму одной операции по модулю.
+	maxGrantAmount = 1_000_000
+	// maxGrantRecipients ограничивает число сотрудников в одной операции.
+	maxGrantRecipients = 500
+)
+
+var (
+	ErrInvalidGrantAmount     = newError(CodeInvalidGrantAmount, KindInvalid, "amount must be a non-zero integer within limits")
+	ErrInvalidGrantReason     = newError(CodeInvalidGrantReason, KindInvalid, "reason is required and must not exceed 500 characters")
+	ErrInvalidGrantRecipients = newError(CodeInvalidGrantRecipients, KindInvalid, "usernames are required")
+)
+
+// CoinGrant — результат начисления или списания для одного сотрудника.
+// Amount отрицателен для списаний.
+type CoinGrant struct {
+	TransactionID int32  `json:"transaction_id"`
+	Username      string `json:"username"`
+	Amount        int32  `json:"amount"`
+	Balance       int32  `json:"balance"`
+}
+
+// GrantService начисляет (amount > 0) и списывает (amount < 0) монеты
+// администратором. Операция над списком сотрудников выполняется атомарно:
+// если хотя бы одно изменение невозможно, не применяется ни одно.
+type GrantService interface {
+	AdjustCoins(ctx context.Context, usernames []string, amount int32, reason string) ([]CoinGrant, error)
+}
+
+type grantService struct {
+	repo   repository.GrantRepository
+	logger utils.Logger
+}
+
+func NewGrantService(repo repository.GrantRepository, logger utils.Logger) GrantService {
+	logger.WithFields(utils.LogFields{"component": "grant_service"}).Info("GrantService initialized")
+	return &grantService{
+		repo:   repo,
+		logger: logger.WithFields(utils.LogFields{"component": "grant_service"}),
+	}
+}
+
+func (s *grantService) AdjustCoins(ctx context.Context, usernames []string, amount int32, reason string) ([]CoinGrant, error) {
+	adminID := middleware.GetUserIDFromContext(ctx)
+	log := s.logger.WithFields(utils.LogFields{
+		"operation": "adjust_coins",
+		"admin_id":  adminID,
+		"amount":    amount,
+	})
+
+	if adminID == 0 {
+		log.Error("User not authenticated")
+		return nil, ErrUnauthenticated
+	}
+	if amount == 0 || amount > maxGrantAmount || amount < -maxGrantAmount {
+		return nil, fmt.Errorf("%w: %w", ErrBusinessValidation, ErrInvalidGrantAmount)
+	}
+	reason = strings.TrimSpace(reason)
+	if reason == "" || len(reason) > maxGrantReasonLength {
+		return nil, fmt.Errorf("%w: %w", ErrBusinessValidation, ErrInvalidGrantReason)
+	}
+	usernames = uniqueUsernames(usernames)
+	if len(usernames) == 0 || len(usernames) > maxGrantRecipients {
+		return nil, fmt.Errorf("%w: %w", ErrBusinessValidation, ErrInvalidGrantRecipients)
+	}
+
+	grants := make([]CoinGrant, 0, len(usernames))
+	err := s.repo.ExecTx(ctx, func(r repository.GrantRepository) error {
+		grants = grants[:0]
+
+		// Сначала находятся все сотрудники, затем их строки блокируются одним
+		// запросом по возрастанию id — в том же порядке, что и при переводах.
+		employees := make([]db.Employee, 0, len(usernames))
+		ids := make([]int32, 0, len(usernames))
+		for _, username := range usernames {
+			employee, err := r.GetEmployee(ctx, username)
+			if err != nil {
+				if errors.Is(err, sql.ErrNoRows) {
+					return fmt.Errorf("%w: %w: %s", ErrBusinessValidation, ErrUserNotFound, username)
+				}
+				return fmt.Errorf("failed to get employee: %w", err)
+			}
+			employees = append(employees, employee)
+			ids = append(ids, employee.ID)
+		}
+		balances, err := r.LockBalances(ctx, ids...)
+		if err != nil {
+			return fmt.Errorf("failed to lock balances: %w", err)
+		}
+
+		for _, employee := range employees {
+			grant, err := s.adjust(ctx, r, int32(adminID), employee, balances[employee.ID], amount, reason)
+			if err != nil {
+				return err
+			}
+			grants = append(grants, grant)
+		}
+		return nil
+	})
+	if err != nil {
+		log.WithFields(utils.LogFields{"error": err}).Error("Coin adjustment failed")
+		return nil, err
+	}
+
+	log.WithFields(utils.LogFields{"recipients": len(grants), "reason": reason}).Info("Coins adjusted")
+	return grants, nil
+}
+
+// adjust меняет баланс одного сотрудника, строка которого уже заблокирована,
+// и записывает операцию в историю. balance — баланс на момент блокировки.
+// Списание использует ту же защиту от отрицательного баланса, что и переводы;
+// начисление не может поднять баланс выше предела int4 столбца employees.coins.
+func (s *grantService) adjust(ctx context.Context, r repository.GrantRepository, adminID int32, employee db.Employee, balance, amount int32, reason string) (CoinGrant, error) {
+	username := employee.Username
+	if int64(balance)+int64(amount) > math.MaxInt32 {
+		return CoinGrant{}, fmt.Errorf("%w: %w: %s", ErrBusinessValidation, ErrInvalidGrantAmount, username)
+	}
+	if int64(balance)+int64(amount) < 0 {
+		return CoinGrant{}, fmt.Errorf("%w: %w: %s", ErrBusinessValidation, ErrInsufficientFunds, username)
+	}
+
+	affected, err := r.UpdateCoins(ctx, employee.ID, amount)
+	if err != nil {
+		return CoinGrant{}, fmt.Errorf("failed to update coins: %w", err)
+	}
+	if affected == 0 {
+		return CoinGrant{}, fmt.Errorf("%w: %w: %s", ErrBusinessValidation, ErrInsufficientFunds, username)
+	}
+
+	var transactionID int32
+	if amount > 0 {
+		transactionID, err = r.CreateGrantTransaction(ctx, db.CreateCoinTransactionGrantParams{
+			ToEmployeeID: employee.ID,
+			Amount:       amount,
+			Reason:       reason,
+			CreatedBy:    adminID,
+		})
+	} else {
+		transactionID, err = r.CreateAdjustmentTransaction(ctx, db.CreateCoinTransactionAdjustmentParams{
+			FromEmployeeID: employee.ID,
+			Amount:         -amount,
+			Reason:         reason,
+			CreatedBy:      adminID,
+		})
+	}
+	if err != nil {
+		return CoinGrant{}, fmt.Errorf("failed to record coin adjustment: %w", err)
+	}
+
+	newBalance, err := r.GetBalance(ctx, employee.ID)
+	if err != nil {
+		return CoinGrant{}, fmt.Errorf("failed to get balance: %w", err)
+	}
+
+	return CoinGrant{
+		TransactionID: transactionID,
+		Username:      employee.Username,
+		Amount:        amount,
+		Balance:       newBalance,
+	}, nil
+}
+
+// uniqueUsernames убирает пустые значения и повторы, сохраняя порядок:
+// повтор имени в списке не должен начислять монеты дважды.
+func uniqueUsernames(usernames []string) []string {
+	seen := make(map[string]struct{}, len(usernames))
+	result := make([]string, 0, len(usernames))
+	for _, username := range usernames {
+		username = strings.TrimSpace(username)
+		if username == "" {
+			continue
+		}
+		if _, ok := seen[username]; ok {
+			continue
+		}
+		seen[username] = struct{}{}
+		result = append(result, username)
+	}
+	return result
+}
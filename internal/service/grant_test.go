@@ -0,0 +1,188 @@
+package service_test
+
+import (
+	"context"
+	"database/sql"
+	"math"
+	"testing"
+
+	"github.com/par1ram/merch-store/internal/db"
+	"github.com/par1ram/merch-store/internal/repository"
+	"github.com/par1ram/merch-store/internal/service"
+	"github.com/par1ram/merch-store/internal/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockGrantRepository struct {
+	mock.Mock
+}
+
+func (m *MockGrantRepository) ExecTx(ctx context.Context, fn func(repository.GrantRepository) error) error {
+	args := m.Called(ctx, fn)
+	if err := args.Error(0); err != nil {
+		return err
+	}
+	return fn(m)
+}
+
+func (m *MockGrantRepository) GetEmployee(ctx context.Context, username string) (db.Employee, error) {
+	args := m.Called(ctx, username)
+	return args.Get(0).(db.Employee), args.Error(1)
+}
+
+func (m *MockGrantRepository) LockBalances(ctx context.Context, userIDs ...int32) (map[int32]int32, error) {
+	args := m.Called(ctx, userIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[int32]int32), args.Error(1)
+}
+
+func (m *MockGrantRepository) UpdateCoins(ctx context.Context, userID, delta int32) (int64, error) {
+	args := m.Called(ctx, userID, delta)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockGrantRepository) GetBalance(ctx context.Context, userID int32) (int32, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(int32), args.Error(1)
+}
+
+func (m *MockGrantRepository) CreateGrantTransaction(ctx context.Context, params db.CreateCoinTransactionGrantParams) (int32, error) {
+	args := m.Called(ctx, params)
+	return args.Get(0).(int32), args.Error(1)
+}
+
+func (m *MockGrantRepository) CreateAdjustmentTransaction(ctx context.Context, params db.CreateCoinTransactionAdjustmentParams) (int32, error) {
+	args := m.Called(ctx, params)
+	return args.Get(0).(int32), args.Error(1)
+}
+
+func TestAdjustCoins_GrantToList(t *testing.T) {
+	ctx := userCtx(1)
+	repo := new(MockGrantRepository)
+	repo.On("ExecTx", ctx, mock.Anything).Return(nil).Once()
+	repo.On("GetEmployee", ctx, "alice").Return(db.Employee{ID: 7, Username: "alice"}, nil).Once()
+	repo.On("GetEmployee", ctx, "bob").Return(db.Employee{ID: 8, Username: "bob"}, nil).Once()
+	repo.On("LockBalances", ctx, []int32{7, 8}).Return(map[int32]int32{7: 1000, 8: 200}, nil).Once()
+	repo.On("UpdateCoins", ctx, int32(7), int32(100)).Return(int64(1), nil).Once()
+	repo.On("UpdateCoins", ctx, int32(8), int32(100)).Return(int64(1), nil).Once()
+	repo.On("CreateGrantTransaction", ctx, db.CreateCoinTransactionGrantParams{
+		ToEmployeeID: 7, Amount: 100, Reason: "hackathon winner", CreatedBy: 1,
+	}).Return(int32(40), nil).Once()
+	repo.On("CreateGrantTransaction", ctx, db.CreateCoinTransactionGrantParams{
+		ToEmployeeID: 8, Amount: 100, Reason: "hackathon winner", CreatedBy: 1,
+	}).Return(int32(41), nil).Once()
+	repo.On("GetBalance", ctx, int32(7)).Return(int32(1100), nil).Once()
+	repo.On("GetBalance", ctx, int32(8)).Return(int32(300), nil).Once()
+
+	svc := service.NewGrantService(repo, utils.NewLogger())
+	grants, err := svc.AdjustCoins(ctx, []string{"alice", " bob ", "alice"}, 100, "  hackathon winner ")
+	assert.NoError(t, err)
+	assert.Equal(t, []service.CoinGrant{
+		{TransactionID: 40, Username: "alice", Amount: 100, Balance: 1100},
+		{TransactionID: 41, Username: "bob", Amount: 100, Balance: 300},
+	}, grants)
+
+	repo.AssertExpectations(t)
+}
+
+func TestAdjustCoins_DeductionRecordsAdjustment(t *testing.T) {
+	ctx := userCtx(1)
+	repo := new(MockGrantRepository)
+	repo.On("ExecTx", ctx, mock.Anything).Return(nil).Once()
+	repo.On("GetEmployee", ctx, "alice").Return(db.Employee{ID: 7, Username: "alice"}, nil).Once()
+	repo.On("LockBalances", ctx, []int32{7}).Return(map[int32]int32{7: 1000}, nil).Once()
+	repo.On("UpdateCoins", ctx, int32(7), int32(-20)).Return(int64(1), nil).Once()
+	repo.On("CreateAdjustmentTransaction", ctx, db.CreateCoinTransactionAdjustmentParams{
+		FromEmployeeID: 7, Amount: 20, Reason: "duplicate grant", CreatedBy: 1,
+	}).Return(int32(42), nil).Once()
+	repo.On("GetBalance", ctx, int32(7)).Return(int32(980), nil).Once()
+
+	svc := service.NewGrantService(repo, utils.NewLogger())
+	grants, err := svc.AdjustCoins(ctx, []string{"alice"}, -20, "duplicate grant")
+	assert.NoError(t, err)
+	assert.Equal(t, int32(-20), grants[0].Amount)
+
+	repo.AssertExpectations(t)
+}
+
+func TestAdjustCoins_DeductionBelowZero(t *testing.T) {
+	ctx := userCtx(1)
+	repo := new(MockGrantRepository)
+	repo.On("ExecTx", ctx, mock.Anything).Return(nil).Once()
+	repo.On("GetEmployee", ctx, "alice").Return(db.Employee{ID: 7, Username: "alice"}, nil).Once()
+	repo.On("LockBalances", ctx, []int32{7}).Return(map[int32]int32{7: 1000}, nil).Once()
+
+	svc := service.NewGrantService(repo, utils.NewLogger())
+	_, err := svc.AdjustCoins(ctx, []string{"alice"}, -5000, "correction")
+	assert.ErrorIs(t, err, service.ErrInsufficientFunds)
+
+	repo.AssertExpectations(t)
+	repo.AssertNotCalled(t, "UpdateCoins", mock.Anything, mock.Anything, mock.Anything)
+	repo.AssertNotCalled(t, "CreateAdjustmentTransaction", mock.Anything, mock.Anything)
+}
+
+func TestAdjustCoins_GrantOverflowsBalance(t *testing.T) {
+	ctx := userCtx(1)
+	repo := new(MockGrantRepository)
+	repo.On("ExecTx", ctx, mock.Anything).Return(nil).Once()
+	repo.On("GetEmployee", ctx, "alice").Return(db.Employee{ID: 7, Username: "alice"}, nil).Once()
+	repo.On("LockBalances", ctx, []int32{7}).Return(map[int32]int32{7: math.MaxInt32 - 10}, nil).Once()
+
+	svc := service.NewGrantService(repo, utils.NewLogger())
+	_, err := svc.AdjustCoins(ctx, []string{"alice"}, 11, "bonus")
+	assert.ErrorIs(t, err, service.ErrInvalidGrantAmount)
+	assert.ErrorIs(t, err, service.ErrBusinessValidation)
+	assert.Contains(t, err.Error(), "alice")
+
+	repo.AssertExpectations(t)
+	repo.AssertNotCalled(t, "UpdateCoins", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestAdjustCoins_UnknownUserAbortsBatch(t *testing.T) {
+	ctx := userCtx(1)
+	repo := new(MockGrantRepository)
+	repo.On("ExecTx", ctx, mock.Anything).Return(nil).Once()
+	repo.On("GetEmployee", ctx, "alice").Return(db.Employee{ID: 7, Username: "alice"}, nil).Once()
+	repo.On("GetEmployee", ctx, "ghost").Return(db.Employee{}, sql.ErrNoRows).Once()
+
+	svc := service.NewGrantService(repo, utils.NewLogger())
+	grants, err := svc.AdjustCoins(ctx, []string{"alice", "ghost"}, 50, "bonus")
+	assert.ErrorIs(t, err, service.ErrUserNotFound)
+	assert.Contains(t, err.Error(), "ghost")
+	assert.Nil(t, grants)
+
+	repo.AssertExpectations(t)
+	repo.AssertNotCalled(t, "LockBalances", mock.Anything, mock.Anything)
+	repo.AssertNotCalled(t, "UpdateCoins", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestAdjustCoins_Validation(t *testing.T) {
+	cases := []struct {
+		usernames []string
+		amount    int32
+		reason    string
+		err       error
+	}{
+		{[]string{"alice"}, 0, "bonus", service.ErrInvalidGrantAmount},
+		{[]string{"alice"}, 2_000_000, "bonus", service.ErrInvalidGrantAmount},
+		{[]string{"alice"}, 10, "   ", service.ErrInvalidGrantReason},
+		{[]string{"", " "}, 10, "bonus", service.ErrInvalidGrantRecipients},
+	}
+
+	for _, tc := range cases {
+		repo := new(MockGrantRepository)
+		svc := service.NewGrantService(repo, utils.NewLogger())
+
+		_, err := svc.AdjustCoins(userCtx(1), tc.usernames, tc.amount, tc.reason)
+		assert.ErrorIs(t, err, tc.err)
+		assert.ErrorIs(t, err, service.ErrBusinessValidation)
+		repo.AssertNotCalled(t, "ExecTx", mock.Anything, mock.Anything)
+	}
+
+	_, err := service.NewGrantService(new(MockGrantRepository), utils.NewLogger()).
+		AdjustCoins(context.Background(), []string{"alice"}, 10, "bonus")
+	assert.ErrorIs(t, err, service.ErrUnauthenticated)
+}
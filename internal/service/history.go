@@ -0,0 +1,276 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/par1ram/merch-store/internal/db"
+	"github.com/par1ram/merch-store/internal/middleware"
+	"github.com/par1ram/merch-store/internal/repository"
+	"github.com/par1ram/merch-store/internal/utils"
+)
+
+// Направления операций в истории: монеты зачислены сотруднику или списаны с него.
+const (
+	HistoryDirectionIncoming = "incoming"
+	HistoryDirectionOutgoing = "outgoing"
+)
+
+const (
+	defaultHistoryPageSize = 50
+	maxHistoryPageSize     = 200
+)
+
+var (
+	ErrInvalidHistoryFilter = newError(CodeInvalidHistoryFilter, KindInvalid, "invalid history filter")
+	ErrInvalidHistoryCursor = newError(CodeInvalidHistoryCursor, KindInvalid, "invalid history cursor")
+)
+
+// HistoryFilter задаёт фильтры и страницу истории операций. Пустые поля не
+// ограничивают выборку; период — полуинтервал [From, To). Limit 0 означает
+// размер страницы по умолчанию.
+type HistoryFilter struct {
+	Direction    string
+	Counterparty string
+	Types        []string
+	From         time.Time
+	To           time.Time
+	MinAmount    int32
+	MaxAmount    int32
+	Cursor       string
+	Limit        int32
+}
+
+// HistoryEntry — операция с монетами с точки зрения сотрудника. Amount всегда
+// положителен, знак определяется Direction. Counterparty пуст для операций без
+// второго сотрудника: покупок, начислений, пособий и сгорания монет. Item и
+// Quantity заполнены только для покупок и возвратов.
+type HistoryEntry struct {
+	ID           int32     `json:"id"`
+	Type         string    `json:"type"`
+	Direction    string    `json:"direction"`
+	Amount       int32     `json:"amount"`
+	Counterparty string    `json:"counterparty,omitempty"`
+	Item         string    `json:"item,omitempty"`
+	Quantity     int32     `json:"quantity,omitempty"`
+	Message      string    `json:"message,omitempty"`
+	Hashtags     []string  `json:"hashtags,omitempty"`
+	Reason       string    `json:"reason,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// HistoryPage — страница истории, новые операции первыми. NextCursor
+// передаётся в следующий запрос; пустой NextCursor означает последнюю страницу.
+type HistoryPage struct {
+	Entries    []HistoryEntry `json:"entries"`
+	NextCursor string         `json:"next_cursor,omitempty"`
+}
+
+// HistoryService возвращает полную ленту операций текущего пользователя —
+// переводы, покупки, возвраты, начисления и прочие движения монет в
+// хронологическом порядке — постранично. В отличие от /api/info, размер ответа
+// не зависит от стажа.
+type HistoryService interface {
+	ListHistory(ctx context.Context, filter HistoryFilter) (HistoryPage, error)
+}
+
+type historyService struct {
+	repo   repository.HistoryRepository
+	logger utils.Logger
+}
+
+func NewHistoryService(repo repository.HistoryRepository, logger utils.Logger) HistoryService {
+	logger.WithFields(utils.LogFields{"component": "history_service"}).Info("HistoryService initialized")
+	return &historyService{
+		repo:   repo,
+		logger: logger.WithFields(utils.LogFields{"component": "history_service"}),
+	}
+}
+
+func (s *historyService) ListHistory(ctx context.Context, filter HistoryFilter) (HistoryPage, error) {
+	userID := middleware.GetUserIDFromContext(ctx)
+	log := s.logger.WithFields(utils.LogFields{
+		"operation": "list_history",
+		"user_id":   userID,
+	})
+	if userID == 0 {
+		log.Error("User not authenticated")
+		return HistoryPage{}, ErrUnauthenticated
+	}
+
+	params, err := historyParams(int32(userID), filter)
+	if err != nil {
+		log.WithFields(utils.LogFields{"error": err}).Warn("Invalid history filter")
+		return HistoryPage{}, err
+	}
+
+	// Одна лишняя строка показывает, есть ли следующая страница.
+	pageSize := params.RowLimit
+	params.RowLimit++
+	rows, err := s.repo.ListCoinHistory(ctx, params)
+	if err != nil {
+		log.WithFields(utils.LogFields{"error": err}).Error("Failed to list history")
+		return HistoryPage{}, err
+	}
+
+	page := HistoryPage{Entries: make([]HistoryEntry, 0, min(len(rows), int(pageSize)))}
+	for i, row := range rows {
+		if i == int(pageSize) {
+			last := rows[i-1]
+			page.NextCursor = encodeHistoryCursor(last.CreatedAt.Time, last.ID)
+			break
+		}
+		page.Entries = append(page.Entries, toHistoryEntry(row))
+	}
+	return page, nil
+}
+
+func historyParams(userID int32, filter HistoryFilter) (db.ListCoinHistoryParams, error) {
+	params := db.ListCoinHistoryParams{EmployeeID: userID, RowLimit: defaultHistoryPageSize}
+	invalid := fmt.Errorf("%w: %w", ErrBusinessValidation, ErrInvalidHistoryFilter)
+
+	switch filter.Direction {
+	case "":
+	case HistoryDirectionIncoming:
+		params.Incoming = pgtype.Bool{Bool: true, Valid: true}
+	case HistoryDirectionOutgoing:
+		params.Incoming = pgtype.Bool{Bool: false, Valid: true}
+	default:
+		return params, invalid
+	}
+
+	if counterparty := strings.TrimSpace(filter.Counterparty); counterparty != "" {
+		params.Counterparty = pgtype.Text{String: counterparty, Valid: true}
+	}
+
+	for _, t := range filter.Types {
+		if !validTransactionType(t) {
+			return params, invalid
+		}
+		params.Types = append(params.Types, t)
+	}
+
+	if !filter.From.IsZero() {
+		params.CreatedFrom = pgtype.Timestamptz{Time: filter.From, Valid: true}
+	}
+	if !filter.To.IsZero() {
+		params.CreatedTo = pgtype.Timestamptz{Time: filter.To, Valid: true}
+	}
+	if !filter.From.IsZero() && !filter.To.IsZero() && !filter.From.Before(filter.To) {
+		return params, invalid
+	}
+
+	if filter.MinAmount < 0 || filter.MaxAmount < 0 || (filter.MaxAmount > 0 && filter.MinAmount > filter.MaxAmount) {
+		return params, invalid
+	}
+	if filter.MinAmount > 0 {
+		params.MinAmount = pgtype.Int4{Int32: filter.MinAmount, Valid: true}
+	}
+	if filter.MaxAmount > 0 {
+		params.MaxAmount = pgtype.Int4{Int32: filter.MaxAmount, Valid: true}
+	}
+
+	if filter.Limit < 0 || filter.Limit > maxHistoryPageSize {
+		return params, invalid
+	}
+	if filter.Limit > 0 {
+		params.RowLimit = filter.Limit
+	}
+
+	if filter.Cursor != "" {
+		createdAt, id, err := decodeHistoryCursor(filter.Cursor)
+		if err != nil {
+			return params, fmt.Errorf("%w: %w", ErrBusinessValidation, ErrInvalidHistoryCursor)
+		}
+		params.CursorCreatedAt = pgtype.Timestamptz{Time: createdAt, Valid: true}
+		params.CursorID = pgtype.Int4{Int32: id, Valid: true}
+	}
+	return params, nil
+}
+
+func validTransactionType(t string) bool {
+	switch db.TransactionTypeEnum(t) {
+	case db.TransactionTypeEnumTransfer,
+		db.TransactionTypeEnumPurchase,
+		db.TransactionTypeEnumRefund,
+		db.TransactionTypeEnumGrant,
+		db.TransactionTypeEnumAdjustment,
+		db.TransactionTypeEnumAllowance,
+		db.TransactionTypeEnumExpiry,
+		db.TransactionTypeEnumHold,
+		db.TransactionTypeEnumHoldRelease,
+		db.TransactionTypeEnumHoldRefund:
+		return true
+	}
+	return false
+}
+
+// encodeHistoryCursor кодирует позицию последней операции страницы. Время
+// хранится в микросекундах — с той же точностью, что и в PostgreSQL.
+func encodeHistoryCursor(createdAt time.Time, id int32) string {
+	raw := strconv.FormatInt(createdAt.UnixMicro(), 10) + ":" + strconv.FormatInt(int64(id), 10)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeHistoryCursor(cursor string) (time.Time, int32, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return time.Time{}, 0, err
+	}
+	microPart, idPart, ok := strings.Cut(string(raw), ":")
+	if !ok {
+		return time.Time{}, 0, fmt.Errorf("malformed cursor %q", raw)
+	}
+	micros, err := strconv.ParseInt(microPart, 10, 64)
+	if err != nil {
+		return time.Time{}, 0, err
+	}
+	id, err := strconv.ParseInt(idPart, 10, 32)
+	if err != nil || id <= 0 {
+		return time.Time{}, 0, fmt.Errorf("malformed cursor %q", raw)
+	}
+	return time.UnixMicro(micros).UTC(), int32(id), nil
+}
+
+func toHistoryEntry(row db.ListCoinHistoryRow) HistoryEntry {
+	return historyEntryFromActivity(repository.CoinActivity{
+		ID:           row.ID,
+		Type:         string(row.TransactionType),
+		Incoming:     row.Incoming,
+		Amount:       row.Amount,
+		Counterparty: row.Counterparty,
+		Item:         row.MerchName,
+		Quantity:     row.Quantity,
+		Message:      row.Message,
+		Hashtags:     row.Hashtags,
+		Reason:       row.Reason,
+		CreatedAt:    row.CreatedAt.Time,
+	})
+}
+
+func historyEntryFromActivity(a repository.CoinActivity) HistoryEntry {
+	entry := HistoryEntry{
+		ID:           a.ID,
+		Type:         a.Type,
+		Direction:    HistoryDirectionOutgoing,
+		Amount:       a.Amount,
+		Counterparty: a.Counterparty,
+		Message:      a.Message,
+		Hashtags:     a.Hashtags,
+		Reason:       a.Reason,
+		CreatedAt:    a.CreatedAt,
+	}
+	if a.Incoming {
+		entry.Direction = HistoryDirectionIncoming
+	}
+	if a.Item != "" {
+		entry.Item = a.Item
+		entry.Quantity = a.Quantity
+	}
+	return entry
+}
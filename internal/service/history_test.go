@@ -0,0 +1,170 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/par1ram/merch-store/internal/db"
+	"github.com/par1ram/merch-store/internal/service"
+	"github.com/par1ram/merch-store/internal/utils"
+)
+
+type MockHistoryRepository struct {
+	mock.Mock
+}
+
+func (m *MockHistoryRepository) ListCoinHistory(ctx context.Context, params db.ListCoinHistoryParams) ([]db.ListCoinHistoryRow, error) {
+	args := m.Called(ctx, params)
+	if rows, ok := args.Get(0).([]db.ListCoinHistoryRow); ok {
+		return rows, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func historyRow(id int32, createdAt time.Time) db.ListCoinHistoryRow {
+	return db.ListCoinHistoryRow{
+		ID:              id,
+		TransactionType: db.TransactionTypeEnumTransfer,
+		Amount:          10,
+		Counterparty:    "bob",
+		CreatedAt:       pgtype.Timestamptz{Time: createdAt, Valid: true},
+	}
+}
+
+func TestListHistory_PaginatesWithCursor(t *testing.T) {
+	ctx := userCtx(1)
+	newest := time.Date(2026, time.October, 10, 12, 0, 0, 123456000, time.UTC)
+	older := newest.Add(-time.Hour)
+
+	repo := new(MockHistoryRepository)
+	repo.On("ListCoinHistory", ctx, db.ListCoinHistoryParams{
+		EmployeeID: 1,
+		Incoming:   pgtype.Bool{Bool: false, Valid: true},
+		Types:      []string{"transfer"},
+		RowLimit:   3,
+	}).Return([]db.ListCoinHistoryRow{
+		historyRow(9, newest),
+		historyRow(8, older),
+		historyRow(7, older),
+	}, nil).Once()
+
+	svc := service.NewHistoryService(repo, utils.NewLogger())
+	page, err := svc.ListHistory(ctx, service.HistoryFilter{
+		Direction: service.HistoryDirectionOutgoing,
+		Types:     []string{"transfer"},
+		Limit:     2,
+	})
+	assert.NoError(t, err)
+	assert.Len(t, page.Entries, 2)
+	assert.Equal(t, int32(9), page.Entries[0].ID)
+	assert.Equal(t, service.HistoryDirectionOutgoing, page.Entries[0].Direction)
+	assert.NotEmpty(t, page.NextCursor)
+
+	// Следующая страница начинается после последней операции первой.
+	repo.On("ListCoinHistory", ctx, db.ListCoinHistoryParams{
+		EmployeeID:      1,
+		CursorCreatedAt: pgtype.Timestamptz{Time: older, Valid: true},
+		CursorID:        pgtype.Int4{Int32: 8, Valid: true},
+		RowLimit:        3,
+	}).Return([]db.ListCoinHistoryRow{historyRow(7, older)}, nil).Once()
+
+	page, err = svc.ListHistory(ctx, service.HistoryFilter{Cursor: page.NextCursor, Limit: 2})
+	assert.NoError(t, err)
+	assert.Len(t, page.Entries, 1)
+	assert.Empty(t, page.NextCursor)
+
+	repo.AssertExpectations(t)
+}
+
+func TestListHistory_Filters(t *testing.T) {
+	ctx := userCtx(1)
+	from := time.Date(2026, time.September, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, time.October, 1, 0, 0, 0, 0, time.UTC)
+
+	repo := new(MockHistoryRepository)
+	repo.On("ListCoinHistory", ctx, db.ListCoinHistoryParams{
+		EmployeeID:   1,
+		Incoming:     pgtype.Bool{Bool: true, Valid: true},
+		Counterparty: pgtype.Text{String: "alice", Valid: true},
+		CreatedFrom:  pgtype.Timestamptz{Time: from, Valid: true},
+		CreatedTo:    pgtype.Timestamptz{Time: to, Valid: true},
+		MinAmount:    pgtype.Int4{Int32: 5, Valid: true},
+		MaxAmount:    pgtype.Int4{Int32: 100, Valid: true},
+		RowLimit:     51,
+	}).Return([]db.ListCoinHistoryRow{}, nil).Once()
+
+	svc := service.NewHistoryService(repo, utils.NewLogger())
+	page, err := svc.ListHistory(ctx, service.HistoryFilter{
+		Direction:    service.HistoryDirectionIncoming,
+		Counterparty: " alice ",
+		From:         from,
+		To:           to,
+		MinAmount:    5,
+		MaxAmount:    100,
+	})
+	assert.NoError(t, err)
+	assert.NotNil(t, page.Entries)
+	assert.Empty(t, page.NextCursor)
+
+	repo.AssertExpectations(t)
+}
+
+func TestListHistory_InvalidFilter(t *testing.T) {
+	ctx := userCtx(1)
+	from := time.Date(2026, time.October, 1, 0, 0, 0, 0, time.UTC)
+	svc := service.NewHistoryService(new(MockHistoryRepository), utils.NewLogger())
+
+	for name, filter := range map[string]service.HistoryFilter{
+		"direction":    {Direction: "sideways"},
+		"type":         {Types: []string{"bribe"}},
+		"period":       {From: from, To: from},
+		"amount range": {MinAmount: 100, MaxAmount: 10},
+		"limit":        {Limit: 1000},
+	} {
+		_, err := svc.ListHistory(ctx, filter)
+		assert.ErrorIs(t, err, service.ErrBusinessValidation, name)
+		assert.ErrorIs(t, err, service.ErrInvalidHistoryFilter, name)
+	}
+
+	_, err := svc.ListHistory(ctx, service.HistoryFilter{Cursor: "not a cursor"})
+	assert.ErrorIs(t, err, service.ErrInvalidHistoryCursor)
+}
+
+func TestListHistory_Unauthenticated(t *testing.T) {
+	svc := service.NewHistoryService(new(MockHistoryRepository), utils.NewLogger())
+	_, err := svc.ListHistory(context.Background(), service.HistoryFilter{})
+	assert.ErrorIs(t, err, service.ErrUnauthenticated)
+}
+
+func TestListHistory_IncludesPurchases(t *testing.T) {
+	ctx := userCtx(1)
+	createdAt := time.Date(2026, time.October, 10, 12, 0, 0, 0, time.UTC)
+
+	repo := new(MockHistoryRepository)
+	repo.On("ListCoinHistory", ctx, db.ListCoinHistoryParams{EmployeeID: 1, RowLimit: 51}).
+		Return([]db.ListCoinHistoryRow{
+			{
+				ID: 12, TransactionType: db.TransactionTypeEnumPurchase, Amount: 160, MerchName: "t-shirt", Quantity: 2,
+				CreatedAt: pgtype.Timestamptz{Time: createdAt, Valid: true},
+			},
+			{
+				ID: 11, TransactionType: db.TransactionTypeEnumGrant, Incoming: true, Amount: 200, Quantity: 1,
+				Reason: "hackathon winner", CreatedAt: pgtype.Timestamptz{Time: createdAt, Valid: true},
+			},
+		}, nil).Once()
+
+	svc := service.NewHistoryService(repo, utils.NewLogger())
+	page, err := svc.ListHistory(ctx, service.HistoryFilter{})
+	assert.NoError(t, err)
+	assert.Equal(t, []service.HistoryEntry{
+		{ID: 12, Type: "purchase", Direction: service.HistoryDirectionOutgoing, Amount: 160, Item: "t-shirt", Quantity: 2, CreatedAt: createdAt},
+		{ID: 11, Type: "grant", Direction: service.HistoryDirectionIncoming, Amount: 200, Reason: "hackathon winner", CreatedAt: createdAt},
+	}, page.Entries)
+
+	repo.AssertExpectations(t)
+}
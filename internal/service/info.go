@@ -2,15 +2,23 @@ package service
 
 import (
 	"context"
+	"time"
 
 	"github.com/par1ram/merch-store/internal/repository"
 	"github.com/par1ram/merch-store/internal/utils"
 )
 
+// InfoResponse.Inventory содержит все купленные и не возвращённые товары,
+// включая ещё не выданные; заказы, ожидающие выдачи, перечислены в
+// PendingOrders, а Received — только уже выданные на руки товары.
+// UpcomingExpirations — монеты, сгорающие в ближайшие 90 дней.
 type InfoResponse struct {
-	Coins       int         `json:"coins"`
-	Inventory   []Inventory `json:"inventory"`
-	CoinHistory CoinHistory `json:"coinHistory"`
+	Coins               int              `json:"coins"`
+	Inventory           []Inventory      `json:"inventory"`
+	Received            []Inventory      `json:"received"`
+	CoinHistory         CoinHistory      `json:"coinHistory"`
+	UpcomingExpirations []CoinExpiration `json:"upcomingExpirations"`
+	PendingOrders       []PendingOrder   `json:"pendingOrders"`
 }
 
 type Inventory struct {
@@ -18,21 +26,82 @@ type Inventory struct {
 	Quantity int    `json:"quantity"`
 }
 
+// CoinHistory содержит не более infoRecentLimit последних записей каждого вида.
+// CoinHistory.Adjustments содержит начисления (grant) и списания (adjustment)
+// администраторами, ежемесячные пособия (allowance), сгоревшие монеты (expiry)
+// и движения удержанных переводов (hold, hold_release, hold_refund);
+// суммы списаний отрицательны. CoinHistory.Activity — общая лента операций
+// всех типов, включая покупки и возвраты, в хронологическом порядке (новые
+// первыми); полная лента доступна в /api/history.
+//
+// Adjustments появился раньше Activity и остаётся для клиентов, которые
+// показывают только операции, не связанные с переводами и покупками: в общей
+// ленте они теряются среди переводов, а лимит infoRecentLimit действует на
+// каждый список отдельно. Новым клиентам достаточно Activity.
 type CoinHistory struct {
-	Received []ReceivedTransaction `json:"received"`
-	Sent     []SentTransaction     `json:"sent"`
+	Received    []ReceivedTransaction `json:"received"`
+	Sent        []SentTransaction     `json:"sent"`
+	Adjustments []CoinAdjustment      `json:"adjustments"`
+	Activity    []ActivityEntry       `json:"activity"`
 }
 
+// ActivityEntry — запись ленты CoinHistory.Activity. Поля совпадают
+// с HistoryEntry, но имена в JSON следуют camelCase, как и остальной
+// ответ /api/info; HistoryEntry сохраняет snake_case ответа /api/history.
+type ActivityEntry struct {
+	ID           int32     `json:"id"`
+	Type         string    `json:"type"`
+	Direction    string    `json:"direction"`
+	Amount       int32     `json:"amount"`
+	Counterparty string    `json:"counterparty,omitempty"`
+	Item         string    `json:"item,omitempty"`
+	Quantity     int32     `json:"quantity,omitempty"`
+	Message      string    `json:"message,omitempty"`
+	Hashtags     []string  `json:"hashtags,omitempty"`
+	Reason       string    `json:"reason,omitempty"`
+	CreatedAt    time.Time `json:"createdAt"`
+}
+
+// ReceivedTransaction и SentTransaction содержат сообщение отправителя
+// и хэштеги из него, если они были указаны при переводе.
 type ReceivedTransaction struct {
-	FromUser string `json:"fromUser"`
-	Amount   int    `json:"amount"`
+	FromUser string   `json:"fromUser"`
+	Amount   int      `json:"amount"`
+	Message  string   `json:"message,omitempty"`
+	Hashtags []string `json:"hashtags,omitempty"`
 }
 
 type SentTransaction struct {
-	ToUser string `json:"toUser"`
-	Amount int    `json:"amount"`
+	ToUser   string   `json:"toUser"`
+	Amount   int      `json:"amount"`
+	Message  string   `json:"message,omitempty"`
+	Hashtags []string `json:"hashtags,omitempty"`
+}
+
+type CoinAdjustment struct {
+	Type      string    `json:"type"`
+	Amount    int       `json:"amount"`
+	Reason    string    `json:"reason"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type CoinExpiration struct {
+	Amount    int       `json:"amount"`
+	ExpiresAt time.Time `json:"expiresAt"`
 }
 
+type PendingOrder struct {
+	ID       int32     `json:"id"`
+	Item     string    `json:"item"`
+	Quantity int       `json:"quantity"`
+	Status   string    `json:"status"`
+	PlacedAt time.Time `json:"placedAt"`
+}
+
+// infoRecentLimit — сколько последних переводов и корректировок каждого вида
+// попадает в /api/info. Полная история доступна через HistoryService.
+const infoRecentLimit = 20
+
 type InfoService interface {
 	GetInfo(ctx context.Context, userID int64) (InfoResponse, error)
 }
@@ -80,7 +149,22 @@ func (s *infoService) GetInfo(ctx context.Context, userID int64) (InfoResponse,
 		})
 	}
 
-	rec, err := s.repo.GetReceivedTransfers(ctx, userID)
+	recItems, err := s.repo.GetReceivedItems(ctx, userID)
+	if err != nil {
+		log.WithFields(utils.LogFields{"error": err}).Error("Failed to get received items")
+		return InfoResponse{}, err
+	}
+	log.WithFields(utils.LogFields{"received_items_count": len(recItems)}).Debug("Received items retrieved")
+
+	received := make([]Inventory, 0, len(recItems))
+	for _, item := range recItems {
+		received = append(received, Inventory{
+			Type:     item.Type,
+			Quantity: item.Quantity,
+		})
+	}
+
+	rec, err := s.repo.GetReceivedTransfers(ctx, userID, infoRecentLimit)
 	if err != nil {
 		log.WithFields(utils.LogFields{"error": err}).Error("Failed to get received transfers")
 		return InfoResponse{}, err
@@ -92,10 +176,12 @@ func (s *infoService) GetInfo(ctx context.Context, userID int64) (InfoResponse,
 		recTrans = append(recTrans, ReceivedTransaction{
 			FromUser: t.FromUser,
 			Amount:   t.Amount,
+			Message:  t.Message,
+			Hashtags: t.Hashtags,
 		})
 	}
 
-	sent, err := s.repo.GetSentTransfers(ctx, userID)
+	sent, err := s.repo.GetSentTransfers(ctx, userID, infoRecentLimit)
 	if err != nil {
 		log.WithFields(utils.LogFields{"error": err}).Error("Failed to get sent transfers")
 		return InfoResponse{}, err
@@ -105,25 +191,99 @@ func (s *infoService) GetInfo(ctx context.Context, userID int64) (InfoResponse,
 	sentTrans := make([]SentTransaction, 0, len(sent))
 	for _, t := range sent {
 		sentTrans = append(sentTrans, SentTransaction{
-			ToUser: t.ToUser,
-			Amount: t.Amount,
+			ToUser:   t.ToUser,
+			Amount:   t.Amount,
+			Message:  t.Message,
+			Hashtags: t.Hashtags,
+		})
+	}
+
+	adj, err := s.repo.GetCoinAdjustments(ctx, userID, infoRecentLimit)
+	if err != nil {
+		log.WithFields(utils.LogFields{"error": err}).Error("Failed to get coin adjustments")
+		return InfoResponse{}, err
+	}
+	log.WithFields(utils.LogFields{"adjustment_count": len(adj)}).Debug("Coin adjustments retrieved")
+
+	adjustments := make([]CoinAdjustment, 0, len(adj))
+	for _, a := range adj {
+		adjustments = append(adjustments, CoinAdjustment{
+			Type:      a.Type,
+			Amount:    a.Amount,
+			Reason:    a.Reason,
+			CreatedAt: a.CreatedAt,
+		})
+	}
+
+	act, err := s.repo.GetRecentActivity(ctx, userID, infoRecentLimit)
+	if err != nil {
+		log.WithFields(utils.LogFields{"error": err}).Error("Failed to get recent activity")
+		return InfoResponse{}, err
+	}
+	log.WithFields(utils.LogFields{"activity_count": len(act)}).Debug("Recent activity retrieved")
+
+	activity := make([]ActivityEntry, 0, len(act))
+	for _, a := range act {
+		activity = append(activity, ActivityEntry(historyEntryFromActivity(a)))
+	}
+
+	exp, err := s.repo.GetUpcomingExpirations(ctx, userID)
+	if err != nil {
+		log.WithFields(utils.LogFields{"error": err}).Error("Failed to get upcoming expirations")
+		return InfoResponse{}, err
+	}
+	log.WithFields(utils.LogFields{"expiration_count": len(exp)}).Debug("Upcoming expirations retrieved")
+
+	expirations := make([]CoinExpiration, 0, len(exp))
+	for _, e := range exp {
+		expirations = append(expirations, CoinExpiration{
+			Amount:    e.Amount,
+			ExpiresAt: e.ExpiresAt,
+		})
+	}
+
+	orders, err := s.repo.GetPendingOrders(ctx, userID)
+	if err != nil {
+		log.WithFields(utils.LogFields{"error": err}).Error("Failed to get pending orders")
+		return InfoResponse{}, err
+	}
+	log.WithFields(utils.LogFields{"pending_orders_count": len(orders)}).Debug("Pending orders retrieved")
+
+	pendingOrders := make([]PendingOrder, 0, len(orders))
+	for _, o := range orders {
+		pendingOrders = append(pendingOrders, PendingOrder{
+			ID:       o.ID,
+			Item:     o.Item,
+			Quantity: o.Quantity,
+			Status:   o.Status,
+			PlacedAt: o.PlacedAt,
 		})
 	}
 
 	response := InfoResponse{
 		Coins:     coins,
 		Inventory: inventory,
+		Received:  received,
 		CoinHistory: CoinHistory{
-			Received: recTrans,
-			Sent:     sentTrans,
+			Received:    recTrans,
+			Sent:        sentTrans,
+			Adjustments: adjustments,
+			Activity:    activity,
 		},
+		UpcomingExpirations: expirations,
+		PendingOrders:       pendingOrders,
 	}
 
 	log.WithFields(utils.LogFields{
 		"total_coins":        coins,
 		"inventory_items":    len(inventory),
+		"received_items":     len(received),
 		"received_transfers": len(recTrans),
 		"sent_transfers":     len(sentTrans),
+		"coin_adjustments":   len(adjustments),
+		"activity":           len(activity),
+		"expirations":        len(expirations),
+		"pending_orders":     len(pendingOrders),
 	}).Info("Info retrieved successfully")
 
 	return response, nil
@@ -2,8 +2,10 @@ package service_test
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/par1ram/merch-store/internal/repository"
 	"github.com/par1ram/merch-store/internal/service"
@@ -30,22 +32,62 @@ func (m *MockInfoRepository) GetInventory(ctx context.Context, userID int64) ([]
 	return nil, args.Error(1)
 }
 
-func (m *MockInfoRepository) GetReceivedTransfers(ctx context.Context, userID int64) ([]repository.ReceivedTransaction, error) {
+func (m *MockInfoRepository) GetReceivedItems(ctx context.Context, userID int64) ([]repository.InventoryItem, error) {
 	args := m.Called(ctx, userID)
+	if res, ok := args.Get(0).([]repository.InventoryItem); ok {
+		return res, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockInfoRepository) GetReceivedTransfers(ctx context.Context, userID int64, limit int32) ([]repository.ReceivedTransaction, error) {
+	args := m.Called(ctx, userID, limit)
 	if res, ok := args.Get(0).([]repository.ReceivedTransaction); ok {
 		return res, args.Error(1)
 	}
 	return nil, args.Error(1)
 }
 
-func (m *MockInfoRepository) GetSentTransfers(ctx context.Context, userID int64) ([]repository.SentTransaction, error) {
-	args := m.Called(ctx, userID)
+func (m *MockInfoRepository) GetSentTransfers(ctx context.Context, userID int64, limit int32) ([]repository.SentTransaction, error) {
+	args := m.Called(ctx, userID, limit)
 	if res, ok := args.Get(0).([]repository.SentTransaction); ok {
 		return res, args.Error(1)
 	}
 	return nil, args.Error(1)
 }
 
+func (m *MockInfoRepository) GetCoinAdjustments(ctx context.Context, userID int64, limit int32) ([]repository.CoinAdjustment, error) {
+	args := m.Called(ctx, userID, limit)
+	if res, ok := args.Get(0).([]repository.CoinAdjustment); ok {
+		return res, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockInfoRepository) GetRecentActivity(ctx context.Context, userID int64, limit int32) ([]repository.CoinActivity, error) {
+	args := m.Called(ctx, userID, limit)
+	if res, ok := args.Get(0).([]repository.CoinActivity); ok {
+		return res, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockInfoRepository) GetUpcomingExpirations(ctx context.Context, userID int64) ([]repository.CoinExpiration, error) {
+	args := m.Called(ctx, userID)
+	if res, ok := args.Get(0).([]repository.CoinExpiration); ok {
+		return res, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockInfoRepository) GetPendingOrders(ctx context.Context, userID int64) ([]repository.PendingOrder, error) {
+	args := m.Called(ctx, userID)
+	if res, ok := args.Get(0).([]repository.PendingOrder); ok {
+		return res, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
 // TestInfoService_GetInfo_Success проверяет успешное получение информации.
 func TestInfoService_GetInfo_Success(t *testing.T) {
 	// Создаем мок-репозиторий.
@@ -60,14 +102,38 @@ func TestInfoService_GetInfo_Success(t *testing.T) {
 			{Type: "T-Shirt", Quantity: 2},
 			{Type: "Hoodie", Quantity: 1},
 		}, nil).Once()
-	mockRepo.On("GetReceivedTransfers", mock.Anything, userID).
+	mockRepo.On("GetReceivedItems", mock.Anything, userID).
+		Return([]repository.InventoryItem{
+			{Type: "T-Shirt", Quantity: 1},
+			{Type: "Hoodie", Quantity: 1},
+		}, nil).Once()
+	mockRepo.On("GetReceivedTransfers", mock.Anything, userID, int32(20)).
 		Return([]repository.ReceivedTransaction{
 			{FromUser: "Alice", Amount: 50},
 		}, nil).Once()
-	mockRepo.On("GetSentTransfers", mock.Anything, userID).
+	mockRepo.On("GetSentTransfers", mock.Anything, userID, int32(20)).
 		Return([]repository.SentTransaction{
 			{ToUser: "Bob", Amount: 30},
 		}, nil).Once()
+	mockRepo.On("GetCoinAdjustments", mock.Anything, userID, int32(20)).
+		Return([]repository.CoinAdjustment{
+			{Type: "grant", Amount: 200, Reason: "hackathon winner"},
+			{Type: "adjustment", Amount: -20, Reason: "duplicate grant"},
+		}, nil).Once()
+	mockRepo.On("GetRecentActivity", mock.Anything, userID, int32(20)).
+		Return([]repository.CoinActivity{
+			{ID: 12, Type: "purchase", Amount: 160, Item: "T-Shirt", Quantity: 2},
+			{ID: 11, Type: "transfer", Incoming: true, Amount: 50, Counterparty: "Alice"},
+		}, nil).Once()
+	expiresAt := time.Date(2026, time.December, 1, 0, 0, 0, 0, time.UTC)
+	mockRepo.On("GetUpcomingExpirations", mock.Anything, userID).
+		Return([]repository.CoinExpiration{
+			{Amount: 300, ExpiresAt: expiresAt},
+		}, nil).Once()
+	mockRepo.On("GetPendingOrders", mock.Anything, userID).
+		Return([]repository.PendingOrder{
+			{ID: 7, Item: "T-Shirt", Quantity: 1, Status: "packed"},
+		}, nil).Once()
 
 	// Создаем InfoService с моковым репо.
 	logger := utils.NewLogger() // или utils.NewLogger(), если у вас есть такая
@@ -87,6 +153,12 @@ func TestInfoService_GetInfo_Success(t *testing.T) {
 	assert.Equal(t, "Hoodie", resp.Inventory[1].Type)
 	assert.Equal(t, 1, resp.Inventory[1].Quantity)
 
+	// Одна футболка из двух ещё ждёт выдачи и в Received не попадает.
+	assert.Equal(t, []service.Inventory{
+		{Type: "T-Shirt", Quantity: 1},
+		{Type: "Hoodie", Quantity: 1},
+	}, resp.Received)
+
 	assert.Len(t, resp.CoinHistory.Received, 1)
 	assert.Equal(t, "Alice", resp.CoinHistory.Received[0].FromUser)
 	assert.Equal(t, 50, resp.CoinHistory.Received[0].Amount)
@@ -95,6 +167,28 @@ func TestInfoService_GetInfo_Success(t *testing.T) {
 	assert.Equal(t, "Bob", resp.CoinHistory.Sent[0].ToUser)
 	assert.Equal(t, 30, resp.CoinHistory.Sent[0].Amount)
 
+	assert.Len(t, resp.CoinHistory.Adjustments, 2)
+	assert.Equal(t, "grant", resp.CoinHistory.Adjustments[0].Type)
+	assert.Equal(t, "hackathon winner", resp.CoinHistory.Adjustments[0].Reason)
+	assert.Equal(t, -20, resp.CoinHistory.Adjustments[1].Amount)
+
+	assert.Equal(t, []service.ActivityEntry{
+		{ID: 12, Type: "purchase", Direction: service.HistoryDirectionOutgoing, Amount: 160, Item: "T-Shirt", Quantity: 2},
+		{ID: 11, Type: "transfer", Direction: service.HistoryDirectionIncoming, Amount: 50, Counterparty: "Alice"},
+	}, resp.CoinHistory.Activity)
+
+	// Лента в /api/info использует camelCase, как и остальной ответ.
+	activityJSON, err := json.Marshal(resp.CoinHistory.Activity[0])
+	assert.NoError(t, err)
+	assert.Contains(t, string(activityJSON), `"createdAt":`)
+	assert.NotContains(t, string(activityJSON), `"created_at":`)
+
+	assert.Equal(t, []service.CoinExpiration{{Amount: 300, ExpiresAt: expiresAt}}, resp.UpcomingExpirations)
+
+	assert.Len(t, resp.PendingOrders, 1)
+	assert.Equal(t, int32(7), resp.PendingOrders[0].ID)
+	assert.Equal(t, "packed", resp.PendingOrders[0].Status)
+
 	// Проверяем, что все ожидания выполнились
 	mockRepo.AssertExpectations(t)
 }
@@ -138,6 +232,25 @@ func TestInfoService_GetInfo_ErrorOnInventory(t *testing.T) {
 	mockRepo.AssertExpectations(t)
 }
 
+// TestInfoService_GetInfo_ErrorOnReceivedItems проверяет ошибку на этапе GetReceivedItems.
+func TestInfoService_GetInfo_ErrorOnReceivedItems(t *testing.T) {
+	mockRepo := new(MockInfoRepository)
+	userID := int64(123)
+
+	mockRepo.On("GetCoins", mock.Anything, userID).Return(150, nil).Once()
+	mockRepo.On("GetInventory", mock.Anything, userID).Return([]repository.InventoryItem{}, nil).Once()
+	mockRepo.On("GetReceivedItems", mock.Anything, userID).Return(nil, errors.New("received items error")).Once()
+
+	infoSvc := service.NewInfoService(mockRepo, utils.NewLogger())
+
+	resp, err := infoSvc.GetInfo(context.Background(), userID)
+	assert.Error(t, err)
+	assert.Equal(t, "received items error", err.Error())
+	assert.Equal(t, service.InfoResponse{}, resp)
+
+	mockRepo.AssertExpectations(t)
+}
+
 // TestInfoService_GetInfo_ErrorOnReceivedTransfers проверяет ошибку на этапе GetReceivedTransfers.
 func TestInfoService_GetInfo_ErrorOnReceivedTransfers(t *testing.T) {
 	mockRepo := new(MockInfoRepository)
@@ -148,7 +261,8 @@ func TestInfoService_GetInfo_ErrorOnReceivedTransfers(t *testing.T) {
 		Return([]repository.InventoryItem{
 			{Type: "T-Shirt", Quantity: 2},
 		}, nil).Once()
-	mockRepo.On("GetReceivedTransfers", mock.Anything, userID).
+	mockRepo.On("GetReceivedItems", mock.Anything, userID).Return([]repository.InventoryItem{}, nil).Once()
+	mockRepo.On("GetReceivedTransfers", mock.Anything, userID, int32(20)).
 		Return(nil, errors.New("received error")).Once()
 
 	// Метод GetSentTransfers не вызывается, так как код должен вернуть ошибку после GetReceivedTransfers.
@@ -172,11 +286,12 @@ func TestInfoService_GetInfo_ErrorOnSentTransfers(t *testing.T) {
 		Return([]repository.InventoryItem{
 			{Type: "T-Shirt", Quantity: 2},
 		}, nil).Once()
-	mockRepo.On("GetReceivedTransfers", mock.Anything, userID).
+	mockRepo.On("GetReceivedItems", mock.Anything, userID).Return([]repository.InventoryItem{}, nil).Once()
+	mockRepo.On("GetReceivedTransfers", mock.Anything, userID, int32(20)).
 		Return([]repository.ReceivedTransaction{
 			{FromUser: "Alice", Amount: 50},
 		}, nil).Once()
-	mockRepo.On("GetSentTransfers", mock.Anything, userID).
+	mockRepo.On("GetSentTransfers", mock.Anything, userID, int32(20)).
 		Return(nil, errors.New("sent error")).Once()
 
 	infoSvc := service.NewInfoService(mockRepo, utils.NewLogger())
@@ -188,3 +303,47 @@ func TestInfoService_GetInfo_ErrorOnSentTransfers(t *testing.T) {
 
 	mockRepo.AssertExpectations(t)
 }
+
+// TestInfoService_GetInfo_ErrorOnPendingOrders проверяет ошибку на этапе GetPendingOrders.
+func TestInfoService_GetInfo_ErrorOnPendingOrders(t *testing.T) {
+	mockRepo := new(MockInfoRepository)
+	userID := int64(123)
+
+	mockRepo.On("GetCoins", mock.Anything, userID).Return(150, nil).Once()
+	mockRepo.On("GetInventory", mock.Anything, userID).Return([]repository.InventoryItem{}, nil).Once()
+	mockRepo.On("GetReceivedItems", mock.Anything, userID).Return([]repository.InventoryItem{}, nil).Once()
+	mockRepo.On("GetReceivedTransfers", mock.Anything, userID, int32(20)).Return([]repository.ReceivedTransaction{}, nil).Once()
+	mockRepo.On("GetSentTransfers", mock.Anything, userID, int32(20)).Return([]repository.SentTransaction{}, nil).Once()
+	mockRepo.On("GetCoinAdjustments", mock.Anything, userID, int32(20)).Return([]repository.CoinAdjustment{}, nil).Once()
+	mockRepo.On("GetRecentActivity", mock.Anything, userID, int32(20)).Return([]repository.CoinActivity{}, nil).Once()
+	mockRepo.On("GetUpcomingExpirations", mock.Anything, userID).Return([]repository.CoinExpiration{}, nil).Once()
+	mockRepo.On("GetPendingOrders", mock.Anything, userID).Return(nil, errors.New("orders error")).Once()
+
+	infoSvc := service.NewInfoService(mockRepo, utils.NewLogger())
+	resp, err := infoSvc.GetInfo(context.Background(), userID)
+
+	assert.Error(t, err)
+	assert.Equal(t, service.InfoResponse{}, resp)
+	mockRepo.AssertExpectations(t)
+}
+
+// TestInfoService_GetInfo_ErrorOnRecentActivity проверяет ошибку на этапе GetRecentActivity.
+func TestInfoService_GetInfo_ErrorOnRecentActivity(t *testing.T) {
+	mockRepo := new(MockInfoRepository)
+	userID := int64(123)
+
+	mockRepo.On("GetCoins", mock.Anything, userID).Return(150, nil).Once()
+	mockRepo.On("GetInventory", mock.Anything, userID).Return([]repository.InventoryItem{}, nil).Once()
+	mockRepo.On("GetReceivedItems", mock.Anything, userID).Return([]repository.InventoryItem{}, nil).Once()
+	mockRepo.On("GetReceivedTransfers", mock.Anything, userID, int32(20)).Return([]repository.ReceivedTransaction{}, nil).Once()
+	mockRepo.On("GetSentTransfers", mock.Anything, userID, int32(20)).Return([]repository.SentTransaction{}, nil).Once()
+	mockRepo.On("GetCoinAdjustments", mock.Anything, userID, int32(20)).Return([]repository.CoinAdjustment{}, nil).Once()
+	mockRepo.On("GetRecentActivity", mock.Anything, userID, int32(20)).Return(nil, errors.New("activity error")).Once()
+
+	infoSvc := service.NewInfoService(mockRepo, utils.NewLogger())
+	resp, err := infoSvc.GetInfo(context.Background(), userID)
+
+	assert.Error(t, err)
+	assert.Equal(t, service.InfoResponse{}, resp)
+	mockRepo.AssertExpectations(t)
+}
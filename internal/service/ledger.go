@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/par1ram/merch-store/internal/repository"
+	"github.com/par1ram/merch-store/internal/utils"
+)
+
+// LedgerAccountBalance — остаток системного счёта книги. Остаток счёта
+// выпуска отрицателен и по модулю равен числу монет в обращении.
+type LedgerAccountBalance struct {
+	Code    string `json:"code"`
+	Balance int64  `json:"balance"`
+}
+
+// LedgerMismatch — сотрудник, баланс которого расходится с его счётом в книге.
+type LedgerMismatch struct {
+	Username      string `json:"username"`
+	Balance       int32  `json:"balance"`
+	LedgerBalance int64  `json:"ledger_balance"`
+}
+
+// LedgerReport — результат проверки балансов по книге двойной записи.
+// Consistent истинно, если все проводки сбалансированы и балансы сотрудников
+// совпадают с остатками их счетов.
+type LedgerReport struct {
+	Consistent        bool                   `json:"consistent"`
+	UnbalancedEntries int64                  `json:"unbalanced_entries"`
+	SystemAccounts    []LedgerAccountBalance `json:"system_accounts"`
+	Mismatches        []LedgerMismatch       `json:"mismatches"`
+}
+
+// LedgerService проверяет employees.coins по книге двойной записи,
+// которая является источником истины для балансов.
+type LedgerService interface {
+	Verify(ctx context.Context) (LedgerReport, error)
+}
+
+type ledgerService struct {
+	repo   repository.LedgerRepository
+	logger utils.Logger
+}
+
+func NewLedgerService(repo repository.LedgerRepository, logger utils.Logger) LedgerService {
+	logger.WithFields(utils.LogFields{"component": "ledger_service"}).Info("LedgerService initialized")
+	return &ledgerService{
+		repo:   repo,
+		logger: logger.WithFields(utils.LogFields{"component": "ledger_service"}),
+	}
+}
+
+func (s *ledgerService) Verify(ctx context.Context) (LedgerReport, error) {
+	log := s.logger.WithFields(utils.LogFields{"operation": "verify_ledger"})
+
+	unbalanced, err := s.repo.CountUnbalancedEntries(ctx)
+	if err != nil {
+		return LedgerReport{}, fmt.Errorf("failed to count unbalanced entries: %w", err)
+	}
+
+	accounts, err := s.repo.ListSystemAccountBalances(ctx)
+	if err != nil {
+		return LedgerReport{}, fmt.Errorf("failed to list system accounts: %w", err)
+	}
+
+	mismatches, err := s.repo.ListBalanceMismatches(ctx)
+	if err != nil {
+		return LedgerReport{}, fmt.Errorf("failed to list balance mismatches: %w", err)
+	}
+
+	report := LedgerReport{
+		Consistent:        unbalanced == 0 && len(mismatches) == 0,
+		UnbalancedEntries: unbalanced,
+		SystemAccounts:    make([]LedgerAccountBalance, 0, len(accounts)),
+		Mismatches:        make([]LedgerMismatch, 0, len(mismatches)),
+	}
+	for _, account := range accounts {
+		report.SystemAccounts = append(report.SystemAccounts, LedgerAccountBalance{Code: account.Code, Balance: account.Balance})
+	}
+	for _, row := range mismatches {
+		report.Mismatches = append(report.Mismatches, LedgerMismatch{
+			Username:      row.Username,
+			Balance:       row.Coins,
+			LedgerBalance: row.LedgerBalance,
+		})
+	}
+
+	if !report.Consistent {
+		log.WithFields(utils.LogFields{
+			"unbalanced_entries": unbalanced,
+			"mismatches":         len(mismatches),
+		}).Warn("Ledger verification found inconsistencies")
+	}
+	return report, nil
+}
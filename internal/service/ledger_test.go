@@ -0,0 +1,95 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/par1ram/merch-store/internal/db"
+	"github.com/par1ram/merch-store/internal/service"
+	"github.com/par1ram/merch-store/internal/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockLedgerRepository struct {
+	mock.Mock
+}
+
+func (m *MockLedgerRepository) ListSystemAccountBalances(ctx context.Context) ([]db.ListSystemAccountBalancesRow, error) {
+	args := m.Called(ctx)
+	if res, ok := args.Get(0).([]db.ListSystemAccountBalancesRow); ok {
+		return res, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockLedgerRepository) ListBalanceMismatches(ctx context.Context) ([]db.ListLedgerBalanceMismatchesRow, error) {
+	args := m.Called(ctx)
+	if res, ok := args.Get(0).([]db.ListLedgerBalanceMismatchesRow); ok {
+		return res, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockLedgerRepository) CountUnbalancedEntries(ctx context.Context) (int64, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func TestLedgerVerify_Consistent(t *testing.T) {
+	ctx := context.Background()
+
+	repo := new(MockLedgerRepository)
+	repo.On("CountUnbalancedEntries", ctx).Return(int64(0), nil)
+	repo.On("ListSystemAccountBalances", ctx).Return([]db.ListSystemAccountBalancesRow{
+		{Code: "issuance", Balance: -2000},
+		{Code: "store_revenue", Balance: 300},
+		{Code: "expired", Balance: 0},
+	}, nil)
+	repo.On("ListBalanceMismatches", ctx).Return([]db.ListLedgerBalanceMismatchesRow{}, nil)
+
+	svc := service.NewLedgerService(repo, utils.NewLogger())
+	report, err := svc.Verify(ctx)
+	assert.NoError(t, err)
+	assert.True(t, report.Consistent)
+	assert.Equal(t, []service.LedgerAccountBalance{
+		{Code: "issuance", Balance: -2000},
+		{Code: "store_revenue", Balance: 300},
+		{Code: "expired", Balance: 0},
+	}, report.SystemAccounts)
+	assert.Empty(t, report.Mismatches)
+
+	repo.AssertExpectations(t)
+}
+
+func TestLedgerVerify_Mismatch(t *testing.T) {
+	ctx := context.Background()
+
+	repo := new(MockLedgerRepository)
+	repo.On("CountUnbalancedEntries", ctx).Return(int64(0), nil)
+	repo.On("ListSystemAccountBalances", ctx).Return([]db.ListSystemAccountBalancesRow{}, nil)
+	repo.On("ListBalanceMismatches", ctx).Return([]db.ListLedgerBalanceMismatchesRow{
+		{ID: 4, Username: "dave", Coins: 950, LedgerBalance: 1000},
+	}, nil)
+
+	svc := service.NewLedgerService(repo, utils.NewLogger())
+	report, err := svc.Verify(ctx)
+	assert.NoError(t, err)
+	assert.False(t, report.Consistent)
+	assert.Equal(t, []service.LedgerMismatch{{Username: "dave", Balance: 950, LedgerBalance: 1000}}, report.Mismatches)
+
+	repo.AssertExpectations(t)
+}
+
+func TestLedgerVerify_Error(t *testing.T) {
+	ctx := context.Background()
+	dbErr := errors.New("db error")
+
+	repo := new(MockLedgerRepository)
+	repo.On("CountUnbalancedEntries", ctx).Return(int64(0), dbErr)
+
+	svc := service.NewLedgerService(repo, utils.NewLogger())
+	_, err := svc.Verify(ctx)
+	assert.ErrorIs(t, err, dbErr)
+}
@@ -0,0 +1,349 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/par1ram/merch-store/internal/db"
+	"github.com/par1ram/merch-store/internal/middleware"
+	"github.com/par1ram/merch-store/internal/repository"
+	"github.com/par1ram/merch-store/internal/utils"
+)
+
+// Поля, по которым можно сортировать каталог.
+const (
+	MerchSortByName  = "name"
+	MerchSortByPrice = "price"
+)
+
+// maxMerchNameLength совпадает с размером колонки merch.name.
+const maxMerchNameLength = 255
+
+var (
+	ErrInvalidMerchFilter = newError(CodeInvalidMerchFilter, KindInvalid, "invalid merch filter")
+	ErrInvalidMerchName   = newError(CodeInvalidMerchName, KindInvalid, "invalid merch name")
+	ErrInvalidMerchPrice  = newError(CodeInvalidMerchPrice, KindInvalid, "price must be positive")
+	ErrInvalidMerchStock  = newError(CodeInvalidMerchStock, KindInvalid, "stock must be non-negative")
+	ErrMerchNotFound      = newError(CodeItemNotFound, KindNotFound, "merch not found")
+	ErrMerchExists        = newError(CodeMerchExists, KindConflict, "merch with this name already exists")
+	ErrMerchRetired       = newError(CodeMerchRetired, KindConflict, "merch already retired")
+)
+
+// MerchFilter задаёт сортировку и ценовой диапазон каталога.
+// Нулевые MinPrice/MaxPrice означают отсутствие ограничения.
+type MerchFilter struct {
+	SortBy   string
+	Desc     bool
+	MinPrice int32
+	MaxPrice int32
+}
+
+// MerchItem — товар каталога. Stock отсутствует, если остаток не ограничен.
+type MerchItem struct {
+	ID         int32  `json:"id"`
+	Name       string `json:"name"`
+	Price      int32  `json:"price"`
+	Stock      *int32 `json:"stock,omitempty"`
+	Affordable bool   `json:"affordable"`
+}
+
+type MerchListResponse struct {
+	Items []MerchItem `json:"items"`
+}
+
+// AdminMerchItem — представление товара для администратора, включая снятые с продажи.
+type AdminMerchItem struct {
+	ID        int32      `json:"id"`
+	Name      string     `json:"name"`
+	Price     int32      `json:"price"`
+	Stock     *int32     `json:"stock"`
+	Active    bool       `json:"active"`
+	RetiredAt *time.Time `json:"retired_at,omitempty"`
+}
+
+// MerchUpdate описывает изменения товара; nil-поля остаются без изменений.
+// Остаток меняется только при SetStock, nil Stock делает его неограниченным.
+type MerchUpdate struct {
+	Name     *string
+	Price    *int32
+	Stock    *int32
+	SetStock bool
+}
+
+type MerchService interface {
+	ListMerch(ctx context.Context, filter MerchFilter) (MerchListResponse, error)
+	ListAllMerch(ctx context.Context) ([]AdminMerchItem, error)
+	CreateMerch(ctx context.Context, name string, price int32, stock *int32) (AdminMerchItem, error)
+	UpdateMerch(ctx context.Context, id int32, update MerchUpdate) (AdminMerchItem, error)
+	RetireMerch(ctx context.Context, id int32) (AdminMerchItem, error)
+}
+
+type merchService struct {
+	repo   repository.MerchRepository
+	logger utils.Logger
+}
+
+func NewMerchService(repo repository.MerchRepository, logger utils.Logger) MerchService {
+	logger.WithFields(utils.LogFields{"component": "merch_service"}).Info("MerchService initialized")
+	return &merchService{
+		repo:   repo,
+		logger: logger.WithFields(utils.LogFields{"component": "merch_service"}),
+	}
+}
+
+func (s *merchService) ListMerch(ctx context.Context, filter MerchFilter) (MerchListResponse, error) {
+	log := s.logger.WithFields(utils.LogFields{
+		"operation": "list_merch",
+		"sort_by":   filter.SortBy,
+		"desc":      filter.Desc,
+		"min_price": filter.MinPrice,
+		"max_price": filter.MaxPrice,
+	})
+
+	userID := middleware.GetUserIDFromContext(ctx)
+	if userID == 0 {
+		log.Error("User not authenticated")
+		return MerchListResponse{}, ErrUnauthenticated
+	}
+
+	if filter.SortBy == "" {
+		filter.SortBy = MerchSortByName
+	}
+	if filter.SortBy != MerchSortByName && filter.SortBy != MerchSortByPrice {
+		log.Warn("Unsupported sort field")
+		return MerchListResponse{}, fmt.Errorf("%w: %w", ErrBusinessValidation, ErrInvalidMerchFilter)
+	}
+	if filter.MinPrice < 0 || filter.MaxPrice < 0 || (filter.MaxPrice > 0 && filter.MinPrice > filter.MaxPrice) {
+		log.Warn("Invalid price range")
+		return MerchListResponse{}, fmt.Errorf("%w: %w", ErrBusinessValidation, ErrInvalidMerchFilter)
+	}
+
+	merch, err := s.repo.ListMerch(ctx)
+	if err != nil {
+		log.WithFields(utils.LogFields{"error": err}).Error("Failed to list merch")
+		return MerchListResponse{}, err
+	}
+
+	balance, err := s.repo.GetBalance(ctx, int32(userID))
+	if err != nil {
+		log.WithFields(utils.LogFields{"error": err}).Error("Failed to get balance")
+		return MerchListResponse{}, err
+	}
+
+	items := make([]MerchItem, 0, len(merch))
+	for _, m := range merch {
+		if filter.MinPrice > 0 && m.Price < filter.MinPrice {
+			continue
+		}
+		if filter.MaxPrice > 0 && m.Price > filter.MaxPrice {
+			continue
+		}
+		items = append(items, MerchItem{
+			ID:         m.ID,
+			Name:       m.Name,
+			Price:      m.Price,
+			Stock:      stockPtr(m.Stock),
+			Affordable: balance >= m.Price,
+		})
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		a, b := items[i], items[j]
+		if filter.Desc {
+			a, b = b, a
+		}
+		if filter.SortBy == MerchSortByPrice && a.Price != b.Price {
+			return a.Price < b.Price
+		}
+		return a.Name < b.Name
+	})
+
+	log.WithFields(utils.LogFields{
+		"item_count": len(items),
+		"balance":    balance,
+	}).Info("Merch list retrieved")
+
+	return MerchListResponse{Items: items}, nil
+}
+
+func (s *merchService) ListAllMerch(ctx context.Context) ([]AdminMerchItem, error) {
+	log := s.logger.WithFields(utils.LogFields{"operation": "list_all_merch"})
+
+	merch, err := s.repo.ListAllMerch(ctx)
+	if err != nil {
+		log.WithFields(utils.LogFields{"error": err}).Error("Failed to list merch")
+		return nil, err
+	}
+
+	items := make([]AdminMerchItem, 0, len(merch))
+	for _, m := range merch {
+		items = append(items, toAdminMerchItem(m))
+	}
+
+	log.WithFields(utils.LogFields{"item_count": len(items)}).Debug("Full merch list retrieved")
+	return items, nil
+}
+
+func (s *merchService) CreateMerch(ctx context.Context, name string, price int32, stock *int32) (AdminMerchItem, error) {
+	log := s.logger.WithFields(utils.LogFields{
+		"operation": "create_merch",
+		"name":      name,
+		"price":     price,
+	})
+
+	name, err := validateMerchName(name)
+	if err != nil {
+		log.Warn("Invalid merch name")
+		return AdminMerchItem{}, err
+	}
+	if price <= 0 {
+		log.Warn("Invalid merch price")
+		return AdminMerchItem{}, fmt.Errorf("%w: %w", ErrBusinessValidation, ErrInvalidMerchPrice)
+	}
+	if stock != nil && *stock < 0 {
+		log.Warn("Invalid merch stock")
+		return AdminMerchItem{}, fmt.Errorf("%w: %w", ErrBusinessValidation, ErrInvalidMerchStock)
+	}
+
+	merch, err := s.repo.CreateMerch(ctx, db.CreateMerchParams{Name: name, Price: price, Stock: stockParam(stock)})
+	if err != nil {
+		log.WithFields(utils.LogFields{"error": err}).Error("Failed to create merch")
+		return AdminMerchItem{}, mapMerchError(err)
+	}
+
+	log.WithFields(utils.LogFields{"merch_id": merch.ID}).Info("Merch created")
+	return toAdminMerchItem(merch), nil
+}
+
+func (s *merchService) UpdateMerch(ctx context.Context, id int32, update MerchUpdate) (AdminMerchItem, error) {
+	log := s.logger.WithFields(utils.LogFields{
+		"operation": "update_merch",
+		"merch_id":  id,
+	})
+
+	params := db.UpdateMerchParams{ID: id}
+	if update.Name != nil {
+		name, err := validateMerchName(*update.Name)
+		if err != nil {
+			log.Warn("Invalid merch name")
+			return AdminMerchItem{}, err
+		}
+		params.Name = pgtype.Text{String: name, Valid: true}
+	}
+	if update.Price != nil {
+		if *update.Price <= 0 {
+			log.Warn("Invalid merch price")
+			return AdminMerchItem{}, fmt.Errorf("%w: %w", ErrBusinessValidation, ErrInvalidMerchPrice)
+		}
+		params.Price = pgtype.Int4{Int32: *update.Price, Valid: true}
+	}
+	if update.SetStock {
+		if update.Stock != nil && *update.Stock < 0 {
+			log.Warn("Invalid merch stock")
+			return AdminMerchItem{}, fmt.Errorf("%w: %w", ErrBusinessValidation, ErrInvalidMerchStock)
+		}
+		params.SetStock = true
+		params.Stock = stockParam(update.Stock)
+	}
+
+	merch, err := s.repo.UpdateMerch(ctx, params)
+	if err != nil {
+		log.WithFields(utils.LogFields{"error": err}).Error("Failed to update merch")
+		return AdminMerchItem{}, mapMerchError(err)
+	}
+
+	log.WithFields(utils.LogFields{
+		"name":  merch.Name,
+		"price": merch.Price,
+	}).Info("Merch updated")
+	return toAdminMerchItem(merch), nil
+}
+
+func (s *merchService) RetireMerch(ctx context.Context, id int32) (AdminMerchItem, error) {
+	log := s.logger.WithFields(utils.LogFields{
+		"operation": "retire_merch",
+		"merch_id":  id,
+	})
+
+	current, err := s.repo.GetMerchByID(ctx, id)
+	if err != nil {
+		log.WithFields(utils.LogFields{"error": err}).Error("Failed to get merch")
+		return AdminMerchItem{}, mapMerchError(err)
+	}
+	if !current.Active {
+		log.Warn("Merch already retired")
+		return AdminMerchItem{}, fmt.Errorf("%w: %w", ErrBusinessValidation, ErrMerchRetired)
+	}
+
+	merch, err := s.repo.RetireMerch(ctx, id)
+	if err != nil {
+		log.WithFields(utils.LogFields{"error": err}).Error("Failed to retire merch")
+		// Товар мог быть снят с продажи параллельным запросом.
+		if errors.Is(err, sql.ErrNoRows) {
+			return AdminMerchItem{}, fmt.Errorf("%w: %w", ErrBusinessValidation, ErrMerchRetired)
+		}
+		return AdminMerchItem{}, err
+	}
+
+	log.Info("Merch retired")
+	return toAdminMerchItem(merch), nil
+}
+
+// validateMerchName нормализует название товара. Название используется
+// в пути /api/buy/{item}, поэтому символ "/" в нём запрещён.
+func validateMerchName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || len(name) > maxMerchNameLength || strings.Contains(name, "/") {
+		return "", fmt.Errorf("%w: %w", ErrBusinessValidation, ErrInvalidMerchName)
+	}
+	return name, nil
+}
+
+// mapMerchError переводит ошибки репозитория в бизнес-ошибки каталога.
+func mapMerchError(err error) error {
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return fmt.Errorf("%w: %w", ErrBusinessValidation, ErrMerchNotFound)
+	case errors.Is(err, repository.ErrMerchNameTaken):
+		return fmt.Errorf("%w: %w", ErrBusinessValidation, ErrMerchExists)
+	default:
+		return err
+	}
+}
+
+func toAdminMerchItem(m db.Merch) AdminMerchItem {
+	item := AdminMerchItem{
+		ID:     m.ID,
+		Name:   m.Name,
+		Price:  m.Price,
+		Stock:  stockPtr(m.Stock),
+		Active: m.Active,
+	}
+	if m.RetiredAt.Valid {
+		retiredAt := m.RetiredAt.Time
+		item.RetiredAt = &retiredAt
+	}
+	return item
+}
+
+// stockPtr переводит остаток из БД в представление API: nil — без ограничений.
+func stockPtr(stock pgtype.Int4) *int32 {
+	if !stock.Valid {
+		return nil
+	}
+	v := stock.Int32
+	return &v
+}
+
+func stockParam(stock *int32) pgtype.Int4 {
+	if stock == nil {
+		return pgtype.Int4{}
+	}
+	return pgtype.Int4{Int32: *stock, Valid: true}
+}
@@ -0,0 +1,272 @@
+package service_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/par1ram/merch-store/internal/db"
+	"github.com/par1ram/merch-store/internal/middleware"
+	"github.com/par1ram/merch-store/internal/repository"
+	"github.com/par1ram/merch-store/internal/service"
+	"github.com/par1ram/merch-store/internal/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockMerchRepository struct {
+	mock.Mock
+}
+
+func (m *MockMerchRepository) ListMerch(ctx context.Context) ([]db.Merch, error) {
+	args := m.Called(ctx)
+	if res, ok := args.Get(0).([]db.Merch); ok {
+		return res, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockMerchRepository) ListAllMerch(ctx context.Context) ([]db.Merch, error) {
+	args := m.Called(ctx)
+	if res, ok := args.Get(0).([]db.Merch); ok {
+		return res, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockMerchRepository) GetMerchByID(ctx context.Context, id int32) (db.Merch, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(db.Merch), args.Error(1)
+}
+
+func (m *MockMerchRepository) CreateMerch(ctx context.Context, params db.CreateMerchParams) (db.Merch, error) {
+	args := m.Called(ctx, params)
+	return args.Get(0).(db.Merch), args.Error(1)
+}
+
+func (m *MockMerchRepository) UpdateMerch(ctx context.Context, params db.UpdateMerchParams) (db.Merch, error) {
+	args := m.Called(ctx, params)
+	return args.Get(0).(db.Merch), args.Error(1)
+}
+
+func (m *MockMerchRepository) RetireMerch(ctx context.Context, id int32) (db.Merch, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(db.Merch), args.Error(1)
+}
+
+func (m *MockMerchRepository) GetBalance(ctx context.Context, userID int32) (int32, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(int32), args.Error(1)
+}
+
+func merchStub() []db.Merch {
+	return []db.Merch{
+		{ID: 1, Name: "book", Price: 50},
+		{ID: 2, Name: "cup", Price: 20},
+		{ID: 3, Name: "hoody", Price: 300},
+		{ID: 4, Name: "pen", Price: 10},
+	}
+}
+
+func TestMerchService_ListMerch_DefaultSort(t *testing.T) {
+	claims := jwt.MapClaims{"user_id": 123.0}
+	ctx := context.WithValue(context.Background(), middleware.UserCtxKey, claims)
+
+	repoMock := new(MockMerchRepository)
+	repoMock.On("ListMerch", ctx).Return(merchStub(), nil).Once()
+	repoMock.On("GetBalance", ctx, int32(123)).Return(int32(50), nil).Once()
+
+	svc := service.NewMerchService(repoMock, utils.NewLogger())
+	resp, err := svc.ListMerch(ctx, service.MerchFilter{})
+	assert.NoError(t, err)
+	assert.Equal(t, []service.MerchItem{
+		{ID: 1, Name: "book", Price: 50, Affordable: true},
+		{ID: 2, Name: "cup", Price: 20, Affordable: true},
+		{ID: 3, Name: "hoody", Price: 300, Affordable: false},
+		{ID: 4, Name: "pen", Price: 10, Affordable: true},
+	}, resp.Items)
+
+	repoMock.AssertExpectations(t)
+}
+
+func TestMerchService_ListMerch_PriceDescWithRange(t *testing.T) {
+	claims := jwt.MapClaims{"user_id": 123.0}
+	ctx := context.WithValue(context.Background(), middleware.UserCtxKey, claims)
+
+	repoMock := new(MockMerchRepository)
+	repoMock.On("ListMerch", ctx).Return(merchStub(), nil).Once()
+	repoMock.On("GetBalance", ctx, int32(123)).Return(int32(1000), nil).Once()
+
+	svc := service.NewMerchService(repoMock, utils.NewLogger())
+	resp, err := svc.ListMerch(ctx, service.MerchFilter{
+		SortBy:   service.MerchSortByPrice,
+		Desc:     true,
+		MinPrice: 20,
+		MaxPrice: 100,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []service.MerchItem{
+		{ID: 1, Name: "book", Price: 50, Affordable: true},
+		{ID: 2, Name: "cup", Price: 20, Affordable: true},
+	}, resp.Items)
+
+	repoMock.AssertExpectations(t)
+}
+
+func TestMerchService_ListMerch_InvalidFilter(t *testing.T) {
+	claims := jwt.MapClaims{"user_id": 123.0}
+	ctx := context.WithValue(context.Background(), middleware.UserCtxKey, claims)
+
+	repoMock := new(MockMerchRepository)
+	svc := service.NewMerchService(repoMock, utils.NewLogger())
+
+	_, err := svc.ListMerch(ctx, service.MerchFilter{SortBy: "color"})
+	assert.ErrorIs(t, err, service.ErrBusinessValidation)
+
+	_, err = svc.ListMerch(ctx, service.MerchFilter{MinPrice: 100, MaxPrice: 50})
+	assert.ErrorIs(t, err, service.ErrBusinessValidation)
+
+	repoMock.AssertNotCalled(t, "ListMerch", mock.Anything)
+}
+
+func TestMerchService_ListMerch_RepoError(t *testing.T) {
+	claims := jwt.MapClaims{"user_id": 123.0}
+	ctx := context.WithValue(context.Background(), middleware.UserCtxKey, claims)
+
+	repoMock := new(MockMerchRepository)
+	repoMock.On("ListMerch", ctx).Return(nil, errors.New("db error")).Once()
+
+	svc := service.NewMerchService(repoMock, utils.NewLogger())
+	_, err := svc.ListMerch(ctx, service.MerchFilter{})
+	assert.Error(t, err)
+
+	repoMock.AssertExpectations(t)
+}
+
+func TestMerchService_ListMerch_UserNotAuthenticated(t *testing.T) {
+	repoMock := new(MockMerchRepository)
+	svc := service.NewMerchService(repoMock, utils.NewLogger())
+
+	_, err := svc.ListMerch(context.Background(), service.MerchFilter{})
+	assert.Error(t, err)
+	assert.Equal(t, "user not authenticated", err.Error())
+}
+
+func TestMerchService_CreateMerch_Success(t *testing.T) {
+	repoMock := new(MockMerchRepository)
+	stock := int32(100)
+	repoMock.On("CreateMerch", mock.Anything, db.CreateMerchParams{
+		Name:  "sticker",
+		Price: 5,
+		Stock: pgtype.Int4{Int32: 100, Valid: true},
+	}).Return(db.Merch{ID: 11, Name: "sticker", Price: 5, Active: true, Stock: pgtype.Int4{Int32: 100, Valid: true}}, nil).Once()
+
+	svc := service.NewMerchService(repoMock, utils.NewLogger())
+	item, err := svc.CreateMerch(context.Background(), "  sticker ", 5, &stock)
+	assert.NoError(t, err)
+	assert.Equal(t, service.AdminMerchItem{ID: 11, Name: "sticker", Price: 5, Stock: &stock, Active: true}, item)
+
+	repoMock.AssertExpectations(t)
+}
+
+func TestMerchService_CreateMerch_Validation(t *testing.T) {
+	repoMock := new(MockMerchRepository)
+	svc := service.NewMerchService(repoMock, utils.NewLogger())
+
+	_, err := svc.CreateMerch(context.Background(), "", 5, nil)
+	assert.ErrorIs(t, err, service.ErrInvalidMerchName)
+
+	_, err = svc.CreateMerch(context.Background(), "bad/name", 5, nil)
+	assert.ErrorIs(t, err, service.ErrInvalidMerchName)
+
+	_, err = svc.CreateMerch(context.Background(), "sticker", 0, nil)
+	assert.ErrorIs(t, err, service.ErrInvalidMerchPrice)
+	assert.ErrorIs(t, err, service.ErrBusinessValidation)
+
+	negative := int32(-1)
+	_, err = svc.CreateMerch(context.Background(), "sticker", 5, &negative)
+	assert.ErrorIs(t, err, service.ErrInvalidMerchStock)
+
+	repoMock.AssertNotCalled(t, "CreateMerch", mock.Anything, mock.Anything)
+}
+
+func TestMerchService_CreateMerch_Duplicate(t *testing.T) {
+	repoMock := new(MockMerchRepository)
+	repoMock.On("CreateMerch", mock.Anything, mock.Anything).
+		Return(db.Merch{}, repository.ErrMerchNameTaken).Once()
+
+	svc := service.NewMerchService(repoMock, utils.NewLogger())
+	_, err := svc.CreateMerch(context.Background(), "cup", 20, nil)
+	assert.ErrorIs(t, err, service.ErrMerchExists)
+
+	repoMock.AssertExpectations(t)
+}
+
+func TestMerchService_UpdateMerch_NotFound(t *testing.T) {
+	price := int32(90)
+	repoMock := new(MockMerchRepository)
+	repoMock.On("UpdateMerch", mock.Anything, db.UpdateMerchParams{
+		Price: pgtype.Int4{Int32: 90, Valid: true},
+		ID:    42,
+	}).Return(db.Merch{}, sql.ErrNoRows).Once()
+
+	svc := service.NewMerchService(repoMock, utils.NewLogger())
+	_, err := svc.UpdateMerch(context.Background(), 42, service.MerchUpdate{Price: &price})
+	assert.ErrorIs(t, err, service.ErrMerchNotFound)
+
+	repoMock.AssertExpectations(t)
+}
+
+func TestMerchService_UpdateMerch_ClearStock(t *testing.T) {
+	repoMock := new(MockMerchRepository)
+	repoMock.On("UpdateMerch", mock.Anything, db.UpdateMerchParams{
+		SetStock: true,
+		ID:       6,
+	}).Return(db.Merch{ID: 6, Name: "pink-hoody", Price: 500, Active: true}, nil).Once()
+
+	svc := service.NewMerchService(repoMock, utils.NewLogger())
+	item, err := svc.UpdateMerch(context.Background(), 6, service.MerchUpdate{SetStock: true})
+	assert.NoError(t, err)
+	assert.Nil(t, item.Stock)
+
+	repoMock.AssertExpectations(t)
+}
+
+func TestMerchService_RetireMerch_Success(t *testing.T) {
+	retiredAt := time.Now()
+	repoMock := new(MockMerchRepository)
+	repoMock.On("GetMerchByID", mock.Anything, int32(10)).
+		Return(db.Merch{ID: 10, Name: "pink-hoody", Price: 500, Active: true}, nil).Once()
+	repoMock.On("RetireMerch", mock.Anything, int32(10)).
+		Return(db.Merch{
+			ID:        10,
+			Name:      "pink-hoody",
+			Price:     500,
+			RetiredAt: pgtype.Timestamptz{Time: retiredAt, Valid: true},
+		}, nil).Once()
+
+	svc := service.NewMerchService(repoMock, utils.NewLogger())
+	item, err := svc.RetireMerch(context.Background(), 10)
+	assert.NoError(t, err)
+	assert.False(t, item.Active)
+	assert.Equal(t, &retiredAt, item.RetiredAt)
+
+	repoMock.AssertExpectations(t)
+}
+
+func TestMerchService_RetireMerch_AlreadyRetired(t *testing.T) {
+	repoMock := new(MockMerchRepository)
+	repoMock.On("GetMerchByID", mock.Anything, int32(10)).
+		Return(db.Merch{ID: 10, Name: "pink-hoody", Price: 500, Active: false}, nil).Once()
+
+	svc := service.NewMerchService(repoMock, utils.NewLogger())
+	_, err := svc.RetireMerch(context.Background(), 10)
+	assert.ErrorIs(t, err, service.ErrMerchRetired)
+
+	repoMock.AssertExpectations(t)
+	repoMock.AssertNotCalled(t, "RetireMerch", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,251 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/par1ram/merch-store/internal/db"
+	"github.com/par1ram/merch-store/internal/middleware"
+	"github.com/par1ram/merch-store/internal/repository"
+	"github.com/par1ram/merch-store/internal/utils"
+)
+
+// Статусы заказа в порядке жизненного цикла. В cancelled заказ переходит
+// только при полном возврате покупки: отмена без возврата оставила бы
+// покупателя без монет и с товаром в инвентаре.
+const (
+	OrderStatusPlaced         = "placed"
+	OrderStatusPacked         = "packed"
+	OrderStatusReadyForPickup = "ready_for_pickup"
+	OrderStatusDelivered      = "delivered"
+	OrderStatusCancelled      = "cancelled"
+)
+
+// orderStatusStep — позиция статуса в жизненном цикле заказа.
+var orderStatusStep = map[string]int{
+	OrderStatusPlaced:         0,
+	OrderStatusPacked:         1,
+	OrderStatusReadyForPickup: 2,
+	OrderStatusDelivered:      3,
+}
+
+var (
+	ErrOrderNotFound          = newError(CodeOrderNotFound, KindNotFound, "order not found")
+	ErrInvalidOrderStatus     = newError(CodeInvalidOrderStatus, KindInvalid, "invalid order status")
+	ErrInvalidOrderTransition = newError(CodeInvalidOrderTransition, KindConflict, "invalid order status transition")
+	ErrOrderCancelViaRefund   = newError(CodeOrderCancelViaRefund, KindConflict, "orders are cancelled by refunding the purchase via /api/admin/refunds")
+)
+
+// Order — заказ на выдачу купленного товара.
+type Order struct {
+	ID         int32     `json:"id"`
+	PurchaseID int32     `json:"purchase_id"`
+	Username   string    `json:"username,omitempty"`
+	Item       string    `json:"item"`
+	Quantity   int32     `json:"quantity"`
+	Status     string    `json:"status"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// OrderService позволяет сотрудникам отслеживать свои заказы, а
+// администраторам — продвигать заказы по жизненному циклу.
+type OrderService interface {
+	ListMyOrders(ctx context.Context, status string) ([]Order, error)
+	GetMyOrder(ctx context.Context, orderID int32) (Order, error)
+	ListOrders(ctx context.Context, status string) ([]Order, error)
+	UpdateStatus(ctx context.Context, orderID int32, status string) (Order, error)
+}
+
+type orderService struct {
+	repo   repository.OrderRepository
+	logger utils.Logger
+}
+
+func NewOrderService(repo repository.OrderRepository, logger utils.Logger) OrderService {
+	logger.WithFields(utils.LogFields{"component": "order_service"}).Info("OrderService initialized")
+	return &orderService{
+		repo:   repo,
+		logger: logger.WithFields(utils.LogFields{"component": "order_service"}),
+	}
+}
+
+func (s *orderService) ListMyOrders(ctx context.Context, status string) ([]Order, error) {
+	userID := middleware.GetUserIDFromContext(ctx)
+	if userID == 0 {
+		s.logger.Error("User not authenticated")
+		return nil, ErrUnauthenticated
+	}
+
+	filter, err := orderStatusFilter(status)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.repo.ListEmployeeOrders(ctx, db.ListOrdersByEmployeeParams{
+		EmployeeID: int32(userID),
+		Status:     filter,
+	})
+	if err != nil {
+		s.logger.WithFields(utils.LogFields{"error": err, "user_id": userID}).Error("Failed to list orders")
+		return nil, err
+	}
+
+	orders := make([]Order, 0, len(rows))
+	for _, row := range rows {
+		orders = append(orders, Order{
+			ID:         row.ID,
+			PurchaseID: row.PurchaseID,
+			Item:       row.MerchName,
+			Quantity:   row.Quantity,
+			Status:     string(row.Status),
+			CreatedAt:  row.CreatedAt.Time,
+			UpdatedAt:  row.UpdatedAt.Time,
+		})
+	}
+	return orders, nil
+}
+
+func (s *orderService) GetMyOrder(ctx context.Context, orderID int32) (Order, error) {
+	userID := middleware.GetUserIDFromContext(ctx)
+	if userID == 0 {
+		s.logger.Error("User not authenticated")
+		return Order{}, ErrUnauthenticated
+	}
+
+	// Чужой заказ неотличим от несуществующего.
+	row, err := s.repo.GetEmployeeOrder(ctx, db.GetOrderByEmployeeParams{
+		ID:         orderID,
+		EmployeeID: int32(userID),
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return Order{}, fmt.Errorf("%w: %w", ErrBusinessValidation, ErrOrderNotFound)
+		}
+		s.logger.WithFields(utils.LogFields{"error": err, "order_id": orderID}).Error("Failed to get order")
+		return Order{}, err
+	}
+
+	return Order{
+		ID:         row.ID,
+		PurchaseID: row.PurchaseID,
+		Item:       row.MerchName,
+		Quantity:   row.Quantity,
+		Status:     string(row.Status),
+		CreatedAt:  row.CreatedAt.Time,
+		UpdatedAt:  row.UpdatedAt.Time,
+	}, nil
+}
+
+func (s *orderService) ListOrders(ctx context.Context, status string) ([]Order, error) {
+	filter, err := orderStatusFilter(status)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.repo.ListOrders(ctx, filter)
+	if err != nil {
+		s.logger.WithFields(utils.LogFields{"error": err}).Error("Failed to list orders")
+		return nil, err
+	}
+
+	orders := make([]Order, 0, len(rows))
+	for _, row := range rows {
+		orders = append(orders, Order{
+			ID:         row.ID,
+			PurchaseID: row.PurchaseID,
+			Username:   row.Username,
+			Item:       row.MerchName,
+			Quantity:   row.Quantity,
+			Status:     string(row.Status),
+			CreatedAt:  row.CreatedAt.Time,
+			UpdatedAt:  row.UpdatedAt.Time,
+		})
+	}
+	return orders, nil
+}
+
+// UpdateStatus продвигает заказ вперёд по жизненному циклу; пропуск
+// промежуточных статусов допускается (например, выдача сразу после оформления).
+// Выданный или отменённый заказ изменить нельзя. Отменить заказ здесь нельзя:
+// возврат покупки через /api/admin/refunds возвращает монеты, забирает товар
+// из инвентаря и отменяет заказ в одной транзакции.
+func (s *orderService) UpdateStatus(ctx context.Context, orderID int32, status string) (Order, error) {
+	log := s.logger.WithFields(utils.LogFields{
+		"operation": "update_order_status",
+		"order_id":  orderID,
+		"status":    status,
+		"admin_id":  middleware.GetUserIDFromContext(ctx),
+	})
+
+	if status == OrderStatusCancelled {
+		log.Warn("Order cancellation requested without refund")
+		return Order{}, fmt.Errorf("%w: %w", ErrBusinessValidation, ErrOrderCancelViaRefund)
+	}
+	if _, ok := orderStatusStep[status]; !ok {
+		return Order{}, fmt.Errorf("%w: %w", ErrBusinessValidation, ErrInvalidOrderStatus)
+	}
+
+	var order Order
+	err := s.repo.ExecTx(ctx, func(r repository.OrderRepository) error {
+		current, err := r.GetOrderStatusForUpdate(ctx, orderID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return fmt.Errorf("%w: %w", ErrBusinessValidation, ErrOrderNotFound)
+			}
+			return err
+		}
+		if !canTransitionOrder(string(current), status) {
+			return fmt.Errorf("%w: %w: %s -> %s", ErrBusinessValidation, ErrInvalidOrderTransition, current, status)
+		}
+
+		row, err := r.UpdateOrderStatus(ctx, db.UpdateOrderStatusParams{
+			ID:     orderID,
+			Status: db.OrderStatusEnum(status),
+		})
+		if err != nil {
+			return err
+		}
+
+		order = Order{
+			ID:         row.ID,
+			PurchaseID: row.PurchaseID,
+			Username:   row.Username,
+			Item:       row.MerchName,
+			Quantity:   row.Quantity,
+			Status:     string(row.Status),
+			CreatedAt:  row.CreatedAt.Time,
+			UpdatedAt:  row.UpdatedAt.Time,
+		}
+		return nil
+	})
+	if err != nil {
+		log.WithFields(utils.LogFields{"error": err}).Error("Failed to update order status")
+		return Order{}, err
+	}
+
+	log.Info("Order status updated")
+	return order, nil
+}
+
+// canTransitionOrder проверяет допустимость перехода между статусами заказа.
+func canTransitionOrder(from, to string) bool {
+	if from == OrderStatusDelivered || from == OrderStatusCancelled {
+		return false
+	}
+	return orderStatusStep[to] > orderStatusStep[from]
+}
+
+// orderStatusFilter преобразует необязательный статус из запроса в фильтр запроса к БД.
+func orderStatusFilter(status string) (db.NullOrderStatusEnum, error) {
+	if status == "" {
+		return db.NullOrderStatusEnum{}, nil
+	}
+	if _, ok := orderStatusStep[status]; !ok && status != OrderStatusCancelled {
+		return db.NullOrderStatusEnum{}, fmt.Errorf("%w: %w", ErrBusinessValidation, ErrInvalidOrderStatus)
+	}
+	return db.NullOrderStatusEnum{OrderStatusEnum: db.OrderStatusEnum(status), Valid: true}, nil
+}
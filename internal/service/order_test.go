@@ -0,0 +1,166 @@
+package service_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/par1ram/merch-store/internal/db"
+	"github.com/par1ram/merch-store/internal/repository"
+	"github.com/par1ram/merch-store/internal/service"
+	"github.com/par1ram/merch-store/internal/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockOrderRepository struct {
+	mock.Mock
+}
+
+func (m *MockOrderRepository) ExecTx(ctx context.Context, fn func(repository.OrderRepository) error) error {
+	args := m.Called(ctx, fn)
+	if err := args.Error(0); err != nil {
+		return err
+	}
+	return fn(m)
+}
+
+func (m *MockOrderRepository) ListEmployeeOrders(ctx context.Context, params db.ListOrdersByEmployeeParams) ([]db.ListOrdersByEmployeeRow, error) {
+	args := m.Called(ctx, params)
+	if res, ok := args.Get(0).([]db.ListOrdersByEmployeeRow); ok {
+		return res, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockOrderRepository) GetEmployeeOrder(ctx context.Context, params db.GetOrderByEmployeeParams) (db.GetOrderByEmployeeRow, error) {
+	args := m.Called(ctx, params)
+	return args.Get(0).(db.GetOrderByEmployeeRow), args.Error(1)
+}
+
+func (m *MockOrderRepository) ListOrders(ctx context.Context, status db.NullOrderStatusEnum) ([]db.ListOrdersRow, error) {
+	args := m.Called(ctx, status)
+	if res, ok := args.Get(0).([]db.ListOrdersRow); ok {
+		return res, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockOrderRepository) GetOrderStatusForUpdate(ctx context.Context, orderID int32) (db.OrderStatusEnum, error) {
+	args := m.Called(ctx, orderID)
+	return args.Get(0).(db.OrderStatusEnum), args.Error(1)
+}
+
+func (m *MockOrderRepository) UpdateOrderStatus(ctx context.Context, params db.UpdateOrderStatusParams) (db.UpdateOrderStatusRow, error) {
+	args := m.Called(ctx, params)
+	return args.Get(0).(db.UpdateOrderStatusRow), args.Error(1)
+}
+
+func TestOrderService_UpdateStatus_Transitions(t *testing.T) {
+	cases := []struct {
+		from    db.OrderStatusEnum
+		to      string
+		allowed bool
+	}{
+		{db.OrderStatusEnumPlaced, service.OrderStatusPacked, true},
+		{db.OrderStatusEnumPlaced, service.OrderStatusDelivered, true},
+		{db.OrderStatusEnumReadyForPickup, service.OrderStatusDelivered, true},
+		{db.OrderStatusEnumPacked, service.OrderStatusPlaced, false},
+		{db.OrderStatusEnumPacked, service.OrderStatusPacked, false},
+		{db.OrderStatusEnumDelivered, service.OrderStatusPacked, false},
+		{db.OrderStatusEnumCancelled, service.OrderStatusPacked, false},
+	}
+
+	for _, tc := range cases {
+		ctx := userCtx(1)
+		repoMock := new(MockOrderRepository)
+		repoMock.On("ExecTx", ctx, mock.AnythingOfType("func(repository.OrderRepository) error")).Return(nil).Once()
+		repoMock.On("GetOrderStatusForUpdate", mock.Anything, int32(4)).Return(tc.from, nil).Once()
+		if tc.allowed {
+			repoMock.On("UpdateOrderStatus", mock.Anything, db.UpdateOrderStatusParams{
+				Status: db.OrderStatusEnum(tc.to),
+				ID:     4,
+			}).Return(db.UpdateOrderStatusRow{ID: 4, Username: "alice", MerchName: "cup", Quantity: 1, Status: db.OrderStatusEnum(tc.to)}, nil).Once()
+		}
+
+		svc := service.NewOrderService(repoMock, utils.NewLogger())
+		order, err := svc.UpdateStatus(ctx, 4, tc.to)
+
+		if tc.allowed {
+			assert.NoError(t, err, "%s -> %s", tc.from, tc.to)
+			assert.Equal(t, tc.to, order.Status)
+		} else {
+			assert.ErrorIs(t, err, service.ErrInvalidOrderTransition, "%s -> %s", tc.from, tc.to)
+			repoMock.AssertNotCalled(t, "UpdateOrderStatus", mock.Anything, mock.Anything)
+		}
+		repoMock.AssertExpectations(t)
+	}
+}
+
+func TestOrderService_UpdateStatus_InvalidStatus(t *testing.T) {
+	repoMock := new(MockOrderRepository)
+	svc := service.NewOrderService(repoMock, utils.NewLogger())
+
+	_, err := svc.UpdateStatus(userCtx(1), 4, "shipped")
+	assert.ErrorIs(t, err, service.ErrInvalidOrderStatus)
+	assert.ErrorIs(t, err, service.ErrBusinessValidation)
+
+	repoMock.AssertNotCalled(t, "ExecTx", mock.Anything, mock.Anything)
+}
+
+func TestOrderService_UpdateStatus_CancelRequiresRefund(t *testing.T) {
+	repoMock := new(MockOrderRepository)
+	svc := service.NewOrderService(repoMock, utils.NewLogger())
+
+	_, err := svc.UpdateStatus(userCtx(1), 4, service.OrderStatusCancelled)
+	assert.ErrorIs(t, err, service.ErrOrderCancelViaRefund)
+	assert.ErrorIs(t, err, service.ErrBusinessValidation)
+
+	repoMock.AssertNotCalled(t, "ExecTx", mock.Anything, mock.Anything)
+}
+
+func TestOrderService_UpdateStatus_NotFound(t *testing.T) {
+	ctx := userCtx(1)
+	repoMock := new(MockOrderRepository)
+	repoMock.On("ExecTx", ctx, mock.Anything).Return(nil).Once()
+	repoMock.On("GetOrderStatusForUpdate", mock.Anything, int32(99)).Return(db.OrderStatusEnum(""), sql.ErrNoRows).Once()
+
+	svc := service.NewOrderService(repoMock, utils.NewLogger())
+	_, err := svc.UpdateStatus(ctx, 99, service.OrderStatusPacked)
+	assert.ErrorIs(t, err, service.ErrOrderNotFound)
+
+	repoMock.AssertExpectations(t)
+}
+
+func TestOrderService_ListMyOrders_Success(t *testing.T) {
+	ctx := userCtx(123)
+	repoMock := new(MockOrderRepository)
+	repoMock.On("ListEmployeeOrders", ctx, db.ListOrdersByEmployeeParams{
+		EmployeeID: 123,
+		Status:     db.NullOrderStatusEnum{OrderStatusEnum: db.OrderStatusEnumPlaced, Valid: true},
+	}).Return([]db.ListOrdersByEmployeeRow{
+		{ID: 2, PurchaseID: 10, MerchName: "cup", Quantity: 1, Status: db.OrderStatusEnumPlaced},
+	}, nil).Once()
+
+	svc := service.NewOrderService(repoMock, utils.NewLogger())
+	orders, err := svc.ListMyOrders(ctx, service.OrderStatusPlaced)
+	assert.NoError(t, err)
+	assert.Equal(t, []service.Order{
+		{ID: 2, PurchaseID: 10, Item: "cup", Quantity: 1, Status: service.OrderStatusPlaced},
+	}, orders)
+
+	repoMock.AssertExpectations(t)
+}
+
+func TestOrderService_GetMyOrder_NotFound(t *testing.T) {
+	ctx := userCtx(123)
+	repoMock := new(MockOrderRepository)
+	repoMock.On("GetEmployeeOrder", ctx, db.GetOrderByEmployeeParams{ID: 5, EmployeeID: 123}).
+		Return(db.GetOrderByEmployeeRow{}, sql.ErrNoRows).Once()
+
+	svc := service.NewOrderService(repoMock, utils.NewLogger())
+	_, err := svc.GetMyOrder(ctx, 5)
+	assert.ErrorIs(t, err, service.ErrOrderNotFound)
+
+	repoMock.AssertExpectations(t)
+}
@@ -0,0 +1,269 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/par1ram/merch-store/internal/db"
+	"github.com/par1ram/merch-store/internal/middleware"
+	"github.com/par1ram/merch-store/internal/repository"
+	"github.com/par1ram/merch-store/internal/utils"
+)
+
+// Статусы удержанных переводов.
+const (
+	PendingTransferStatusPending  = "pending"
+	PendingTransferStatusAccepted = "accepted"
+	PendingTransferStatusDeclined = "declined"
+	PendingTransferStatusExpired  = "expired"
+)
+
+// defaultHoldRefundBatchSize — сколько просроченных удержаний возвращается за одну транзакцию.
+const defaultHoldRefundBatchSize = 100
+
+var (
+	ErrPendingTransferNotFound      = newError(CodePendingTransferNotFound, KindNotFound, "pending transfer not found")
+	ErrPendingTransferResolved      = newError(CodePendingTransferResolved, KindConflict, "pending transfer already resolved")
+	ErrPendingTransferExpired       = newError(CodePendingTransferExpired, KindConflict, "pending transfer expired")
+	ErrInvalidPendingTransferStatus = newError(CodeInvalidPendingTransferStatus, KindInvalid, "invalid pending transfer status")
+)
+
+// PendingTransfer — перевод, удержанный до решения получателя. Sender не
+// заполняется в ответе на создание перевода: отправитель — текущий пользователь.
+type PendingTransfer struct {
+	ID         int32      `json:"id"`
+	Sender     string     `json:"sender,omitempty"`
+	Recipient  string     `json:"recipient"`
+	Amount     int32      `json:"amount"`
+	Message    string     `json:"message,omitempty"`
+	Hashtags   []string   `json:"hashtags,omitempty"`
+	Status     string     `json:"status"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	ResolvedAt *time.Time `json:"resolved_at,omitempty"`
+}
+
+// PendingTransferService обслуживает удержанные переводы: получатель принимает
+// перевод — монеты зачисляются ему — или отклоняет, и монеты возвращаются
+// отправителю. Удержания без решения возвращаются отправителю по истечении срока.
+// Каждое изменение состояния записывается в историю операций.
+type PendingTransferService interface {
+	ListIncoming(ctx context.Context, status string) ([]PendingTransfer, error)
+	ListOutgoing(ctx context.Context, status string) ([]PendingTransfer, error)
+	AcceptTransfer(ctx context.Context, holdID int32) (PendingTransfer, error)
+	DeclineTransfer(ctx context.Context, holdID int32) (PendingTransfer, error)
+	RefundExpired(ctx context.Context) (int, error)
+}
+
+type pendingTransferService struct {
+	repo   repository.PendingTransferRepository
+	logger utils.Logger
+}
+
+func NewPendingTransferService(repo repository.PendingTransferRepository, logger utils.Logger) PendingTransferService {
+	logger.WithFields(utils.LogFields{"component": "pending_transfer_service"}).Info("PendingTransferService initialized")
+	return &pendingTransferService{
+		repo:   repo,
+		logger: logger.WithFields(utils.LogFields{"component": "pending_transfer_service"}),
+	}
+}
+
+// ListIncoming возвращает удержанные переводы, адресованные текущему пользователю.
+func (s *pendingTransferService) ListIncoming(ctx context.Context, status string) ([]PendingTransfer, error) {
+	return s.list(ctx, status, true)
+}
+
+// ListOutgoing возвращает удержанные переводы текущего пользователя.
+func (s *pendingTransferService) ListOutgoing(ctx context.Context, status string) ([]PendingTransfer, error) {
+	return s.list(ctx, status, false)
+}
+
+func (s *pendingTransferService) list(ctx context.Context, status string, incoming bool) ([]PendingTransfer, error) {
+	userID := middleware.GetUserIDFromContext(ctx)
+	if userID == 0 {
+		s.logger.Error("User not authenticated")
+		return nil, ErrUnauthenticated
+	}
+
+	params := db.ListTransferHoldsParams{}
+	if incoming {
+		params.RecipientID = pgtype.Int4{Int32: int32(userID), Valid: true}
+	} else {
+		params.SenderID = pgtype.Int4{Int32: int32(userID), Valid: true}
+	}
+	if status != "" {
+		switch status {
+		case PendingTransferStatusPending, PendingTransferStatusAccepted, PendingTransferStatusDeclined, PendingTransferStatusExpired:
+		default:
+			return nil, fmt.Errorf("%w: %w", ErrBusinessValidation, ErrInvalidPendingTransferStatus)
+		}
+		params.Status = db.NullTransferHoldStatusEnum{TransferHoldStatusEnum: db.TransferHoldStatusEnum(status), Valid: true}
+	}
+
+	rows, err := s.repo.ListTransferHolds(ctx, params)
+	if err != nil {
+		s.logger.WithFields(utils.LogFields{"error": err}).Error("Failed to list pending transfers")
+		return nil, err
+	}
+
+	transfers := make([]PendingTransfer, 0, len(rows))
+	for _, row := range rows {
+		transfers = append(transfers, toPendingTransferRow(row))
+	}
+	return transfers, nil
+}
+
+// AcceptTransfer зачисляет удержанные монеты текущему пользователю — получателю перевода.
+func (s *pendingTransferService) AcceptTransfer(ctx context.Context, holdID int32) (PendingTransfer, error) {
+	return s.resolve(ctx, holdID, db.TransferHoldStatusEnumAccepted)
+}
+
+// DeclineTransfer возвращает удержанные монеты отправителю.
+func (s *pendingTransferService) DeclineTransfer(ctx context.Context, holdID int32) (PendingTransfer, error) {
+	return s.resolve(ctx, holdID, db.TransferHoldStatusEnumDeclined)
+}
+
+func (s *pendingTransferService) resolve(ctx context.Context, holdID int32, status db.TransferHoldStatusEnum) (PendingTransfer, error) {
+	userID := middleware.GetUserIDFromContext(ctx)
+	log := s.logger.WithFields(utils.LogFields{
+		"operation": "resolve_pending_transfer",
+		"hold_id":   holdID,
+		"status":    status,
+		"user_id":   userID,
+	})
+	if userID == 0 {
+		log.Error("User not authenticated")
+		return PendingTransfer{}, ErrUnauthenticated
+	}
+
+	var result PendingTransfer
+	err := s.repo.ExecTx(ctx, func(r repository.PendingTransferRepository) error {
+		hold, err := s.lockPendingHold(ctx, r, holdID, int32(userID))
+		if err != nil {
+			return err
+		}
+		if err := r.SettleHold(ctx, hold, status); err != nil {
+			return err
+		}
+
+		result, err = s.getTransfer(ctx, r, holdID)
+		return err
+	})
+	if err != nil {
+		log.WithFields(utils.LogFields{"error": err}).Error("Failed to resolve pending transfer")
+		return PendingTransfer{}, err
+	}
+
+	log.WithFields(utils.LogFields{"amount": result.Amount, "sender": result.Sender}).Info("Pending transfer resolved")
+	return result, nil
+}
+
+// RefundExpired возвращает отправителям монеты удержаний с истёкшим сроком.
+// Каждая порция обрабатывается в своей транзакции; заблокированные другой
+// репликой удержания пропускаются и будут возвращены ею.
+func (s *pendingTransferService) RefundExpired(ctx context.Context) (int, error) {
+	refunded := 0
+	for {
+		var batch int
+		err := s.repo.ExecTx(ctx, func(r repository.PendingTransferRepository) error {
+			holds, err := r.ListExpiredTransferHolds(ctx, defaultHoldRefundBatchSize)
+			if err != nil {
+				return err
+			}
+			for _, hold := range holds {
+				if err := r.SettleHold(ctx, hold, db.TransferHoldStatusEnumExpired); err != nil {
+					return err
+				}
+			}
+			batch = len(holds)
+			return nil
+		})
+		if err != nil {
+			s.logger.WithFields(utils.LogFields{"error": err, "refunded": refunded}).Error("Failed to refund expired holds")
+			return refunded, err
+		}
+		refunded += batch
+		if batch < defaultHoldRefundBatchSize {
+			break
+		}
+		if err := ctx.Err(); err != nil {
+			return refunded, err
+		}
+	}
+
+	if refunded > 0 {
+		s.logger.WithFields(utils.LogFields{"refunded": refunded}).Info("Expired holds refunded")
+	}
+	return refunded, nil
+}
+
+// lockPendingHold блокирует удержание и проверяет, что оно адресовано
+// recipientID и ещё ожидает решения. Чужое удержание неотличимо от несуществующего.
+func (s *pendingTransferService) lockPendingHold(ctx context.Context, r repository.PendingTransferRepository, holdID, recipientID int32) (db.TransferHold, error) {
+	hold, err := r.GetTransferHoldForUpdate(ctx, holdID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return hold, fmt.Errorf("%w: %w", ErrBusinessValidation, ErrPendingTransferNotFound)
+		}
+		return hold, err
+	}
+	if hold.RecipientID != recipientID {
+		return hold, fmt.Errorf("%w: %w", ErrBusinessValidation, ErrPendingTransferNotFound)
+	}
+	if hold.Status == db.TransferHoldStatusEnumExpired ||
+		(hold.Status == db.TransferHoldStatusEnumPending && !hold.ExpiresAt.Time.After(time.Now())) {
+		return hold, fmt.Errorf("%w: %w", ErrBusinessValidation, ErrPendingTransferExpired)
+	}
+	if hold.Status != db.TransferHoldStatusEnumPending {
+		return hold, fmt.Errorf("%w: %w", ErrBusinessValidation, ErrPendingTransferResolved)
+	}
+	return hold, nil
+}
+
+func (s *pendingTransferService) getTransfer(ctx context.Context, r repository.PendingTransferRepository, holdID int32) (PendingTransfer, error) {
+	rows, err := r.ListTransferHolds(ctx, db.ListTransferHoldsParams{ID: pgtype.Int4{Int32: holdID, Valid: true}})
+	if err != nil {
+		return PendingTransfer{}, fmt.Errorf("failed to get pending transfer: %w", err)
+	}
+	if len(rows) == 0 {
+		return PendingTransfer{}, fmt.Errorf("%w: %w", ErrBusinessValidation, ErrPendingTransferNotFound)
+	}
+	return toPendingTransferRow(rows[0]), nil
+}
+
+// toPendingTransfer описывает только что созданное удержание.
+func toPendingTransfer(hold db.TransferHold, recipient string) PendingTransfer {
+	return PendingTransfer{
+		ID:        hold.ID,
+		Recipient: recipient,
+		Amount:    hold.Amount,
+		Message:   hold.Message.String,
+		Hashtags:  hold.Hashtags,
+		Status:    string(hold.Status),
+		CreatedAt: hold.CreatedAt.Time,
+		ExpiresAt: hold.ExpiresAt.Time,
+	}
+}
+
+func toPendingTransferRow(row db.ListTransferHoldsRow) PendingTransfer {
+	transfer := PendingTransfer{
+		ID:        row.ID,
+		Sender:    row.Sender,
+		Recipient: row.Recipient,
+		Amount:    row.Amount,
+		Message:   row.Message,
+		Hashtags:  row.Hashtags,
+		Status:    string(row.Status),
+		CreatedAt: row.CreatedAt.Time,
+		ExpiresAt: row.ExpiresAt.Time,
+	}
+	if row.ResolvedAt.Valid {
+		resolvedAt := row.ResolvedAt.Time
+		transfer.ResolvedAt = &resolvedAt
+	}
+	return transfer
+}
@@ -0,0 +1,241 @@
+package service_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/par1ram/merch-store/internal/db"
+	"github.com/par1ram/merch-store/internal/repository"
+	"github.com/par1ram/merch-store/internal/service"
+	"github.com/par1ram/merch-store/internal/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockPendingTransferRepository struct {
+	mock.Mock
+}
+
+func (m *MockPendingTransferRepository) ExecTx(ctx context.Context, fn func(repository.PendingTransferRepository) error) error {
+	args := m.Called(ctx, fn)
+	if err := args.Error(0); err != nil {
+		return err
+	}
+	return fn(m)
+}
+
+func (m *MockPendingTransferRepository) GetTransferHoldForUpdate(ctx context.Context, id int32) (db.TransferHold, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(db.TransferHold), args.Error(1)
+}
+
+func (m *MockPendingTransferRepository) ListExpiredTransferHolds(ctx context.Context, batchSize int32) ([]db.TransferHold, error) {
+	args := m.Called(ctx, batchSize)
+	return args.Get(0).([]db.TransferHold), args.Error(1)
+}
+
+func (m *MockPendingTransferRepository) ListTransferHolds(ctx context.Context, params db.ListTransferHoldsParams) ([]db.ListTransferHoldsRow, error) {
+	args := m.Called(ctx, params)
+	return args.Get(0).([]db.ListTransferHoldsRow), args.Error(1)
+}
+
+func (m *MockPendingTransferRepository) SettleHold(ctx context.Context, hold db.TransferHold, status db.TransferHoldStatusEnum) error {
+	args := m.Called(ctx, hold, status)
+	return args.Error(0)
+}
+
+func pendingHold(id, senderID, recipientID, amount int32) db.TransferHold {
+	return db.TransferHold{
+		ID:          id,
+		SenderID:    senderID,
+		RecipientID: recipientID,
+		Amount:      amount,
+		Status:      db.TransferHoldStatusEnumPending,
+		ExpiresAt:   pgtype.Timestamptz{Time: time.Now().Add(time.Hour), Valid: true},
+	}
+}
+
+func byHoldID(id int32) db.ListTransferHoldsParams {
+	return db.ListTransferHoldsParams{ID: pgtype.Int4{Int32: id, Valid: true}}
+}
+
+func TestSendCoinService_SendCoinPending(t *testing.T) {
+	ctx := userCtx(1)
+
+	memo := repository.TransferMemo{Message: "demo #teamwork", Hashtags: []string{"teamwork"}}
+	mockRepo := new(MockSendCoinRepository)
+	mockRepo.On("ExecTx", ctx, mock.Anything).Return(nil).Once()
+	mockRepo.On("GetRecipient", ctx, "bob").Return(&db.Employee{ID: 2, Username: "bob"}, nil).Once()
+	mockRepo.On("LockBalances", ctx, mock.Anything).Return(map[int32]int32{1: 100}, nil).Once()
+	mockRepo.On("GetTransferLimitOverride", ctx, int32(1)).Return(db.GetTransferLimitOverrideRow{}, nil).Once()
+	mockRepo.On("HoldCoins", ctx, int32(1), int32(2), int32(40), memo, mock.MatchedBy(func(expiresAt time.Time) bool {
+		return expiresAt.After(time.Now().Add(47*time.Hour)) && expiresAt.Before(time.Now().Add(49*time.Hour))
+	})).Return(db.TransferHold{
+		ID:          7,
+		SenderID:    1,
+		RecipientID: 2,
+		Amount:      40,
+		Message:     pgtype.Text{String: memo.Message, Valid: true},
+		Hashtags:    memo.Hashtags,
+		Status:      db.TransferHoldStatusEnumPending,
+	}, nil).Once()
+
+	svc := service.NewSendCoinService(mockRepo, service.TransferLimits{}, 48*time.Hour, utils.NewLogger())
+	transfer, err := svc.SendCoinPending(ctx, "bob", 40, "demo #teamwork")
+	assert.NoError(t, err)
+	assert.Equal(t, int32(7), transfer.ID)
+	assert.Equal(t, "bob", transfer.Recipient)
+	assert.Equal(t, service.PendingTransferStatusPending, transfer.Status)
+	assert.Equal(t, []string{"teamwork"}, transfer.Hashtags)
+
+	mockRepo.AssertNotCalled(t, "TransferCoins", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestSendCoinService_SendCoinPending_LimitExceeded(t *testing.T) {
+	ctx := userCtx(1)
+
+	mockRepo := new(MockSendCoinRepository)
+	mockRepo.On("ExecTx", ctx, mock.Anything).Return(nil).Once()
+	mockRepo.On("GetRecipient", ctx, "bob").Return(&db.Employee{ID: 2, Username: "bob"}, nil).Once()
+	mockRepo.On("LockBalances", ctx, mock.Anything).Return(map[int32]int32{1: 500}, nil).Once()
+	mockRepo.On("GetTransferLimitOverride", ctx, int32(1)).Return(db.GetTransferLimitOverrideRow{}, nil).Once()
+	mockRepo.On("GetOutgoingTransferTotals", ctx, mock.Anything).
+		Return(db.GetOutgoingTransferTotalsRow{SentToday: 80}, nil).Once()
+
+	svc := service.NewSendCoinService(mockRepo, service.TransferLimits{Daily: 100}, 0, utils.NewLogger())
+	_, err := svc.SendCoinPending(ctx, "bob", 40, "")
+	assert.ErrorIs(t, err, service.ErrDailyTransferLimit)
+
+	mockRepo.AssertNotCalled(t, "HoldCoins", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestPendingTransferService_Accept(t *testing.T) {
+	ctx := userCtx(2)
+
+	hold := pendingHold(7, 1, 2, 40)
+	mockRepo := new(MockPendingTransferRepository)
+	mockRepo.On("ExecTx", ctx, mock.Anything).Return(nil).Once()
+	mockRepo.On("GetTransferHoldForUpdate", ctx, int32(7)).Return(hold, nil).Once()
+	mockRepo.On("SettleHold", ctx, hold, db.TransferHoldStatusEnumAccepted).Return(nil).Once()
+	mockRepo.On("ListTransferHolds", ctx, byHoldID(7)).
+		Return([]db.ListTransferHoldsRow{{ID: 7, Sender: "alice", Recipient: "bob", Amount: 40, Status: db.TransferHoldStatusEnumAccepted,
+			ResolvedAt: pgtype.Timestamptz{Time: time.Now(), Valid: true}}}, nil).Once()
+
+	svc := service.NewPendingTransferService(mockRepo, utils.NewLogger())
+	transfer, err := svc.AcceptTransfer(ctx, 7)
+	assert.NoError(t, err)
+	assert.Equal(t, service.PendingTransferStatusAccepted, transfer.Status)
+	assert.NotNil(t, transfer.ResolvedAt)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestPendingTransferService_Decline(t *testing.T) {
+	ctx := userCtx(2)
+
+	hold := pendingHold(7, 1, 2, 40)
+	mockRepo := new(MockPendingTransferRepository)
+	mockRepo.On("ExecTx", ctx, mock.Anything).Return(nil).Once()
+	mockRepo.On("GetTransferHoldForUpdate", ctx, int32(7)).Return(hold, nil).Once()
+	mockRepo.On("SettleHold", ctx, hold, db.TransferHoldStatusEnumDeclined).Return(nil).Once()
+	mockRepo.On("ListTransferHolds", ctx, byHoldID(7)).
+		Return([]db.ListTransferHoldsRow{{ID: 7, Sender: "alice", Recipient: "bob", Amount: 40, Status: db.TransferHoldStatusEnumDeclined}}, nil).Once()
+
+	svc := service.NewPendingTransferService(mockRepo, utils.NewLogger())
+	transfer, err := svc.DeclineTransfer(ctx, 7)
+	assert.NoError(t, err)
+	assert.Equal(t, service.PendingTransferStatusDeclined, transfer.Status)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestPendingTransferService_ResolveRejected(t *testing.T) {
+	ctx := userCtx(2)
+
+	expired := pendingHold(8, 1, 2, 40)
+	expired.ExpiresAt = pgtype.Timestamptz{Time: time.Now().Add(-time.Minute), Valid: true}
+	resolved := pendingHold(9, 1, 2, 40)
+	resolved.Status = db.TransferHoldStatusEnumDeclined
+
+	mockRepo := new(MockPendingTransferRepository)
+	mockRepo.On("ExecTx", ctx, mock.Anything).Return(nil)
+	mockRepo.On("GetTransferHoldForUpdate", ctx, int32(6)).Return(db.TransferHold{}, sql.ErrNoRows).Once()
+	// Отправитель не может сам принять свой перевод.
+	mockRepo.On("GetTransferHoldForUpdate", ctx, int32(7)).Return(pendingHold(7, 2, 3, 40), nil).Once()
+	mockRepo.On("GetTransferHoldForUpdate", ctx, int32(8)).Return(expired, nil).Once()
+	mockRepo.On("GetTransferHoldForUpdate", ctx, int32(9)).Return(resolved, nil).Once()
+
+	svc := service.NewPendingTransferService(mockRepo, utils.NewLogger())
+
+	_, err := svc.AcceptTransfer(ctx, 6)
+	assert.ErrorIs(t, err, service.ErrPendingTransferNotFound)
+
+	_, err = svc.AcceptTransfer(ctx, 7)
+	assert.ErrorIs(t, err, service.ErrPendingTransferNotFound)
+
+	_, err = svc.AcceptTransfer(ctx, 8)
+	assert.ErrorIs(t, err, service.ErrPendingTransferExpired)
+
+	_, err = svc.DeclineTransfer(ctx, 9)
+	assert.ErrorIs(t, err, service.ErrPendingTransferResolved)
+
+	_, err = svc.AcceptTransfer(context.Background(), 7)
+	assert.ErrorIs(t, err, service.ErrUnauthenticated)
+
+	mockRepo.AssertNotCalled(t, "SettleHold", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestPendingTransferService_List(t *testing.T) {
+	ctx := userCtx(2)
+
+	mockRepo := new(MockPendingTransferRepository)
+	mockRepo.On("ListTransferHolds", ctx, db.ListTransferHoldsParams{
+		RecipientID: pgtype.Int4{Int32: 2, Valid: true},
+		Status:      db.NullTransferHoldStatusEnum{TransferHoldStatusEnum: db.TransferHoldStatusEnumPending, Valid: true},
+	}).Return([]db.ListTransferHoldsRow{{ID: 7, Sender: "alice", Recipient: "bob", Amount: 40, Status: db.TransferHoldStatusEnumPending}}, nil).Once()
+	mockRepo.On("ListTransferHolds", ctx, db.ListTransferHoldsParams{SenderID: pgtype.Int4{Int32: 2, Valid: true}}).
+		Return([]db.ListTransferHoldsRow{}, nil).Once()
+
+	svc := service.NewPendingTransferService(mockRepo, utils.NewLogger())
+
+	incoming, err := svc.ListIncoming(ctx, service.PendingTransferStatusPending)
+	assert.NoError(t, err)
+	assert.Len(t, incoming, 1)
+	assert.Equal(t, "alice", incoming[0].Sender)
+
+	outgoing, err := svc.ListOutgoing(ctx, "")
+	assert.NoError(t, err)
+	assert.Empty(t, outgoing)
+
+	_, err = svc.ListIncoming(ctx, "unknown")
+	assert.ErrorIs(t, err, service.ErrInvalidPendingTransferStatus)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestPendingTransferService_RefundExpired(t *testing.T) {
+	ctx := context.Background()
+
+	first := make([]db.TransferHold, 100)
+	for i := range first {
+		first[i] = pendingHold(int32(i+1), 1, 2, 10)
+	}
+	second := []db.TransferHold{pendingHold(101, 3, 2, 5)}
+
+	mockRepo := new(MockPendingTransferRepository)
+	mockRepo.On("ExecTx", ctx, mock.Anything).Return(nil).Twice()
+	mockRepo.On("ListExpiredTransferHolds", ctx, int32(100)).Return(first, nil).Once()
+	mockRepo.On("ListExpiredTransferHolds", ctx, int32(100)).Return(second, nil).Once()
+	mockRepo.On("SettleHold", ctx, mock.Anything, db.TransferHoldStatusEnumExpired).Return(nil).Times(101)
+
+	svc := service.NewPendingTransferService(mockRepo, utils.NewLogger())
+	refunded, err := svc.RefundExpired(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 101, refunded)
+
+	mockRepo.AssertExpectations(t)
+}
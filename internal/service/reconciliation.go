@@ -0,0 +1,255 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/par1ram/merch-store/internal/db"
+	"github.com/par1ram/merch-store/internal/metrics"
+	"github.com/par1ram/merch-store/internal/repository"
+	"github.com/par1ram/merch-store/internal/utils"
+)
+
+// reconciliationReason — причина компенсирующих транзакций в истории сотрудника.
+const reconciliationReason = "balance reconciliation"
+
+var ErrAdminRequired = newError(CodeAdminRequired, KindForbidden, "admin role is required")
+
+// BalanceDrift — расхождение баланса сотрудника с историей операций.
+// Drift положителен, если на балансе больше монет, чем объясняет история.
+// TransactionID заполняется, если расхождение закрыто компенсирующей транзакцией.
+type BalanceDrift struct {
+	Username      string `json:"username"`
+	Balance       int32  `json:"balance"`
+	Expected      int64  `json:"expected"`
+	Drift         int64  `json:"drift"`
+	TransactionID int32  `json:"transaction_id,omitempty"`
+}
+
+// CoinLotDrift — расхождение баланса сотрудника с суммой остатков его партий.
+// Drift положителен, если на балансе больше монет, чем в партиях.
+type CoinLotDrift struct {
+	Username string `json:"username"`
+	Balance  int32  `json:"balance"`
+	Lots     int64  `json:"lots"`
+	Drift    int64  `json:"drift"`
+}
+
+// ReconciliationReport — результат сверки балансов. LotMismatches после Fix
+// содержит расхождения партий, оставшиеся после записи компенсаций: Fix их
+// не исправляет, они требуют разбора вручную.
+type ReconciliationReport struct {
+	CheckedAt     time.Time      `json:"checked_at"`
+	Consistent    bool           `json:"consistent"`
+	Fixed         bool           `json:"fixed"`
+	TotalDrift    int64          `json:"total_drift"`
+	Mismatches    []BalanceDrift `json:"mismatches"`
+	LotMismatches []CoinLotDrift `json:"lot_mismatches"`
+}
+
+// ReconciliationService пересчитывает ожидаемый баланс каждого сотрудника
+// по coin_transactions и сравнивает его с employees.coins. Стартовые монеты
+// в coin_transactions не записываются, поэтому начальный остаток берётся из
+// проводок signup и opening в книге.
+//
+// Fix не меняет балансы: расхождение закрывается записью grant или adjustment
+// на сумму расхождения, после чего история снова объясняет баланс. Вместе
+// с записью создаётся проводка в книге и, как при ручном начислении или
+// списании, создаётся или расходуется партия монет.
+//
+// Сверка также сравнивает баланс с суммой остатков партий (LotMismatches).
+type ReconciliationService interface {
+	Report(ctx context.Context) (ReconciliationReport, error)
+	Fix(ctx context.Context, adminUsername string) (ReconciliationReport, error)
+}
+
+type reconciliationService struct {
+	repo   repository.ReconciliationRepository
+	logger utils.Logger
+}
+
+func NewReconciliationService(repo repository.ReconciliationRepository, logger utils.Logger) ReconciliationService {
+	logger.WithFields(utils.LogFields{"component": "reconciliation_service"}).Info("ReconciliationService initialized")
+	return &reconciliationService{
+		repo:   repo,
+		logger: logger.WithFields(utils.LogFields{"component": "reconciliation_service"}),
+	}
+}
+
+func (s *reconciliationService) Report(ctx context.Context) (ReconciliationReport, error) {
+	rows, err := s.repo.ListBalanceDrift(ctx)
+	if err != nil {
+		return ReconciliationReport{}, fmt.Errorf("failed to list balance drift: %w", err)
+	}
+	lotRows, err := s.repo.ListCoinLotDrift(ctx)
+	if err != nil {
+		return ReconciliationReport{}, fmt.Errorf("failed to list coin lot drift: %w", err)
+	}
+
+	report := newReconciliationReport(rows, lotRows)
+	s.record(report)
+	return report, nil
+}
+
+// Fix закрывает все найденные расхождения от имени администратора adminUsername.
+// Сверка и запись компенсаций выполняются в одной транзакции.
+func (s *reconciliationService) Fix(ctx context.Context, adminUsername string) (ReconciliationReport, error) {
+	log := s.logger.WithFields(utils.LogFields{
+		"operation": "fix_balance_drift",
+		"admin":     adminUsername,
+	})
+
+	var report ReconciliationReport
+	err := s.repo.ExecTx(ctx, func(r repository.ReconciliationRepository) error {
+		admin, err := r.GetEmployee(ctx, adminUsername)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return fmt.Errorf("%w: %w: %s", ErrBusinessValidation, ErrUserNotFound, adminUsername)
+			}
+			return fmt.Errorf("failed to get employee: %w", err)
+		}
+		if admin.Role != RoleAdmin {
+			return fmt.Errorf("%w: %w: %s", ErrBusinessValidation, ErrAdminRequired, adminUsername)
+		}
+
+		rows, err := r.ListBalanceDrift(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to list balance drift: %w", err)
+		}
+
+		// Компенсация не меняет баланс, поэтому расхождение, найденное до
+		// блокировки, остаётся верным: переводы и покупки меняют баланс
+		// и историю одновременно.
+		if len(rows) > 0 {
+			ids := make([]int32, 0, len(rows))
+			for _, row := range rows {
+				ids = append(ids, row.ID)
+			}
+			if _, err := r.LockBalances(ctx, ids...); err != nil {
+				return fmt.Errorf("failed to lock balances: %w", err)
+			}
+		}
+
+		transactionIDs := make([]int32, len(rows))
+		for i, row := range rows {
+			id, err := compensate(ctx, r, admin.ID, row)
+			if err != nil {
+				return fmt.Errorf("failed to compensate drift for %s: %w", row.Username, err)
+			}
+			transactionIDs[i] = id
+		}
+
+		lotRows, err := r.ListCoinLotDrift(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to list coin lot drift: %w", err)
+		}
+
+		report = newReconciliationReport(rows, lotRows)
+		for i, id := range transactionIDs {
+			report.Mismatches[i].TransactionID = id
+		}
+		report.Fixed = len(rows) > 0
+		return nil
+	})
+	if err != nil {
+		log.WithFields(utils.LogFields{"error": err}).Error("Balance reconciliation fix failed")
+		return ReconciliationReport{}, err
+	}
+
+	if report.Fixed {
+		log.WithFields(utils.LogFields{
+			"employees":   len(report.Mismatches),
+			"total_drift": report.TotalDrift,
+		}).Info("Balance drift compensated")
+	}
+	s.record(report)
+	return report, nil
+}
+
+// compensate записывает транзакцию на сумму расхождения, не меняя баланс;
+// партии сотрудника меняются вместе с записью.
+func compensate(ctx context.Context, r repository.ReconciliationRepository, adminID int32, row db.ListBalanceDriftRow) (int32, error) {
+	drift := int64(row.Coins) - row.Expected
+	if drift > math.MaxInt32 || drift < -math.MaxInt32 {
+		return 0, fmt.Errorf("drift %d is out of range", drift)
+	}
+	if drift > 0 {
+		return r.CreateGrantTransaction(ctx, db.CreateCoinTransactionGrantParams{
+			ToEmployeeID: row.ID,
+			Amount:       int32(drift),
+			Reason:       reconciliationReason,
+			CreatedBy:    adminID,
+		})
+	}
+	return r.CreateAdjustmentTransaction(ctx, db.CreateCoinTransactionAdjustmentParams{
+		FromEmployeeID: row.ID,
+		Amount:         int32(-drift),
+		Reason:         reconciliationReason,
+		CreatedBy:      adminID,
+	})
+}
+
+func newReconciliationReport(rows []db.ListBalanceDriftRow, lotRows []db.ListCoinLotDriftRow) ReconciliationReport {
+	report := ReconciliationReport{
+		CheckedAt:     time.Now().UTC(),
+		Consistent:    len(rows) == 0 && len(lotRows) == 0,
+		Mismatches:    make([]BalanceDrift, 0, len(rows)),
+		LotMismatches: make([]CoinLotDrift, 0, len(lotRows)),
+	}
+	for _, row := range lotRows {
+		report.LotMismatches = append(report.LotMismatches, CoinLotDrift{
+			Username: row.Username,
+			Balance:  row.Coins,
+			Lots:     row.Lots,
+			Drift:    int64(row.Coins) - row.Lots,
+		})
+	}
+	for _, row := range rows {
+		drift := int64(row.Coins) - row.Expected
+		report.TotalDrift += drift
+		report.Mismatches = append(report.Mismatches, BalanceDrift{
+			Username: row.Username,
+			Balance:  row.Coins,
+			Expected: row.Expected,
+			Drift:    drift,
+		})
+	}
+	return report
+}
+
+// record обновляет метрики сверки и предупреждает о незакрытых расхождениях.
+func (s *reconciliationService) record(report ReconciliationReport) {
+	metrics.ReconciliationLastRun.Set(report.CheckedAt.Unix())
+	metrics.CoinLotDriftEmployees.Set(int64(len(report.LotMismatches)))
+	if len(report.LotMismatches) > 0 {
+		s.logger.WithFields(utils.LogFields{
+			"employees": len(report.LotMismatches),
+		}).Warn("Coin lot drift detected")
+	}
+
+	if report.Fixed || len(report.Mismatches) == 0 {
+		metrics.BalanceDriftEmployees.Set(0)
+		metrics.BalanceDriftCoins.Set(0)
+		return
+	}
+
+	var absolute int64
+	for _, m := range report.Mismatches {
+		if m.Drift < 0 {
+			absolute -= m.Drift
+		} else {
+			absolute += m.Drift
+		}
+	}
+	metrics.BalanceDriftEmployees.Set(int64(len(report.Mismatches)))
+	metrics.BalanceDriftCoins.Set(absolute)
+
+	s.logger.WithFields(utils.LogFields{
+		"employees":   len(report.Mismatches),
+		"total_drift": report.TotalDrift,
+	}).Warn("Balance drift detected")
+}
@@ -0,0 +1,172 @@
+package service_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/par1ram/merch-store/internal/db"
+	"github.com/par1ram/merch-store/internal/repository"
+	"github.com/par1ram/merch-store/internal/service"
+	"github.com/par1ram/merch-store/internal/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockReconciliationRepository struct {
+	mock.Mock
+}
+
+func (m *MockReconciliationRepository) ExecTx(ctx context.Context, fn func(repository.ReconciliationRepository) error) error {
+	args := m.Called(ctx, fn)
+	if err := args.Error(0); err != nil {
+		return err
+	}
+	return fn(m)
+}
+
+func (m *MockReconciliationRepository) ListBalanceDrift(ctx context.Context) ([]db.ListBalanceDriftRow, error) {
+	args := m.Called(ctx)
+	if res, ok := args.Get(0).([]db.ListBalanceDriftRow); ok {
+		return res, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockReconciliationRepository) ListCoinLotDrift(ctx context.Context) ([]db.ListCoinLotDriftRow, error) {
+	args := m.Called(ctx)
+	if res, ok := args.Get(0).([]db.ListCoinLotDriftRow); ok {
+		return res, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockReconciliationRepository) LockBalances(ctx context.Context, userIDs ...int32) (map[int32]int32, error) {
+	args := m.Called(ctx, userIDs)
+	if res, ok := args.Get(0).(map[int32]int32); ok {
+		return res, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockReconciliationRepository) GetEmployee(ctx context.Context, username string) (db.Employee, error) {
+	args := m.Called(ctx, username)
+	return args.Get(0).(db.Employee), args.Error(1)
+}
+
+func (m *MockReconciliationRepository) CreateGrantTransaction(ctx context.Context, params db.CreateCoinTransactionGrantParams) (int32, error) {
+	args := m.Called(ctx, params)
+	return args.Get(0).(int32), args.Error(1)
+}
+
+func (m *MockReconciliationRepository) CreateAdjustmentTransaction(ctx context.Context, params db.CreateCoinTransactionAdjustmentParams) (int32, error) {
+	args := m.Called(ctx, params)
+	return args.Get(0).(int32), args.Error(1)
+}
+
+var driftRows = []db.ListBalanceDriftRow{
+	{ID: 4, Username: "dave", Coins: 950, Expected: 1000},
+	{ID: 5, Username: "erin", Coins: 1030, Expected: 1000},
+}
+
+func TestReconciliationReport_Drift(t *testing.T) {
+	ctx := context.Background()
+	repo := new(MockReconciliationRepository)
+	repo.On("ListBalanceDrift", ctx).Return(driftRows, nil).Once()
+	repo.On("ListCoinLotDrift", ctx).Return([]db.ListCoinLotDriftRow{}, nil).Once()
+
+	svc := service.NewReconciliationService(repo, utils.NewLogger())
+	report, err := svc.Report(ctx)
+	assert.NoError(t, err)
+	assert.False(t, report.Consistent)
+	assert.False(t, report.Fixed)
+	assert.Equal(t, int64(-20), report.TotalDrift)
+	assert.Equal(t, []service.BalanceDrift{
+		{Username: "dave", Balance: 950, Expected: 1000, Drift: -50},
+		{Username: "erin", Balance: 1030, Expected: 1000, Drift: 30},
+	}, report.Mismatches)
+
+	repo.AssertExpectations(t)
+}
+
+func TestReconciliationReport_Consistent(t *testing.T) {
+	ctx := context.Background()
+	repo := new(MockReconciliationRepository)
+	repo.On("ListBalanceDrift", ctx).Return([]db.ListBalanceDriftRow{}, nil).Once()
+	repo.On("ListCoinLotDrift", ctx).Return([]db.ListCoinLotDriftRow{}, nil).Once()
+
+	svc := service.NewReconciliationService(repo, utils.NewLogger())
+	report, err := svc.Report(ctx)
+	assert.NoError(t, err)
+	assert.True(t, report.Consistent)
+	assert.Empty(t, report.Mismatches)
+	assert.Empty(t, report.LotMismatches)
+}
+
+func TestReconciliationReport_CoinLotDrift(t *testing.T) {
+	ctx := context.Background()
+	repo := new(MockReconciliationRepository)
+	repo.On("ListBalanceDrift", ctx).Return([]db.ListBalanceDriftRow{}, nil).Once()
+	repo.On("ListCoinLotDrift", ctx).Return([]db.ListCoinLotDriftRow{
+		{ID: 4, Username: "dave", Coins: 950, Lots: 1000},
+	}, nil).Once()
+
+	svc := service.NewReconciliationService(repo, utils.NewLogger())
+	report, err := svc.Report(ctx)
+	assert.NoError(t, err)
+	assert.False(t, report.Consistent)
+	assert.Empty(t, report.Mismatches)
+	assert.Equal(t, []service.CoinLotDrift{
+		{Username: "dave", Balance: 950, Lots: 1000, Drift: -50},
+	}, report.LotMismatches)
+}
+
+func TestReconciliationFix_WritesCompensations(t *testing.T) {
+	ctx := context.Background()
+	repo := new(MockReconciliationRepository)
+	repo.On("ExecTx", ctx, mock.Anything).Return(nil).Once()
+	repo.On("GetEmployee", ctx, "root").Return(db.Employee{ID: 1, Username: "root", Role: db.EmployeeRoleEnumAdmin}, nil).Once()
+	repo.On("ListBalanceDrift", ctx).Return(driftRows, nil).Once()
+	repo.On("LockBalances", ctx, []int32{4, 5}).Return(map[int32]int32{4: 950, 5: 1030}, nil).Once()
+	repo.On("CreateAdjustmentTransaction", ctx, db.CreateCoinTransactionAdjustmentParams{
+		FromEmployeeID: 4, Amount: 50, Reason: "balance reconciliation", CreatedBy: 1,
+	}).Return(int32(90), nil).Once()
+	repo.On("CreateGrantTransaction", ctx, db.CreateCoinTransactionGrantParams{
+		ToEmployeeID: 5, Amount: 30, Reason: "balance reconciliation", CreatedBy: 1,
+	}).Return(int32(91), nil).Once()
+	repo.On("ListCoinLotDrift", ctx).Return([]db.ListCoinLotDriftRow{}, nil).Once()
+
+	svc := service.NewReconciliationService(repo, utils.NewLogger())
+	report, err := svc.Fix(ctx, "root")
+	assert.NoError(t, err)
+	assert.True(t, report.Fixed)
+	assert.Empty(t, report.LotMismatches)
+	assert.Equal(t, int32(90), report.Mismatches[0].TransactionID)
+	assert.Equal(t, int32(91), report.Mismatches[1].TransactionID)
+
+	repo.AssertExpectations(t)
+}
+
+func TestReconciliationFix_RequiresAdmin(t *testing.T) {
+	ctx := context.Background()
+	repo := new(MockReconciliationRepository)
+	repo.On("ExecTx", ctx, mock.Anything).Return(nil).Once()
+	repo.On("GetEmployee", ctx, "bob").Return(db.Employee{ID: 2, Username: "bob", Role: db.EmployeeRoleEnumEmployee}, nil).Once()
+
+	svc := service.NewReconciliationService(repo, utils.NewLogger())
+	_, err := svc.Fix(ctx, "bob")
+	assert.ErrorIs(t, err, service.ErrAdminRequired)
+
+	repo.AssertNotCalled(t, "ListBalanceDrift", mock.Anything)
+}
+
+func TestReconciliationFix_AdminNotFound(t *testing.T) {
+	ctx := context.Background()
+	repo := new(MockReconciliationRepository)
+	repo.On("ExecTx", ctx, mock.Anything).Return(nil).Once()
+	repo.On("GetEmployee", ctx, "ghost").Return(db.Employee{}, sql.ErrNoRows).Once()
+
+	svc := service.NewReconciliationService(repo, utils.NewLogger())
+	_, err := svc.Fix(ctx, "ghost")
+	assert.ErrorIs(t, err, service.ErrUserNotFound)
+}
@@ -0,0 +1,464 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/par1ram/merch-store/internal/db"
+	"github.com/par1ram/merch-store/internal/middleware"
+	"github.com/par1ram/merch-store/internal/repository"
+	"github.com/par1ram/merch-store/internal/utils"
+)
+
+// Статусы заявок на возврат.
+const (
+	RefundStatusPending  = "pending"
+	RefundStatusApproved = "approved"
+	RefundStatusRejected = "rejected"
+)
+
+// maxRefundReasonLength ограничивает длину причины возврата.
+const maxRefundReasonLength = 500
+
+var (
+	ErrPurchaseNotFound      = newError(CodePurchaseNotFound, KindNotFound, "purchase not found")
+	ErrRefundExceedsPurchase = newError(CodeRefundExceedsPurchase, KindConflict, "refund quantity exceeds refundable quantity")
+	ErrRefundItemNotHeld     = newError(CodeRefundItemNotHeld, KindConflict, "refunded item is not in employee inventory")
+	ErrRefundRequestNotFound = newError(CodeRefundRequestNotFound, KindNotFound, "refund request not found")
+	ErrRefundRequestResolved = newError(CodeRefundRequestResolved, KindConflict, "refund request already resolved")
+	ErrInvalidRefundStatus   = newError(CodeInvalidRefundStatus, KindInvalid, "invalid refund status")
+	ErrInvalidRefundReason   = newError(CodeInvalidRefundReason, KindInvalid, "refund reason is too long")
+)
+
+// PurchaseRecord — покупка сотрудника с количеством уже возвращённых единиц.
+type PurchaseRecord struct {
+	ID               int32     `json:"id"`
+	Item             string    `json:"item"`
+	Quantity         int32     `json:"quantity"`
+	UnitPrice        int32     `json:"unit_price"`
+	Amount           int32     `json:"amount"`
+	RefundedQuantity int32     `json:"refunded_quantity"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+// RefundResult описывает выполненный возврат.
+type RefundResult struct {
+	RefundID   int32  `json:"refund_id"`
+	PurchaseID int32  `json:"purchase_id"`
+	Item       string `json:"item"`
+	Quantity   int32  `json:"quantity"`
+	Amount     int32  `json:"amount"`
+}
+
+// RefundRequest — заявка сотрудника на возврат.
+type RefundRequest struct {
+	ID         int32      `json:"id"`
+	PurchaseID int32      `json:"purchase_id"`
+	Username   string     `json:"username,omitempty"`
+	Item       string     `json:"item"`
+	Quantity   int32      `json:"quantity"`
+	Reason     string     `json:"reason"`
+	Status     string     `json:"status"`
+	RefundID   *int32     `json:"refund_id,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ResolvedAt *time.Time `json:"resolved_at,omitempty"`
+}
+
+// RefundService реализует возвраты: сразу по решению администратора или
+// по заявке сотрудника после одобрения администратором.
+// Нулевое количество в запросах означает все ещё не возвращённые единицы покупки.
+type RefundService interface {
+	ListPurchases(ctx context.Context) ([]PurchaseRecord, error)
+	RequestRefund(ctx context.Context, purchaseID, quantity int32, reason string) (RefundRequest, error)
+	ListMyRefundRequests(ctx context.Context) ([]RefundRequest, error)
+	ListRefundRequests(ctx context.Context, status string) ([]RefundRequest, error)
+	RefundPurchase(ctx context.Context, purchaseID, quantity int32) (RefundResult, error)
+	ApproveRefundRequest(ctx context.Context, requestID int32) (RefundResult, error)
+	RejectRefundRequest(ctx context.Context, requestID int32) error
+}
+
+type refundService struct {
+	repo   repository.RefundRepository
+	logger utils.Logger
+}
+
+func NewRefundService(repo repository.RefundRepository, logger utils.Logger) RefundService {
+	logger.WithFields(utils.LogFields{"component": "refund_service"}).Info("RefundService initialized")
+	return &refundService{
+		repo:   repo,
+		logger: logger.WithFields(utils.LogFields{"component": "refund_service"}),
+	}
+}
+
+func (s *refundService) ListPurchases(ctx context.Context) ([]PurchaseRecord, error) {
+	log := s.logger.WithFields(utils.LogFields{"operation": "list_purchases"})
+
+	userID := middleware.GetUserIDFromContext(ctx)
+	if userID == 0 {
+		log.Error("User not authenticated")
+		return nil, ErrUnauthenticated
+	}
+
+	rows, err := s.repo.ListPurchases(ctx, int32(userID))
+	if err != nil {
+		log.WithFields(utils.LogFields{"error": err}).Error("Failed to list purchases")
+		return nil, err
+	}
+
+	purchases := make([]PurchaseRecord, 0, len(rows))
+	for _, row := range rows {
+		purchases = append(purchases, PurchaseRecord{
+			ID:               row.ID,
+			Item:             row.MerchName,
+			Quantity:         row.Quantity,
+			UnitPrice:        row.UnitPrice.Int32,
+			Amount:           row.Amount,
+			RefundedQuantity: row.RefundedQuantity,
+			CreatedAt:        row.CreatedAt.Time,
+		})
+	}
+	return purchases, nil
+}
+
+func (s *refundService) RequestRefund(ctx context.Context, purchaseID, quantity int32, reason string) (RefundRequest, error) {
+	log := s.logger.WithFields(utils.LogFields{
+		"operation":   "request_refund",
+		"purchase_id": purchaseID,
+		"quantity":    quantity,
+	})
+
+	userID := middleware.GetUserIDFromContext(ctx)
+	if userID == 0 {
+		log.Error("User not authenticated")
+		return RefundRequest{}, ErrUnauthenticated
+	}
+	if quantity < 0 {
+		return RefundRequest{}, fmt.Errorf("%w: %w", ErrBusinessValidation, ErrInvalidQuantity)
+	}
+	reason = strings.TrimSpace(reason)
+	if len(reason) > maxRefundReasonLength {
+		return RefundRequest{}, fmt.Errorf("%w: %w", ErrBusinessValidation, ErrInvalidRefundReason)
+	}
+
+	var request RefundRequest
+	err := s.repo.ExecTx(ctx, func(r repository.RefundRepository) error {
+		purchase, remaining, err := s.lockPurchase(ctx, r, purchaseID)
+		if err != nil {
+			return err
+		}
+		// Чужие покупки неотличимы от несуществующих.
+		if purchase.FromEmployeeID.Int32 != int32(userID) {
+			return fmt.Errorf("%w: %w", ErrBusinessValidation, ErrPurchaseNotFound)
+		}
+		if quantity == 0 {
+			quantity = remaining
+		}
+		if quantity == 0 || quantity > remaining {
+			return fmt.Errorf("%w: %w", ErrBusinessValidation, ErrRefundExceedsPurchase)
+		}
+
+		created, err := r.CreateRefundRequest(ctx, db.CreateRefundRequestParams{
+			PurchaseID: purchaseID,
+			EmployeeID: int32(userID),
+			Quantity:   quantity,
+			Reason:     reason,
+		})
+		if err != nil {
+			return err
+		}
+
+		request = toRefundRequest(created)
+		request.Item = purchase.MerchName
+		return nil
+	})
+	if err != nil {
+		log.WithFields(utils.LogFields{"error": err}).Error("Failed to create refund request")
+		return RefundRequest{}, err
+	}
+
+	log.WithFields(utils.LogFields{"request_id": request.ID, "user_id": userID}).Info("Refund requested")
+	return request, nil
+}
+
+func (s *refundService) ListMyRefundRequests(ctx context.Context) ([]RefundRequest, error) {
+	userID := middleware.GetUserIDFromContext(ctx)
+	if userID == 0 {
+		s.logger.Error("User not authenticated")
+		return nil, ErrUnauthenticated
+	}
+
+	return s.listRefundRequests(ctx, db.ListRefundRequestsParams{
+		EmployeeID: pgtype.Int4{Int32: int32(userID), Valid: true},
+	})
+}
+
+func (s *refundService) ListRefundRequests(ctx context.Context, status string) ([]RefundRequest, error) {
+	params := db.ListRefundRequestsParams{}
+	if status != "" {
+		switch status {
+		case RefundStatusPending, RefundStatusApproved, RefundStatusRejected:
+		default:
+			return nil, fmt.Errorf("%w: %w", ErrBusinessValidation, ErrInvalidRefundStatus)
+		}
+		params.Status = db.NullRefundStatusEnum{RefundStatusEnum: db.RefundStatusEnum(status), Valid: true}
+	}
+
+	return s.listRefundRequests(ctx, params)
+}
+
+func (s *refundService) listRefundRequests(ctx context.Context, params db.ListRefundRequestsParams) ([]RefundRequest, error) {
+	rows, err := s.repo.ListRefundRequests(ctx, params)
+	if err != nil {
+		s.logger.WithFields(utils.LogFields{"error": err}).Error("Failed to list refund requests")
+		return nil, err
+	}
+
+	requests := make([]RefundRequest, 0, len(rows))
+	for _, row := range rows {
+		request := RefundRequest{
+			ID:         row.ID,
+			PurchaseID: row.PurchaseID,
+			Username:   row.Username,
+			Item:       row.MerchName,
+			Quantity:   row.Quantity,
+			Reason:     row.Reason,
+			Status:     string(row.Status),
+			CreatedAt:  row.CreatedAt.Time,
+		}
+		if row.RefundID.Valid {
+			refundID := row.RefundID.Int32
+			request.RefundID = &refundID
+		}
+		if row.ResolvedAt.Valid {
+			resolvedAt := row.ResolvedAt.Time
+			request.ResolvedAt = &resolvedAt
+		}
+		requests = append(requests, request)
+	}
+	return requests, nil
+}
+
+func (s *refundService) RefundPurchase(ctx context.Context, purchaseID, quantity int32) (RefundResult, error) {
+	log := s.logger.WithFields(utils.LogFields{
+		"operation":   "refund_purchase",
+		"purchase_id": purchaseID,
+		"quantity":    quantity,
+		"admin_id":    middleware.GetUserIDFromContext(ctx),
+	})
+
+	if quantity < 0 {
+		return RefundResult{}, fmt.Errorf("%w: %w", ErrBusinessValidation, ErrInvalidQuantity)
+	}
+
+	var result RefundResult
+	err := s.repo.ExecTx(ctx, func(r repository.RefundRepository) error {
+		var err error
+		result, err = s.refund(ctx, r, purchaseID, quantity)
+		return err
+	})
+	if err != nil {
+		log.WithFields(utils.LogFields{"error": err}).Error("Refund failed")
+		return RefundResult{}, err
+	}
+
+	log.WithFields(utils.LogFields{"refund_id": result.RefundID, "amount": result.Amount}).Info("Purchase refunded")
+	return result, nil
+}
+
+func (s *refundService) ApproveRefundRequest(ctx context.Context, requestID int32) (RefundResult, error) {
+	adminID := middleware.GetUserIDFromContext(ctx)
+	log := s.logger.WithFields(utils.LogFields{
+		"operation":  "approve_refund_request",
+		"request_id": requestID,
+		"admin_id":   adminID,
+	})
+
+	var result RefundResult
+	err := s.repo.ExecTx(ctx, func(r repository.RefundRepository) error {
+		request, err := s.lockPendingRequest(ctx, r, requestID)
+		if err != nil {
+			return err
+		}
+
+		// Остаток проверяется заново: с момента подачи заявки часть покупки
+		// могла быть возвращена другим путём.
+		result, err = s.refund(ctx, r, request.PurchaseID, request.Quantity)
+		if err != nil {
+			return err
+		}
+
+		return r.ResolveRefundRequest(ctx, db.ResolveRefundRequestParams{
+			ID:         requestID,
+			Status:     db.RefundStatusEnumApproved,
+			RefundID:   pgtype.Int4{Int32: result.RefundID, Valid: true},
+			ResolvedBy: int32(adminID),
+		})
+	})
+	if err != nil {
+		log.WithFields(utils.LogFields{"error": err}).Error("Failed to approve refund request")
+		return RefundResult{}, err
+	}
+
+	log.WithFields(utils.LogFields{"refund_id": result.RefundID, "amount": result.Amount}).Info("Refund request approved")
+	return result, nil
+}
+
+func (s *refundService) RejectRefundRequest(ctx context.Context, requestID int32) error {
+	adminID := middleware.GetUserIDFromContext(ctx)
+	log := s.logger.WithFields(utils.LogFields{
+		"operation":  "reject_refund_request",
+		"request_id": requestID,
+		"admin_id":   adminID,
+	})
+
+	err := s.repo.ExecTx(ctx, func(r repository.RefundRepository) error {
+		if _, err := s.lockPendingRequest(ctx, r, requestID); err != nil {
+			return err
+		}
+		return r.ResolveRefundRequest(ctx, db.ResolveRefundRequestParams{
+			ID:         requestID,
+			Status:     db.RefundStatusEnumRejected,
+			ResolvedBy: int32(adminID),
+		})
+	})
+	if err != nil {
+		log.WithFields(utils.LogFields{"error": err}).Error("Failed to reject refund request")
+		return err
+	}
+
+	log.Info("Refund request rejected")
+	return nil
+}
+
+// refund выполняет возврат внутри транзакции: блокирует строку покупателя,
+// забирает товар из инвентаря, возвращает монеты по цене покупки с исходными
+// сроками действия и записывает транзакцию refund. Заказ на выдачу отменяется, если покупка возвращена
+// полностью, и уменьшается при частичном возврате.
+// Остаток на складе не увеличивается: возвращённый товар может быть
+// бракованным, администратор меняет остаток вручную.
+func (s *refundService) refund(ctx context.Context, r repository.RefundRepository, purchaseID, quantity int32) (RefundResult, error) {
+	purchase, remaining, err := s.lockPurchase(ctx, r, purchaseID)
+	if err != nil {
+		return RefundResult{}, err
+	}
+	if quantity == 0 {
+		quantity = remaining
+	}
+	if quantity == 0 || quantity > remaining {
+		return RefundResult{}, fmt.Errorf("%w: %w", ErrBusinessValidation, ErrRefundExceedsPurchase)
+	}
+
+	buyerID := purchase.FromEmployeeID.Int32
+	merchID := purchase.MerchID.Int32
+	amount := purchase.UnitPrice.Int32 * quantity
+
+	// Сотрудник блокируется раньше инвентаря, как при покупке: обратный
+	// порядок приводил бы к взаимной блокировке с покупкой того же товара.
+	if _, err := r.LockBalance(ctx, buyerID); err != nil {
+		return RefundResult{}, err
+	}
+
+	affected, err := r.RemoveInventory(ctx, db.RemoveInventoryParams{
+		EmployeeID: buyerID,
+		MerchID:    merchID,
+		Quantity:   quantity,
+	})
+	if err != nil {
+		return RefundResult{}, err
+	}
+	if affected == 0 {
+		return RefundResult{}, fmt.Errorf("%w: %w", ErrBusinessValidation, ErrRefundItemNotHeld)
+	}
+
+	if err := r.RestoreCoins(ctx, purchaseID, buyerID, amount); err != nil {
+		return RefundResult{}, err
+	}
+
+	refundID, err := r.CreateRefundTransaction(ctx, db.CreateCoinTransactionRefundParams{
+		ToEmployeeID: buyerID,
+		MerchID:      merchID,
+		Amount:       amount,
+		Quantity:     quantity,
+		UnitPrice:    purchase.UnitPrice.Int32,
+		RefundOf:     purchaseID,
+	})
+	if err != nil {
+		return RefundResult{}, err
+	}
+
+	// Полностью возвращённую покупку выдавать не нужно, при частичном возврате
+	// выдаётся только оставшееся количество.
+	if quantity == remaining {
+		err = r.CancelOrder(ctx, purchaseID)
+	} else {
+		err = r.ReduceOrder(ctx, purchaseID, quantity)
+	}
+	if err != nil {
+		return RefundResult{}, err
+	}
+
+	return RefundResult{
+		RefundID:   refundID,
+		PurchaseID: purchaseID,
+		Item:       purchase.MerchName,
+		Quantity:   quantity,
+		Amount:     amount,
+	}, nil
+}
+
+// lockPurchase блокирует покупку и возвращает количество единиц, которые ещё можно вернуть.
+func (s *refundService) lockPurchase(ctx context.Context, r repository.RefundRepository, purchaseID int32) (db.GetPurchaseForUpdateRow, int32, error) {
+	purchase, err := r.GetPurchaseForUpdate(ctx, purchaseID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return purchase, 0, fmt.Errorf("%w: %w", ErrBusinessValidation, ErrPurchaseNotFound)
+		}
+		return purchase, 0, err
+	}
+
+	refunded, err := r.GetRefundedQuantity(ctx, purchaseID)
+	if err != nil {
+		return purchase, 0, err
+	}
+	return purchase, purchase.Quantity - refunded, nil
+}
+
+// lockPendingRequest блокирует заявку и проверяет, что решение по ней ещё не принято.
+func (s *refundService) lockPendingRequest(ctx context.Context, r repository.RefundRepository, requestID int32) (db.RefundRequest, error) {
+	request, err := r.GetRefundRequestForUpdate(ctx, requestID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return request, fmt.Errorf("%w: %w", ErrBusinessValidation, ErrRefundRequestNotFound)
+		}
+		return request, err
+	}
+	if request.Status != db.RefundStatusEnumPending {
+		return request, fmt.Errorf("%w: %w", ErrBusinessValidation, ErrRefundRequestResolved)
+	}
+	return request, nil
+}
+
+func toRefundRequest(r db.RefundRequest) RefundRequest {
+	request := RefundRequest{
+		ID:         r.ID,
+		PurchaseID: r.PurchaseID,
+		Quantity:   r.Quantity,
+		Reason:     r.Reason,
+		Status:     string(r.Status),
+		CreatedAt:  r.CreatedAt.Time,
+	}
+	if r.RefundID.Valid {
+		refundID := r.RefundID.Int32
+		request.RefundID = &refundID
+	}
+	if r.ResolvedAt.Valid {
+		resolvedAt := r.ResolvedAt.Time
+		request.ResolvedAt = &resolvedAt
+	}
+	return request
+}
@@ -0,0 +1,310 @@
+package service_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/par1ram/merch-store/internal/db"
+	"github.com/par1ram/merch-store/internal/middleware"
+	"github.com/par1ram/merch-store/internal/repository"
+	"github.com/par1ram/merch-store/internal/service"
+	"github.com/par1ram/merch-store/internal/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockRefundRepository struct {
+	mock.Mock
+}
+
+func (m *MockRefundRepository) ExecTx(ctx context.Context, fn func(repository.RefundRepository) error) error {
+	args := m.Called(ctx, fn)
+	if err := args.Error(0); err != nil {
+		return err
+	}
+	return fn(m)
+}
+
+func (m *MockRefundRepository) GetPurchaseForUpdate(ctx context.Context, purchaseID int32) (db.GetPurchaseForUpdateRow, error) {
+	args := m.Called(ctx, purchaseID)
+	return args.Get(0).(db.GetPurchaseForUpdateRow), args.Error(1)
+}
+
+func (m *MockRefundRepository) GetRefundedQuantity(ctx context.Context, purchaseID int32) (int32, error) {
+	args := m.Called(ctx, purchaseID)
+	return args.Get(0).(int32), args.Error(1)
+}
+
+func (m *MockRefundRepository) LockBalance(ctx context.Context, userID int32) (int32, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(int32), args.Error(1)
+}
+
+func (m *MockRefundRepository) RemoveInventory(ctx context.Context, params db.RemoveInventoryParams) (int64, error) {
+	args := m.Called(ctx, params)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockRefundRepository) RestoreCoins(ctx context.Context, purchaseID, userID, amount int32) error {
+	args := m.Called(ctx, purchaseID, userID, amount)
+	return args.Error(0)
+}
+
+func (m *MockRefundRepository) CreateRefundTransaction(ctx context.Context, params db.CreateCoinTransactionRefundParams) (int32, error) {
+	args := m.Called(ctx, params)
+	return args.Get(0).(int32), args.Error(1)
+}
+
+func (m *MockRefundRepository) CancelOrder(ctx context.Context, purchaseID int32) error {
+	args := m.Called(ctx, purchaseID)
+	return args.Error(0)
+}
+
+func (m *MockRefundRepository) ReduceOrder(ctx context.Context, purchaseID, quantity int32) error {
+	args := m.Called(ctx, purchaseID, quantity)
+	return args.Error(0)
+}
+
+func (m *MockRefundRepository) ListPurchases(ctx context.Context, employeeID int32) ([]db.ListPurchasesByEmployeeRow, error) {
+	args := m.Called(ctx, employeeID)
+	if res, ok := args.Get(0).([]db.ListPurchasesByEmployeeRow); ok {
+		return res, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockRefundRepository) CreateRefundRequest(ctx context.Context, params db.CreateRefundRequestParams) (db.RefundRequest, error) {
+	args := m.Called(ctx, params)
+	return args.Get(0).(db.RefundRequest), args.Error(1)
+}
+
+func (m *MockRefundRepository) GetRefundRequestForUpdate(ctx context.Context, requestID int32) (db.RefundRequest, error) {
+	args := m.Called(ctx, requestID)
+	return args.Get(0).(db.RefundRequest), args.Error(1)
+}
+
+func (m *MockRefundRepository) ListRefundRequests(ctx context.Context, params db.ListRefundRequestsParams) ([]db.ListRefundRequestsRow, error) {
+	args := m.Called(ctx, params)
+	if res, ok := args.Get(0).([]db.ListRefundRequestsRow); ok {
+		return res, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockRefundRepository) ResolveRefundRequest(ctx context.Context, params db.ResolveRefundRequestParams) error {
+	args := m.Called(ctx, params)
+	return args.Error(0)
+}
+
+// purchaseStub — покупка трёх кружек по 20 монет сотрудником 123.
+func purchaseStub() db.GetPurchaseForUpdateRow {
+	return db.GetPurchaseForUpdateRow{
+		ID:             5,
+		FromEmployeeID: pgtype.Int4{Int32: 123, Valid: true},
+		MerchID:        pgtype.Int4{Int32: 2, Valid: true},
+		MerchName:      "cup",
+		Quantity:       3,
+		UnitPrice:      pgtype.Int4{Int32: 20, Valid: true},
+		Amount:         60,
+	}
+}
+
+func userCtx(userID float64) context.Context {
+	claims := jwt.MapClaims{"user_id": userID}
+	return context.WithValue(context.Background(), middleware.UserCtxKey, claims)
+}
+
+func TestRefundPurchase_PartialSuccess(t *testing.T) {
+	ctx := userCtx(1)
+
+	repoMock := new(MockRefundRepository)
+	repoMock.On("ExecTx", ctx, mock.AnythingOfType("func(repository.RefundRepository) error")).Return(nil).Once()
+	repoMock.On("GetPurchaseForUpdate", mock.Anything, int32(5)).Return(purchaseStub(), nil).Once()
+	repoMock.On("GetRefundedQuantity", mock.Anything, int32(5)).Return(int32(1), nil).Once()
+	// Покупатель блокируется раньше инвентаря — в том же порядке, что и при покупке.
+	lock := repoMock.On("LockBalance", mock.Anything, int32(123)).Return(int32(500), nil).Once()
+	repoMock.On("RemoveInventory", mock.Anything, db.RemoveInventoryParams{Quantity: 2, EmployeeID: 123, MerchID: 2}).
+		Return(int64(1), nil).Once().NotBefore(lock)
+	repoMock.On("RestoreCoins", mock.Anything, int32(5), int32(123), int32(40)).Return(nil).Once()
+	repoMock.On("CreateRefundTransaction", mock.Anything, db.CreateCoinTransactionRefundParams{
+		ToEmployeeID: 123,
+		MerchID:      2,
+		Amount:       40,
+		Quantity:     2,
+		UnitPrice:    20,
+		RefundOf:     5,
+	}).Return(int32(12), nil).Once()
+	repoMock.On("CancelOrder", mock.Anything, int32(5)).Return(nil).Once()
+
+	svc := service.NewRefundService(repoMock, utils.NewLogger())
+
+	// Нулевое количество — возврат всех оставшихся единиц; заказ отменяется.
+	result, err := svc.RefundPurchase(ctx, 5, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, service.RefundResult{RefundID: 12, PurchaseID: 5, Item: "cup", Quantity: 2, Amount: 40}, result)
+
+	repoMock.AssertExpectations(t)
+}
+
+func TestRefundPurchase_ExceedsRemaining(t *testing.T) {
+	ctx := userCtx(1)
+
+	repoMock := new(MockRefundRepository)
+	repoMock.On("ExecTx", ctx, mock.Anything).Return(nil).Once()
+	repoMock.On("GetPurchaseForUpdate", mock.Anything, int32(5)).Return(purchaseStub(), nil).Once()
+	repoMock.On("GetRefundedQuantity", mock.Anything, int32(5)).Return(int32(2), nil).Once()
+
+	svc := service.NewRefundService(repoMock, utils.NewLogger())
+	_, err := svc.RefundPurchase(ctx, 5, 2)
+	assert.ErrorIs(t, err, service.ErrRefundExceedsPurchase)
+	assert.ErrorIs(t, err, service.ErrBusinessValidation)
+
+	repoMock.AssertNotCalled(t, "RemoveInventory", mock.Anything, mock.Anything)
+	repoMock.AssertNotCalled(t, "RestoreCoins", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestRefundPurchase_ItemNotHeld(t *testing.T) {
+	ctx := userCtx(1)
+
+	repoMock := new(MockRefundRepository)
+	repoMock.On("ExecTx", ctx, mock.Anything).Return(nil).Once()
+	repoMock.On("GetPurchaseForUpdate", mock.Anything, int32(5)).Return(purchaseStub(), nil).Once()
+	repoMock.On("GetRefundedQuantity", mock.Anything, int32(5)).Return(int32(0), nil).Once()
+	repoMock.On("LockBalance", mock.Anything, int32(123)).Return(int32(500), nil).Once()
+	repoMock.On("RemoveInventory", mock.Anything, mock.Anything).Return(int64(0), nil).Once()
+
+	svc := service.NewRefundService(repoMock, utils.NewLogger())
+	_, err := svc.RefundPurchase(ctx, 5, 1)
+	assert.ErrorIs(t, err, service.ErrRefundItemNotHeld)
+
+	repoMock.AssertNotCalled(t, "RestoreCoins", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestRefundPurchase_NotFound(t *testing.T) {
+	ctx := userCtx(1)
+
+	repoMock := new(MockRefundRepository)
+	repoMock.On("ExecTx", ctx, mock.Anything).Return(nil).Once()
+	repoMock.On("GetPurchaseForUpdate", mock.Anything, int32(99)).
+		Return(db.GetPurchaseForUpdateRow{}, sql.ErrNoRows).Once()
+
+	svc := service.NewRefundService(repoMock, utils.NewLogger())
+	_, err := svc.RefundPurchase(ctx, 99, 1)
+	assert.ErrorIs(t, err, service.ErrPurchaseNotFound)
+
+	repoMock.AssertExpectations(t)
+}
+
+func TestRequestRefund_Success(t *testing.T) {
+	ctx := userCtx(123)
+
+	repoMock := new(MockRefundRepository)
+	repoMock.On("ExecTx", ctx, mock.Anything).Return(nil).Once()
+	repoMock.On("GetPurchaseForUpdate", mock.Anything, int32(5)).Return(purchaseStub(), nil).Once()
+	repoMock.On("GetRefundedQuantity", mock.Anything, int32(5)).Return(int32(0), nil).Once()
+	repoMock.On("CreateRefundRequest", mock.Anything, db.CreateRefundRequestParams{
+		PurchaseID: 5,
+		EmployeeID: 123,
+		Quantity:   1,
+		Reason:     "wrong size",
+	}).Return(db.RefundRequest{
+		ID:         8,
+		PurchaseID: 5,
+		EmployeeID: 123,
+		Quantity:   1,
+		Reason:     "wrong size",
+		Status:     db.RefundStatusEnumPending,
+	}, nil).Once()
+
+	svc := service.NewRefundService(repoMock, utils.NewLogger())
+	request, err := svc.RequestRefund(ctx, 5, 1, "  wrong size ")
+	assert.NoError(t, err)
+	assert.Equal(t, int32(8), request.ID)
+	assert.Equal(t, "cup", request.Item)
+	assert.Equal(t, service.RefundStatusPending, request.Status)
+
+	repoMock.AssertExpectations(t)
+}
+
+func TestRequestRefund_ForeignPurchase(t *testing.T) {
+	ctx := userCtx(456)
+
+	repoMock := new(MockRefundRepository)
+	repoMock.On("ExecTx", ctx, mock.Anything).Return(nil).Once()
+	repoMock.On("GetPurchaseForUpdate", mock.Anything, int32(5)).Return(purchaseStub(), nil).Once()
+	repoMock.On("GetRefundedQuantity", mock.Anything, int32(5)).Return(int32(0), nil).Once()
+
+	svc := service.NewRefundService(repoMock, utils.NewLogger())
+	_, err := svc.RequestRefund(ctx, 5, 1, "")
+	assert.ErrorIs(t, err, service.ErrPurchaseNotFound)
+
+	repoMock.AssertNotCalled(t, "CreateRefundRequest", mock.Anything, mock.Anything)
+}
+
+func TestApproveRefundRequest_Success(t *testing.T) {
+	ctx := userCtx(1)
+
+	repoMock := new(MockRefundRepository)
+	repoMock.On("ExecTx", ctx, mock.Anything).Return(nil).Once()
+	repoMock.On("GetRefundRequestForUpdate", mock.Anything, int32(8)).Return(db.RefundRequest{
+		ID:         8,
+		PurchaseID: 5,
+		EmployeeID: 123,
+		Quantity:   1,
+		Status:     db.RefundStatusEnumPending,
+	}, nil).Once()
+	repoMock.On("GetPurchaseForUpdate", mock.Anything, int32(5)).Return(purchaseStub(), nil).Once()
+	repoMock.On("GetRefundedQuantity", mock.Anything, int32(5)).Return(int32(0), nil).Once()
+	repoMock.On("LockBalance", mock.Anything, int32(123)).Return(int32(500), nil).Once()
+	repoMock.On("RemoveInventory", mock.Anything, db.RemoveInventoryParams{Quantity: 1, EmployeeID: 123, MerchID: 2}).
+		Return(int64(1), nil).Once()
+	repoMock.On("RestoreCoins", mock.Anything, int32(5), int32(123), int32(20)).Return(nil).Once()
+	repoMock.On("CreateRefundTransaction", mock.Anything, mock.Anything).Return(int32(13), nil).Once()
+	repoMock.On("ResolveRefundRequest", mock.Anything, db.ResolveRefundRequestParams{
+		ID:         8,
+		Status:     db.RefundStatusEnumApproved,
+		RefundID:   pgtype.Int4{Int32: 13, Valid: true},
+		ResolvedBy: 1,
+	}).Return(nil).Once()
+	repoMock.On("ReduceOrder", mock.Anything, int32(5), int32(1)).Return(nil).Once()
+
+	svc := service.NewRefundService(repoMock, utils.NewLogger())
+	result, err := svc.ApproveRefundRequest(ctx, 8)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(13), result.RefundID)
+	assert.Equal(t, int32(20), result.Amount)
+
+	repoMock.AssertExpectations(t)
+	// Возвращена одна из трёх единиц — заказ остаётся в силе с двумя единицами.
+	repoMock.AssertNotCalled(t, "CancelOrder", mock.Anything, mock.Anything)
+}
+
+func TestRejectRefundRequest_AlreadyResolved(t *testing.T) {
+	ctx := userCtx(1)
+
+	repoMock := new(MockRefundRepository)
+	repoMock.On("ExecTx", ctx, mock.Anything).Return(nil).Once()
+	repoMock.On("GetRefundRequestForUpdate", mock.Anything, int32(8)).Return(db.RefundRequest{
+		ID:     8,
+		Status: db.RefundStatusEnumApproved,
+	}, nil).Once()
+
+	svc := service.NewRefundService(repoMock, utils.NewLogger())
+	err := svc.RejectRefundRequest(ctx, 8)
+	assert.ErrorIs(t, err, service.ErrRefundRequestResolved)
+
+	repoMock.AssertNotCalled(t, "ResolveRefundRequest", mock.Anything, mock.Anything)
+}
+
+func TestListRefundRequests_InvalidStatus(t *testing.T) {
+	repoMock := new(MockRefundRepository)
+	svc := service.NewRefundService(repoMock, utils.NewLogger())
+
+	_, err := svc.ListRefundRequests(context.Background(), "lost")
+	assert.ErrorIs(t, err, service.ErrInvalidRefundStatus)
+
+	repoMock.AssertNotCalled(t, "ListRefundRequests", mock.Anything, mock.Anything)
+}
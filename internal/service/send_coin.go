@@ -2,8 +2,10 @@ package service
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/par1ram/merch-store/internal/middleware"
 	"github.com/par1ram/merch-store/internal/repository"
@@ -11,37 +13,76 @@ import (
 )
 
 var (
-	ErrBusinessValidation = errors.New("business validation error")
-	ErrSelfTransfer       = errors.New("self-transfer prohibited")
-	ErrInsufficientFunds  = errors.New("insufficient funds")
-	ErrRecipientNotFound  = errors.New("recipient not found")
+	ErrSelfTransfer      = newError(CodeSelfTransfer, KindInvalid, "self-transfer prohibited")
+	ErrInsufficientFunds = newError(CodeInsufficientFunds, KindInsufficientFunds, "insufficient funds")
+	ErrRecipientNotFound = newError(CodeRecipientNotFound, KindNotFound, "recipient not found")
+	ErrInvalidMessage    = newError(CodeInvalidMessage, KindInvalid, "message must not exceed 280 characters")
 )
 
+// defaultHoldTTL используется, если срок удержания перевода не задан.
+const defaultHoldTTL = 7 * 24 * time.Hour
+
+// SendCoinService переводит монеты другим сотрудникам. Необязательное
+// сообщение сохраняется вместе с переводом, хэштеги из него — отдельно.
+// Каждый перевод проверяется на лимиты исходящих переводов отправителя.
+// SendCoinPending вместо зачисления удерживает монеты до решения получателя.
 type SendCoinService interface {
-	SendCoin(ctx context.Context, toUser string, amount int32) error
+	SendCoin(ctx context.Context, toUser string, amount int32, message string) error
+	SendCoinPending(ctx context.Context, toUser string, amount int32, message string) (PendingTransfer, error)
+	SendCoinBatch(ctx context.Context, legs []TransferLeg, message string) ([]TransferLeg, error)
+	SendCoinSplit(ctx context.Context, toUsers []string, total int32, message string) ([]TransferLeg, error)
 }
 
 type sendCoinService struct {
-	repo   repository.SendCoinRepository
-	logger utils.Logger
+	repo    repository.SendCoinRepository
+	limits  TransferLimits
+	holdTTL time.Duration
+	logger  utils.Logger
 }
 
-func NewSendCoinService(repo repository.SendCoinRepository, logger utils.Logger) SendCoinService {
+// NewSendCoinService создаёт сервис переводов; limits — лимиты по умолчанию,
+// которые администратор может переопределить для отдельного сотрудника,
+// holdTTL — через сколько удержанный перевод возвращается отправителю.
+func NewSendCoinService(repo repository.SendCoinRepository, limits TransferLimits, holdTTL time.Duration, logger utils.Logger) SendCoinService {
+	if holdTTL <= 0 {
+		holdTTL = defaultHoldTTL
+	}
 	logger.WithFields(utils.LogFields{
-		"component": "send_coin_service",
+		"component":     "send_coin_service",
+		"daily_limit":   limits.Daily,
+		"monthly_limit": limits.Monthly,
+		"per_recipient": limits.PerRecipient,
+		"hold_ttl":      holdTTL.String(),
 	}).Info("SendCoinService initialized")
 
 	return &sendCoinService{
-		repo:   repo,
-		logger: logger,
+		repo:    repo,
+		limits:  limits,
+		holdTTL: holdTTL,
+		logger:  logger,
 	}
 }
 
-func (s *sendCoinService) SendCoin(ctx context.Context, toUser string, amount int32) error {
+func (s *sendCoinService) SendCoin(ctx context.Context, toUser string, amount int32, message string) error {
+	_, err := s.sendCoin(ctx, toUser, amount, message, false)
+	return err
+}
+
+// SendCoinPending списывает монеты отправителя на удержание. Получатель
+// принимает или отклоняет перевод через PendingTransferService; без решения
+// монеты возвращаются отправителю через holdTTL.
+func (s *sendCoinService) SendCoinPending(ctx context.Context, toUser string, amount int32, message string) (PendingTransfer, error) {
+	return s.sendCoin(ctx, toUser, amount, message, true)
+}
+
+// sendCoin выполняет перевод или, если pending, создаёт удержание. Проверки
+// получателя, баланса и лимитов одинаковы для обоих режимов.
+func (s *sendCoinService) sendCoin(ctx context.Context, toUser string, amount int32, message string, pending bool) (PendingTransfer, error) {
 	log := s.logger.WithFields(utils.LogFields{
 		"operation": "send_coin",
 		"to_user":   toUser,
 		"amount":    amount,
+		"pending":   pending,
 	})
 
 	senderID := middleware.GetUserIDFromContext(ctx)
@@ -49,21 +90,30 @@ func (s *sendCoinService) SendCoin(ctx context.Context, toUser string, amount in
 		log.Error("Authentication required", utils.LogFields{
 			"error": "missing_user_id",
 		})
-		return errors.New("authentication required")
+		return PendingTransfer{}, ErrUnauthenticated
 	}
 
+	memo, err := parseTransferMessage(message)
+	if err != nil {
+		return PendingTransfer{}, err
+	}
+
+	var held PendingTransfer
+
 	log = log.WithFields(utils.LogFields{"from_user_id": senderID})
 	log.Debug("Starting transaction")
 
-	err := s.repo.ExecTx(ctx, func(r repository.SendCoinRepository) error {
+	err = s.repo.ExecTx(ctx, func(r repository.SendCoinRepository) error {
 		recipient, err := r.GetRecipient(ctx, toUser)
 		if err != nil {
-			log.Error("Recipient lookup failed", utils.LogFields{
-				"error":      err,
-				"error_type": "recipient_not_found",
-				"recipient":  toUser,
-			})
-			return fmt.Errorf("%w: %v", ErrBusinessValidation, ErrRecipientNotFound)
+			if errors.Is(err, sql.ErrNoRows) {
+				log.Warn("Recipient not found", utils.LogFields{
+					"error_type": "recipient_not_found",
+					"recipient":  toUser,
+				})
+				return fmt.Errorf("%w: %w", ErrBusinessValidation, ErrRecipientNotFound)
+			}
+			return fmt.Errorf("failed to get recipient: %w", err)
 		}
 
 		if recipient.ID == int32(senderID) {
@@ -72,29 +122,52 @@ func (s *sendCoinService) SendCoin(ctx context.Context, toUser string, amount in
 				"sender_id":    senderID,
 				"recipient_id": recipient.ID,
 			})
-			return fmt.Errorf("%w: %v", ErrBusinessValidation, ErrSelfTransfer)
+			return fmt.Errorf("%w: %w", ErrBusinessValidation, ErrSelfTransfer)
 		}
 
-		balance, err := r.GetBalance(ctx, int32(senderID))
+		// Строки отправителя и получателя блокируются до конца транзакции:
+		// параллельный перевод или покупка не потратит проверенный баланс.
+		balances, err := r.LockBalances(ctx, int32(senderID), recipient.ID)
 		if err != nil {
-			log.Error("Balance check failed", utils.LogFields{
-				"error":      err,
-				"error_type": "balance_check_failed",
-				"user_id":    senderID,
-			})
-			return fmt.Errorf("internal server error")
+			return fmt.Errorf("failed to lock balances: %w", err)
 		}
 
+		balance := balances[int32(senderID)]
 		if balance < amount {
 			log.Warn("Insufficient funds", utils.LogFields{
 				"current_balance": balance,
 				"required_amount": amount,
 				"error_type":      "insufficient_funds",
 			})
-			return fmt.Errorf("%w: %v", ErrBusinessValidation, ErrInsufficientFunds)
+			return fmt.Errorf("%w: %w", ErrBusinessValidation, ErrInsufficientFunds)
+		}
+
+		limits, err := resolveTransferLimits(ctx, r, s.limits, int32(senderID))
+		if err != nil {
+			return err
+		}
+		if err := checkTransferLimits(ctx, r, limits, int32(senderID), recipient.ID, amount); err != nil {
+			log.Warn("Transfer limit exceeded", utils.LogFields{
+				"error":      err,
+				"error_type": "transfer_limit_exceeded",
+			})
+			return err
+		}
+
+		if pending {
+			hold, err := r.HoldCoins(ctx, int32(senderID), recipient.ID, amount, memo, time.Now().Add(s.holdTTL))
+			if err != nil {
+				if errors.Is(err, repository.ErrInsufficientBalance) {
+					return fmt.Errorf("%w: %w", ErrBusinessValidation, ErrInsufficientFunds)
+				}
+				return err
+			}
+			held = toPendingTransfer(hold, recipient.Username)
+			log.Info("Transfer held", utils.LogFields{"hold_id": hold.ID})
+			return nil
 		}
 
-		if err := r.TransferCoins(ctx, int32(senderID), recipient.ID, amount); err != nil {
+		if err := r.TransferCoins(ctx, int32(senderID), recipient.ID, amount, memo); err != nil {
 			log.Error("Transfer failed", utils.LogFields{
 				"error":           err,
 				"error_type":      "transfer_failure",
@@ -102,6 +175,10 @@ func (s *sendCoinService) SendCoin(ctx context.Context, toUser string, amount in
 				"recipient_id":    recipient.ID,
 				"transfer_amount": amount,
 			})
+			// Баланс мог измениться после проверки параллельной операцией.
+			if errors.Is(err, repository.ErrInsufficientBalance) {
+				return fmt.Errorf("%w: %w", ErrBusinessValidation, ErrInsufficientFunds)
+			}
 			return err
 		}
 
@@ -118,9 +195,9 @@ func (s *sendCoinService) SendCoin(ctx context.Context, toUser string, amount in
 			"error":      err,
 			"error_type": "transaction_failure",
 		})
-		return err
+		return PendingTransfer{}, err
 	}
 
 	log.Info("Transaction completed successfully")
-	return nil
+	return held, nil
 }
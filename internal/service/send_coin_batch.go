@@ -0,0 +1,170 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/par1ram/merch-store/internal/middleware"
+	"github.com/par1ram/merch-store/internal/repository"
+	"github.com/par1ram/merch-store/internal/utils"
+)
+
+// maxBatchRecipients ограничивает число получателей одного пакетного перевода.
+const maxBatchRecipients = 100
+
+var (
+	ErrInvalidBatchRecipients = newError(CodeInvalidBatchRecipients, KindInvalid, "recipients must be a non-empty list of up to 100 unique users")
+	ErrInvalidTransferAmount  = newError(CodeInvalidTransferAmount, KindInvalid, "each recipient must receive a positive amount")
+)
+
+// TransferLeg — часть пакетного перевода одному получателю.
+type TransferLeg struct {
+	ToUser string `json:"to_user"`
+	Amount int32  `json:"amount"`
+}
+
+// SendCoinBatch переводит монеты нескольким получателям. Все получатели
+// и общая сумма проверяются заранее, переводы выполняются в одной транзакции:
+// либо проходят все, либо ни одного. Лимиты проверяются для каждой части
+// с учётом предыдущих частей пакета.
+func (s *sendCoinService) SendCoinBatch(ctx context.Context, legs []TransferLeg, message string) ([]TransferLeg, error) {
+	log := s.logger.WithFields(utils.LogFields{
+		"operation":  "send_coin_batch",
+		"recipients": len(legs),
+	})
+
+	senderID := middleware.GetUserIDFromContext(ctx)
+	if senderID == 0 {
+		log.Error("Authentication required")
+		return nil, ErrUnauthenticated
+	}
+
+	total, err := validateTransferLegs(legs)
+	if err != nil {
+		return nil, err
+	}
+
+	memo, err := parseTransferMessage(message)
+	if err != nil {
+		return nil, err
+	}
+
+	log = log.WithFields(utils.LogFields{"from_user_id": senderID, "total": total})
+
+	err = s.repo.ExecTx(ctx, func(r repository.SendCoinRepository) error {
+		recipientIDs := make([]int32, len(legs))
+		for i, leg := range legs {
+			recipient, err := r.GetRecipient(ctx, leg.ToUser)
+			if err != nil {
+				if errors.Is(err, sql.ErrNoRows) {
+					return fmt.Errorf("%w: %w: %s", ErrBusinessValidation, ErrRecipientNotFound, leg.ToUser)
+				}
+				return fmt.Errorf("failed to get recipient: %w", err)
+			}
+			if recipient.ID == int32(senderID) {
+				return fmt.Errorf("%w: %w", ErrBusinessValidation, ErrSelfTransfer)
+			}
+			recipientIDs[i] = recipient.ID
+		}
+
+		balances, err := r.LockBalances(ctx, append(recipientIDs, int32(senderID))...)
+		if err != nil {
+			return fmt.Errorf("failed to lock balances: %w", err)
+		}
+		balance := balances[int32(senderID)]
+		if int64(balance) < total {
+			log.Warn("Insufficient funds", utils.LogFields{"current_balance": balance})
+			return fmt.Errorf("%w: %w", ErrBusinessValidation, ErrInsufficientFunds)
+		}
+
+		limits, err := resolveTransferLimits(ctx, r, s.limits, int32(senderID))
+		if err != nil {
+			return err
+		}
+
+		for i, leg := range legs {
+			if err := checkTransferLimits(ctx, r, limits, int32(senderID), recipientIDs[i], leg.Amount); err != nil {
+				log.Warn("Transfer limit exceeded", utils.LogFields{"error": err, "to_user": leg.ToUser})
+				return err
+			}
+			if err := r.TransferCoins(ctx, int32(senderID), recipientIDs[i], leg.Amount, memo); err != nil {
+				if errors.Is(err, repository.ErrInsufficientBalance) {
+					return fmt.Errorf("%w: %w", ErrBusinessValidation, ErrInsufficientFunds)
+				}
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		log.WithFields(utils.LogFields{"error": err}).Error("Batch transfer failed")
+		return nil, err
+	}
+
+	log.Info("Batch transfer completed")
+	return legs, nil
+}
+
+// SendCoinSplit делит total поровну между получателями и выполняет пакетный
+// перевод. Остаток от деления получают по одной монете первые получатели
+// списка, так что списывается ровно total.
+func (s *sendCoinService) SendCoinSplit(ctx context.Context, toUsers []string, total int32, message string) ([]TransferLeg, error) {
+	legs, err := splitEvenly(toUsers, total)
+	if err != nil {
+		return nil, err
+	}
+	return s.SendCoinBatch(ctx, legs, message)
+}
+
+func splitEvenly(toUsers []string, total int32) ([]TransferLeg, error) {
+	if len(toUsers) == 0 || len(toUsers) > maxBatchRecipients {
+		return nil, fmt.Errorf("%w: %w", ErrBusinessValidation, ErrInvalidBatchRecipients)
+	}
+	n := int32(len(toUsers))
+	if total < n {
+		return nil, fmt.Errorf("%w: %w", ErrBusinessValidation, ErrInvalidTransferAmount)
+	}
+
+	share, remainder := total/n, total%n
+	legs := make([]TransferLeg, len(toUsers))
+	for i, username := range toUsers {
+		legs[i] = TransferLeg{ToUser: username, Amount: share}
+		if int32(i) < remainder {
+			legs[i].Amount++
+		}
+	}
+	return legs, nil
+}
+
+// validateTransferLegs проверяет состав пакета и возвращает общую сумму.
+// Повтор получателя считается ошибкой, а не объединяется: иначе клиент
+// может не заметить, что одному человеку ушло больше задуманного.
+func validateTransferLegs(legs []TransferLeg) (int64, error) {
+	if len(legs) == 0 || len(legs) > maxBatchRecipients {
+		return 0, fmt.Errorf("%w: %w", ErrBusinessValidation, ErrInvalidBatchRecipients)
+	}
+
+	var total int64
+	seen := make(map[string]struct{}, len(legs))
+	for _, leg := range legs {
+		if leg.ToUser == "" {
+			return 0, fmt.Errorf("%w: %w", ErrBusinessValidation, ErrInvalidBatchRecipients)
+		}
+		if _, ok := seen[leg.ToUser]; ok {
+			return 0, fmt.Errorf("%w: %w: %s", ErrBusinessValidation, ErrInvalidBatchRecipients, leg.ToUser)
+		}
+		seen[leg.ToUser] = struct{}{}
+
+		if leg.Amount <= 0 {
+			return 0, fmt.Errorf("%w: %w: %s", ErrBusinessValidation, ErrInvalidTransferAmount, leg.ToUser)
+		}
+		total += int64(leg.Amount)
+	}
+	if total > math.MaxInt32 {
+		return 0, fmt.Errorf("%w: %w", ErrBusinessValidation, ErrInsufficientFunds)
+	}
+	return total, nil
+}
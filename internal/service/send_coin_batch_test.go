@@ -0,0 +1,125 @@
+package service_test
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/par1ram/merch-store/internal/db"
+	"github.com/par1ram/merch-store/internal/repository"
+	"github.com/par1ram/merch-store/internal/service"
+	"github.com/par1ram/merch-store/internal/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestSendCoinBatch_Success(t *testing.T) {
+	ctx := userCtx(123)
+	memo := repository.TransferMemo{Message: "sprint done #teamwork", Hashtags: []string{"teamwork"}}
+
+	mockRepo := new(MockSendCoinRepository)
+	mockRepo.On("ExecTx", ctx, mock.Anything).Return(nil).Once()
+	mockRepo.On("GetRecipient", ctx, "alice").Return(&db.Employee{ID: 1, Username: "alice"}, nil).Once()
+	mockRepo.On("GetRecipient", ctx, "bob").Return(&db.Employee{ID: 2, Username: "bob"}, nil).Once()
+	mockRepo.On("LockBalances", ctx, mock.Anything).Return(map[int32]int32{123: 50}, nil).Once()
+	mockRepo.On("GetTransferLimitOverride", ctx, int32(123)).Return(db.GetTransferLimitOverrideRow{}, nil).Once()
+	mockRepo.On("TransferCoins", ctx, int32(123), int32(1), int32(30), memo).Return(nil).Once()
+	mockRepo.On("TransferCoins", ctx, int32(123), int32(2), int32(20), memo).Return(nil).Once()
+
+	svc := service.NewSendCoinService(mockRepo, service.TransferLimits{}, 0, utils.NewLogger())
+	legs := []service.TransferLeg{{ToUser: "alice", Amount: 30}, {ToUser: "bob", Amount: 20}}
+	result, err := svc.SendCoinBatch(ctx, legs, "sprint done #teamwork")
+	assert.NoError(t, err)
+	assert.Equal(t, legs, result)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestSendCoinBatch_TotalExceedsBalance(t *testing.T) {
+	ctx := userCtx(123)
+
+	mockRepo := new(MockSendCoinRepository)
+	mockRepo.On("ExecTx", ctx, mock.Anything).Return(nil).Once()
+	mockRepo.On("GetRecipient", ctx, "alice").Return(&db.Employee{ID: 1, Username: "alice"}, nil).Once()
+	mockRepo.On("GetRecipient", ctx, "bob").Return(&db.Employee{ID: 2, Username: "bob"}, nil).Once()
+	mockRepo.On("LockBalances", ctx, mock.Anything).Return(map[int32]int32{123: 40}, nil).Once()
+
+	svc := service.NewSendCoinService(mockRepo, service.TransferLimits{}, 0, utils.NewLogger())
+	_, err := svc.SendCoinBatch(ctx, []service.TransferLeg{{ToUser: "alice", Amount: 30}, {ToUser: "bob", Amount: 20}}, "")
+	assert.ErrorIs(t, err, service.ErrInsufficientFunds)
+
+	mockRepo.AssertNotCalled(t, "TransferCoins", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestSendCoinBatch_UnknownRecipient(t *testing.T) {
+	ctx := userCtx(123)
+
+	mockRepo := new(MockSendCoinRepository)
+	mockRepo.On("ExecTx", ctx, mock.Anything).Return(nil).Once()
+	mockRepo.On("GetRecipient", ctx, "alice").Return(&db.Employee{ID: 1, Username: "alice"}, nil).Once()
+	mockRepo.On("GetRecipient", ctx, "ghost").Return(nil, sql.ErrNoRows).Once()
+
+	svc := service.NewSendCoinService(mockRepo, service.TransferLimits{}, 0, utils.NewLogger())
+	_, err := svc.SendCoinBatch(ctx, []service.TransferLeg{{ToUser: "alice", Amount: 30}, {ToUser: "ghost", Amount: 20}}, "")
+	assert.ErrorIs(t, err, service.ErrRecipientNotFound)
+	assert.Contains(t, err.Error(), "ghost")
+
+	mockRepo.AssertNotCalled(t, "LockBalances", mock.Anything, mock.Anything)
+}
+
+func TestSendCoinBatch_SelfInList(t *testing.T) {
+	ctx := userCtx(123)
+
+	mockRepo := new(MockSendCoinRepository)
+	mockRepo.On("ExecTx", ctx, mock.Anything).Return(nil).Once()
+	mockRepo.On("GetRecipient", ctx, "me").Return(&db.Employee{ID: 123, Username: "me"}, nil).Once()
+
+	svc := service.NewSendCoinService(mockRepo, service.TransferLimits{}, 0, utils.NewLogger())
+	_, err := svc.SendCoinBatch(ctx, []service.TransferLeg{{ToUser: "me", Amount: 10}}, "")
+	assert.ErrorIs(t, err, service.ErrSelfTransfer)
+}
+
+func TestSendCoinBatch_InvalidLegs(t *testing.T) {
+	ctx := userCtx(123)
+	svc := service.NewSendCoinService(new(MockSendCoinRepository), service.TransferLimits{}, 0, utils.NewLogger())
+
+	_, err := svc.SendCoinBatch(ctx, nil, "")
+	assert.ErrorIs(t, err, service.ErrInvalidBatchRecipients)
+
+	_, err = svc.SendCoinBatch(ctx, []service.TransferLeg{{ToUser: "alice", Amount: 10}, {ToUser: "alice", Amount: 5}}, "")
+	assert.ErrorIs(t, err, service.ErrInvalidBatchRecipients)
+
+	_, err = svc.SendCoinBatch(ctx, []service.TransferLeg{{ToUser: "alice", Amount: 0}}, "")
+	assert.ErrorIs(t, err, service.ErrInvalidTransferAmount)
+}
+
+func TestSendCoinSplit_DistributesRemainder(t *testing.T) {
+	ctx := userCtx(123)
+
+	mockRepo := new(MockSendCoinRepository)
+	mockRepo.On("ExecTx", ctx, mock.Anything).Return(nil).Once()
+	mockRepo.On("GetRecipient", ctx, "alice").Return(&db.Employee{ID: 1, Username: "alice"}, nil).Once()
+	mockRepo.On("GetRecipient", ctx, "bob").Return(&db.Employee{ID: 2, Username: "bob"}, nil).Once()
+	mockRepo.On("GetRecipient", ctx, "carol").Return(&db.Employee{ID: 3, Username: "carol"}, nil).Once()
+	mockRepo.On("LockBalances", ctx, mock.Anything).Return(map[int32]int32{123: 1000}, nil).Once()
+	mockRepo.On("GetTransferLimitOverride", ctx, int32(123)).Return(db.GetTransferLimitOverrideRow{}, nil).Once()
+	mockRepo.On("TransferCoins", ctx, int32(123), int32(1), int32(34), repository.TransferMemo{}).Return(nil).Once()
+	mockRepo.On("TransferCoins", ctx, int32(123), int32(2), int32(33), repository.TransferMemo{}).Return(nil).Once()
+	mockRepo.On("TransferCoins", ctx, int32(123), int32(3), int32(33), repository.TransferMemo{}).Return(nil).Once()
+
+	svc := service.NewSendCoinService(mockRepo, service.TransferLimits{}, 0, utils.NewLogger())
+	legs, err := svc.SendCoinSplit(ctx, []string{"alice", "bob", "carol"}, 100, "")
+	assert.NoError(t, err)
+	assert.Equal(t, []service.TransferLeg{
+		{ToUser: "alice", Amount: 34},
+		{ToUser: "bob", Amount: 33},
+		{ToUser: "carol", Amount: 33},
+	}, legs)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestSendCoinSplit_TotalTooSmall(t *testing.T) {
+	svc := service.NewSendCoinService(new(MockSendCoinRepository), service.TransferLimits{}, 0, utils.NewLogger())
+	_, err := svc.SendCoinSplit(userCtx(123), []string{"alice", "bob", "carol"}, 2, "")
+	assert.ErrorIs(t, err, service.ErrInvalidTransferAmount)
+}
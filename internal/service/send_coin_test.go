@@ -2,15 +2,21 @@ package service_test
 
 import (
 	"context"
+	"database/sql"
 	"errors"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/golang-jwt/jwt/v4"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/par1ram/merch-store/internal/db"
 	"github.com/par1ram/merch-store/internal/middleware"
 	"github.com/par1ram/merch-store/internal/repository"
 	"github.com/par1ram/merch-store/internal/service"
 	"github.com/par1ram/merch-store/internal/utils"
+	"github.com/pashagolub/pgxmock/v4"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -26,7 +32,7 @@ func (m *MockSendCoinRepository) ExecTx(ctx context.Context, fn func(repository.
 		return err
 	}
 	// Вызываем колбэк на том же самом объекте (m),
-	// чтобы методы TransferCoins, GetBalance и т.п. вызывались на нем же.
+	// чтобы методы TransferCoins, LockBalances и т.п. вызывались на нем же.
 	return fn(m)
 }
 
@@ -41,18 +47,36 @@ func (m *MockSendCoinRepository) GetRecipient(ctx context.Context, username stri
 	return val.(*db.Employee), args.Error(1)
 }
 
-// GetBalance — возвращает int32 (баланс) и ошибку
-func (m *MockSendCoinRepository) GetBalance(ctx context.Context, userID int32) (int32, error) {
-	args := m.Called(ctx, userID)
-	return args.Get(0).(int32), args.Error(1)
+// LockBalances — возвращает балансы заблокированных сотрудников и ошибку
+func (m *MockSendCoinRepository) LockBalances(ctx context.Context, userIDs ...int32) (map[int32]int32, error) {
+	args := m.Called(ctx, userIDs)
+	if balances, ok := args.Get(0).(map[int32]int32); ok {
+		return balances, args.Error(1)
+	}
+	return nil, args.Error(1)
 }
 
 // TransferCoins — имитируем успешный или неуспешный перевод
-func (m *MockSendCoinRepository) TransferCoins(ctx context.Context, fromUserID, toUserID, amount int32) error {
-	args := m.Called(ctx, fromUserID, toUserID, amount)
+func (m *MockSendCoinRepository) TransferCoins(ctx context.Context, fromUserID, toUserID, amount int32, memo repository.TransferMemo) error {
+	args := m.Called(ctx, fromUserID, toUserID, amount, memo)
 	return args.Error(0)
 }
 
+func (m *MockSendCoinRepository) GetTransferLimitOverride(ctx context.Context, userID int32) (db.GetTransferLimitOverrideRow, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(db.GetTransferLimitOverrideRow), args.Error(1)
+}
+
+func (m *MockSendCoinRepository) GetOutgoingTransferTotals(ctx context.Context, params db.GetOutgoingTransferTotalsParams) (db.GetOutgoingTransferTotalsRow, error) {
+	args := m.Called(ctx, params)
+	return args.Get(0).(db.GetOutgoingTransferTotalsRow), args.Error(1)
+}
+
+func (m *MockSendCoinRepository) HoldCoins(ctx context.Context, fromUserID, toUserID, amount int32, memo repository.TransferMemo, expiresAt time.Time) (db.TransferHold, error) {
+	args := m.Called(ctx, fromUserID, toUserID, amount, memo, expiresAt)
+	return args.Get(0).(db.TransferHold), args.Error(1)
+}
+
 // TestSendCoinService_Success — проверяем успешный сценарий перевода.
 func TestSendCoinService_Success(t *testing.T) {
 	// Создаем мок-репозиторий
@@ -77,18 +101,23 @@ func TestSendCoinService_Success(t *testing.T) {
 		Return(&db.Employee{ID: 999, Username: "alice"}, nil).
 		Once()
 
-	// 3) GetBalance
-	mockRepo.On("GetBalance", mock.Anything, int32(senderID)).
-		Return(int32(200), nil).
+	// 3) LockBalances — строки отправителя и получателя блокируются вместе
+	mockRepo.On("LockBalances", mock.Anything, []int32{int32(senderID), 999}).
+		Return(map[int32]int32{int32(senderID): 200, 999: 0}, nil).
+		Once()
+
+	// 4) GetTransferLimitOverride — переопределений нет, лимиты по умолчанию отключены
+	mockRepo.On("GetTransferLimitOverride", mock.Anything, int32(senderID)).
+		Return(db.GetTransferLimitOverrideRow{}, nil).
 		Once()
 
-	// 4) TransferCoins
-	mockRepo.On("TransferCoins", mock.Anything, int32(senderID), int32(999), amount).
+	// 5) TransferCoins
+	mockRepo.On("TransferCoins", mock.Anything, int32(senderID), int32(999), amount, repository.TransferMemo{}).
 		Return(nil).
 		Once()
 
-	svc := service.NewSendCoinService(mockRepo, logger)
-	err := svc.SendCoin(ctx, toUser, amount)
+	svc := service.NewSendCoinService(mockRepo, service.TransferLimits{}, 0, logger)
+	err := svc.SendCoin(ctx, toUser, amount, "")
 	assert.NoError(t, err)
 
 	mockRepo.AssertExpectations(t)
@@ -102,18 +131,18 @@ func TestSendCoinService_AuthRequired(t *testing.T) {
 	// Контекст без user_id
 	ctx := context.Background()
 
-	svc := service.NewSendCoinService(mockRepo, logger)
-	err := svc.SendCoin(ctx, "alice", 50)
+	svc := service.NewSendCoinService(mockRepo, service.TransferLimits{}, 0, logger)
+	err := svc.SendCoin(ctx, "alice", 50, "")
 	assert.Error(t, err)
-	assert.Equal(t, "authentication required", err.Error())
+	assert.ErrorIs(t, err, service.ErrUnauthenticated)
 
 	// Проверим, что ExecTx и др. методы не вызывались
 	mockRepo.AssertNotCalled(t, "ExecTx", mock.Anything, mock.Anything)
 	mockRepo.AssertNotCalled(t, "GetRecipient", mock.Anything, mock.Anything)
 }
 
-// TestSendCoinService_RecipientNotFound — если repo.GetRecipient вернёт ошибку,
-// сервис должен вернуть бизнес-ошибку c ErrRecipientNotFound.
+// TestSendCoinService_RecipientNotFound — если repo.GetRecipient не нашёл
+// сотрудника, сервис должен вернуть бизнес-ошибку c ErrRecipientNotFound.
 func TestSendCoinService_RecipientNotFound(t *testing.T) {
 	mockRepo := new(MockSendCoinRepository)
 	logger := utils.NewLogger()
@@ -123,13 +152,13 @@ func TestSendCoinService_RecipientNotFound(t *testing.T) {
 
 	// GetRecipient вернет ошибку
 	mockRepo.On("GetRecipient", mock.Anything, "bob").
-		Return((*db.Employee)(nil), errors.New("no rows found")).Once()
+		Return((*db.Employee)(nil), sql.ErrNoRows).Once()
 
 	claims := jwt.MapClaims{"user_id": float64(111)}
 	ctx := context.WithValue(context.Background(), middleware.UserCtxKey, claims)
 
-	svc := service.NewSendCoinService(mockRepo, logger)
-	err := svc.SendCoin(ctx, "bob", 30)
+	svc := service.NewSendCoinService(mockRepo, service.TransferLimits{}, 0, logger)
+	err := svc.SendCoin(ctx, "bob", 30, "")
 	assert.Error(t, err)
 
 	// У нас код делает:
@@ -155,8 +184,8 @@ func TestSendCoinService_SelfTransfer(t *testing.T) {
 	claims := jwt.MapClaims{"user_id": float64(444)} // senderID = 444
 	ctx := context.WithValue(context.Background(), middleware.UserCtxKey, claims)
 
-	svc := service.NewSendCoinService(mockRepo, logger)
-	err := svc.SendCoin(ctx, "john", 10)
+	svc := service.NewSendCoinService(mockRepo, service.TransferLimits{}, 0, logger)
+	err := svc.SendCoin(ctx, "john", 10, "")
 	assert.Error(t, err)
 	// Сервис выдаёт "...: self-transfer prohibited"
 	assert.Contains(t, err.Error(), "self-transfer")
@@ -164,9 +193,28 @@ func TestSendCoinService_SelfTransfer(t *testing.T) {
 	mockRepo.AssertExpectations(t)
 }
 
-// TestSendCoinService_GetBalanceError — если repo.GetBalance вернёт ошибку,
-// сервис возвращает "internal server error".
-func TestSendCoinService_GetBalanceError(t *testing.T) {
+// TestSendCoinService_RecipientLookupError — сбой БД при поиске получателя
+// не выдаётся за отсутствие получателя.
+func TestSendCoinService_RecipientLookupError(t *testing.T) {
+	mockRepo := new(MockSendCoinRepository)
+
+	mockRepo.On("ExecTx", mock.Anything, mock.AnythingOfType("func(repository.SendCoinRepository) error")).
+		Return(nil).Once()
+	dbErr := errors.New("connection reset")
+	mockRepo.On("GetRecipient", mock.Anything, "bob").Return(nil, dbErr).Once()
+
+	svc := service.NewSendCoinService(mockRepo, service.TransferLimits{}, 0, utils.NewLogger())
+	err := svc.SendCoin(userCtx(111), "bob", 30, "")
+	assert.ErrorIs(t, err, dbErr)
+	assert.NotErrorIs(t, err, service.ErrRecipientNotFound)
+	assert.NotErrorIs(t, err, service.ErrBusinessValidation)
+
+	mockRepo.AssertNotCalled(t, "LockBalances", mock.Anything, mock.Anything)
+}
+
+// TestSendCoinService_LockBalancesError — ошибка repo.LockBalances
+// возвращается обёрнутой, без потери исходной ошибки БД.
+func TestSendCoinService_LockBalancesError(t *testing.T) {
 	mockRepo := new(MockSendCoinRepository)
 	logger := utils.NewLogger()
 
@@ -175,19 +223,72 @@ func TestSendCoinService_GetBalanceError(t *testing.T) {
 
 	mockRepo.On("GetRecipient", mock.Anything, "alice").
 		Return(&db.Employee{ID: 999, Username: "alice"}, nil).Once()
-	// GetBalance вернёт ошибку
-	mockRepo.On("GetBalance", mock.Anything, int32(123)).
-		Return(int32(0), errors.New("some DB error")).Once()
+	// LockBalances вернёт ошибку
+	dbErr := errors.New("some DB error")
+	mockRepo.On("LockBalances", mock.Anything, []int32{123, 999}).
+		Return(nil, dbErr).Once()
 
 	claims := jwt.MapClaims{"user_id": float64(123)}
 	ctx := context.WithValue(context.Background(), middleware.UserCtxKey, claims)
 
-	svc := service.NewSendCoinService(mockRepo, logger)
-	err := svc.SendCoin(ctx, "alice", 50)
-	assert.Error(t, err)
-	assert.Equal(t, "internal server error", err.Error())
+	svc := service.NewSendCoinService(mockRepo, service.TransferLimits{}, 0, logger)
+	err := svc.SendCoin(ctx, "alice", 50, "")
+	assert.ErrorIs(t, err, dbErr)
+	assert.NotErrorIs(t, err, service.ErrBusinessValidation)
+
+	mockRepo.AssertExpectations(t)
+}
+
+// retryingSendCoinRepository выполняет ExecTx через настоящий TxRunner,
+// чтобы ошибки репозитория проходили через его проверку SQLSTATE.
+type retryingSendCoinRepository struct {
+	*MockSendCoinRepository
+	runner repository.TxRunner
+}
+
+func (r *retryingSendCoinRepository) ExecTx(ctx context.Context, fn func(repository.SendCoinRepository) error) error {
+	return r.runner.Run(ctx, func(pgx.Tx) error {
+		return fn(r.MockSendCoinRepository)
+	})
+}
+
+// TestSendCoinService_LockBalancesDeadlockRetried — взаимная блокировка при
+// блокировке балансов доходит до TxRunner, и перевод повторяется целиком.
+func TestSendCoinService_LockBalancesDeadlockRetried(t *testing.T) {
+	mockPool, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mockPool.Close()
+
+	mockPool.ExpectBegin()
+	mockPool.ExpectRollback()
+	mockPool.ExpectBegin()
+	mockPool.ExpectCommit()
+
+	mockRepo := new(MockSendCoinRepository)
+	mockRepo.On("GetRecipient", mock.Anything, "alice").
+		Return(&db.Employee{ID: 999, Username: "alice"}, nil).Twice()
+	mockRepo.On("LockBalances", mock.Anything, []int32{123, 999}).
+		Return(nil, &pgconn.PgError{Code: "40P01"}).Once()
+	mockRepo.On("LockBalances", mock.Anything, []int32{123, 999}).
+		Return(map[int32]int32{123: 200, 999: 0}, nil).Once()
+	mockRepo.On("GetTransferLimitOverride", mock.Anything, int32(123)).
+		Return(db.GetTransferLimitOverrideRow{}, nil).Once()
+	mockRepo.On("TransferCoins", mock.Anything, int32(123), int32(999), int32(50), repository.TransferMemo{}).
+		Return(nil).Once()
+
+	runner := repository.NewTxRunner(mockPool, repository.TxConfig{
+		MaxAttempts: 3,
+		BaseDelay:   time.Millisecond,
+		MaxDelay:    time.Millisecond,
+	}, utils.NewLogger())
+	repo := &retryingSendCoinRepository{MockSendCoinRepository: mockRepo, runner: runner}
+
+	svc := service.NewSendCoinService(repo, service.TransferLimits{}, 0, utils.NewLogger())
+	err = svc.SendCoin(userCtx(123), "alice", 50, "")
+	assert.NoError(t, err)
 
 	mockRepo.AssertExpectations(t)
+	assert.NoError(t, mockPool.ExpectationsWereMet())
 }
 
 // TestSendCoinService_InsufficientFunds — если balance < amount,
@@ -203,16 +304,17 @@ func TestSendCoinService_InsufficientFunds(t *testing.T) {
 		Return(&db.Employee{ID: 999, Username: "bob"}, nil).
 		Once()
 
-	mockRepo.On("GetBalance", mock.Anything, int32(123)).
-		Return(int32(40), nil).Once()
+	mockRepo.On("LockBalances", mock.Anything, []int32{123, 999}).
+		Return(map[int32]int32{123: 40, 999: 0}, nil).Once()
 
 	claims := jwt.MapClaims{"user_id": float64(123)}
 	ctx := context.WithValue(context.Background(), middleware.UserCtxKey, claims)
 
-	svc := service.NewSendCoinService(mockRepo, logger)
-	err := svc.SendCoin(ctx, "bob", 50)
+	svc := service.NewSendCoinService(mockRepo, service.TransferLimits{}, 0, logger)
+	err := svc.SendCoin(ctx, "bob", 50, "")
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "insufficient funds")
+	assert.ErrorIs(t, err, service.ErrInsufficientFunds)
 
 	mockRepo.AssertExpectations(t)
 }
@@ -227,20 +329,59 @@ func TestSendCoinService_TransferError(t *testing.T) {
 
 	mockRepo.On("GetRecipient", mock.Anything, "bob").
 		Return(&db.Employee{ID: 999, Username: "bob"}, nil).Once()
-	mockRepo.On("GetBalance", mock.Anything, int32(123)).
-		Return(int32(100), nil).Once()
+	mockRepo.On("LockBalances", mock.Anything, []int32{123, 999}).
+		Return(map[int32]int32{123: 100, 999: 0}, nil).Once()
 
-	mockRepo.On("TransferCoins", mock.Anything, int32(123), int32(999), int32(50)).
+	mockRepo.On("GetTransferLimitOverride", mock.Anything, int32(123)).Return(db.GetTransferLimitOverrideRow{}, nil).Once()
+	mockRepo.On("TransferCoins", mock.Anything, int32(123), int32(999), int32(50), repository.TransferMemo{}).
 		Return(errors.New("updateEmployeeCoins failed")).
 		Once()
 
 	claims := jwt.MapClaims{"user_id": float64(123)}
 	ctx := context.WithValue(context.Background(), middleware.UserCtxKey, claims)
 
-	svc := service.NewSendCoinService(mockRepo, logger)
-	err := svc.SendCoin(ctx, "bob", 50)
+	svc := service.NewSendCoinService(mockRepo, service.TransferLimits{}, 0, logger)
+	err := svc.SendCoin(ctx, "bob", 50, "")
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "updateEmployeeCoins failed")
 
 	mockRepo.AssertExpectations(t)
 }
+
+// TestSendCoinService_WithMessage — сообщение очищается, хэштеги сохраняются
+// в нижнем регистре без повторов.
+func TestSendCoinService_WithMessage(t *testing.T) {
+	mockRepo := new(MockSendCoinRepository)
+
+	claims := jwt.MapClaims{"user_id": float64(123)}
+	ctx := context.WithValue(context.Background(), middleware.UserCtxKey, claims)
+
+	mockRepo.On("ExecTx", ctx, mock.Anything).Return(nil).Once()
+	mockRepo.On("GetRecipient", mock.Anything, "alice").Return(&db.Employee{ID: 999, Username: "alice"}, nil).Once()
+	mockRepo.On("LockBalances", mock.Anything, mock.Anything).Return(map[int32]int32{123: 200}, nil).Once()
+	mockRepo.On("GetTransferLimitOverride", mock.Anything, int32(123)).Return(db.GetTransferLimitOverrideRow{}, nil).Once()
+	mockRepo.On("TransferCoins", mock.Anything, int32(123), int32(999), int32(50), repository.TransferMemo{
+		Message:  "Thanks for the help! #TeamWork #help C#sharp #teamwork",
+		Hashtags: []string{"teamwork", "help"},
+	}).Return(nil).Once()
+
+	svc := service.NewSendCoinService(mockRepo, service.TransferLimits{}, 0, utils.NewLogger())
+	err := svc.SendCoin(ctx, "alice", 50, "  Thanks for the help!\n\t#TeamWork\u202e #help C#sharp #teamwork\x00 ")
+	assert.NoError(t, err)
+
+	mockRepo.AssertExpectations(t)
+}
+
+// TestSendCoinService_MessageTooLong — слишком длинное сообщение отклоняется до начала транзакции.
+func TestSendCoinService_MessageTooLong(t *testing.T) {
+	mockRepo := new(MockSendCoinRepository)
+
+	claims := jwt.MapClaims{"user_id": float64(123)}
+	ctx := context.WithValue(context.Background(), middleware.UserCtxKey, claims)
+
+	svc := service.NewSendCoinService(mockRepo, service.TransferLimits{}, 0, utils.NewLogger())
+	err := svc.SendCoin(ctx, "alice", 50, strings.Repeat("я", 281))
+	assert.ErrorIs(t, err, service.ErrInvalidMessage)
+
+	mockRepo.AssertNotCalled(t, "ExecTx", mock.Anything, mock.Anything)
+}
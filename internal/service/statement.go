@@ -0,0 +1,235 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/par1ram/merch-store/internal/db"
+	"github.com/par1ram/merch-store/internal/middleware"
+	"github.com/par1ram/merch-store/internal/repository"
+	"github.com/par1ram/merch-store/internal/utils"
+)
+
+// statementPeriodLayout — формат месяца выписки в запросах.
+const statementPeriodLayout = "2006-01"
+
+// StatementTypeSignup — строка выписки со стартовыми монетами сотрудника,
+// зарегистрированного внутри периода. В coin_transactions такой записи нет.
+const StatementTypeSignup = "signup"
+
+var (
+	ErrInvalidStatementPeriod = newError(CodeInvalidStatementPeriod, KindInvalid, "period must be a past or current month in YYYY-MM format")
+	ErrStatementNotFound      = newError(CodeStatementNotFound, KindNotFound, "no statement for the period")
+)
+
+// StatementLine — операция выписки и баланс сотрудника сразу после неё.
+type StatementLine struct {
+	HistoryEntry
+	Balance int64
+}
+
+// Change возвращает изменение баланса: зачисления положительны, списания отрицательны.
+func (l StatementLine) Change() int64 {
+	if l.Direction == HistoryDirectionIncoming {
+		return int64(l.Amount)
+	}
+	return -int64(l.Amount)
+}
+
+// Statement — выписка сотрудника за месяц [From, To): входящий остаток, все
+// операции в хронологическом порядке и исходящий остаток. Closing всегда равен
+// Opening плюс сумма изменений по операциям. У сотрудника, зарегистрированного
+// внутри периода, Opening равен нулю, а стартовые монеты — первая строка
+// с типом StatementTypeSignup.
+type Statement struct {
+	EmployeeID int32
+	Username   string
+	Period     string
+	From       time.Time
+	To         time.Time
+	Opening    int64
+	Closing    int64
+	Lines      []StatementLine
+}
+
+// StatementService строит месячные выписки по coin_transactions. Период —
+// календарный месяц в UTC в формате YYYY-MM; будущие месяцы недопустимы, для
+// текущего месяца исходящий остаток равен текущему балансу.
+type StatementService interface {
+	// GetStatement возвращает выписку текущего пользователя.
+	GetStatement(ctx context.Context, period string) (Statement, error)
+	// ListStatements возвращает выписки всех сотрудников, зарегистрированных до
+	// конца периода, упорядоченные по id. Доступ проверяет вызывающая сторона.
+	ListStatements(ctx context.Context, period string) ([]Statement, error)
+}
+
+type statementService struct {
+	repo   repository.StatementRepository
+	logger utils.Logger
+}
+
+func NewStatementService(repo repository.StatementRepository, logger utils.Logger) StatementService {
+	logger.WithFields(utils.LogFields{"component": "statement_service"}).Info("StatementService initialized")
+	return &statementService{
+		repo:   repo,
+		logger: logger.WithFields(utils.LogFields{"component": "statement_service"}),
+	}
+}
+
+func (s *statementService) GetStatement(ctx context.Context, period string) (Statement, error) {
+	userID := middleware.GetUserIDFromContext(ctx)
+	log := s.logger.WithFields(utils.LogFields{
+		"operation": "get_statement",
+		"user_id":   userID,
+		"period":    period,
+	})
+	if userID == 0 {
+		log.Error("User not authenticated")
+		return Statement{}, ErrUnauthenticated
+	}
+
+	statements, err := s.build(ctx, log, period, pgtype.Int4{Int32: int32(userID), Valid: true})
+	if err != nil {
+		return Statement{}, err
+	}
+	if len(statements) == 0 {
+		log.Warn("Employee registered after the statement period")
+		return Statement{}, fmt.Errorf("%w: %w", ErrBusinessValidation, ErrStatementNotFound)
+	}
+	return statements[0], nil
+}
+
+func (s *statementService) ListStatements(ctx context.Context, period string) ([]Statement, error) {
+	log := s.logger.WithFields(utils.LogFields{
+		"operation": "list_statements",
+		"user_id":   middleware.GetUserIDFromContext(ctx),
+		"period":    period,
+	})
+
+	statements, err := s.build(ctx, log, period, pgtype.Int4{})
+	if err != nil {
+		return nil, err
+	}
+	log.WithFields(utils.LogFields{"statements": len(statements)}).Info("Statements generated")
+	return statements, nil
+}
+
+// build собирает выписки за период для одного сотрудника или, если employeeID
+// не задан, для всех. Операции приходят упорядоченными по сотруднику и времени,
+// поэтому текущий баланс считается одним проходом.
+func (s *statementService) build(ctx context.Context, log utils.Logger, period string, employeeID pgtype.Int4) ([]Statement, error) {
+	from, err := parseStatementPeriod(period, time.Now())
+	if err != nil {
+		log.Warn("Invalid statement period")
+		return nil, err
+	}
+	to := from.AddDate(0, 1, 0)
+	start := pgtype.Timestamptz{Time: from, Valid: true}
+	end := pgtype.Timestamptz{Time: to, Valid: true}
+
+	// Остатки и операции читаются из одного снимка: операция, зафиксированная
+	// между двумя запросами, иначе попала бы в строки, но не в входящий остаток.
+	var (
+		balances []db.ListStatementBalancesRow
+		entries  []db.ListStatementEntriesRow
+	)
+	err = s.repo.ExecTx(ctx, func(r repository.StatementRepository) error {
+		var err error
+		balances, err = r.ListStatementBalances(ctx, db.ListStatementBalancesParams{
+			PeriodStart: start,
+			PeriodEnd:   end,
+			EmployeeID:  employeeID,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to list statement balances: %w", err)
+		}
+		entries, err = r.ListStatementEntries(ctx, db.ListStatementEntriesParams{
+			PeriodStart: start,
+			PeriodEnd:   end,
+			EmployeeID:  employeeID,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to list statement entries: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		log.WithFields(utils.LogFields{"error": err}).Error("Failed to read statement data")
+		return nil, err
+	}
+
+	statements := make([]Statement, len(balances))
+	index := make(map[int32]int, len(balances))
+	for i, b := range balances {
+		statements[i] = Statement{
+			EmployeeID: b.ID,
+			Username:   b.Username,
+			Period:     from.Format(statementPeriodLayout),
+			From:       from,
+			To:         to,
+			Opening:    b.OpeningBalance,
+			Closing:    b.OpeningBalance,
+			Lines:      []StatementLine{},
+		}
+		if !b.CreatedAt.Time.Before(from) {
+			statements[i].Opening = 0
+			if b.OpeningBalance != 0 {
+				statements[i].Lines = append(statements[i].Lines, signupLine(b))
+			}
+		}
+		index[b.ID] = i
+	}
+	for _, e := range entries {
+		i, ok := index[e.EmployeeID]
+		if !ok {
+			continue
+		}
+		line := StatementLine{HistoryEntry: historyEntryFromActivity(repository.CoinActivity{
+			ID:           e.ID,
+			Type:         string(e.TransactionType),
+			Incoming:     e.Incoming,
+			Amount:       e.Amount,
+			Counterparty: e.Counterparty,
+			Item:         e.MerchName,
+			Quantity:     e.Quantity,
+			Message:      e.Message,
+			Reason:       e.Reason,
+			CreatedAt:    e.CreatedAt.Time,
+		})}
+		statements[i].Closing += line.Change()
+		line.Balance = statements[i].Closing
+		statements[i].Lines = append(statements[i].Lines, line)
+	}
+	return statements, nil
+}
+
+// signupLine — строка стартовых монет сотрудника, зарегистрированного внутри
+// периода; для такого сотрудника ListStatementBalances возвращает баланс на
+// момент регистрации.
+func signupLine(b db.ListStatementBalancesRow) StatementLine {
+	return StatementLine{
+		HistoryEntry: HistoryEntry{
+			Type:      StatementTypeSignup,
+			Direction: HistoryDirectionIncoming,
+			Amount:    int32(b.OpeningBalance),
+			CreatedAt: b.CreatedAt.Time,
+		},
+		Balance: b.OpeningBalance,
+	}
+}
+
+// parseStatementPeriod возвращает начало месяца выписки в UTC. Месяц позже
+// текущего недопустим: по нему ещё нет операций, а остаток был бы выдуман.
+func parseStatementPeriod(period string, now time.Time) (time.Time, error) {
+	invalid := fmt.Errorf("%w: %w", ErrBusinessValidation, ErrInvalidStatementPeriod)
+	from, err := time.Parse(statementPeriodLayout, period)
+	if err != nil {
+		return time.Time{}, invalid
+	}
+	if from.After(allowancePeriod(now)) {
+		return time.Time{}, invalid
+	}
+	return from, nil
+}
@@ -0,0 +1,218 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/par1ram/merch-store/internal/db"
+	"github.com/par1ram/merch-store/internal/repository"
+	"github.com/par1ram/merch-store/internal/service"
+	"github.com/par1ram/merch-store/internal/utils"
+)
+
+type MockStatementRepository struct {
+	mock.Mock
+}
+
+func (m *MockStatementRepository) ExecTx(ctx context.Context, fn func(repository.StatementRepository) error) error {
+	args := m.Called(ctx, fn)
+	if err := args.Error(0); err != nil {
+		return err
+	}
+	return fn(m)
+}
+
+func (m *MockStatementRepository) ListStatementBalances(ctx context.Context, params db.ListStatementBalancesParams) ([]db.ListStatementBalancesRow, error) {
+	args := m.Called(ctx, params)
+	if rows, ok := args.Get(0).([]db.ListStatementBalancesRow); ok {
+		return rows, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockStatementRepository) ListStatementEntries(ctx context.Context, params db.ListStatementEntriesParams) ([]db.ListStatementEntriesRow, error) {
+	args := m.Called(ctx, params)
+	if rows, ok := args.Get(0).([]db.ListStatementEntriesRow); ok {
+		return rows, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+var (
+	statementFrom = time.Date(2026, time.September, 1, 0, 0, 0, 0, time.UTC)
+	statementTo   = time.Date(2026, time.October, 1, 0, 0, 0, 0, time.UTC)
+)
+
+func statementEntry(employeeID, id int32, t db.TransactionTypeEnum, incoming bool, amount int32) db.ListStatementEntriesRow {
+	return db.ListStatementEntriesRow{
+		EmployeeID:      employeeID,
+		ID:              id,
+		TransactionType: t,
+		Incoming:        incoming,
+		Amount:          amount,
+		Quantity:        1,
+		CreatedAt:       pgtype.Timestamptz{Time: statementFrom.Add(time.Duration(id) * time.Hour), Valid: true},
+	}
+}
+
+func TestGetStatement_RunningBalance(t *testing.T) {
+	ctx := userCtx(1)
+	employee := pgtype.Int4{Int32: 1, Valid: true}
+	start := pgtype.Timestamptz{Time: statementFrom, Valid: true}
+	end := pgtype.Timestamptz{Time: statementTo, Valid: true}
+
+	purchase := statementEntry(1, 3, db.TransactionTypeEnumPurchase, false, 80)
+	purchase.MerchName = "cup"
+
+	repo := new(MockStatementRepository)
+	repo.On("ExecTx", ctx, mock.AnythingOfType("func(repository.StatementRepository) error")).Return(nil).Once()
+	repo.On("ListStatementBalances", ctx, db.ListStatementBalancesParams{PeriodStart: start, PeriodEnd: end, EmployeeID: employee}).
+		Return([]db.ListStatementBalancesRow{{ID: 1, Username: "alice", OpeningBalance: 900}}, nil).Once()
+	repo.On("ListStatementEntries", ctx, db.ListStatementEntriesParams{PeriodStart: start, PeriodEnd: end, EmployeeID: employee}).
+		Return([]db.ListStatementEntriesRow{
+			statementEntry(1, 2, db.TransactionTypeEnumGrant, true, 200),
+			purchase,
+			statementEntry(1, 4, db.TransactionTypeEnumTransfer, false, 50),
+		}, nil).Once()
+
+	svc := service.NewStatementService(repo, utils.NewLogger())
+	statement, err := svc.GetStatement(ctx, "2026-09")
+	assert.NoError(t, err)
+	assert.Equal(t, "alice", statement.Username)
+	assert.Equal(t, "2026-09", statement.Period)
+	assert.Equal(t, statementFrom, statement.From)
+	assert.Equal(t, statementTo, statement.To)
+	assert.Equal(t, int64(900), statement.Opening)
+	assert.Equal(t, int64(970), statement.Closing)
+
+	assert.Len(t, statement.Lines, 3)
+	assert.Equal(t, []int64{1100, 1020, 970}, []int64{
+		statement.Lines[0].Balance, statement.Lines[1].Balance, statement.Lines[2].Balance,
+	})
+	assert.Equal(t, int64(-80), statement.Lines[1].Change())
+	assert.Equal(t, "cup", statement.Lines[1].Item)
+
+	repo.AssertExpectations(t)
+}
+
+func TestGetStatement_RegisteredDuringPeriod(t *testing.T) {
+	ctx := userCtx(1)
+	registered := statementFrom.AddDate(0, 0, 14)
+
+	repo := new(MockStatementRepository)
+	repo.On("ExecTx", ctx, mock.AnythingOfType("func(repository.StatementRepository) error")).Return(nil).Once()
+	repo.On("ListStatementBalances", ctx, mock.Anything).
+		Return([]db.ListStatementBalancesRow{{
+			ID:             1,
+			Username:       "alice",
+			CreatedAt:      pgtype.Timestamptz{Time: registered, Valid: true},
+			OpeningBalance: 1000,
+		}}, nil).Once()
+	repo.On("ListStatementEntries", ctx, mock.Anything).
+		Return([]db.ListStatementEntriesRow{
+			statementEntry(1, 4, db.TransactionTypeEnumTransfer, false, 50),
+		}, nil).Once()
+
+	svc := service.NewStatementService(repo, utils.NewLogger())
+	statement, err := svc.GetStatement(ctx, "2026-09")
+	assert.NoError(t, err)
+
+	// До регистрации монет не было: стартовые монеты — отдельная строка.
+	assert.Equal(t, int64(0), statement.Opening)
+	assert.Equal(t, int64(950), statement.Closing)
+	assert.Len(t, statement.Lines, 2)
+	assert.Equal(t, service.StatementTypeSignup, statement.Lines[0].Type)
+	assert.Equal(t, service.HistoryDirectionIncoming, statement.Lines[0].Direction)
+	assert.Equal(t, int64(1000), statement.Lines[0].Change())
+	assert.Equal(t, int64(1000), statement.Lines[0].Balance)
+	assert.Equal(t, registered, statement.Lines[0].CreatedAt)
+	assert.Equal(t, int64(950), statement.Lines[1].Balance)
+
+	repo.AssertExpectations(t)
+}
+
+func TestGetStatement_NotRegisteredYet(t *testing.T) {
+	ctx := userCtx(1)
+
+	repo := new(MockStatementRepository)
+	repo.On("ExecTx", ctx, mock.AnythingOfType("func(repository.StatementRepository) error")).Return(nil).Once()
+	repo.On("ListStatementBalances", ctx, mock.Anything).Return([]db.ListStatementBalancesRow{}, nil).Once()
+	repo.On("ListStatementEntries", ctx, mock.Anything).Return([]db.ListStatementEntriesRow{}, nil).Once()
+
+	svc := service.NewStatementService(repo, utils.NewLogger())
+	_, err := svc.GetStatement(ctx, "2020-01")
+	assert.ErrorIs(t, err, service.ErrStatementNotFound)
+
+	repo.AssertExpectations(t)
+}
+
+func TestGetStatement_InvalidPeriod(t *testing.T) {
+	svc := service.NewStatementService(new(MockStatementRepository), utils.NewLogger())
+
+	next := time.Now().UTC().AddDate(0, 1, 0).Format("2006-01")
+	for _, period := range []string{"", "2026-13", "2026-9", "09-2026", next} {
+		_, err := svc.GetStatement(userCtx(1), period)
+		assert.ErrorIs(t, err, service.ErrBusinessValidation, period)
+		assert.ErrorIs(t, err, service.ErrInvalidStatementPeriod, period)
+	}
+}
+
+func TestGetStatement_Unauthenticated(t *testing.T) {
+	svc := service.NewStatementService(new(MockStatementRepository), utils.NewLogger())
+	_, err := svc.GetStatement(context.Background(), "2026-09")
+	assert.ErrorIs(t, err, service.ErrUnauthenticated)
+}
+
+func TestListStatements_AllEmployees(t *testing.T) {
+	ctx := userCtx(1)
+	start := pgtype.Timestamptz{Time: statementFrom, Valid: true}
+	end := pgtype.Timestamptz{Time: statementTo, Valid: true}
+
+	repo := new(MockStatementRepository)
+	repo.On("ExecTx", ctx, mock.AnythingOfType("func(repository.StatementRepository) error")).Return(nil).Once()
+	repo.On("ListStatementBalances", ctx, db.ListStatementBalancesParams{PeriodStart: start, PeriodEnd: end}).
+		Return([]db.ListStatementBalancesRow{
+			{ID: 1, Username: "alice", OpeningBalance: 1000},
+			{ID: 2, Username: "bob", OpeningBalance: 500},
+			{ID: 3, Username: "carol", OpeningBalance: 1000},
+		}, nil).Once()
+	repo.On("ListStatementEntries", ctx, db.ListStatementEntriesParams{PeriodStart: start, PeriodEnd: end}).
+		Return([]db.ListStatementEntriesRow{
+			statementEntry(1, 5, db.TransactionTypeEnumTransfer, false, 100),
+			statementEntry(2, 5, db.TransactionTypeEnumTransfer, true, 100),
+		}, nil).Once()
+
+	svc := service.NewStatementService(repo, utils.NewLogger())
+	statements, err := svc.ListStatements(ctx, "2026-09")
+	assert.NoError(t, err)
+	assert.Len(t, statements, 3)
+	assert.Equal(t, int64(900), statements[0].Closing)
+	assert.Equal(t, int64(600), statements[1].Closing)
+	assert.Equal(t, int64(1000), statements[2].Closing)
+	assert.NotNil(t, statements[2].Lines)
+	assert.Empty(t, statements[2].Lines)
+
+	repo.AssertExpectations(t)
+}
+
+func TestListStatements_RepositoryError(t *testing.T) {
+	ctx := userCtx(1)
+
+	repo := new(MockStatementRepository)
+	repo.On("ExecTx", ctx, mock.AnythingOfType("func(repository.StatementRepository) error")).Return(nil).Once()
+	dbErr := errors.New("db error")
+	repo.On("ListStatementBalances", ctx, mock.Anything).Return(nil, dbErr).Once()
+
+	svc := service.NewStatementService(repo, utils.NewLogger())
+	_, err := svc.ListStatements(ctx, "2026-09")
+	assert.ErrorIs(t, err, dbErr)
+	repo.AssertNotCalled(t, "ListStatementEntries", mock.Anything, mock.Anything)
+
+	repo.AssertExpectations(t)
+}
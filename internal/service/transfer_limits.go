@@ -0,0 +1,245 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/par1ram/merch-store/internal/db"
+	"github.com/par1ram/merch-store/internal/middleware"
+	"github.com/par1ram/merch-store/internal/repository"
+	"github.com/par1ram/merch-store/internal/utils"
+)
+
+var (
+	ErrDailyTransferLimit     = newError(CodeDailyTransferLimit, KindForbidden, "daily transfer limit exceeded")
+	ErrMonthlyTransferLimit   = newError(CodeMonthlyTransferLimit, KindForbidden, "monthly transfer limit exceeded")
+	ErrRecipientTransferLimit = newError(CodeRecipientTransferLimit, KindForbidden, "monthly transfer limit for this recipient exceeded")
+	ErrInvalidTransferLimit   = newError(CodeInvalidTransferLimit, KindInvalid, "transfer limits must be non-negative")
+	ErrTransfersBlocked       = newError(CodeTransfersBlocked, KindForbidden, "outgoing transfers are blocked for this employee")
+)
+
+// TransferLimits — лимиты исходящих переводов сотрудника в монетах; 0 означает
+// отсутствие лимита. Дни и месяцы считаются календарными по UTC, PerRecipient
+// ограничивает переводы одному получателю за месяц. Blocked истинно, если
+// администратор задал хотя бы одно нулевое переопределение: любой перевод
+// превысил бы такой лимит, поэтому исходящие переводы запрещены полностью.
+type TransferLimits struct {
+	Daily        int32 `json:"daily"`
+	Monthly      int32 `json:"monthly"`
+	PerRecipient int32 `json:"per_recipient"`
+	Blocked      bool  `json:"blocked"`
+}
+
+// TransferLimitOverride — индивидуальные лимиты сотрудника, заданные администратором.
+// nil означает лимит по умолчанию, 0 запрещает исходящие переводы. Чтобы снять
+// лимит по умолчанию, задаётся значение больше любой возможной суммы переводов.
+type TransferLimitOverride struct {
+	Daily        *int32 `json:"daily"`
+	Monthly      *int32 `json:"monthly"`
+	PerRecipient *int32 `json:"per_recipient"`
+}
+
+// EmployeeTransferLimits — действующие лимиты сотрудника и их переопределения.
+type EmployeeTransferLimits struct {
+	Username  string                `json:"username"`
+	Effective TransferLimits        `json:"effective"`
+	Override  TransferLimitOverride `json:"override"`
+}
+
+// TransferLimitService управляет индивидуальными лимитами переводов.
+// Сами лимиты проверяет SendCoinService при каждом переводе.
+type TransferLimitService interface {
+	GetLimits(ctx context.Context, username string) (EmployeeTransferLimits, error)
+	SetOverride(ctx context.Context, username string, override TransferLimitOverride) (EmployeeTransferLimits, error)
+	ResetOverride(ctx context.Context, username string) (EmployeeTransferLimits, error)
+}
+
+type transferLimitService struct {
+	repo     repository.TransferLimitRepository
+	defaults TransferLimits
+	logger   utils.Logger
+}
+
+func NewTransferLimitService(repo repository.TransferLimitRepository, defaults TransferLimits, logger utils.Logger) TransferLimitService {
+	logger.WithFields(utils.LogFields{"component": "transfer_limit_service"}).Info("TransferLimitService initialized")
+	return &transferLimitService{
+		repo:     repo,
+		defaults: defaults,
+		logger:   logger.WithFields(utils.LogFields{"component": "transfer_limit_service"}),
+	}
+}
+
+func (s *transferLimitService) GetLimits(ctx context.Context, username string) (EmployeeTransferLimits, error) {
+	employee, err := s.getEmployee(ctx, username)
+	if err != nil {
+		return EmployeeTransferLimits{}, err
+	}
+	return s.employeeLimits(ctx, employee)
+}
+
+// SetOverride заменяет переопределения сотрудника целиком: поля со значением nil
+// возвращаются к лимитам по умолчанию.
+func (s *transferLimitService) SetOverride(ctx context.Context, username string, override TransferLimitOverride) (EmployeeTransferLimits, error) {
+	adminID := middleware.GetUserIDFromContext(ctx)
+	if adminID == 0 {
+		return EmployeeTransferLimits{}, ErrUnauthenticated
+	}
+	for _, limit := range []*int32{override.Daily, override.Monthly, override.PerRecipient} {
+		if limit != nil && *limit < 0 {
+			return EmployeeTransferLimits{}, fmt.Errorf("%w: %w", ErrBusinessValidation, ErrInvalidTransferLimit)
+		}
+	}
+
+	employee, err := s.getEmployee(ctx, username)
+	if err != nil {
+		return EmployeeTransferLimits{}, err
+	}
+
+	if err := s.repo.UpsertOverride(ctx, db.UpsertTransferLimitOverrideParams{
+		EmployeeID:     employee.ID,
+		DailyLimit:     optionalInt4(override.Daily),
+		MonthlyLimit:   optionalInt4(override.Monthly),
+		RecipientLimit: optionalInt4(override.PerRecipient),
+		UpdatedBy:      int32(adminID),
+	}); err != nil {
+		return EmployeeTransferLimits{}, fmt.Errorf("failed to save transfer limits: %w", err)
+	}
+
+	s.logger.WithFields(utils.LogFields{
+		"operation": "set_transfer_limits",
+		"admin_id":  adminID,
+		"username":  employee.Username,
+	}).Info("Transfer limits overridden")
+	return s.employeeLimits(ctx, employee)
+}
+
+func (s *transferLimitService) ResetOverride(ctx context.Context, username string) (EmployeeTransferLimits, error) {
+	employee, err := s.getEmployee(ctx, username)
+	if err != nil {
+		return EmployeeTransferLimits{}, err
+	}
+
+	if _, err := s.repo.DeleteOverride(ctx, employee.ID); err != nil {
+		return EmployeeTransferLimits{}, fmt.Errorf("failed to reset transfer limits: %w", err)
+	}
+
+	s.logger.WithFields(utils.LogFields{
+		"operation": "reset_transfer_limits",
+		"admin_id":  middleware.GetUserIDFromContext(ctx),
+		"username":  employee.Username,
+	}).Info("Transfer limits reset to defaults")
+	return s.employeeLimits(ctx, employee)
+}
+
+func (s *transferLimitService) getEmployee(ctx context.Context, username string) (db.Employee, error) {
+	employee, err := s.repo.GetEmployee(ctx, username)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return db.Employee{}, fmt.Errorf("%w: %w: %s", ErrBusinessValidation, ErrUserNotFound, username)
+		}
+		return db.Employee{}, fmt.Errorf("failed to get employee: %w", err)
+	}
+	return employee, nil
+}
+
+func (s *transferLimitService) employeeLimits(ctx context.Context, employee db.Employee) (EmployeeTransferLimits, error) {
+	row, err := s.repo.GetOverride(ctx, employee.ID)
+	if err != nil {
+		return EmployeeTransferLimits{}, fmt.Errorf("failed to get transfer limits: %w", err)
+	}
+	return EmployeeTransferLimits{
+		Username:  employee.Username,
+		Effective: s.defaults.withOverride(row),
+		Override: TransferLimitOverride{
+			Daily:        int4Pointer(row.DailyLimit),
+			Monthly:      int4Pointer(row.MonthlyLimit),
+			PerRecipient: int4Pointer(row.RecipientLimit),
+		},
+	}, nil
+}
+
+// withOverride возвращает лимиты с учётом переопределений сотрудника.
+// В отличие от лимитов по умолчанию, нулевое переопределение не снимает
+// лимит, а запрещает переводы.
+func (l TransferLimits) withOverride(row db.GetTransferLimitOverrideRow) TransferLimits {
+	if row.DailyLimit.Valid {
+		l.Daily = row.DailyLimit.Int32
+	}
+	if row.MonthlyLimit.Valid {
+		l.Monthly = row.MonthlyLimit.Int32
+	}
+	if row.RecipientLimit.Valid {
+		l.PerRecipient = row.RecipientLimit.Int32
+	}
+	for _, override := range []pgtype.Int4{row.DailyLimit, row.MonthlyLimit, row.RecipientLimit} {
+		if override.Valid && override.Int32 == 0 {
+			l.Blocked = true
+		}
+	}
+	return l
+}
+
+// resolveTransferLimits возвращает действующие лимиты отправителя.
+func resolveTransferLimits(ctx context.Context, r repository.SendCoinRepository, defaults TransferLimits, senderID int32) (TransferLimits, error) {
+	row, err := r.GetTransferLimitOverride(ctx, senderID)
+	if err != nil {
+		return TransferLimits{}, fmt.Errorf("failed to get transfer limits: %w", err)
+	}
+	return defaults.withOverride(row), nil
+}
+
+// checkTransferLimits проверяет, что перевод amount получателю recipientID не
+// выведет отправителя за лимиты. Суммы считаются по coin_transactions в текущей
+// транзакции, поэтому уже выполненные части пакетного перевода учитываются.
+func checkTransferLimits(ctx context.Context, r repository.SendCoinRepository, limits TransferLimits, senderID, recipientID, amount int32) error {
+	if limits.Blocked {
+		return fmt.Errorf("%w: %w", ErrBusinessValidation, ErrTransfersBlocked)
+	}
+	if limits == (TransferLimits{}) {
+		return nil
+	}
+
+	now := time.Now().UTC()
+	dayStart := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	totals, err := r.GetOutgoingTransferTotals(ctx, db.GetOutgoingTransferTotalsParams{
+		SenderID:    senderID,
+		RecipientID: recipientID,
+		DayStart:    pgtype.Timestamptz{Time: dayStart, Valid: true},
+		MonthStart:  pgtype.Timestamptz{Time: allowancePeriod(now), Valid: true},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get outgoing transfer totals: %w", err)
+	}
+
+	switch {
+	case exceedsLimit(limits.Daily, totals.SentToday, amount):
+		return fmt.Errorf("%w: %w", ErrBusinessValidation, ErrDailyTransferLimit)
+	case exceedsLimit(limits.Monthly, totals.SentThisMonth, amount):
+		return fmt.Errorf("%w: %w", ErrBusinessValidation, ErrMonthlyTransferLimit)
+	case exceedsLimit(limits.PerRecipient, totals.SentToRecipient, amount):
+		return fmt.Errorf("%w: %w", ErrBusinessValidation, ErrRecipientTransferLimit)
+	}
+	return nil
+}
+
+func exceedsLimit(limit int32, sent int64, amount int32) bool {
+	return limit > 0 && sent+int64(amount) > int64(limit)
+}
+
+func optionalInt4(v *int32) pgtype.Int4 {
+	if v == nil {
+		return pgtype.Int4{}
+	}
+	return pgtype.Int4{Int32: *v, Valid: true}
+}
+
+func int4Pointer(v pgtype.Int4) *int32 {
+	if !v.Valid {
+		return nil
+	}
+	return &v.Int32
+}
@@ -0,0 +1,215 @@
+package service_test
+
+import (
+	"context"
+	"database/sql"
+	"math"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/par1ram/merch-store/internal/db"
+	"github.com/par1ram/merch-store/internal/repository"
+	"github.com/par1ram/merch-store/internal/service"
+	"github.com/par1ram/merch-store/internal/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockTransferLimitRepository struct {
+	mock.Mock
+}
+
+func (m *MockTransferLimitRepository) GetEmployee(ctx context.Context, username string) (db.Employee, error) {
+	args := m.Called(ctx, username)
+	return args.Get(0).(db.Employee), args.Error(1)
+}
+
+func (m *MockTransferLimitRepository) GetOverride(ctx context.Context, employeeID int32) (db.GetTransferLimitOverrideRow, error) {
+	args := m.Called(ctx, employeeID)
+	return args.Get(0).(db.GetTransferLimitOverrideRow), args.Error(1)
+}
+
+func (m *MockTransferLimitRepository) UpsertOverride(ctx context.Context, params db.UpsertTransferLimitOverrideParams) error {
+	args := m.Called(ctx, params)
+	return args.Error(0)
+}
+
+func (m *MockTransferLimitRepository) DeleteOverride(ctx context.Context, employeeID int32) (int64, error) {
+	args := m.Called(ctx, employeeID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func int32Ptr(v int32) *int32 {
+	return &v
+}
+
+// totalsFor сопоставляет запрос сумм переводов с получателем.
+func totalsFor(recipientID int32) interface{} {
+	return mock.MatchedBy(func(p db.GetOutgoingTransferTotalsParams) bool {
+		return p.SenderID == 123 && p.RecipientID == recipientID && !p.DayStart.Time.Before(p.MonthStart.Time)
+	})
+}
+
+func TestSendCoin_DailyLimitExceeded(t *testing.T) {
+	ctx := userCtx(123)
+
+	mockRepo := new(MockSendCoinRepository)
+	mockRepo.On("ExecTx", ctx, mock.Anything).Return(nil).Once()
+	mockRepo.On("GetRecipient", ctx, "alice").Return(&db.Employee{ID: 1, Username: "alice"}, nil).Once()
+	mockRepo.On("LockBalances", ctx, mock.Anything).Return(map[int32]int32{123: 500}, nil).Once()
+	mockRepo.On("GetTransferLimitOverride", ctx, int32(123)).Return(db.GetTransferLimitOverrideRow{}, nil).Once()
+	mockRepo.On("GetOutgoingTransferTotals", ctx, totalsFor(1)).
+		Return(db.GetOutgoingTransferTotalsRow{SentToday: 80, SentThisMonth: 80}, nil).Once()
+
+	svc := service.NewSendCoinService(mockRepo, service.TransferLimits{Daily: 100, Monthly: 1000}, 0, utils.NewLogger())
+	err := svc.SendCoin(ctx, "alice", 30, "")
+	assert.ErrorIs(t, err, service.ErrDailyTransferLimit)
+
+	mockRepo.AssertNotCalled(t, "TransferCoins", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestSendCoin_RecipientLimitFromOverride(t *testing.T) {
+	ctx := userCtx(123)
+
+	mockRepo := new(MockSendCoinRepository)
+	mockRepo.On("ExecTx", ctx, mock.Anything).Return(nil).Once()
+	mockRepo.On("GetRecipient", ctx, "alice").Return(&db.Employee{ID: 1, Username: "alice"}, nil).Once()
+	mockRepo.On("LockBalances", ctx, mock.Anything).Return(map[int32]int32{123: 500}, nil).Once()
+	mockRepo.On("GetTransferLimitOverride", ctx, int32(123)).
+		Return(db.GetTransferLimitOverrideRow{RecipientLimit: pgtype.Int4{Int32: 50, Valid: true}}, nil).Once()
+	mockRepo.On("GetOutgoingTransferTotals", ctx, totalsFor(1)).
+		Return(db.GetOutgoingTransferTotalsRow{SentToday: 40, SentThisMonth: 40, SentToRecipient: 40}, nil).Once()
+
+	svc := service.NewSendCoinService(mockRepo, service.TransferLimits{}, 0, utils.NewLogger())
+	err := svc.SendCoin(ctx, "alice", 20, "")
+	assert.ErrorIs(t, err, service.ErrRecipientTransferLimit)
+}
+
+// TestSendCoin_ZeroOverrideBlocksTransfers — переопределение 0 запрещает
+// переводы, даже если лимит по умолчанию не задан, и суммы не запрашиваются.
+func TestSendCoin_ZeroOverrideBlocksTransfers(t *testing.T) {
+	ctx := userCtx(123)
+
+	mockRepo := new(MockSendCoinRepository)
+	mockRepo.On("ExecTx", ctx, mock.Anything).Return(nil).Once()
+	mockRepo.On("GetRecipient", ctx, "alice").Return(&db.Employee{ID: 1, Username: "alice"}, nil).Once()
+	mockRepo.On("LockBalances", ctx, mock.Anything).Return(map[int32]int32{123: 500}, nil).Once()
+	mockRepo.On("GetTransferLimitOverride", ctx, int32(123)).
+		Return(db.GetTransferLimitOverrideRow{MonthlyLimit: pgtype.Int4{Int32: 0, Valid: true}}, nil).Once()
+
+	svc := service.NewSendCoinService(mockRepo, service.TransferLimits{}, 0, utils.NewLogger())
+	err := svc.SendCoin(ctx, "alice", 1, "")
+	assert.ErrorIs(t, err, service.ErrTransfersBlocked)
+	assert.ErrorIs(t, err, service.ErrBusinessValidation)
+
+	mockRepo.AssertExpectations(t)
+	mockRepo.AssertNotCalled(t, "GetOutgoingTransferTotals", mock.Anything, mock.Anything)
+	mockRepo.AssertNotCalled(t, "TransferCoins", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+// TestSendCoin_LargeOverrideLiftsLimit — лимит по умолчанию снимается
+// переопределением больше суммы переводов.
+func TestSendCoin_LargeOverrideLiftsLimit(t *testing.T) {
+	ctx := userCtx(123)
+
+	mockRepo := new(MockSendCoinRepository)
+	mockRepo.On("ExecTx", ctx, mock.Anything).Return(nil).Once()
+	mockRepo.On("GetRecipient", ctx, "alice").Return(&db.Employee{ID: 1, Username: "alice"}, nil).Once()
+	mockRepo.On("LockBalances", ctx, mock.Anything).Return(map[int32]int32{123: 500}, nil).Once()
+	mockRepo.On("GetTransferLimitOverride", ctx, int32(123)).
+		Return(db.GetTransferLimitOverrideRow{MonthlyLimit: pgtype.Int4{Int32: math.MaxInt32, Valid: true}}, nil).Once()
+	mockRepo.On("GetOutgoingTransferTotals", ctx, totalsFor(1)).
+		Return(db.GetOutgoingTransferTotalsRow{SentToday: 200, SentThisMonth: 200}, nil).Once()
+	mockRepo.On("TransferCoins", ctx, int32(123), int32(1), int32(300), repository.TransferMemo{}).Return(nil).Once()
+
+	svc := service.NewSendCoinService(mockRepo, service.TransferLimits{Monthly: 100}, 0, utils.NewLogger())
+	err := svc.SendCoin(ctx, "alice", 300, "")
+	assert.NoError(t, err)
+
+	mockRepo.AssertExpectations(t)
+}
+
+// TestSendCoinBatch_MonthlyLimitCountsEarlierLegs — вторая часть пакета видит
+// первую в суммах за месяц и отклоняет весь пакет.
+func TestSendCoinBatch_MonthlyLimitCountsEarlierLegs(t *testing.T) {
+	ctx := userCtx(123)
+
+	mockRepo := new(MockSendCoinRepository)
+	mockRepo.On("ExecTx", ctx, mock.Anything).Return(nil).Once()
+	mockRepo.On("GetRecipient", ctx, "alice").Return(&db.Employee{ID: 1, Username: "alice"}, nil).Once()
+	mockRepo.On("GetRecipient", ctx, "bob").Return(&db.Employee{ID: 2, Username: "bob"}, nil).Once()
+	mockRepo.On("LockBalances", ctx, mock.Anything).Return(map[int32]int32{123: 500}, nil).Once()
+	mockRepo.On("GetTransferLimitOverride", ctx, int32(123)).Return(db.GetTransferLimitOverrideRow{}, nil).Once()
+	mockRepo.On("GetOutgoingTransferTotals", ctx, totalsFor(1)).
+		Return(db.GetOutgoingTransferTotalsRow{SentToday: 10, SentThisMonth: 50}, nil).Once()
+	mockRepo.On("TransferCoins", ctx, int32(123), int32(1), int32(30), repository.TransferMemo{}).Return(nil).Once()
+	mockRepo.On("GetOutgoingTransferTotals", ctx, totalsFor(2)).
+		Return(db.GetOutgoingTransferTotalsRow{SentToday: 40, SentThisMonth: 80}, nil).Once()
+
+	svc := service.NewSendCoinService(mockRepo, service.TransferLimits{Monthly: 100}, 0, utils.NewLogger())
+	_, err := svc.SendCoinBatch(ctx, []service.TransferLeg{{ToUser: "alice", Amount: 30}, {ToUser: "bob", Amount: 30}}, "")
+	assert.ErrorIs(t, err, service.ErrMonthlyTransferLimit)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestTransferLimitService_SetOverride(t *testing.T) {
+	ctx := userCtx(1)
+
+	mockRepo := new(MockTransferLimitRepository)
+	mockRepo.On("GetEmployee", ctx, "bob").Return(db.Employee{ID: 7, Username: "bob"}, nil).Once()
+	mockRepo.On("UpsertOverride", ctx, db.UpsertTransferLimitOverrideParams{
+		EmployeeID: 7,
+		DailyLimit: pgtype.Int4{Int32: 50, Valid: true},
+		UpdatedBy:  1,
+	}).Return(nil).Once()
+	mockRepo.On("GetOverride", ctx, int32(7)).
+		Return(db.GetTransferLimitOverrideRow{DailyLimit: pgtype.Int4{Int32: 50, Valid: true}}, nil).Once()
+
+	svc := service.NewTransferLimitService(mockRepo, service.TransferLimits{Daily: 100, Monthly: 1000}, utils.NewLogger())
+	limits, err := svc.SetOverride(ctx, "bob", service.TransferLimitOverride{Daily: int32Ptr(50)})
+	assert.NoError(t, err)
+	assert.Equal(t, service.TransferLimits{Daily: 50, Monthly: 1000}, limits.Effective)
+	assert.Equal(t, int32Ptr(50), limits.Override.Daily)
+	assert.Nil(t, limits.Override.Monthly)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestTransferLimitService_ZeroOverrideBlocks(t *testing.T) {
+	ctx := userCtx(1)
+
+	mockRepo := new(MockTransferLimitRepository)
+	mockRepo.On("GetEmployee", ctx, "bob").Return(db.Employee{ID: 7, Username: "bob"}, nil).Once()
+	mockRepo.On("GetOverride", ctx, int32(7)).
+		Return(db.GetTransferLimitOverrideRow{RecipientLimit: pgtype.Int4{Int32: 0, Valid: true}}, nil).Once()
+
+	svc := service.NewTransferLimitService(mockRepo, service.TransferLimits{Daily: 100, Monthly: 1000}, utils.NewLogger())
+	limits, err := svc.GetLimits(ctx, "bob")
+	assert.NoError(t, err)
+	assert.Equal(t, service.TransferLimits{Daily: 100, Monthly: 1000, Blocked: true}, limits.Effective)
+	assert.Equal(t, int32Ptr(0), limits.Override.PerRecipient)
+}
+
+func TestTransferLimitService_SetOverrideNegative(t *testing.T) {
+	mockRepo := new(MockTransferLimitRepository)
+
+	svc := service.NewTransferLimitService(mockRepo, service.TransferLimits{}, utils.NewLogger())
+	_, err := svc.SetOverride(userCtx(1), "bob", service.TransferLimitOverride{Monthly: int32Ptr(-1)})
+	assert.ErrorIs(t, err, service.ErrInvalidTransferLimit)
+
+	mockRepo.AssertNotCalled(t, "UpsertOverride", mock.Anything, mock.Anything)
+}
+
+func TestTransferLimitService_UnknownEmployee(t *testing.T) {
+	ctx := userCtx(1)
+
+	mockRepo := new(MockTransferLimitRepository)
+	mockRepo.On("GetEmployee", ctx, "ghost").Return(db.Employee{}, sql.ErrNoRows).Once()
+
+	svc := service.NewTransferLimitService(mockRepo, service.TransferLimits{}, utils.NewLogger())
+	_, err := svc.ResetOverride(ctx, "ghost")
+	assert.ErrorIs(t, err, service.ErrUserNotFound)
+
+	mockRepo.AssertNotCalled(t, "DeleteOverride", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,71 @@
+package service
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/par1ram/merch-store/internal/repository"
+)
+
+const (
+	// maxTransferMessageLength — наибольшая длина сообщения к переводу в символах.
+	maxTransferMessageLength = 280
+	// maxTransferHashtags — сколько хэштегов из сообщения сохраняется.
+	maxTransferHashtags = 10
+	// maxHashtagLength — хэштеги длиннее этого значения не сохраняются.
+	maxHashtagLength = 50
+)
+
+// hashtagPattern находит хэштеги в начале сообщения или после пробела,
+// чтобы не принимать за хэштег, например, «C#».
+var hashtagPattern = regexp.MustCompile(`(?:^|\s)#([\p{L}\p{N}_]+)`)
+
+// parseTransferMessage очищает сообщение к переводу и извлекает из него хэштеги.
+// Из сообщения удаляются управляющие и невидимые символы форматирования,
+// пробельные последовательности схлопываются в один пробел.
+func parseTransferMessage(message string) (repository.TransferMemo, error) {
+	message = sanitizeMessage(message)
+	if utf8.RuneCountInString(message) > maxTransferMessageLength {
+		return repository.TransferMemo{}, fmt.Errorf("%w: %w", ErrBusinessValidation, ErrInvalidMessage)
+	}
+	return repository.TransferMemo{Message: message, Hashtags: extractHashtags(message)}, nil
+}
+
+func sanitizeMessage(message string) string {
+	message = strings.ToValidUTF8(message, "")
+	message = strings.Map(func(r rune) rune {
+		switch {
+		case unicode.IsSpace(r):
+			return ' '
+		case unicode.IsControl(r), unicode.Is(unicode.Cf, r):
+			return -1
+		}
+		return r
+	}, message)
+	return strings.Join(strings.Fields(message), " ")
+}
+
+// extractHashtags возвращает уникальные хэштеги сообщения в нижнем регистре
+// без символа # в порядке появления.
+func extractHashtags(message string) []string {
+	var hashtags []string
+	seen := make(map[string]struct{})
+	for _, match := range hashtagPattern.FindAllStringSubmatch(message, -1) {
+		tag := strings.ToLower(match[1])
+		if utf8.RuneCountInString(tag) > maxHashtagLength {
+			continue
+		}
+		if _, ok := seen[tag]; ok {
+			continue
+		}
+		seen[tag] = struct{}{}
+		hashtags = append(hashtags, tag)
+		if len(hashtags) == maxTransferHashtags {
+			break
+		}
+	}
+	return hashtags
+}
@@ -0,0 +1,67 @@
+-- AccrueAllowanceBatch начисляет пособие за период очередной порции сотрудников,
+-- ещё не получивших его. Запись в coin_transactions, проводка со счёта выпуска,
+-- партия монет и изменение баланса выполняются одним запросом; ON CONFLICT по уникальному индексу периода
+-- исключает повторное начисление, если другая реплика успела раньше.
+-- Строки порции блокируются по возрастанию id, как в LockEmployeeBalances,
+-- чтобы начисление не попадало во взаимную блокировку с переводами.
+-- Возвращает id сотрудников, которым начислено пособие.
+-- name: AccrueAllowanceBatch :many
+WITH batch AS (
+  SELECT e.id
+  FROM employees e
+  WHERE NOT EXISTS (
+    SELECT 1 FROM coin_transactions ct
+    WHERE ct.transaction_type = 'allowance'
+      AND ct.to_employee_id = e.id
+      AND ct.period = sqlc.arg(period)::date
+  )
+  ORDER BY e.id
+  LIMIT sqlc.arg(batch_size)::integer
+  FOR UPDATE OF e
+),
+inserted AS (
+  INSERT INTO coin_transactions (transaction_type, to_employee_id, amount, period)
+  SELECT 'allowance', batch.id, sqlc.arg(amount)::integer, sqlc.arg(period)::date
+  FROM batch
+  ON CONFLICT (to_employee_id, period) WHERE transaction_type = 'allowance' DO NOTHING
+  RETURNING id, to_employee_id
+),
+entries AS (
+  INSERT INTO journal_entries (entry_type, coin_transaction_id)
+  SELECT 'allowance', inserted.id FROM inserted
+  RETURNING id, coin_transaction_id
+),
+postings AS (
+  INSERT INTO ledger_postings (entry_id, account_id, amount)
+  SELECT entries.id, leg.account_id, leg.amount
+  FROM entries
+  JOIN inserted ON inserted.id = entries.coin_transaction_id,
+  LATERAL (VALUES
+    ((SELECT id FROM ledger_accounts WHERE code = 'issuance'), -sqlc.arg(amount)::integer),
+    ((SELECT id FROM ledger_accounts WHERE employee_id = inserted.to_employee_id), sqlc.arg(amount)::integer)
+  ) AS leg(account_id, amount)
+),
+lots AS (
+  INSERT INTO coin_lots (employee_id, amount, remaining)
+  SELECT inserted.to_employee_id, sqlc.arg(amount)::integer, sqlc.arg(amount)::integer
+  FROM inserted
+)
+UPDATE employees e
+SET coins = e.coins + sqlc.arg(amount)::integer
+FROM inserted
+WHERE e.id = inserted.to_employee_id
+RETURNING e.id;
+
+------------------------------------------------------------
+-- ListAllowanceRecipients возвращает сотрудников, которым ещё не начислено
+-- пособие за период. Используется для предварительного просмотра.
+-- name: ListAllowanceRecipients :many
+SELECT e.id, e.username
+FROM employees e
+WHERE NOT EXISTS (
+  SELECT 1 FROM coin_transactions ct
+  WHERE ct.transaction_type = 'allowance'
+    AND ct.to_employee_id = e.id
+    AND ct.period = sqlc.arg(period)::date
+)
+ORDER BY e.id;
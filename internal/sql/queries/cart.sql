@@ -0,0 +1,37 @@
+-- AddCartItem добавляет товар в корзину сотрудника или увеличивает количество,
+-- если товар уже лежит в корзине. Количество позиции не может превысить
+-- max_quantity: такое добавление не меняет строку и возвращает 0.
+-- name: AddCartItem :execrows
+INSERT INTO cart_items (employee_id, merch_id, quantity)
+VALUES (sqlc.arg(employee_id), sqlc.arg(merch_id), sqlc.arg(quantity))
+ON CONFLICT (employee_id, merch_id)
+DO UPDATE SET quantity = cart_items.quantity + EXCLUDED.quantity
+WHERE cart_items.quantity + EXCLUDED.quantity <= sqlc.arg(max_quantity)::integer;
+
+------------------------------------------------------------
+-- RemoveCartItem удаляет позицию из корзины.
+-- name: RemoveCartItem :execrows
+DELETE FROM cart_items
+WHERE employee_id = $1 AND merch_id = $2;
+
+------------------------------------------------------------
+-- ListCartItems возвращает позиции корзины с текущими ценами товаров.
+-- Порядок по merch_id задаёт единый порядок блокировки строк merch при оформлении.
+-- name: ListCartItems :many
+SELECT 
+  c.merch_id,
+  m.name AS merch_name,
+  m.price,
+  m.active,
+  m.stock,
+  c.quantity
+FROM cart_items c
+JOIN merch m ON c.merch_id = m.id
+WHERE c.employee_id = $1
+ORDER BY c.merch_id;
+
+------------------------------------------------------------
+-- ClearCart очищает корзину сотрудника после оформления заказа.
+-- name: ClearCart :exec
+DELETE FROM cart_items
+WHERE employee_id = $1;
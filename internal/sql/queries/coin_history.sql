@@ -1,24 +1,92 @@
 -- GetReceivedTransfers возвращает историю переводов (монеты, полученные сотрудником).
--- Для каждого перевода возвращается сумма и имя отправителя.
+-- Для каждого перевода возвращается сумма, имя отправителя, сообщение и хэштеги;
+-- возвращаются только row_limit последних переводов.
 -- name: GetReceivedTransfers :many
 SELECT 
   ct.amount,
-  e.username AS from_user
+  e.username AS from_user,
+  COALESCE(ct.message, '')::text AS message,
+  ct.hashtags
 FROM coin_transactions ct
 JOIN employees e ON ct.from_employee_id = e.id
 WHERE ct.transaction_type = 'transfer'
-  AND ct.to_employee_id = $1
-ORDER BY ct.created_at DESC;
+  AND ct.to_employee_id = sqlc.arg(to_employee_id)::integer
+ORDER BY ct.created_at DESC, ct.id DESC
+LIMIT sqlc.arg(row_limit);
 
 ------------------------------------------------------------
 -- GetSentTransfers возвращает историю исходящих переводов (монеты, отправленные сотрудником).
--- Для каждого перевода возвращается сумма и имя получателя.
+-- Для каждого перевода возвращается сумма, имя получателя, сообщение и хэштеги;
+-- возвращаются только row_limit последних переводов.
 -- name: GetSentTransfers :many
 SELECT 
   ct.amount,
-  e.username AS to_user
+  e.username AS to_user,
+  COALESCE(ct.message, '')::text AS message,
+  ct.hashtags
 FROM coin_transactions ct
 JOIN employees e ON ct.to_employee_id = e.id
 WHERE ct.transaction_type = 'transfer'
-  AND ct.from_employee_id = $1
-ORDER BY ct.created_at DESC;
+  AND ct.from_employee_id = sqlc.arg(from_employee_id)::integer
+ORDER BY ct.created_at DESC, ct.id DESC
+LIMIT sqlc.arg(row_limit);
+
+------------------------------------------------------------
+-- GetCoinAdjustments возвращает начисления и списания администраторами,
+-- ежемесячные пособия, сгоревшие монеты и движения удержанных переводов
+-- (удержание, зачисление получателю, возврат отправителю). Суммы списаний
+-- возвращаются отрицательными; возвращаются только row_limit последних операций.
+-- name: GetCoinAdjustments :many
+SELECT
+  ct.transaction_type,
+  (CASE WHEN ct.transaction_type IN ('adjustment', 'expiry', 'hold') THEN -ct.amount ELSE ct.amount END)::integer AS amount,
+  COALESCE(ct.reason, '')::text AS reason,
+  ct.created_at
+FROM coin_transactions ct
+WHERE (ct.transaction_type IN ('grant', 'allowance', 'hold_release', 'hold_refund') AND ct.to_employee_id = sqlc.arg(employee_id)::integer)
+   OR (ct.transaction_type IN ('adjustment', 'expiry', 'hold') AND ct.from_employee_id = sqlc.arg(employee_id)::integer)
+ORDER BY ct.created_at DESC, ct.id DESC
+LIMIT sqlc.arg(row_limit);
+
+------------------------------------------------------------
+-- ListCoinHistory возвращает страницу истории операций сотрудника всех типов,
+-- новые первыми. incoming — монеты зачислены сотруднику, иначе списаны. Для
+-- удержанных переводов контрагентом считается вторая сторона перевода, для
+-- покупок, начислений и сгорания контрагента нет. Для покупок и возвратов
+-- возвращаются название товара и количество. Страница продолжается после
+-- операции (cursor_created_at, cursor_id); фильтры со значением NULL не применяются.
+-- name: ListCoinHistory :many
+SELECT
+  ct.id,
+  ct.transaction_type,
+  (ct.to_employee_id IS NOT DISTINCT FROM sqlc.arg(employee_id)::integer)::boolean AS incoming,
+  ct.amount,
+  COALESCE(cp.username, '')::text AS counterparty,
+  COALESCE(m.name, '')::text AS merch_name,
+  ct.quantity,
+  COALESCE(ct.message, '')::text AS message,
+  ct.hashtags,
+  COALESCE(ct.reason, '')::text AS reason,
+  ct.created_at
+FROM coin_transactions ct
+LEFT JOIN merch m ON m.id = ct.merch_id
+LEFT JOIN transfer_holds h ON h.id = ct.hold_id
+LEFT JOIN employees cp ON cp.id = CASE
+  WHEN ct.transaction_type = 'hold_release' THEN h.sender_id
+  WHEN ct.hold_id IS NOT NULL THEN h.recipient_id
+  WHEN ct.to_employee_id = sqlc.arg(employee_id)::integer THEN ct.from_employee_id
+  ELSE ct.to_employee_id
+END
+WHERE (ct.from_employee_id = sqlc.arg(employee_id)::integer OR ct.to_employee_id = sqlc.arg(employee_id)::integer)
+  AND (sqlc.narg(incoming)::boolean IS NULL
+       OR (ct.to_employee_id IS NOT DISTINCT FROM sqlc.arg(employee_id)::integer) = sqlc.narg(incoming)::boolean)
+  AND (sqlc.narg(counterparty)::text IS NULL OR cp.username = sqlc.narg(counterparty)::text)
+  AND (sqlc.narg(types)::text[] IS NULL OR ct.transaction_type::text = ANY(sqlc.narg(types)::text[]))
+  AND (sqlc.narg(created_from)::timestamptz IS NULL OR ct.created_at >= sqlc.narg(created_from)::timestamptz)
+  AND (sqlc.narg(created_to)::timestamptz IS NULL OR ct.created_at < sqlc.narg(created_to)::timestamptz)
+  AND (sqlc.narg(min_amount)::integer IS NULL OR ct.amount >= sqlc.narg(min_amount)::integer)
+  AND (sqlc.narg(max_amount)::integer IS NULL OR ct.amount <= sqlc.narg(max_amount)::integer)
+  AND (sqlc.narg(cursor_created_at)::timestamptz IS NULL
+       OR (ct.created_at, ct.id) < (sqlc.narg(cursor_created_at)::timestamptz, sqlc.narg(cursor_id)::integer))
+ORDER BY ct.created_at DESC, ct.id DESC
+LIMIT sqlc.arg(row_limit);
@@ -0,0 +1,162 @@
+-- CreateCoinLot создаёт партию монет со сроком действия 12 месяцев.
+-- name: CreateCoinLot :exec
+INSERT INTO coin_lots (employee_id, amount, remaining)
+VALUES (sqlc.arg(employee_id)::integer, sqlc.arg(amount)::integer, sqlc.arg(amount)::integer);
+
+------------------------------------------------------------
+-- CreateCoinLotWithExpiry создаёт партию с заданными датами. Используется при
+-- переводе: полученные монеты сохраняют срок действия исходной партии.
+-- name: CreateCoinLotWithExpiry :exec
+INSERT INTO coin_lots (employee_id, amount, remaining, granted_at, expires_at)
+VALUES (
+  sqlc.arg(employee_id)::integer,
+  sqlc.arg(amount)::integer,
+  sqlc.arg(amount)::integer,
+  sqlc.arg(granted_at)::timestamptz,
+  sqlc.arg(expires_at)::timestamptz
+);
+
+------------------------------------------------------------
+-- ConsumeCoinLots списывает amount монет из партий сотрудника, начиная
+-- с ближайшего срока действия (FIFO). Партии блокируются до расчёта;
+-- строка сотрудника к этому моменту уже должна быть заблокирована.
+-- Возвращает израсходованные части партий.
+-- name: ConsumeCoinLots :many
+WITH locked AS (
+  SELECT id, remaining, granted_at, expires_at
+  FROM coin_lots
+  WHERE employee_id = sqlc.arg(employee_id)::integer AND remaining > 0
+  ORDER BY expires_at, id
+  FOR UPDATE
+),
+ordered AS (
+  SELECT id, remaining, granted_at, expires_at,
+         SUM(remaining) OVER (ORDER BY expires_at, id) - remaining AS consumed_before
+  FROM locked
+),
+taken AS (
+  SELECT id, granted_at, expires_at,
+         LEAST(remaining, sqlc.arg(amount)::integer - consumed_before)::integer AS taken
+  FROM ordered
+  WHERE consumed_before < sqlc.arg(amount)::integer
+)
+UPDATE coin_lots l
+SET remaining = l.remaining - taken.taken
+FROM taken
+WHERE l.id = taken.id
+RETURNING l.id, taken.taken, taken.granted_at, taken.expires_at;
+
+------------------------------------------------------------
+-- ExpireCoinLots обнуляет очередную порцию партий с истёкшим сроком,
+-- уменьшает балансы и записывает по одной транзакции expiry на сотрудника
+-- с проводкой на счёт сгоревших монет.
+-- Партии блокируются вместе со строками их владельцев. Если заблокирована
+-- партия или сотрудник (например, идёт перевод), партия пропускается до
+-- следующего запуска: запрос никогда не ждёт блокировок и не может попасть
+-- во взаимную блокировку с переводами и покупками.
+-- name: ExpireCoinLots :many
+WITH stale AS (
+  SELECT l.id, l.employee_id, l.remaining
+  FROM coin_lots l
+  JOIN employees e ON e.id = l.employee_id
+  WHERE l.expires_at <= NOW() AND l.remaining > 0
+  ORDER BY l.id
+  LIMIT sqlc.arg(batch_size)::integer
+  FOR UPDATE OF l, e SKIP LOCKED
+),
+cleared AS (
+  UPDATE coin_lots l
+  SET remaining = 0
+  FROM stale
+  WHERE l.id = stale.id
+  RETURNING stale.employee_id, stale.remaining
+),
+totals AS (
+  SELECT employee_id, SUM(remaining)::integer AS amount
+  FROM cleared
+  GROUP BY employee_id
+),
+debited AS (
+  UPDATE employees e
+  SET coins = e.coins - totals.amount
+  FROM totals
+  WHERE e.id = totals.employee_id
+),
+expired AS (
+  INSERT INTO coin_transactions (transaction_type, from_employee_id, amount)
+  SELECT 'expiry', totals.employee_id, totals.amount
+  FROM totals
+  RETURNING id, from_employee_id, amount
+),
+entries AS (
+  INSERT INTO journal_entries (entry_type, coin_transaction_id)
+  SELECT 'expiry', expired.id FROM expired
+  RETURNING id, coin_transaction_id
+),
+postings AS (
+  INSERT INTO ledger_postings (entry_id, account_id, amount)
+  SELECT entries.id, leg.account_id, leg.amount
+  FROM entries
+  JOIN expired ON expired.id = entries.coin_transaction_id,
+  LATERAL (VALUES
+    ((SELECT id FROM ledger_accounts WHERE employee_id = expired.from_employee_id), -expired.amount),
+    ((SELECT id FROM ledger_accounts WHERE code = 'expired'), expired.amount)
+  ) AS leg(account_id, amount)
+)
+SELECT from_employee_id::integer AS employee_id, amount FROM expired;
+
+------------------------------------------------------------
+-- GetUpcomingExpirations возвращает монеты сотрудника, срок действия которых
+-- истекает в ближайшие 90 дней, с группировкой по дню истечения.
+-- name: GetUpcomingExpirations :many
+SELECT
+  date_trunc('day', expires_at)::timestamptz AS expires_on,
+  SUM(remaining)::integer AS amount
+FROM coin_lots
+WHERE employee_id = sqlc.arg(employee_id)::integer
+  AND remaining > 0
+  AND expires_at <= NOW() + INTERVAL '90 days'
+GROUP BY 1
+ORDER BY 1;
+
+------------------------------------------------------------
+-- CreatePurchaseLot запоминает часть партии, израсходованную на покупку.
+-- name: CreatePurchaseLot :exec
+INSERT INTO purchase_lots (purchase_id, amount, remaining, granted_at, expires_at)
+VALUES (
+  sqlc.arg(purchase_id)::integer,
+  sqlc.arg(amount)::integer,
+  sqlc.arg(amount)::integer,
+  sqlc.arg(granted_at)::timestamptz,
+  sqlc.arg(expires_at)::timestamptz
+);
+
+------------------------------------------------------------
+-- TakePurchaseLots забирает amount монет из ещё не возвращённых частей партий
+-- покупки в том порядке, в котором они были израсходованы. Возвращает взятые
+-- части с исходными сроками действия; если частей не хватает (покупка сделана
+-- до их учёта), сумма взятого меньше amount.
+-- name: TakePurchaseLots :many
+WITH locked AS (
+  SELECT id, remaining, granted_at, expires_at
+  FROM purchase_lots
+  WHERE purchase_id = sqlc.arg(purchase_id)::integer AND remaining > 0
+  ORDER BY expires_at, id
+  FOR UPDATE
+),
+ordered AS (
+  SELECT id, remaining, granted_at, expires_at,
+         SUM(remaining) OVER (ORDER BY expires_at, id) - remaining AS taken_before
+  FROM locked
+),
+taken AS (
+  SELECT id, granted_at, expires_at,
+         LEAST(remaining, sqlc.arg(amount)::integer - taken_before)::integer AS taken
+  FROM ordered
+  WHERE taken_before < sqlc.arg(amount)::integer
+)
+UPDATE purchase_lots p
+SET remaining = p.remaining - taken.taken
+FROM taken
+WHERE p.id = taken.id
+RETURNING p.id, taken.taken, taken.granted_at, taken.expires_at;
@@ -0,0 +1,74 @@
+-- CreateCoinRequest создаёт ожидающий запрос монет.
+-- name: CreateCoinRequest :one
+INSERT INTO coin_requests (requester_id, payer_id, amount, note, expires_at)
+VALUES (
+  sqlc.arg(requester_id)::integer,
+  sqlc.arg(payer_id)::integer,
+  sqlc.arg(amount)::integer,
+  sqlc.arg(note)::text,
+  sqlc.arg(expires_at)::timestamptz
+)
+RETURNING id, requester_id, payer_id, amount, note, status, created_at, expires_at, resolved_at;
+
+------------------------------------------------------------
+-- GetCoinRequestForUpdate возвращает запрос и блокирует его до конца транзакции.
+-- name: GetCoinRequestForUpdate :one
+SELECT
+  id,
+  requester_id,
+  payer_id,
+  amount,
+  note,
+  status,
+  created_at,
+  expires_at,
+  resolved_at
+FROM coin_requests
+WHERE id = $1
+FOR UPDATE;
+
+------------------------------------------------------------
+-- ResolveCoinRequest закрывает ожидающий запрос с указанным решением.
+-- name: ResolveCoinRequest :exec
+UPDATE coin_requests
+SET
+  status = sqlc.arg(status),
+  resolved_at = NOW()
+WHERE id = sqlc.arg(id) AND status = 'pending';
+
+------------------------------------------------------------
+-- ListCoinRequests возвращает последние 100 запросов монет. NULL в фильтре
+-- означает отсутствие ограничения по этому полю. Ожидающий запрос с истёкшим
+-- сроком показывается как expired, даже если фоновая задача его ещё не закрыла.
+-- name: ListCoinRequests :many
+SELECT
+  r.id,
+  requester.username AS requester,
+  payer.username AS payer,
+  r.amount,
+  r.note,
+  (CASE WHEN r.status = 'pending' AND r.expires_at <= NOW() THEN 'expired' ELSE r.status END)::coin_request_status_enum AS status,
+  r.created_at,
+  r.expires_at,
+  r.resolved_at
+FROM coin_requests r
+JOIN employees requester ON requester.id = r.requester_id
+JOIN employees payer ON payer.id = r.payer_id
+WHERE (sqlc.narg(id)::integer IS NULL OR r.id = sqlc.narg(id)::integer)
+  AND (sqlc.narg(requester_id)::integer IS NULL OR r.requester_id = sqlc.narg(requester_id)::integer)
+  AND (sqlc.narg(payer_id)::integer IS NULL OR r.payer_id = sqlc.narg(payer_id)::integer)
+  AND (
+    sqlc.narg(status)::coin_request_status_enum IS NULL
+    OR (CASE WHEN r.status = 'pending' AND r.expires_at <= NOW() THEN 'expired' ELSE r.status END) = sqlc.narg(status)::coin_request_status_enum
+  )
+ORDER BY r.id DESC
+LIMIT 100;
+
+------------------------------------------------------------
+-- ExpireCoinRequests закрывает ожидающие запросы с истёкшим сроком.
+-- name: ExpireCoinRequests :execrows
+UPDATE coin_requests
+SET
+  status = 'expired',
+  resolved_at = expires_at
+WHERE status = 'pending' AND expires_at <= NOW();
@@ -1,12 +1,98 @@
 -- CreateCoinTransactionTransfer вставляет запись о переводе монет между сотрудниками.
--- $1 - id отправителя, $2 - id получателя, $3 - сумма перевода.
+-- $1 - id отправителя, $2 - id получателя, $3 - сумма перевода,
+-- $4 - сообщение (NULL, если не указано), $5 - хэштеги из сообщения.
+-- Каждая запись истории сопровождается проводкой в книге в том же запросе,
+-- поэтому история и книга не расходятся; отсутствующий счёт даёт NULL
+-- в account_id и отклоняет весь запрос.
 -- name: CreateCoinTransactionTransfer :exec
-INSERT INTO coin_transactions (transaction_type, from_employee_id, to_employee_id, amount)
-VALUES ('transfer', $1, $2, $3);
+WITH tx AS (
+  INSERT INTO coin_transactions (transaction_type, from_employee_id, to_employee_id, amount, message, hashtags)
+  VALUES ('transfer', sqlc.arg(from_employee_id)::integer, sqlc.arg(to_employee_id), sqlc.arg(amount), sqlc.narg(message)::text, sqlc.arg(hashtags)::text[])
+  RETURNING id, from_employee_id, to_employee_id, amount
+),
+entry AS (
+  INSERT INTO journal_entries (entry_type, coin_transaction_id)
+  SELECT 'transfer', tx.id FROM tx
+  RETURNING id
+)
+INSERT INTO ledger_postings (entry_id, account_id, amount)
+SELECT entry.id, leg.account_id, leg.amount
+FROM entry, tx, LATERAL (VALUES
+  ((SELECT id FROM ledger_accounts WHERE employee_id = tx.from_employee_id), -tx.amount),
+  ((SELECT id FROM ledger_accounts WHERE employee_id = tx.to_employee_id), tx.amount)
+) AS leg(account_id, amount);
 
 ------------------------------------------------------------
 -- CreateCoinTransactionPurchase вставляет запись о покупке мерча.
--- $1 - id сотрудника (покупателя), $2 - id мерча, $3 - сумма (обычно равна цене товара).
--- name: CreateCoinTransactionPurchase :exec
-INSERT INTO coin_transactions (transaction_type, from_employee_id, merch_id, amount)
-VALUES ('purchase', $1, $2, $3);
+-- $1 - id сотрудника (покупателя), $2 - id мерча, $3 - общая сумма,
+-- $4 - количество единиц, $5 - цена за единицу на момент покупки.
+-- Монеты переходят на счёт выручки магазина.
+-- Возвращает id покупки, по которому создаётся заказ на выдачу.
+-- name: CreateCoinTransactionPurchase :one
+WITH tx AS (
+  INSERT INTO coin_transactions (transaction_type, from_employee_id, merch_id, amount, quantity, unit_price)
+  VALUES ('purchase', sqlc.arg(from_employee_id)::integer, sqlc.arg(merch_id), sqlc.arg(amount), sqlc.arg(quantity), sqlc.arg(unit_price))
+  RETURNING id, from_employee_id, amount
+),
+entry AS (
+  INSERT INTO journal_entries (entry_type, coin_transaction_id)
+  SELECT 'purchase', tx.id FROM tx
+  RETURNING id
+),
+postings AS (
+  INSERT INTO ledger_postings (entry_id, account_id, amount)
+  SELECT entry.id, leg.account_id, leg.amount
+  FROM entry, tx, LATERAL (VALUES
+    ((SELECT id FROM ledger_accounts WHERE employee_id = tx.from_employee_id), -tx.amount),
+    ((SELECT id FROM ledger_accounts WHERE code = 'store_revenue'), tx.amount)
+  ) AS leg(account_id, amount)
+)
+SELECT id FROM tx;
+
+------------------------------------------------------------
+-- CreateCoinTransactionGrant записывает начисление монет администратором
+-- со счёта выпуска.
+-- name: CreateCoinTransactionGrant :one
+WITH tx AS (
+  INSERT INTO coin_transactions (transaction_type, to_employee_id, amount, reason, created_by)
+  VALUES ('grant', sqlc.arg(to_employee_id)::integer, sqlc.arg(amount), sqlc.arg(reason)::text, sqlc.arg(created_by)::integer)
+  RETURNING id, to_employee_id, amount
+),
+entry AS (
+  INSERT INTO journal_entries (entry_type, coin_transaction_id)
+  SELECT 'grant', tx.id FROM tx
+  RETURNING id
+),
+postings AS (
+  INSERT INTO ledger_postings (entry_id, account_id, amount)
+  SELECT entry.id, leg.account_id, leg.amount
+  FROM entry, tx, LATERAL (VALUES
+    ((SELECT id FROM ledger_accounts WHERE code = 'issuance'), -tx.amount),
+    ((SELECT id FROM ledger_accounts WHERE employee_id = tx.to_employee_id), tx.amount)
+  ) AS leg(account_id, amount)
+)
+SELECT id FROM tx;
+
+------------------------------------------------------------
+-- CreateCoinTransactionAdjustment записывает списание монет администратором:
+-- монеты возвращаются на счёт выпуска.
+-- name: CreateCoinTransactionAdjustment :one
+WITH tx AS (
+  INSERT INTO coin_transactions (transaction_type, from_employee_id, amount, reason, created_by)
+  VALUES ('adjustment', sqlc.arg(from_employee_id)::integer, sqlc.arg(amount), sqlc.arg(reason)::text, sqlc.arg(created_by)::integer)
+  RETURNING id, from_employee_id, amount
+),
+entry AS (
+  INSERT INTO journal_entries (entry_type, coin_transaction_id)
+  SELECT 'adjustment', tx.id FROM tx
+  RETURNING id
+),
+postings AS (
+  INSERT INTO ledger_postings (entry_id, account_id, amount)
+  SELECT entry.id, leg.account_id, leg.amount
+  FROM entry, tx, LATERAL (VALUES
+    ((SELECT id FROM ledger_accounts WHERE employee_id = tx.from_employee_id), -tx.amount),
+    ((SELECT id FROM ledger_accounts WHERE code = 'issuance'), tx.amount)
+  ) AS leg(account_id, amount)
+)
+SELECT id FROM tx;
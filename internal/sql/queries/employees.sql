@@ -5,23 +5,52 @@ SELECT
   username,
   password_hash,
   coins,
-  created_at
+  created_at,
+  role
 FROM employees
 WHERE username = $1;
 
 ------------------------------------------------------------
 -- CreateEmployee создаёт нового сотрудника с указанным username и password_hash.
--- При создании coins устанавливается значение по умолчанию (1000).
+-- При создании coins устанавливается значение по умолчанию (1000);
+-- стартовые монеты записываются партией со сроком действия. Вместе с сотрудником
+-- открывается его счёт в книге, стартовые монеты проводятся со счёта выпуска.
 -- name: CreateEmployee :one
-INSERT INTO employees (username, password_hash)
-VALUES ($1, $2)
-RETURNING id, username, coins, password_hash;
+WITH created AS (
+  INSERT INTO employees (username, password_hash)
+  VALUES ($1, $2)
+  RETURNING id, username, coins, password_hash, role
+),
+lot AS (
+  INSERT INTO coin_lots (employee_id, amount, remaining)
+  SELECT created.id, created.coins, created.coins FROM created WHERE created.coins > 0
+),
+account AS (
+  INSERT INTO ledger_accounts (employee_id)
+  SELECT created.id FROM created
+  RETURNING id
+),
+entry AS (
+  INSERT INTO journal_entries (entry_type)
+  SELECT 'signup' FROM created WHERE created.coins > 0
+  RETURNING id
+),
+postings AS (
+  INSERT INTO ledger_postings (entry_id, account_id, amount)
+  SELECT entry.id, leg.account_id, leg.amount
+  FROM entry, created, account, LATERAL (VALUES
+    ((SELECT id FROM ledger_accounts WHERE code = 'issuance'), -created.coins),
+    (account.id, created.coins)
+  ) AS leg(account_id, amount)
+)
+SELECT id, username, coins, password_hash, role FROM created;
 
 ------------------------------------------------------------
 -- UpdateEmployeeCoins обновляет баланс сотрудника.
 -- Параметр $2 может быть положительным (начисление) или отрицательным (списание).
--- Здесь добавлена проверка, чтобы новый баланс не стал отрицательным.
--- name: UpdateEmployeeCoins :exec
+-- Здесь добавлена проверка, чтобы новый баланс не стал отрицательным:
+-- если списание невозможно, строка не обновляется и возвращается 0.
+-- name: UpdateEmployeeCoins :execrows
 UPDATE employees
 SET coins = coins + $2
 WHERE id = $1 AND coins + $2 >= 0;
@@ -42,3 +71,35 @@ WHERE id = $1;
 -- Получение баланса по ID сотрудника.
 -- name: GetCoinsByID :one
 SELECT coins FROM employees WHERE id=$1;
+
+------------------------------------------------------------
+-- LockEmployeeCoins возвращает баланс сотрудника и блокирует его строку
+-- до конца транзакции: параллельные списания ждут её завершения.
+-- name: LockEmployeeCoins :one
+SELECT coins FROM employees WHERE id = $1 FOR UPDATE;
+
+------------------------------------------------------------
+-- LockEmployeeBalances блокирует строки нескольких сотрудников в порядке
+-- возрастания id и возвращает их балансы. Единый порядок блокировок не даёт
+-- встречным переводам взаимно заблокировать друг друга.
+-- name: LockEmployeeBalances :many
+SELECT id, coins
+FROM employees
+WHERE id = ANY(sqlc.arg(ids)::integer[])
+ORDER BY id
+FOR UPDATE;
+
+------------------------------------------------------------
+-- SetEmployeeRole назначает роль сотруднику по username.
+-- name: SetEmployeeRole :one
+UPDATE employees
+SET role = $2
+WHERE username = $1
+RETURNING id, username, coins, password_hash, role;
+
+------------------------------------------------------------
+-- GetEmployeeRole возвращает текущую роль сотрудника по id.
+-- name: GetEmployeeRole :one
+SELECT role
+FROM employees
+WHERE id = $1;
@@ -0,0 +1,48 @@
+-- ReserveIdempotencyKey занимает ключ идемпотентности перед выполнением запроса.
+-- Просроченная запись (created_at < expired_before) перезаписывается.
+-- 0 затронутых строк означает, что ключ уже занят действующей записью.
+-- name: ReserveIdempotencyKey :execrows
+INSERT INTO idempotency_keys (employee_id, idempotency_key, request_hash)
+VALUES (sqlc.arg('employee_id'), sqlc.arg('idempotency_key'), sqlc.arg('request_hash'))
+ON CONFLICT (employee_id, idempotency_key)
+DO UPDATE SET
+  request_hash = EXCLUDED.request_hash,
+  response_status = NULL,
+  response_body = NULL,
+  created_at = NOW()
+WHERE idempotency_keys.created_at < sqlc.arg('expired_before');
+
+------------------------------------------------------------
+-- GetIdempotencyKey возвращает сохранённую запись по ключу.
+-- name: GetIdempotencyKey :one
+SELECT 
+  employee_id,
+  idempotency_key,
+  request_hash,
+  response_status,
+  response_body,
+  created_at
+FROM idempotency_keys
+WHERE employee_id = $1 AND idempotency_key = $2;
+
+------------------------------------------------------------
+-- SaveIdempotencyResponse сохраняет ответ на выполненный запрос.
+-- name: SaveIdempotencyResponse :exec
+UPDATE idempotency_keys
+SET 
+  response_status = $3,
+  response_body = $4
+WHERE employee_id = $1 AND idempotency_key = $2;
+
+------------------------------------------------------------
+-- DeleteIdempotencyKey освобождает ключ, если запрос завершился ошибкой сервера
+-- и его можно безопасно повторить.
+-- name: DeleteIdempotencyKey :exec
+DELETE FROM idempotency_keys
+WHERE employee_id = $1 AND idempotency_key = $2;
+
+------------------------------------------------------------
+-- DeleteExpiredIdempotencyKeys удаляет записи старше окна идемпотентности.
+-- name: DeleteExpiredIdempotencyKeys :execrows
+DELETE FROM idempotency_keys
+WHERE created_at < $1;
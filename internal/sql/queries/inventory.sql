@@ -18,3 +18,32 @@ FROM inventory i
 JOIN merch m ON i.merch_id = m.id
 WHERE i.employee_id = $1
 ORDER BY m.name;
+
+------------------------------------------------------------
+-- GetReceivedInventoryByEmployeeID возвращает товары, которые сотрудник уже
+-- получил на руки: инвентарь за вычетом заказов, ожидающих выдачи.
+-- name: GetReceivedInventoryByEmployeeID :many
+SELECT
+  m.name AS merch_name,
+  (i.quantity - COALESCE(p.pending, 0))::int AS quantity
+FROM inventory i
+JOIN merch m ON i.merch_id = m.id
+LEFT JOIN (
+  SELECT o.merch_id, SUM(o.quantity)::int AS pending
+  FROM orders o
+  WHERE o.employee_id = $1 AND o.status NOT IN ('delivered', 'cancelled')
+  GROUP BY o.merch_id
+) p ON p.merch_id = i.merch_id
+WHERE i.employee_id = $1
+  AND i.quantity - COALESCE(p.pending, 0) > 0
+ORDER BY m.name;
+
+------------------------------------------------------------
+-- RemoveInventory уменьшает количество товара в инвентаре сотрудника.
+-- 0 затронутых строк означает, что у сотрудника нет столько единиц товара.
+-- name: RemoveInventory :execrows
+UPDATE inventory
+SET quantity = quantity - sqlc.arg(quantity)
+WHERE employee_id = sqlc.arg(employee_id)
+  AND merch_id = sqlc.arg(merch_id)
+  AND quantity >= sqlc.arg(quantity);
@@ -0,0 +1,37 @@
+-- ListSystemAccountBalances возвращает остатки системных счетов книги.
+-- name: ListSystemAccountBalances :many
+SELECT
+  a.code::text AS code,
+  COALESCE(SUM(p.amount), 0)::bigint AS balance
+FROM ledger_accounts a
+LEFT JOIN ledger_postings p ON p.account_id = a.id
+WHERE a.code IS NOT NULL
+GROUP BY a.id, a.code
+ORDER BY a.id;
+
+------------------------------------------------------------
+-- ListLedgerBalanceMismatches возвращает сотрудников, у которых employees.coins
+-- не совпадает с остатком их счёта в книге.
+-- name: ListLedgerBalanceMismatches :many
+SELECT
+  e.id,
+  e.username,
+  e.coins,
+  COALESCE(SUM(p.amount), 0)::bigint AS ledger_balance
+FROM employees e
+LEFT JOIN ledger_accounts a ON a.employee_id = e.id
+LEFT JOIN ledger_postings p ON p.account_id = a.id
+GROUP BY e.id, e.username, e.coins
+HAVING e.coins <> COALESCE(SUM(p.amount), 0)
+ORDER BY e.id;
+
+------------------------------------------------------------
+-- CountUnbalancedJournalEntries считает проводки, сумма движений которых не равна нулю.
+-- name: CountUnbalancedJournalEntries :one
+SELECT COUNT(*) FROM (
+  SELECT entry_id
+  FROM ledger_postings
+  GROUP BY entry_id
+  HAVING SUM(amount) <> 0
+) unbalanced;
+
@@ -1,18 +1,95 @@
 -- GetMerchByName возвращает информацию о товаре по его названию.
+-- Снятые с продажи товары не возвращаются, поэтому купить их нельзя.
 -- name: GetMerchByName :one
 SELECT 
   id,
   name,
-  price
+  price,
+  active,
+  retired_at,
+  stock
 FROM merch
-WHERE name = $1;
+WHERE name = $1 AND active;
 
 ------------------------------------------------------------
--- ListMerch возвращает список всех товаров из мерча.
+-- ListMerch возвращает список всех товаров из мерча, доступных для покупки.
 -- name: ListMerch :many
 SELECT 
   id,
   name,
-  price
+  price,
+  active,
+  retired_at,
+  stock
 FROM merch
+WHERE active
 ORDER BY name;
+
+------------------------------------------------------------
+-- ListAllMerch возвращает все товары, включая снятые с продажи (для администратора).
+-- name: ListAllMerch :many
+SELECT 
+  id,
+  name,
+  price,
+  active,
+  retired_at,
+  stock
+FROM merch
+ORDER BY id;
+
+------------------------------------------------------------
+-- GetMerchByID возвращает товар по идентификатору независимо от его статуса.
+-- name: GetMerchByID :one
+SELECT 
+  id,
+  name,
+  price,
+  active,
+  retired_at,
+  stock
+FROM merch
+WHERE id = $1;
+
+------------------------------------------------------------
+-- CreateMerch добавляет новый товар в каталог.
+-- NULL в stock означает неограниченный остаток.
+-- name: CreateMerch :one
+INSERT INTO merch (name, price, stock)
+VALUES ($1, $2, $3)
+RETURNING id, name, price, active, retired_at, stock;
+
+------------------------------------------------------------
+-- UpdateMerch переименовывает товар, меняет его цену и/или остаток.
+-- NULL в name/price означает, что поле не меняется. Остаток меняется
+-- только при set_stock = TRUE, и тогда NULL делает его неограниченным.
+-- name: UpdateMerch :one
+UPDATE merch
+SET 
+  name = COALESCE(sqlc.narg('name'), name),
+  price = COALESCE(sqlc.narg('price'), price),
+  stock = CASE WHEN sqlc.arg('set_stock')::boolean THEN sqlc.narg('stock')::integer ELSE stock END
+WHERE id = sqlc.arg('id')
+RETURNING id, name, price, active, retired_at, stock;
+
+------------------------------------------------------------
+-- RetireMerch снимает товар с продажи. Строка остаётся в таблице,
+-- чтобы не нарушать внешние ключи inventory и coin_transactions.
+-- name: RetireMerch :one
+UPDATE merch
+SET 
+  active = FALSE,
+  retired_at = NOW()
+WHERE id = $1 AND active
+RETURNING id, name, price, active, retired_at, stock;
+
+------------------------------------------------------------
+-- DecrementMerchStock уменьшает остаток товара на $2 единиц.
+-- Условие проверяется под блокировкой строки, поэтому при параллельных
+-- покупках остаток не уходит в минус. Товары с stock = NULL не ограничены.
+-- 0 затронутых строк означает, что товара не хватает.
+-- name: DecrementMerchStock :execrows
+UPDATE merch
+SET stock = stock - sqlc.arg('quantity')::integer
+WHERE id = sqlc.arg('id')
+  AND (stock IS NULL OR stock >= sqlc.arg('quantity')::integer);
@@ -0,0 +1,116 @@
+-- CreateOrder создаёт заказ на выдачу товара по покупке.
+-- name: CreateOrder :exec
+INSERT INTO orders (purchase_id, employee_id, merch_id, quantity)
+VALUES ($1, $2, $3, $4);
+
+------------------------------------------------------------
+-- ListOrdersByEmployee возвращает заказы сотрудника, новые первыми.
+-- NULL в фильтре статуса означает все заказы.
+-- name: ListOrdersByEmployee :many
+SELECT 
+  o.id,
+  o.purchase_id,
+  m.name AS merch_name,
+  o.quantity,
+  o.status,
+  o.created_at,
+  o.updated_at
+FROM orders o
+JOIN merch m ON m.id = o.merch_id
+WHERE o.employee_id = sqlc.arg(employee_id)
+  AND (sqlc.narg(status)::order_status_enum IS NULL OR o.status = sqlc.narg(status)::order_status_enum)
+ORDER BY o.id DESC;
+
+------------------------------------------------------------
+-- GetOrderByEmployee возвращает заказ, только если он принадлежит сотруднику.
+-- name: GetOrderByEmployee :one
+SELECT 
+  o.id,
+  o.purchase_id,
+  m.name AS merch_name,
+  o.quantity,
+  o.status,
+  o.created_at,
+  o.updated_at
+FROM orders o
+JOIN merch m ON m.id = o.merch_id
+WHERE o.id = sqlc.arg(id) AND o.employee_id = sqlc.arg(employee_id);
+
+------------------------------------------------------------
+-- ListOrders возвращает заказы всех сотрудников, старые первыми, чтобы
+-- выдавать их в порядке очереди. NULL в фильтре статуса означает все заказы.
+-- name: ListOrders :many
+SELECT 
+  o.id,
+  o.purchase_id,
+  e.username,
+  m.name AS merch_name,
+  o.quantity,
+  o.status,
+  o.created_at,
+  o.updated_at
+FROM orders o
+JOIN employees e ON e.id = o.employee_id
+JOIN merch m ON m.id = o.merch_id
+WHERE (sqlc.narg(status)::order_status_enum IS NULL OR o.status = sqlc.narg(status)::order_status_enum)
+ORDER BY o.id;
+
+------------------------------------------------------------
+-- GetOrderStatusForUpdate возвращает статус заказа и блокирует его строку
+-- до конца транзакции, чтобы переходы статусов не выполнялись параллельно.
+-- name: GetOrderStatusForUpdate :one
+SELECT status FROM orders WHERE id = $1 FOR UPDATE;
+
+------------------------------------------------------------
+-- UpdateOrderStatus переводит заказ в новый статус и возвращает его.
+-- name: UpdateOrderStatus :one
+WITH updated AS (
+  UPDATE orders
+  SET status = sqlc.arg(status), updated_at = NOW()
+  WHERE orders.id = sqlc.arg(id)
+  RETURNING orders.id, orders.purchase_id, orders.employee_id, orders.merch_id, orders.quantity, orders.status, orders.created_at, orders.updated_at
+)
+SELECT 
+  u.id,
+  u.purchase_id,
+  e.username,
+  m.name AS merch_name,
+  u.quantity,
+  u.status,
+  u.created_at,
+  u.updated_at
+FROM updated u
+JOIN employees e ON e.id = u.employee_id
+JOIN merch m ON m.id = u.merch_id;
+
+------------------------------------------------------------
+-- CancelOrderByPurchase отменяет ещё не выданный заказ по покупке.
+-- Используется, когда покупка возвращена полностью.
+-- name: CancelOrderByPurchase :exec
+UPDATE orders
+SET status = 'cancelled', updated_at = NOW()
+WHERE purchase_id = $1 AND status NOT IN ('delivered', 'cancelled');
+
+------------------------------------------------------------
+-- ReduceOrderQuantity уменьшает количество в ещё не выданном заказе при
+-- частичном возврате покупки.
+-- name: ReduceOrderQuantity :exec
+UPDATE orders
+SET quantity = quantity - sqlc.arg(quantity)::integer, updated_at = NOW()
+WHERE purchase_id = sqlc.arg(purchase_id)::integer
+  AND status NOT IN ('delivered', 'cancelled')
+  AND quantity > sqlc.arg(quantity)::integer;
+
+------------------------------------------------------------
+-- GetPendingOrdersByEmployeeID возвращает ещё не выданные и не отменённые заказы сотрудника.
+-- name: GetPendingOrdersByEmployeeID :many
+SELECT 
+  o.id,
+  m.name AS merch_name,
+  o.quantity,
+  o.status,
+  o.created_at
+FROM orders o
+JOIN merch m ON m.id = o.merch_id
+WHERE o.employee_id = $1 AND o.status NOT IN ('delivered', 'cancelled')
+ORDER BY o.id;
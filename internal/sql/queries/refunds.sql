@@ -0,0 +1,135 @@
+-- GetPurchaseForUpdate возвращает покупку и блокирует её строку до конца
+-- транзакции, чтобы параллельные возвраты не превысили купленное количество.
+-- name: GetPurchaseForUpdate :one
+SELECT 
+  ct.id,
+  ct.from_employee_id,
+  ct.merch_id,
+  m.name AS merch_name,
+  ct.quantity,
+  ct.unit_price,
+  ct.amount
+FROM coin_transactions ct
+JOIN merch m ON m.id = ct.merch_id
+WHERE ct.id = $1 AND ct.transaction_type = 'purchase'
+FOR UPDATE OF ct;
+
+------------------------------------------------------------
+-- GetRefundedQuantity возвращает количество единиц покупки, уже возвращённых ранее.
+-- name: GetRefundedQuantity :one
+SELECT COALESCE(SUM(quantity), 0)::integer AS refunded
+FROM coin_transactions
+WHERE refund_of = sqlc.arg(purchase_id)::integer AND transaction_type = 'refund';
+
+------------------------------------------------------------
+-- CreateCoinTransactionRefund записывает возврат: монеты зачисляются покупателю
+-- со счёта выручки магазина, refund_of ссылается на исходную покупку.
+-- name: CreateCoinTransactionRefund :one
+WITH tx AS (
+  INSERT INTO coin_transactions (transaction_type, to_employee_id, merch_id, amount, quantity, unit_price, refund_of)
+  VALUES (
+    'refund',
+    sqlc.arg(to_employee_id)::integer,
+    sqlc.arg(merch_id)::integer,
+    sqlc.arg(amount),
+    sqlc.arg(quantity),
+    sqlc.arg(unit_price)::integer,
+    sqlc.arg(refund_of)::integer
+  )
+  RETURNING id, to_employee_id, amount
+),
+entry AS (
+  INSERT INTO journal_entries (entry_type, coin_transaction_id)
+  SELECT 'refund', tx.id FROM tx
+  RETURNING id
+),
+postings AS (
+  INSERT INTO ledger_postings (entry_id, account_id, amount)
+  SELECT entry.id, leg.account_id, leg.amount
+  FROM entry, tx, LATERAL (VALUES
+    ((SELECT id FROM ledger_accounts WHERE code = 'store_revenue'), -tx.amount),
+    ((SELECT id FROM ledger_accounts WHERE employee_id = tx.to_employee_id), tx.amount)
+  ) AS leg(account_id, amount)
+)
+SELECT id FROM tx;
+
+------------------------------------------------------------
+-- ListPurchasesByEmployee возвращает покупки сотрудника вместе с количеством
+-- уже возвращённых единиц.
+-- name: ListPurchasesByEmployee :many
+SELECT 
+  ct.id,
+  m.name AS merch_name,
+  ct.quantity,
+  ct.unit_price,
+  ct.amount,
+  ct.created_at,
+  COALESCE((
+    SELECT SUM(r.quantity)
+    FROM coin_transactions r
+    WHERE r.refund_of = ct.id AND r.transaction_type = 'refund'
+  ), 0)::integer AS refunded_quantity
+FROM coin_transactions ct
+JOIN merch m ON m.id = ct.merch_id
+WHERE ct.transaction_type = 'purchase'
+  AND ct.from_employee_id = sqlc.arg(employee_id)::integer
+ORDER BY ct.created_at DESC, ct.id DESC;
+
+------------------------------------------------------------
+-- CreateRefundRequest создаёт заявку сотрудника на возврат.
+-- name: CreateRefundRequest :one
+INSERT INTO refund_requests (purchase_id, employee_id, quantity, reason)
+VALUES ($1, $2, $3, $4)
+RETURNING id, purchase_id, employee_id, quantity, reason, status, refund_id, resolved_by, created_at, resolved_at;
+
+------------------------------------------------------------
+-- GetRefundRequestForUpdate возвращает заявку и блокирует её до конца транзакции.
+-- name: GetRefundRequestForUpdate :one
+SELECT 
+  id,
+  purchase_id,
+  employee_id,
+  quantity,
+  reason,
+  status,
+  refund_id,
+  resolved_by,
+  created_at,
+  resolved_at
+FROM refund_requests
+WHERE id = $1
+FOR UPDATE;
+
+------------------------------------------------------------
+-- ListRefundRequests возвращает заявки на возврат. NULL в фильтре означает
+-- отсутствие ограничения по этому полю.
+-- name: ListRefundRequests :many
+SELECT 
+  rr.id,
+  rr.purchase_id,
+  e.username,
+  m.name AS merch_name,
+  rr.quantity,
+  rr.reason,
+  rr.status,
+  rr.refund_id,
+  rr.created_at,
+  rr.resolved_at
+FROM refund_requests rr
+JOIN employees e ON e.id = rr.employee_id
+JOIN coin_transactions ct ON ct.id = rr.purchase_id
+JOIN merch m ON m.id = ct.merch_id
+WHERE (sqlc.narg(status)::refund_status_enum IS NULL OR rr.status = sqlc.narg(status)::refund_status_enum)
+  AND (sqlc.narg(employee_id)::integer IS NULL OR rr.employee_id = sqlc.narg(employee_id)::integer)
+ORDER BY rr.id DESC;
+
+------------------------------------------------------------
+-- ResolveRefundRequest закрывает ожидающую заявку с указанным решением.
+-- name: ResolveRefundRequest :exec
+UPDATE refund_requests
+SET 
+  status = sqlc.arg(status),
+  refund_id = sqlc.narg(refund_id),
+  resolved_by = sqlc.arg(resolved_by)::integer,
+  resolved_at = NOW()
+WHERE id = sqlc.arg(id) AND status = 'pending';
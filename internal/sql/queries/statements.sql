@@ -0,0 +1,56 @@
+-- ListStatementBalances возвращает сотрудников, зарегистрированных до конца
+-- периода, и их баланс на начало периода. employees.coins хранит только
+-- текущий баланс, поэтому входящий остаток восстанавливается в обратную
+-- сторону: из текущего баланса вычитаются зачисления и прибавляются списания с
+-- начала периода. Чтобы остаток сходился со строками ListStatementEntries,
+-- оба запроса выполняются в одной транзакции REPEATABLE READ и видят один
+-- снимок. Для сотрудников, зарегистрированных внутри периода
+-- (created_at >= period_start), это стартовые монеты на момент регистрации,
+-- а не входящий остаток. employee_id со значением NULL означает всех сотрудников.
+-- name: ListStatementBalances :many
+SELECT
+  e.id,
+  e.username,
+  e.created_at,
+  (e.coins - COALESCE(SUM(CASE WHEN ct.to_employee_id = e.id THEN ct.amount ELSE -ct.amount END), 0))::bigint AS opening_balance
+FROM employees e
+LEFT JOIN coin_transactions ct
+  ON (ct.from_employee_id = e.id OR ct.to_employee_id = e.id)
+ AND ct.created_at >= sqlc.arg(period_start)::timestamptz
+WHERE e.created_at < sqlc.arg(period_end)::timestamptz
+  AND (sqlc.narg(employee_id)::integer IS NULL OR e.id = sqlc.narg(employee_id)::integer)
+GROUP BY e.id
+ORDER BY e.id;
+
+-- ListStatementEntries возвращает операции за период [period_start, period_end)
+-- с точки зрения каждой из сторон: перевод попадает и в выписку отправителя, и
+-- в выписку получателя. Контрагент определяется так же, как в ListCoinHistory.
+-- Строки упорядочены по сотруднику и хронологически внутри выписки.
+-- name: ListStatementEntries :many
+SELECT
+  s.employee_id::integer AS employee_id,
+  ct.id,
+  ct.transaction_type,
+  s.incoming::boolean AS incoming,
+  ct.amount,
+  COALESCE(cp.username, '')::text AS counterparty,
+  COALESCE(m.name, '')::text AS merch_name,
+  ct.quantity,
+  COALESCE(ct.message, '')::text AS message,
+  COALESCE(ct.reason, '')::text AS reason,
+  ct.created_at
+FROM coin_transactions ct
+CROSS JOIN LATERAL (VALUES (ct.to_employee_id, true), (ct.from_employee_id, false)) AS s(employee_id, incoming)
+LEFT JOIN merch m ON m.id = ct.merch_id
+LEFT JOIN transfer_holds h ON h.id = ct.hold_id
+LEFT JOIN employees cp ON cp.id = CASE
+  WHEN ct.transaction_type = 'hold_release' THEN h.sender_id
+  WHEN ct.hold_id IS NOT NULL THEN h.recipient_id
+  WHEN s.incoming THEN ct.from_employee_id
+  ELSE ct.to_employee_id
+END
+WHERE s.employee_id IS NOT NULL
+  AND ct.created_at >= sqlc.arg(period_start)::timestamptz
+  AND ct.created_at < sqlc.arg(period_end)::timestamptz
+  AND (sqlc.narg(employee_id)::integer IS NULL OR s.employee_id = sqlc.narg(employee_id)::integer)
+ORDER BY s.employee_id, ct.created_at, ct.id;
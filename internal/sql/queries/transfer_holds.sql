@@ -0,0 +1,155 @@
+-- CreateTransferHold создаёт ожидающий решения получателя перевод.
+-- name: CreateTransferHold :one
+INSERT INTO transfer_holds (sender_id, recipient_id, amount, message, hashtags, expires_at)
+VALUES (
+  sqlc.arg(sender_id)::integer,
+  sqlc.arg(recipient_id)::integer,
+  sqlc.arg(amount)::integer,
+  sqlc.narg(message)::text,
+  sqlc.arg(hashtags)::text[],
+  sqlc.arg(expires_at)::timestamptz
+)
+RETURNING id, sender_id, recipient_id, amount, message, hashtags, status, created_at, expires_at, resolved_at;
+
+------------------------------------------------------------
+-- CreateTransferHoldLot сохраняет часть партии, списанную на удержание.
+-- name: CreateTransferHoldLot :exec
+INSERT INTO transfer_hold_lots (hold_id, amount, granted_at, expires_at)
+VALUES (
+  sqlc.arg(hold_id)::integer,
+  sqlc.arg(amount)::integer,
+  sqlc.arg(granted_at)::timestamptz,
+  sqlc.arg(expires_at)::timestamptz
+);
+
+------------------------------------------------------------
+-- ListTransferHoldLots возвращает части партий удержания.
+-- name: ListTransferHoldLots :many
+SELECT amount, granted_at, expires_at
+FROM transfer_hold_lots
+WHERE hold_id = sqlc.arg(hold_id)::integer
+ORDER BY expires_at, id;
+
+------------------------------------------------------------
+-- CreateCoinTransactionHold записывает списание отправителя на счёт удержания.
+-- name: CreateCoinTransactionHold :exec
+WITH tx AS (
+  INSERT INTO coin_transactions (transaction_type, from_employee_id, amount, message, hashtags, hold_id)
+  VALUES (
+    'hold',
+    sqlc.arg(from_employee_id)::integer,
+    sqlc.arg(amount)::integer,
+    sqlc.narg(message)::text,
+    sqlc.arg(hashtags)::text[],
+    sqlc.arg(hold_id)::integer
+  )
+  RETURNING id, from_employee_id, amount
+),
+entry AS (
+  INSERT INTO journal_entries (entry_type, coin_transaction_id)
+  SELECT 'hold', tx.id FROM tx
+  RETURNING id
+)
+INSERT INTO ledger_postings (entry_id, account_id, amount)
+SELECT entry.id, leg.account_id, leg.amount
+FROM entry, tx, LATERAL (VALUES
+  ((SELECT id FROM ledger_accounts WHERE employee_id = tx.from_employee_id), -tx.amount),
+  ((SELECT id FROM ledger_accounts WHERE code = 'escrow'), tx.amount)
+) AS leg(account_id, amount);
+
+------------------------------------------------------------
+-- CreateCoinTransactionHoldRelease записывает зачисление удержанных монет получателю.
+-- name: CreateCoinTransactionHoldRelease :exec
+WITH tx AS (
+  INSERT INTO coin_transactions (transaction_type, to_employee_id, amount, hold_id)
+  VALUES ('hold_release', sqlc.arg(to_employee_id)::integer, sqlc.arg(amount)::integer, sqlc.arg(hold_id)::integer)
+  RETURNING id, to_employee_id, amount
+),
+entry AS (
+  INSERT INTO journal_entries (entry_type, coin_transaction_id)
+  SELECT 'hold_release', tx.id FROM tx
+  RETURNING id
+)
+INSERT INTO ledger_postings (entry_id, account_id, amount)
+SELECT entry.id, leg.account_id, leg.amount
+FROM entry, tx, LATERAL (VALUES
+  ((SELECT id FROM ledger_accounts WHERE code = 'escrow'), -tx.amount),
+  ((SELECT id FROM ledger_accounts WHERE employee_id = tx.to_employee_id), tx.amount)
+) AS leg(account_id, amount);
+
+------------------------------------------------------------
+-- CreateCoinTransactionHoldRefund записывает возврат удержанных монет отправителю.
+-- name: CreateCoinTransactionHoldRefund :exec
+WITH tx AS (
+  INSERT INTO coin_transactions (transaction_type, to_employee_id, amount, hold_id)
+  VALUES ('hold_refund', sqlc.arg(to_employee_id)::integer, sqlc.arg(amount)::integer, sqlc.arg(hold_id)::integer)
+  RETURNING id, to_employee_id, amount
+),
+entry AS (
+  INSERT INTO journal_entries (entry_type, coin_transaction_id)
+  SELECT 'hold_refund', tx.id FROM tx
+  RETURNING id
+)
+INSERT INTO ledger_postings (entry_id, account_id, amount)
+SELECT entry.id, leg.account_id, leg.amount
+FROM entry, tx, LATERAL (VALUES
+  ((SELECT id FROM ledger_accounts WHERE code = 'escrow'), -tx.amount),
+  ((SELECT id FROM ledger_accounts WHERE employee_id = tx.to_employee_id), tx.amount)
+) AS leg(account_id, amount);
+
+------------------------------------------------------------
+-- GetTransferHoldForUpdate возвращает удержание и блокирует его до конца транзакции.
+-- name: GetTransferHoldForUpdate :one
+SELECT id, sender_id, recipient_id, amount, message, hashtags, status, created_at, expires_at, resolved_at
+FROM transfer_holds
+WHERE id = $1
+FOR UPDATE;
+
+------------------------------------------------------------
+-- ListExpiredTransferHolds блокирует очередную порцию ожидающих удержаний
+-- с истёкшим сроком. Заблокированные другими транзакциями пропускаются.
+-- name: ListExpiredTransferHolds :many
+SELECT id, sender_id, recipient_id, amount, message, hashtags, status, created_at, expires_at, resolved_at
+FROM transfer_holds
+WHERE status = 'pending' AND expires_at <= NOW()
+ORDER BY id
+LIMIT sqlc.arg(batch_size)::integer
+FOR UPDATE SKIP LOCKED;
+
+------------------------------------------------------------
+-- ResolveTransferHold закрывает ожидающее удержание с указанным итогом.
+-- name: ResolveTransferHold :exec
+UPDATE transfer_holds
+SET
+  status = sqlc.arg(status),
+  resolved_at = NOW()
+WHERE id = sqlc.arg(id) AND status = 'pending';
+
+------------------------------------------------------------
+-- ListTransferHolds возвращает последние 100 удержаний. NULL в фильтре означает
+-- отсутствие ограничения по этому полю. Ожидающее удержание с истёкшим сроком
+-- показывается как expired, даже если фоновая задача ещё не вернула монеты.
+-- name: ListTransferHolds :many
+SELECT
+  h.id,
+  sender.username AS sender,
+  recipient.username AS recipient,
+  h.amount,
+  COALESCE(h.message, '')::text AS message,
+  h.hashtags,
+  (CASE WHEN h.status = 'pending' AND h.expires_at <= NOW() THEN 'expired' ELSE h.status END)::transfer_hold_status_enum AS status,
+  h.created_at,
+  h.expires_at,
+  h.resolved_at
+FROM transfer_holds h
+JOIN employees sender ON sender.id = h.sender_id
+JOIN employees recipient ON recipient.id = h.recipient_id
+WHERE (sqlc.narg(id)::integer IS NULL OR h.id = sqlc.narg(id)::integer)
+  AND (sqlc.narg(sender_id)::integer IS NULL OR h.sender_id = sqlc.narg(sender_id)::integer)
+  AND (sqlc.narg(recipient_id)::integer IS NULL OR h.recipient_id = sqlc.narg(recipient_id)::integer)
+  AND (
+    sqlc.narg(status)::transfer_hold_status_enum IS NULL
+    OR (CASE WHEN h.status = 'pending' AND h.expires_at <= NOW() THEN 'expired' ELSE h.status END) = sqlc.narg(status)::transfer_hold_status_enum
+  )
+ORDER BY h.id DESC
+LIMIT 100;
@@ -0,0 +1,55 @@
+-- GetTransferLimitOverride возвращает индивидуальные лимиты сотрудника.
+-- Если переопределений нет, все значения NULL.
+-- name: GetTransferLimitOverride :one
+SELECT
+  o.daily_limit,
+  o.monthly_limit,
+  o.recipient_limit
+FROM employees e
+LEFT JOIN transfer_limit_overrides o ON o.employee_id = e.id
+WHERE e.id = sqlc.arg(employee_id)::integer;
+
+------------------------------------------------------------
+-- GetOutgoingTransferTotals возвращает суммы исходящих переводов сотрудника
+-- с начала дня, с начала месяца и получателю recipient_id с начала месяца.
+-- Удержанные переводы учитываются с момента создания, пока монеты не
+-- вернулись отправителю.
+-- Переводы текущей транзакции тоже учитываются.
+-- name: GetOutgoingTransferTotals :one
+SELECT
+  COALESCE(SUM(ct.amount) FILTER (WHERE ct.created_at >= sqlc.arg(day_start)::timestamptz), 0)::bigint AS sent_today,
+  COALESCE(SUM(ct.amount), 0)::bigint AS sent_this_month,
+  COALESCE(SUM(ct.amount) FILTER (
+    WHERE COALESCE(ct.to_employee_id, h.recipient_id) = sqlc.arg(recipient_id)::integer
+  ), 0)::bigint AS sent_to_recipient
+FROM coin_transactions ct
+LEFT JOIN transfer_holds h ON h.id = ct.hold_id
+WHERE ct.transaction_type IN ('transfer', 'hold')
+  AND ct.from_employee_id = sqlc.arg(sender_id)::integer
+  AND ct.created_at >= sqlc.arg(month_start)::timestamptz
+  AND (h.status IS NULL OR h.status IN ('pending', 'accepted'));
+
+------------------------------------------------------------
+-- UpsertTransferLimitOverride задаёт индивидуальные лимиты сотрудника.
+-- name: UpsertTransferLimitOverride :exec
+INSERT INTO transfer_limit_overrides (employee_id, daily_limit, monthly_limit, recipient_limit, updated_by)
+VALUES (
+  sqlc.arg(employee_id)::integer,
+  sqlc.narg(daily_limit)::integer,
+  sqlc.narg(monthly_limit)::integer,
+  sqlc.narg(recipient_limit)::integer,
+  sqlc.arg(updated_by)::integer
+)
+ON CONFLICT (employee_id) DO UPDATE
+SET
+  daily_limit = EXCLUDED.daily_limit,
+  monthly_limit = EXCLUDED.monthly_limit,
+  recipient_limit = EXCLUDED.recipient_limit,
+  updated_by = EXCLUDED.updated_by,
+  updated_at = NOW();
+
+------------------------------------------------------------
+-- DeleteTransferLimitOverride возвращает сотруднику лимиты по умолчанию.
+-- name: DeleteTransferLimitOverride :execrows
+DELETE FROM transfer_limit_overrides
+WHERE employee_id = sqlc.arg(employee_id)::integer;
@@ -0,0 +1,11 @@
+-- +goose Up
+-- Товары не удаляются физически: на них ссылаются inventory и coin_transactions.
+-- Снятый с продажи товар помечается active = FALSE с датой retired_at.
+ALTER TABLE merch
+  ADD COLUMN active BOOLEAN NOT NULL DEFAULT TRUE,
+  ADD COLUMN retired_at TIMESTAMPTZ;
+
+-- +goose Down
+ALTER TABLE merch
+  DROP COLUMN retired_at,
+  DROP COLUMN active;
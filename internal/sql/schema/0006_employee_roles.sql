@@ -0,0 +1,9 @@
+-- +goose Up
+CREATE TYPE employee_role_enum AS ENUM ('employee', 'manager', 'admin');
+
+ALTER TABLE employees
+  ADD COLUMN role employee_role_enum NOT NULL DEFAULT 'employee';
+
+-- +goose Down
+ALTER TABLE employees DROP COLUMN role;
+DROP TYPE employee_role_enum;
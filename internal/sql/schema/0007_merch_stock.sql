@@ -0,0 +1,7 @@
+-- +goose Up
+-- Остаток товара на складе. NULL означает неограниченное количество.
+ALTER TABLE merch
+  ADD COLUMN stock INTEGER CHECK (stock >= 0);
+
+-- +goose Down
+ALTER TABLE merch DROP COLUMN stock;
@@ -0,0 +1,23 @@
+-- +goose Up
+-- Покупка может включать несколько единиц товара. Для покупок храним
+-- количество и цену за единицу на момент покупки: amount = unit_price * quantity.
+ALTER TABLE coin_transactions
+  ADD COLUMN quantity INTEGER NOT NULL DEFAULT 1 CHECK (quantity > 0),
+  ADD COLUMN unit_price INTEGER CHECK (unit_price > 0);
+
+-- До этой миграции покупалась ровно одна единица товара.
+UPDATE coin_transactions
+SET unit_price = amount
+WHERE transaction_type = 'purchase';
+
+ALTER TABLE coin_transactions
+  ADD CONSTRAINT coin_transactions_purchase_total_check CHECK (
+    transaction_type <> 'purchase'
+    OR (unit_price IS NOT NULL AND amount = unit_price * quantity)
+  );
+
+-- +goose Down
+ALTER TABLE coin_transactions
+  DROP CONSTRAINT coin_transactions_purchase_total_check,
+  DROP COLUMN unit_price,
+  DROP COLUMN quantity;
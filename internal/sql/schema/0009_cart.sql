@@ -0,0 +1,13 @@
+-- +goose Up
+-- Серверная корзина сотрудника. Цена не хранится: при оформлении заказа
+-- каждая позиция оценивается по текущей цене товара.
+CREATE TABLE cart_items (
+  employee_id INTEGER NOT NULL REFERENCES employees(id),
+  merch_id INTEGER NOT NULL REFERENCES merch(id),
+  quantity INTEGER NOT NULL CHECK (quantity > 0),
+  added_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  PRIMARY KEY (employee_id, merch_id)
+);
+
+-- +goose Down
+DROP TABLE cart_items;
@@ -0,0 +1,17 @@
+-- +goose Up
+-- Результаты запросов с заголовком Idempotency-Key. Ключ уникален в пределах
+-- сотрудника; пустой response_status означает, что запрос ещё выполняется.
+CREATE TABLE idempotency_keys (
+  employee_id INTEGER NOT NULL REFERENCES employees(id),
+  idempotency_key VARCHAR(255) NOT NULL,
+  request_hash VARCHAR(64) NOT NULL,
+  response_status INTEGER,
+  response_body BYTEA,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  PRIMARY KEY (employee_id, idempotency_key)
+);
+
+CREATE INDEX idx_idempotency_keys_created_at ON idempotency_keys(created_at);
+
+-- +goose Down
+DROP TABLE idempotency_keys;
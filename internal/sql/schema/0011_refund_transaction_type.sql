@@ -0,0 +1,8 @@
+-- +goose Up
+-- Новое значение enum нельзя использовать в той же транзакции, где оно
+-- добавлено, поэтому ограничения для возвратов — в следующей миграции.
+ALTER TYPE transaction_type_enum ADD VALUE 'refund';
+
+-- +goose Down
+-- Значение enum нельзя удалить без пересоздания типа; строки с типом
+-- refund удаляются откатом следующей миграции.
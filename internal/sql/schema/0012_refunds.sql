@@ -0,0 +1,54 @@
+-- +goose Up
+-- Возврат зачисляет монеты покупателю со стороны магазина, поэтому у него
+-- нет отправителя-сотрудника. refund_of ссылается на исходную покупку.
+ALTER TABLE coin_transactions
+  ALTER COLUMN from_employee_id DROP NOT NULL,
+  ADD COLUMN refund_of INTEGER REFERENCES coin_transactions(id);
+
+ALTER TABLE coin_transactions
+  DROP CONSTRAINT coin_transactions_check,
+  ADD CONSTRAINT coin_transactions_type_check CHECK (
+    (transaction_type = 'transfer' AND from_employee_id IS NOT NULL AND to_employee_id IS NOT NULL AND merch_id IS NULL AND refund_of IS NULL)
+    OR (transaction_type = 'purchase' AND from_employee_id IS NOT NULL AND to_employee_id IS NULL AND merch_id IS NOT NULL AND refund_of IS NULL)
+    OR (transaction_type = 'refund' AND from_employee_id IS NULL AND to_employee_id IS NOT NULL AND merch_id IS NOT NULL AND refund_of IS NOT NULL)
+  );
+
+CREATE INDEX idx_transactions_refund_of ON coin_transactions(refund_of) WHERE refund_of IS NOT NULL;
+
+-- Заявки сотрудников на возврат, ожидающие решения администратора.
+CREATE TYPE refund_status_enum AS ENUM ('pending', 'approved', 'rejected');
+
+CREATE TABLE refund_requests (
+  id SERIAL PRIMARY KEY,
+  purchase_id INTEGER NOT NULL REFERENCES coin_transactions(id),
+  employee_id INTEGER NOT NULL REFERENCES employees(id),
+  quantity INTEGER NOT NULL CHECK (quantity > 0),
+  reason TEXT NOT NULL DEFAULT '',
+  status refund_status_enum NOT NULL DEFAULT 'pending',
+  refund_id INTEGER REFERENCES coin_transactions(id),
+  resolved_by INTEGER REFERENCES employees(id),
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  resolved_at TIMESTAMPTZ,
+  CHECK ((status = 'approved') = (refund_id IS NOT NULL))
+);
+
+CREATE INDEX idx_refund_requests_employee ON refund_requests(employee_id);
+CREATE INDEX idx_refund_requests_status ON refund_requests(status);
+
+-- +goose Down
+DROP TABLE refund_requests;
+DROP TYPE refund_status_enum;
+
+DROP INDEX idx_transactions_refund_of;
+DELETE FROM coin_transactions WHERE transaction_type = 'refund';
+
+ALTER TABLE coin_transactions
+  DROP CONSTRAINT coin_transactions_type_check,
+  ADD CONSTRAINT coin_transactions_check CHECK (
+    (transaction_type = 'transfer' AND to_employee_id IS NOT NULL AND merch_id IS NULL)
+    OR (transaction_type = 'purchase' AND to_employee_id IS NULL AND merch_id IS NOT NULL)
+  );
+
+ALTER TABLE coin_transactions
+  DROP COLUMN refund_of,
+  ALTER COLUMN from_employee_id SET NOT NULL;
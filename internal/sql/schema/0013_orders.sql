@@ -0,0 +1,27 @@
+-- +goose Up
+-- Заказ на выдачу физического товара создаётся для каждой покупки.
+CREATE TYPE order_status_enum AS ENUM ('placed', 'packed', 'ready_for_pickup', 'delivered', 'cancelled');
+
+CREATE TABLE orders (
+  id SERIAL PRIMARY KEY,
+  purchase_id INTEGER NOT NULL UNIQUE REFERENCES coin_transactions(id),
+  employee_id INTEGER NOT NULL REFERENCES employees(id),
+  merch_id INTEGER NOT NULL REFERENCES merch(id),
+  quantity INTEGER NOT NULL CHECK (quantity > 0),
+  status order_status_enum NOT NULL DEFAULT 'placed',
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_orders_employee_status ON orders(employee_id, status);
+CREATE INDEX idx_orders_status ON orders(status);
+
+-- Покупки, сделанные до появления заказов, считаются уже выданными.
+INSERT INTO orders (purchase_id, employee_id, merch_id, quantity, status, created_at, updated_at)
+SELECT id, from_employee_id, merch_id, quantity, 'delivered', created_at, created_at
+FROM coin_transactions
+WHERE transaction_type = 'purchase';
+
+-- +goose Down
+DROP TABLE orders;
+DROP TYPE order_status_enum;
@@ -0,0 +1,9 @@
+-- +goose Up
+-- Начисления и списания администратором. Новые значения enum используются
+-- в ограничениях только в следующей миграции.
+ALTER TYPE transaction_type_enum ADD VALUE 'grant';
+ALTER TYPE transaction_type_enum ADD VALUE 'adjustment';
+
+-- +goose Down
+-- Значения enum нельзя удалить без пересоздания типа; строки с этими
+-- типами удаляются откатом следующей миграции.
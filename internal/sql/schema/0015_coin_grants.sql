@@ -0,0 +1,34 @@
+-- +goose Up
+-- grant зачисляет монеты сотруднику (to_employee_id), adjustment списывает
+-- их (from_employee_id). Для обоих типов обязательны причина и администратор,
+-- выполнивший операцию.
+ALTER TABLE coin_transactions
+  ADD COLUMN reason TEXT,
+  ADD COLUMN created_by INTEGER REFERENCES employees(id);
+
+ALTER TABLE coin_transactions
+  DROP CONSTRAINT coin_transactions_type_check,
+  ADD CONSTRAINT coin_transactions_type_check CHECK (
+    (transaction_type = 'transfer' AND from_employee_id IS NOT NULL AND to_employee_id IS NOT NULL AND merch_id IS NULL AND refund_of IS NULL)
+    OR (transaction_type = 'purchase' AND from_employee_id IS NOT NULL AND to_employee_id IS NULL AND merch_id IS NOT NULL AND refund_of IS NULL)
+    OR (transaction_type = 'refund' AND from_employee_id IS NULL AND to_employee_id IS NOT NULL AND merch_id IS NOT NULL AND refund_of IS NOT NULL)
+    OR (transaction_type = 'grant' AND from_employee_id IS NULL AND to_employee_id IS NOT NULL AND merch_id IS NULL AND refund_of IS NULL
+        AND reason <> '' AND created_by IS NOT NULL)
+    OR (transaction_type = 'adjustment' AND from_employee_id IS NOT NULL AND to_employee_id IS NULL AND merch_id IS NULL AND refund_of IS NULL
+        AND reason <> '' AND created_by IS NOT NULL)
+  );
+
+-- +goose Down
+DELETE FROM coin_transactions WHERE transaction_type IN ('grant', 'adjustment');
+
+ALTER TABLE coin_transactions
+  DROP CONSTRAINT coin_transactions_type_check,
+  ADD CONSTRAINT coin_transactions_type_check CHECK (
+    (transaction_type = 'transfer' AND from_employee_id IS NOT NULL AND to_employee_id IS NOT NULL AND merch_id IS NULL AND refund_of IS NULL)
+    OR (transaction_type = 'purchase' AND from_employee_id IS NOT NULL AND to_employee_id IS NULL AND merch_id IS NOT NULL AND refund_of IS NULL)
+    OR (transaction_type = 'refund' AND from_employee_id IS NULL AND to_employee_id IS NOT NULL AND merch_id IS NOT NULL AND refund_of IS NOT NULL)
+  );
+
+ALTER TABLE coin_transactions
+  DROP COLUMN created_by,
+  DROP COLUMN reason;
@@ -0,0 +1,8 @@
+-- +goose Up
+-- Ежемесячное начисление монет. Значение используется в ограничениях
+-- и индексе только в следующей миграции.
+ALTER TYPE transaction_type_enum ADD VALUE 'allowance';
+
+-- +goose Down
+-- Значение enum нельзя удалить без пересоздания типа; строки с типом
+-- allowance удаляются откатом следующей миграции.
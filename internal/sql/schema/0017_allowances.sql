@@ -0,0 +1,41 @@
+-- +goose Up
+-- period — первый день месяца, за который начислено пособие. Уникальный
+-- индекс гарантирует не более одного начисления сотруднику за период даже
+-- при перезапусках и нескольких репликах.
+ALTER TABLE coin_transactions ADD COLUMN period DATE;
+
+ALTER TABLE coin_transactions
+  DROP CONSTRAINT coin_transactions_type_check,
+  ADD CONSTRAINT coin_transactions_type_check CHECK (
+    (transaction_type = 'transfer' AND from_employee_id IS NOT NULL AND to_employee_id IS NOT NULL AND merch_id IS NULL AND refund_of IS NULL)
+    OR (transaction_type = 'purchase' AND from_employee_id IS NOT NULL AND to_employee_id IS NULL AND merch_id IS NOT NULL AND refund_of IS NULL)
+    OR (transaction_type = 'refund' AND from_employee_id IS NULL AND to_employee_id IS NOT NULL AND merch_id IS NOT NULL AND refund_of IS NOT NULL)
+    OR (transaction_type = 'grant' AND from_employee_id IS NULL AND to_employee_id IS NOT NULL AND merch_id IS NULL AND refund_of IS NULL
+        AND reason <> '' AND created_by IS NOT NULL)
+    OR (transaction_type = 'adjustment' AND from_employee_id IS NOT NULL AND to_employee_id IS NULL AND merch_id IS NULL AND refund_of IS NULL
+        AND reason <> '' AND created_by IS NOT NULL)
+    OR (transaction_type = 'allowance' AND from_employee_id IS NULL AND to_employee_id IS NOT NULL AND merch_id IS NULL AND refund_of IS NULL
+        AND period IS NOT NULL)
+  );
+
+CREATE UNIQUE INDEX idx_transactions_allowance_period
+  ON coin_transactions(to_employee_id, period)
+  WHERE transaction_type = 'allowance';
+
+-- +goose Down
+DROP INDEX idx_transactions_allowance_period;
+DELETE FROM coin_transactions WHERE transaction_type = 'allowance';
+
+ALTER TABLE coin_transactions
+  DROP CONSTRAINT coin_transactions_type_check,
+  ADD CONSTRAINT coin_transactions_type_check CHECK (
+    (transaction_type = 'transfer' AND from_employee_id IS NOT NULL AND to_employee_id IS NOT NULL AND merch_id IS NULL AND refund_of IS NULL)
+    OR (transaction_type = 'purchase' AND from_employee_id IS NOT NULL AND to_employee_id IS NULL AND merch_id IS NOT NULL AND refund_of IS NULL)
+    OR (transaction_type = 'refund' AND from_employee_id IS NULL AND to_employee_id IS NOT NULL AND merch_id IS NOT NULL AND refund_of IS NOT NULL)
+    OR (transaction_type = 'grant' AND from_employee_id IS NULL AND to_employee_id IS NOT NULL AND merch_id IS NULL AND refund_of IS NULL
+        AND reason <> '' AND created_by IS NOT NULL)
+    OR (transaction_type = 'adjustment' AND from_employee_id IS NOT NULL AND to_employee_id IS NULL AND merch_id IS NULL AND refund_of IS NULL
+        AND reason <> '' AND created_by IS NOT NULL)
+  );
+
+ALTER TABLE coin_transactions DROP COLUMN period;
@@ -0,0 +1,8 @@
+-- +goose Up
+-- Списание монет с истёкшим сроком действия. Значение используется
+-- в ограничениях только в следующей миграции.
+ALTER TYPE transaction_type_enum ADD VALUE 'expiry';
+
+-- +goose Down
+-- Значение enum нельзя удалить без пересоздания типа; строки с типом
+-- expiry удаляются откатом следующей миграции.
@@ -0,0 +1,54 @@
+-- +goose Up
+-- Партии монет: каждое зачисление создаёт партию со сроком действия 12 месяцев,
+-- списания расходуют партии с ближайшим сроком первыми (FIFO). Сумма remaining
+-- по партиям сотрудника равна employees.coins.
+CREATE TABLE coin_lots (
+  id SERIAL PRIMARY KEY,
+  employee_id INTEGER NOT NULL REFERENCES employees(id),
+  amount INTEGER NOT NULL CHECK (amount > 0),
+  remaining INTEGER NOT NULL CHECK (remaining >= 0 AND remaining <= amount),
+  granted_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  expires_at TIMESTAMPTZ NOT NULL DEFAULT NOW() + INTERVAL '12 months'
+);
+
+CREATE INDEX idx_coin_lots_employee_open ON coin_lots(employee_id, expires_at, id) WHERE remaining > 0;
+CREATE INDEX idx_coin_lots_expires_open ON coin_lots(expires_at) WHERE remaining > 0;
+
+-- Историю происхождения существующих балансов восстановить нельзя,
+-- поэтому срок действия текущих монет отсчитывается с момента миграции.
+INSERT INTO coin_lots (employee_id, amount, remaining)
+SELECT id, coins, coins FROM employees WHERE coins > 0;
+
+ALTER TABLE coin_transactions
+  DROP CONSTRAINT coin_transactions_type_check,
+  ADD CONSTRAINT coin_transactions_type_check CHECK (
+    (transaction_type = 'transfer' AND from_employee_id IS NOT NULL AND to_employee_id IS NOT NULL AND merch_id IS NULL AND refund_of IS NULL)
+    OR (transaction_type = 'purchase' AND from_employee_id IS NOT NULL AND to_employee_id IS NULL AND merch_id IS NOT NULL AND refund_of IS NULL)
+    OR (transaction_type = 'refund' AND from_employee_id IS NULL AND to_employee_id IS NOT NULL AND merch_id IS NOT NULL AND refund_of IS NOT NULL)
+    OR (transaction_type = 'grant' AND from_employee_id IS NULL AND to_employee_id IS NOT NULL AND merch_id IS NULL AND refund_of IS NULL
+        AND reason <> '' AND created_by IS NOT NULL)
+    OR (transaction_type = 'adjustment' AND from_employee_id IS NOT NULL AND to_employee_id IS NULL AND merch_id IS NULL AND refund_of IS NULL
+        AND reason <> '' AND created_by IS NOT NULL)
+    OR (transaction_type = 'allowance' AND from_employee_id IS NULL AND to_employee_id IS NOT NULL AND merch_id IS NULL AND refund_of IS NULL
+        AND period IS NOT NULL)
+    OR (transaction_type = 'expiry' AND from_employee_id IS NOT NULL AND to_employee_id IS NULL AND merch_id IS NULL AND refund_of IS NULL)
+  );
+
+-- +goose Down
+DELETE FROM coin_transactions WHERE transaction_type = 'expiry';
+
+ALTER TABLE coin_transactions
+  DROP CONSTRAINT coin_transactions_type_check,
+  ADD CONSTRAINT coin_transactions_type_check CHECK (
+    (transaction_type = 'transfer' AND from_employee_id IS NOT NULL AND to_employee_id IS NOT NULL AND merch_id IS NULL AND refund_of IS NULL)
+    OR (transaction_type = 'purchase' AND from_employee_id IS NOT NULL AND to_employee_id IS NULL AND merch_id IS NOT NULL AND refund_of IS NULL)
+    OR (transaction_type = 'refund' AND from_employee_id IS NULL AND to_employee_id IS NOT NULL AND merch_id IS NOT NULL AND refund_of IS NOT NULL)
+    OR (transaction_type = 'grant' AND from_employee_id IS NULL AND to_employee_id IS NOT NULL AND merch_id IS NULL AND refund_of IS NULL
+        AND reason <> '' AND created_by IS NOT NULL)
+    OR (transaction_type = 'adjustment' AND from_employee_id IS NOT NULL AND to_employee_id IS NULL AND merch_id IS NULL AND refund_of IS NULL
+        AND reason <> '' AND created_by IS NOT NULL)
+    OR (transaction_type = 'allowance' AND from_employee_id IS NULL AND to_employee_id IS NOT NULL AND merch_id IS NULL AND refund_of IS NULL
+        AND period IS NOT NULL)
+  );
+
+DROP TABLE coin_lots;
@@ -0,0 +1,86 @@
+-- +goose Up
+-- Двойная запись: каждая операция с монетами — проводка (journal_entries)
+-- из нескольких движений (ledger_postings) по счетам, сумма которых равна нулю.
+-- Положительная сумма движения увеличивает остаток счёта, отрицательная уменьшает.
+-- Счета сотрудников хранят их монеты; системные счета — источники и получатели:
+--   issuance      — выпуск монет (стартовые монеты, начисления, пособия; списания
+--                   администратором возвращают монеты сюда), остаток отрицательный;
+--   store_revenue — монеты, потраченные в магазине (за вычетом возвратов);
+--   expired       — монеты с истёкшим сроком действия.
+CREATE TABLE ledger_accounts (
+  id SERIAL PRIMARY KEY,
+  code TEXT UNIQUE,
+  employee_id INTEGER UNIQUE REFERENCES employees(id),
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  CHECK ((code IS NULL) <> (employee_id IS NULL))
+);
+
+INSERT INTO ledger_accounts (code) VALUES ('issuance'), ('store_revenue'), ('expired');
+
+CREATE TYPE journal_entry_type_enum AS ENUM (
+  'opening', 'signup', 'transfer', 'purchase', 'refund', 'grant', 'adjustment', 'allowance', 'expiry'
+);
+
+-- coin_transaction_id связывает проводку с записью истории операций;
+-- у стартовых монет и входящего остатка такой записи нет.
+CREATE TABLE journal_entries (
+  id SERIAL PRIMARY KEY,
+  entry_type journal_entry_type_enum NOT NULL,
+  coin_transaction_id INTEGER UNIQUE REFERENCES coin_transactions(id),
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE ledger_postings (
+  id SERIAL PRIMARY KEY,
+  entry_id INTEGER NOT NULL REFERENCES journal_entries(id),
+  account_id INTEGER NOT NULL REFERENCES ledger_accounts(id),
+  amount INTEGER NOT NULL CHECK (amount <> 0)
+);
+
+CREATE INDEX idx_ledger_postings_entry ON ledger_postings(entry_id);
+CREATE INDEX idx_ledger_postings_account ON ledger_postings(account_id);
+
+-- Сбалансированность проводки проверяется при фиксации транзакции,
+-- когда все её движения уже вставлены.
+-- +goose StatementBegin
+CREATE FUNCTION check_journal_entry_balanced() RETURNS trigger AS $$
+BEGIN
+  IF (SELECT SUM(amount) FROM ledger_postings WHERE entry_id = NEW.entry_id) <> 0 THEN
+    RAISE EXCEPTION 'journal entry % is not balanced', NEW.entry_id
+      USING ERRCODE = 'check_violation';
+  END IF;
+  RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE CONSTRAINT TRIGGER ledger_postings_balanced
+  AFTER INSERT ON ledger_postings
+  DEFERRABLE INITIALLY DEFERRED
+  FOR EACH ROW EXECUTE FUNCTION check_journal_entry_balanced();
+
+-- Историю до появления книги восстановить целиком нельзя, поэтому текущие
+-- балансы переносятся одной проводкой входящего остатка из счёта выпуска.
+INSERT INTO ledger_accounts (employee_id) SELECT id FROM employees ORDER BY id;
+
+WITH opening AS (
+  INSERT INTO journal_entries (entry_type)
+  SELECT 'opening' WHERE EXISTS (SELECT 1 FROM employees WHERE coins > 0)
+  RETURNING id
+)
+INSERT INTO ledger_postings (entry_id, account_id, amount)
+SELECT opening.id, a.id, e.coins
+FROM opening, employees e
+JOIN ledger_accounts a ON a.employee_id = e.id
+WHERE e.coins > 0
+UNION ALL
+SELECT opening.id, a.id, -(SELECT SUM(coins) FROM employees)::integer
+FROM opening, ledger_accounts a
+WHERE a.code = 'issuance';
+
+-- +goose Down
+DROP TABLE ledger_postings;
+DROP FUNCTION check_journal_entry_balanced();
+DROP TABLE journal_entries;
+DROP TYPE journal_entry_type_enum;
+DROP TABLE ledger_accounts;
@@ -0,0 +1,16 @@
+-- +goose Up
+-- Сообщение к переводу и хэштеги из него. Хэштеги хранятся отдельно
+-- в нижнем регистре, чтобы их можно было агрегировать без разбора текста.
+ALTER TABLE coin_transactions
+  ADD COLUMN message TEXT,
+  ADD COLUMN hashtags TEXT[] NOT NULL DEFAULT '{}';
+
+CREATE INDEX idx_transactions_hashtags ON coin_transactions USING GIN (hashtags)
+  WHERE hashtags <> '{}';
+
+-- +goose Down
+DROP INDEX idx_transactions_hashtags;
+
+ALTER TABLE coin_transactions
+  DROP COLUMN hashtags,
+  DROP COLUMN message;
@@ -0,0 +1,20 @@
+-- +goose Up
+-- Индивидуальные лимиты исходящих переводов. NULL означает лимит по умолчанию
+-- из конфигурации, 0 — отсутствие лимита.
+CREATE TABLE transfer_limit_overrides (
+  employee_id INTEGER PRIMARY KEY REFERENCES employees(id),
+  daily_limit INTEGER CHECK (daily_limit >= 0),
+  monthly_limit INTEGER CHECK (monthly_limit >= 0),
+  recipient_limit INTEGER CHECK (recipient_limit >= 0),
+  updated_by INTEGER NOT NULL REFERENCES employees(id),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Суммы исходящих переводов за день и месяц считаются при каждом переводе.
+CREATE INDEX idx_transactions_transfer_from_created
+  ON coin_transactions(from_employee_id, created_at)
+  WHERE transaction_type = 'transfer';
+
+-- +goose Down
+DROP INDEX idx_transactions_transfer_from_created;
+DROP TABLE transfer_limit_overrides;
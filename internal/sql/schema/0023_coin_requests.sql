@@ -0,0 +1,26 @@
+-- +goose Up
+-- Запросы монет: requester просит payer перевести ему amount монет.
+-- Принятие выполняет обычный перевод от payer к requester. Ожидающий запрос
+-- после expires_at считается просроченным; статус expired проставляется фоновой задачей.
+CREATE TYPE coin_request_status_enum AS ENUM ('pending', 'accepted', 'declined', 'expired');
+
+CREATE TABLE coin_requests (
+  id SERIAL PRIMARY KEY,
+  requester_id INTEGER NOT NULL REFERENCES employees(id),
+  payer_id INTEGER NOT NULL REFERENCES employees(id),
+  amount INTEGER NOT NULL CHECK (amount > 0),
+  note TEXT NOT NULL DEFAULT '',
+  status coin_request_status_enum NOT NULL DEFAULT 'pending',
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  expires_at TIMESTAMPTZ NOT NULL,
+  resolved_at TIMESTAMPTZ,
+  CHECK (requester_id <> payer_id)
+);
+
+CREATE INDEX idx_coin_requests_payer ON coin_requests(payer_id, status);
+CREATE INDEX idx_coin_requests_requester ON coin_requests(requester_id, status);
+CREATE INDEX idx_coin_requests_pending_expiry ON coin_requests(expires_at) WHERE status = 'pending';
+
+-- +goose Down
+DROP TABLE coin_requests;
+DROP TYPE coin_request_status_enum;
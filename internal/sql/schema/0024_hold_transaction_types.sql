@@ -0,0 +1,16 @@
+-- +goose Up
+-- Переводы с подтверждением: hold — списание отправителя на счёт удержания,
+-- hold_release — зачисление получателю после принятия, hold_refund — возврат
+-- отправителю после отказа или истечения срока. Значения используются
+-- в ограничениях только в следующей миграции.
+ALTER TYPE transaction_type_enum ADD VALUE 'hold';
+ALTER TYPE transaction_type_enum ADD VALUE 'hold_release';
+ALTER TYPE transaction_type_enum ADD VALUE 'hold_refund';
+
+ALTER TYPE journal_entry_type_enum ADD VALUE 'hold';
+ALTER TYPE journal_entry_type_enum ADD VALUE 'hold_release';
+ALTER TYPE journal_entry_type_enum ADD VALUE 'hold_refund';
+
+-- +goose Down
+-- Значения enum нельзя удалить без пересоздания типа; строки с этими
+-- типами удаляются откатом следующей миграции.
@@ -0,0 +1,102 @@
+-- +goose Up
+-- Удержанные переводы: монеты списываются с отправителя на системный счёт
+-- escrow и ждут решения получателя. Принятие зачисляет их получателю, отказ
+-- или истечение expires_at возвращает отправителю.
+INSERT INTO ledger_accounts (code) VALUES ('escrow');
+
+CREATE TYPE transfer_hold_status_enum AS ENUM ('pending', 'accepted', 'declined', 'expired');
+
+CREATE TABLE transfer_holds (
+  id SERIAL PRIMARY KEY,
+  sender_id INTEGER NOT NULL REFERENCES employees(id),
+  recipient_id INTEGER NOT NULL REFERENCES employees(id),
+  amount INTEGER NOT NULL CHECK (amount > 0),
+  message TEXT,
+  hashtags TEXT[] NOT NULL DEFAULT '{}',
+  status transfer_hold_status_enum NOT NULL DEFAULT 'pending',
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  expires_at TIMESTAMPTZ NOT NULL,
+  resolved_at TIMESTAMPTZ,
+  CHECK (sender_id <> recipient_id)
+);
+
+CREATE INDEX idx_transfer_holds_sender ON transfer_holds(sender_id, status);
+CREATE INDEX idx_transfer_holds_recipient ON transfer_holds(recipient_id, status);
+CREATE INDEX idx_transfer_holds_pending_expiry ON transfer_holds(expires_at) WHERE status = 'pending';
+
+-- Части партий, списанные с отправителя. При расчёте по удержанию монеты
+-- зачисляются с исходными сроками действия, как при обычном переводе.
+CREATE TABLE transfer_hold_lots (
+  id SERIAL PRIMARY KEY,
+  hold_id INTEGER NOT NULL REFERENCES transfer_holds(id),
+  amount INTEGER NOT NULL CHECK (amount > 0),
+  granted_at TIMESTAMPTZ NOT NULL,
+  expires_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX idx_transfer_hold_lots_hold ON transfer_hold_lots(hold_id);
+
+ALTER TABLE coin_transactions ADD COLUMN hold_id INTEGER REFERENCES transfer_holds(id);
+
+CREATE INDEX idx_transactions_hold ON coin_transactions(hold_id) WHERE hold_id IS NOT NULL;
+
+ALTER TABLE coin_transactions
+  DROP CONSTRAINT coin_transactions_type_check,
+  ADD CONSTRAINT coin_transactions_type_check CHECK (
+    (transaction_type = 'transfer' AND from_employee_id IS NOT NULL AND to_employee_id IS NOT NULL AND merch_id IS NULL AND refund_of IS NULL)
+    OR (transaction_type = 'purchase' AND from_employee_id IS NOT NULL AND to_employee_id IS NULL AND merch_id IS NOT NULL AND refund_of IS NULL)
+    OR (transaction_type = 'refund' AND from_employee_id IS NULL AND to_employee_id IS NOT NULL AND merch_id IS NOT NULL AND refund_of IS NOT NULL)
+    OR (transaction_type = 'grant' AND from_employee_id IS NULL AND to_employee_id IS NOT NULL AND merch_id IS NULL AND refund_of IS NULL
+        AND reason <> '' AND created_by IS NOT NULL)
+    OR (transaction_type = 'adjustment' AND from_employee_id IS NOT NULL AND to_employee_id IS NULL AND merch_id IS NULL AND refund_of IS NULL
+        AND reason <> '' AND created_by IS NOT NULL)
+    OR (transaction_type = 'allowance' AND from_employee_id IS NULL AND to_employee_id IS NOT NULL AND merch_id IS NULL AND refund_of IS NULL
+        AND period IS NOT NULL)
+    OR (transaction_type = 'expiry' AND from_employee_id IS NOT NULL AND to_employee_id IS NULL AND merch_id IS NULL AND refund_of IS NULL)
+    OR (transaction_type = 'hold' AND from_employee_id IS NOT NULL AND to_employee_id IS NULL AND merch_id IS NULL AND refund_of IS NULL
+        AND hold_id IS NOT NULL)
+    OR (transaction_type IN ('hold_release', 'hold_refund') AND from_employee_id IS NULL AND to_employee_id IS NOT NULL
+        AND merch_id IS NULL AND refund_of IS NULL AND hold_id IS NOT NULL)
+  );
+
+-- Удержания учитываются в лимитах исходящих переводов наравне с переводами.
+DROP INDEX idx_transactions_transfer_from_created;
+CREATE INDEX idx_transactions_transfer_from_created
+  ON coin_transactions(from_employee_id, created_at)
+  WHERE transaction_type IN ('transfer', 'hold');
+
+-- +goose Down
+DROP INDEX idx_transactions_transfer_from_created;
+CREATE INDEX idx_transactions_transfer_from_created
+  ON coin_transactions(from_employee_id, created_at)
+  WHERE transaction_type = 'transfer';
+
+DELETE FROM ledger_postings WHERE entry_id IN (
+  SELECT id FROM journal_entries WHERE entry_type IN ('hold', 'hold_release', 'hold_refund')
+);
+DELETE FROM journal_entries WHERE entry_type IN ('hold', 'hold_release', 'hold_refund');
+DELETE FROM coin_transactions WHERE transaction_type IN ('hold', 'hold_release', 'hold_refund');
+
+ALTER TABLE coin_transactions
+  DROP CONSTRAINT coin_transactions_type_check,
+  ADD CONSTRAINT coin_transactions_type_check CHECK (
+    (transaction_type = 'transfer' AND from_employee_id IS NOT NULL AND to_employee_id IS NOT NULL AND merch_id IS NULL AND refund_of IS NULL)
+    OR (transaction_type = 'purchase' AND from_employee_id IS NOT NULL AND to_employee_id IS NULL AND merch_id IS NOT NULL AND refund_of IS NULL)
+    OR (transaction_type = 'refund' AND from_employee_id IS NULL AND to_employee_id IS NOT NULL AND merch_id IS NOT NULL AND refund_of IS NOT NULL)
+    OR (transaction_type = 'grant' AND from_employee_id IS NULL AND to_employee_id IS NOT NULL AND merch_id IS NULL AND refund_of IS NULL
+        AND reason <> '' AND created_by IS NOT NULL)
+    OR (transaction_type = 'adjustment' AND from_employee_id IS NOT NULL AND to_employee_id IS NULL AND merch_id IS NULL AND refund_of IS NULL
+        AND reason <> '' AND created_by IS NOT NULL)
+    OR (transaction_type = 'allowance' AND from_employee_id IS NULL AND to_employee_id IS NOT NULL AND merch_id IS NULL AND refund_of IS NULL
+        AND period IS NOT NULL)
+    OR (transaction_type = 'expiry' AND from_employee_id IS NOT NULL AND to_employee_id IS NULL AND merch_id IS NULL AND refund_of IS NULL)
+  );
+
+DROP INDEX idx_transactions_hold;
+ALTER TABLE coin_transactions DROP COLUMN hold_id;
+
+DROP TABLE transfer_hold_lots;
+DROP TABLE transfer_holds;
+DROP TYPE transfer_hold_status_enum;
+
+DELETE FROM ledger_accounts WHERE code = 'escrow';
@@ -0,0 +1,9 @@
+-- +goose Up
+-- Индексы для постраничной истории операций сотрудника: выборка идёт по
+-- отправителю или получателю в порядке (created_at, id) по убыванию.
+CREATE INDEX idx_transactions_from_created ON coin_transactions(from_employee_id, created_at DESC, id DESC);
+CREATE INDEX idx_transactions_to_created ON coin_transactions(to_employee_id, created_at DESC, id DESC);
+
+-- +goose Down
+DROP INDEX idx_transactions_to_created;
+DROP INDEX idx_transactions_from_created;
@@ -0,0 +1,7 @@
+-- +goose Up
+-- Индекс для выписок за месяц по всем сотрудникам: выборка идёт по периоду
+-- created_at без привязки к отправителю или получателю.
+CREATE INDEX idx_transactions_created_at ON coin_transactions(created_at, id);
+
+-- +goose Down
+DROP INDEX idx_transactions_created_at;
@@ -0,0 +1,19 @@
+-- +goose Up
+-- Части партий, израсходованные на покупку. При возврате монеты зачисляются
+-- с исходными сроками действия, а не новой партией на 12 месяцев. remaining —
+-- ещё не возвращённая часть: частичные возвраты расходуют её по порядку.
+-- Для покупок, сделанных до миграции, частей нет: возврат по ним зачисляется
+-- новой партией.
+CREATE TABLE purchase_lots (
+  id SERIAL PRIMARY KEY,
+  purchase_id INTEGER NOT NULL REFERENCES coin_transactions(id),
+  amount INTEGER NOT NULL CHECK (amount > 0),
+  remaining INTEGER NOT NULL CHECK (remaining >= 0 AND remaining <= amount),
+  granted_at TIMESTAMPTZ NOT NULL,
+  expires_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX idx_purchase_lots_purchase ON purchase_lots(purchase_id);
+
+-- +goose Down
+DROP TABLE purchase_lots;
@@ -0,0 +1,12 @@
+-- +goose Up
+-- Количество одной позиции корзины ограничено: повторное добавление товара
+-- суммирует количество и без предела могло выйти за границы integer.
+-- Значение совпадает с maxCartLineQuantity в сервисе корзины.
+UPDATE cart_items SET quantity = LEAST(quantity, 1000);
+
+ALTER TABLE cart_items
+  ADD CONSTRAINT cart_items_quantity_limit CHECK (quantity <= 1000);
+
+-- +goose Down
+ALTER TABLE cart_items
+  DROP CONSTRAINT cart_items_quantity_limit;
@@ -4,6 +4,7 @@ package utils
 import (
 	"encoding/json"
 	"net/http"
+	"strings"
 
 	"github.com/sirupsen/logrus"
 )
@@ -27,12 +28,34 @@ func JSONResponse(w http.ResponseWriter, status int, payload interface{}) {
 	}).Debug("JSON response sent successfully")
 }
 
-// ErrorResponse отправляет JSON-ответ с ошибкой и логирует отправку ошибки.
+// ErrorResponse — единый формат ответа с ошибкой. Code — стабильный
+// машиночитаемый код, Error — описание для человека.
+type ErrorResponse struct {
+	Error string `json:"error"`
+	Code  string `json:"code"`
+}
+
+// JSONErrorResponse отправляет JSON-ответ с ошибкой; код ошибки выводится из HTTP-статуса.
 func JSONErrorResponse(w http.ResponseWriter, status int, message string) {
+	JSONErrorCodeResponse(w, status, StatusErrorCode(status), message)
+}
+
+// JSONErrorCodeResponse отправляет JSON-ответ с ошибкой и явным кодом и логирует отправку ошибки.
+func JSONErrorCodeResponse(w http.ResponseWriter, status int, code, message string) {
 	logrus.WithFields(logrus.Fields{
 		"status":  status,
+		"code":    code,
 		"message": message,
 	}).Warn("sending error response")
 
-	JSONResponse(w, status, map[string]string{"error": message})
+	JSONResponse(w, status, ErrorResponse{Error: message, Code: code})
+}
+
+// StatusErrorCode строит код ошибки по HTTP-статусу: 404 -> NOT_FOUND.
+func StatusErrorCode(status int) string {
+	text := http.StatusText(status)
+	if text == "" {
+		return "ERROR"
+	}
+	return strings.ToUpper(strings.NewReplacer(" ", "_", "-", "_", "'", "").Replace(text))
 }
@@ -0,0 +1,31 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/sirupsen/logrus"
+)
+
+// SeedAdmins назначает роль admin пользователям из списка (переменная ADMIN_USERS).
+// Нужен для появления первого администратора; остальные роли назначаются
+// через PUT /api/admin/employees/{username}/role.
+func SeedAdmins(ctx context.Context, pool *pgxpool.Pool, usernames []string, logger *logrus.Logger) error {
+	if len(usernames) == 0 {
+		return nil
+	}
+
+	tag, err := pool.Exec(ctx, `
+		UPDATE employees
+		SET role = 'admin'
+		WHERE username = ANY($1) AND role <> 'admin'
+	`, usernames)
+	if err != nil {
+		logger.Errorf("Failed to promote admins %v: %v", usernames, err)
+		return fmt.Errorf("failed to promote admins: %w", err)
+	}
+
+	logger.Infof("Admin role granted to %d user(s) from %v", tag.RowsAffected(), usernames)
+	return nil
+}
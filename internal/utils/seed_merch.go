@@ -9,7 +9,9 @@ import (
 	"golang.org/x/crypto/bcrypt"
 )
 
-// SeedMerchData вставляет товары (merch) — как уже было.
+// SeedMerchData заполняет каталог товаров, если он ещё пуст.
+// Дальше каталогом управляет администратор через /api/admin/merch,
+// поэтому переименованные и снятые с продажи товары не создаются заново.
 // А также вставляет двух пользователей.
 func SeedMerchData(ctx context.Context, pool *pgxpool.Pool, logger *logrus.Logger) error {
 	items := []struct {
@@ -28,17 +30,26 @@ func SeedMerchData(ctx context.Context, pool *pgxpool.Pool, logger *logrus.Logge
 		{"pink-hoody", 500},
 	}
 
-	for _, item := range items {
-		_, err := pool.Exec(ctx, `
-			INSERT INTO merch (name, price)
-			VALUES ($1, $2)
-			ON CONFLICT (name) DO NOTHING
-		`, item.Name, item.Price)
-		if err != nil {
-			logger.Errorf("Failed to insert merch '%s': %v", item.Name, err)
-			return fmt.Errorf("failed to insert merch '%s': %w", item.Name, err)
+	var merchCount int
+	if err := pool.QueryRow(ctx, `SELECT COUNT(*) FROM merch`).Scan(&merchCount); err != nil {
+		logger.Errorf("Failed to count merch: %v", err)
+		return fmt.Errorf("failed to count merch: %w", err)
+	}
+	if merchCount > 0 {
+		logger.Infof("Merch catalog already contains %d items, skipping merch seed", merchCount)
+	} else {
+		for _, item := range items {
+			_, err := pool.Exec(ctx, `
+				INSERT INTO merch (name, price)
+				VALUES ($1, $2)
+				ON CONFLICT (name) DO NOTHING
+			`, item.Name, item.Price)
+			if err != nil {
+				logger.Errorf("Failed to insert merch '%s': %v", item.Name, err)
+				return fmt.Errorf("failed to insert merch '%s': %w", item.Name, err)
+			}
+			logger.Infof("Merch '%s' inserted (or already exists)", item.Name)
 		}
-		logger.Infof("Merch '%s' inserted (or already exists)", item.Name)
 	}
 
 	users := []struct {